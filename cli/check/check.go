@@ -0,0 +1,299 @@
+// Package check contains sq's data quality checks: a file of declarative
+// rules, such as "column email is unique", that are evaluated against
+// sources. The package entrypoints are Load and Evaluate.
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// Rule types.
+const (
+	// TypeNotNull asserts that a column contains no null values.
+	TypeNotNull = "not_null"
+
+	// TypeUnique asserts that a column's non-null values are unique.
+	TypeUnique = "unique"
+
+	// TypeAcceptedValues asserts that a column's non-null values are
+	// each one of Rule.Values.
+	TypeAcceptedValues = "accepted_values"
+
+	// TypeRegex asserts that a column's non-null values each match
+	// Rule.Pattern.
+	TypeRegex = "regex"
+
+	// TypeRange asserts that a column's values are between Rule.Min and
+	// Rule.Max, inclusive. Either bound may be omitted.
+	TypeRange = "range"
+
+	// TypeRowCount asserts that a table's row count is between Rule.Min
+	// and Rule.Max, inclusive. Either bound may be omitted.
+	TypeRowCount = "row_count"
+
+	// TypeRelationship asserts referential integrity: each non-null value
+	// of a column is present in the Rule.RefColumn column of the Rule.Ref
+	// table, which may be in a different source.
+	TypeRelationship = "relationship"
+
+	// TypeQuery asserts that the SLQ query Rule.Query returns zero rows.
+	TypeQuery = "query"
+)
+
+// Types is the set of rule types.
+var Types = []string{
+	TypeNotNull, TypeUnique, TypeAcceptedValues, TypeRegex,
+	TypeRange, TypeRowCount, TypeRelationship, TypeQuery,
+}
+
+// Rules is the content of a rules file.
+type Rules struct {
+	Rules []*Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule is a data quality assertion. The fields that apply depend upon the
+// rule's Type.
+type Rule struct {
+	// Min and Max are the bounds of a range or row_count rule.
+	Min any `yaml:"min,omitempty" json:"min,omitempty"`
+	Max any `yaml:"max,omitempty" json:"max,omitempty"`
+
+	// re is the compiled Pattern of a regex rule.
+	re *regexp.Regexp
+
+	// Name is the rule's name. If empty, a name is derived from the type
+	// and target.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Type is the rule type, e.g. "not_null". See Types.
+	Type string `yaml:"type" json:"type"`
+
+	// Table is the checked table, in the form "@handle.table", or
+	// ".table" for the active source. It is required by every rule type
+	// except query.
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
+
+	// Column is the checked column. It is required by every rule type
+	// except row_count and query.
+	Column string `yaml:"column,omitempty" json:"column,omitempty"`
+
+	// Pattern is the regular expression of a regex rule, in Go syntax.
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+
+	// Ref is the referenced table of a relationship rule, in the form
+	// "@handle.table".
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+
+	// RefColumn is the referenced column of a relationship rule.
+	RefColumn string `yaml:"ref_column,omitempty" json:"ref_column,omitempty"`
+
+	// Query is the SLQ query of a query rule.
+	Query string `yaml:"query,omitempty" json:"query,omitempty"`
+
+	// Values is the set of accepted values of an accepted_values rule.
+	Values []any `yaml:"values,omitempty" json:"values,omitempty"`
+
+	// handle and tbl are the parsed parts of Table; refHandle and refTbl
+	// are the parsed parts of Ref.
+	handle, tbl       string
+	refHandle, refTbl string
+}
+
+// Target returns a description of what r checks, e.g.
+// "@sakila.actor.first_name".
+func (r *Rule) Target() string {
+	switch r.Type {
+	case TypeQuery:
+		return r.Query
+	case TypeRowCount:
+		return r.Table
+	case TypeRelationship:
+		return r.Table + "." + r.Column + " → " + r.Ref + "." + r.RefColumn
+	default:
+		return r.Table + "." + r.Column
+	}
+}
+
+// DisplayName returns r.Name, or if empty, a name derived from the rule's
+// type and target.
+func (r *Rule) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if r.Type == TypeQuery {
+		return r.Type
+	}
+	return r.Type + " " + r.Target()
+}
+
+// LoadFile loads the rules file at fpath. See Load.
+func LoadFile(fpath string) (*Rules, error) {
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, errz.Wrap(err, "read rules file")
+	}
+
+	rules, err := Load(data)
+	if err != nil {
+		return nil, errz.Wrapf(err, "rules file %s", fpath)
+	}
+	return rules, nil
+}
+
+// Load loads rules from YAML data, and validates each rule.
+func Load(data []byte) (*Rules, error) {
+	rules := &Rules{}
+	if err := ioz.UnmarshallYAML(data, rules); err != nil {
+		return nil, err
+	}
+
+	if len(rules.Rules) == 0 {
+		return nil, errz.New("no rules")
+	}
+
+	for i, r := range rules.Rules {
+		if r == nil {
+			return nil, errz.Errorf("rule %d: empty rule", i+1)
+		}
+		if err := r.validate(); err != nil {
+			if r.Name != "" {
+				return nil, errz.Wrapf(err, "rule %d (%s)", i+1, r.Name)
+			}
+			return nil, errz.Wrapf(err, "rule %d", i+1)
+		}
+	}
+
+	return rules, nil
+}
+
+// validate validates r, and populates r's unexported fields.
+func (r *Rule) validate() error {
+	var err error
+	if r.Type == "" {
+		return errz.Errorf("type is required: one of %s", strings.Join(Types, ", "))
+	}
+
+	if r.Type == TypeQuery {
+		if strings.TrimSpace(r.Query) == "" {
+			return errz.Errorf("%s: query is required", r.Type)
+		}
+		if r.Table != "" || r.Column != "" {
+			return errz.Errorf("%s: table and column don't apply: specify them in the query", r.Type)
+		}
+		return nil
+	}
+
+	if r.Table == "" {
+		return errz.Errorf("%s: table is required", r.Type)
+	}
+	if r.handle, r.tbl, err = source.ParseTableHandle(r.Table); err != nil {
+		return errz.Wrapf(err, "%s: invalid table", r.Type)
+	}
+	if r.tbl == "" {
+		return errz.Errorf("%s: invalid table {%s}: must be @handle.table or .table", r.Type, r.Table)
+	}
+
+	if r.Type != TypeRowCount && r.Column == "" {
+		return errz.Errorf("%s: column is required", r.Type)
+	}
+
+	switch r.Type {
+	case TypeNotNull, TypeUnique:
+	case TypeAcceptedValues:
+		if len(r.Values) == 0 {
+			return errz.Errorf("%s: values is required", r.Type)
+		}
+	case TypeRegex:
+		if r.Pattern == "" {
+			return errz.Errorf("%s: pattern is required", r.Type)
+		}
+		if r.re, err = regexp.Compile(r.Pattern); err != nil {
+			return errz.Wrapf(err, "%s: invalid pattern", r.Type)
+		}
+	case TypeRange:
+		if r.Min == nil && r.Max == nil {
+			return errz.Errorf("%s: min or max is required", r.Type)
+		}
+	case TypeRowCount:
+		if r.Min == nil && r.Max == nil {
+			return errz.Errorf("%s: min or max is required", r.Type)
+		}
+		for _, v := range []any{r.Min, r.Max} {
+			if _, ok := toInt64(v); v != nil && !ok {
+				return errz.Errorf("%s: min and max must be integers", r.Type)
+			}
+		}
+	case TypeRelationship:
+		if r.Ref == "" || r.RefColumn == "" {
+			return errz.Errorf("%s: ref and ref_column are required", r.Type)
+		}
+		if r.refHandle, r.refTbl, err = source.ParseTableHandle(r.Ref); err != nil {
+			return errz.Wrapf(err, "%s: invalid ref", r.Type)
+		}
+		if r.refTbl == "" {
+			return errz.Errorf("%s: invalid ref {%s}: must be @handle.table or .table", r.Type, r.Ref)
+		}
+	default:
+		return errz.Errorf("invalid type {%s}: must be one of %s", r.Type, strings.Join(Types, ", "))
+	}
+
+	return nil
+}
+
+// slqTable returns the SLQ table selector of handle and tbl, e.g.
+// `@sakila."actor"`.
+func slqTable(handle, tbl string) string {
+	return handle + "." + slqQuote(tbl)
+}
+
+// slqCol returns the SLQ column selector of col, e.g. `."first_name"`.
+func slqCol(col string) string {
+	return "." + slqQuote(col)
+}
+
+// slqQuote returns s as a double-quoted SLQ string, whose escapes are
+// those of a JSON string.
+func slqQuote(s string) string {
+	b, _ := json.Marshal(s) // Can't fail for a string
+	return string(b)
+}
+
+// slqLiteral returns v, a value from the rules file, as a SLQ literal.
+func slqLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return slqQuote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toInt64 returns v, a value from the rules file, as int64.
+func toInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true //nolint:gosec // row counts don't overflow
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package check_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/check"
+	"github.com/neilotoole/sq/cli/output"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "empty", data: "rules: []", wantErr: "no rules"},
+		{name: "no_type", data: "rules:\n  - table: .data\n", wantErr: "type is required"},
+		{name: "bad_type", data: "rules:\n  - type: bogus\n    table: .data\n    column: a\n", wantErr: "invalid type"},
+		{name: "no_table", data: "rules:\n  - type: not_null\n    column: a\n", wantErr: "table is required"},
+		{name: "bad_table", data: "rules:\n  - type: not_null\n    table: data\n    column: a\n", wantErr: "invalid table"},
+		{name: "no_column", data: "rules:\n  - type: unique\n    table: .data\n", wantErr: "column is required"},
+		{
+			name:    "no_values",
+			data:    "rules:\n  - type: accepted_values\n    table: .data\n    column: a\n",
+			wantErr: "values is required",
+		},
+		{
+			name:    "bad_pattern",
+			data:    "rules:\n  - type: regex\n    table: .data\n    column: a\n    pattern: '['\n",
+			wantErr: "invalid pattern",
+		},
+		{name: "no_bounds", data: "rules:\n  - type: range\n    table: .data\n    column: a\n", wantErr: "min or max"},
+		{
+			name:    "row_count_float",
+			data:    "rules:\n  - type: row_count\n    table: .data\n    min: 1.5\n",
+			wantErr: "must be integers",
+		},
+		{
+			name:    "no_ref",
+			data:    "rules:\n  - type: relationship\n    table: .data\n    column: a\n",
+			wantErr: "ref and ref_column",
+		},
+		{
+			name:    "query_with_table",
+			data:    "rules:\n  - type: query\n    query: .data\n    table: .data\n",
+			wantErr: "don't apply",
+		},
+		{
+			name:    "named",
+			data:    "rules:\n  - name: my rule\n    type: not_null\n",
+			wantErr: "rule 1 (my rule)",
+		},
+		{
+			name: "valid",
+			data: `rules:
+  - type: not_null
+    table: "@sakila.actor"
+    column: first_name
+  - type: row_count
+    table: .actor
+    min: 1
+  - type: relationship
+    table: .film_actor
+    column: actor_id
+    ref: "@sakila.actor"
+    ref_column: actor_id
+  - type: query
+    query: '.actor | where(.actor_id < 0)'
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := check.Load([]byte(tc.data))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rules.Rules, 4)
+			require.Equal(t, "not_null @sakila.actor.first_name", rules.Rules[0].DisplayName())
+			require.Equal(t, ".film_actor.actor_id → @sakila.actor.actor_id", rules.Rules[2].Target())
+		})
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []output.CheckResult{
+		{Name: "a", Type: check.TypeNotNull, Target: "@h.t.a", Status: output.CheckStatusPass},
+		{
+			Name: "b", Type: check.TypeUnique, Target: "@h.t.b", Status: output.CheckStatusFail,
+			Message: "1 duplicated value", Samples: []string{"x (2)"}, Failures: 1,
+		},
+		{Name: "c", Type: check.TypeRange, Target: "@h.t.c", Status: output.CheckStatusError, Message: "boom"},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, check.WriteJUnit(buf, "rules.yml", time.Now(), results))
+
+	var got struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Errors   int `xml:"errors,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Body    string `xml:",chardata"`
+				} `xml:"failure"`
+				Error *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"error"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, 3, got.Tests)
+	require.Equal(t, 1, got.Failures)
+	require.Equal(t, 1, got.Errors)
+	require.Len(t, got.Suites, 1)
+	require.Equal(t, "rules.yml", got.Suites[0].Name)
+
+	cases := got.Suites[0].Cases
+	require.Len(t, cases, 3)
+	require.Nil(t, cases[0].Failure)
+	require.Nil(t, cases[0].Error)
+	require.NotNil(t, cases[1].Failure)
+	require.Equal(t, "1 duplicated value", cases[1].Failure.Message)
+	require.Contains(t, cases[1].Failure.Body, "x (2)")
+	require.NotNil(t, cases[2].Error)
+	require.Equal(t, "boom", cases[2].Error.Message)
+}
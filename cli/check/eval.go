@@ -0,0 +1,397 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// maxSamples is the maximum number of violating values, or rows, reported
+// for a rule.
+const maxSamples = 5
+
+// Evaluate evaluates each of rules, in order, returning a result for each.
+// Each rule is evaluated by executing SLQ queries against its sources, via
+// the regular query pipeline. A rule that can't be evaluated, e.g. because
+// its table doesn't exist, has a result with status output.CheckStatusError;
+// the remaining rules are still evaluated. An error is returned only if ctx
+// is done.
+func Evaluate(ctx context.Context, ru *run.Run, rules *Rules) ([]output.CheckResult, error) {
+	log := lg.FromContext(ctx)
+	e := &evaluator{ru: ru, tables: map[string]*metadata.Table{}}
+	results := make([]output.CheckResult, 0, len(rules.Rules))
+	for _, r := range rules.Rules {
+		if err := ctx.Err(); err != nil {
+			return results, errz.Err(context.Cause(ctx))
+		}
+
+		start := time.Now()
+		res := output.CheckResult{Name: r.DisplayName(), Type: r.Type, Target: r.Target()}
+		err := e.evaluateRule(ctx, r, &res)
+		res.Elapsed = time.Since(start)
+		switch {
+		case err != nil:
+			if errz.IsErrContext(err) {
+				return results, err
+			}
+			res.Status = output.CheckStatusError
+			res.Message = errz.HumanMessage(err)
+			res.Failures, res.Samples = 0, nil
+		case res.Failures > 0:
+			res.Status = output.CheckStatusFail
+		default:
+			res.Status = output.CheckStatusPass
+		}
+
+		log.Debug("Evaluated check rule", lga.Name, res.Name, "status", res.Status,
+			lga.Count, res.Failures, lga.Elapsed, res.Elapsed)
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// evaluator evaluates rules.
+type evaluator struct {
+	ru *run.Run
+
+	// tables caches the metadata of the rules' tables, keyed by
+	// "@handle.table".
+	tables map[string]*metadata.Table
+}
+
+// evaluateRule evaluates r, populating res.Failures, res.Message, and
+// res.Samples.
+func (e *evaluator) evaluateRule(ctx context.Context, r *Rule, res *output.CheckResult) error {
+	ru := e.ru
+	tbl, col := slqTable(r.handle, r.tbl), slqCol(r.Column)
+	var err error
+
+	if r.Type != TypeQuery {
+		// Verify the table and column up front: a SLQ query against a
+		// column that doesn't exist doesn't necessarily fail, e.g. SQLite
+		// treats an unknown double-quoted identifier as a string literal.
+		if err = e.verifyColumn(ctx, r.handle, r.tbl, r.Column); err != nil {
+			return err
+		}
+		if r.Type == TypeRelationship {
+			if err = e.verifyColumn(ctx, r.refHandle, r.refTbl, r.RefColumn); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch r.Type {
+	case TypeNotNull:
+		res.Failures, err = queryCount(ctx, ru, tbl+" | where("+col+" == null) | count")
+		res.Message = fmt.Sprintf("%d null %s", res.Failures, stringz.Plu("value(s)", int(res.Failures)))
+
+	case TypeUnique:
+		// SLQ's count can't be used in an expression, but sum(1) is
+		// equivalent.
+		err = queryGroups(ctx, ru, tbl, col, "", "sum(1) > 1", func(v any, n int64) {
+			res.Failures++
+			addSample(res, v, n)
+		})
+		res.Message = fmt.Sprintf("%d duplicated %s", res.Failures, stringz.Plu("value(s)", int(res.Failures)))
+
+	case TypeAcceptedValues:
+		conds := make([]string, len(r.Values))
+		for i, v := range r.Values {
+			conds[i] = col + " != " + slqLiteral(v)
+		}
+		err = queryGroups(ctx, ru, tbl, col, strings.Join(conds, " && "), "", func(v any, n int64) {
+			res.Failures += n
+			addSample(res, v, n)
+		})
+		res.Message = fmt.Sprintf("%d %s not accepted", res.Failures, stringz.Plu("value(s)", int(res.Failures)))
+
+	case TypeRegex:
+		var grip driver.Grip
+		if grip, err = e.openGrip(ctx, r.handle); err != nil {
+			return err
+		}
+		// If the database can evaluate the pattern, only the violating
+		// values are returned. Otherwise, every value is returned, and the
+		// pattern is evaluated here.
+		var where string
+		if fn := grip.SQLDriver().Dialect().RegexpMatch; fn != "" {
+			where = "_" + fn + "(" + col + ", " + slqQuote(r.Pattern) + ") == false"
+		}
+		err = queryGroups(ctx, ru, tbl, col, where, "", func(v any, n int64) {
+			if where != "" || !r.re.MatchString(formatValue(v)) {
+				res.Failures += n
+				addSample(res, v, n)
+			}
+		})
+		res.Message = fmt.Sprintf("%d %s not matching %s", res.Failures,
+			stringz.Plu("value(s)", int(res.Failures)), r.Pattern)
+
+	case TypeRange:
+		var conds []string
+		if r.Min != nil {
+			conds = append(conds, col+" < "+slqLiteral(r.Min))
+		}
+		if r.Max != nil {
+			conds = append(conds, col+" > "+slqLiteral(r.Max))
+		}
+		err = queryGroups(ctx, ru, tbl, col, strings.Join(conds, " || "), "", func(v any, n int64) {
+			res.Failures += n
+			addSample(res, v, n)
+		})
+		res.Message = fmt.Sprintf("%d %s out of range [%s, %s]", res.Failures,
+			stringz.Plu("value(s)", int(res.Failures)), formatBound(r.Min), formatBound(r.Max))
+
+	case TypeRowCount:
+		var count int64
+		if count, err = queryCount(ctx, ru, tbl+" | count"); err != nil {
+			return err
+		}
+		minCount, hasMin := toInt64(r.Min)
+		maxCount, hasMax := toInt64(r.Max)
+		switch {
+		case hasMin && count < minCount:
+			res.Failures = 1
+			res.Message = fmt.Sprintf("row count %d is less than min %d", count, minCount)
+		case hasMax && count > maxCount:
+			res.Failures = 1
+			res.Message = fmt.Sprintf("row count %d is greater than max %d", count, maxCount)
+		default:
+			res.Message = fmt.Sprintf("row count %d", count)
+		}
+
+	case TypeRelationship:
+		// The orphaned values are those without a match in the left join
+		// to the referenced table. The tables are aliased, as they may have
+		// the same name, or be the same table.
+		col = ".t" + slqCol(r.Column)
+		refCol := ".r" + slqCol(r.RefColumn)
+		from := tbl + ":t | left_join(" + slqTable(r.refHandle, r.refTbl) + ":r, " + col + " == " + refCol + ")"
+		err = queryGroups(ctx, ru, from, col, refCol+" == null", "", func(v any, n int64) {
+			res.Failures += n
+			addSample(res, v, n)
+		})
+		res.Message = fmt.Sprintf("%d %s not found in %s.%s", res.Failures,
+			stringz.Plu("value(s)", int(res.Failures)), r.Ref, r.RefColumn)
+
+	case TypeQuery:
+		err = query(ctx, ru, r.Query, func(rec record.Record) error {
+			res.Failures++
+			if len(res.Samples) < maxSamples {
+				vals := make([]string, len(rec))
+				for i := range rec {
+					vals[i] = formatValue(rec[i])
+				}
+				res.Samples = append(res.Samples, strings.Join(vals, ", "))
+			}
+			return nil
+		})
+		res.Message = fmt.Sprintf("%d %s returned", res.Failures, stringz.Plu("row(s)", int(res.Failures)))
+
+	default:
+		// Shouldn't happen: the rule was validated.
+		return errz.Errorf("invalid rule type {%s}", r.Type)
+	}
+
+	if err == nil && res.Failures == 0 && r.Type != TypeRowCount {
+		res.Message = ""
+	}
+	return err
+}
+
+// openGrip opens the source with handle, or the active source if handle
+// is empty.
+func (e *evaluator) openGrip(ctx context.Context, handle string) (driver.Grip, error) {
+	coll := e.ru.Config.Collection
+	var src *source.Source
+	if handle == "" {
+		if src = coll.Active(); src == nil {
+			return nil, errz.New("no active source")
+		}
+	} else {
+		var err error
+		if src, err = coll.Get(handle); err != nil {
+			return nil, err
+		}
+	}
+	return e.ru.Grips.Open(ctx, src, driver.ModeReadOnly)
+}
+
+// verifyColumn returns an error if the source with handle (or the active
+// source, if handle is empty) doesn't have table tbl, or if col is
+// non-empty, and tbl doesn't have column col.
+func (e *evaluator) verifyColumn(ctx context.Context, handle, tbl, col string) error {
+	grip, err := e.openGrip(ctx, handle)
+	if err != nil {
+		return err
+	}
+
+	key := grip.Source().Handle + "." + tbl
+	tblMeta, ok := e.tables[key]
+	if !ok {
+		if tblMeta, err = grip.TableMetadata(ctx, tbl); err != nil {
+			return err
+		}
+		e.tables[key] = tblMeta
+	}
+
+	if col != "" && tblMeta.Column(col) == nil {
+		return errz.Errorf("column {%s} not found in table %s", col, key)
+	}
+	return nil
+}
+
+// queryGroups executes a query that groups the non-null values of column
+// col of from, a table or join, invoking fn with each value and its count.
+// If where is non-empty, only the values that satisfy the where condition
+// are grouped. If having is non-empty, only the groups that satisfy the
+// having condition are returned.
+func queryGroups(ctx context.Context, ru *run.Run, from, col, where, having string,
+	fn func(v any, n int64),
+) error {
+	cond := col + " != null"
+	if where != "" {
+		cond += " && (" + where + ")"
+	}
+
+	slq := from + " | where(" + cond + ") | " + col + ", count | group_by(" + col + ")"
+	if having != "" {
+		slq += " | having(" + having + ")"
+	}
+	return query(ctx, ru, slq, func(rec record.Record) error {
+		n, ok := rec[1].(int64)
+		if !ok {
+			return errz.Errorf("expected int64 count but got %T", rec[1])
+		}
+		fn(rec[0], n)
+		return nil
+	})
+}
+
+// queryCount executes slq, which must return a single count value.
+func queryCount(ctx context.Context, ru *run.Run, slq string) (int64, error) {
+	var (
+		count int64
+		found bool
+	)
+	err := query(ctx, ru, slq, func(rec record.Record) error {
+		var ok bool
+		if count, ok = rec[0].(int64); !ok {
+			return errz.Errorf("expected int64 count but got %T", rec[0])
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, errz.Errorf("no count returned by: %s", slq)
+	}
+	return count, nil
+}
+
+// query executes slq via the query pipeline, invoking fn for each record.
+func query(ctx context.Context, ru *run.Run, slq string, fn func(rec record.Record) error) error {
+	lg.FromContext(ctx).Debug("Executing check query", lga.SLQ, slq)
+	qc := run.NewQueryContext(ru, nil)
+	// Checks never write to a source: open read-only.
+	qc.AccessMode = driver.ModeReadOnly
+
+	recw := output.NewRecordWriterAdapter(ctx, &recordFuncWriter{fn: fn})
+	execErr := libsq.ExecSLQ(ctx, qc, slq, recw)
+	_, waitErr := recw.Wait()
+	if execErr != nil {
+		return execErr
+	}
+	return waitErr
+}
+
+var _ output.RecordWriter = (*recordFuncWriter)(nil)
+
+// recordFuncWriter is an output.RecordWriter that invokes fn for each
+// record.
+type recordFuncWriter struct {
+	fn func(rec record.Record) error
+}
+
+// Open implements output.RecordWriter.
+func (w *recordFuncWriter) Open(context.Context, record.Meta) error {
+	return nil
+}
+
+// WriteRecords implements output.RecordWriter.
+func (w *recordFuncWriter) WriteRecords(_ context.Context, recs []record.Record) error {
+	for _, rec := range recs {
+		if err := w.fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush implements output.RecordWriter.
+func (w *recordFuncWriter) Flush(context.Context) error {
+	return nil
+}
+
+// Close implements output.RecordWriter.
+func (w *recordFuncWriter) Close(context.Context) error {
+	return nil
+}
+
+// addSample adds the violating value v, which occurs n times, to
+// res.Samples, unless there are already maxSamples samples.
+func addSample(res *output.CheckResult, v any, n int64) {
+	if len(res.Samples) >= maxSamples {
+		return
+	}
+
+	s := formatValue(v)
+	if n > 1 {
+		s += " (" + strconv.FormatInt(n, 10) + ")"
+	}
+	res.Samples = append(res.Samples, s)
+}
+
+// formatValue returns the record value v as a string. It's used for
+// display, and to compare values from different sources: an integral
+// float formats the same as the equivalent int.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatBound returns the range bound v for display, or "" if v is nil.
+func formatBound(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+}
+
+type junitTestCase struct {
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as a JUnit XML report, as consumed by CI
+// systems, to w. The report has a single test suite, named suite, with a
+// test case for each rule: a failed rule is reported as a test failure,
+// and a rule that couldn't be evaluated as a test error. The sample
+// violations are the failure's body.
+func WriteJUnit(w io.Writer, suite string, started time.Time, results []output.CheckResult) error {
+	ts := junitTestSuite{
+		Name:      suite,
+		Timestamp: started.UTC().Format("2006-01-02T15:04:05"),
+		Tests:     len(results),
+		Cases:     make([]junitTestCase, 0, len(results)),
+	}
+
+	var elapsed time.Duration
+	for _, res := range results {
+		elapsed += res.Elapsed
+		tc := junitTestCase{Name: res.Name, ClassName: res.Type, Time: junitSeconds(res.Elapsed)}
+		switch res.Status {
+		case output.CheckStatusFail:
+			ts.Failures++
+			tc.Failure = &junitProblem{
+				Message: res.Message,
+				Type:    res.Type,
+				Body:    junitBody(res),
+			}
+		case output.CheckStatusError:
+			ts.Errors++
+			tc.Error = &junitProblem{Message: res.Message, Type: "error", Body: res.Target}
+		}
+		ts.Cases = append(ts.Cases, tc)
+	}
+	ts.Time = junitSeconds(elapsed)
+
+	doc := junitTestSuites{
+		Name:     "sq check",
+		Time:     ts.Time,
+		Tests:    ts.Tests,
+		Failures: ts.Failures,
+		Errors:   ts.Errors,
+		Suites:   []junitTestSuite{ts},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errz.Err(err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return errz.Err(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return errz.Err(err)
+}
+
+// junitBody returns the body of the failure element of res: the target,
+// followed by the sample violations.
+func junitBody(res output.CheckResult) string {
+	var sb strings.Builder
+	sb.WriteString(res.Target)
+	if len(res.Samples) > 0 {
+		sb.WriteString("\n\nSamples:\n")
+		for _, s := range res.Samples {
+			sb.WriteString("  ")
+			sb.WriteString(s)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -204,6 +204,15 @@ func ExecuteWith(ctx context.Context, ru *run.Run, args []string) (err error) {
 				rootCmd.SetArgs(effectiveArgs)
 			} else {
 				// It's just a normal command like "sq ls" or such.
+				if cmd.Name() != "slq" && cmd.Flags().Lookup(flag.Arg) != nil {
+					// The command accepts --arg, e.g. "sq run". Note that an
+					// explicit "sq slq" is left as-is, and thus continues to
+					// accept the pflag-native "--arg name:value" form.
+					if args, err = preprocessFlagArgVars(args); err != nil {
+						lg.WarnIfCloseError(log, "Problem closing run", ru)
+						return err
+					}
+				}
 
 				// Explicitly set the args on rootCmd as this makes
 				// cobra happy when this func is executed via tests.
@@ -253,7 +262,9 @@ func newCommandTree(ru *run.Run) (rootCmd *cobra.Command) {
 	addCmd(ru, rootCmd, slqCmd)
 
 	addCmd(ru, rootCmd, newSrcAddCmd())
-	addCmd(ru, rootCmd, newSrcCommand())
+	srcCmd := addCmd(ru, rootCmd, newSrcCommand())
+	addCmd(ru, srcCmd, newSrcExportCmd())
+	addCmd(ru, srcCmd, newSrcImportCmd())
 	addCmd(ru, rootCmd, newGroupCommand())
 	addCmd(ru, rootCmd, newListCmd())
 	addCmd(ru, rootCmd, newMoveCmd())
@@ -264,6 +275,13 @@ func newCommandTree(ru *run.Run) (rootCmd *cobra.Command) {
 	addCmd(ru, rootCmd, newSQLCmd())
 	addCmd(ru, rootCmd, newScratchCmd())
 
+	addCmd(ru, rootCmd, newRunCmd())
+	queryCmd := addCmd(ru, rootCmd, newQueryCmd())
+	addCmd(ru, queryCmd, newQueryListCmd())
+	addCmd(ru, queryCmd, newQueryAddCmd())
+	addCmd(ru, queryCmd, newQueryRemoveCmd())
+	addCmd(ru, queryCmd, newQueryEditCmd())
+
 	tblCmd := addCmd(ru, rootCmd, newTblCmd())
 	addCmd(ru, tblCmd, newTblCopyCmd())
 	addCmd(ru, tblCmd, newTblTruncateCmd())
@@ -279,6 +297,7 @@ func newCommandTree(ru *run.Run) (rootCmd *cobra.Command) {
 	addCmd(ru, dbRestoreCmd, newDBRestoreClusterCmd())
 
 	addCmd(ru, rootCmd, newDiffCmd())
+	addCmd(ru, rootCmd, newCheckCmd())
 
 	driverCmd := addCmd(ru, rootCmd, newDriverCmd())
 	addCmd(ru, driverCmd, newDriverListCmd())
@@ -298,6 +317,8 @@ func newCommandTree(ru *run.Run) (rootCmd *cobra.Command) {
 	addCmd(ru, configKeyringCmd, newConfigKeyringGetCmd())
 	addCmd(ru, configKeyringCmd, newConfigKeyringRmCmd())
 	addCmd(ru, configKeyringCmd, newConfigKeyringMigrateCmd())
+	configSOPSCmd := addCmd(ru, configCmd, newConfigSOPSCmd())
+	addCmd(ru, configSOPSCmd, newConfigSOPSMigrateCmd())
 
 	cacheCmd := addCmd(ru, rootCmd, newCacheCmd())
 	addCmd(ru, cacheCmd, newCacheLocationCmd())
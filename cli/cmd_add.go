@@ -18,6 +18,8 @@ import (
 	"github.com/neilotoole/sq/cli/run"
 	"github.com/neilotoole/sq/drivers/csv"
 	"github.com/neilotoole/sq/drivers/duckdb"
+	"github.com/neilotoole/sq/drivers/json"
+	"github.com/neilotoole/sq/drivers/odbc"
 	"github.com/neilotoole/sq/drivers/sqlite3"
 	"github.com/neilotoole/sq/libsq/core/errz"
 	"github.com/neilotoole/sq/libsq/core/ioz"
@@ -104,6 +106,11 @@ is ambiguous, explicitly specify the driver type.
 
   $ sq add --driver=tsv ./mystery.data
 
+Fixed-width text isn't detected: specify the fixedwidth driver, and
+typically the column layout.
+
+  $ sq add --driver=fixedwidth ./accounts.txt --driver.fixedwidth.layout='id:1:6,name:7:20'
+
 Available source driver types can be listed via "sq driver ls". At a
 minimum, the following drivers are bundled:
 
@@ -167,6 +174,14 @@ More examples:
   # Add a CSV source from a URL (will be downloaded)
   $ sq add https://sq.io/testdata/actor.csv
 
+  # Add a glob (or directory) of files as a single source, whose
+  # files are unioned into one table, with a "_file" column
+  $ sq add './events/*.jsonl' --handle @events --ingest.file-column
+
+  # Add a paginated REST API, whose records are in the "items" field
+  # of each response, and whose next page is given by the Link header
+  $ sq add --driver=rest https://api.acme.com/v1/users --driver.rest.records='$.items'
+
   # Add a source, and make it the active source (and group)
   $ sq add ./actor.csv --handle @csv/actor
 
@@ -194,9 +209,19 @@ More examples:
 	cmd.Flags().BoolP(flag.AddActive, flag.AddActiveShort, false, flag.AddActiveUsage)
 
 	addOptionFlag(cmd.Flags(), driver.OptIngestHeader)
+	addOptionFlag(cmd.Flags(), driver.OptIngestFileColumn)
 	addOptionFlag(cmd.Flags(), csv.OptEmptyAsNull)
 	addOptionFlag(cmd.Flags(), csv.OptDelim)
 	panicOn(cmd.RegisterFlagCompletionFunc(csv.OptDelim.Flag().Name, completeStrings(csv.NamedDelims()...)))
+	addOptionFlag(cmd.Flags(), csv.OptFixedWidthLayout)
+	addOptionFlag(cmd.Flags(), json.OptRESTRecords)
+	addOptionFlag(cmd.Flags(), json.OptRESTPaginate)
+	panicOn(cmd.RegisterFlagCompletionFunc(json.OptRESTPaginate.Flag().Name,
+		completeStrings(json.PaginateStrategies()...)))
+	addOptionFlag(cmd.Flags(), odbc.OptQuote)
+	panicOn(cmd.RegisterFlagCompletionFunc(odbc.OptQuote.Flag().Name, completeStrings(odbc.QuoteStyles()...)))
+	addOptionFlag(cmd.Flags(), odbc.OptLimit)
+	panicOn(cmd.RegisterFlagCompletionFunc(odbc.OptLimit.Flag().Name, completeStrings(odbc.LimitStyles()...)))
 
 	return cmd
 }
@@ -273,11 +298,11 @@ func execSrcAdd(cmd *cobra.Command, args []string) (err error) {
 		if cmdFlagChanged(cmd, flag.AddStore) {
 			return errz.Errorf("--%s is not supported when the location is a ${...} placeholder", flag.AddStore)
 		}
-		// Resolve relative ${file:...} paths against the current working
-		// directory. The file resolver itself only accepts absolute paths
-		// (or ~/...) — but at add time we know where the user is and can
-		// capture that intent before persisting the placeholder. Other
-		// schemes pass through unchanged.
+		// Resolve relative ${file:...} and ${sops:...} paths against the
+		// current working directory. The file and sops resolvers only
+		// accept absolute paths (or ~/...) — but at add time we know where
+		// the user is and can capture that intent before persisting the
+		// placeholder. Other schemes pass through unchanged.
 		if loc, err = secret.RewritePlaceholders(ctx, loc, absolutizeFilePath); err != nil {
 			return err
 		}
@@ -327,6 +352,9 @@ func execSrcAdd(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return err
 	}
+	if err = absolutizeIngestFiles(ru.OptionsRegistry, o); err != nil {
+		return err
+	}
 
 	src, err := newSource(
 		ctx,
@@ -751,7 +779,8 @@ func isURLLocation(loc string) bool {
 
 // absolutizeFilePath is a secret.RewritePlaceholders callback: for
 // the "file" scheme it returns filepath.Abs(path) when path is bare
-// relative (e.g. "./pg.dsn", "pg.dsn", "../shared/pw"). Paths that
+// relative (e.g. "./pg.dsn", "pg.dsn", "../shared/pw"). For the "sops"
+// scheme, the same applies to the file part of "<file>#<key.path>". Paths that
 // are already usable by the file resolver — absolute, ~/-prefixed,
 // or the file:/// URI form — pass through unchanged so user intent
 // is preserved (e.g. "~/" stays portable across users). Other
@@ -761,7 +790,18 @@ func isURLLocation(loc string) bool {
 // The expansion uses os.Getwd at add time and is captured once;
 // later moves of the user's working directory don't affect a source
 // already added.
-func absolutizeFilePath(_ context.Context, scheme, path string) (string, error) {
+func absolutizeFilePath(ctx context.Context, scheme, path string) (string, error) {
+	if scheme == "sops" {
+		// ${sops:<file>#<key.path>}: absolutize the file part only. The
+		// split is on the last '#', as sops.SplitPath does, so a file
+		// name containing '#' survives.
+		i := strings.LastIndexByte(path, '#')
+		if i < 0 {
+			return path, nil
+		}
+		fp, err := absolutizeFilePath(ctx, "file", path[:i])
+		return fp + path[i:], err
+	}
 	if scheme != "file" || path == "" {
 		return path, nil
 	}
@@ -554,6 +554,36 @@ func TestCmdAdd_Placeholder_FileRelativeIsAbsolutized(t *testing.T) {
 		"./pg.dsn should have been absolutized at add time")
 }
 
+// TestCmdAdd_Placeholder_SOPSRelativeIsAbsolutized verifies that the
+// file part of a ${sops:<file>#<key.path>} placeholder is absolutized at
+// sq-add time, like ${file:...}, while the key path is left untouched. As
+// for the sops resolver, the key path follows the last '#'.
+func TestCmdAdd_Placeholder_SOPSRelativeIsAbsolutized(t *testing.T) {
+	dir := t.TempDir()
+	pwd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Chdir(pwd) })
+	require.NoError(t, os.Chdir(dir))
+	resolvedDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	th := testh.New(t)
+	tr := testrun.New(th.Context, t, nil)
+
+	const handle = "@from_relative_sops"
+	require.NoError(t, tr.Exec("add",
+		"postgres://alice:${sops:./team#/./secrets.enc.yaml#pg.password}@localhost/sakila",
+		"--handle", handle,
+		"--driver", "postgres", // skip add-time resolution
+		"--skip-verify"))
+
+	src, err := tr.Run.Config.Collection.Get(handle)
+	require.NoError(t, err)
+	wantLoc := "postgres://alice:${sops:" + filepath.Join(resolvedDir, "team#", "secrets.enc.yaml") +
+		"#pg.password}@localhost/sakila"
+	require.Equal(t, wantLoc, src.Location)
+}
+
 // TestCmdAdd_Placeholder_FilePassthroughForms verifies that path
 // forms the file resolver already accepts (absolute, ~/) are
 // preserved verbatim — absolutizing them would harm portability
@@ -113,3 +113,41 @@ func TestCmdCacheClear_Placeholder_UnresolvableSecret(t *testing.T) {
 // covered at the files level by TestFiles_CacheClearSourceAll_*: such
 // nesting can no longer be created via Collection.Add or sq mv, but may
 // exist in legacy or hand-edited configs.
+
+// TestCmdSLQ_ResultCache verifies that query results are cached when
+// cache.result.ttl is set, that the cached results are invalidated when a
+// document source changes, and that they're cleared by "sq cache clear".
+func TestCmdSLQ_ResultCache(t *testing.T) {
+	const handle = "@csv_rescache"
+	th := testh.New(t)
+	fp := filepath.Join(t.TempDir(), "data.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("name,age\nalice,30\n"), 0o600))
+	src := source.Source{Handle: handle, Type: drivertype.CSV, Location: fp}
+
+	tr := testrun.New(th.Context, t, nil).Add(src)
+	resultsDir := func() string {
+		dir, err := tr.Run.Files.CacheDirFor(&src)
+		require.NoError(t, err)
+		return filepath.Join(dir, "results")
+	}
+
+	require.NoError(t, tr.Exec("--no-cache", "--cache-ttl", "1h", "--csv", handle+".data"))
+	require.NoDirExists(t, resultsDir(), "--no-cache should bypass the result cache")
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--cache-ttl", "1h", "--csv", "-H", handle+".data"))
+	require.Equal(t, [][]string{{"alice", "30"}}, tr.BindCSV())
+	entries, err := os.ReadDir(resultsDir())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, os.WriteFile(fp, []byte("name,age\nbob,40\n"), 0o600))
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--cache-ttl", "1h", "--csv", "-H", handle+".data"))
+	require.Equal(t, [][]string{{"bob", "40"}}, tr.BindCSV(),
+		"changed document should invalidate cached results")
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("cache", "clear", handle))
+	require.NoDirExists(t, resultsDir())
+}
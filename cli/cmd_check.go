@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/check"
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+)
+
+func newCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check RULES_FILE",
+		Args:  cobra.ExactArgs(1),
+		Short: "Check data quality rules",
+		Long: `Check data quality: evaluate each rule of a YAML rules file against
+sources, and print whether each rule passes or fails. For example:
+
+  rules:
+    - type: not_null
+      table: "@sakila.actor"
+      column: first_name
+    - type: unique
+      table: "@sakila.customer"
+      column: email
+    - name: valid ratings
+      type: accepted_values
+      table: "@sakila.film"
+      column: rating
+      values: [G, PG, PG-13, R, NC-17]
+    - type: regex
+      table: "@sakila.customer"
+      column: email
+      pattern: '^[^@]+@[^@]+\.[a-z]+$'
+    - type: range
+      table: "@sakila.payment"
+      column: amount
+      min: 0
+      max: 100
+    - type: row_count
+      table: "@sakila.actor"
+      min: 1
+    - type: relationship
+      table: "@sakila.payment"
+      column: customer_id
+      ref: "@crm.customer"
+      ref_column: id
+    - name: no future payments
+      type: query
+      query: '@sakila.payment | where(.payment_date > "2030-01-01")'
+
+The rule types are:
+
+  not_null         the column has no null values
+  unique           the column's non-null values are unique
+  accepted_values  each non-null value is one of "values"
+  regex            each non-null value matches "pattern" (Go regexp syntax)
+  range            each value is within "min" and "max" (either may be omitted)
+  row_count        the table's row count is within "min" and "max"
+  relationship     each non-null value is present in "ref_column" of the
+                   "ref" table, which may be in a different source
+  query            the SLQ query returns zero rows
+
+A table is specified as @HANDLE.TABLE, or as .TABLE for the active source.
+Each rule is evaluated by executing SLQ queries against its sources, which
+return only the violating values. For a failed rule, the number of
+violations, and a sample of the violating values, are reported.
+
+A regex pattern is evaluated by the database if it supports regular
+expressions (Postgres, DuckDB, ClickHouse), in which case the syntax may
+differ in the details from Go's: e.g. Postgres uses POSIX regular
+expressions. Otherwise, every distinct value is returned, and the pattern
+is evaluated by sq.
+
+Use --junit to also write a JUnit XML report, for consumption by CI systems.
+
+Exit status is 0 if every rule passes, 1 if any rule fails, and 2 if a rule
+couldn't be evaluated, or on any other error.`,
+		RunE: execCheck,
+		Example: `  # Check the rules in rules.yml
+  $ sq check rules.yml
+
+  # Show more detail, including sample violations
+  $ sq check -v rules.yml
+
+  # Output results as JSON
+  $ sq check --json rules.yml
+
+  # Also write a JUnit XML report for CI
+  $ sq check rules.yml --junit check-report.xml`,
+	}
+
+	cmd.Flags().String(flag.CheckJUnit, "", flag.CheckJUnitUsage)
+
+	addTextFormatFlags(cmd)
+	cmd.Flags().BoolP(flag.JSON, flag.JSONShort, false, flag.JSONUsage)
+	addOptionFlag(cmd.Flags(), OptCompact)
+	cmd.Flags().BoolP(flag.YAML, flag.YAMLShort, false, flag.YAMLUsage)
+	return cmd
+}
+
+func execCheck(cmd *cobra.Command, args []string) (err error) {
+	ctx := cmd.Context()
+	ru, log := run.FromContext(ctx), lg.FromContext(ctx)
+
+	var failed bool
+	defer func() {
+		// As for "sq diff": exit status is 0 if all rules pass, 1 if any
+		// rule fails, and 2 on trouble.
+		switch {
+		case err != nil:
+			err = errz.WithExitCode(err, 2)
+		case failed:
+			err = errz.WithExitCode(errz.ErrNoMsg, 1)
+		}
+	}()
+
+	rules, err := check.LoadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	results, err := check.Evaluate(ctx, ru, rules)
+	if err != nil {
+		return err
+	}
+
+	if err = ru.Writers.Check.Results(results); err != nil {
+		return err
+	}
+
+	if fpath, _ := cmd.Flags().GetString(flag.CheckJUnit); fpath != "" {
+		var f *os.File
+		if f, err = os.Create(fpath); err != nil {
+			return errz.Wrap(err, "create JUnit report")
+		}
+		err = check.WriteJUnit(f, filepath.Base(args[0]), started, results)
+		lg.WarnIfCloseError(log, lgm.CloseFileWriter, f)
+		if err != nil {
+			return errz.Wrap(err, "write JUnit report")
+		}
+		log.Debug("Wrote JUnit report", lga.Path, fpath)
+	}
+
+	for _, res := range results {
+		switch res.Status {
+		case output.CheckStatusError:
+			return errz.ErrNoMsg
+		case output.CheckStatusFail:
+			failed = true
+		}
+	}
+	return nil
+}
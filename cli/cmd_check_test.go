@@ -0,0 +1,151 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+func TestCmdCheck(t *testing.T) {
+	th := testh.New(t)
+	dir := t.TempDir()
+	people := filepath.Join(dir, "people.csv")
+	require.NoError(t, os.WriteFile(people,
+		[]byte("name,age,city\nalice,30,NYC\nbob,,SF\ncarol,40,LA\ndave,25,NYC\n"), 0o600))
+	cities := filepath.Join(dir, "cities.csv")
+	require.NoError(t, os.WriteFile(cities, []byte("code,name,pop\nNYC,New York,8336\nSF,San Francisco,808\n"), 0o600))
+
+	srcs := []source.Source{
+		{Handle: "@check_people", Type: drivertype.CSV, Location: people},
+		{Handle: "@check_cities", Type: drivertype.CSV, Location: cities},
+	}
+
+	testCases := []struct {
+		name       string
+		rules      string
+		wantStatus []string
+		wantFails  []int64
+		// wantExit is the wanted exit code, or zero for no error.
+		wantExit int
+	}{
+		{
+			name: "pass",
+			rules: `rules:
+  - type: not_null
+    table: "@check_people.data"
+    column: name
+  - type: unique
+    table: "@check_people.data"
+    column: name
+  - type: row_count
+    table: "@check_people.data"
+    min: 1
+    max: 10
+  - type: query
+    query: '@check_people.data | where(.age > 100)'
+`,
+			wantStatus: []string{output.CheckStatusPass, output.CheckStatusPass, output.CheckStatusPass, output.CheckStatusPass},
+			wantFails:  []int64{0, 0, 0, 0},
+			wantExit:   0,
+		},
+		{
+			name: "fail",
+			rules: `rules:
+  - type: not_null
+    table: "@check_people.data"
+    column: age
+  - type: unique
+    table: "@check_people.data"
+    column: city
+  - type: accepted_values
+    table: "@check_people.data"
+    column: city
+    values: [NYC, SF]
+  - type: regex
+    table: "@check_people.data"
+    column: name
+    pattern: '^[a-c]'
+  - type: range
+    table: "@check_people.data"
+    column: age
+    min: 26
+    max: 35
+  - type: relationship
+    table: "@check_people.data"
+    column: city
+    ref: "@check_cities.data"
+    ref_column: code
+`,
+			wantStatus: []string{
+				output.CheckStatusFail, output.CheckStatusFail, output.CheckStatusFail,
+				output.CheckStatusFail, output.CheckStatusFail, output.CheckStatusFail,
+			},
+			wantFails: []int64{1, 1, 1, 1, 2, 1},
+			wantExit:  1,
+		},
+		{
+			name: "error",
+			rules: `rules:
+  - type: not_null
+    table: "@check_people.data"
+    column: nope
+  - type: not_null
+    table: "@check_people.data"
+    column: name
+`,
+			wantStatus: []string{output.CheckStatusError, output.CheckStatusPass},
+			wantFails:  []int64{0, 0},
+			wantExit:   2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rulesFile := filepath.Join(t.TempDir(), "rules.yml")
+			require.NoError(t, os.WriteFile(rulesFile, []byte(tc.rules), 0o600))
+			junitFile := filepath.Join(t.TempDir(), "junit.xml")
+
+			tr := testrun.New(th.Context, t, nil).Add(srcs...)
+			err := tr.Exec("check", "--json", "--junit", junitFile, rulesFile)
+
+			var results []output.CheckResult
+			tr.Bind(&results)
+			require.Len(t, results, len(tc.wantStatus))
+			for i := range results {
+				require.Equal(t, tc.wantStatus[i], results[i].Status, results[i].Name)
+				require.Equal(t, tc.wantFails[i], results[i].Failures, results[i].Name)
+			}
+
+			if tc.wantExit == 0 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, tc.wantExit, errz.ExitCode(err))
+			}
+
+			data, err := os.ReadFile(junitFile)
+			require.NoError(t, err)
+			require.Contains(t, string(data), "<testsuites")
+		})
+	}
+}
+
+func TestCmdCheck_InvalidRules(t *testing.T) {
+	th := testh.New(t)
+	rulesFile := filepath.Join(t.TempDir(), "rules.yml")
+	require.NoError(t, os.WriteFile(rulesFile, []byte("rules:\n  - type: bogus\n    table: .data\n    column: x\n"), 0o600))
+
+	tr := testrun.New(th.Context, t, nil)
+	err := tr.Exec("check", rulesFile)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bogus")
+}
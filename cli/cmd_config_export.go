@@ -16,6 +16,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/ioz"
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/source"
 )
 
 func newConfigExportCmd() *cobra.Command {
@@ -90,13 +91,19 @@ func execConfigExport(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return errz.Wrap(err, "config export")
 	}
-	data := []byte(rendered)
+	return writeExport(cmd, ru, []byte(rendered), "config export")
+}
 
+// writeExport writes data, the output of an export command such as
+// "sq config export", to ru.Out, or to the --output file if specified. The
+// --output file is created with mode 0600, since the export may contain
+// credentials. Arg what is the command name, used in error messages.
+func writeExport(cmd *cobra.Command, ru *run.Run, data []byte, what string) error {
 	if !cmdFlagChanged(cmd, flag.FileOutput) {
 		// Write through ru.Out (colorable-wrapped) so ANSI color codes
 		// render correctly on Windows consoles too.
-		if _, err = ru.Out.Write(data); err != nil {
-			return errz.Wrap(err, "config export: write")
+		if _, err := ru.Out.Write(data); err != nil {
+			return errz.Wrap(err, what+": write")
 		}
 		return nil
 	}
@@ -106,7 +113,7 @@ func execConfigExport(cmd *cobra.Command, _ []string) error {
 		return errz.Err(err)
 	}
 	if fpath = strings.TrimSpace(fpath); fpath == "" {
-		return errz.Errorf("config export: --%s is specified, but empty", flag.FileOutput)
+		return errz.Errorf("%s: --%s is specified, but empty", what, flag.FileOutput)
 	}
 
 	// Create parent dirs at 0o700 (not os.ModePerm) because the export
@@ -114,18 +121,18 @@ func execConfigExport(cmd *cobra.Command, _ []string) error {
 	// Existing parent dirs keep their permissions; MkdirAll only sets
 	// mode on dirs it creates.
 	if err = os.MkdirAll(filepath.Dir(fpath), 0o700); err != nil {
-		return errz.Wrap(err, "config export: create parent dir")
+		return errz.Wrap(err, what+": create parent dir")
 	}
 
 	if err = ioz.WriteFileAtomic(fpath, data, ioz.RWPerms); err != nil {
-		return errz.Wrap(err, "config export: write")
+		return errz.Wrap(err, what+": write")
 	}
 	return nil
 }
 
 // exportExpandConfig returns a copy of cfg with every source's Location
 // passed through ru.SecretRegistry.Expand. Collection is deep-cloned;
-// Options and Ext are shared with the input cfg (safe because only
+// Options, Queries and Ext are shared with the input cfg (safe because only
 // Collection sources are mutated). The input cfg is not mutated.
 // Resolution errors are wrapped with the source handle so the user knows
 // which source's placeholder failed.
@@ -141,6 +148,7 @@ func exportExpandConfig(ctx context.Context, ru *run.Run, cfg *config.Config) (*
 		Version:    cfg.Version,
 		Options:    cfg.Options,
 		Collection: cfg.Collection.Clone(),
+		Queries:    cfg.Queries,
 		Ext:        cfg.Ext,
 	}
 
@@ -148,16 +156,26 @@ func exportExpandConfig(ctx context.Context, ru *run.Run, cfg *config.Config) (*
 		return clone, nil
 	}
 
-	for _, src := range clone.Collection.Sources() {
+	if err := exportExpandSources(ctx, ru, clone.Collection.Sources(), "config export"); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// exportExpandSources passes the Location of each of srcs through
+// ru.SecretRegistry.Expand, modifying srcs in place: callers must pass
+// clones. Arg what is the command name, used in error messages.
+func exportExpandSources(ctx context.Context, ru *run.Run, srcs []*source.Source, what string) error {
+	for _, src := range srcs {
 		resolved, err := ru.SecretRegistry.Expand(ctx, src.Location)
 		if err != nil {
-			return nil, errz.Wrapf(err, "config export: %s", src.Handle)
+			return errz.Wrapf(err, "%s: %s", what, src.Handle)
 		}
 		// The export is itself a config (a template), so re-escape the
 		// resolved literal: import then round-trips byte-identically.
 		// See the "Templates vs literals" section of package secret.
 		src.Location = secret.Escape(resolved)
 	}
-
-	return clone, nil
+	return nil
 }
@@ -74,7 +74,80 @@ type migratePlan struct {
 	reason string // populated when skipped
 }
 
+// migrateTarget is a secret store into which migrate relocates inline
+// credentials: the OS keyring for "sq config keyring migrate", or an
+// encrypted secrets file for "sq config sops migrate".
+type migrateTarget interface {
+	// plannedRef returns the placeholder shown for src in a plan, or
+	// empty if it isn't known until the migration is applied.
+	plannedRef(src *source.Source) string
+
+	// put stores the literal location value for src, and returns the
+	// placeholder that replaces src's Location.
+	put(ctx context.Context, src *source.Source, value string) (string, error)
+
+	// commit persists every put. It is called once, before the config
+	// is saved.
+	commit(ctx context.Context) error
+
+	// rollback undoes every put, and commit if it was called.
+	rollback(ctx context.Context)
+}
+
+var _ migrateTarget = (*keyringMigrateTarget)(nil)
+
+// keyringMigrateTarget is the migrateTarget for the OS keyring. Each put
+// writes the keyring immediately, at a freshly minted opaque ID.
+type keyringMigrateTarget struct {
+	kr      *keyring.Store
+	written []keyringMigrateEntry
+}
+
+type keyringMigrateEntry struct {
+	handle string
+	id     string
+}
+
+func (t *keyringMigrateTarget) plannedRef(*source.Source) string {
+	return ""
+}
+
+func (t *keyringMigrateTarget) put(ctx context.Context, src *source.Source, value string) (string, error) {
+	id, err := t.kr.NewID(ctx)
+	if err != nil {
+		return "", errz.Wrap(err, "mint keyring id for "+src.Handle)
+	}
+	if err = t.kr.Set(ctx, id, value); err != nil {
+		return "", errz.Wrap(err, "write keyring for "+src.Handle)
+	}
+	t.written = append(t.written, keyringMigrateEntry{handle: src.Handle, id: id})
+	return "${keyring:" + id + "}", nil
+}
+
+func (t *keyringMigrateTarget) commit(context.Context) error {
+	return nil
+}
+
+func (t *keyringMigrateTarget) rollback(ctx context.Context) {
+	for _, e := range t.written {
+		if delErr := t.kr.Delete(ctx, e.id); delErr != nil {
+			// Rollback delete failed: the keyring entry written this
+			// run may orphan. Log it so the failure is recoverable
+			// from debug output and via 'sq config keyring prune'.
+			lg.FromContext(ctx).Warn("Failed to roll back keyring entry during migrate rollback",
+				lga.Path, e.id, lga.Handle, e.handle, lga.Err, delErr)
+		}
+	}
+	t.written = nil
+}
+
 func execConfigKeyringMigrate(cmd *cobra.Command, args []string) error {
+	return execMigrate(cmd, args, &keyringMigrateTarget{kr: keyring.NewStore()})
+}
+
+// execMigrate implements the migrate commands: it relocates the inline
+// credentials of the sources selected by args (or --all) into target.
+func execMigrate(cmd *cobra.Command, args []string, target migrateTarget) error {
 	ru := run.FromContext(cmd.Context())
 	ctx := cmd.Context()
 
@@ -91,7 +164,7 @@ func execConfigKeyringMigrate(cmd *cobra.Command, args []string) error {
 
 	dryRun := cmdFlagIsSetTrue(cmd, flagMigrateDryRun)
 	if dryRun {
-		return ru.Writers.Keyring.Migrate(planRowsForReport(plans), true)
+		return ru.Writers.Keyring.Migrate(planRowsForReport(plans, target), true)
 	}
 
 	// Non-dry-run: print the plan in text mode so the user can see what
@@ -100,7 +173,7 @@ func execConfigKeyringMigrate(cmd *cobra.Command, args []string) error {
 	if outputFormatIsJSON(ru) {
 		// JSON: skip preview, skip confirmation prompt, apply directly.
 		// JSON callers are non-interactive; --yes is implied.
-		rows, err := applyMigratePlans(ctx, ru, plans)
+		rows, err := applyMigratePlans(ctx, ru, plans, target)
 		writerErr := ru.Writers.Keyring.Migrate(rows, false)
 		if err != nil {
 			return err
@@ -117,10 +190,10 @@ func execConfigKeyringMigrate(cmd *cobra.Command, args []string) error {
 		}
 	}
 	if actionable == 0 {
-		return ru.Writers.Keyring.Migrate(planRowsForReport(plans), true)
+		return ru.Writers.Keyring.Migrate(planRowsForReport(plans, target), true)
 	}
 
-	if err := ru.Writers.Keyring.Migrate(planRowsForReport(plans), true); err != nil {
+	if err := ru.Writers.Keyring.Migrate(planRowsForReport(plans, target), true); err != nil {
 		return err
 	}
 
@@ -136,7 +209,7 @@ func execConfigKeyringMigrate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	rows, applyErr := applyMigratePlans(ctx, ru, plans)
+	rows, applyErr := applyMigratePlans(ctx, ru, plans, target)
 	if writerErr := ru.Writers.Keyring.Migrate(rows, false); writerErr != nil && applyErr == nil {
 		return writerErr
 	}
@@ -165,7 +238,7 @@ func buildMigratePlans(srcs []*source.Source) []migratePlan {
 // planRowsForReport converts plans into writer rows for a dry-run /
 // pre-apply preview: each plan is either a "skip" with a reason or a
 // "planned" migration.
-func planRowsForReport(plans []migratePlan) []output.KeyringMigrateRow {
+func planRowsForReport(plans []migratePlan, target migrateTarget) []output.KeyringMigrateRow {
 	rows := make([]output.KeyringMigrateRow, 0, len(plans))
 	for _, p := range plans {
 		if p.reason != "" {
@@ -177,46 +250,39 @@ func planRowsForReport(plans []migratePlan) []output.KeyringMigrateRow {
 			continue
 		}
 		rows = append(rows, output.KeyringMigrateRow{
-			Handle: p.src.Handle,
-			Status: output.KeyringMigrateStatusPlanned,
+			Handle:      p.src.Handle,
+			Status:      output.KeyringMigrateStatusPlanned,
+			NewLocation: target.plannedRef(p.src),
 		})
 	}
 	return rows
 }
 
-// applyMigratePlans performs the migration atomically. It writes every
-// eligible source's keyring entry and rewrites its Location in memory,
-// then saves the config exactly once. If any step fails (minting an ID,
-// writing the keyring, or the single config save), the whole batch is
-// rolled back: every keyring entry written this run is deleted, every
-// Location is restored, and the config is left untouched. So a run is
-// all-or-nothing; a failure migrates no sources. Skipped plans don't
-// appear in the result because they were already reported during the
-// plan phase.
-func applyMigratePlans(ctx context.Context, ru *run.Run, plans []migratePlan) (
+// applyMigratePlans performs the migration atomically. It stores every
+// eligible source's location in target and rewrites its Location in
+// memory, then commits target and saves the config exactly once. If any
+// step fails (writing to target, committing it, or the single config
+// save), the whole batch is rolled back: every target write made this run
+// is undone, every Location is restored, and the config is left
+// untouched. So a run is all-or-nothing; a failure migrates no sources.
+// Skipped plans don't appear in the result because they were already
+// reported during the plan phase.
+func applyMigratePlans(ctx context.Context, ru *run.Run, plans []migratePlan, target migrateTarget) (
 	[]output.KeyringMigrateRow, error,
 ) {
-	kr := keyring.NewStore()
-
-	// done tracks each source whose keyring entry was written and Location
-	// rewritten in memory, so a later failure can roll the whole batch back.
+	// done tracks each source whose location was written to target and
+	// Location rewritten in memory, so a later failure can roll the whole
+	// batch back.
 	type applied struct {
 		src *source.Source
-		id  string
 		old string
 	}
 	var done []applied
 
 	rollbackAll := func() {
+		target.rollback(ctx)
 		for _, a := range done {
 			a.src.Location = a.old
-			if delErr := kr.Delete(ctx, a.id); delErr != nil {
-				// Rollback delete failed: the keyring entry written this
-				// run may orphan. Log it so the failure is recoverable
-				// from debug output and via 'sq config keyring prune'.
-				lg.FromContext(ctx).Warn("Failed to roll back keyring entry during migrate rollback",
-					lga.Path, a.id, lga.Handle, a.src.Handle, lga.Err, delErr)
-			}
 		}
 	}
 
@@ -245,22 +311,19 @@ func applyMigratePlans(ctx context.Context, ru *run.Run, plans []migratePlan) (
 		if p.reason != "" {
 			continue
 		}
-		id, err := kr.NewID(ctx)
-		if err != nil {
-			return failAll("mint keyring id for " + p.src.Handle + ": " + err.Error())
-		}
 		// The stored Location is a placeholder template in which '$$'
 		// escapes a literal '$' (e.g. written by the v0.54.0 config
-		// upgrade). The keyring slot holds a literal value that
+		// upgrade). The target holds a literal value that
 		// Registry.Expand splices raw at connect time, so unescape
 		// here; storing the template bytes verbatim would hand the
 		// driver a wrong (still-escaped) credential. Safe because
 		// migrateSkipReason guarantees zero placeholder refs.
-		if err = kr.Set(ctx, id, secret.Unescape(p.src.Location)); err != nil {
-			return failAll("write keyring for " + p.src.Handle + ": " + err.Error())
+		ref, err := target.put(ctx, p.src, secret.Unescape(p.src.Location))
+		if err != nil {
+			return failAll(err.Error())
 		}
-		done = append(done, applied{src: p.src, id: id, old: p.src.Location})
-		p.src.Location = "${keyring:" + id + "}"
+		done = append(done, applied{src: p.src, old: p.src.Location})
+		p.src.Location = ref
 	}
 
 	if len(done) == 0 {
@@ -270,6 +333,10 @@ func applyMigratePlans(ctx context.Context, ru *run.Run, plans []migratePlan) (
 		return nil, nil
 	}
 
+	if err := target.commit(ctx); err != nil {
+		return failAll(err.Error())
+	}
+
 	if err := ru.ConfigStore.Save(ctx, ru.Config); err != nil {
 		return failAll("save config: " + err.Error())
 	}
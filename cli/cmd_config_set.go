@@ -121,6 +121,9 @@ func execConfigSet(cmd *cobra.Command, args []string) error {
 	if o2, err = opt.Process(o2); err != nil {
 		return err
 	}
+	if err = absolutizeIngestFiles(ru.OptionsRegistry, o2); err != nil {
+		return err
+	}
 
 	o[opt.Key()] = o2[opt.Key()]
 	if err = ru.ConfigStore.Save(ctx, ru.Config); err != nil {
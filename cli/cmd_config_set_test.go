@@ -2,6 +2,8 @@ package cli_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +13,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/testh"
 	"github.com/neilotoole/sq/testh/proj"
 	"github.com/neilotoole/sq/testh/sakila"
@@ -72,6 +75,47 @@ func TestConfigSetBaseOptOnSourceIsError(t *testing.T) {
 	require.Error(t, err, "should NOT work for source config")
 }
 
+// TestCmdConfigSet_IngestSchemaAbsolutized verifies that a relative
+// ingest schema file path, or masking policy file path, is made absolute
+// when set, so that the file is found regardless of the dir that sq is
+// later invoked from.
+func TestCmdConfigSet_IngestSchemaAbsolutized(t *testing.T) {
+	const handle = "@actor"
+	dir := t.TempDir()
+	pwd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Chdir(pwd) })
+	require.NoError(t, os.Chdir(dir))
+	resolvedDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil).Hush()
+	require.NoError(t, tr.Exec("add", proj.Abs("drivers/csv/testdata/sakila-csv/actor.csv"), "--handle="+handle))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--"+flag.ConfigSrc, handle,
+		driver.OptIngestSchema.Key(), "./actor.schema.yml"))
+	src, err := tr.Run.Config.Collection.Get(handle)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(resolvedDir, "actor.schema.yml"), driver.OptIngestSchema.Get(src.Options))
+
+	// An inline schema is left as is.
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--"+flag.ConfigSrc, handle,
+		driver.OptIngestSchema.Key(), "actor_id:text"))
+	src, err = tr.Run.Config.Collection.Get(handle)
+	require.NoError(t, err)
+	require.Equal(t, "actor_id:text", driver.OptIngestSchema.Get(src.Options))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--"+flag.ConfigSrc, handle,
+		mask.OptPolicy.Key(), "mask-policy.yml"))
+	src, err = tr.Run.Config.Collection.Get(handle)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(resolvedDir, "mask-policy.yml"), mask.OptPolicy.Get(src.Options))
+}
+
 // TestSourceOptOverridesBaseOpt tests that source-specific opts override base opts.
 func TestSourceOptOverridesBaseOpt(t *testing.T) {
 	const handle = "@actor"
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newConfigSOPSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sops",
+		Args:  cobra.NoArgs,
+		Short: "Manage encrypted secrets files used by source secrets",
+		Long: `Manage encrypted secrets files that source locations reference via
+${sops:<file>#<key.path>} placeholders.
+
+A ${sops:...} placeholder resolves to the value at the dotted key path of
+an encrypted YAML or JSON file. Two file formats are supported:
+
+  SOPS      A file encrypted by the sops CLI with the age backend. sq
+            reads these, but never writes them: edit them with sops.
+  age       A YAML or JSON file encrypted as a whole with age. This is
+            the format written by 'sq config sops migrate'.
+
+Either way, the file is safe to commit to git. The age key is read from
+the SOPS_AGE_KEY env var, the file named by SOPS_AGE_KEY_FILE, or the sops
+default key file (e.g. ~/.config/sops/age/keys.txt), so a CI runner
+needs only the key, not an OS keyring.
+
+Examples of placeholder forms in a source's Location:
+
+  location: postgres://alice:${sops:/work/proj/secrets.enc.yaml#pg.password}@db/sakila
+  location: ${sops:/work/proj/secrets.yaml.age#sakila/pg.dsn}`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		Example: `  # Move inline credentials into an age-encrypted secrets file
+  $ sq config sops migrate ./secrets.yaml.age --all`,
+	}
+	return cmd
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/secret/sops"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+const flagSOPSRecipient = "recipient"
+
+func newConfigSOPSMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate FILE [@HANDLE]",
+		Args:  cobra.RangeArgs(1, 2),
+		Short: "Migrate inline-credential sources to an encrypted secrets file",
+		Long: `For each source (or one specified by handle), write its
+Location URL to the age-encrypted secrets FILE, at key "<handle>.dsn", and
+replace the Location with a ${sops:<FILE>#<handle>.dsn} placeholder. The
+driver type stays in the driver: field; the file entry holds the entire
+DSN. FILE is created if it doesn't exist, and any existing entries in it
+are preserved. FILE must be an age-encrypted file: SOPS files are
+read-only to sq.
+
+FILE is encrypted to the age recipients given by --recipient, or else by
+the SOPS_AGE_RECIPIENTS env var (comma-separated), or else to the public
+keys of the identities in the age key file, so that sq can read it back.
+
+Sources skipped automatically:
+  - Non-URL locations (file paths, sqlite, Excel, etc.)
+  - URLs with no password component
+  - Locations that already contain a ${...} placeholder
+
+Use --dry-run to preview without making any changes. Use --yes to skip
+the confirmation prompt.`,
+		RunE:              execConfigSOPSMigrate,
+		ValidArgsFunction: completeSOPSMigrate,
+		Example: `  # Preview the migration
+  $ sq config sops migrate ./secrets.yaml.age --all --dry-run
+
+  # Migrate every source without prompting, encrypting to two recipients
+  $ sq config sops migrate ./secrets.yaml.age --all --yes \
+      --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p \
+      --recipient age1lggyhqrw2nlhcxprm67z43rta597azn8gknawjehu9d9dl0jq3yqqvfafg
+
+  # Migrate a single source
+  $ sq config sops migrate ./secrets.yaml.age @sakila`,
+	}
+	cmd.Flags().Bool(flagMigrateAll, false, "Migrate every source")
+	cmd.Flags().Bool(flagMigrateDryRun, false, "Show planned changes, make no writes")
+	cmd.Flags().Bool(flagMigrateYes, false, "Skip the confirmation prompt")
+	cmd.Flags().StringArray(flagSOPSRecipient, nil, "Encrypt to this age recipient (repeatable)")
+	addKeyringFormatFlags(cmd)
+	cmdMarkRequiresConfigLock(cmd)
+	return cmd
+}
+
+// completeSOPSMigrate completes the FILE arg with file names, and the
+// optional second arg with source handles.
+func completeSOPSMigrate(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completeHandle(2, true)(cmd, args, toComplete)
+}
+
+func execConfigSOPSMigrate(cmd *cobra.Command, args []string) error {
+	fp, err := filepath.Abs(args[0])
+	if err != nil {
+		return errz.Err(err)
+	}
+	if strings.ContainsAny(fp, "#}") {
+		// Neither can be expressed in a ${sops:<file>#<key>} placeholder.
+		return errz.Errorf("secrets file path must not contain '#' or '}': %s", fp)
+	}
+
+	recips, err := cmd.Flags().GetStringArray(flagSOPSRecipient)
+	if err != nil {
+		return errz.Err(err)
+	}
+
+	target, err := newSOPSMigrateTarget(fp, recips)
+	if err != nil {
+		return err
+	}
+	return execMigrate(cmd, args[1:], target)
+}
+
+var _ migrateTarget = (*sopsMigrateTarget)(nil)
+
+// sopsMigrateTarget is the migrateTarget for an age-encrypted secrets
+// file. Puts accumulate in memory; commit writes the file once.
+type sopsMigrateTarget struct {
+	doc        map[string]any
+	fp         string
+	orig       []byte // fp's contents before commit; nil if fp didn't exist
+	recipients []age.Recipient
+	committed  bool
+}
+
+// newSOPSMigrateTarget loads the existing secrets file at fp, if any, so
+// that its entries are preserved.
+func newSOPSMigrateTarget(fp string, recips []string) (*sopsMigrateTarget, error) {
+	recipients, err := sops.LoadRecipients(recips)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := sops.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	return &sopsMigrateTarget{fp: fp, doc: doc, recipients: recipients}, nil
+}
+
+// key returns the key path under which src's location is stored.
+func (t *sopsMigrateTarget) key(src *source.Source) string {
+	return strings.TrimPrefix(src.Handle, "@") + ".dsn"
+}
+
+func (t *sopsMigrateTarget) ref(src *source.Source) string {
+	return "${sops:" + t.fp + "#" + t.key(src) + "}"
+}
+
+func (t *sopsMigrateTarget) plannedRef(src *source.Source) string {
+	return t.ref(src)
+}
+
+func (t *sopsMigrateTarget) put(_ context.Context, src *source.Source, value string) (string, error) {
+	if err := sops.SetValue(t.doc, t.key(src), value); err != nil {
+		return "", errz.Wrap(err, "write secrets file entry for "+src.Handle)
+	}
+	return t.ref(src), nil
+}
+
+func (t *sopsMigrateTarget) commit(context.Context) error {
+	orig, err := os.ReadFile(t.fp)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errz.Wrap(err, "read secrets file")
+	}
+	t.orig = orig
+	if err = sops.WriteFile(t.fp, t.doc, t.recipients); err != nil {
+		return errz.Wrap(err, "write secrets file")
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *sopsMigrateTarget) rollback(context.Context) {
+	if !t.committed {
+		return
+	}
+	t.committed = false
+	if t.orig == nil {
+		_ = os.Remove(t.fp)
+		return
+	}
+	// Best effort: restore the file's previous ciphertext verbatim.
+	_ = os.WriteFile(t.fp, t.orig, 0o600)
+}
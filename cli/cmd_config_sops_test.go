@@ -0,0 +1,143 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/libsq/core/secret/sops"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// setupSOPSKey exposes a fresh age identity to the sops resolver via
+// SOPS_AGE_KEY.
+func setupSOPSKey(t *testing.T) {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Setenv(sops.EnvAgeKey, id.String())
+	t.Setenv(sops.EnvAgeKeyFile, "")
+	t.Setenv(sops.EnvAgeRecipients, "")
+}
+
+// TestCmdConfigSOPSMigrate verifies that migrate moves inline credentials
+// into the encrypted secrets file, rewrites the Location to a ${sops:...}
+// placeholder that resolves to the original DSN, preserves existing file
+// entries, and skips sources without credentials.
+func TestCmdConfigSOPSMigrate(t *testing.T) {
+	setupSOPSKey(t)
+	fp := filepath.Join(t.TempDir(), "secrets.yaml.age")
+
+	// Seed the file with an unrelated entry, which must survive.
+	doc, err := sops.ReadFile(fp)
+	require.NoError(t, err)
+	require.NoError(t, sops.SetValue(doc, "other.key", "keep me"))
+	rcps, err := sops.LoadRecipients(nil)
+	require.NoError(t, err)
+	require.NoError(t, sops.WriteFile(fp, doc, rcps))
+
+	th := testh.New(t)
+	tr := testrun.New(th.Context, t, nil).Add(
+		source.Source{
+			Handle:   "@sakila/pg",
+			Type:     drivertype.Pg,
+			Location: "postgres://alice:hunter2@db/sakila",
+		},
+		source.Source{
+			Handle:   "@nopw",
+			Type:     drivertype.Pg,
+			Location: "postgres://alice@db/sakila",
+		},
+	)
+
+	require.NoError(t, tr.Exec("config", "sops", "migrate", fp, "--all", "--json"))
+
+	var got struct {
+		Rows []struct {
+			Handle      string `json:"handle"`
+			Status      string `json:"status"`
+			NewLocation string `json:"new_location"`
+		} `json:"rows"`
+	}
+	require.NoError(t, json.Unmarshal(tr.Out.Bytes(), &got))
+	require.Len(t, got.Rows, 1)
+	require.Equal(t, "@sakila/pg", got.Rows[0].Handle)
+	require.Equal(t, "migrated", got.Rows[0].Status)
+
+	wantLoc := "${sops:" + fp + "#sakila/pg.dsn}"
+	require.Equal(t, wantLoc, got.Rows[0].NewLocation)
+
+	src, err := tr.Run.Config.Collection.Get("@sakila/pg")
+	require.NoError(t, err)
+	require.Equal(t, wantLoc, src.Location)
+	src, err = tr.Run.Config.Collection.Get("@nopw")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://alice@db/sakila", src.Location)
+
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "hunter2")
+
+	r := sops.NewResolver()
+	dsn, err := r.Resolve(th.Context, fp+"#sakila/pg.dsn")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://alice:hunter2@db/sakila", dsn)
+	other, err := r.Resolve(th.Context, fp+"#other.key")
+	require.NoError(t, err)
+	require.Equal(t, "keep me", other)
+}
+
+// TestCmdConfigSOPSMigrate_DryRun verifies that a dry run reports the
+// target placeholder, and writes neither the file nor the config.
+func TestCmdConfigSOPSMigrate_DryRun(t *testing.T) {
+	setupSOPSKey(t)
+	fp := filepath.Join(t.TempDir(), "secrets.yaml.age")
+
+	th := testh.New(t)
+	const origLoc = "postgres://alice:hunter2@db/sakila"
+	tr := testrun.New(th.Context, t, nil).Add(source.Source{
+		Handle:   "@dry",
+		Type:     drivertype.Pg,
+		Location: origLoc,
+	})
+
+	require.NoError(t, tr.Exec("config", "sops", "migrate", fp, "@dry", "--dry-run"))
+	require.Contains(t, tr.Out.String(), "${sops:"+fp+"#dry.dsn}")
+
+	require.NoFileExists(t, fp)
+	src, err := tr.Run.Config.Collection.Get("@dry")
+	require.NoError(t, err)
+	require.Equal(t, origLoc, src.Location)
+}
+
+// TestCmdConfigSOPSMigrate_RollbackOnSaveFailure verifies that a failed
+// config save removes the secrets file written this run.
+func TestCmdConfigSOPSMigrate_RollbackOnSaveFailure(t *testing.T) {
+	setupSOPSKey(t)
+	fp := filepath.Join(t.TempDir(), "secrets.yaml.age")
+
+	th := testh.New(t)
+	const origLoc = "postgres://alice:hunter2@db/sakila"
+	tr := testrun.New(th.Context, t, nil).Add(source.Source{
+		Handle:   "@rb",
+		Type:     drivertype.Pg,
+		Location: origLoc,
+	})
+	tr.Run.ConfigStore = &failingConfigStore{underlying: tr.Run.ConfigStore}
+
+	err := tr.Exec("config", "sops", "migrate", fp, "--all", "--yes", "--json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no sources were changed")
+	require.NoFileExists(t, fp)
+
+	src, err := tr.Run.Config.Collection.Get("@rb")
+	require.NoError(t, err)
+	require.Equal(t, origLoc, src.Location)
+}
@@ -13,12 +13,42 @@ import (
 	"github.com/neilotoole/sq/libsq/core/errz"
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/core/termz"
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/metadata"
 )
 
+var OptInspectProfileTop = options.NewInt(
+	"inspect.profile.top",
+	nil,
+	5,
+	"Number of most frequent values per column for --profile",
+	`Number of most frequent values reported for each column by "sq inspect
+--profile". If zero, frequent values are not reported.`,
+)
+
+var OptInspectProfileHistogram = options.NewInt(
+	"inspect.profile.histogram",
+	nil,
+	0,
+	"Number of histogram bins per numeric column for --profile",
+	`Number of histogram bins reported for each numeric column by "sq inspect
+--profile". If zero, histograms are not reported.`,
+)
+
+var OptInspectProfileApprox = options.NewBool(
+	"inspect.profile.approx",
+	nil,
+	false,
+	"Approximate distinct counts for --profile",
+	`Approximate the distinct count of each column for "sq inspect --profile",
+using the database's HyperLogLog aggregate, if it has one. This is much
+cheaper for large tables. A database without such an aggregate computes
+the exact count.`,
+)
+
 func newInspectCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:  "inspect [@HANDLE|@HANDLE.TABLE|.TABLE]",
@@ -48,6 +78,16 @@ is supplied, the default is to show the source metadata and schema.
 
   --schemata:  List the schemas available in the source's active catalog.
 
+Use --profile to compute per-column statistics for the table, or for each
+table of the source: null count and percentage, distinct count, min/max,
+mean/stddev for numeric columns, min/max length for text columns, and the
+most frequent values. The statistics are computed via SQL aggregates executed
+by the database. Use --inspect.profile.top to set the number of frequent
+values, --inspect.profile.histogram to add a histogram of each numeric
+column, and --inspect.profile.approx to approximate distinct counts
+(HyperLogLog) where the database supports it. Profiling a large source can
+be slow.
+
 Use --verbose with --text format to see more detail. The --json and --yaml
 formats both show extensive detail. The --markdown and --html formats each
 render a schema document that includes a Mermaid entity-relationship diagram;
@@ -76,6 +116,9 @@ render a schema document that includes a Mermaid entity-relationship diagram;
   # Write the HTML schema doc to a file instead of stdout.
   $ sq inspect --html @pg1 -o pg1-schema.html
 
+  # Output the schema ER diagram as DBML (also: plantuml-erd, d2-erd).
+  $ sq inspect -f dbml @pg1
+
   # Show only the DB properties for @pg1.
   $ sq inspect --dbprops @pg1
 
@@ -94,6 +137,12 @@ render a schema document that includes a Mermaid entity-relationship diagram;
   # Inspect "actor" in active data source.
   $ sq inspect .actor
 
+  # Profile the columns of table "actor" in @pg1.
+  $ sq inspect --profile @pg1.actor
+
+  # Profile each table, with 10-bin histograms and approximate distinct counts.
+  $ sq inspect --profile --inspect.profile.histogram 10 --inspect.profile.approx @pg1
+
   # Inspect a non-default schema in source @my1.
   $ sq inspect @my1 --src.schema information_schema
 
@@ -116,6 +165,9 @@ render a schema document that includes a Mermaid entity-relationship diagram;
 			format.MermaidERD.String(),
 			format.SVGERD.String(),
 			format.PNGERD.String(),
+			format.DBML.String(),
+			format.PlantUMLERD.String(),
+			format.D2ERD.String(),
 		),
 	))
 	addTextFormatFlags(cmd)
@@ -136,6 +188,13 @@ render a schema document that includes a Mermaid entity-relationship diagram;
 
 	cmd.MarkFlagsMutuallyExclusive(flag.InspectOverview, flag.InspectDBProps, flag.InspectCatalogs, flag.InspectSchemata)
 
+	cmd.Flags().Bool(flag.InspectProfile, false, flag.InspectProfileUsage)
+	addOptionFlag(cmd.Flags(), OptInspectProfileTop)
+	addOptionFlag(cmd.Flags(), OptInspectProfileHistogram)
+	addOptionFlag(cmd.Flags(), OptInspectProfileApprox)
+	cmd.MarkFlagsMutuallyExclusive(flag.InspectProfile, flag.InspectOverview, flag.InspectDBProps,
+		flag.InspectCatalogs, flag.InspectSchemata)
+
 	cmd.Flags().String(flag.ActiveSchema, "", flag.ActiveSchemaUsage)
 	panicOn(cmd.RegisterFlagCompletionFunc(flag.ActiveSchema,
 		activeSchemaCompleter{getActiveSourceViaArgs}.complete))
@@ -210,6 +269,12 @@ func execInspect(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if cmdFlagIsSetTrue(cmd, flag.InspectProfile) {
+			if err = driver.ProfileTable(ctx, grip, tblMeta, getProfileOpts(o)); err != nil {
+				return err
+			}
+		}
+
 		return ru.Writers.Metadata.TableMetadata(tblMeta)
 	}
 
@@ -291,9 +356,27 @@ func execInspect(cmd *cobra.Command, args []string) error {
 		srcMeta.DBProperties = nil
 	}
 
+	if cmdFlagIsSetTrue(cmd, flag.InspectProfile) {
+		profOpts := getProfileOpts(o)
+		for _, tblMeta := range srcMeta.Tables {
+			if err = driver.ProfileTable(ctx, grip, tblMeta, profOpts); err != nil {
+				return err
+			}
+		}
+	}
+
 	return ru.Writers.Metadata.SourceMetadata(srcMeta, !overviewOnly)
 }
 
+// getProfileOpts returns the driver.ProfileOpts for "sq inspect --profile".
+func getProfileOpts(o options.Options) driver.ProfileOpts {
+	return driver.ProfileOpts{
+		TopN:          OptInspectProfileTop.Get(o),
+		HistogramBins: OptInspectProfileHistogram.Get(o),
+		Approx:        OptInspectProfileApprox.Get(o),
+	}
+}
+
 // errBinaryFormatToTerminal returns a guard error when fm is a binary image
 // format (png-erd) bound for a terminal without a file target: writing PNG
 // bytes to a TTY would corrupt the terminal. It returns nil for any other
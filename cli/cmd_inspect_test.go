@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"image/png"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -629,6 +630,44 @@ func TestCmdInspect_pngERD(t *testing.T) {
 	require.Positive(t, img.Bounds().Dy())
 }
 
+// TestCmdInspect_erdSource exercises the "dbml", "plantuml-erd" and
+// "d2-erd" output formats against the sakila SQLite source, for
+// whole-source and single-table inspection.
+func TestCmdInspect_erdSource(t *testing.T) { //nolint:tparallel
+	t.Parallel()
+
+	testCases := []struct {
+		fm        format.Format
+		wantStart string
+		wantRef   string
+	}{
+		{fm: format.DBML, wantStart: "Table ", wantRef: "film.language_id > language.language_id"},
+		{fm: format.PlantUMLERD, wantStart: "@startuml", wantRef: "language ||--o{ film"},
+		{fm: format.D2ERD, wantStart: "", wantRef: "film.language_id -> language.language_id"},
+	}
+
+	th := testh.New(t)
+	src := th.Source(sakila.SL3)
+	for _, tc := range testCases {
+		t.Run(tc.fm.String(), func(t *testing.T) {
+			tr := testrun.New(th.Context, t, nil).Hush().Add(*src)
+			require.NoError(t, tr.Exec("inspect", "--format="+tc.fm.String()))
+			out := tr.Out.String()
+			require.True(t, strings.HasPrefix(out, tc.wantStart))
+			require.Contains(t, out, tc.wantRef)
+
+			tr = testrun.New(th.Context, t, tr)
+			require.NoError(t, tr.Exec("inspect", src.Handle+".film_actor", "-f", tc.fm.String()))
+			require.Contains(t, tr.Out.String(), "film_actor")
+
+			tr = testrun.New(th.Context, t, tr)
+			err := tr.Exec("inspect", src.Handle, "--"+flag.InspectOverview, "-f", tc.fm.String())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.fm.String())
+		})
+	}
+}
+
 // TestErrBinaryFormatToTerminal pins the guard that refuses to write the
 // binary png-erd format to a terminal: it errors only for png-erd, only when
 // no file target is set, and only when stdout is a terminal. svg-erd (text)
@@ -1333,3 +1372,59 @@ func TestInspect_DuckDB_DoesNotModifyMtime(t *testing.T) {
 	require.Equal(t, statBefore.ModTime(), statAfter.ModTime(),
 		"DuckDB file mtime must not change after sq inspect")
 }
+
+func TestCmdInspect_Profile(t *testing.T) {
+	t.Parallel()
+
+	const handle = "@csv_profile"
+	th := testh.New(t)
+	fp := filepath.Join(t.TempDir(), "data.csv")
+	require.NoError(t, os.WriteFile(fp, []byte(
+		"name,age,city\nalice,30,NYC\nbob,,LA\ncarol,25,NYC\ndave,40,NYC\neve,30,\n"), 0o600))
+	src := source.Source{Handle: handle, Type: drivertype.CSV, Location: fp}
+
+	tr := testrun.New(th.Context, t, nil).Add(src)
+	require.NoError(t, tr.Exec("inspect", "--json", "--profile", "--inspect.profile.histogram", "3", handle+".data"))
+	tblMeta := &metadata.Table{}
+	require.NoError(t, json.Unmarshal(tr.Out.Bytes(), tblMeta))
+
+	name := tblMeta.Column("name").Profile
+	require.NotNil(t, name)
+	require.Equal(t, int64(0), name.NullCount)
+	require.Equal(t, int64(5), *name.DistinctCount)
+	require.Equal(t, "alice", name.Min)
+	require.Equal(t, "eve", name.Max)
+	require.Equal(t, int64(3), *name.MinLength)
+	require.Equal(t, int64(5), *name.MaxLength)
+	require.Empty(t, name.Top, "all values are distinct")
+
+	age := tblMeta.Column("age").Profile
+	require.NotNil(t, age)
+	require.Equal(t, int64(1), age.NullCount)
+	require.Equal(t, 20.0, age.NullPct)
+	require.Equal(t, int64(3), *age.DistinctCount)
+	require.Equal(t, 25.0, age.Min)
+	require.Equal(t, 40.0, age.Max)
+	require.Equal(t, 31.25, *age.Mean)
+	require.InDelta(t, 6.2915, *age.StdDev, 0.0001)
+	require.Equal(t, 30.0, age.Top[0].Value)
+	require.Equal(t, int64(2), age.Top[0].Count)
+	require.Len(t, age.Histogram, 3)
+	var histTotal int64
+	for _, bin := range age.Histogram {
+		histTotal += bin.Count
+	}
+	require.Equal(t, int64(4), histTotal, "histogram should count every non-null value")
+
+	city := tblMeta.Column("city").Profile
+	require.NotNil(t, city)
+	require.Equal(t, []*metadata.ValueCount{{Value: "NYC", Count: 3}, {Value: "LA", Count: 1}}, city.Top)
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("inspect", "--text", "--profile", handle))
+	require.Contains(t, tr.OutString(), "NYC (3), LA (1)")
+
+	tr = testrun.New(th.Context, t, tr)
+	err := tr.Exec("inspect", "--profile", "--overview", handle)
+	require.Error(t, err, "--profile and --overview are mutually exclusive")
+}
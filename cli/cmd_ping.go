@@ -164,8 +164,13 @@ func pingSources(ctx context.Context, dp driver.Provider, reg *secret.Registry,
 	// is returned from this func.
 	var pingErrExists bool
 
+	// The SSH tunnels (see driver.OptSSHTunnel) are shared by the sources
+	// that use the same bastion, and are torn down when all pings are done.
+	tunnels := driver.NewTunnels(reg)
+	defer lg.WarnIfFuncError(log, "Close SSH tunnels", tunnels.Close)
+
 	for _, src := range srcs {
-		go pingSource(ctx, dp, reg, src, timeout, resultCh)
+		go pingSource(ctx, dp, reg, tunnels, src, timeout, resultCh)
 	}
 
 	// This func doesn't check for context.Canceled itself; instead
@@ -210,7 +215,11 @@ func pingSources(ctx context.Context, dp driver.Provider, reg *secret.Registry,
 // resolution below), and what pingResult carries to the output
 // writers; the writer layer's expand decorator handles the --expand
 // display view.
-func pingSource(ctx context.Context, dp driver.Provider, reg *secret.Registry,
+//
+// If src uses an SSH tunnel, a failure to establish the tunnel is reported
+// as a *driver.TunnelError, distinct from a failure to reach the database
+// via the tunnel.
+func pingSource(ctx context.Context, dp driver.Provider, reg *secret.Registry, tunnels *driver.Tunnels,
 	src *source.Source, timeout time.Duration, resultCh chan<- pingResult,
 ) {
 	drvr, err := dp.DriverFor(src.Type)
@@ -248,7 +257,22 @@ func pingSource(ctx context.Context, dp driver.Provider, reg *secret.Registry,
 		// Ping is read-only by definition: connectivity check, no writes.
 		// It calls drvr.Ping directly (bypassing Grips), passing the mode
 		// as an explicit argument.
-		pingErr := drvr.Ping(ctx, resolved, driver.ModeReadOnly)
+		tunneled := resolved
+		o := options.Merge(options.FromContext(ctx), src.Options)
+		tunnel := driver.OptSSHTunnel.Get(o)
+		if tunnel != "" {
+			var err error
+			if tunneled, err = tunnels.Rewrite(ctx, resolved, o, drvr.DriverMetadata().DefaultPort); err != nil {
+				doneCh <- pingResult{src: src, duration: time.Since(start), err: err}
+				return
+			}
+		}
+
+		pingErr := drvr.Ping(ctx, tunneled, driver.ModeReadOnly)
+		if pingErr != nil && tunnel != "" {
+			// The tunnel is up, so it's the DB that's unreachable.
+			pingErr = errz.Wrapf(pingErr, "db via ssh tunnel %s", tunnel)
+		}
 		doneCh <- pingResult{src: src, duration: time.Since(start), err: pingErr}
 	}()
 
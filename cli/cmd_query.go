@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/neilotoole/shelleditor"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/config"
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+)
+
+func newQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query",
+		Args:  cobra.NoArgs,
+		Short: "Manage saved queries",
+		Long: `Manage saved queries. A saved query is a named SLQ query, stored in
+sq's config, that is executed via "sq run NAME". The query can reference
+args such as $region, whose values are supplied via --arg when the query
+is run. Default values for args can be saved with the query.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		Example: `  # Save a query
+  $ sq query add top-customers '@sakila | .customer | where(.region == $region) | .[0:10]'
+
+  # Save a query, with a description, and a default value for $region
+  $ sq query add top-customers --description "Top 10 customers" \
+    --arg region=EU '@sakila | .customer | where(.region == $region) | .[0:10]'
+
+  # List saved queries
+  $ sq query ls
+
+  # Edit a saved query in $EDITOR
+  $ sq query edit top-customers
+
+  # Remove a saved query
+  $ sq query rm top-customers
+
+  # Run a saved query
+  $ sq run top-customers --arg region=US`,
+	}
+
+	return cmd
+}
+
+func newQueryListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Args:    cobra.NoArgs,
+		Short:   "List saved queries",
+		Long: `List saved queries. Use --verbose to also print each query's SLQ.
+Each query's args are listed, along with their default values, if any.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ru := run.FromContext(cmd.Context())
+			queries := slices.Clone(ru.Config.Queries)
+			slices.SortFunc(queries, func(a, b *config.Query) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+			return ru.Writers.Query.List(queries)
+		},
+		Example: `  $ sq query ls
+  NAME           ARGS        DESCRIPTION
+  top-customers  $region=EU  Top 10 customers
+
+  # Also show the SLQ
+  $ sq query ls -v`,
+	}
+
+	addQueryFormatFlags(cmd)
+	return cmd
+}
+
+func newQueryAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add NAME SLQ",
+		Args:  cobra.ExactArgs(2),
+		Short: "Save a query",
+		Long: `Save SLQ as a named query, to be executed via "sq run NAME". The SLQ
+is validated before it is saved. Use --arg to save a default value for
+an arg referenced by the query; an arg without a default value must be
+supplied via --arg when the query is run. Use --force to overwrite an
+existing saved query of the same name.`,
+		RunE: execQueryAdd,
+		Example: `  $ sq query add actors '@sakila.actor'
+
+  # Query with an arg, and a description
+  $ sq query add actor-by-name --description "Actor by first name" \
+    '@sakila.actor | where(.first_name == $name)'
+
+  # Same as above, but with a default value for $name
+  $ sq query add actor-by-name --arg name=TOM \
+    '@sakila.actor | where(.first_name == $name)'
+
+  # Overwrite the existing "actors" query
+  $ sq query add actors --force '@sakila.actor | .[0:10]'`,
+	}
+
+	cmd.Flags().String(flag.QueryDescription, "", flag.QueryDescriptionUsage)
+	cmd.Flags().StringArray(flag.Arg, nil, flag.QueryArgUsage)
+	panicOn(cmd.RegisterFlagCompletionFunc(flag.Arg, completeNone))
+	cmd.Flags().Bool(flag.QueryForce, false, flag.QueryForceUsage)
+	addQueryFormatFlags(cmd)
+	cmdMarkRequiresConfigLock(cmd)
+	return cmd
+}
+
+func execQueryAdd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ru := run.FromContext(ctx)
+	cfg := ru.Config
+
+	mArgs, err := extractFlagArgsValues(cmd)
+	if err != nil {
+		return err
+	}
+
+	q := &config.Query{
+		Name: strings.TrimSpace(args[0]),
+		SLQ:  strings.TrimSpace(args[1]),
+		Args: mArgs,
+	}
+	q.Description, _ = cmd.Flags().GetString(flag.QueryDescription)
+
+	if err = validSavedQuery(q); err != nil {
+		return err
+	}
+
+	if i := slices.IndexFunc(cfg.Queries, func(item *config.Query) bool {
+		return item.Name == q.Name
+	}); i >= 0 {
+		if !cmdFlagIsSetTrue(cmd, flag.QueryForce) {
+			return errz.Errorf("saved query already exists: %s: use --%s to overwrite", q.Name, flag.QueryForce)
+		}
+		cfg.Queries[i] = q
+	} else {
+		cfg.Queries = append(cfg.Queries, q)
+	}
+
+	if err = ru.ConfigStore.Save(ctx, cfg); err != nil {
+		return err
+	}
+
+	return ru.Writers.Query.Query(q)
+}
+
+func newQueryRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "rm NAME [NAME...]",
+		Aliases:           []string{"remove"},
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeQueryName(0),
+		Short:             "Remove saved queries",
+		Long:              `Remove one or more saved queries.`,
+		RunE:              execQueryRemove,
+		Example: `  $ sq query rm top-customers
+
+  $ sq query rm top-customers actors`,
+	}
+
+	cmdMarkRequiresConfigLock(cmd)
+	return cmd
+}
+
+func execQueryRemove(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ru := run.FromContext(ctx)
+	cfg := ru.Config
+
+	args = lo.Uniq(args)
+	for _, name := range args {
+		if cfg.FindQuery(name) == nil {
+			return errz.Errorf("saved query not found: %s", name)
+		}
+	}
+
+	cfg.Queries = slices.DeleteFunc(cfg.Queries, func(q *config.Query) bool {
+		return slices.Contains(args, q.Name)
+	})
+
+	return ru.ConfigStore.Save(ctx, cfg)
+}
+
+func newQueryEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "edit NAME",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeQueryName(1),
+		Short:             "Edit saved query in $EDITOR",
+		Long: `Edit a saved query in the editor specified in envar $SQ_EDITOR or
+$EDITOR. The edited query is validated before it is saved.`,
+		RunE: execQueryEdit,
+		Example: `  $ sq query edit top-customers
+
+  # Use a different editor
+  $ SQ_EDITOR=nano sq query edit top-customers`,
+	}
+
+	cmdMarkRequiresConfigLock(cmd)
+	return cmd
+}
+
+func execQueryEdit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ru, log := run.FromContext(ctx), lg.From(cmd)
+	cfg := ru.Config
+
+	i := slices.IndexFunc(cfg.Queries, func(q *config.Query) bool {
+		return q.Name == args[0]
+	})
+	if i < 0 {
+		return errz.Errorf("saved query not found: %s", args[0])
+	}
+
+	before, err := ioz.MarshalYAML(cfg.Queries[i])
+	if err != nil {
+		return err
+	}
+
+	ed := shelleditor.NewDefaultEditor(editorEnvs...)
+	after, tmpFile, err := ed.LaunchTempFile("sq", ".yml", bytes.NewReader(before))
+	if tmpFile != "" {
+		defer func() {
+			lg.WarnIfError(log, "Delete editor temp file", errz.Err(os.Remove(tmpFile)))
+		}()
+	}
+	if err != nil {
+		return errz.Wrap(err, "edit saved query")
+	}
+
+	if bytes.Equal(before, after) {
+		log.Debug("Edit saved query: no changes made", lga.Name, args[0])
+		return nil
+	}
+
+	q := &config.Query{}
+	if err = ioz.UnmarshallYAML(after, q); err != nil {
+		return err
+	}
+	q.Name, q.SLQ = strings.TrimSpace(q.Name), strings.TrimSpace(q.SLQ)
+
+	if err = validSavedQuery(q); err != nil {
+		return err
+	}
+
+	if q.Name != args[0] && cfg.FindQuery(q.Name) != nil {
+		return errz.Errorf("can't rename saved query %s: query %s already exists", args[0], q.Name)
+	}
+
+	cfg.Queries[i] = q
+	if err = ru.ConfigStore.Save(ctx, cfg); err != nil {
+		return err
+	}
+
+	log.Debug("Edit saved query: changes saved", lga.Name, q.Name, lga.Path, ru.ConfigStore.Location())
+	return nil
+}
+
+// validSavedQuery returns an error if q is not valid. The query's SLQ is
+// parsed, and each of q's default arg values must correspond to an arg
+// referenced by the SLQ.
+func validSavedQuery(q *config.Query) error {
+	if err := config.ValidQueryName(q.Name); err != nil {
+		return err
+	}
+
+	if q.SLQ == "" {
+		return errz.Errorf("saved query %s: empty SLQ", q.Name)
+	}
+
+	params, err := q.Params()
+	if err != nil {
+		return err
+	}
+
+	for k := range q.Args {
+		if !slices.Contains(params, k) {
+			return errz.Errorf("saved query %s: --%s %s: query doesn't reference $%s", q.Name, flag.Arg, k, k)
+		}
+	}
+
+	return nil
+}
+
+// addQueryFormatFlags adds the output format flags for the "sq query"
+// commands.
+func addQueryFormatFlags(cmd *cobra.Command) {
+	addTextFormatFlags(cmd)
+	cmd.Flags().BoolP(flag.JSON, flag.JSONShort, false, flag.JSONUsage)
+	addOptionFlag(cmd.Flags(), OptCompact)
+	cmd.Flags().BoolP(flag.YAML, flag.YAMLShort, false, flag.YAMLUsage)
+}
@@ -0,0 +1,101 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+func TestCmdQuery_Run(t *testing.T) {
+	const handle = "@csv_people"
+	th := testh.New(t)
+	fp := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("name,region,age\nalice,EU,30\nbob,US,40\ncarol,EU,50\n"), 0o600))
+	src := source.Source{Handle: handle, Type: drivertype.CSV, Location: fp}
+
+	tr := testrun.New(th.Context, t, nil).Add(src)
+	require.NoError(t, tr.Exec("query", "add", "by-region", "--description", "People by region",
+		"--arg", "region=EU", handle+".data | where(.region == $region) | .name"))
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("query", "ls", "--json"))
+	got := tr.BindSliceMap()
+	require.Len(t, got, 1)
+	require.Equal(t, "by-region", got[0]["name"])
+	require.Equal(t, "People by region", got[0]["description"])
+	require.Equal(t, map[string]any{"region": "EU"}, got[0]["args"])
+
+	// Default arg value.
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("run", "by-region", "--csv", "-H"))
+	require.Equal(t, [][]string{{"alice"}, {"carol"}}, tr.BindCSV())
+
+	// Arg value via "--arg name=value".
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("run", "by-region", "--arg", "region=US", "--csv", "-H"))
+	require.Equal(t, [][]string{{"bob"}}, tr.BindCSV())
+
+	// Arg value via jq-style "--arg name value".
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("run", "by-region", "--arg", "region", "US", "--csv", "-H"))
+	require.Equal(t, [][]string{{"bob"}}, tr.BindCSV())
+
+	tr = testrun.New(th.Context, t, tr)
+	require.Error(t, tr.Exec("run", "by-region", "--arg", "nope=1"), "query doesn't reference $nope")
+
+	tr = testrun.New(th.Context, t, tr)
+	require.Error(t, tr.Exec("run", "not-exist"))
+
+	// Query without a default value for an arg.
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("query", "add", "older", handle+".data | where(.age > $age) | .name"))
+	tr = testrun.New(th.Context, t, tr)
+	require.Error(t, tr.Exec("run", "older"), "no value for $age")
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("run", "older", "--arg", "age=35", "--csv", "-H"))
+	require.Equal(t, [][]string{{"bob"}, {"carol"}}, tr.BindCSV())
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("query", "rm", "older", "by-region"))
+	require.Empty(t, tr.Run.Config.Queries)
+}
+
+func TestCmdQuery_Add_invalid(t *testing.T) {
+	const handle = "@csv_people"
+	th := testh.New(t)
+	fp := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("name,age\nalice,30\n"), 0o600))
+	src := source.Source{Handle: handle, Type: drivertype.CSV, Location: fp}
+
+	tr := testrun.New(th.Context, t, nil).Add(src)
+	require.NoError(t, tr.Exec("query", "add", "q1", handle+".data"))
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{name: "syntax_error", args: []string{"q2", handle + ".data | where("}},
+		{name: "invalid_name", args: []string{"2q", handle + ".data"}},
+		{name: "exists", args: []string{"q1", handle + ".data"}},
+		{name: "unknown_arg", args: []string{"q2", "--arg", "x=1", handle + ".data"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr2 := testrun.New(th.Context, t, tr)
+			require.Error(t, tr2.Exec(append([]string{"query", "add"}, tc.args...)...))
+			require.Len(t, tr2.Run.Config.Queries, 1)
+		})
+	}
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("query", "add", "q1", "--force", handle+".data | .name"))
+	require.Equal(t, handle+".data | .name", tr.Run.Config.FindQuery("q1").SLQ)
+}
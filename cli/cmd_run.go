@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+func newRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "run NAME",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeQueryName(1),
+		Short:             "Execute saved query",
+		Long: `Execute a saved query. Values for the query's args are supplied via
+--arg, as with any SLQ query. An arg that isn't supplied takes its saved
+default value, if any; otherwise an error is returned. Output flags such as
+--json or --insert behave just as for a regular query. If the saved query
+is a multi-statement script, --keep-going continues after a failed
+statement.
+
+Use "sq query" to manage saved queries.`,
+		RunE: execRun,
+		Example: `  # Run saved query "top-customers"
+  $ sq run top-customers
+
+  # Supply a value for arg $region
+  $ sq run top-customers --arg region=EU
+
+  # Same as above, using jq-style --arg
+  $ sq run top-customers --arg region EU
+
+  # Output JSON
+  $ sq run top-customers --arg region=EU --json
+
+  # Show the SQL that would be executed
+  $ sq run top-customers --arg region=EU --render-sql`,
+	}
+
+	addQueryCmdFlags(cmd)
+	cmd.Flags().Bool(flag.RenderSQL, false, flag.RenderSQLUsage)
+	cmd.Flags().Bool(flag.KeepGoing, false, flag.KeepGoingUsage)
+	cmd.Flags().StringArray(flag.Arg, nil, flag.ArgUsage)
+	panicOn(cmd.RegisterFlagCompletionFunc(flag.Arg, completeQueryArg))
+
+	return cmd
+}
+
+func execRun(cmd *cobra.Command, args []string) error {
+	ru := run.FromContext(cmd.Context())
+
+	q := ru.Config.FindQuery(args[0])
+	if q == nil {
+		return errz.Errorf("saved query not found: %s", args[0])
+	}
+
+	params, err := q.Params()
+	if err != nil {
+		return err
+	}
+
+	mArgs, err := extractFlagArgsValues(cmd)
+	if err != nil {
+		return err
+	}
+
+	for k := range mArgs {
+		if !slices.Contains(params, k) {
+			return errz.Errorf("saved query %s: --%s %s: query doesn't reference $%s", q.Name, flag.Arg, k, k)
+		}
+	}
+
+	vals := make(map[string]string, len(params))
+	var missing []string
+	for _, p := range params {
+		if v, ok := mArgs[p]; ok {
+			vals[p] = v
+		} else if v, ok = q.Args[p]; ok {
+			vals[p] = v
+		} else {
+			missing = append(missing, "$"+p)
+		}
+	}
+
+	if len(missing) > 0 {
+		return errz.Errorf("saved query %s: no value for %s: use --%s",
+			q.Name, strings.Join(missing, ", "), flag.Arg)
+	}
+
+	ru.Args = []string{q.SLQ}
+	return execSLQArgs(cmd, vals)
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
 	"slices"
 	"strings"
 	"time"
@@ -15,12 +16,16 @@ import (
 	"github.com/neilotoole/sq/cli/run"
 	"github.com/neilotoole/sq/drivers/csv"
 	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/ast"
 	"github.com/neilotoole/sq/libsq/core/errz"
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/record"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/core/tuning"
 	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/libsq/source"
 )
 
@@ -47,6 +52,10 @@ func newSLQCmd() *cobra.Command {
 	// cobra's default filename completion.
 	panicOn(cmd.RegisterFlagCompletionFunc(flag.Arg, completeNone))
 
+	addSLQScriptFlags(cmd)
+	addSLQStreamFlags(cmd)
+	addSLQWatchFlags(cmd)
+
 	// Explicitly add flagVersion because people like to do "sq --version"
 	// as much as "sq version".
 	cmd.Flags().Bool(flag.Version, false, flag.VersionUsage)
@@ -54,9 +63,34 @@ func newSLQCmd() *cobra.Command {
 	return cmd
 }
 
+// addSLQScriptFlags adds the flags for executing a SLQ script: --file
+// and --keep-going.
+func addSLQScriptFlags(cmd *cobra.Command) {
+	cmd.Flags().String(flag.SLQFile, "", flag.SLQFileUsage)
+	cmd.Flags().Bool(flag.KeepGoing, false, flag.KeepGoingUsage)
+}
+
 // execSLQ is sq's core command.
 func execSLQ(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
+	if cmdFlagChanged(cmd, flag.SLQFile) {
+		if len(args) > 0 {
+			return errz.Errorf("--%s can't be used with a query argument", flag.SLQFile)
+		}
+
+		fpath := strings.TrimSpace(cmd.Flag(flag.SLQFile).Value.String())
+		if fpath == "" {
+			return errz.Errorf("--%s is specified, but empty", flag.SLQFile)
+		}
+
+		b, err := os.ReadFile(fpath)
+		if err != nil {
+			return errz.Wrapf(err, "--%s", flag.SLQFile)
+		}
+
+		// The script's content is the query, as if it had been
+		// supplied as the sole arg.
+		run.FromContext(cmd.Context()).Args = []string{string(b)}
+	} else if len(args) == 0 {
 		msg := "no query"
 		if cmdFlagChanged(cmd, flag.Arg) {
 			msg += fmt.Sprintf(": maybe check flag --%s usage", flag.Arg)
@@ -65,6 +99,26 @@ func execSLQ(cmd *cobra.Command, args []string) error {
 		return errz.New(msg)
 	}
 
+	mArgs, err := extractFlagArgsValues(cmd)
+	if err != nil {
+		return err
+	}
+
+	return execSLQArgs(cmd, mArgs)
+}
+
+// execSLQArgs executes the SLQ query in run.Run.Args, with the $arg
+// values in mArgs. It is the body of execSLQ, and is shared with the
+// "run" command, which executes a saved query. The query may be a script
+// of several ';'-separated statements, which are executed in order: see
+// execSLQScript.
+func execSLQArgs(cmd *cobra.Command, mArgs map[string]string) error {
+	if cmdFlagIsSetTrue(cmd, flag.Stream) {
+		// Streaming mode must be handled before determineSources,
+		// which would buffer and ingest all of stdin.
+		return execSLQStream(cmd, mArgs)
+	}
+
 	ctx := cmd.Context()
 	ru := run.FromContext(ctx)
 	coll := ru.Config.Collection
@@ -94,65 +148,252 @@ func execSLQ(cmd *cobra.Command, args []string) error {
 		// active source, so we allow progress to continue.
 	}
 
-	mArgs, err := extractFlagArgsValues(cmd)
+	if err = applyCollectionOptions(cmd, coll); err != nil {
+		return err
+	}
+
+	if cmdFlagIsSetTrue(cmd, flag.RenderSQL) && cmdFlagChanged(cmd, flag.Insert) {
+		return errz.Errorf("--%s is not compatible with --%s", flag.Insert, flag.RenderSQL)
+	}
+
+	explainFlag, analyze := getExplainFlag(cmd)
+	switch {
+	case explainFlag == "":
+	case cmdFlagIsSetTrue(cmd, flag.RenderSQL):
+		return errz.Errorf("--%s is not compatible with --%s", flag.RenderSQL, explainFlag)
+	case cmdFlagChanged(cmd, flag.Insert):
+		return errz.Errorf("--%s is not compatible with --%s", flag.Insert, explainFlag)
+	}
+
+	slq, err := preprocessUserSLQ(ctx, ru, ru.Args)
 	if err != nil {
 		return err
 	}
 
-	if err = applyCollectionOptions(cmd, coll); err != nil {
+	stmts, err := ast.SplitStatements(lg.FromContext(ctx), slq)
+	if err != nil {
 		return err
 	}
 
-	if cmdFlagIsSetTrue(cmd, flag.RenderSQL) {
-		if cmdFlagChanged(cmd, flag.Insert) {
-			return errz.Errorf("--%s is not compatible with --%s", flag.Insert, flag.RenderSQL)
+	if cmdFlagChanged(cmd, flag.Watch) {
+		if len(stmts) > 1 {
+			return errz.Errorf("--%s doesn't support multi-statement scripts", flag.Watch)
 		}
-		return execSLQRenderSQL(ctx, ru, mArgs)
+		return execSLQWatch(ctx, cmd, ru, mArgs, slq)
+	}
+	if cmdFlagIsSetTrue(cmd, flag.WatchDiff) {
+		return errz.Errorf("--%s requires --%s", flag.WatchDiff, flag.Watch)
+	}
+
+	if len(stmts) > 1 {
+		return execSLQScript(cmd, mArgs, stmts)
+	}
+
+	if cmdFlagIsSetTrue(cmd, flag.RenderSQL) {
+		return execSLQRenderSQL(ctx, ru, mArgs, slq)
+	}
+
+	if explainFlag != "" {
+		return execSLQExplain(ctx, ru, mArgs, slq, analyze)
 	}
 
 	if !cmdFlagChanged(cmd, flag.Insert) {
 		// The user didn't specify the --insert=@src.tbl flag, so we just
 		// want to print the records; execSLQPrint opens the source(s)
 		// read-only via QueryContext.AccessMode.
-		return execSLQPrint(ctx, ru, mArgs)
+		return execSLQPrint(ctx, ru, mArgs, slq, ru.Writers.Record)
 	}
 
+	destSrc, destTbl, err := getSLQInsertDest(cmd, coll)
+	if err != nil {
+		return err
+	}
+
+	return execSLQInsert(ctx, ru, mArgs, slq, destSrc, destTbl)
+}
+
+// getSLQInsertDest returns the destination source and table specified
+// by the --insert=@HANDLE.TABLE flag.
+func getSLQInsertDest(cmd *cobra.Command, coll *source.Collection) (*source.Source, string, error) {
 	// Instead of printing the records, they will be
 	// written to another database
 	insertTo, _ := cmd.Flags().GetString(flag.Insert)
 	if insertTo == "" {
-		return errz.Errorf("invalid --%s value: empty", flag.Insert)
+		return nil, "", errz.Errorf("invalid --%s value: empty", flag.Insert)
 	}
 
 	destHandle, destTbl, err := source.ParseTableHandle(insertTo)
 	if err != nil {
-		return errz.Wrapf(err, "invalid --%s value", flag.Insert)
+		return nil, "", errz.Wrapf(err, "invalid --%s value", flag.Insert)
 	}
 
 	if destTbl == "" {
-		return errz.Errorf("invalid value for --%s: must be @HANDLE.TABLE", flag.Insert)
+		return nil, "", errz.Errorf("invalid value for --%s: must be @HANDLE.TABLE", flag.Insert)
 	}
 
 	destSrc, err := coll.Get(destHandle)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return destSrc, destTbl, nil
+}
+
+// execSLQScript executes each of stmts, the statements of a SLQ script,
+// in order. The statements share ru.Grips, so a source is opened only
+// once. Each statement's result set is written separately: see
+// newSLQScriptRecordWriter. Execution stops at the first failed
+// statement, unless --keep-going, in which case each failure is printed
+// and execution continues, and errz.ErrNoMsg is returned at the end.
+func execSLQScript(cmd *cobra.Command, mArgs map[string]string, stmts []string) error {
+	ctx := cmd.Context()
+	ru := run.FromContext(ctx)
+	keepGoing := cmdFlagIsSetTrue(cmd, flag.KeepGoing)
+
+	var (
+		destSrc *source.Source
+		destTbl string
+		recw    *slqScriptRecordWriter
+		err     error
+	)
+
+	explainFlag, analyze := getExplainFlag(cmd)
+	switch {
+	case cmdFlagIsSetTrue(cmd, flag.RenderSQL), explainFlag != "":
+	case cmdFlagChanged(cmd, flag.Insert):
+		if destSrc, destTbl, err = getSLQInsertDest(cmd, ru.Config.Collection); err != nil {
+			return err
+		}
+	default:
+		if recw, err = newSLQScriptRecordWriter(cmd, ru); err != nil {
+			return err
+		}
+	}
+
+	var failed bool
+	for i, stmt := range stmts {
+		switch {
+		case cmdFlagIsSetTrue(cmd, flag.RenderSQL):
+			err = execSLQRenderSQL(ctx, ru, mArgs, stmt)
+		case explainFlag != "":
+			err = execSLQExplain(ctx, ru, mArgs, stmt, analyze)
+		case destSrc != nil:
+			err = execSLQInsert(ctx, ru, mArgs, stmt, destSrc, destTbl)
+		default:
+			err = execSLQPrint(ctx, ru, mArgs, stmt, recw.next())
+		}
+
+		if err == nil {
+			continue
+		}
+
+		err = errz.Wrapf(err, "statement %d of %d", i+1, len(stmts))
+		if !keepGoing || errz.IsErrContext(err) {
+			break
+		}
+
+		failed = true
+		ru.Writers.Error.Error(err, err)
+		err = nil
+	}
+
+	if recw != nil {
+		err = errz.Append(err, recw.Close(ctx))
+	}
+
 	if err != nil {
 		return err
 	}
 
-	return execSLQInsert(ctx, ru, mArgs, destSrc, destTbl)
+	if failed {
+		// Each failure was already printed.
+		return errz.ErrNoMsg
+	}
+	return nil
+}
+
+// slqScriptRecordWriter supplies the output.RecordWriter for each
+// statement of a SLQ script. If the configured record writer implements
+// output.ResultSetWriter, it writes every result set, and each statement
+// gets a wrapper whose Close ends the result set; the real Close is
+// invoked by slqScriptRecordWriter.Close. Otherwise, each statement after
+// the first gets a new record writer, and so the result sets are simply
+// concatenated, e.g. one JSON array after another.
+type slqScriptRecordWriter struct {
+	rsw    output.ResultSetWriter
+	newFn  output.NewRecordWriterFunc
+	first  output.RecordWriter
+	ru     *run.Run
+	count  int
+	opened bool
+}
+
+func newSLQScriptRecordWriter(cmd *cobra.Command, ru *run.Run) (*slqScriptRecordWriter, error) {
+	w := &slqScriptRecordWriter{ru: ru, first: ru.Writers.Record}
+	if rsw, ok := ru.Writers.Record.(output.ResultSetWriter); ok {
+		w.rsw = rsw
+		return w, nil
+	}
+
+	o, err := getOptionsFromCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.newFn = getRecordWriterFunc(getFormat(cmd, o)); w.newFn == nil {
+		return nil, errz.Errorf("format {%s} doesn't support multi-statement scripts", getFormat(cmd, o))
+	}
+	return w, nil
+}
+
+// next returns the output.RecordWriter for the next statement.
+func (w *slqScriptRecordWriter) next() output.RecordWriter {
+	defer func() { w.count++ }()
+	switch {
+	case w.rsw != nil:
+		return &resultSetRecordWriter{ResultSetWriter: w.rsw, opened: &w.opened}
+	case w.count == 0:
+		return w.first
+	default:
+		return w.newFn(w.ru.Out, w.ru.Writers.PrOut)
+	}
+}
+
+// Close closes the output.ResultSetWriter, if any result set was written
+// to it.
+func (w *slqScriptRecordWriter) Close(ctx context.Context) error {
+	if w.rsw == nil || !w.opened {
+		return nil
+	}
+	return w.rsw.Close(ctx)
+}
+
+// resultSetRecordWriter is an output.RecordWriter that writes a single
+// result set to an output.ResultSetWriter: its Close method invokes
+// EndResultSet.
+type resultSetRecordWriter struct {
+	output.ResultSetWriter
+	opened *bool
+}
+
+// Open implements output.RecordWriter.
+func (w *resultSetRecordWriter) Open(ctx context.Context, recMeta record.Meta) error {
+	*w.opened = true
+	return w.ResultSetWriter.Open(ctx, recMeta)
+}
+
+// Close implements output.RecordWriter.
+func (w *resultSetRecordWriter) Close(ctx context.Context) error {
+	return w.EndResultSet(ctx)
 }
 
 // execSQLInsert executes the SLQ and inserts resulting records
 // into destTbl in destSrc.
 func execSLQInsert(ctx context.Context, ru *run.Run, mArgs map[string]string,
-	destSrc *source.Source, destTbl string,
+	slq string, destSrc *source.Source, destTbl string,
 ) error {
 	qc := run.NewQueryContext(ru, mArgs)
 
-	slq, err := preprocessUserSLQ(ctx, ru, ru.Args)
-	if err != nil {
-		return err
-	}
-
 	ctx, cancelFn := context.WithCancel(ctx)
 	defer cancelFn()
 
@@ -184,6 +425,13 @@ func execSLQInsert(ctx context.Context, ru *run.Run, mArgs map[string]string,
 		tuning.OptRecBufSize.Get(destSrc.Options),
 		libsq.DBWriterCreateTableIfNotExistsHook(destTbl),
 	)
+	policy, err := getMaskPolicy(ctx, ru, destSrc)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		inserter.SetMask(policy)
+	}
 
 	start := time.Now()
 	execErr := libsq.ExecSLQ(ctx, qc, slq, inserter)
@@ -203,18 +451,17 @@ func execSLQInsert(ctx context.Context, ru *run.Run, mArgs map[string]string,
 	return ru.Writers.RecordInsert.RecordsInserted(ctx, destSrc, destTbl, affected, elapsed)
 }
 
-// execSLQPrint executes the SLQ query, and prints output to writer.
-func execSLQPrint(ctx context.Context, ru *run.Run, mArgs map[string]string) error {
+// execSLQPrint executes the SLQ query, and prints output to rw.
+func execSLQPrint(ctx context.Context, ru *run.Run, mArgs map[string]string,
+	slq string, rw output.RecordWriter,
+) error {
 	qc := run.NewQueryContext(ru, mArgs)
 	// Printing a query never writes to a source: open read-only.
 	qc.AccessMode = driver.ModeReadOnly
+	// Printed results may be served from the result cache, if configured.
+	qc.Files = ru.Files
 
-	slq, err := preprocessUserSLQ(ctx, ru, ru.Args)
-	if err != nil {
-		return err
-	}
-
-	recw := output.NewRecordWriterAdapter(ctx, ru.Writers.Record)
+	recw := output.NewRecordWriterAdapter(ctx, rw)
 	execErr := libsq.ExecSLQ(ctx, qc, slq, recw)
 	_, waitErr := recw.Wait()
 	if execErr != nil {
@@ -237,7 +484,7 @@ func execSLQPrint(ctx context.Context, ru *run.Run, mArgs map[string]string) err
 // don't have a natural representation for a single rendered statement —
 // but a log.Warn is emitted so the substitution is discoverable to
 // anyone running with verbose / debug logging.
-func execSLQRenderSQL(ctx context.Context, ru *run.Run, mArgs map[string]string) error {
+func execSLQRenderSQL(ctx context.Context, ru *run.Run, mArgs map[string]string, slq string) error {
 	qc := run.NewQueryContext(ru, mArgs)
 	// Rendering only reads source metadata; open read-only.
 	qc.AccessMode = driver.ModeReadOnly
@@ -249,11 +496,6 @@ func execSLQRenderSQL(ctx context.Context, ru *run.Run, mArgs map[string]string)
 		)
 	}
 
-	slq, err := preprocessUserSLQ(ctx, ru, ru.Args)
-	if err != nil {
-		return err
-	}
-
 	res, err := libsq.SLQ2SQL(ctx, qc, slq)
 	if err != nil {
 		return errz.Wrap(err, "render SQL")
@@ -440,6 +682,11 @@ func addQueryCmdFlags(cmd *cobra.Command) {
 	)...)
 
 	addTimeFormatOptsFlags(cmd)
+	addExcelOptsFlags(cmd)
+
+	cmd.Flags().Bool(flag.Explain, false, flag.ExplainUsage)
+	cmd.Flags().Bool(flag.ExplainAnalyze, false, flag.ExplainAnalyzeUsage)
+	cmd.MarkFlagsMutuallyExclusive(flag.Explain, flag.ExplainAnalyze)
 
 	cmd.Flags().StringP(flag.FileOutput, flag.FileOutputShort, "", flag.FileOutputUsage)
 
@@ -449,6 +696,7 @@ func addQueryCmdFlags(cmd *cobra.Command) {
 	cmd.Flags().String(flag.Insert, "", flag.InsertUsage)
 	panicOn(cmd.RegisterFlagCompletionFunc(flag.Insert,
 		(&handleTableCompleter{onlySQL: true, handleRequired: true}).complete))
+	addOptionFlag(cmd.Flags(), mask.OptPolicy)
 
 	cmd.Flags().String(flag.ActiveSrc, "", flag.ActiveSrcUsage)
 	panicOn(cmd.RegisterFlagCompletionFunc(flag.ActiveSrc, completeHandleFlag(false)))
@@ -463,6 +711,7 @@ func addQueryCmdFlags(cmd *cobra.Command) {
 
 	addOptionFlag(cmd.Flags(), driver.OptIngestHeader)
 	addOptionFlag(cmd.Flags(), driver.OptIngestCache)
+	addOptionFlag(cmd.Flags(), files.OptResultCacheTTL)
 	addOptionFlag(cmd.Flags(), csv.OptDelim)
 	panicOn(cmd.RegisterFlagCompletionFunc(csv.OptDelim.Key(), completeStrings(csv.NamedDelims()...)))
 	addOptionFlag(cmd.Flags(), csv.OptEmptyAsNull)
@@ -551,6 +800,9 @@ func extractFlagArgsValues(cmd *cobra.Command) (map[string]string, error) {
 // are concatenated into a single flag value "first:TOM". Thus, the returned
 // slice will be shorter.
 //
+// The "--arg name=value" form is also accepted, e.g. "--arg first=TOM". It
+// too results in flag value "first:TOM".
+//
 // This function needs to be called before cobra/pflag starts processing
 // the program args.
 //
@@ -571,6 +823,15 @@ func preprocessFlagArgVars(args []string) ([]string, error) {
 
 	var i int
 	for i = 0; i < len(args); {
+		if args[i] == flg && i+1 < len(args) {
+			if k, v, ok := strings.Cut(args[i+1], "="); ok && stringz.ValidIdent(k) == nil {
+				// It's the "--arg name=value" form.
+				rez = append(rez, flg, k+":"+v)
+				i += 2
+				continue
+			}
+		}
+
 		if args[i] == flg {
 			val, err := extractFlagArgsSingleArg(args[i:])
 			if err != nil {
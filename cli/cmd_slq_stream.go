@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/output/format"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// streamFollowPoll is how often a followed file is checked for appended
+// data, when streaming.
+const streamFollowPoll = time.Millisecond * 250
+
+// addSLQStreamFlags adds the flags for streaming mode: --stream and
+// its options.
+func addSLQStreamFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(flag.Stream, false, flag.StreamUsage)
+	addOptionFlag(cmd.Flags(), libsq.OptStreamWindow)
+	addOptionFlag(cmd.Flags(), libsq.OptStreamFollow)
+}
+
+// execSLQStream executes the SLQ query in streaming mode (--stream), via
+// libsq.ExecSLQStream. Unlike the regular query path, stdin isn't buffered
+// and ingested: rows are evaluated as they arrive, so that unbounded
+// input, such as "tail -f app.jsonl | sq --stream '.data'", produces
+// output as it goes.
+func execSLQStream(cmd *cobra.Command, mArgs map[string]string) error {
+	ctx := cmd.Context()
+	ru := run.FromContext(ctx)
+	coll := ru.Config.Collection
+
+	for _, name := range []string{flag.Insert, flag.RenderSQL, flag.Explain, flag.ExplainAnalyze, flag.Watch} {
+		if cmdFlagChanged(cmd, name) {
+			return errz.Errorf("--%s is not compatible with --%s", name, flag.Stream)
+		}
+	}
+
+	o, err := getOptionsFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+	ctx = options.NewContext(ctx, options.Merge(options.FromContext(ctx), o))
+
+	rw, err := newStreamRecordWriter(cmd, ru, o)
+	if err != nil {
+		return err
+	}
+
+	if _, err = activeSrcAndSchemaFromFlagsOrConfig(ru, driver.ModeReadOnly); err != nil {
+		return err
+	}
+
+	openFn := openStreamFile
+	stdin, err := checkStdinStream(ctx, ru)
+	if err != nil {
+		return err
+	}
+	if stdin != nil {
+		if err = coll.Add(stdin.src); err != nil {
+			return err
+		}
+		if !cmdFlagChanged(cmd, flag.ActiveSrc) {
+			if _, err = coll.SetActive(stdin.src.Handle, false); err != nil {
+				return err
+			}
+		}
+		openFn = stdin.open
+	}
+
+	if err = applyCollectionOptions(cmd, coll); err != nil {
+		return err
+	}
+
+	if len(ru.Args) == 0 {
+		return errz.New(msgEmptyQueryString)
+	}
+	slq := strings.Join(ru.Args, " ")
+	stmts, err := ast.SplitStatements(lg.FromContext(ctx), slq)
+	if err != nil {
+		return err
+	}
+	if len(stmts) > 1 {
+		return errz.Errorf("--%s doesn't support multi-statement scripts", flag.Stream)
+	}
+
+	qc := run.NewQueryContext(ru, mArgs)
+	qc.AccessMode = driver.ModeReadOnly
+
+	recw := output.NewRecordWriterAdapter(ctx, rw)
+	execErr := libsq.ExecSLQStream(ctx, qc, slq, openFn, recw)
+	_, waitErr := recw.Wait()
+	if execErr != nil {
+		return execErr
+	}
+	return waitErr
+}
+
+// newStreamRecordWriter returns the record writer for streaming mode.
+// The text format's writer buffers all records until the end of output,
+// so it's no use for an unbounded stream: if the format is text by
+// default, JSONL is used instead; if text is explicitly specified, an
+// error is returned.
+func newStreamRecordWriter(cmd *cobra.Command, ru *run.Run, o options.Options) (output.RecordWriter, error) {
+	if getFormat(cmd, o) != format.Text {
+		return ru.Writers.Record, nil
+	}
+
+	if cmdFlagChanged(cmd, flag.Text) || cmdFlagChanged(cmd, OptFormat.Flag().Name) {
+		return nil, errz.Errorf("--%s doesn't support format {%s}: use a line-oriented format such as --%s",
+			flag.Stream, format.Text, flag.JSONL)
+	}
+	return getRecordWriterFunc(format.JSONL)(ru.Out, ru.Writers.PrOut), nil
+}
+
+// openStreamFile is a libsq.StreamOpenFunc that opens src's file. If
+// libsq.OptStreamFollow is true, the returned reader follows data
+// appended to the file.
+func openStreamFile(ctx context.Context, src *source.Source) (io.ReadCloser, error) {
+	if location.TypeOf(src.Location) != location.TypeFile {
+		return nil, errz.Errorf("--%s requires a file source or stdin, but %s is {%s}",
+			flag.Stream, src.Handle, location.TypeOf(src.Location))
+	}
+
+	f, err := os.Open(src.Location)
+	if err != nil {
+		return nil, errz.Err(err)
+	}
+
+	if !libsq.OptStreamFollow.Get(options.Merge(options.FromContext(ctx), src.Options)) {
+		return f, nil
+	}
+	return ioz.NewFollowReader(ctx, f, streamFollowPoll), nil
+}
+
+// stdinStream is the @stdin source for streaming mode, and its reader.
+type stdinStream struct {
+	src *source.Source
+	r   io.Reader
+}
+
+// open is a libsq.StreamOpenFunc that returns the stdin reader.
+func (s *stdinStream) open(context.Context, *source.Source) (io.ReadCloser, error) {
+	return io.NopCloser(s.r), nil
+}
+
+// checkStdinStream is the streaming counterpart of checkStdinSource. If
+// there's data on stdin, an @stdin source is returned; otherwise nil is
+// returned. Unlike checkStdinSource, stdin isn't buffered, so the driver
+// type must be given via --ingest.driver, unless the first line of input
+// is a JSON object, in which case the type is JSONL.
+func checkStdinStream(ctx context.Context, ru *run.Run) (*stdinStream, error) {
+	ok, err := stdinHasData(ctx, ru.Stdin)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	br := bufio.NewReader(ru.Stdin)
+	typ := drivertype.None
+	if ru.Cmd.Flags().Changed(flag.IngestDriver) {
+		val, _ := ru.Cmd.Flags().GetString(flag.IngestDriver)
+		typ = drivertype.Type(val)
+		if ru.DriverRegistry.ProviderFor(typ) == nil {
+			return nil, errz.Errorf("unknown driver type: %s", typ)
+		}
+	} else {
+		// Peek blocks until the input's first non-whitespace byte arrives.
+		for i := 1; ; i++ {
+			b, err := br.Peek(i)
+			if err != nil {
+				return nil, errz.Wrap(err, "unable to detect type of stdin")
+			}
+			c := b[i-1]
+			if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+				continue
+			}
+			if c == '{' {
+				typ = drivertype.JSONL
+			}
+			break
+		}
+		if typ == drivertype.None {
+			return nil, errz.Errorf("unable to detect type of stdin: use flag --%s", flag.IngestDriver)
+		}
+	}
+
+	src, err := newSource(ctx, ru.DriverRegistry, typ, source.StdinHandle, source.StdinHandle, options.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &stdinStream{src: src, r: br}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -337,6 +338,26 @@ func TestCmdSLQ_PreprocessFlagArgVars(t *testing.T) {
 			in:   []string{"--arg", "name", "TOM", "--arg", "eyes", "blue", ".actor"},
 			want: []string{"--arg", "name:TOM", "--arg", "eyes:blue", ".actor"},
 		},
+		{
+			name: "single arg flag with equals",
+			in:   []string{"--arg", "name=TOM", ".actor"},
+			want: []string{"--arg", "name:TOM", ".actor"},
+		},
+		{
+			name: "equals in value",
+			in:   []string{"--arg", "name=T=OM", ".actor"},
+			want: []string{"--arg", "name:T=OM", ".actor"},
+		},
+		{
+			name: "equals in value of space form",
+			in:   []string{"--arg", "name", "T=OM", ".actor"},
+			want: []string{"--arg", "name:T=OM", ".actor"},
+		},
+		{
+			name: "mixed equals and space forms",
+			in:   []string{"--arg", "name=TOM", "--arg", "eyes", "blue", ".actor"},
+			want: []string{"--arg", "name:TOM", "--arg", "eyes:blue", ".actor"},
+		},
 		{
 			name: "two arg flags with interspersed flag",
 			in:   []string{"--arg", "name", "TOM", "--json", "true", "--arg", "eyes", "blue", ".actor"},
@@ -679,6 +700,33 @@ func TestCmdSLQ_RenderSQL_InvalidSLQ(t *testing.T) {
 		"stdout should be empty on error, got: %s", tr.OutString())
 }
 
+// TestCmdSLQ_Explain verifies that --explain prints the query plan
+// instead of the results, including the join copies of a cross-source
+// query.
+func TestCmdSLQ_Explain(t *testing.T) {
+	ctx := context.Background()
+	aPath := tu.WriteTemp(t, "*.csv", []byte("id,name\n1,alice\n2,bob\n"), true)
+	bPath := tu.WriteTemp(t, "*.csv", []byte("id,dept\n1,eng\n2,ops\n"), true)
+
+	tr := testrun.New(ctx, t, nil).Hush()
+	require.NoError(t, tr.Exec("add", aPath, "--handle", "@a"))
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("add", bPath, "--handle", "@b"))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("slq", "--explain", "--json", "@a.data"))
+	require.NotEmpty(t, tr.JQ(".plan.op"))
+	require.Equal(t, false, tr.JQ(".analyze"))
+	require.Nil(t, tr.JQ(".join_copies"))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("slq", "--explain", "--json", "@a.data | join(@b.data, .id)"))
+	require.Len(t, tr.JQ(".join_copies"), 2)
+
+	tr = testrun.New(ctx, t, tr)
+	require.Error(t, tr.Exec("slq", "--explain", "--render-sql", "@a.data"))
+}
+
 // TestRenderSQLSupportsFormat is a parity test pinning the
 // renderSQLSupportsFormat allow-list to format.All(). Adding a new
 // format.Format to format.All() will fail this test until the new
@@ -969,3 +1017,78 @@ func TestCmdSLQ_FormatDecimal(t *testing.T) {
 		require.NotContains(t, out, `"20100"`)
 	})
 }
+
+// TestCmdSLQ_Script verifies that a multi-statement SLQ script executes
+// each statement, and writes each result set separately.
+func TestCmdSLQ_Script(t *testing.T) {
+	dir := tu.TempDir(t)
+	csvPath := filepath.Join(dir, "data.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id,name\n1,alice\n2,bob\n3,carol\n"), 0o600))
+
+	th := testh.New(t)
+	tr := testrun.New(th.Context, t, nil)
+	require.NoError(t, tr.Exec("add", csvPath, "--handle", "@people"))
+
+	const script = "@people.data | .[0:2]; @people.data | count"
+
+	t.Run("json", func(t *testing.T) {
+		tr := testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("--json", script))
+
+		dec := json.NewDecoder(strings.NewReader(tr.OutString()))
+		var rows []map[string]any
+		require.NoError(t, dec.Decode(&rows))
+		require.Len(t, rows, 2)
+		var counts []map[string]any
+		require.NoError(t, dec.Decode(&counts))
+		require.Equal(t, []map[string]any{{"count": float64(3)}}, counts)
+		require.False(t, dec.More())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		tr := testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("--yaml", script))
+		docs := strings.Split(tr.OutString(), "---\n")
+		require.Len(t, docs, 2)
+		require.Contains(t, docs[0], "alice")
+		require.Equal(t, "- count: 3", docs[1])
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		fp := filepath.Join(dir, "out.xlsx")
+		tr := testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("--xlsx", "-o", fp, script))
+
+		tr = testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("add", fp, "--handle", "@out"))
+		tr = testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("--csv", "--no-header", "@out.data2"))
+		require.Equal(t, [][]string{{"3"}}, tr.BindCSV())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		fp := filepath.Join(dir, "report.slq")
+		require.NoError(t, os.WriteFile(fp, []byte(script+";\n"), 0o600))
+
+		tr := testrun.New(th.Context, t, tr)
+		require.NoError(t, tr.Exec("--csv", "--no-header", "--file", fp))
+		require.Equal(t, "1,alice\n2,bob\n3", tr.OutString())
+
+		tr = testrun.New(th.Context, t, tr)
+		require.Error(t, tr.Exec("--file", fp, "@people.data"))
+	})
+
+	t.Run("keep_going", func(t *testing.T) {
+		const failScript = "@people.data | count; @people.nope; @people.data | .[0:1]"
+
+		tr := testrun.New(th.Context, t, tr).Hush()
+		err := tr.Exec("--csv", "--no-header", failScript)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "statement 2 of 3")
+		require.Equal(t, "3", tr.OutString(), "statement 3 must not be executed")
+
+		tr = testrun.New(th.Context, t, tr).Hush()
+		require.Error(t, tr.Exec("--csv", "--no-header", "--keep-going", failScript))
+		require.Equal(t, "3\n1,alice", tr.OutString())
+	})
+}
@@ -0,0 +1,614 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/output/format"
+	"github.com/neilotoole/sq/cli/output/tablew"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/diffdoc"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/checksum"
+	"github.com/neilotoole/sq/libsq/core/ioz/scannerz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/core/termz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// watchChecksumPoll is how often the files of the query's file sources are
+// checked for changes, when watching.
+const watchChecksumPoll = time.Millisecond * 500
+
+// ansiClearScreen moves the cursor to the top left, and clears the screen.
+const ansiClearScreen = "\033[H\033[2J"
+
+// addSLQWatchFlags adds the flags for watch mode: --watch and --watch-diff.
+func addSLQWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration(flag.Watch, 0, flag.WatchUsage)
+	panicOn(cmd.RegisterFlagCompletionFunc(flag.Watch, completeNone))
+	cmd.Flags().Bool(flag.WatchDiff, false, flag.WatchDiffUsage)
+}
+
+// execSLQWatch executes the SLQ query in watch mode (--watch): the query is
+// re-executed every interval, and its output redrawn, until ctx is done.
+// The sources stay open between runs, via ru.Grips. However, if each of
+// the query's sources is a file source, the query is instead re-executed
+// when a source's ingest checksum changes, i.e. when its file has been
+// modified; the changed source's grip is evicted first, so that it's
+// re-ingested. Similarly, the grips of other sources whose data is
+// ingested, such as a MongoDB or REST source, are evicted before each
+// timed run.
+func execSLQWatch(ctx context.Context, cmd *cobra.Command, ru *run.Run, mArgs map[string]string,
+	slq string,
+) error {
+	for _, name := range []string{flag.Insert, flag.RenderSQL, flag.Explain, flag.ExplainAnalyze} {
+		if cmdFlagChanged(cmd, name) {
+			return errz.Errorf("--%s is not compatible with --%s", name, flag.Watch)
+		}
+	}
+
+	interval, err := cmd.Flags().GetDuration(flag.Watch)
+	if err != nil {
+		return errz.Err(err)
+	}
+	if interval <= 0 {
+		return errz.Errorf("invalid --%s value: must be a positive duration, e.g. 10s", flag.Watch)
+	}
+
+	o, err := getOptionsFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+	fm := getFormat(cmd, o)
+	if fm == format.XLSX {
+		return errz.Errorf("--%s doesn't support format {%s}", flag.Watch, fm)
+	}
+	newRecw := getRecordWriterFunc(fm)
+	if newRecw == nil {
+		fm, newRecw = format.Text, tablew.NewRecordWriter
+	}
+
+	w := &slqWatcher{
+		ru:       ru,
+		mArgs:    mArgs,
+		slq:      slq,
+		interval: interval,
+		newRecw:  newRecw,
+		pr:       ru.Writers.PrOut,
+		diff:     cmdFlagIsSetTrue(cmd, flag.WatchDiff),
+		recLines: isRecordLinesFormat(fm),
+		clear:    termz.IsTerminal(ru.Stdout) && !cmdFlagChanged(cmd, flag.FileOutput),
+		sums:     map[string]map[string]checksum.Checksum{},
+	}
+	if w.diff {
+		// The output is diffed as plain text; it's the diff that's colored.
+		w.pr = ru.Writers.PrOut.Clone()
+		w.pr.EnableColor(false)
+	}
+
+	srcs, err := watchSources(ctx, ru.Config.Collection, slq)
+	if err != nil {
+		return err
+	}
+	for _, src := range srcs {
+		switch {
+		case location.TypeOf(src.Location) == location.TypeFile:
+			w.fileSrcs = append(w.fileSrcs, src)
+		case !ru.Grips.IsSQLSource(src):
+			w.ingestSrcs = append(w.ingestSrcs, src)
+		}
+	}
+	// If there are only file sources, there's no need for the timer:
+	// the query is re-executed only when a file changes.
+	w.timed = len(srcs) == 0 || len(w.fileSrcs) < len(srcs)
+
+	return w.watch(ctx)
+}
+
+// watchSources returns the sources referenced by slq: those named by
+// handle, or else the active source, if any.
+func watchSources(ctx context.Context, coll *source.Collection, slq string) ([]*source.Source, error) {
+	a, err := ast.Parse(lg.FromContext(ctx), slq)
+	if err != nil {
+		return nil, err
+	}
+
+	handles := ast.ExtractHandles(a)
+	if len(handles) == 0 {
+		if active := coll.Active(); active != nil {
+			return []*source.Source{active}, nil
+		}
+		return nil, nil
+	}
+
+	srcs := make([]*source.Source, 0, len(handles))
+	for _, handle := range handles {
+		src, err := coll.Get(handle)
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, src)
+	}
+	return srcs, nil
+}
+
+// slqWatcher re-executes a SLQ query, for --watch.
+type slqWatcher struct {
+	ru      *run.Run
+	mArgs   map[string]string
+	newRecw output.NewRecordWriterFunc
+
+	// pr is the printing config for the query output. If diff is true,
+	// it's monochrome.
+	pr *output.Printing
+
+	// sums holds the ingest checksums of each of fileSrcs, keyed by handle.
+	sums map[string]map[string]checksum.Checksum
+
+	// prev is the output of the previous run.
+	prev *watchResult
+
+	slq      string
+	fileSrcs []*source.Source
+
+	// ingestSrcs holds the sources, other than fileSrcs, whose data is
+	// ingested, and thus must be re-ingested for each timed run.
+	ingestSrcs []*source.Source
+
+	interval time.Duration
+
+	// timed is true if the query is re-executed every interval. If false,
+	// the query is re-executed only when one of fileSrcs changes.
+	timed bool
+
+	// diff is true if the output should show the rows added and removed
+	// since the previous run (--watch-diff).
+	diff bool
+
+	// recLines is true if the output format renders the records one after
+	// another, following any header lines: see isRecordLinesFormat.
+	recLines bool
+
+	// clear is true if the screen is cleared before each run's output.
+	clear bool
+}
+
+// isRecordLinesFormat returns true if format fm renders the records one
+// after another, on their own lines, following any header lines. That is,
+// each of the output's lines, other than the header, belongs to a record.
+// For example, format text and format csv do, whereas format json, which
+// closes its array after the records, doesn't.
+func isRecordLinesFormat(fm format.Format) bool {
+	switch fm { //nolint:exhaustive
+	case format.Text, format.CSV, format.TSV, format.JSONL, format.JSONA:
+		return true
+	default:
+		return false
+	}
+}
+
+// watchResult is the result of a run of the query.
+type watchResult struct {
+	// body is the rendered output.
+	body string
+
+	// recMeta and recs are the records of the output. They're populated
+	// only for --watch-diff.
+	recMeta record.Meta
+	recs    []record.Record
+}
+
+// watch executes the query, and then re-executes it as the timer fires or
+// the file sources change, until ctx is done. An error is returned if the
+// first execution fails; a later failure is printed in place of the
+// output, and watching continues.
+func (w *slqWatcher) watch(ctx context.Context) error {
+	for _, src := range w.fileSrcs {
+		if _, err := w.checkChanged(ctx, src); err != nil {
+			return err
+		}
+	}
+
+	if err := w.runOnce(ctx, true); err != nil {
+		return err
+	}
+
+	var tickC, pollC <-chan time.Time
+	if w.timed {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
+	if len(w.fileSrcs) > 0 {
+		ticker := time.NewTicker(watchChecksumPoll)
+		defer ticker.Stop()
+		pollC = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tickC:
+			w.evictIngested(ctx)
+		case <-pollC:
+			if !w.evictChanged(ctx) {
+				continue
+			}
+		}
+
+		_ = w.runOnce(ctx, false)
+	}
+}
+
+// evictChanged evicts the grips of the file sources that have changed
+// since they were last checked, returning true if there were any.
+func (w *slqWatcher) evictChanged(ctx context.Context) bool {
+	log := lg.FromContext(ctx)
+	var changed bool
+	for _, src := range w.fileSrcs {
+		ok, err := w.checkChanged(ctx, src)
+		if err != nil {
+			// The file may be in the middle of being rewritten: try again
+			// at the next poll.
+			log.Debug("Watch: failed to check source for changes", lga.Src, src, lga.Err, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		log.Debug("Watch: source changed", lga.Src, src)
+		changed = true
+		if err = w.ru.Grips.Evict(ctx, src.Handle); err != nil {
+			log.Warn("Watch: failed to close changed source", lga.Src, src, lga.Err, err)
+		}
+	}
+	return changed
+}
+
+// evictIngested evicts the grips of ingestSrcs, so that their data is
+// re-ingested by the next run.
+func (w *slqWatcher) evictIngested(ctx context.Context) {
+	for _, src := range w.ingestSrcs {
+		if err := w.ru.Grips.Evict(ctx, src.Handle); err != nil {
+			lg.FromContext(ctx).Warn("Watch: failed to close source", lga.Src, src, lga.Err, err)
+		}
+	}
+}
+
+// checkChanged returns true if the ingest checksums of src have changed
+// since the previous check. The first check of src returns false.
+func (w *slqWatcher) checkChanged(ctx context.Context, src *source.Source) (bool, error) {
+	sums, err := w.ru.Files.IngestChecksums(ctx, src)
+	if err != nil {
+		return false, err
+	}
+
+	prev, ok := w.sums[src.Handle]
+	w.sums[src.Handle] = sums
+	return ok && !maps.Equal(prev, sums), nil
+}
+
+// runOnce executes the query, and prints the output. If first is true
+// and the query fails, the error is returned; otherwise, it's printed in
+// place of the output.
+func (w *slqWatcher) runOnce(ctx context.Context, first bool) error {
+	res, err := w.execute(ctx)
+	if err != nil && first {
+		return err
+	}
+	if ctx.Err() != nil {
+		// Interrupted: don't print a partial run.
+		return nil //nolint:nilerr
+	}
+
+	out := w.ru.Out
+	switch {
+	case w.clear:
+		_, _ = io.WriteString(out, ansiClearScreen)
+	case !first:
+		// Separate the output of each run.
+		_, _ = io.WriteString(out, "\n")
+	}
+
+	what := fmt.Sprintf("Every %s", w.interval)
+	if !w.timed {
+		what = "On change"
+	}
+	w.ru.Writers.PrOut.Faint.Fprintf(out, "%s: %s    %s\n\n", what, w.slq, time.Now().Format(time.DateTime))
+
+	if err != nil {
+		w.ru.Writers.PrOut.Error.Fprintf(out, "sq: %s\n", err)
+		return err
+	}
+
+	if w.diff {
+		var body string
+		if body, err = w.diffBody(ctx, res); err != nil {
+			return err
+		}
+		_, err = io.Copy(out, diffdoc.NewColorizer(ctx, w.ru.Writers.PrOut.Diff, strings.NewReader(body)))
+		return errz.Err(err)
+	}
+
+	_, err = io.WriteString(out, res.body)
+	return errz.Err(err)
+}
+
+// execute executes the query, returning its output, and, for --watch-diff,
+// its records. The result cache is bypassed: the point of watching is to
+// see fresh results.
+func (w *slqWatcher) execute(ctx context.Context) (*watchResult, error) {
+	buf := &bytes.Buffer{}
+	qc := run.NewQueryContext(w.ru, w.mArgs)
+	qc.AccessMode = driver.ModeReadOnly
+
+	var tee *recordTee
+	recw := w.newRecw(buf, w.pr)
+	if w.diff {
+		tee = &recordTee{RecordWriter: recw}
+		recw = tee
+	}
+
+	adapter := output.NewRecordWriterAdapter(ctx, recw)
+	execErr := libsq.ExecSLQ(ctx, qc, w.slq, adapter)
+	_, waitErr := adapter.Wait()
+	if execErr != nil {
+		return nil, execErr
+	}
+	if waitErr != nil {
+		return nil, waitErr
+	}
+
+	res := &watchResult{body: buf.String()}
+	if tee != nil {
+		res.recMeta, res.recs = tee.recMeta, tee.recs
+	}
+	return res, nil
+}
+
+var _ output.RecordWriter = (*recordTee)(nil)
+
+// recordTee is an output.RecordWriter that retains the records that it
+// passes through to the embedded RecordWriter.
+type recordTee struct {
+	output.RecordWriter
+	recMeta record.Meta
+	recs    []record.Record
+}
+
+// Open implements output.RecordWriter.
+func (t *recordTee) Open(ctx context.Context, recMeta record.Meta) error {
+	t.recMeta = recMeta
+	return t.RecordWriter.Open(ctx, recMeta)
+}
+
+// WriteRecords implements output.RecordWriter.
+func (t *recordTee) WriteRecords(ctx context.Context, recs []record.Record) error {
+	t.recs = append(t.recs, recs...)
+	return t.RecordWriter.WriteRecords(ctx, recs)
+}
+
+// diffBody returns the output of the current run, cur, as a unified diff
+// against the previous run's output, with each line marked with the usual
+// unified diff prefix: "+" for an added line, "-" for a removed line, and
+// " " for an unchanged line. The diff's header lines are omitted, and the
+// whole of the output is included as context.
+//
+// Where possible, the records of the two runs are compared, rather than
+// their rendered output: see diffRecords. Otherwise, for example if the
+// output format is json, the rendered output is compared line by line.
+func (w *slqWatcher) diffBody(ctx context.Context, cur *watchResult) (string, error) {
+	prev := w.prev
+	w.prev = cur
+
+	switch {
+	case prev == nil, prev.body == cur.body:
+		return prefixLines(cur.body, " "), nil
+	case w.recLines && cur.recMeta != nil && prev.recMeta.Equalish(cur.recMeta):
+		body, ok, err := w.diffRecords(ctx, prev, cur)
+		if err != nil || ok {
+			return body, err
+		}
+	}
+
+	lines := strings.Count(prev.body, "\n") + strings.Count(cur.body, "\n") + 1
+	unified, err := diffdoc.ComputeUnified(ctx, "previous", "current", lines, prev.body, cur.body)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	for _, line := range strings.SplitAfter(scannerz.TrimHead(ctx, unified, 2), "\n") {
+		if !strings.HasPrefix(line, "@@ ") {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String(), nil
+}
+
+// diffRecords is like diffBody, but compares the records of prev and cur,
+// rather than their rendered output. Thus, for example, a value that widens
+// a column of the text format's table doesn't mark each of the table's
+// rows as changed. The rows added since prev, the rows removed, and the
+// unchanged rows are rendered together, and each rendered row is marked
+// accordingly; the header lines are unchanged. It returns false if the
+// rendered output can't be matched up with the records.
+func (w *slqWatcher) diffRecords(ctx context.Context, prev, cur *watchResult) (body string, ok bool, err error) {
+	recs, marks, err := diffRecordMarks(ctx, prev.recs, cur.recs)
+	if err != nil {
+		return "", false, err
+	}
+
+	if body, err = w.render(ctx, w.pr, cur.recMeta, recs); err != nil {
+		return "", false, err
+	}
+
+	// Determine how many lines each record is rendered as. Typically,
+	// that's one line, but a value may contain a newline.
+	noHeader := w.pr.Clone()
+	noHeader.ShowHeader = false
+	recLines := make([]int, len(recs))
+	var rows string
+	if rows, err = w.render(ctx, noHeader, cur.recMeta, recs); err != nil {
+		return "", false, err
+	}
+	var total int
+	if strings.Count(rows, "\n") == len(recs) {
+		for i := range recLines {
+			recLines[i] = 1
+		}
+		total = len(recs)
+	} else {
+		for i, rec := range recs {
+			if rows, err = w.render(ctx, noHeader, cur.recMeta, []record.Record{rec}); err != nil {
+				return "", false, err
+			}
+			recLines[i] = strings.Count(rows, "\n")
+			total += recLines[i]
+		}
+	}
+
+	lines := strings.SplitAfter(body, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	header := len(lines) - total
+	if header < 0 {
+		return "", false, nil
+	}
+
+	sb := strings.Builder{}
+	for _, line := range lines[:header] {
+		sb.WriteByte(' ')
+		sb.WriteString(line)
+	}
+	lines = lines[header:]
+	for i := range recs {
+		for _, line := range lines[:recLines[i]] {
+			sb.WriteByte(marks[i])
+			sb.WriteString(line)
+		}
+		lines = lines[recLines[i]:]
+	}
+	return sb.String(), true, nil
+}
+
+// render returns recs, rendered via w.newRecw.
+func (w *slqWatcher) render(ctx context.Context, pr *output.Printing, recMeta record.Meta,
+	recs []record.Record,
+) (string, error) {
+	buf := &bytes.Buffer{}
+	recw := w.newRecw(buf, pr)
+	if err := recw.Open(ctx, recMeta); err != nil {
+		return "", err
+	}
+	if err := recw.WriteRecords(ctx, recs); err != nil {
+		return "", err
+	}
+	if err := recw.Flush(ctx); err != nil {
+		return "", err
+	}
+	if err := recw.Close(ctx); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// diffRecordMarks compares the records of the previous run, prev, with the
+// records of the current run, cur. It returns the records of both runs, in
+// diff order, and the unified diff prefix of each record: '-' for a record
+// of prev only, '+' for a record of cur only, and ' ' for a record of both.
+func diffRecordMarks(ctx context.Context, prev, cur []record.Record) (recs []record.Record, marks []byte,
+	err error,
+) {
+	before, after := canonicalRecords(prev), canonicalRecords(cur)
+	if before == after {
+		return cur, []byte(strings.Repeat(" ", len(cur))), nil
+	}
+
+	unified, err := diffdoc.ComputeUnified(ctx, "previous", "current", len(prev)+len(cur)+1, before, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var i, j int
+	for _, line := range strings.Split(scannerz.TrimHead(ctx, unified, 2), "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case ' ':
+			recs = append(recs, cur[j])
+			i++
+			j++
+		case '-':
+			recs = append(recs, prev[i])
+			i++
+		case '+':
+			recs = append(recs, cur[j])
+			j++
+		default:
+			// A hunk header.
+			continue
+		}
+		marks = append(marks, line[0])
+	}
+
+	if i != len(prev) || j != len(cur) {
+		return nil, nil, errz.Errorf("watch: diff of records: matched %d of %d previous and %d of %d current records",
+			i, len(prev), j, len(cur))
+	}
+	return recs, marks, nil
+}
+
+// canonicalRecords returns recs as text, one line per record, such that
+// two records have the same line only if their values are the same.
+func canonicalRecords(recs []record.Record) string {
+	sb := strings.Builder{}
+	for _, rec := range recs {
+		for i, val := range rec {
+			if i > 0 {
+				sb.WriteByte('\t')
+			}
+			sb.WriteString(strconv.Quote(fmt.Sprintf("%T:%v", val, val)))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// prefixLines returns s with prefix inserted at the start of each line.
+func prefixLines(s, prefix string) string {
+	if s == "" {
+		return s
+	}
+	lines := strings.SplitAfter(s, "\n")
+	sb := strings.Builder{}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		sb.WriteString(prefix)
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
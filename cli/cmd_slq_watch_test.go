@@ -0,0 +1,104 @@
+package cli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// TestCmdSLQ_Watch verifies that, for a file source, --watch re-executes
+// the query when the file changes, and that --watch-diff marks the added
+// rows.
+func TestCmdSLQ_Watch(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "jobs.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("id,status\n1,running\n2,done\n"), 0o600))
+	src := source.Source{Handle: "@watch_csv", Type: drivertype.CSV, Location: fp}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	go func() {
+		time.Sleep(time.Second)
+		assert.NoError(t, os.WriteFile(fp, []byte("id,status\n1,running\n2,done\n3,running\n"), 0o600))
+		time.Sleep(time.Second * 2)
+		cancelFn()
+	}()
+
+	// The interval is long, so any re-execution is due to the file change.
+	tr := testrun.New(ctx, t, nil).Add(src)
+	require.NoError(t, tr.Exec("--csv", "-H", "--watch", "1h", "--watch-diff",
+		`@watch_csv.data | where(.status == "running") | .id`))
+
+	got := tr.OutString()
+	require.Equal(t, 2, strings.Count(got, "On change: "), got)
+	require.Contains(t, got, "\n 1\n")
+	require.Contains(t, got, "\n+3")
+}
+
+// TestCmdSLQ_WatchDiff_Records verifies that --watch-diff compares the
+// records of each run, rather than the rendered table: a new row that
+// widens a column doesn't mark the unchanged rows as changed.
+func TestCmdSLQ_WatchDiff_Records(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "jobs.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("id,name\n1,a\n2,b\n"), 0o600))
+	src := source.Source{Handle: "@watch_csv", Type: drivertype.CSV, Location: fp}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	go func() {
+		time.Sleep(time.Second)
+		assert.NoError(t, os.WriteFile(fp, []byte("id,name\n1,a\n3,a_much_wider_name\n"), 0o600))
+		time.Sleep(time.Second * 2)
+		cancelFn()
+	}()
+
+	tr := testrun.New(ctx, t, nil).Add(src)
+	require.NoError(t, tr.Exec("--text", "--watch", "1h", "--watch-diff", "@watch_csv.data"))
+
+	got := tr.OutString()
+	_, second, ok := strings.Cut(got[strings.Index(got, "On change: ")+1:], "On change: ")
+	require.True(t, ok, got)
+	_, table, ok := strings.Cut(second, "\n\n")
+	require.True(t, ok, second)
+	lines := strings.Split(strings.TrimRight(table, "\n"), "\n")
+	require.Len(t, lines, 4, table)
+	require.True(t, strings.HasPrefix(lines[0], " id "), table)
+	require.True(t, strings.HasPrefix(lines[1], " 1 "), table)
+	require.True(t, strings.HasPrefix(lines[2], "-2 "), table)
+	require.True(t, strings.HasPrefix(lines[3], "+3 "), table)
+}
+
+func TestCmdSLQ_Watch_Errors(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "jobs.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("id,status\n1,running\n"), 0o600))
+	src := source.Source{Handle: "@watch_csv", Type: drivertype.CSV, Location: fp}
+
+	testCases := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "diff_no_watch", args: []string{"--watch-diff"}, wantErr: "--watch-diff requires --watch"},
+		{name: "zero_interval", args: []string{"--watch", "0s"}, wantErr: "invalid --watch value"},
+		{name: "render_sql", args: []string{"--watch", "1s", "--render-sql"}, wantErr: "not compatible"},
+		{name: "stream", args: []string{"--watch", "1s", "--stream"}, wantErr: "not compatible"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := testrun.New(context.Background(), t, nil).Hush().Add(src)
+			err := tr.Exec(append(tc.args, "@watch_csv.data")...)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
@@ -128,6 +128,20 @@ func execSQL(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if explainFlag, analyze := getExplainFlag(cmd); explainFlag != "" {
+		if cmdFlagChanged(cmd, flag.Insert) {
+			return errz.Errorf("--%s is not compatible with --%s", flag.Insert, explainFlag)
+		}
+
+		// Explaining a query only reads the source: only a SELECT query
+		// can be analyzed, i.e. executed.
+		srcMode := driver.ModeReadOnly
+		if readOnlySrc {
+			srcMode = driver.ModeReadOnlyExplicit
+		}
+		return execSQLExplain(ctx, ru, activeSrc, srcMode, analyze)
+	}
+
 	if !cmdFlagChanged(cmd, flag.Insert) {
 		// The user didn't specify the --insert=@src.tbl flag, so we just
 		// want to print the records. Pass the source access mode
@@ -156,6 +170,12 @@ func execSQL(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if destSrc != activeSrc {
+		if err = applySourceOptions(cmd, destSrc); err != nil {
+			return err
+		}
+	}
+
 	return execSQLInsert(ctx, ru, activeSrc, destSrc, destTbl, readOnlySrc)
 }
 
@@ -199,9 +219,15 @@ func execSQLPrint(ctx context.Context, ru *run.Run, fromSrc *source.Source, srcM
 		return ru.Writers.StmtExec.StmtExecuted(ctx, fromSrc, affected, elapsed)
 	}
 
-	// This is a query, use QuerySQL
+	// This is a query, use QuerySQL. A SELECT may be served from the result
+	// cache, if configured; other queries (e.g. DELETE ... RETURNING) may
+	// have side effects, so they're always executed.
+	fs := ru.Files
+	if !sqlIsSelect(sql) {
+		fs = nil
+	}
 	recw := output.NewRecordWriterAdapter(ctx, ru.Writers.Record)
-	err = libsq.QuerySQL(ctx, grip, nil, recw, nil, sql)
+	err = libsq.QuerySQLCached(ctx, fs, fromSrc, grip, recw, sql)
 	if err != nil {
 		return err
 	}
@@ -209,6 +235,16 @@ func execSQLPrint(ctx context.Context, ru *run.Run, fromSrc *source.Source, srcM
 	return err
 }
 
+// sqlIsSelect returns true if the first keyword of query is SELECT or WITH.
+func sqlIsSelect(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+
+	return strings.EqualFold(fields[0], "SELECT") || strings.EqualFold(fields[0], "WITH")
+}
+
 // execSQLInsert executes the SQL and inserts resulting records
 // into destTbl in destSrc. readOnlySrc controls whether the source
 // (fromSrc) is opened READ_ONLY; the destination is always opened
@@ -257,6 +293,13 @@ func execSQLInsert(ctx context.Context, ru *run.Run,
 		tuning.OptRecBufSize.Get(destSrc.Options),
 		libsq.DBWriterCreateTableIfNotExistsHook(destTbl),
 	)
+	policy, err := getMaskPolicy(ctx, ru, destSrc)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		inserter.SetMask(policy)
+	}
 
 	start := time.Now()
 	err = libsq.QuerySQL(ctx, fromGrip, nil, inserter, nil, args[0])
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLIsSelect(t *testing.T) {
+	testCases := map[string]bool{
+		"":                                       false,
+		"   ":                                    false,
+		"SELECT * FROM actor":                    true,
+		"  select 1":                             true,
+		"\nWITH x AS (SELECT 1) SELECT * FROM x": true,
+		"DELETE FROM actor RETURNING *":          false,
+		"INSERT INTO actor VALUES (1)":           false,
+		"selected":                               false,
+	}
+
+	for query, want := range testCases {
+		require.Equal(t, want, sqlIsSelect(query), query)
+	}
+}
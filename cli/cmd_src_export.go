@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output/yamlw"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// srcExportDoc is the document written by "sq src export", and read
+// by "sq src import".
+type srcExportDoc struct {
+	Sources []*source.Source `yaml:"sources" json:"sources"`
+}
+
+func newSrcExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "export [GROUP]",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeGroup(1),
+		Short:             "Export sources as YAML",
+		Long: `Export sources as YAML, for import into another sq config via
+"sq src import". If GROUP is specified, only the sources in GROUP (and
+its subgroups) are exported; otherwise every source is exported.
+
+By default, ${scheme:path} placeholders (keyring, env, file) are written
+verbatim, so the export holds no secrets that aren't already inline in
+the config. Note that a ${keyring:...} placeholder is only meaningful on
+a machine whose keyring holds the referenced entry. With --expand, each
+placeholder is resolved, and the resolved value is written in plaintext.
+
+When --output is used, the output file is created with mode 0600.`,
+		RunE: execSrcExport,
+		Example: `  # Export all sources
+  $ sq src export > sources.yml
+
+  # Export the sources in group "prod"
+  $ sq src export prod -o prod.yml
+
+  # Self-contained export, with placeholders resolved
+  $ sq src export --expand -o sources.yml`,
+	}
+
+	cmd.Flags().StringP(flag.FileOutput, flag.FileOutputShort, "", flag.FileOutputUsage)
+	// See newConfigExportCmd: execSrcExport handles --output itself.
+	cmdMarkPlainStdout(cmd)
+	return cmd
+}
+
+func execSrcExport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ru := run.FromContext(ctx)
+	coll := ru.Config.Collection
+
+	var (
+		srcs []*source.Source
+		err  error
+	)
+	if len(args) == 0 {
+		srcs = coll.Sources()
+	} else if srcs, err = coll.SourcesInGroup(args[0]); err != nil {
+		return err
+	}
+
+	doc := &srcExportDoc{Sources: make([]*source.Source, len(srcs))}
+	for i := range srcs {
+		doc.Sources[i] = srcs[i].Clone()
+	}
+
+	if cmdFlagIsSetTrue(cmd, flag.Expand) {
+		if err = exportExpandSources(ctx, ru, doc.Sources, "src export"); err != nil {
+			return err
+		}
+		lg.FromContext(ctx).Warn("sq src export --expand: resolved secrets are written in plaintext")
+	}
+
+	rendered, err := yamlw.MarshalToString(ru.Writers.PrOut, doc)
+	if err != nil {
+		return errz.Wrap(err, "src export")
+	}
+	return writeExport(cmd, ru, []byte(rendered), "src export")
+}
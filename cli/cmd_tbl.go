@@ -8,12 +8,15 @@ import (
 
 	"github.com/neilotoole/sq/cli/flag"
 	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq"
 	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/progress"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/core/tablefq"
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/driver/dialect"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/libsq/source"
 )
 
@@ -54,13 +57,17 @@ func newTblCopyCmd() *cobra.Command {
   $ sq tbl copy .actor
 
   # Copy table structure, but don't copy table data
-  $ sq tbl copy --data=false .actor`,
+  $ sq tbl copy --data=false .actor
+
+  # Copy table, masking the data per the masking policy file
+  $ sq tbl copy --mask=mask-policy.yml .customer .customer_masked`,
 	}
 
 	addTextFormatFlags(cmd)
 	cmd.Flags().BoolP(flag.JSON, flag.JSONShort, false, flag.JSONUsage)
 	addOptionFlag(cmd.Flags(), OptCompact)
 	cmd.Flags().Bool(flag.TblData, true, flag.TblDataUsage)
+	addOptionFlag(cmd.Flags(), mask.OptPolicy)
 
 	return cmd
 }
@@ -137,9 +144,33 @@ func execTblCopy(cmd *cobra.Command, args []string) error {
 	fromTbl := tablefq.New(tblHandles[0].tbl)
 	toTbl := tablefq.New(tblHandles[1].tbl)
 
-	bar := progress.FromContext(ctx).NewWaiter("Copy table")
-	copied, err := sqlDrvr.CopyTable(ctx, db, fromTbl, toTbl, copyData)
-	bar.Stop()
+	var policy *mask.Policy
+	if copyData {
+		if policy, err = getMaskPolicy(ctx, ru, tblHandles[1].src); err != nil {
+			return err
+		}
+	}
+
+	var copied int64
+	if policy != nil {
+		// The data must be masked, so it can't be copied by the database.
+		// Instead, copy the table structure, and then pass each row through
+		// a masking DBWriter.
+		bar := progress.FromContext(ctx).NewWaiter("Copy table")
+		_, err = sqlDrvr.CopyTable(ctx, db, fromTbl, toTbl, false)
+		bar.Stop()
+		if err == nil {
+			if copied, err = libsq.CopyTableData(ctx, grip, fromTbl, grip, toTbl, policy); err != nil {
+				// Don't leave behind a partial copy.
+				lg.WarnIfError(lg.FromContext(ctx), "Drop partially copied table",
+					sqlDrvr.DropTable(ctx, db, toTbl, true))
+			}
+		}
+	} else {
+		bar := progress.FromContext(ctx).NewWaiter("Copy table")
+		copied, err = sqlDrvr.CopyTable(ctx, db, fromTbl, toTbl, copyData)
+		bar.Stop()
+	}
 	if err != nil {
 		return errz.Wrapf(err, "failed tbl copy %s.%s --> %s.%s",
 			tblHandles[0].handle, tblHandles[0].tbl,
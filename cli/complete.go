@@ -209,6 +209,61 @@ func completeGroup(maxVals int) completionFunc {
 	}
 }
 
+// completeQueryName is a completionFunc that suggests saved query names.
+// The max arg is the maximum number of completions. Set to 0
+// for no limit.
+func completeQueryName(maxVals int) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxVals > 0 && len(args) >= maxVals {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		ru := getRun(cmd)
+		names := lo.Filter(ru.Config.QueryNames(), func(item string, _ int) bool {
+			return strings.HasPrefix(item, toComplete)
+		})
+		names, _ = lo.Difference(names, args)
+
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeQueryArg is a completionFunc for the --arg flag of "sq run". It
+// suggests the names of the saved query's args (args[0] being the query
+// name) that haven't already been supplied.
+func completeQueryArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ru := getRun(cmd)
+	q := ru.Config.FindQuery(args[0])
+	if q == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	params, err := q.Params()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	vals, _ := cmd.Flags().GetStringArray(flag.Arg)
+	suggestions := make([]string, 0, len(params))
+	for _, p := range params {
+		if !strings.HasPrefix(p, toComplete) {
+			continue
+		}
+		if slices.ContainsFunc(vals, func(v string) bool {
+			return v == p || strings.HasPrefix(v, p+":") || strings.HasPrefix(v, p+"=")
+		}) {
+			continue
+		}
+		suggestions = append(suggestions, p)
+	}
+
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
 // completeHandleOrGroup returns the matching list of handles+groups.
 func completeHandleOrGroup(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	switch {
@@ -240,11 +295,12 @@ func completeSLQ(cmd *cobra.Command, args []string, toComplete string) ([]string
 	// VALUE. Count those fake positionals so we can skip them.
 	//
 	// The "=" form ("--arg=NAME:VALUE") is consumed by pflag as a single token,
-	// so it contributes no fake positionals. We tell the two apart by ":": a
-	// joined NAME:VALUE always contains one, a dangling NAME never does. This
-	// assumes valid input (NAME is a bare identifier, per stringz.ValidIdent);
-	// malformed input like "--arg fo:o" or "--arg=foo" is misclassified, but
-	// such input is rejected at exec time, so the cost is only stray completion.
+	// so it contributes no fake positionals; likewise "--arg NAME=VALUE". We
+	// tell the forms apart by ":" or "=": a joined NAME:VALUE or NAME=VALUE
+	// always contains one, a dangling NAME never does. This assumes valid input
+	// (NAME is a bare identifier, per stringz.ValidIdent); malformed input like
+	// "--arg fo:o" or "--arg=foo" is misclassified, but such input is rejected
+	// at exec time, so the cost is only stray completion.
 	//
 	// Cobra traverses the arg list once per positional during completion (to
 	// check each intermediate word), and StringArray is cumulative, so vals may
@@ -260,7 +316,7 @@ func completeSLQ(cmd *cobra.Command, args []string, toComplete string) ([]string
 				continue
 			}
 			seen[v] = struct{}{}
-			if !strings.Contains(v, ":") {
+			if !strings.ContainsAny(v, ":=") {
 				spaceFormArgCount++
 			}
 		}
@@ -680,3 +680,32 @@ func enableCompletionLog(ctx context.Context) context.Context {
 	o[cli.OptShellCompletionLog.Key()] = true
 	return ctx
 }
+
+func TestCompleteSavedQuery(t *testing.T) {
+	tu.SkipIssueWindows(t, tu.GH372ShellCompletionWin)
+
+	th := testh.New(t)
+	tr := testrun.New(th.Context, t, nil)
+	require.NoError(t, tr.Exec("query", "add", "by-region", ".data | where(.region == $region && .age > $age)"))
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("query", "add", "all", ".data"))
+
+	got := testComplete(t, tr, "run", "")
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, got.result)
+	assert.Equal(t, []string{"all", "by-region"}, got.values)
+
+	got = testComplete(t, tr, "run", "b")
+	assert.Equal(t, []string{"by-region"}, got.values)
+
+	got = testComplete(t, tr, "run", "by-region", "")
+	assert.Empty(t, got.values)
+
+	got = testComplete(t, tr, "query", "rm", "all", "")
+	assert.Equal(t, []string{"by-region"}, got.values)
+
+	got = testComplete(t, tr, "run", "by-region", "--"+flag.Arg, "")
+	assert.Equal(t, []string{"region", "age"}, got.values)
+
+	got = testComplete(t, tr, "run", "by-region", "--"+flag.Arg, "region=EU", "--"+flag.Arg, "")
+	assert.Equal(t, []string{"age"}, got.values)
+}
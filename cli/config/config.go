@@ -45,6 +45,9 @@ type Config struct {
 	// Collection is the set of data sources.
 	Collection *source.Collection `yaml:"collection" json:"collection"`
 
+	// Queries is the set of saved named queries. See "sq run".
+	Queries []*Query `yaml:"queries,omitempty" json:"queries,omitempty"`
+
 	// Ext holds sq config extensions, such as user driver config.
 	Ext Ext `yaml:"-" json:"-"`
 }
@@ -87,6 +90,10 @@ func Valid(cfg *Config) error {
 		}
 	}
 
+	if err := validQueries(cfg.Queries); err != nil {
+		return err
+	}
+
 	return nil
 }
 
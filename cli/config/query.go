@@ -0,0 +1,120 @@
+package config
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+)
+
+// Query is a named SLQ query saved in config, and executed via
+// "sq run NAME". The query's $args are supplied via --arg when the
+// query is run; Args holds default values for those args.
+type Query struct { //nolint:govet // field alignment
+	// Name is the query's name, e.g. "top-customers".
+	Name string `yaml:"name" json:"name"`
+
+	// Description is an optional human-readable description.
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// SLQ is the query text.
+	SLQ string `yaml:"slq" json:"slq"`
+
+	// Args holds default values for the query's args, keyed by arg
+	// name (without the leading $).
+	Args map[string]string `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// Params parses the query's SLQ, and returns the names of the args that
+// it references, without the leading $ (e.g. "region"), in order of first
+// appearance. If the SLQ is a multi-statement script, the args of every
+// statement are returned. An error is returned if the SLQ is not valid.
+func (q *Query) Params() ([]string, error) {
+	stmts, err := ast.SplitStatements(lg.Discard(), q.SLQ)
+	if err != nil {
+		return nil, errz.Wrapf(err, "query %s", q.Name)
+	}
+
+	var params []string
+	for _, stmt := range stmts {
+		a, err := ast.Parse(lg.Discard(), stmt)
+		if err != nil {
+			return nil, errz.Wrapf(err, "query %s", q.Name)
+		}
+
+		for _, arg := range ast.NewInspector(a).FindArgs() {
+			if !slices.Contains(params, arg) {
+				params = append(params, arg)
+			}
+		}
+	}
+	return params, nil
+}
+
+var queryNamePattern = regexp.MustCompile(`\A[a-zA-Z][a-zA-Z0-9_-]*\z`)
+
+// ValidQueryName returns an error if name is not a valid saved query
+// name. A name must start with a letter, and may contain letters,
+// numbers, underscore, and hyphen.
+func ValidQueryName(name string) error {
+	if queryNamePattern.MatchString(name) {
+		return nil
+	}
+	return errz.Errorf("invalid query name: %q: must start with a letter, and contain only "+
+		"letters, numbers, underscore, or hyphen", name)
+}
+
+// FindQuery returns the saved query with name, or nil if not found.
+func (c *Config) FindQuery(name string) *Query {
+	for _, q := range c.Queries {
+		if q != nil && q.Name == name {
+			return q
+		}
+	}
+	return nil
+}
+
+// QueryNames returns the names of the saved queries, sorted.
+func (c *Config) QueryNames() []string {
+	names := make([]string, 0, len(c.Queries))
+	for _, q := range c.Queries {
+		if q != nil {
+			names = append(names, q.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// validQueries returns an error if any of queries is invalid, or if
+// there are duplicate names.
+func validQueries(queries []*Query) error {
+	names := make(map[string]struct{}, len(queries))
+	for i, q := range queries {
+		if q == nil {
+			return errz.Errorf("config: invalid '.queries[%d]': nil", i)
+		}
+		if err := ValidQueryName(q.Name); err != nil {
+			return errz.Wrapf(err, "config: invalid '.queries[%d]'", i)
+		}
+		if _, ok := names[q.Name]; ok {
+			return errz.Errorf("config: invalid '.queries': duplicate name: %s", q.Name)
+		}
+		names[q.Name] = struct{}{}
+
+		if strings.TrimSpace(q.SLQ) == "" {
+			return errz.Errorf("config: invalid '.queries[%d]': query %s: empty SLQ", i, q.Name)
+		}
+		for k := range q.Args {
+			if err := stringz.ValidIdent(k); err != nil {
+				return errz.Errorf("config: invalid '.queries[%d]': query %s: invalid arg name: %s",
+					i, q.Name, k)
+			}
+		}
+	}
+	return nil
+}
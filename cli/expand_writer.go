@@ -153,6 +153,12 @@ func (ew *expandSourceWriter) Moved(coll *source.Collection, old, nu *source.Sou
 	return ew.w.Moved(coll, old, nu)
 }
 
+// Imported implements output.SourceWriter. The rows carry no
+// locations, so there's nothing to expand.
+func (ew *expandSourceWriter) Imported(rows []output.SourceImportRow) error {
+	return ew.w.Imported(rows)
+}
+
 // Group implements output.SourceWriter.
 func (ew *expandSourceWriter) Group(group *source.Group) error {
 	group, err := ew.group(group)
@@ -64,6 +64,10 @@ func (w *recordingSourceWriter) Moved(_ *source.Collection, old, nu *source.Sour
 	return nil
 }
 
+func (w *recordingSourceWriter) Imported([]output.SourceImportRow) error {
+	return nil
+}
+
 func (w *recordingSourceWriter) Group(group *source.Group) error {
 	w.gotGroup = group
 	return nil
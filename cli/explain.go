@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/output/format"
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// getExplainFlag returns the name of the --explain or --explain-analyze
+// flag, if either is set, and whether the plan is to be analyzed. If
+// neither flag is set, name is empty.
+func getExplainFlag(cmd *cobra.Command) (name string, analyze bool) {
+	switch {
+	case cmdFlagIsSetTrue(cmd, flag.ExplainAnalyze):
+		return flag.ExplainAnalyze, true
+	case cmdFlagIsSetTrue(cmd, flag.Explain):
+		return flag.Explain, false
+	default:
+		return "", false
+	}
+}
+
+// execSLQExplain writes the plan of the SQL that the SLQ query renders to,
+// via ru.Writers.Plan. For a cross-source query, the join copy tasks are
+// executed, and listed in the output. See also: execSLQRenderSQL.
+func execSLQExplain(ctx context.Context, ru *run.Run, mArgs map[string]string, slq string, analyze bool) error {
+	qc := run.NewQueryContext(ru, mArgs)
+	// SLQ queries only read from their sources.
+	qc.AccessMode = driver.ModeReadOnly
+	warnExplainFormat(ctx, ru)
+
+	plan, err := libsq.ExplainSLQ(ctx, qc, slq, analyze)
+	if err != nil {
+		return errz.Wrap(err, "explain")
+	}
+
+	outPlan := newOutputPlan(plan)
+	outPlan.SLQ = slq
+	return ru.Writers.Plan.Plan(outPlan)
+}
+
+// execSQLExplain writes the plan of the SQL input, via ru.Writers.Plan.
+// Only a SELECT query can be analyzed, because analyzing the query
+// executes it. As a further safeguard, libsq.ExplainSQL rolls back the
+// analyzed query, because a WITH query can still modify data.
+func execSQLExplain(ctx context.Context, ru *run.Run, src *source.Source, srcMode driver.AccessMode,
+	analyze bool,
+) error {
+	query := ru.Args[0]
+	if analyze && !sqlIsSelect(query) {
+		return errz.Errorf("--%s executes the query, so it can only be used with a SELECT query; use --%s instead",
+			flag.ExplainAnalyze, flag.Explain)
+	}
+	warnExplainFormat(ctx, ru)
+
+	grip, err := ru.Grips.Open(ctx, src, srcMode)
+	if err != nil {
+		return err
+	}
+
+	plan, err := libsq.ExplainSQL(ctx, grip, query, analyze)
+	if err != nil {
+		return errz.Wrap(err, "explain")
+	}
+
+	return ru.Writers.Plan.Plan(newOutputPlan(plan))
+}
+
+// warnExplainFormat logs a warning if --explain has no writer for the
+// requested format, and so falls back to text.
+func warnExplainFormat(ctx context.Context, ru *run.Run) {
+	//nolint:exhaustive // explicit allow-list; other formats intentionally fall back to text.
+	switch fm := getFormat(ru.Cmd, ru.Config.Options); fm {
+	case format.Text, format.JSON, format.YAML:
+	default:
+		lg.FromContext(ctx).Warn(
+			"--explain has no writer for the requested format; falling back to text",
+			"format", fm,
+		)
+	}
+}
+
+// newOutputPlan returns the output form of plan.
+func newOutputPlan(plan *libsq.QueryPlan) output.QueryPlan {
+	outPlan := output.QueryPlan{
+		Plan:    plan.Plan,
+		SQL:     plan.SQL,
+		Dialect: plan.Dialect.String(),
+		Target:  plan.Target,
+		Analyze: plan.Analyze,
+	}
+	for _, jc := range plan.JoinCopies {
+		outPlan.JoinCopies = append(outPlan.JoinCopies, output.JoinCopy{From: jc.From, To: jc.To})
+	}
+	return outPlan
+}
@@ -49,6 +49,25 @@ const (
 	RenderSQL      = "render-sql"
 	RenderSQLUsage = `Render the SLQ to SQL without executing it`
 
+	Stream      = "stream"
+	StreamUsage = `Evaluate the query row-by-row as input arrives, e.g. from stdin`
+
+	Watch          = "watch"
+	WatchUsage     = `Re-execute the query every interval, e.g. 10s, and redraw the output`
+	WatchDiff      = "watch-diff"
+	WatchDiffUsage = `With --watch, highlight rows added or removed since the previous run`
+
+	Explain             = "explain"
+	ExplainUsage        = `Show the query plan instead of executing the query`
+	ExplainAnalyze      = "explain-analyze"
+	ExplainAnalyzeUsage = `Execute the query, and show the query plan with actual row counts`
+
+	SLQFile      = "file"
+	SLQFileUsage = "Read SLQ query or script from <file>"
+
+	KeepGoing      = "keep-going"
+	KeepGoingUsage = "Continue executing a multi-statement script after a statement fails"
+
 	Reveal      = "reveal"
 	RevealUsage = "Show secret values in output (don't redact passwords; print keyring values)"
 
@@ -168,6 +187,14 @@ const (
 	Arg      = "arg"
 	ArgUsage = "Set a string value to a variable"
 
+	QueryArgUsage = "Save a default value for a query variable"
+
+	QueryDescription      = "description"
+	QueryDescriptionUsage = "Description of the saved query"
+
+	QueryForce      = "force"
+	QueryForceUsage = "Overwrite an existing saved query"
+
 	Config      = "config"
 	ConfigUsage = "Load config from here"
 
@@ -187,6 +214,12 @@ const (
 	InspectSchemataShort = "S"
 	InspectSchemataUsage = "List schemas (in current catalog) only"
 
+	CheckJUnit      = "junit"
+	CheckJUnitUsage = "Also write a JUnit XML report to <file>"
+
+	InspectProfile      = "profile"
+	InspectProfileUsage = "Profile column values (nulls, distinct, min/max, etc.)"
+
 	DiffOverview      = "overview"
 	DiffOverviewShort = "O"
 	DiffOverviewUsage = "Compare source overview"
@@ -250,3 +283,14 @@ var OutputFormatFlags = []string{
 	XML,
 	YAML,
 }
+
+const (
+	SrcImportOnConflict      = "on-conflict"
+	SrcImportOnConflictUsage = "How to handle a handle conflict: skip or rename"
+
+	SrcImportInline      = "inline"
+	SrcImportInlineUsage = "Keep inline credentials, instead of moving them to the keyring"
+
+	SrcImportPing      = "ping"
+	SrcImportPingUsage = "Ping each source, and skip those that fail"
+)
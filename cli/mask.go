@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/neilotoole/sq/cli/run"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/mask"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// getMaskPolicy returns the masking policy that applies to data written to
+// destSrc, as specified by mask.OptPolicy, or nil if there's no policy. The
+// policy's salt, which may be a ${scheme:path} placeholder, is resolved.
+func getMaskPolicy(ctx context.Context, ru *run.Run, destSrc *source.Source) (*mask.Policy, error) {
+	fpath := mask.OptPolicy.Get(destSrc.Options)
+	if fpath == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	policy, err := mask.LoadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	if ru.SecretRegistry != nil {
+		if policy.Salt, err = ru.SecretRegistry.Expand(ctx, policy.Salt); err != nil {
+			return nil, errz.Wrapf(err, "mask policy file %s: salt", fpath)
+		}
+	}
+	if policy.Salt == "" {
+		return nil, errz.Errorf("mask policy file %s: salt is empty", fpath)
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// TestMaskPolicy verifies that a masking policy is applied by --insert and
+// tbl copy, and that masking is deterministic across tables.
+func TestMaskPolicy(t *testing.T) {
+	th := testh.New(t)
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "customer.csv")
+	require.NoError(t, os.WriteFile(csvPath,
+		[]byte("id,email,ssn,age\n1,ann@corp.com,123-45-6789,30\n2,bob@corp.com,987-65-4321,40\n"), 0o600))
+	policyPath := filepath.Join(dir, "policy.yml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`salt: ${env:SQ_TEST_MASK_SALT}
+rules:
+  - column: customer.email
+    transform: fake_email
+  - column: "*id"
+    transform: hash
+  - column: ssn
+    transform: redact
+    keep_last: 4
+`), 0o600))
+	t.Setenv("SQ_TEST_MASK_SALT", "s3cret")
+
+	srcs := []source.Source{
+		{Handle: "@mask_csv", Type: drivertype.CSV, Location: csvPath},
+		{Handle: "@mask_sl3", Type: drivertype.SQLite, Location: "sqlite3://" + filepath.Join(dir, "dest.db")},
+	}
+
+	tr := testrun.New(th.Context, t, nil).Add(srcs...)
+	require.NoError(t, tr.Exec("--mask", policyPath, "@mask_csv.data | .id, .email, .ssn, .age",
+		"--insert", "@mask_sl3.customer"))
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--csv", "-H", "@mask_sl3.customer"))
+	masked := tr.BindCSV()
+	require.Len(t, masked, 2)
+	for i, row := range masked {
+		require.NotEqual(t, []string{"1", "2"}[i], row[0])
+		require.Regexp(t, `@example\.com$`, row[1])
+		require.Regexp(t, `^\d{3}-\d{2}-(6789|4321)$`, row[2])
+		require.NotContains(t, []string{"123-45-6789", "987-65-4321"}, row[2])
+		require.Equal(t, []string{"30", "40"}[i], row[3], "unmatched column should be unchanged")
+	}
+
+	// The email rule is table-qualified, so it doesn't match table "people".
+	// But the id column is masked identically, so joins still match.
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--mask", policyPath, "@mask_csv.data | .id, .email",
+		"--insert", "@mask_sl3.people"))
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--csv", "-H", "@mask_sl3.people"))
+	people := tr.BindCSV()
+	require.Equal(t, masked[0][0], people[0][0])
+	require.Equal(t, "ann@corp.com", people[0][1])
+
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("tbl", "copy", "--mask", policyPath, "@mask_sl3.people", "@mask_sl3.customer2"))
+	tr = testrun.New(th.Context, t, tr)
+	require.NoError(t, tr.Exec("--csv", "-H", "@mask_sl3.customer2"))
+	copied := tr.BindCSV()
+	require.Len(t, copied, 2)
+	require.NotEqual(t, people[0][0], copied[0][0], "id should be hashed again")
+	require.Equal(t, "ann@corp.com", copied[0][1], "table-qualified rule matches neither people nor customer2")
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/samber/lo"
@@ -14,6 +15,10 @@ import (
 	"github.com/neilotoole/sq/cli/pprofile"
 	"github.com/neilotoole/sq/cli/run"
 	"github.com/neilotoole/sq/drivers/csv"
+	"github.com/neilotoole/sq/drivers/json"
+	"github.com/neilotoole/sq/drivers/odbc"
+	"github.com/neilotoole/sq/drivers/xlsx"
+	"github.com/neilotoole/sq/libsq"
 	"github.com/neilotoole/sq/libsq/core/debugz"
 	"github.com/neilotoole/sq/libsq/core/errz"
 	"github.com/neilotoole/sq/libsq/core/ioz/scannerz"
@@ -23,6 +28,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/tuning"
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
 )
@@ -118,6 +124,30 @@ func getSrcOptionsFromFlags(flags *pflag.FlagSet, reg *options.Registry,
 	return getOptionsFromFlags(flags, srcReg)
 }
 
+// absolutizeIngestFiles rewrites the value of each option of o that's
+// tagged options.TagIngestFile, and that is a relative file path (e.g.
+// "./customers.schema.yml"), to an absolute path. The file is read at
+// ingest time, and thus must be found no matter which dir sq is later
+// invoked from. A "~/" path passes through unchanged.
+func absolutizeIngestFiles(reg *options.Registry, o options.Options) error {
+	for _, opt := range reg.Opts() {
+		if !opt.HasTag(options.TagIngestFile) {
+			continue
+		}
+		val, ok := o[opt.Key()].(string)
+		if !ok || !driver.IsIngestFile(val) || filepath.IsAbs(val) || strings.HasPrefix(val, "~") {
+			continue
+		}
+
+		fp, err := filepath.Abs(val)
+		if err != nil {
+			return errz.Wrapf(err, "%s: absolute path", opt.Key())
+		}
+		o[opt.Key()] = fp
+	}
+	return nil
+}
+
 // getOptionsFromCmd returns the options.Options generated by merging
 // config options and flag options.
 //
@@ -197,6 +227,13 @@ func RegisterDefaultOpts(reg *options.Registry) {
 		xlsxw.OptDatetimeFormat,
 		xlsxw.OptDateFormat,
 		xlsxw.OptTimeFormat,
+		xlsxw.OptSheets,
+		xlsxw.OptSplitBy,
+		xlsxw.OptMode,
+		xlsxw.OptHeaderStyle,
+		xlsxw.OptAutoFilter,
+		xlsxw.OptFreezeHeader,
+		xlsxw.OptColWidth,
 		driver.OptResultColRename,
 		OptVerbose,
 		OptPrintHeader,
@@ -216,6 +253,9 @@ func RegisterDefaultOpts(reg *options.Registry) {
 		OptLogFile,
 		OptLogLevel,
 		OptLogFormat,
+		OptInspectProfileTop,
+		OptInspectProfileHistogram,
+		OptInspectProfileApprox,
 		OptDiffNumLines,
 		OptDiffStopAfter,
 		OptDiffDataFormat,
@@ -223,6 +263,20 @@ func RegisterDefaultOpts(reg *options.Registry) {
 		files.OptHTTPRequestTimeout,
 		files.OptHTTPResponseTimeout,
 		files.OptHTTPSInsecureSkipVerify,
+		files.OptHTTPHeaders,
+		files.OptHTTPAuthBearer,
+		files.OptHTTPAuthBasic,
+		files.OptHTTPSClientCert,
+		files.OptHTTPSClientKey,
+		files.OptS3Endpoint,
+		files.OptS3Region,
+		files.OptS3Profile,
+		files.OptS3AccessKeyID,
+		files.OptS3SecretAccessKey,
+		files.OptS3SessionToken,
+		files.OptGCSToken,
+		files.OptAzureAccount,
+		files.OptAzureSASToken,
 		files.OptDownloadCache,
 		files.OptDownloadContinueOnError,
 		driver.OptConnMaxOpen,
@@ -230,6 +284,11 @@ func RegisterDefaultOpts(reg *options.Registry) {
 		driver.OptConnMaxIdleTime,
 		driver.OptConnMaxLifetime,
 		driver.OptConnOpenTimeout,
+		driver.OptSSHTunnel,
+		driver.OptSSHKey,
+		driver.OptSSHKeyPassphrase,
+		driver.OptSSHKnownHosts,
+		driver.OptSSHInsecureSkipVerify,
 		tuning.OptMaxRetryInterval,
 		tuning.OptErrgroupLimit,
 		tuning.OptRecBufSize,
@@ -240,10 +299,42 @@ func RegisterDefaultOpts(reg *options.Registry) {
 		driver.OptIngestHeader,
 		driver.OptIngestCache,
 		files.OptCacheLockTimeout,
+		files.OptResultCacheTTL,
+		libsq.OptStreamWindow,
+		libsq.OptStreamFollow,
 		driver.OptIngestColRename,
 		driver.OptIngestSampleSize,
+		driver.OptIngestSchema,
+		driver.OptIngestFileColumn,
 		csv.OptDelim,
 		csv.OptEmptyAsNull,
+		csv.OptEncoding,
+		csv.OptNullTokens,
+		csv.OptSkipLines,
+		csv.OptComment,
+		csv.OptQuote,
+		csv.OptEscape,
+		csv.OptDatetimeFormat,
+		csv.OptDateFormat,
+		csv.OptTimeFormat,
+		csv.OptFixedWidthLayout,
+		xlsx.OptSheets,
+		xlsx.OptRanges,
+		xlsx.OptHeaderRow,
+		json.OptRESTRecords,
+		json.OptRESTPaginate,
+		json.OptRESTCursorPath,
+		json.OptRESTCursorParam,
+		json.OptRESTPageParam,
+		json.OptRESTPageStart,
+		json.OptRESTOffsetParam,
+		json.OptRESTLimit,
+		json.OptRESTLimitParam,
+		json.OptRESTMaxPages,
+		json.OptRESTRetryTimeout,
+		odbc.OptQuote,
+		odbc.OptLimit,
+		mask.OptPolicy,
 		OptDebugTrackMemory,
 		pprofile.OptMode,
 		debugz.OptProgressDebugSleep,
@@ -374,3 +465,36 @@ func addTimeFormatOptsFlags(cmd *cobra.Command) {
 		"h:mm:ss AM/PM",
 	)))
 }
+
+// addExcelOptsFlags adds the flags for the format.excel.* options that
+// control the layout and styling of XLSX output.
+func addExcelOptsFlags(cmd *cobra.Command) {
+	addOptionFlag(cmd.Flags(), xlsxw.OptSheets)
+	addOptionFlag(cmd.Flags(), xlsxw.OptSplitBy)
+
+	key := addOptionFlag(cmd.Flags(), xlsxw.OptMode)
+	panicOn(cmd.RegisterFlagCompletionFunc(key, completeStrings(
+		xlsxw.ModeNew,
+		xlsxw.ModeAppend,
+		xlsxw.ModeReplace,
+	)))
+
+	key = addOptionFlag(cmd.Flags(), xlsxw.OptHeaderStyle)
+	panicOn(cmd.RegisterFlagCompletionFunc(key, completeStrings(
+		xlsxw.HeaderStyleBold,
+		xlsxw.HeaderStyleShaded,
+		xlsxw.HeaderStyleNone,
+	)))
+
+	key = addOptionFlag(cmd.Flags(), xlsxw.OptAutoFilter)
+	panicOn(cmd.RegisterFlagCompletionFunc(key, completeBool))
+	key = addOptionFlag(cmd.Flags(), xlsxw.OptFreezeHeader)
+	panicOn(cmd.RegisterFlagCompletionFunc(key, completeBool))
+
+	key = addOptionFlag(cmd.Flags(), xlsxw.OptColWidth)
+	panicOn(cmd.RegisterFlagCompletionFunc(key, completeStrings(
+		xlsxw.ColWidthKind,
+		xlsxw.ColWidthAuto,
+		xlsxw.ColWidthNone,
+	)))
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/neilotoole/sq/cli/output"
 	"github.com/neilotoole/sq/cli/output/csvw"
 	"github.com/neilotoole/sq/cli/output/erdimgw"
+	"github.com/neilotoole/sq/cli/output/erdsrcw"
 	"github.com/neilotoole/sq/cli/output/format"
 	"github.com/neilotoole/sq/cli/output/htmlw"
 	"github.com/neilotoole/sq/cli/output/jsonw"
@@ -365,7 +366,10 @@ func newWriters(ru *run.Run, o options.Options) (w *output.Writers, outCfg *outp
 		Version: tablew.NewVersionWriter(outCfg.out, outCfg.outPr),
 		Config:  tablew.NewConfigWriter(outCfg.out, outCfg.outPr),
 		Keyring: tablew.NewKeyringWriter(outCfg.out, outCfg.outPr),
+		Query:   tablew.NewQueryWriter(outCfg.out, outCfg.outPr),
+		Check:   tablew.NewCheckWriter(outCfg.out, outCfg.outPr),
 		SQL:     sqlw.NewTextWriter(outCfg.out, outCfg.outPr),
+		Plan:    tablew.NewPlanWriter(outCfg.out, outCfg.outPr),
 	}
 
 	if OptErrorFormat.Get(o) == format.JSON {
@@ -384,7 +388,10 @@ func newWriters(ru *run.Run, o options.Options) (w *output.Writers, outCfg *outp
 		w.Ping = jsonw.NewPingWriter(outCfg.out, outCfg.outPr)
 		w.Config = jsonw.NewConfigWriter(outCfg.out, outCfg.outPr)
 		w.Keyring = jsonw.NewKeyringWriter(outCfg.out, outCfg.outPr)
+		w.Query = jsonw.NewQueryWriter(outCfg.out, outCfg.outPr)
+		w.Check = jsonw.NewCheckWriter(outCfg.out, outCfg.outPr)
 		w.SQL = sqlw.NewJSONWriter(outCfg.out, outCfg.outPr)
+		w.Plan = jsonw.NewPlanWriter(outCfg.out, outCfg.outPr)
 
 	case format.JSONL:
 		w.SQL = sqlw.NewJSONLWriter(outCfg.out, outCfg.outPr)
@@ -404,7 +411,10 @@ func newWriters(ru *run.Run, o options.Options) (w *output.Writers, outCfg *outp
 		w.Metadata = yamlw.NewMetadataWriter(outCfg.out, outCfg.outPr)
 		w.Source = yamlw.NewSourceWriter(outCfg.out, outCfg.outPr)
 		w.Version = yamlw.NewVersionWriter(outCfg.out, outCfg.outPr)
+		w.Query = yamlw.NewQueryWriter(outCfg.out, outCfg.outPr)
+		w.Check = yamlw.NewCheckWriter(outCfg.out, outCfg.outPr)
 		w.SQL = sqlw.NewYAMLWriter(outCfg.out, outCfg.outPr)
+		w.Plan = yamlw.NewPlanWriter(outCfg.out, outCfg.outPr)
 
 	case format.Markdown:
 		w.Metadata = markdownw.NewMetadataWriter(outCfg.out, outCfg.outPr)
@@ -420,6 +430,15 @@ func newWriters(ru *run.Run, o options.Options) (w *output.Writers, outCfg *outp
 
 	case format.SVGERD:
 		w.Metadata = erdimgw.NewSVGMetadataWriter(outCfg.out, outCfg.outPr)
+
+	case format.DBML:
+		w.Metadata = erdsrcw.NewDBMLMetadataWriter(outCfg.out, outCfg.outPr)
+
+	case format.PlantUMLERD:
+		w.Metadata = erdsrcw.NewPlantUMLMetadataWriter(outCfg.out, outCfg.outPr)
+
+	case format.D2ERD:
+		w.Metadata = erdsrcw.NewD2MetadataWriter(outCfg.out, outCfg.outPr)
 	default:
 	}
 
@@ -477,10 +496,11 @@ func getRecordWriterFunc(f format.Format) output.NewRecordWriterFunc {
 		return yamlw.NewRecordWriter
 	case format.Raw:
 		return raww.NewRecordWriter
-	case format.MermaidERD, format.PNGERD, format.SVGERD:
-		// mermaid-erd, png-erd, and svg-erd are metadata-only (sq inspect)
-		// ERD formats; they have no record writer, so callers fall back to
-		// text for record output.
+	case format.MermaidERD, format.PNGERD, format.SVGERD,
+		format.DBML, format.PlantUMLERD, format.D2ERD:
+		// mermaid-erd, png-erd, svg-erd, dbml, plantuml-erd and d2-erd are
+		// metadata-only (sq inspect) ERD formats; they have no record writer,
+		// so callers fall back to text for record output.
 		return nil
 	default:
 		return nil
@@ -567,6 +587,18 @@ func getOutputConfig(cmd *cobra.Command, fs *files.Files, clnup *cleanup.Cleanup
 	pr.ExcelDatetimeFormat = xlsxw.OptDatetimeFormat.Get(o)
 	pr.ExcelDateFormat = xlsxw.OptDateFormat.Get(o)
 	pr.ExcelTimeFormat = xlsxw.OptTimeFormat.Get(o)
+	if sheets := xlsxw.OptSheets.Get(o); sheets != "" {
+		// An empty entry means the result set gets the default sheet name.
+		for _, sheet := range strings.Split(sheets, ",") {
+			pr.ExcelSheets = append(pr.ExcelSheets, strings.TrimSpace(sheet))
+		}
+	}
+	pr.ExcelSplitBy = xlsxw.OptSplitBy.Get(o)
+	pr.ExcelMode = xlsxw.OptMode.Get(o)
+	pr.ExcelHeaderStyle = xlsxw.OptHeaderStyle.Get(o)
+	pr.ExcelAutoFilter = xlsxw.OptAutoFilter.Get(o)
+	pr.ExcelFreezeHeader = xlsxw.OptFreezeHeader.Get(o)
+	pr.ExcelColWidth = xlsxw.OptColWidth.Get(o)
 
 	pr.Verbose = OptVerbose.Get(o)
 	pr.FlushThreshold = int(tuning.OptFlushThreshold.Get(o).Bytes()) //nolint:gosec // ignore overflow concern
@@ -3,9 +3,12 @@ package commonw
 
 import (
 	"cmp"
+	"fmt"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/core/sqlz"
@@ -344,3 +347,141 @@ func UCRows(tbl *metadata.Table) []UCRow {
 	}
 	return rows
 }
+
+// ProfileRow is a single column's metadata.ColumnProfile flattened for
+// tabular rendering. Each field is a plain (unwrapped) string, or "" when
+// the statistic doesn't apply to the column's kind.
+type ProfileRow struct {
+	Column string
+
+	// Nulls is the null count and percentage, e.g. "2 (40.0%)".
+	Nulls string
+
+	// Distinct is the distinct count, prefixed with "≈" when approximate.
+	Distinct string
+
+	Min    string
+	Max    string
+	Mean   string
+	StdDev string
+
+	// Length is the text length range, e.g. "3–12".
+	Length string
+
+	// Top lists the most frequent values with their counts, e.g.
+	// "NYC (3), LA (1)".
+	Top string
+
+	// Histogram is the histogram rendered as a sparkline, e.g. "▁▃█▂".
+	Histogram string
+}
+
+// ProfileRows flattens the profiles of tbl's columns into rows for
+// tabular rendering, in column order. Returns nil when no column of tbl
+// has been profiled.
+func ProfileRows(tbl *metadata.Table) []ProfileRow {
+	if tbl == nil {
+		return nil
+	}
+
+	var rows []ProfileRow
+	for _, col := range tbl.Columns {
+		if col == nil || col.Profile == nil {
+			continue
+		}
+
+		prof := col.Profile
+		row := ProfileRow{
+			Column: col.Name,
+			Nulls:  fmt.Sprintf("%d (%.1f%%)", prof.NullCount, prof.NullPct),
+			Min:    profileValue(prof.Min),
+			Max:    profileValue(prof.Max),
+			Mean:   profileStat(prof.Mean),
+			StdDev: profileStat(prof.StdDev),
+		}
+
+		if prof.DistinctCount != nil {
+			row.Distinct = strconv.FormatInt(*prof.DistinctCount, 10)
+			if prof.DistinctApprox {
+				row.Distinct = "≈" + row.Distinct
+			}
+		}
+
+		if prof.MinLength != nil && prof.MaxLength != nil {
+			row.Length = fmt.Sprintf("%d–%d", *prof.MinLength, *prof.MaxLength)
+		}
+
+		top := make([]string, 0, len(prof.Top))
+		for _, vc := range prof.Top {
+			if vc != nil {
+				top = append(top, fmt.Sprintf("%s (%d)", profileValue(vc.Value), vc.Count))
+			}
+		}
+		row.Top = strings.Join(top, ", ")
+		row.Histogram = Sparkline(prof.Histogram)
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// Sparkline renders the counts of bins as a sparkline of block characters,
+// scaled to the largest count, e.g. "▁▃█ ▂", where an empty bin is a space.
+// Returns "" for no bins.
+func Sparkline(bins []*metadata.HistogramBin) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	levels := []rune(blocks)
+
+	var maxCount int64
+	for _, bin := range bins {
+		if bin != nil {
+			maxCount = max(maxCount, bin.Count)
+		}
+	}
+
+	var sb strings.Builder
+	for _, bin := range bins {
+		if bin == nil || bin.Count == 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		sb.WriteRune(levels[bin.Count*int64(len(levels)-1)/maxCount])
+	}
+	return sb.String()
+}
+
+// profileValueMaxLen is the length at which profileValue truncates a value.
+const profileValueMaxLen = 24
+
+// profileValue returns v, a profiled min, max or frequent value, as a
+// string, truncating a long value.
+func profileValue(v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		s = v.Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if r := []rune(s); len(r) > profileValueMaxLen {
+		s = string(r[:profileValueMaxLen-1]) + "…"
+	}
+	return s
+}
+
+// profileStat returns the computed statistic f, such as a mean, rounded to
+// four decimal places, or "" if f is nil.
+func profileStat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	s := strconv.FormatFloat(*f, 'f', 4, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -217,3 +217,49 @@ func TestUCRows(t *testing.T) {
 	require.Equal(t, commonw.UCRow{Name: "", Columns: "x, y"}, rows[0])
 	require.Equal(t, commonw.UCRow{Name: "t_email_key", Columns: "email"}, rows[1])
 }
+
+func TestProfileRows(t *testing.T) {
+	require.Nil(t, commonw.ProfileRows(nil))
+	require.Nil(t, commonw.ProfileRows(&metadata.Table{Name: "t", Columns: []*metadata.Column{{Name: "a"}}}))
+
+	distinct, minLen, maxLen := int64(2), int64(2), int64(3)
+	mean, stddev := 31.25, 6.29152869
+	tbl := &metadata.Table{
+		Name: "t",
+		Columns: []*metadata.Column{
+			{Name: "blob"}, // Not profiled
+			{
+				Name: "age",
+				Profile: &metadata.ColumnProfile{
+					NullCount: 1, NullPct: 20, DistinctCount: &distinct, DistinctApprox: true,
+					Min: int64(25), Max: 40.5, Mean: &mean, StdDev: &stddev,
+					Top: []*metadata.ValueCount{{Value: int64(30), Count: 2}, {Value: int64(25), Count: 1}},
+					Histogram: []*metadata.HistogramBin{
+						{Lower: 25, Upper: 30, Count: 1},
+						{Lower: 30, Upper: 35, Count: 0},
+						{Lower: 35, Upper: 40.5, Count: 7},
+					},
+				},
+			},
+			{
+				Name: "city",
+				Profile: &metadata.ColumnProfile{
+					DistinctCount: &distinct, Min: "LA", Max: "a very long city name indeed!",
+					MinLength: &minLen, MaxLength: &maxLen,
+				},
+			},
+		},
+	}
+
+	rows := commonw.ProfileRows(tbl)
+	require.Equal(t, []commonw.ProfileRow{
+		{
+			Column: "age", Nulls: "1 (20.0%)", Distinct: "≈2", Min: "25", Max: "40.5",
+			Mean: "31.25", StdDev: "6.2915", Top: "30 (2), 25 (1)", Histogram: "▂ █",
+		},
+		{
+			Column: "city", Nulls: "0 (0.0%)", Distinct: "2", Min: "LA",
+			Max: "a very long city name i…", Length: "2–3",
+		},
+	}, rows)
+}
@@ -0,0 +1,145 @@
+// Package erdsrcw implements output.MetadataWriter for the "dbml",
+// "plantuml-erd" and "d2-erd" formats: sq inspect's schema
+// entity-relationship diagram as source text for dbdiagram.io (DBML),
+// PlantUML or D2. The source is generated by cli/output/internal/erdsrc.
+//
+// Like mermaidw, it supports only source and table schema inspection
+// (SourceMetadata and TableMetadata); the other metadata operations have no
+// ERD representation and return an error.
+package erdsrcw
+
+import (
+	"cmp"
+	"io"
+	"slices"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/output/format"
+	"github.com/neilotoole/sq/cli/output/internal/erdsrc"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+var _ output.MetadataWriter = (*metadataWriter)(nil)
+
+// metadataWriter implements output.MetadataWriter for the ERD source
+// formats. The source and table funcs generate the format's diagram source.
+type metadataWriter struct {
+	out    io.Writer
+	source func(tables []*metadata.Table) string
+	table  func(tbl *metadata.Table, cardIndex map[string]*metadata.Table) string
+	format format.Format
+}
+
+// NewDBMLMetadataWriter returns an output.MetadataWriter that outputs the
+// schema ERD as DBML, as used by dbdiagram.io. The *output.Printing arg is
+// accepted for call-site consistency with the other metadata writers but is
+// unused: the output is never colorized, so that it can be pasted or piped
+// directly into the diagramming tool.
+func NewDBMLMetadataWriter(out io.Writer, _ *output.Printing) output.MetadataWriter {
+	return &metadataWriter{
+		out:    out,
+		format: format.DBML,
+		source: erdsrc.SourceDBML,
+		table:  erdsrc.TableDBML,
+	}
+}
+
+// NewPlantUMLMetadataWriter returns an output.MetadataWriter that outputs the
+// schema ERD as a PlantUML entity diagram. See NewDBMLMetadataWriter
+// regarding the unused *output.Printing arg.
+func NewPlantUMLMetadataWriter(out io.Writer, _ *output.Printing) output.MetadataWriter {
+	return &metadataWriter{
+		out:    out,
+		format: format.PlantUMLERD,
+		source: erdsrc.SourcePlantUML,
+		table:  erdsrc.TablePlantUML,
+	}
+}
+
+// NewD2MetadataWriter returns an output.MetadataWriter that outputs the
+// schema ERD as a D2 diagram. See NewDBMLMetadataWriter regarding the unused
+// *output.Printing arg.
+func NewD2MetadataWriter(out io.Writer, _ *output.Printing) output.MetadataWriter {
+	return &metadataWriter{
+		out:    out,
+		format: format.D2ERD,
+		source: erdsrc.SourceD2,
+		table:  erdsrc.TableD2,
+	}
+}
+
+// SourceMetadata implements output.MetadataWriter. It writes the whole-source
+// ERD. Overview mode (showSchema=false) carries no table schema, so there's
+// nothing to diagram and it returns an error.
+func (w *metadataWriter) SourceMetadata(md *metadata.Source, showSchema bool) error {
+	if !showSchema {
+		return w.errUnsupported()
+	}
+
+	// Render with a stable table ordering (tables before views, then by
+	// name), matching the other ERD writers.
+	tables := append([]*metadata.Table(nil), md.Tables...)
+	slices.SortFunc(tables, compareTables)
+
+	return w.writeDiagram(w.source(tables))
+}
+
+// TableMetadata implements output.MetadataWriter, writing a focused
+// single-table ERD.
+func (w *metadataWriter) TableMetadata(md *metadata.Table) error {
+	return w.writeDiagram(w.table(md, nil))
+}
+
+// writeDiagram writes the diagram source to w.out. As with mermaidw, the
+// diagram is the entire output, so an empty src (the erdsrc generators
+// return "" when there's nothing to draw) is an error rather than silent
+// empty output.
+func (w *metadataWriter) writeDiagram(src string) error {
+	if src == "" {
+		return errz.Errorf(
+			"the %s format has nothing to render: no columns or foreign keys found", w.format)
+	}
+	_, err := io.WriteString(w.out, src)
+	return err
+}
+
+// errUnsupported returns the error for the metadata operations that have no
+// ERD representation.
+func (w *metadataWriter) errUnsupported() error {
+	return errz.Errorf("the %s format supports only source and table schema diagrams", w.format)
+}
+
+// DBProperties implements output.MetadataWriter. DB properties have no ERD
+// representation.
+func (w *metadataWriter) DBProperties(map[string]any) error {
+	return w.errUnsupported()
+}
+
+// DriverMetadata implements output.MetadataWriter. The driver list has no ERD
+// representation.
+func (w *metadataWriter) DriverMetadata([]driver.Metadata) error {
+	return w.errUnsupported()
+}
+
+// Catalogs implements output.MetadataWriter. A catalog list has no ERD
+// representation.
+func (w *metadataWriter) Catalogs(string, []string) error {
+	return w.errUnsupported()
+}
+
+// Schemata implements output.MetadataWriter. A schema list has no ERD
+// representation.
+func (w *metadataWriter) Schemata(string, []*metadata.Schema) error {
+	return w.errUnsupported()
+}
+
+// compareTables orders tables before views, then by name, so the emitted
+// diagram is deterministic.
+func compareTables(a, b *metadata.Table) int {
+	if a.TableType == b.TableType {
+		return cmp.Compare(a.Name, b.Name)
+	}
+	return cmp.Compare(a.TableType, b.TableType)
+}
@@ -0,0 +1,117 @@
+package erdsrcw_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/cli/output/erdsrcw"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// newTestSource builds a small deterministic two-table source
+// (actor + film_actor, with film_actor.actor_id → actor.actor_id) and links
+// its foreign keys so FK.Incoming is populated. The tables are deliberately
+// out of order, to check that the writer sorts them.
+func newTestSource() *metadata.Source {
+	actor := &metadata.Table{
+		Name: "actor", TableType: "table", RowCount: 200,
+		Columns: []*metadata.Column{
+			{Name: "actor_id", Position: 1, PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+			{Name: "first_name", Position: 2, ColumnType: "TEXT", Kind: kind.Text},
+		},
+	}
+	filmActor := &metadata.Table{
+		Name: "film_actor", TableType: "table", RowCount: 5462,
+		Columns: []*metadata.Column{
+			{Name: "actor_id", Position: 1, PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+			{Name: "film_id", Position: 2, PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+		},
+		FK: &metadata.FKGroup{Outgoing: []*metadata.ForeignKey{{
+			Name: "fk_film_actor_actor", Table: "film_actor", Columns: []string{"actor_id"},
+			RefTable: "actor", RefColumns: []string{"actor_id"},
+		}}},
+	}
+	src := &metadata.Source{
+		Handle: "@test", Name: "testdb", Driver: drivertype.Type("sqlite3"),
+		Schema: "main", Tables: []*metadata.Table{filmActor, actor},
+	}
+	metadata.LinkForeignKeys(nil, src)
+	return src
+}
+
+var testCases = []struct {
+	name      string
+	newFn     func(io.Writer, *output.Printing) output.MetadataWriter
+	wantStart string
+	wantRef   string
+}{
+	{
+		name:      "dbml",
+		newFn:     erdsrcw.NewDBMLMetadataWriter,
+		wantStart: "Table actor {",
+		wantRef:   "Ref fk_film_actor_actor: film_actor.actor_id > actor.actor_id",
+	},
+	{
+		name:      "plantuml-erd",
+		newFn:     erdsrcw.NewPlantUMLMetadataWriter,
+		wantStart: "@startuml",
+		wantRef:   "actor ||--o{ film_actor : fk_film_actor_actor",
+	},
+	{
+		name:      "d2-erd",
+		newFn:     erdsrcw.NewD2MetadataWriter,
+		wantStart: "actor: {",
+		wantRef:   `film_actor.actor_id -> actor.actor_id: "fk_film_actor_actor"`,
+	},
+}
+
+func TestMetadataWriter(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tc.newFn(buf, output.NewPrinting())
+			require.NoError(t, w.SourceMetadata(newTestSource(), true))
+			out := buf.String()
+			require.True(t, strings.HasPrefix(out, tc.wantStart), out)
+			require.Contains(t, out, tc.wantRef)
+			require.Less(t, strings.Index(out, "actor_id"), strings.Index(out, "film_id"),
+				"tables should be sorted by name")
+
+			buf.Reset()
+			require.NoError(t, w.TableMetadata(newTestSource().Table("film_actor")))
+			require.Contains(t, buf.String(), "film_actor")
+			require.Contains(t, buf.String(), "fk_film_actor_actor")
+		})
+	}
+}
+
+// TestMetadataWriter_unsupported tests that the operations that have no ERD
+// representation return an error naming the format.
+func TestMetadataWriter_unsupported(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tc.newFn(buf, output.NewPrinting())
+
+			err := w.SourceMetadata(newTestSource(), false)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.name)
+			require.Error(t, w.DBProperties(nil))
+			require.Error(t, w.DriverMetadata(nil))
+			require.Error(t, w.Catalogs("", nil))
+			require.Error(t, w.Schemata("", nil))
+
+			err = w.TableMetadata(&metadata.Table{Name: "empty"})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "nothing to render")
+			require.Empty(t, buf.String())
+		})
+	}
+}
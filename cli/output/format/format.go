@@ -13,7 +13,7 @@ func (f *Format) UnmarshalText(text []byte) error {
 	default:
 		return errz.Errorf("unknown output format {%s}", string(text))
 	case JSON, JSONA, JSONL, Text, Raw,
-		HTML, Markdown, MermaidERD, PNGERD, SVGERD, XLSX, XML,
+		HTML, Markdown, MermaidERD, PNGERD, SVGERD, DBML, PlantUMLERD, D2ERD, XLSX, XML,
 		CSV, TSV, YAML:
 	case "table":
 		// Legacy: the "text" format used to be named "table".
@@ -48,19 +48,30 @@ const (
 	// SVGERD renders sq inspect's schema entity-relationship diagram to an SVG
 	// image. Inspect-only with no record writer; see PNGERD.
 	SVGERD Format = "svg-erd"
-	XLSX   Format = "xlsx"
-	XML    Format = "xml"
-	CSV    Format = "csv"
-	TSV    Format = "tsv"
-	Raw    Format = "raw"
-	YAML   Format = "yaml"
+	// DBML emits sq inspect's schema entity-relationship diagram as DBML
+	// source, as used by dbdiagram.io. Inspect-only with no record writer;
+	// see MermaidERD.
+	DBML Format = "dbml"
+	// PlantUMLERD emits sq inspect's schema entity-relationship diagram as
+	// PlantUML source. Inspect-only with no record writer; see MermaidERD.
+	PlantUMLERD Format = "plantuml-erd"
+	// D2ERD emits sq inspect's schema entity-relationship diagram as D2
+	// source. Inspect-only with no record writer; see MermaidERD.
+	D2ERD Format = "d2-erd"
+	XLSX  Format = "xlsx"
+	XML   Format = "xml"
+	CSV   Format = "csv"
+	TSV   Format = "tsv"
+	Raw   Format = "raw"
+	YAML  Format = "yaml"
 )
 
 // All returns a new slice containing all format.Format values.
 //
-// All deliberately omits MermaidERD: it's an inspect-only metadata format with
-// no record writer, so advertising it for query commands (shell completion,
-// format parity) would be misleading. See MermaidERD's doc comment.
+// All deliberately omits MermaidERD and the other ERD formats: they're
+// inspect-only metadata formats with no record writer, so advertising them
+// for query commands (shell completion, format parity) would be misleading.
+// See MermaidERD's doc comment.
 func All() []Format {
 	return []Format{
 		Text,
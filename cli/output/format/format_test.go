@@ -23,3 +23,15 @@ func TestMermaidERD_validButUnenumerated(t *testing.T) {
 	require.False(t, slices.Contains(format.All(), format.MermaidERD),
 		"MermaidERD must stay out of All(): it's inspect-only with no record writer")
 }
+
+// TestERDSourceFormats_validButUnenumerated extends the MermaidERD guarantee
+// to the dbml, plantuml-erd and d2-erd formats, which are likewise
+// inspect-only with no record writer.
+func TestERDSourceFormats_validButUnenumerated(t *testing.T) {
+	for _, want := range []format.Format{format.DBML, format.PlantUMLERD, format.D2ERD} {
+		var f format.Format
+		require.NoError(t, f.UnmarshalText([]byte(want.String())))
+		require.Equal(t, want, f)
+		require.False(t, slices.Contains(format.All(), want))
+	}
+}
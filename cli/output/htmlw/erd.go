@@ -157,6 +157,7 @@ func (w *metadataWriter) writeTableSection(
 	writeMermaidBlock(buf, mermaid.TableDiagram(tbl, cardIndex), tableSlug(tbl.Name)+"-erd", level+1)
 	w.writeViewDefinition(buf, tbl)
 	w.writeColumns(buf, tbl)
+	w.writeProfile(buf, tbl)
 	w.writeForeignKeys(buf, tbl)
 	w.writeUniqueConstraints(buf, tbl)
 	w.writeIndexes(buf, tbl)
@@ -283,6 +284,42 @@ func (w *metadataWriter) writeForeignKeys(buf *bytes.Buffer, tbl *metadata.Table
 	writeTableEl(buf, "Foreign keys", tableSlug(tbl.Name)+"-foreign-keys", headers, cells)
 }
 
+// writeProfile renders a "Profile" table when tbl's columns have been
+// profiled (sq inspect --profile). The Histogram column is included only
+// when there are histograms.
+func (w *metadataWriter) writeProfile(buf *bytes.Buffer, tbl *metadata.Table) {
+	rows := commonw.ProfileRows(tbl)
+	if len(rows) == 0 {
+		return
+	}
+
+	showHistogram := slices.ContainsFunc(rows, func(r commonw.ProfileRow) bool { return r.Histogram != "" })
+	headers := []string{"Column", "Nulls", "Distinct", "Min", "Max", "Mean", "Std dev", "Length", "Top"}
+	if showHistogram {
+		headers = append(headers, "Histogram")
+	}
+
+	cells := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		row := []string{
+			htmlCode(r.Column),
+			html.EscapeString(r.Nulls),
+			html.EscapeString(r.Distinct),
+			htmlCode(r.Min),
+			htmlCode(r.Max),
+			html.EscapeString(r.Mean),
+			html.EscapeString(r.StdDev),
+			html.EscapeString(r.Length),
+			html.EscapeString(r.Top),
+		}
+		if showHistogram {
+			row = append(row, html.EscapeString(r.Histogram))
+		}
+		cells = append(cells, row)
+	}
+	writeTableEl(buf, "Profile", tableSlug(tbl.Name)+"-profile", headers, cells)
+}
+
 func (w *metadataWriter) writeUniqueConstraints(buf *bytes.Buffer, tbl *metadata.Table) {
 	rows := commonw.UCRows(tbl)
 	if len(rows) == 0 {
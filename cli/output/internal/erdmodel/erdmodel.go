@@ -1,14 +1,17 @@
 // Package erdmodel holds the renderer-neutral parts of sq's
 // entity-relationship model shared by the diagram renderers (the Mermaid
-// source generator in package mermaid and the Graphviz DOT generator in
-// package erddot). In particular it owns the foreign-key cardinality
+// source generator in package mermaid, the Graphviz DOT generator in
+// package erddot, and the DBML, PlantUML and D2 generators in package
+// erdsrc). In particular it owns the foreign-key cardinality
 // inference, so every ERD renderer reports the same relationships for a
 // given schema rather than each re-deriving (and potentially disagreeing
 // on) cardinality.
 package erdmodel
 
 import (
+	"cmp"
 	"slices"
+	"strings"
 
 	"github.com/neilotoole/sq/libsq/source/metadata"
 )
@@ -157,3 +160,136 @@ func sameColSet(a, b []string) bool {
 	slices.Sort(bb)
 	return slices.Equal(aa, bb)
 }
+
+// Ref is a resolved foreign-key relationship including its columns, for
+// renderers that draw column-level references (DBML, PlantUML, D2). Edge is
+// deliberately kept column-free (and thus comparable); Ref carries the
+// columns alongside it.
+type Ref struct {
+	// FK is the foreign key that the Ref was resolved from.
+	FK *metadata.ForeignKey
+
+	Edge
+}
+
+// SourceRefs returns a Ref for every in-source outgoing foreign key of
+// tables, sorted and deduplicated.
+func SourceRefs(tables []*metadata.Table) []Ref {
+	byName := Index(tables)
+	var refs []Ref
+	for _, tbl := range tables {
+		if tbl.FK == nil {
+			continue
+		}
+		for _, fk := range tbl.FK.Outgoing {
+			if e, ok := Resolve(fk, byName); ok {
+				refs = append(refs, Ref{Edge: e, FK: fk})
+			}
+		}
+	}
+	return sortDedupRefs(refs)
+}
+
+// TableRefs returns a Ref for every in-source foreign key that tbl
+// participates in, outgoing or incoming, sorted and deduplicated. cardIndex
+// supplies neighbor tables for cardinality inference; it may be nil.
+func TableRefs(tbl *metadata.Table, cardIndex map[string]*metadata.Table) []Ref {
+	if cardIndex == nil {
+		cardIndex = map[string]*metadata.Table{tbl.Name: tbl}
+	}
+	if tbl.FK == nil {
+		return nil
+	}
+
+	var refs []Ref
+	for _, fks := range [][]*metadata.ForeignKey{tbl.FK.Outgoing, tbl.FK.Incoming} {
+		for _, fk := range fks {
+			if e, ok := Resolve(fk, cardIndex); ok {
+				refs = append(refs, Ref{Edge: e, FK: fk})
+			}
+		}
+	}
+	return sortDedupRefs(refs)
+}
+
+// sortDedupRefs sorts refs into a deterministic order and removes
+// duplicates, such as a self-referencing foreign key that's both outgoing
+// and incoming.
+func sortDedupRefs(refs []Ref) []Ref {
+	key := func(r Ref) string {
+		return strings.Join([]string{
+			r.Child, r.Parent, r.Label,
+			strings.Join(r.FK.Columns, ","), strings.Join(r.FK.RefColumns, ","),
+		}, "\x00")
+	}
+	slices.SortFunc(refs, func(a, b Ref) int {
+		return cmp.Compare(key(a), key(b))
+	})
+	return slices.CompactFunc(refs, func(a, b Ref) bool { return key(a) == key(b) })
+}
+
+// ColumnType returns the type of col for display: the column's DB type if
+// known, else its base type, else its kind.
+func ColumnType(col *metadata.Column) string {
+	switch {
+	case col.ColumnType != "":
+		return col.ColumnType
+	case col.BaseType != "":
+		return col.BaseType
+	default:
+		return col.Kind.String()
+	}
+}
+
+// UniqueColumnSet returns the set of column names on tbl that are, by
+// themselves, unique: that is, they're the sole column of a unique
+// constraint.
+func UniqueColumnSet(tbl *metadata.Table) map[string]bool {
+	set := make(map[string]bool)
+	for _, uc := range tbl.UniqueConstraints {
+		if uc != nil && len(uc.Columns) == 1 {
+			set[uc.Columns[0]] = true
+		}
+	}
+	return set
+}
+
+// StubColumns returns placeholder columns for table name, which is
+// referenced by refs but whose own columns aren't available. The stub has
+// name's key columns from refs, typed from the matching columns of the
+// counterpart table, looked up in byName. This is for formats, such as DBML,
+// that require every referenced table to be declared with its columns.
+// Because their nullability isn't known, stub columns are marked nullable,
+// which renderers treat as the unconstrained default.
+func StubColumns(name string, refs []Ref, byName map[string]*metadata.Table) []*metadata.Column {
+	var cols []*metadata.Column
+	seen := map[string]bool{}
+	add := func(colName string, peer *metadata.Table, peerCol string) {
+		if seen[colName] {
+			return
+		}
+		seen[colName] = true
+		col := &metadata.Column{Name: colName, Position: int64(len(cols) + 1), Nullable: true}
+		if peer != nil {
+			if c := peer.Column(peerCol); c != nil {
+				col.ColumnType, col.BaseType, col.Kind = c.ColumnType, c.BaseType, c.Kind
+			}
+		}
+		cols = append(cols, col)
+	}
+
+	for _, r := range refs {
+		n := min(len(r.FK.Columns), len(r.FK.RefColumns))
+		if r.Child == name {
+			for i := range n {
+				add(r.FK.Columns[i], byName[r.Parent], r.FK.RefColumns[i])
+			}
+		}
+		if r.Parent == name {
+			for i := range n {
+				add(r.FK.RefColumns[i], byName[r.Child], r.FK.Columns[i])
+			}
+		}
+	}
+	return cols
+}
@@ -108,3 +108,43 @@ func TestKeyMarker(t *testing.T) {
 	require.Equal(t, "PK,FK", erdmodel.KeyMarker(&metadata.Column{Name: "ref_id", PrimaryKey: true}, fkCols))
 	require.Empty(t, erdmodel.KeyMarker(&metadata.Column{Name: "other"}, fkCols))
 }
+
+// TestTableRefs_selfRef tests that a self-referencing foreign key, which is
+// both outgoing and incoming, yields a single Ref.
+func TestTableRefs_selfRef(t *testing.T) {
+	fk := &metadata.ForeignKey{
+		Name: "fk_emp_manager", Table: "emp", Columns: []string{"manager_id"},
+		RefTable: "emp", RefColumns: []string{"id"},
+	}
+	emp := &metadata.Table{
+		Name: "emp",
+		Columns: []*metadata.Column{
+			{Name: "id", PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+			{Name: "manager_id", Nullable: true, ColumnType: "INTEGER", Kind: kind.Int},
+		},
+		FK: &metadata.FKGroup{
+			Outgoing: []*metadata.ForeignKey{fk},
+			Incoming: []*metadata.ForeignKey{fk},
+		},
+	}
+
+	refs := erdmodel.TableRefs(emp, nil)
+	require.Len(t, refs, 1)
+	require.Equal(t, "emp", refs[0].Parent)
+	require.True(t, refs[0].Card.ParentOptional)
+	require.Equal(t, refs, erdmodel.SourceRefs([]*metadata.Table{emp}))
+}
+
+func TestStubColumns(t *testing.T) {
+	child := childFK(false, false, false)
+	refs := erdmodel.TableRefs(child, nil)
+	require.Len(t, refs, 1)
+
+	cols := erdmodel.StubColumns("parent", refs, erdmodel.Index([]*metadata.Table{child}))
+	require.Len(t, cols, 1)
+	require.Equal(t, "id", cols[0].Name)
+	require.Equal(t, "INTEGER", cols[0].ColumnType)
+	require.True(t, cols[0].Nullable)
+
+	require.Empty(t, erdmodel.StubColumns("other", refs, nil))
+}
@@ -0,0 +1,168 @@
+package erdsrc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/output/internal/erdmodel"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// SourceD2 returns the whole-source D2 diagram: a sql_table shape for every
+// table that has columns, plus a connection for every in-source outgoing
+// foreign key. Returns "" when there is nothing to draw.
+func SourceD2(tables []*metadata.Table) string {
+	return renderD2(sourceDiagram(tables))
+}
+
+// TableD2 returns a focused D2 diagram for tbl: tbl's sql_table shape plus a
+// connection for every foreign key it participates in. Related tables are
+// rendered as column-less sql_table shapes. cardIndex, when non-nil,
+// supplies neighbor tables for cardinality inference; pass nil for a
+// single-table inspect. Returns "" when there is nothing to draw.
+func TableD2(tbl *metadata.Table, cardIndex map[string]*metadata.Table) string {
+	return renderD2(tableDiagram(tbl, cardIndex))
+}
+
+func renderD2(d *diagram) string {
+	if d == nil {
+		return ""
+	}
+
+	buf := &strings.Builder{}
+	for _, t := range d.tables {
+		writeD2Table(buf, t)
+	}
+	for _, name := range d.neighbors {
+		fmt.Fprintf(buf, "%s: {shape: sql_table}\n\n", d2Key(name))
+	}
+
+	for _, r := range d.refs {
+		// A single-column foreign key connects the columns themselves, where
+		// both tables' columns are drawn. Otherwise, the connection is
+		// between the tables.
+		child, parent := d2Key(r.Child), d2Key(r.Parent)
+		if len(r.FK.Columns) == 1 && len(r.FK.RefColumns) == 1 {
+			if d.rendered[r.Child] {
+				child += "." + d2Key(r.FK.Columns[0])
+			}
+			if d.rendered[r.Parent] {
+				parent += "." + d2Key(r.FK.RefColumns[0])
+			}
+		}
+
+		childShape, parentShape := "cf-many", "cf-one-required"
+		if r.Card.ChildUnique {
+			childShape = "cf-one"
+		}
+		if r.Card.ParentOptional {
+			parentShape = "cf-one"
+		}
+
+		fmt.Fprintf(buf, "%s -> %s", child, parent)
+		if r.Label != "" {
+			fmt.Fprintf(buf, ": %s", d2String(r.Label))
+		}
+		fmt.Fprintf(buf, " {\n  source-arrowhead.shape: %s\n  target-arrowhead.shape: %s\n}\n",
+			childShape, parentShape)
+	}
+
+	// Each shape is followed by a blank line; drop the last one when there
+	// are no connections.
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
+
+// writeD2Table writes a sql_table shape for t. D2 draws a column's
+// constraint abbreviation (PK, FK, UNQ) but has no place for a column
+// comment, a multi-column unique constraint or a check constraint, so those
+// are carried in the shape's tooltip, along with the table comment.
+func writeD2Table(buf *strings.Builder, t *metadata.Table) {
+	fmt.Fprintf(buf, "%s: {\n  shape: sql_table\n", d2Key(t.Name))
+
+	var tooltip []string
+	if t.Comment != "" {
+		tooltip = append(tooltip, t.Comment)
+	}
+
+	fkCols := erdmodel.FKColumnSet(t)
+	uniqueCols := erdmodel.UniqueColumnSet(t)
+	for _, col := range t.Columns {
+		var constraints []string
+		if col.PrimaryKey {
+			constraints = append(constraints, "primary_key")
+		}
+		if fkCols[col.Name] {
+			constraints = append(constraints, "foreign_key")
+		}
+		if uniqueCols[col.Name] {
+			constraints = append(constraints, "unique")
+		}
+
+		fmt.Fprintf(buf, "  %s: %s", d2Key(col.Name), d2String(erdmodel.ColumnType(col)))
+		switch len(constraints) {
+		case 0:
+		case 1:
+			fmt.Fprintf(buf, " {constraint: %s}", constraints[0])
+		default:
+			fmt.Fprintf(buf, " {constraint: [%s]}", strings.Join(constraints, "; "))
+		}
+		buf.WriteString("\n")
+
+		if col.Comment != "" {
+			tooltip = append(tooltip, col.Name+": "+col.Comment)
+		}
+	}
+
+	for _, uc := range multiColumnUniques(t) {
+		s := "UNIQUE (" + strings.Join(uc.Columns, ", ") + ")"
+		if uc.Name != "" {
+			s = uc.Name + ": " + s
+		}
+		tooltip = append(tooltip, s)
+	}
+	for _, cc := range t.CheckConstraints {
+		if cc == nil {
+			continue
+		}
+		s := "CHECK (" + cc.Clause + ")"
+		if cc.Name != "" {
+			s = cc.Name + ": " + s
+		}
+		tooltip = append(tooltip, s)
+	}
+
+	if len(tooltip) > 0 {
+		fmt.Fprintf(buf, "  tooltip: %s\n", d2String(strings.Join(tooltip, "\n")))
+	}
+	buf.WriteString("}\n\n")
+}
+
+// d2KeyRe matches keys safe to emit unquoted in D2.
+var d2KeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// d2Reserved holds D2 keywords that are quoted when used as a key, so that
+// e.g. a column named "label" isn't read as the shape's label.
+var d2Reserved = map[string]bool{
+	"class": true, "constraint": true, "direction": true, "height": true, "icon": true,
+	"label": true, "link": true, "near": true, "shape": true, "style": true,
+	"tooltip": true, "vars": true, "width": true,
+}
+
+// d2Key renders a shape or column key, quoting it when it isn't a bare
+// identifier or is a D2 keyword. In particular, a period in a key must be
+// quoted, because D2 treats it as a path separator.
+func d2Key(s string) string {
+	if d2KeyRe.MatchString(s) && !d2Reserved[strings.ToLower(s)] {
+		return s
+	}
+	return d2String(s)
+}
+
+// d2QuoteSafe escapes a value for a double-quoted D2 string.
+var d2QuoteSafe = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// d2String renders s as a double-quoted D2 string.
+func d2String(s string) string {
+	return `"` + d2QuoteSafe.Replace(s) + `"`
+}
@@ -0,0 +1,192 @@
+package erdsrc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/output/internal/erdmodel"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// SourceDBML returns the whole-source DBML: a Table block for every table
+// that has columns, plus a Ref for every in-source outgoing foreign key.
+// Returns "" when there is nothing to draw.
+func SourceDBML(tables []*metadata.Table) string {
+	return renderDBML(sourceDiagram(tables))
+}
+
+// TableDBML returns focused DBML for tbl: tbl's Table block plus a Ref for
+// every foreign key it participates in. DBML requires each referenced table
+// to be declared, so related tables are rendered as stub Table blocks
+// holding just their key columns. cardIndex, when non-nil, supplies
+// neighbor tables for cardinality inference; pass nil for a single-table
+// inspect. Returns "" when there is nothing to draw.
+func TableDBML(tbl *metadata.Table, cardIndex map[string]*metadata.Table) string {
+	return renderDBML(tableDiagram(tbl, cardIndex))
+}
+
+func renderDBML(d *diagram) string {
+	if d == nil {
+		return ""
+	}
+
+	buf := &strings.Builder{}
+	for _, t := range d.tables {
+		writeDBMLTable(buf, t)
+	}
+	for _, name := range d.neighbors {
+		writeDBMLTable(buf, &metadata.Table{
+			Name:    name,
+			Columns: erdmodel.StubColumns(name, d.refs, d.byName),
+		})
+	}
+
+	for _, r := range d.refs {
+		op := ">"
+		if r.Card.ChildUnique {
+			op = "-"
+		}
+		buf.WriteString("Ref")
+		if r.Label != "" {
+			buf.WriteString(" " + dbmlIdent(r.Label))
+		}
+		fmt.Fprintf(buf, ": %s.%s %s %s.%s",
+			dbmlIdent(r.Child), dbmlColumns(r.FK.Columns), op,
+			dbmlIdent(r.Parent), dbmlColumns(r.FK.RefColumns))
+		var settings []string
+		if r.FK.OnDelete != "" {
+			settings = append(settings, "delete: "+strings.ToLower(r.FK.OnDelete))
+		}
+		if r.FK.OnUpdate != "" {
+			settings = append(settings, "update: "+strings.ToLower(r.FK.OnUpdate))
+		}
+		if len(settings) > 0 {
+			fmt.Fprintf(buf, " [%s]", strings.Join(settings, ", "))
+		}
+		buf.WriteString("\n")
+	}
+
+	// Each Table block is followed by a blank line; drop the last one when
+	// there are no refs.
+	return strings.TrimRight(buf.String(), "\n") + "\n"
+}
+
+// writeDBMLTable writes a Table block for t, including its indexes (for
+// multi-column unique constraints), checks, and note.
+func writeDBMLTable(buf *strings.Builder, t *metadata.Table) {
+	uniqueCols := erdmodel.UniqueColumnSet(t)
+	fmt.Fprintf(buf, "Table %s {\n", dbmlIdent(t.Name))
+	for _, col := range t.Columns {
+		var settings []string
+		if col.PrimaryKey {
+			settings = append(settings, "pk")
+		}
+		if col.AutoIncrement || col.Identity {
+			settings = append(settings, "increment")
+		}
+		if !col.Nullable && !col.PrimaryKey {
+			settings = append(settings, "not null")
+		}
+		if uniqueCols[col.Name] {
+			settings = append(settings, "unique")
+		}
+		if col.DefaultValue != "" {
+			settings = append(settings, "default: `"+dbmlExpr(col.DefaultValue)+"`")
+		}
+		if col.Comment != "" {
+			settings = append(settings, "note: "+dbmlString(col.Comment))
+		}
+
+		fmt.Fprintf(buf, "  %s %s", dbmlIdent(col.Name), dbmlType(erdmodel.ColumnType(col)))
+		if len(settings) > 0 {
+			fmt.Fprintf(buf, " [%s]", strings.Join(settings, ", "))
+		}
+		buf.WriteString("\n")
+	}
+
+	if ucs := multiColumnUniques(t); len(ucs) > 0 {
+		buf.WriteString("\n  indexes {\n")
+		for _, uc := range ucs {
+			fmt.Fprintf(buf, "    %s [unique", dbmlColumns(uc.Columns))
+			if uc.Name != "" {
+				fmt.Fprintf(buf, ", name: %s", dbmlString(uc.Name))
+			}
+			buf.WriteString("]\n")
+		}
+		buf.WriteString("  }\n")
+	}
+
+	if len(t.CheckConstraints) > 0 {
+		buf.WriteString("\n  checks {\n")
+		for _, cc := range t.CheckConstraints {
+			if cc == nil {
+				continue
+			}
+			fmt.Fprintf(buf, "    `%s`", dbmlExpr(cc.Clause))
+			if cc.Name != "" {
+				fmt.Fprintf(buf, " [name: %s]", dbmlString(cc.Name))
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("  }\n")
+	}
+
+	if t.Comment != "" {
+		fmt.Fprintf(buf, "\n  Note: %s\n", dbmlString(t.Comment))
+	}
+	buf.WriteString("}\n\n")
+}
+
+// dbmlIdentRe matches identifiers safe to emit unquoted in DBML.
+var dbmlIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dbmlTypeRe matches column types safe to emit unquoted in DBML, such as
+// "int", "varchar(255)", "numeric(10, 2)" or "text[]".
+var dbmlTypeRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\([0-9, ]*\))?(\[\])?$`)
+
+// dbmlQuoteSafe escapes a value for a double-quoted DBML identifier.
+var dbmlQuoteSafe = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dbmlIdent renders a table, column or ref name, quoting it when it isn't
+// a bare identifier.
+func dbmlIdent(s string) string {
+	if dbmlIdentRe.MatchString(s) {
+		return s
+	}
+	return `"` + dbmlQuoteSafe.Replace(oneLine(s)) + `"`
+}
+
+// dbmlType renders a column type, quoting it when it contains characters
+// DBML wouldn't accept bare, e.g. "double precision".
+func dbmlType(s string) string {
+	if dbmlTypeRe.MatchString(s) {
+		return s
+	}
+	return `"` + dbmlQuoteSafe.Replace(oneLine(s)) + `"`
+}
+
+// dbmlColumns renders a column reference list: a bare column name for a
+// single column, else a parenthesized list.
+func dbmlColumns(cols []string) string {
+	if len(cols) == 1 {
+		return dbmlIdent(cols[0])
+	}
+	idents := make([]string, len(cols))
+	for i, c := range cols {
+		idents[i] = dbmlIdent(c)
+	}
+	return "(" + strings.Join(idents, ", ") + ")"
+}
+
+// dbmlString renders s as a single-quoted DBML string.
+func dbmlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\r", "", "\n", `\n`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// dbmlExpr sanitizes s for use within a backtick-quoted DBML expression,
+// which has no escape for the backtick itself.
+func dbmlExpr(s string) string {
+	return strings.ReplaceAll(oneLine(s), "`", "'")
+}
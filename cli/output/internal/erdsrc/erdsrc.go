@@ -0,0 +1,104 @@
+// Package erdsrc generates text-based ERD source from sq table metadata for
+// external diagramming tools: DBML (dbdiagram.io), PlantUML entity diagrams,
+// and D2 sql_table diagrams. Unlike the mermaid and erddot generators, these
+// formats are column-aware: each foreign key is rendered as a column-level
+// reference, and unique constraints, check constraints and comments are
+// carried through.
+//
+// Each generator has a Source variant (a whole-source diagram) and a Table
+// variant (a focused single-table diagram), mirroring mermaid.SourceDiagram
+// and mermaid.TableDiagram. They return the bare source, or "" when there's
+// nothing to draw.
+package erdsrc
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/output/internal/erdmodel"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// diagram is the renderer-neutral content of a single diagram.
+type diagram struct {
+	// byName indexes every table whose metadata is known, for stub column
+	// typing and cardinality inference.
+	byName map[string]*metadata.Table
+
+	// rendered is the set of names of the tables in tables.
+	rendered map[string]bool
+
+	// tables are the tables rendered with their columns, in the caller's
+	// order.
+	tables []*metadata.Table
+
+	// neighbors are the names of tables referenced by refs that aren't
+	// rendered with columns, sorted.
+	neighbors []string
+
+	// refs are the diagram's foreign-key relationships.
+	refs []erdmodel.Ref
+}
+
+// sourceDiagram returns the diagram for the whole source: every table that
+// has columns, plus every in-source outgoing foreign key. It returns nil
+// when there's nothing to draw.
+func sourceDiagram(tables []*metadata.Table) *diagram {
+	return newDiagram(tables, erdmodel.Index(tables), erdmodel.SourceRefs(tables))
+}
+
+// tableDiagram returns the focused diagram for tbl: tbl itself, plus every
+// foreign key it participates in. Related tables are neighbors. cardIndex,
+// when non-nil, supplies neighbor tables for cardinality inference. It
+// returns nil when there's nothing to draw.
+func tableDiagram(tbl *metadata.Table, cardIndex map[string]*metadata.Table) *diagram {
+	byName := map[string]*metadata.Table{}
+	for name, t := range cardIndex {
+		byName[name] = t
+	}
+	byName[tbl.Name] = tbl
+	return newDiagram([]*metadata.Table{tbl}, byName, erdmodel.TableRefs(tbl, byName))
+}
+
+func newDiagram(tables []*metadata.Table, byName map[string]*metadata.Table, refs []erdmodel.Ref) *diagram {
+	d := &diagram{byName: byName, rendered: map[string]bool{}, refs: refs}
+	for _, t := range tables {
+		if len(t.Columns) > 0 {
+			d.tables = append(d.tables, t)
+			d.rendered[t.Name] = true
+		}
+	}
+
+	for _, r := range refs {
+		for _, name := range []string{r.Parent, r.Child} {
+			if !d.rendered[name] && !slices.Contains(d.neighbors, name) {
+				d.neighbors = append(d.neighbors, name)
+			}
+		}
+	}
+	slices.Sort(d.neighbors)
+
+	if len(d.tables) == 0 && len(d.refs) == 0 {
+		return nil
+	}
+	return d
+}
+
+// multiColumnUniques returns the unique constraints of tbl that span more
+// than one column. Single-column unique constraints are rendered as a
+// column attribute instead; see erdmodel.UniqueColumnSet.
+func multiColumnUniques(tbl *metadata.Table) []*metadata.UniqueConstraint {
+	var ucs []*metadata.UniqueConstraint
+	for _, uc := range tbl.UniqueConstraints {
+		if uc != nil && len(uc.Columns) > 1 {
+			ucs = append(ucs, uc)
+		}
+	}
+	return ucs
+}
+
+// oneLine collapses whitespace runs, including newlines, in s to a single
+// space, for formats whose strings or labels can't span lines.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
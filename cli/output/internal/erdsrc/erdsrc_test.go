@@ -0,0 +1,232 @@
+package erdsrc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/output/internal/erdmodel"
+	"github.com/neilotoole/sq/cli/output/internal/erdsrc"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// testTables returns actor and film_actor, with film_actor.actor_id →
+// actor.actor_id, plus comments, unique and check constraints on actor.
+func testTables() []*metadata.Table {
+	actor := &metadata.Table{
+		Name: "actor", TableType: "table", Comment: "Film actors",
+		Columns: []*metadata.Column{
+			{
+				Name: "actor_id", Position: 1, PrimaryKey: true, AutoIncrement: true,
+				ColumnType: "INTEGER", Kind: kind.Int,
+			},
+			{Name: "first_name", Position: 2, ColumnType: "VARCHAR(45)", Kind: kind.Text},
+			{
+				Name: "last_name", Position: 3, ColumnType: "VARCHAR(45)", Kind: kind.Text,
+				Nullable: true, Comment: "Family name",
+			},
+			{
+				Name: "email", Position: 4, ColumnType: "TEXT", Kind: kind.Text,
+				Nullable: true, DefaultValue: "''",
+			},
+		},
+		UniqueConstraints: []*metadata.UniqueConstraint{
+			{Name: "uq_email", Table: "actor", Columns: []string{"email"}},
+			{Name: "uq_name", Table: "actor", Columns: []string{"first_name", "last_name"}},
+		},
+		CheckConstraints: []*metadata.CheckConstraint{
+			{Name: "ck_first_name", Table: "actor", Clause: "length(first_name) > 0"},
+		},
+	}
+	filmActor := &metadata.Table{
+		Name: "film_actor", TableType: "table",
+		Columns: []*metadata.Column{
+			{Name: "actor_id", Position: 1, PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+			{Name: "film_id", Position: 2, PrimaryKey: true, ColumnType: "INTEGER", Kind: kind.Int},
+		},
+		FK: &metadata.FKGroup{Outgoing: []*metadata.ForeignKey{{
+			Name: "fk_film_actor_actor", Table: "film_actor", Columns: []string{"actor_id"},
+			RefTable: "actor", RefColumns: []string{"actor_id"}, OnDelete: "CASCADE",
+		}}},
+	}
+	src := &metadata.Source{Handle: "@test", Tables: []*metadata.Table{actor, filmActor}}
+	metadata.LinkForeignKeys(nil, src)
+	return src.Tables
+}
+
+func TestSourceDBML(t *testing.T) {
+	got := erdsrc.SourceDBML(testTables())
+	require.Equal(t, `Table actor {
+  actor_id INTEGER [pk, increment]
+  first_name VARCHAR(45) [not null]
+  last_name VARCHAR(45) [note: 'Family name']
+  email TEXT [unique, default: `+"`''`"+`]
+
+  indexes {
+    (first_name, last_name) [unique, name: 'uq_name']
+  }
+
+  checks {
+    `+"`length(first_name) > 0`"+` [name: 'ck_first_name']
+  }
+
+  Note: 'Film actors'
+}
+
+Table film_actor {
+  actor_id INTEGER [pk]
+  film_id INTEGER [pk]
+}
+
+Ref fk_film_actor_actor: film_actor.actor_id > actor.actor_id [delete: cascade]
+`, got)
+}
+
+// TestTableDBML_stub tests that a focused diagram declares the neighbor
+// table as a stub, because DBML requires referenced tables to be declared.
+func TestTableDBML_stub(t *testing.T) {
+	tables := testTables()
+	got := erdsrc.TableDBML(tables[1], nil) // film_actor
+	require.Equal(t, `Table film_actor {
+  actor_id INTEGER [pk]
+  film_id INTEGER [pk]
+}
+
+Table actor {
+  actor_id INTEGER
+}
+
+Ref fk_film_actor_actor: film_actor.actor_id > actor.actor_id [delete: cascade]
+`, got)
+}
+
+func TestDBML_quoting(t *testing.T) {
+	tbl := &metadata.Table{
+		Name: "order item",
+		Columns: []*metadata.Column{
+			{Name: "unit price", ColumnType: "double precision", Nullable: true, Comment: "it's\nnet"},
+		},
+	}
+	got := erdsrc.SourceDBML([]*metadata.Table{tbl})
+	require.Equal(t, `Table "order item" {
+  "unit price" "double precision" [note: 'it\'s\nnet']
+}
+`, got)
+}
+
+func TestSourcePlantUML(t *testing.T) {
+	got := erdsrc.SourcePlantUML(testTables())
+	require.Equal(t, `@startuml
+hide circle
+hide empty members
+skinparam linetype ortho
+
+entity "actor" as actor {
+  * actor_id : INTEGER <<PK>>
+  --
+  * first_name : VARCHAR(45)
+  last_name : VARCHAR(45) // Family name
+  email : TEXT <<unique>> = ''
+  ..
+  uq_name: UNIQUE (first_name, last_name)
+  ck_first_name: CHECK (length(first_name) > 0)
+}
+note top of actor
+  Film actors
+end note
+
+entity "film_actor" as film_actor {
+  * actor_id : INTEGER <<PK>> <<FK>>
+  * film_id : INTEGER <<PK>>
+}
+
+actor ||--o{ film_actor : fk_film_actor_actor
+@enduml
+`, got)
+}
+
+func TestTablePlantUML_focused(t *testing.T) {
+	tables := testTables()
+	got := erdsrc.TablePlantUML(tables[1], erdmodel.Index(tables)) // film_actor
+	require.Equal(t, `@startuml
+hide circle
+hide empty members
+skinparam linetype ortho
+
+entity "film_actor" as film_actor {
+  * actor_id : INTEGER <<PK>> <<FK>>
+  * film_id : INTEGER <<PK>>
+}
+
+entity "actor" as actor
+
+actor ||--o{ film_actor : fk_film_actor_actor
+@enduml
+`, got)
+}
+
+func TestSourceD2(t *testing.T) {
+	got := erdsrc.SourceD2(testTables())
+	require.Equal(t, `actor: {
+  shape: sql_table
+  actor_id: "INTEGER" {constraint: primary_key}
+  first_name: "VARCHAR(45)"
+  last_name: "VARCHAR(45)"
+  email: "TEXT" {constraint: unique}
+  tooltip: "Film actors\nlast_name: Family name\nuq_name: UNIQUE (first_name, last_name)\nck_first_name: CHECK (length(first_name) > 0)"
+}
+
+film_actor: {
+  shape: sql_table
+  actor_id: "INTEGER" {constraint: [primary_key; foreign_key]}
+  film_id: "INTEGER" {constraint: primary_key}
+}
+
+film_actor.actor_id -> actor.actor_id: "fk_film_actor_actor" {
+  source-arrowhead.shape: cf-many
+  target-arrowhead.shape: cf-one-required
+}
+`, got)
+}
+
+// TestTableD2_nilCardIndex tests the single-table inspect path, where the
+// neighbor is a column-less shape, so the connection targets the table.
+func TestTableD2_nilCardIndex(t *testing.T) {
+	tables := testTables()
+	got := erdsrc.TableD2(tables[1], nil) // film_actor
+	require.Equal(t, `film_actor: {
+  shape: sql_table
+  actor_id: "INTEGER" {constraint: [primary_key; foreign_key]}
+  film_id: "INTEGER" {constraint: primary_key}
+}
+
+actor: {shape: sql_table}
+
+film_actor.actor_id -> actor: "fk_film_actor_actor" {
+  source-arrowhead.shape: cf-many
+  target-arrowhead.shape: cf-one-required
+}
+`, got)
+}
+
+func TestD2_reservedKey(t *testing.T) {
+	tbl := &metadata.Table{
+		Name:    "sales.q1",
+		Columns: []*metadata.Column{{Name: "label", ColumnType: "TEXT", Nullable: true}},
+	}
+	got := erdsrc.SourceD2([]*metadata.Table{tbl})
+	require.Equal(t, `"sales.q1": {
+  shape: sql_table
+  "label": "TEXT"
+}
+`, got)
+}
+
+func TestEmpty(t *testing.T) {
+	tbl := &metadata.Table{Name: "empty"}
+	require.Empty(t, erdsrc.SourceDBML([]*metadata.Table{tbl}))
+	require.Empty(t, erdsrc.SourcePlantUML([]*metadata.Table{tbl}))
+	require.Empty(t, erdsrc.SourceD2([]*metadata.Table{tbl}))
+	require.Empty(t, erdsrc.TableD2(tbl, nil))
+}
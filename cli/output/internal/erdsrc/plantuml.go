@@ -0,0 +1,182 @@
+package erdsrc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/output/internal/erdmodel"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// SourcePlantUML returns the whole-source PlantUML entity diagram: an
+// entity for every table that has columns, plus a relationship for every
+// in-source outgoing foreign key. Returns "" when there is nothing to draw.
+func SourcePlantUML(tables []*metadata.Table) string {
+	return renderPlantUML(sourceDiagram(tables))
+}
+
+// TablePlantUML returns a focused PlantUML entity diagram for tbl: tbl's
+// entity plus a relationship for every foreign key it participates in.
+// Related tables are rendered as bare, column-less entities. cardIndex, when
+// non-nil, supplies neighbor tables for cardinality inference; pass nil for
+// a single-table inspect. Returns "" when there is nothing to draw.
+func TablePlantUML(tbl *metadata.Table, cardIndex map[string]*metadata.Table) string {
+	return renderPlantUML(tableDiagram(tbl, cardIndex))
+}
+
+func renderPlantUML(d *diagram) string {
+	if d == nil {
+		return ""
+	}
+
+	buf := &strings.Builder{}
+	buf.WriteString("@startuml\n")
+	buf.WriteString("hide circle\n")
+	buf.WriteString("hide empty members\n")
+	buf.WriteString("skinparam linetype ortho\n")
+
+	for _, t := range d.tables {
+		buf.WriteString("\n")
+		writePlantUMLEntity(buf, t)
+	}
+	if len(d.neighbors) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, name := range d.neighbors {
+		fmt.Fprintf(buf, "entity %s as %s\n", plantUMLString(name), plantUMLAlias(name))
+	}
+
+	if len(d.refs) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, r := range d.refs {
+		// The parent side is "|o" (zero-or-one) when optional, else "||"
+		// (exactly one); the child side is "||" (one-to-one) when unique,
+		// else "o{" (zero-or-many). This is the same notation as Mermaid.
+		parentCard, childCard := "||", "o{"
+		if r.Card.ParentOptional {
+			parentCard = "|o"
+		}
+		if r.Card.ChildUnique {
+			childCard = "||"
+		}
+
+		fmt.Fprintf(buf, "%s %s--%s %s", plantUMLAlias(r.Parent), parentCard, childCard,
+			plantUMLAlias(r.Child))
+		if r.Label != "" {
+			fmt.Fprintf(buf, " : %s", oneLine(r.Label))
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("@enduml\n")
+	return buf.String()
+}
+
+// writePlantUMLEntity writes an entity block for t. Primary key columns are
+// listed above a "--" separator, and multi-column unique and check
+// constraints below a ".." separator. Not-null columns are prefixed with
+// "*". The table comment, if any, is attached as a note.
+func writePlantUMLEntity(buf *strings.Builder, t *metadata.Table) {
+	alias := plantUMLAlias(t.Name)
+	fmt.Fprintf(buf, "entity %s as %s", plantUMLString(t.Name), alias)
+	if t.TableType == "view" {
+		buf.WriteString(" <<view>>")
+	}
+	buf.WriteString(" {\n")
+
+	fkCols := erdmodel.FKColumnSet(t)
+	uniqueCols := erdmodel.UniqueColumnSet(t)
+	writeCol := func(col *metadata.Column) {
+		buf.WriteString("  ")
+		if !col.Nullable || col.PrimaryKey {
+			buf.WriteString("* ")
+		}
+		fmt.Fprintf(buf, "%s : %s", oneLine(col.Name), oneLine(erdmodel.ColumnType(col)))
+		if col.PrimaryKey {
+			buf.WriteString(" <<PK>>")
+		}
+		if fkCols[col.Name] {
+			buf.WriteString(" <<FK>>")
+		}
+		if uniqueCols[col.Name] {
+			buf.WriteString(" <<unique>>")
+		}
+		if col.DefaultValue != "" {
+			fmt.Fprintf(buf, " = %s", oneLine(col.DefaultValue))
+		}
+		if col.Comment != "" {
+			fmt.Fprintf(buf, " // %s", oneLine(col.Comment))
+		}
+		buf.WriteString("\n")
+	}
+
+	var pkCols, otherCols []*metadata.Column
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			pkCols = append(pkCols, col)
+		} else {
+			otherCols = append(otherCols, col)
+		}
+	}
+	for _, col := range pkCols {
+		writeCol(col)
+	}
+	if len(pkCols) > 0 && len(otherCols) > 0 {
+		buf.WriteString("  --\n")
+	}
+	for _, col := range otherCols {
+		writeCol(col)
+	}
+
+	ucs := multiColumnUniques(t)
+	if len(ucs) > 0 || len(t.CheckConstraints) > 0 {
+		buf.WriteString("  ..\n")
+	}
+	for _, uc := range ucs {
+		buf.WriteString("  ")
+		if uc.Name != "" {
+			fmt.Fprintf(buf, "%s: ", oneLine(uc.Name))
+		}
+		fmt.Fprintf(buf, "UNIQUE (%s)\n", oneLine(strings.Join(uc.Columns, ", ")))
+	}
+	for _, cc := range t.CheckConstraints {
+		if cc == nil {
+			continue
+		}
+		buf.WriteString("  ")
+		if cc.Name != "" {
+			fmt.Fprintf(buf, "%s: ", oneLine(cc.Name))
+		}
+		fmt.Fprintf(buf, "CHECK (%s)\n", oneLine(cc.Clause))
+	}
+	buf.WriteString("}\n")
+
+	if t.Comment != "" {
+		fmt.Fprintf(buf, "note top of %s\n", alias)
+		for _, line := range strings.Split(strings.TrimSpace(t.Comment), "\n") {
+			fmt.Fprintf(buf, "  %s\n", strings.TrimSpace(line))
+		}
+		buf.WriteString("end note\n")
+	}
+}
+
+// plantUMLAliasRe matches characters not allowed in a PlantUML alias.
+var plantUMLAliasRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// plantUMLAlias returns the alias by which the diagram refers to table
+// name. The table's real name is the entity's display name; see
+// plantUMLString.
+func plantUMLAlias(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return plantUMLAliasRe.ReplaceAllString(name, "_")
+}
+
+// plantUMLString renders s as a double-quoted PlantUML string. PlantUML has
+// no escape for the double quote, so it's replaced with a single quote.
+func plantUMLString(s string) string {
+	return `"` + strings.ReplaceAll(oneLine(s), `"`, `'`) + `"`
+}
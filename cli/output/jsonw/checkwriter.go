@@ -0,0 +1,29 @@
+package jsonw
+
+import (
+	"io"
+
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.CheckWriter = (*checkWriter)(nil)
+
+// checkWriter implements output.CheckWriter for JSON.
+type checkWriter struct {
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewCheckWriter returns a JSON output.CheckWriter.
+func NewCheckWriter(out io.Writer, pr *output.Printing) output.CheckWriter {
+	return &checkWriter{out: out, pr: pr}
+}
+
+// Results implements output.CheckWriter. Always emits a JSON array,
+// even for the empty case.
+func (w *checkWriter) Results(results []output.CheckResult) error {
+	if results == nil {
+		results = []output.CheckResult{}
+	}
+	return writeJSON(w.out, w.pr, results)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/lg/lgt"
 	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
 	"github.com/neilotoole/sq/testh"
@@ -328,6 +329,24 @@ func TestPingWriter_Result(t *testing.T) {
 		require.Equal(t, "100ms", got["duration"],
 			"duration must be a human-readable string")
 		require.Equal(t, "connection refused", got["error"])
+		require.Equal(t, "source", got["failure"])
+	})
+
+	t.Run("tunnel_failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		pr := output.NewPrinting()
+		pr.EnableColor(false)
+
+		pw := jsonw.NewPingWriter(&buf, pr)
+		pingErr := driver.NewTunnelError("alice@bastion:22", errors.New("connection refused"))
+		err := pw.Result(src, 100*time.Millisecond, pingErr)
+		require.NoError(t, err)
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		require.Equal(t, false, got["pong"])
+		require.Equal(t, "ssh tunnel alice@bastion:22: connection refused", got["error"])
+		require.Equal(t, "tunnel", got["failure"])
 	})
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/neilotoole/sq/cli/output"
 	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/source"
 )
 
@@ -48,6 +49,10 @@ func (p pingWriter) Result(src *source.Source, d time.Duration, err error) error
 		Pong     bool   `json:"pong"`
 		Duration string `json:"duration"`
 		Error    string `json:"error,omitempty"`
+
+		// Failure is "tunnel" if the source's SSH tunnel couldn't be
+		// established, or "source" for any other failure.
+		Failure string `json:"failure,omitempty"`
 	}{
 		Source:   src,
 		Pong:     err == nil,
@@ -58,6 +63,10 @@ func (p pingWriter) Result(src *source.Source, d time.Duration, err error) error
 		// Concise human form when available, matching the error
 		// rendering of error.format=json.
 		r.Error = errz.HumanMessage(err)
+		r.Failure = "source"
+		if driver.IsTunnelError(err) {
+			r.Failure = "tunnel"
+		}
 	}
 
 	enc := jsoncolor.NewEncoder(p.out)
@@ -0,0 +1,25 @@
+package jsonw
+
+import (
+	"io"
+
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.PlanWriter = (*planWriter)(nil)
+
+// planWriter implements output.PlanWriter for JSON.
+type planWriter struct {
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewPlanWriter returns a JSON output.PlanWriter.
+func NewPlanWriter(out io.Writer, pr *output.Printing) output.PlanWriter {
+	return &planWriter{out: out, pr: pr}
+}
+
+// Plan implements output.PlanWriter.
+func (w *planWriter) Plan(plan output.QueryPlan) error {
+	return writeJSON(w.out, w.pr, plan)
+}
@@ -0,0 +1,35 @@
+package jsonw
+
+import (
+	"io"
+
+	"github.com/neilotoole/sq/cli/config"
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.QueryWriter = (*queryWriter)(nil)
+
+// queryWriter implements output.QueryWriter for JSON.
+type queryWriter struct {
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewQueryWriter returns a JSON output.QueryWriter.
+func NewQueryWriter(out io.Writer, pr *output.Printing) output.QueryWriter {
+	return &queryWriter{out: out, pr: pr}
+}
+
+// List implements output.QueryWriter. Always emits a JSON array, even
+// for the empty case.
+func (w *queryWriter) List(queries []*config.Query) error {
+	if queries == nil {
+		queries = []*config.Query{}
+	}
+	return writeJSON(w.out, w.pr, queries)
+}
+
+// Query implements output.QueryWriter.
+func (w *queryWriter) Query(q *config.Query) error {
+	return writeJSON(w.out, w.pr, q)
+}
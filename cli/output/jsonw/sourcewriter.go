@@ -139,3 +139,11 @@ func (w *sourceWriter) Groups(tree *source.Group) error {
 	}
 	return writeJSON(w.out, w.pr, tree)
 }
+
+// Imported implements output.SourceWriter.
+func (w *sourceWriter) Imported(rows []output.SourceImportRow) error {
+	if rows == nil {
+		rows = []output.SourceImportRow{}
+	}
+	return writeJSON(w.out, w.pr, rows)
+}
@@ -241,11 +241,12 @@ func writeTableHeading(buf *bytes.Buffer, tbl *metadata.Table, level int) {
 }
 
 // writeTableBody writes the per-table detail: an optional view-definition
-// block, a column table, and foreign-key, unique-constraint, index,
-// check-constraint, and trigger sections (each omitted when empty).
+// block, a column table, and column-profile, foreign-key, unique-constraint,
+// index, check-constraint, and trigger sections (each omitted when empty).
 func (w *metadataWriter) writeTableBody(buf *bytes.Buffer, tbl *metadata.Table) {
 	w.writeViewDefinition(buf, tbl)
 	w.writeColumns(buf, tbl)
+	w.writeProfile(buf, tbl)
 	w.writeForeignKeys(buf, tbl)
 	w.writeUniqueConstraints(buf, tbl)
 	w.writeIndexes(buf, tbl)
@@ -345,6 +346,45 @@ func (w *metadataWriter) writeForeignKeys(buf *bytes.Buffer, tbl *metadata.Table
 	}
 }
 
+// writeProfile renders a "Profile" subsection when tbl's columns have been
+// profiled (sq inspect --profile). The Histogram column is included only
+// when there are histograms.
+func (w *metadataWriter) writeProfile(buf *bytes.Buffer, tbl *metadata.Table) {
+	rows := commonw.ProfileRows(tbl)
+	if len(rows) == 0 {
+		return
+	}
+
+	showHistogram := slices.ContainsFunc(rows, func(r commonw.ProfileRow) bool { return r.Histogram != "" })
+	headers := []string{"Column", "Nulls", "Distinct", "Min", "Max", "Mean", "Std dev", "Length", "Top"}
+	aligns := []string{"---", "---:", "---:", "---", "---", "---:", "---:", "---", "---"}
+	if showHistogram {
+		headers = append(headers, "Histogram")
+		aligns = append(aligns, "---")
+	}
+
+	buf.WriteString("\n**Profile:**\n\n")
+	writeTableRow(buf, headers...)
+	writeTableRow(buf, aligns...)
+	for _, r := range rows {
+		cells := []string{
+			mdCodeCell(r.Column),
+			r.Nulls,
+			r.Distinct,
+			mdCodeCell(r.Min),
+			mdCodeCell(r.Max),
+			r.Mean,
+			r.StdDev,
+			r.Length,
+			escapeMarkdown(r.Top),
+		}
+		if showHistogram {
+			cells = append(cells, r.Histogram)
+		}
+		writeTableRow(buf, cells...)
+	}
+}
+
 func (w *metadataWriter) writeUniqueConstraints(buf *bytes.Buffer, tbl *metadata.Table) {
 	rows := commonw.UCRows(tbl)
 	if len(rows) == 0 {
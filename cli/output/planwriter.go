@@ -0,0 +1,50 @@
+package output
+
+import "github.com/neilotoole/sq/libsq/driver"
+
+// QueryPlan is the structured form of an --explain result: the plan of
+// a query, normalized across databases. Consumed by PlanWriter
+// implementations and produced by the --explain path of the slq and sql
+// commands.
+type QueryPlan struct {
+	// Plan is the root node of the plan.
+	Plan *driver.PlanNode `json:"plan" yaml:"plan"`
+
+	// SLQ is the input SLQ query, if any. It is empty for "sq sql".
+	SLQ string `json:"slq,omitempty" yaml:"slq,omitempty"`
+
+	// SQL is the SQL query that was explained.
+	SQL string `json:"sql" yaml:"sql"`
+
+	// Dialect is the lower-case dialect / driver-type name (e.g.
+	// "postgres") of the database that produced the plan.
+	Dialect string `json:"dialect" yaml:"dialect"`
+
+	// Target is the handle of the source the SQL targets. For
+	// cross-source queries it is the synthetic join DB's handle.
+	Target string `json:"target" yaml:"target"`
+
+	// JoinCopies lists the tables copied into the join DB for a
+	// cross-source query.
+	JoinCopies []JoinCopy `json:"join_copies,omitempty" yaml:"join_copies,omitempty"`
+
+	// Analyze is true if the query was executed, and the plan
+	// reports actual row counts.
+	Analyze bool `json:"analyze" yaml:"analyze"`
+}
+
+// JoinCopy describes a table copy performed for a cross-source join.
+type JoinCopy struct {
+	// From is the source table, e.g. "@sakila_pg.actor".
+	From string `json:"from" yaml:"from"`
+
+	// To is the table in the join DB, e.g. "@join_b7x2.actor".
+	To string `json:"to" yaml:"to"`
+}
+
+// PlanWriter writes a QueryPlan. Used by the --explain path of the slq
+// and sql commands.
+type PlanWriter interface {
+	// Plan writes plan to the writer's output.
+	Plan(plan QueryPlan) error
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/fatih/color"
@@ -141,6 +142,38 @@ type Printing struct {
 	// See excelw.OptTimeFormat.
 	ExcelTimeFormat string
 
+	// ExcelSheets are the names of the sheets for successive result sets.
+	// See excelw.OptSheets.
+	ExcelSheets []string
+
+	// ExcelSplitBy is the name of the column whose values split a result
+	// set across sheets. See excelw.OptSplitBy.
+	ExcelSplitBy string
+
+	// ExcelMode is one of "new", "append" or "replace". Empty is
+	// equivalent to "new". See excelw.OptMode.
+	ExcelMode string
+
+	// ExcelFile is the path of the workbook to update when ExcelMode
+	// is "append" or "replace".
+	ExcelFile string
+
+	// ExcelHeaderStyle is the style of the header row. Empty is
+	// equivalent to "bold". See excelw.OptHeaderStyle.
+	ExcelHeaderStyle string
+
+	// ExcelAutoFilter determines if an autofilter is added to the
+	// header row. See excelw.OptAutoFilter.
+	ExcelAutoFilter bool
+
+	// ExcelFreezeHeader determines if the header row is frozen.
+	// See excelw.OptFreezeHeader.
+	ExcelFreezeHeader bool
+
+	// ExcelColWidth is the column width preset. Empty is equivalent
+	// to "kind". See excelw.OptColWidth.
+	ExcelColWidth string
+
 	// FlushThreshold is the size in bytes after which an output writer
 	// should flush any internal buffer.
 	FlushThreshold int
@@ -260,6 +293,14 @@ func (pr *Printing) Clone() *Printing {
 		ExcelDatetimeFormat:    pr.ExcelDatetimeFormat,
 		ExcelDateFormat:        pr.ExcelDateFormat,
 		ExcelTimeFormat:        pr.ExcelTimeFormat,
+		ExcelSheets:            slices.Clone(pr.ExcelSheets),
+		ExcelSplitBy:           pr.ExcelSplitBy,
+		ExcelMode:              pr.ExcelMode,
+		ExcelFile:              pr.ExcelFile,
+		ExcelHeaderStyle:       pr.ExcelHeaderStyle,
+		ExcelAutoFilter:        pr.ExcelAutoFilter,
+		ExcelFreezeHeader:      pr.ExcelFreezeHeader,
+		ExcelColWidth:          pr.ExcelColWidth,
 		Diff:                   pr.Diff.Clone(),
 	}
 
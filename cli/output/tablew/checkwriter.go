@@ -0,0 +1,94 @@
+package tablew
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+var _ output.CheckWriter = (*checkWriter)(nil)
+
+// checkWriter is the text/table implementation of output.CheckWriter.
+type checkWriter struct {
+	tbl *table
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewCheckWriter returns a text/table output.CheckWriter.
+func NewCheckWriter(out io.Writer, pr *output.Printing) output.CheckWriter {
+	tbl := &table{out: out, pr: pr, header: pr.ShowHeader}
+	tbl.reset()
+	return &checkWriter{tbl: tbl, out: out, pr: pr}
+}
+
+// Results implements output.CheckWriter. A summary line follows the
+// table of results. In verbose mode, the rule's target, the sample
+// violations, and the elapsed time are also printed.
+func (w *checkWriter) Results(results []output.CheckResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	header := []string{"STATUS", "RULE", "FAILURES", "MESSAGE"}
+	if w.pr.Verbose {
+		header = []string{"STATUS", "RULE", "TYPE", "TARGET", "FAILURES", "MESSAGE", "SAMPLES", "ELAPSED"}
+	}
+
+	var passed, failed, errored int
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		var status string
+		switch res.Status {
+		case output.CheckStatusPass:
+			passed++
+			status = w.pr.Success.Sprint(res.Status)
+		case output.CheckStatusFail:
+			failed++
+			status = w.pr.Error.Sprint(res.Status)
+		default:
+			errored++
+			status = w.pr.Warning.Sprint(res.Status)
+		}
+
+		failures := strconv.FormatInt(res.Failures, 10)
+		if w.pr.Verbose {
+			rows = append(rows, []string{
+				status, res.Name, res.Type, res.Target, failures, res.Message,
+				strings.Join(res.Samples, "; "),
+				res.Elapsed.Truncate(time.Millisecond).String(),
+			})
+			continue
+		}
+		rows = append(rows, []string{status, res.Name, failures, res.Message})
+	}
+
+	w.tbl.tblImpl.SetHeader(header)
+	w.tbl.tblImpl.SetColTrans(1, w.pr.Handle.SprintFunc())
+	if w.pr.Verbose {
+		w.tbl.tblImpl.SetColTrans(2, w.pr.Faint.SprintFunc())
+		w.tbl.tblImpl.SetColTrans(3, w.pr.String.SprintFunc())
+		w.tbl.tblImpl.SetColTrans(4, w.pr.Number.SprintFunc())
+		w.tbl.tblImpl.SetColTrans(6, w.pr.Faint.SprintFunc())
+		w.tbl.tblImpl.SetColTrans(7, w.pr.Duration.SprintFunc())
+	} else {
+		w.tbl.tblImpl.SetColTrans(2, w.pr.Number.SprintFunc())
+	}
+
+	if err := w.tbl.appendRowsAndRenderAll(context.TODO(), rows); err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("\n%d passed, %d failed", passed, failed)
+	if errored > 0 {
+		summary += fmt.Sprintf(", %d errored", errored)
+	}
+	_, err := fmt.Fprintln(w.out, w.pr.Faint.Sprint(summary))
+	return errz.Err(err)
+}
@@ -198,6 +198,9 @@ func migrateStatusLabel(status string) string {
 func migrateDetail(r output.KeyringMigrateRow) string {
 	switch r.Status {
 	case output.KeyringMigrateStatusPlanned:
+		if r.NewLocation != "" {
+			return r.NewLocation
+		}
 		return "${keyring:<new-id>}"
 	case output.KeyringMigrateStatusMigrated:
 		return r.NewLocation
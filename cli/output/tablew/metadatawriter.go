@@ -52,11 +52,17 @@ func (w *mdWriter) DriverMetadata(drvrs []driver.Metadata) error {
 
 // TableMetadata implements output.MetadataWriter.
 func (w *mdWriter) TableMetadata(tblMeta *metadata.Table) error {
+	var err error
 	if w.tbl.pr.Verbose {
-		return w.doTableMetaVerbose(tblMeta)
+		err = w.doTableMetaVerbose(tblMeta)
+	} else {
+		err = w.doTableMeta(tblMeta)
+	}
+	if err != nil {
+		return err
 	}
 
-	return w.doTableMeta(tblMeta)
+	return w.printProfiles([]*metadata.Table{tblMeta}, false)
 }
 
 func (w *mdWriter) doTableMeta(md *metadata.Table) error {
@@ -186,6 +192,22 @@ func (w *mdWriter) printTablesVerbose(tbls []*metadata.Table) error {
 		return ""
 	}
 
+	// getName and getType return the column's name and type, annotated
+	// if the column of an ingested source was overridden, as by option
+	// "ingest.schema".
+	getName := func(col *metadata.Column) string {
+		if col.IngestOverride == nil || col.IngestOverride.RenamedFrom == "" {
+			return col.Name
+		}
+		return col.Name + " " + w.tbl.pr.Subdued.Sprint("(was "+col.IngestOverride.RenamedFrom+")")
+	}
+	getType := func(col *metadata.Column) string {
+		if col.IngestOverride == nil || col.IngestOverride.Kind == kind.Unknown {
+			return col.BaseType
+		}
+		return col.BaseType + " " + w.tbl.pr.Subdued.Sprint("(override)")
+	}
+
 	// formatIdxCell turns the per-column index entries into a single
 	// comma-joined cell. UC-backing entries are wrapped in parens and
 	// styled with [Printing.Subdued] (italic + faint) so they read as
@@ -239,8 +261,8 @@ func (w *mdWriter) printTablesVerbose(tbls []*metadata.Table) error {
 			tbl.TableType,
 			strconv.FormatInt(tbl.RowCount, 10),
 			strconv.Itoa(len(tbl.Columns)),
-			tbl.Columns[0].Name,
-			tbl.Columns[0].BaseType,
+			getName(tbl.Columns[0]),
+			getType(tbl.Columns[0]),
 			getPK(tbl.Columns[0]),
 			getAuto(tbl.Columns[0]),
 			formatFKRefs(fkByCol[tbl.Columns[0].Name]),
@@ -256,8 +278,8 @@ func (w *mdWriter) printTablesVerbose(tbls []*metadata.Table) error {
 				"",
 				"",
 				"",
-				tbl.Columns[i].Name,
-				tbl.Columns[i].BaseType,
+				getName(tbl.Columns[i]),
+				getType(tbl.Columns[i]),
 				getPK(tbl.Columns[i]),
 				getAuto(tbl.Columns[i]),
 				formatFKRefs(fkByCol[tbl.Columns[i].Name]),
@@ -528,11 +550,91 @@ func (w *mdWriter) doSourceMetaFull(md *metadata.Source) error {
 		return cmp.Compare(a.TableType, b.TableType)
 	})
 
+	var err error
 	if w.tbl.pr.Verbose {
-		return w.printTablesVerbose(md.Tables)
+		err = w.printTablesVerbose(md.Tables)
+	} else {
+		err = w.printTables(md.Tables)
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.printProfiles(md.Tables, true)
+}
+
+// printProfiles prints the column profiles of tables, if any column has
+// been profiled. If showTable is true, a TABLE column precedes COLUMN.
+// The HISTOGRAM column is shown only if there are histograms.
+func (w *mdWriter) printProfiles(tables []*metadata.Table, showTable bool) error {
+	type tableProfile struct {
+		name string
+		rows []commonw.ProfileRow
 	}
 
-	return w.printTables(md.Tables)
+	var (
+		profiles      []tableProfile
+		showHistogram bool
+	)
+	for _, tbl := range tables {
+		rows := commonw.ProfileRows(tbl)
+		if len(rows) == 0 {
+			continue
+		}
+		profiles = append(profiles, tableProfile{name: tbl.Name, rows: rows})
+		showHistogram = showHistogram || slices.ContainsFunc(rows, func(r commonw.ProfileRow) bool {
+			return r.Histogram != ""
+		})
+	}
+
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w.tbl.out)
+	w.tbl.reset()
+
+	headers := []string{"COLUMN", "NULLS", "DISTINCT", "MIN", "MAX", "MEAN", "STDDEV", "LENGTH", "TOP"}
+	if showTable {
+		headers = append([]string{"TABLE"}, headers...)
+	}
+	if showHistogram {
+		headers = append(headers, "HISTOGRAM")
+	}
+
+	pr := w.tbl.pr
+	colTrans := []func(...any) string{
+		pr.String.SprintFunc(), pr.Number.SprintFunc(), pr.Number.SprintFunc(),
+		pr.Faint.SprintFunc(), pr.Faint.SprintFunc(), pr.Number.SprintFunc(),
+		pr.Number.SprintFunc(), pr.Number.SprintFunc(), pr.Faint.SprintFunc(),
+	}
+	if showTable {
+		colTrans = append([]func(...any) string{pr.String.SprintFunc()}, colTrans...)
+	}
+	if showHistogram {
+		colTrans = append(colTrans, pr.Number.SprintFunc())
+	}
+
+	w.tbl.tblImpl.SetHeader(headers)
+	for i, fn := range colTrans {
+		w.tbl.tblImpl.SetColTrans(i, fn)
+	}
+
+	var rows [][]string
+	for _, tp := range profiles {
+		for _, r := range tp.rows {
+			row := []string{r.Column, r.Nulls, r.Distinct, r.Min, r.Max, r.Mean, r.StdDev, r.Length, r.Top}
+			if showTable {
+				row = append([]string{tp.name}, row...)
+			}
+			if showHistogram {
+				row = append(row, r.Histogram)
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return w.tbl.appendRowsAndRenderAll(context.TODO(), rows)
 }
 
 // DBProperties implements output.MetadataWriter.
@@ -0,0 +1,87 @@
+package tablew
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ output.PlanWriter = (*planWriter)(nil)
+
+// planWriter is the text implementation of output.PlanWriter. The plan
+// is rendered as a tree, one node per line.
+type planWriter struct {
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewPlanWriter returns a text output.PlanWriter.
+func NewPlanWriter(out io.Writer, pr *output.Printing) output.PlanWriter {
+	return &planWriter{out: out, pr: pr}
+}
+
+// Plan implements output.PlanWriter. Any join copies are listed before
+// the plan tree.
+func (w *planWriter) Plan(plan output.QueryPlan) error {
+	sb := &strings.Builder{}
+	for _, jc := range plan.JoinCopies {
+		sb.WriteString(w.pr.Faint.Sprint("join copy "))
+		sb.WriteString(w.pr.Handle.Sprint(jc.From))
+		sb.WriteString(w.pr.Faint.Sprint(" → "))
+		sb.WriteString(w.pr.Handle.Sprint(jc.To))
+		sb.WriteByte('\n')
+	}
+	if len(plan.JoinCopies) > 0 {
+		sb.WriteByte('\n')
+	}
+
+	if plan.Plan != nil {
+		w.writeNode(sb, plan.Plan, "", "")
+	}
+
+	_, err := io.WriteString(w.out, sb.String())
+	return errz.Err(err)
+}
+
+// writeNode writes node and its descendants to sb. Arg prefix is written
+// before node, and childPrefix before each of node's children.
+func (w *planWriter) writeNode(sb *strings.Builder, node *driver.PlanNode, prefix, childPrefix string) {
+	pr := w.pr
+	sb.WriteString(pr.Faint.Sprint(prefix))
+	sb.WriteString(pr.Key.Sprint(node.Op))
+	if node.Detail != "" {
+		sb.WriteString("  ")
+		sb.WriteString(pr.String.Sprint(node.Detail))
+	}
+
+	for _, stat := range []struct {
+		val   *float64
+		label string
+	}{
+		{node.EstRows, "rows="},
+		{node.ActualRows, "actual="},
+		{node.Cost, "cost="},
+	} {
+		if stat.val == nil {
+			continue
+		}
+		sb.WriteString("  ")
+		sb.WriteString(pr.Faint.Sprint(stat.label))
+		val := math.Round(*stat.val*100) / 100
+		sb.WriteString(pr.Number.Sprint(strconv.FormatFloat(val, 'f', -1, 64)))
+	}
+	sb.WriteByte('\n')
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			w.writeNode(sb, child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			w.writeNode(sb, child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
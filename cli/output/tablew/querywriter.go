@@ -0,0 +1,93 @@
+package tablew
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/neilotoole/sq/cli/config"
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+var _ output.QueryWriter = (*queryWriter)(nil)
+
+// queryWriter is the text/table implementation of output.QueryWriter.
+type queryWriter struct {
+	tbl *table
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewQueryWriter returns a text/table output.QueryWriter.
+func NewQueryWriter(out io.Writer, pr *output.Printing) output.QueryWriter {
+	tbl := &table{out: out, pr: pr, header: pr.ShowHeader}
+	tbl.reset()
+	return &queryWriter{tbl: tbl, out: out, pr: pr}
+}
+
+// List implements output.QueryWriter. In verbose mode, the query text
+// is also printed.
+func (w *queryWriter) List(queries []*config.Query) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	header := []string{"NAME", "ARGS", "DESCRIPTION"}
+	if w.pr.Verbose {
+		header = append(header, "SLQ")
+	}
+
+	rows := make([][]string, 0, len(queries))
+	for _, q := range queries {
+		row := []string{q.Name, w.sprintParams(q), q.Description}
+		if w.pr.Verbose {
+			row = append(row, q.SLQ)
+		}
+		rows = append(rows, row)
+	}
+
+	w.tbl.tblImpl.SetHeader(header)
+	w.tbl.tblImpl.SetColTrans(0, w.pr.Handle.SprintFunc())
+	w.tbl.tblImpl.SetColTrans(2, w.pr.Faint.SprintFunc())
+	return w.tbl.appendRowsAndRenderAll(context.TODO(), rows)
+}
+
+// Query implements output.QueryWriter.
+func (w *queryWriter) Query(q *config.Query) error {
+	sb := strings.Builder{}
+	sb.WriteString(w.pr.Handle.Sprint(q.Name))
+	if q.Description != "" {
+		sb.WriteString("  ")
+		sb.WriteString(w.pr.Faint.Sprint(q.Description))
+	}
+	sb.WriteByte('\n')
+	if params := w.sprintParams(q); params != "" {
+		sb.WriteString(params)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(w.pr.String.Sprint(q.SLQ))
+	sb.WriteByte('\n')
+
+	_, err := fmt.Fprint(w.out, sb.String())
+	return errz.Err(err)
+}
+
+// sprintParams returns the query's args, e.g. "$region=EU $limit",
+// where the default value (if any) follows the arg name.
+func (w *queryWriter) sprintParams(q *config.Query) string {
+	params, err := q.Params()
+	if err != nil {
+		return w.pr.Error.Sprint("invalid")
+	}
+
+	a := make([]string, len(params))
+	for i, p := range params {
+		a[i] = w.pr.Key.Sprint("$" + p)
+		if v, ok := q.Args[p]; ok {
+			a[i] += w.pr.Faint.Sprint("=") + w.pr.String.Sprint(v)
+		}
+	}
+	return strings.Join(a, " ")
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/samber/lo"
+
 	"github.com/neilotoole/sq/cli/output"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/location"
@@ -195,6 +197,37 @@ func (w *sourceWriter) Removed(srcs ...*source.Source) error {
 	return nil
 }
 
+// Imported implements output.SourceWriter.
+func (w *sourceWriter) Imported(rows []output.SourceImportRow) error {
+	pr := w.tbl.pr
+	if len(rows) == 0 {
+		pr.Faint.Fprintln(w.tbl.out, "Nothing to import.")
+		return nil
+	}
+
+	w.tbl.reset()
+	tblRows := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		detail := r.Detail
+		if r.From != "" {
+			detail = strings.Join(lo.Compact([]string{"from " + r.From, r.Detail}), "; ")
+		}
+
+		var status string
+		switch r.Status {
+		case output.SourceImportStatusFailed:
+			status = pr.Error.Sprint(r.Status)
+		case output.SourceImportStatusSkipped:
+			status = pr.Warning.Sprint(r.Status)
+		default:
+			status = pr.Success.Sprint(r.Status)
+		}
+		tblRows = append(tblRows, []string{pr.Handle.Sprint(r.Handle), status, pr.Faint.Sprint(detail)})
+	}
+	w.tbl.tblImpl.SetHeader([]string{"HANDLE", "STATUS", "DETAIL"})
+	return w.tbl.appendRowsAndRenderAll(context.TODO(), tblRows)
+}
+
 func renderSrcOptions(pr *output.Printing, src *source.Source) string {
 	if src == nil || src.Options == nil || len(src.Options) == 0 {
 		return ""
@@ -47,6 +47,23 @@ type RecordWriter interface {
 	Close(ctx context.Context) error
 }
 
+// ResultSetWriter is a RecordWriter that writes several result sets, such
+// as the results of each query of a multi-statement SLQ script, to a
+// single output document: for example, a YAML document stream, or an XLSX
+// workbook with one sheet per result set. Each result set is written via
+// Open, WriteRecords and EndResultSet. Close is invoked once, after the
+// final result set, and completes the document.
+//
+// A RecordWriter that doesn't implement ResultSetWriter writes just one
+// result set: a new RecordWriter is used for each subsequent result set.
+type ResultSetWriter interface {
+	RecordWriter
+
+	// EndResultSet ends the current result set. A subsequent call
+	// to Open begins the next result set.
+	EndResultSet(ctx context.Context) error
+}
+
 // RecordInsertWriter outputs details of record insertion into a destination
 // table.
 //
@@ -127,6 +144,27 @@ type SourceWriter interface {
 
 	// Groups prints a list of groups.
 	Groups(tree *source.Group) error
+
+	// Imported is called with the outcome of "sq src import".
+	Imported(rows []SourceImportRow) error
+}
+
+// SourceImportStatus* enumerate the status values surfaced by
+// SourceWriter.Imported. The string forms are part of the JSON contract.
+const (
+	SourceImportStatusImported = "imported" // added under its own handle
+	SourceImportStatusRenamed  = "renamed"  // added under a new handle, due to a conflict
+	SourceImportStatusSkipped  = "skipped"  // not added, due to a conflict
+	SourceImportStatusFailed   = "failed"   // not added, due to an error (e.g. ping)
+)
+
+// SourceImportRow describes one source's outcome in "sq src import".
+// Status takes one of the SourceImportStatus* constants.
+type SourceImportRow struct {
+	Handle string `json:"handle"`
+	From   string `json:"from,omitempty"` // populated for "renamed": the handle in the import file
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
 }
 
 // ErrorWriter outputs errors.
@@ -203,7 +241,10 @@ type Writers struct {
 	Version      VersionWriter
 	Config       ConfigWriter
 	SQL          SQLWriter
+	Plan         PlanWriter
 	Keyring      KeyringWriter
+	Query        QueryWriter
+	Check        CheckWriter
 }
 
 // KeyringRef is one row of "sq config keyring ls" output. Each row
@@ -238,12 +279,14 @@ const (
 
 // KeyringMigrateRow describes one source's outcome in a migrate plan
 // (dry-run) or migrate result (applied). Status takes one of the
-// KeyringMigrateStatus* constants.
+// KeyringMigrateStatus* constants. The same rows are used by
+// "sq config sops migrate", whose planned placeholder is known in
+// advance.
 type KeyringMigrateRow struct {
 	Handle      string `json:"handle"`
 	Status      string `json:"status"`
 	Reason      string `json:"reason,omitempty"`       // populated for "skip"
-	NewLocation string `json:"new_location,omitempty"` // populated for "migrated"
+	NewLocation string `json:"new_location,omitempty"` // populated for "migrated", and for "planned" if known
 	Error       string `json:"error,omitempty"`        // populated for "failed"
 }
 
@@ -306,5 +349,61 @@ type KeyringWriter interface {
 	Prune(rows []KeyringPruneRow, dryRun bool) error
 }
 
+// QueryWriter prints output for the "sq query" command group, which
+// manages saved queries. Implementations live in cli/output/tablew
+// (text/table), cli/output/jsonw (JSON), and cli/output/yamlw (YAML).
+type QueryWriter interface {
+	// List prints the saved queries, as listed by "sq query ls".
+	List(queries []*config.Query) error
+
+	// Query prints the details of a single saved query, e.g. after
+	// "sq query add".
+	Query(q *config.Query) error
+}
+
+// CheckStatus* enumerate the status values of a CheckResult. The string
+// forms are part of the JSON contract and must not change casually.
+const (
+	CheckStatusPass  = "pass"  // the rule holds
+	CheckStatusFail  = "fail"  // the rule is violated
+	CheckStatusError = "error" // the rule couldn't be evaluated
+)
+
+// CheckResult is the outcome of evaluating one rule of "sq check".
+type CheckResult struct {
+	// Name is the rule's name.
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the rule type, e.g. "not_null".
+	Type string `json:"type" yaml:"type"`
+
+	// Target is what the rule checks, e.g. "@sakila.actor.first_name",
+	// or the SLQ of a query rule.
+	Target string `json:"target" yaml:"target"`
+
+	// Status is one of the CheckStatus* constants.
+	Status string `json:"status" yaml:"status"`
+
+	// Message describes the violation, or the error, of a rule that
+	// didn't pass.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// Samples holds a few of the values, or rows, that violate the rule.
+	Samples []string `json:"samples,omitempty" yaml:"samples,omitempty"`
+
+	// Failures is the number of violations, e.g. the number of null
+	// values for a not_null rule.
+	Failures int64 `json:"failures" yaml:"failures"`
+
+	// Elapsed is the time taken to evaluate the rule.
+	Elapsed time.Duration `json:"-" yaml:"-"`
+}
+
+// CheckWriter outputs the results of "sq check".
+type CheckWriter interface {
+	// Results outputs the result of each rule.
+	Results(results []CheckResult) error
+}
+
 // NewRecordWriterFunc is a func type that returns an output.RecordWriter.
 type NewRecordWriterFunc func(out io.Writer, pr *Printing) RecordWriter
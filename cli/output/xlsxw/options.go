@@ -0,0 +1,147 @@
+package xlsxw
+
+import (
+	"slices"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/options"
+)
+
+// Values for OptMode.
+const (
+	ModeNew     = "new"
+	ModeAppend  = "append"
+	ModeReplace = "replace"
+)
+
+// Values for OptHeaderStyle.
+const (
+	HeaderStyleBold   = "bold"
+	HeaderStyleShaded = "shaded"
+	HeaderStyleNone   = "none"
+)
+
+// Values for OptColWidth.
+const (
+	ColWidthKind = "kind"
+	ColWidthAuto = "auto"
+	ColWidthNone = "none"
+)
+
+// oneOf returns a validation func that accepts only the given values.
+func oneOf(key string, vals ...string) func(string) error {
+	return func(s string) error {
+		if !slices.Contains(vals, s) {
+			return errz.Errorf("config: %s: must be one of %v, but got {%s}", key, vals, s)
+		}
+		return nil
+	}
+}
+
+var (
+	// OptSheets specifies the sheet names for successive result sets.
+	OptSheets = options.NewString(
+		"format.excel.sheets",
+		nil,
+		"",
+		nil,
+		"Sheet names for Excel output",
+		`Comma-separated list of sheet names for Excel output. When a query or
+script produces several result sets, each is written to its own sheet,
+named in order from this list. By default, the sheets are named "data",
+"data2", "data3", etc.
+
+  $ sq '.actor; .film' --xlsx -o report.xlsx --format.excel.sheets=actors,films`,
+		options.TagOutput,
+	)
+
+	// OptSplitBy specifies a column whose values split a result set
+	// across sheets.
+	OptSplitBy = options.NewString(
+		"format.excel.split-by",
+		nil,
+		"",
+		nil,
+		"Split Excel output into sheets by column value",
+		`Name of a result column whose values split Excel output into sheets:
+each distinct value of the column gets its own sheet, named for the value.
+Characters that aren't allowed in a sheet name are replaced with "_".
+
+  $ sq '.payment' --xlsx -o payments.xlsx --format.excel.split-by=staff_id`,
+		options.TagOutput,
+	)
+
+	// OptMode specifies whether Excel output creates a new workbook, or
+	// updates an existing workbook.
+	OptMode = options.NewString(
+		"format.excel.mode",
+		nil,
+		ModeNew,
+		oneOf("format.excel.mode", ModeNew, ModeAppend, ModeReplace),
+		"Create new or update existing Excel workbook",
+		`Determines how Excel output is written to the file specified by --output.
+Allowed values are:
+
+  new       write a new workbook, overwriting any existing file
+  append    add sheets to an existing workbook, which must not already
+            have sheets of the same name
+  replace   add sheets to an existing workbook, replacing any existing
+            sheets of the same name
+
+In modes append and replace, the workbook's other sheets are untouched, and
+the workbook is only updated if the query succeeds. If the file doesn't
+exist, a new workbook is created.
+
+  $ sq '.actor' --xlsx -o report.xlsx --format.excel.mode=replace --format.excel.sheets=actors`,
+		options.TagOutput,
+	)
+
+	// OptHeaderStyle specifies the style of Excel header rows.
+	OptHeaderStyle = options.NewString(
+		"format.excel.header-style",
+		nil,
+		HeaderStyleBold,
+		oneOf("format.excel.header-style", HeaderStyleBold, HeaderStyleShaded, HeaderStyleNone),
+		"Style of Excel header row",
+		`Style of the header row of Excel output. Allowed values are "bold", "shaded"
+(bold, on a grey background, with a bottom border), and "none".`,
+		options.TagOutput,
+	)
+
+	// OptAutoFilter determines if Excel output has an autofilter.
+	OptAutoFilter = options.NewBool(
+		"format.excel.autofilter",
+		nil,
+		false,
+		"Add autofilter to Excel header row",
+		`When true, an autofilter is added to the header row of each sheet of Excel
+output, so that the data can be sorted and filtered in Excel.`,
+		options.TagOutput,
+	)
+
+	// OptFreezeHeader determines if the Excel header row is frozen.
+	OptFreezeHeader = options.NewBool(
+		"format.excel.freeze-header",
+		nil,
+		false,
+		"Freeze Excel header row",
+		`When true, the header row of each sheet of Excel output is frozen, so that
+it remains visible when scrolling.`,
+		options.TagOutput,
+	)
+
+	// OptColWidth specifies how Excel column widths are set.
+	OptColWidth = options.NewString(
+		"format.excel.col-width",
+		nil,
+		ColWidthKind,
+		oneOf("format.excel.col-width", ColWidthKind, ColWidthAuto, ColWidthNone),
+		"Excel column width preset",
+		`Determines how the column widths of Excel output are set. Allowed values are:
+
+  kind   a preset width for each kind of column, e.g. wider for text
+  auto   fit the width to the column's content (up to a maximum)
+  none   use Excel's default width`,
+		options.TagOutput,
+	)
+)
@@ -4,20 +4,26 @@
 package xlsxw
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/shopspring/decimal"
 	excelize "github.com/xuri/excelize/v2"
 
 	"github.com/neilotoole/sq/cli/output"
 	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
 	"github.com/neilotoole/sq/libsq/core/kind"
 	"github.com/neilotoole/sq/libsq/core/record"
 	"github.com/neilotoole/sq/libsq/core/stringz"
@@ -39,17 +45,58 @@ type recordWriter struct {
 	// getDecimalStyle.
 	mDecimalPlacesStyles map[int]int
 	recMeta              record.Meta
-	nextRow              int
 	timeStyle            int
 	dateStyle            int
 	datetimeStyle        int
 	headerStyle          int
 
+	// resultSets is the count of result sets ended via EndResultSet.
+	resultSets int
+
+	// sheet is the sheet being written to. It is nil when the result
+	// set is split across sheets; see splitCol.
+	sheet *sheet
+
+	// sheets holds the sheets created by this writer, keyed by
+	// lowercase name (Excel sheet names are case-insensitive).
+	sheets map[string]*sheet
+
+	// rsSheets holds the sheets of the current result set, in
+	// order of creation. They are finished by finishSheets.
+	rsSheets []*sheet
+
+	// placeholder is the name of the empty sheet of a new workbook,
+	// which is renamed to become the first sheet written. It is
+	// empty when there is no such sheet.
+	placeholder string
+
+	// splitCol is the index of the column whose values split the
+	// current result set across sheets, or -1.
+	splitCol int
+
 	mu     sync.Mutex
 	header bool
 }
 
-var _ output.NewRecordWriterFunc = NewRecordWriter
+// sheet holds the state of a worksheet being written.
+type sheet struct {
+	name string
+
+	// splitKey identifies the split column value of the sheet's
+	// records. It is empty when the result set isn't split.
+	splitKey string
+
+	// widths holds the widest content of each column. It is
+	// only populated when the col width preset is "auto".
+	widths []int
+
+	nextRow int
+}
+
+var (
+	_ output.NewRecordWriterFunc = NewRecordWriter
+	_ output.ResultSetWriter     = (*recordWriter)(nil)
+)
 
 // NewRecordWriter returns an output.RecordWriter instance for XLSX.
 func NewRecordWriter(out io.Writer, pr *output.Printing) output.RecordWriter {
@@ -58,6 +105,8 @@ func NewRecordWriter(out io.Writer, pr *output.Printing) output.RecordWriter {
 		pr:                   pr,
 		header:               pr.ShowHeader,
 		mDecimalPlacesStyles: map[int]int{},
+		sheets:               map[string]*sheet{},
+		splitCol:             -1,
 	}
 }
 
@@ -71,10 +120,22 @@ func NewRecordWriter(out io.Writer, pr *output.Printing) output.RecordWriter {
 func (w *recordWriter) initStyles() error {
 	var err error
 
-	if w.headerStyle, err = w.xfile.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true},
-	}); err != nil {
-		return errw(err)
+	switch w.pr.ExcelHeaderStyle {
+	case HeaderStyleNone:
+	case HeaderStyleShaded:
+		if w.headerStyle, err = w.xfile.NewStyle(&excelize.Style{
+			Font:   &excelize.Font{Bold: true},
+			Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9D9D9"}},
+			Border: []excelize.Border{{Type: "bottom", Color: "000000", Style: 1}},
+		}); err != nil {
+			return errw(err)
+		}
+	default:
+		if w.headerStyle, err = w.xfile.NewStyle(&excelize.Style{
+			Font: &excelize.Font{Bold: true},
+		}); err != nil {
+			return errw(err)
+		}
 	}
 
 	if w.pr.ExcelDatetimeFormat != "" {
@@ -158,63 +219,239 @@ func (w *recordWriter) Open(_ context.Context, recMeta record.Meta) error {
 	defer w.mu.Unlock()
 
 	w.recMeta = recMeta
+	if w.xfile == nil {
+		if err := w.openFile(); err != nil {
+			return err
+		}
+
+		if err := w.initStyles(); err != nil {
+			return err
+		}
+	}
+
+	w.sheet = nil
+	w.splitCol = -1
+	if w.pr.ExcelSplitBy != "" {
+		// The sheets are created on demand by WriteRecords, one
+		// for each distinct value of the split column.
+		w.splitCol = slices.Index(recMeta.MungedNames(), w.pr.ExcelSplitBy)
+		if w.splitCol == -1 {
+			return errz.Errorf("excel: split-by column {%s} not found in result set", w.pr.ExcelSplitBy)
+		}
+		return nil
+	}
+
 	var err error
+	w.sheet, err = w.newSheet(w.sheetName())
+	return err
+}
+
+// openFile sets w.xfile. If the writer is updating an existing
+// workbook, that workbook is opened; otherwise a new workbook is
+// created.
+func (w *recordWriter) openFile() error {
+	var err error
+	switch w.pr.ExcelMode {
+	case "", ModeNew:
+	default:
+		if w.pr.ExcelFile == "" {
+			return errz.Errorf("excel: mode {%s} requires --output", w.pr.ExcelMode)
+		}
+
+		w.xfile, err = excelize.OpenFile(w.pr.ExcelFile)
+		switch {
+		case err == nil:
+			return nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return errz.Wrapf(err, "excel: failed to open workbook: %s", w.pr.ExcelFile)
+		}
+	}
+
 	if w.xfile, err = NewFile(); err != nil {
 		return err
 	}
+	w.placeholder = SheetName
+	return nil
+}
 
-	if err = w.initStyles(); err != nil {
-		return err
+// sheetName returns the name of the sheet for the current result set.
+func (w *recordWriter) sheetName() string {
+	if w.resultSets < len(w.pr.ExcelSheets) && w.pr.ExcelSheets[w.resultSets] != "" {
+		return w.pr.ExcelSheets[w.resultSets]
 	}
 
-	if w.header {
-		w.nextRow++
-		for i, colName := range w.recMeta.MungedNames() {
-			cell := cellName(i, 0)
-			if err := w.xfile.SetCellStr(SheetName, cell, colName); err != nil {
-				return errw(err)
+	if w.resultSets == 0 {
+		return SheetName
+	}
+
+	// Each subsequent result set gets its own sheet: "data2",
+	// "data3", etc.
+	return SheetName + strconv.Itoa(w.resultSets+1)
+}
+
+// newSheet creates the named sheet, and writes its header row. An
+// existing sheet of the same name in the workbook is an error,
+// unless the writer is in replace mode, in which case the new sheet
+// takes the place of the old.
+func (w *recordWriter) newSheet(name string) (*sheet, error) {
+	key := strings.ToLower(name)
+	if _, ok := w.sheets[key]; ok {
+		return nil, errz.Errorf("excel: duplicate sheet name {%s}", name)
+	}
+
+	switch {
+	case w.placeholder != "":
+		if err := w.xfile.SetSheetName(w.placeholder, name); err != nil {
+			return nil, errw(err)
+		}
+		w.placeholder = ""
+	default:
+		idx, err := w.xfile.GetSheetIndex(name)
+		if err != nil {
+			return nil, errw(err)
+		}
+
+		if idx == -1 {
+			if _, err = w.xfile.NewSheet(name); err != nil {
+				return nil, errw(err)
 			}
+			break
+		}
 
-			if err := w.xfile.SetCellStyle(SheetName, cell, cell, w.headerStyle); err != nil {
+		if w.pr.ExcelMode != ModeReplace {
+			return nil, errz.Errorf("excel: sheet {%s} already exists in workbook: %s", name, w.pr.ExcelFile)
+		}
+
+		// Write a temporary sheet, positioned where the old sheet is.
+		// Then delete the old sheet, and take its name.
+		const tmpName = "sq_replace_tmp"
+		if _, err = w.xfile.NewSheet(tmpName); err != nil {
+			return nil, errw(err)
+		}
+		if err = w.xfile.MoveSheet(tmpName, name); err != nil {
+			return nil, errw(err)
+		}
+		if err = w.xfile.DeleteSheet(name); err != nil {
+			return nil, errw(err)
+		}
+		if err = w.xfile.SetSheetName(tmpName, name); err != nil {
+			return nil, errw(err)
+		}
+	}
+
+	sh := &sheet{name: name}
+	if w.pr.ExcelColWidth == ColWidthAuto {
+		sh.widths = make([]int, len(w.recMeta))
+	}
+	w.sheets[key] = sh
+	w.rsSheets = append(w.rsSheets, sh)
+
+	if err := w.writeHeader(sh); err != nil {
+		return nil, err
+	}
+
+	if w.pr.ExcelColWidth != "" && w.pr.ExcelColWidth != ColWidthKind {
+		return sh, nil
+	}
+
+	for i, field := range w.recMeta {
+		if wantWidth := kindColWidth(field.Kind()); wantWidth != -1 {
+			if err := w.setColWidth(sh, i, wantWidth); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return sh, nil
+}
+
+// writeHeader writes the header row of sh, if the header is enabled.
+func (w *recordWriter) writeHeader(sh *sheet) error {
+	if !w.header {
+		return nil
+	}
+
+	sh.nextRow++
+	for i, colName := range w.recMeta.MungedNames() {
+		cell := cellName(i, 0)
+		if err := w.xfile.SetCellStr(sh.name, cell, colName); err != nil {
+			return errw(err)
+		}
+
+		if w.headerStyle != 0 {
+			if err := w.xfile.SetCellStyle(sh.name, cell, cell, w.headerStyle); err != nil {
 				return errw(err)
 			}
 		}
+
+		if sh.widths != nil {
+			sh.widths[i] = utf8.RuneCountInString(colName)
+		}
 	}
 
-	for i, field := range recMeta {
-		wantWidth := -1
-		switch field.Kind() { //nolint:exhaustive
-		case kind.Datetime:
-			// TODO: These widths could be configurable?
-			wantWidth = 20
-		case kind.Date:
-			wantWidth = 12
-		case kind.Time:
-			wantWidth = 16
-		case kind.Text:
-			wantWidth = 32
-		case kind.Decimal:
-			wantWidth = 20
-		default:
+	if w.pr.ExcelFreezeHeader {
+		if err := w.xfile.SetPanes(sh.name, &excelize.Panes{
+			Freeze:      true,
+			YSplit:      1,
+			TopLeftCell: "A2",
+			ActivePane:  "bottomLeft",
+		}); err != nil {
+			return errw(err)
+		}
+	}
+
+	return nil
+}
+
+// finishSheets applies the settings that depend upon the content of
+// the current result set's sheets, i.e. autofilter and auto col width.
+func (w *recordWriter) finishSheets() error {
+	for _, sh := range w.rsSheets {
+		if w.header && w.pr.ExcelAutoFilter && len(w.recMeta) > 0 {
+			ref := cellName(0, 0) + ":" + cellName(len(w.recMeta)-1, sh.nextRow-1)
+			if err := w.xfile.AutoFilter(sh.name, ref, nil); err != nil {
+				return errw(err)
+			}
 		}
 
-		if wantWidth != -1 {
-			if err := w.setColWidth(i, wantWidth); err != nil {
+		for i, width := range sh.widths {
+			const minWidth, maxWidth = 6, 80
+			if err := w.setColWidth(sh, i, min(max(width+2, minWidth), maxWidth)); err != nil {
 				return err
 			}
 		}
 	}
 
+	w.rsSheets = nil
 	return nil
 }
 
+// kindColWidth returns the preset col width for kind k, or -1
+// if there's no preset for k.
+func kindColWidth(k kind.Kind) int {
+	switch k { //nolint:exhaustive
+	case kind.Datetime:
+		return 20
+	case kind.Date:
+		return 12
+	case kind.Time:
+		return 16
+	case kind.Text:
+		return 32
+	case kind.Decimal:
+		return 20
+	default:
+		return -1
+	}
+}
+
 // setColWidth takes the zero-indexed col, and sets its width.
-func (w *recordWriter) setColWidth(col, width int) error {
+func (w *recordWriter) setColWidth(sh *sheet, col, width int) error {
 	colName, err := excelize.ColumnNumberToName(col + 1)
 	if err != nil {
 		return errw(err)
 	}
-	return errw(w.xfile.SetColWidth(SheetName, colName, colName, float64(width)))
+	return errw(w.xfile.SetColWidth(sh.name, colName, colName, float64(width)))
 }
 
 // Flush implements output.RecordWriter.
@@ -227,15 +464,41 @@ func (w *recordWriter) Close(context.Context) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	err := w.xfile.Write(w.out)
-	if err != nil {
+	if err := w.finishSheets(); err != nil {
+		return err
+	}
+
+	if w.pr.ExcelFile == "" {
+		err := w.xfile.Write(w.out)
+		if err != nil {
+			return errz.Wrap(err, "excel: unable to write XLSX")
+		}
+		return nil
+	}
+
+	// We're updating an existing workbook. Only overwrite the file once
+	// the whole workbook has been rendered, so that a failure doesn't
+	// leave a truncated file.
+	defer func() { _ = w.xfile.Close() }()
+	buf := &bytes.Buffer{}
+	if err := w.xfile.Write(buf); err != nil {
 		return errz.Wrap(err, "excel: unable to write XLSX")
 	}
-	return nil
+
+	return errz.Wrap(ioz.WriteFileAtomic(w.pr.ExcelFile, buf.Bytes(), 0o644),
+		"excel: unable to write XLSX")
+}
+
+// EndResultSet implements output.ResultSetWriter.
+func (w *recordWriter) EndResultSet(context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resultSets++
+	return w.finishSheets()
 }
 
 // WriteRecords implements output.RecordWriter.
-func (w *recordWriter) WriteRecords(ctx context.Context, recs []record.Record) error { //nolint:gocognit
+func (w *recordWriter) WriteRecords(ctx context.Context, recs []record.Record) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
@@ -245,141 +508,275 @@ func (w *recordWriter) WriteRecords(ctx context.Context, recs []record.Record) e
 			return ctx.Err()
 		default:
 		}
-		rowi := w.nextRow
 
-		for j, val := range rec {
-			cellIndex := cellName(j, rowi)
-
-			switch val := val.(type) {
-			case nil:
-				// Do nothing for nil
-			case []byte:
-				if len(val) != 0 {
-					b64 := base64.StdEncoding.EncodeToString(val)
-					if err := w.xfile.SetCellValue(SheetName, cellIndex, b64); err != nil {
+		sh := w.sheet
+		if w.splitCol != -1 {
+			var err error
+			if sh, err = w.splitSheet(rec[w.splitCol]); err != nil {
+				return err
+			}
+		}
+
+		if err := w.writeRecord(sh, rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitSheet returns the sheet for split column value val,
+// creating the sheet if necessary.
+func (w *recordWriter) splitSheet(val any) (*sheet, error) {
+	key := fmt.Sprintf("%T:%v", val, val)
+	for _, sh := range w.rsSheets {
+		if sh.splitKey == key {
+			return sh, nil
+		}
+	}
+
+	// Distinct values can result in the same sheet name, e.g. when
+	// they share their first 31 chars, or differ only by case. Such
+	// a name is made unique by a suffix: "name~2", "name~3", etc.
+	base := splitSheetName(val)
+	name := base
+	for i := 2; w.rsSheetExists(name); i++ {
+		suffix := "~" + strconv.Itoa(i)
+		name = truncSheetName(base, maxSheetNameLen-len(suffix)) + suffix
+	}
+
+	sh, err := w.newSheet(name)
+	if err != nil {
+		return nil, err
+	}
+	sh.splitKey = key
+	return sh, nil
+}
+
+// rsSheetExists returns true if the current result set has a sheet
+// with the given name.
+func (w *recordWriter) rsSheetExists(name string) bool {
+	for _, sh := range w.rsSheets {
+		if strings.EqualFold(sh.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRecord writes rec to the next row of sh.
+func (w *recordWriter) writeRecord(sh *sheet, rec record.Record) error { //nolint:gocognit
+	rowi := sh.nextRow
+
+	for j, val := range rec {
+		cellIndex := cellName(j, rowi)
+
+		switch val := val.(type) {
+		case nil:
+			// Do nothing for nil
+		case []byte:
+			if len(val) != 0 {
+				b64 := base64.StdEncoding.EncodeToString(val)
+				if err := w.xfile.SetCellValue(sh.name, cellIndex, b64); err != nil {
+					return errw(err)
+				}
+			}
+
+		case string:
+			// It seems that kind.Time values are supplied as string (at least
+			// by some backend database drivers). However, Excel won't honor the
+			// time format style unless the cell value is set as a float.
+			if w.recMeta[j].Kind() == kind.Time {
+				if timeFloat, err := timeOnlyStringToExcelFloat(val); err == nil {
+					if err = w.xfile.SetCellStyle(sh.name, cellIndex, cellIndex, w.timeStyle); err != nil {
 						return errw(err)
 					}
-				}
 
-			case string:
-				// It seems that kind.Time values are supplied as string (at least
-				// by some backend database drivers). However, Excel won't honor the
-				// time format style unless the cell value is set as a float.
-				if w.recMeta[j].Kind() == kind.Time {
-					if timeFloat, err := timeOnlyStringToExcelFloat(val); err == nil {
-						if err = w.xfile.SetCellStyle(SheetName, cellIndex, cellIndex, w.timeStyle); err != nil {
-							return errw(err)
-						}
-
-						if err = w.xfile.SetCellValue(SheetName, cellIndex, timeFloat); err != nil {
-							return errw(err)
-						}
-
-						break
+					if err = w.xfile.SetCellValue(sh.name, cellIndex, timeFloat); err != nil {
+						return errw(err)
 					}
 
-					// If there's an error, just continue below, using a plain ol' string.
+					break
 				}
 
-				if err := w.xfile.SetCellStr(SheetName, cellIndex, val); err != nil {
+				// If there's an error, just continue below, using a plain ol' string.
+			}
+
+			if err := w.xfile.SetCellStr(sh.name, cellIndex, val); err != nil {
+				return errw(err)
+			}
+		case bool:
+			if err := w.xfile.SetCellBool(sh.name, cellIndex, val); err != nil {
+				return errw(err)
+			}
+		case int64:
+			if err := w.xfile.SetCellInt(sh.name, cellIndex, val); err != nil {
+				return errw(err)
+			}
+		case float64:
+			if err := w.xfile.SetCellFloat(sh.name, cellIndex, val, -1, 64); err != nil {
+				return errw(err)
+			}
+		case decimal.Decimal:
+			styleID, err := w.getDecimalStyle(val)
+			if err != nil {
+				return err
+			}
+
+			if err = w.xfile.SetCellStyle(sh.name, cellIndex, cellIndex, styleID); err != nil {
+				return errw(err)
+			}
+
+			if stringz.DecimalFloatOK(val) {
+				if err = w.xfile.SetCellFloat(sh.name, cellIndex, val.InexactFloat64(), -1, 64); err != nil {
 					return errw(err)
 				}
-			case bool:
-				if err := w.xfile.SetCellBool(SheetName, cellIndex, val); err != nil {
+			} else {
+				// The decimal can't be stored as a float without losing precision.
+				// We need to use a string instead.
+				if err = w.xfile.SetCellStr(sh.name, cellIndex, val.String()); err != nil {
 					return errw(err)
 				}
-			case int64:
-				if err := w.xfile.SetCellInt(SheetName, cellIndex, val); err != nil {
+			}
+
+		case time.Time:
+			switch w.recMeta[j].Kind() { //nolint:exhaustive
+			default:
+				// Shouldn't happen
+				if err := w.xfile.SetCellValue(sh.name, cellIndex, val); err != nil {
 					return errw(err)
 				}
-			case float64:
-				if err := w.xfile.SetCellFloat(SheetName, cellIndex, val, -1, 64); err != nil {
+
+			case kind.Datetime:
+				if err := w.xfile.SetCellStyle(sh.name, cellIndex, cellIndex, w.datetimeStyle); err != nil {
 					return errw(err)
 				}
-			case decimal.Decimal:
-				styleID, err := w.getDecimalStyle(val)
-				if err != nil {
-					return err
-				}
 
-				if err = w.xfile.SetCellStyle(SheetName, cellIndex, cellIndex, styleID); err != nil {
+				if err := w.xfile.SetCellValue(sh.name, cellIndex, val); err != nil {
 					return errw(err)
 				}
-
-				if stringz.DecimalFloatOK(val) {
-					if err = w.xfile.SetCellFloat(SheetName, cellIndex, val.InexactFloat64(), -1, 64); err != nil {
-						return errw(err)
-					}
-				} else {
-					// The decimal can't be stored as a float without losing precision.
-					// We need to use a string instead.
-					if err = w.xfile.SetCellStr(SheetName, cellIndex, val.String()); err != nil {
-						return errw(err)
-					}
+			case kind.Date:
+				if err := w.xfile.SetCellStyle(sh.name, cellIndex, cellIndex, w.dateStyle); err != nil {
+					return errw(err)
 				}
 
-			case time.Time:
-				switch w.recMeta[j].Kind() { //nolint:exhaustive
-				default:
-					// Shouldn't happen
-					if err := w.xfile.SetCellValue(SheetName, cellIndex, val); err != nil {
-						return errw(err)
-					}
-
-				case kind.Datetime:
-					if err := w.xfile.SetCellStyle(SheetName, cellIndex, cellIndex, w.datetimeStyle); err != nil {
-						return errw(err)
-					}
-
-					if err := w.xfile.SetCellValue(SheetName, cellIndex, val); err != nil {
-						return errw(err)
-					}
-				case kind.Date:
-					if err := w.xfile.SetCellStyle(SheetName, cellIndex, cellIndex, w.dateStyle); err != nil {
-						return errw(err)
-					}
+				if err := w.xfile.SetCellValue(sh.name, cellIndex, val); err != nil {
+					return errw(err)
+				}
 
-					if err := w.xfile.SetCellValue(SheetName, cellIndex, val); err != nil {
-						return errw(err)
-					}
+			case kind.Time:
+				if err := w.xfile.SetCellStyle(sh.name, cellIndex, cellIndex, w.timeStyle); err != nil {
+					return errw(err)
+				}
 
-				case kind.Time:
-					if err := w.xfile.SetCellStyle(SheetName, cellIndex, cellIndex, w.timeStyle); err != nil {
+				// Excel prefers that time-only values be represented as float, so
+				// we try that first.
+				if timeFloat, err := timeOnlyToExcelFloat(val); err == nil {
+					if err = w.xfile.SetCellValue(sh.name, cellIndex, timeFloat); err != nil {
 						return errw(err)
 					}
 
-					// Excel prefers that time-only values be represented as float, so
-					// we try that first.
-					if timeFloat, err := timeOnlyToExcelFloat(val); err == nil {
-						if err = w.xfile.SetCellValue(SheetName, cellIndex, timeFloat); err != nil {
-							return errw(err)
-						}
-
-						// Success, we can break out of the switch.
-						break
-					}
-
-					// No success with the float approach. Just default to setting
-					// the time.Time value, and let Excel figure it out.
-					if err := w.xfile.SetCellValue(SheetName, cellIndex, val); err != nil {
-						return errw(err)
-					}
+					// Success, we can break out of the switch.
+					break
 				}
-			default:
-				// should never happen
-				s := fmt.Sprintf("%v", val)
-				if err := w.xfile.SetCellStr(SheetName, cellIndex, s); err != nil {
+
+				// No success with the float approach. Just default to setting
+				// the time.Time value, and let Excel figure it out.
+				if err := w.xfile.SetCellValue(sh.name, cellIndex, val); err != nil {
 					return errw(err)
 				}
 			}
+		default:
+			// should never happen
+			s := fmt.Sprintf("%v", val)
+			if err := w.xfile.SetCellStr(sh.name, cellIndex, s); err != nil {
+				return errw(err)
+			}
 		}
+	}
 
-		w.nextRow++
+	if sh.widths != nil {
+		for j, val := range rec {
+			sh.widths[j] = max(sh.widths[j], w.cellWidth(j, val))
+		}
 	}
 
+	sh.nextRow++
 	return nil
 }
 
+// cellWidth returns the approximate display width of val, which
+// is a value of column col.
+func (w *recordWriter) cellWidth(col int, val any) int {
+	switch val := val.(type) {
+	case nil:
+		return 0
+	case string:
+		return utf8.RuneCountInString(val)
+	case []byte:
+		return base64.StdEncoding.EncodedLen(len(val))
+	case bool:
+		return len(strconv.FormatBool(val))
+	case int64:
+		return len(strconv.FormatInt(val, 10))
+	case float64:
+		return len(strconv.FormatFloat(val, 'f', -1, 64))
+	case decimal.Decimal:
+		return len(stringz.FormatDecimal(val))
+	case time.Time:
+		return max(kindColWidth(w.recMeta[col].Kind()), 0)
+	default:
+		return utf8.RuneCountInString(fmt.Sprintf("%v", val))
+	}
+}
+
+// maxSheetNameLen is the maximum length, in chars, of a sheet name.
+const maxSheetNameLen = 31
+
+// truncSheetName returns s truncated to at most n chars.
+func truncSheetName(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// splitSheetName returns a valid sheet name for split column value val.
+func splitSheetName(val any) string {
+	var s string
+	switch val := val.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		s = val
+	case decimal.Decimal:
+		s = stringz.FormatDecimal(val)
+	case time.Time:
+		s = val.Format(time.DateOnly)
+		if val.Hour() != 0 || val.Minute() != 0 || val.Second() != 0 {
+			s = val.Format("2006-01-02 15.04.05")
+		}
+	default:
+		s = fmt.Sprintf("%v", val)
+	}
+
+	// Excel disallows these chars in sheet names, and also
+	// disallows a leading or trailing apostrophe.
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	s = strings.Trim(s, "'")
+	s = truncSheetName(s, maxSheetNameLen)
+
+	if strings.TrimSpace(s) == "" {
+		return "_"
+	}
+	return s
+}
+
 const SheetName = "data"
 
 // NewFile returns a new file with a single, empty sheet named "data".
@@ -211,3 +211,104 @@ func TestOptDatetimeFormats(t *testing.T) {
 	assert.Equal(t, "89/Nov/09", gotDate)
 	assert.Equal(t, "4:07 pm", gotTime)
 }
+
+// TestSheets tests writing result sets to named sheets, splitting
+// a result set across sheets, and updating an existing workbook.
+func TestSheets(t *testing.T) {
+	ctx := context.Background()
+	csvPath := tu.WriteTemp(t, "*.csv", []byte("id,dept,name\n1,eng,alice\n2,ops,bob\n3,eng,carol\n"), true)
+	xlsxPath := filepath.Join(t.TempDir(), "report.xlsx")
+
+	tr := testrun.New(ctx, t, nil).Hush()
+	require.NoError(t, tr.Exec("add", csvPath, "--handle", "@people"))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("@people.data", "--xlsx", "--output", xlsxPath,
+		"--"+xlsxw.OptSplitBy.Key(), "dept",
+		"--"+xlsxw.OptHeaderStyle.Key(), xlsxw.HeaderStyleShaded,
+		"--"+xlsxw.OptAutoFilter.Key(),
+		"--"+xlsxw.OptFreezeHeader.Key(),
+	))
+
+	xl, err := excelize.OpenFile(xlsxPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eng", "ops"}, xl.GetSheetList())
+	rows, err := xl.GetRows("eng")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept", "name"}, {"1", "eng", "alice"}, {"3", "eng", "carol"}}, rows)
+	panes, err := xl.GetPanes("ops")
+	require.NoError(t, err)
+	require.True(t, panes.Freeze)
+	require.NoError(t, xl.Close())
+
+	// Append adds a sheet, leaving the existing sheets intact.
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("@people.data | .[0:1]", "--xlsx", "--output", xlsxPath,
+		"--"+xlsxw.OptMode.Key(), xlsxw.ModeAppend,
+		"--"+xlsxw.OptSheets.Key(), "first",
+	))
+
+	// But append can't overwrite an existing sheet.
+	tr = testrun.New(ctx, t, tr)
+	require.Error(t, tr.Exec("@people.data", "--xlsx", "--output", xlsxPath,
+		"--"+xlsxw.OptMode.Key(), xlsxw.ModeAppend,
+		"--"+xlsxw.OptSheets.Key(), "ops",
+	))
+
+	// Whereas replace can, and the sheet keeps its position.
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("@people.data | .[1:3]", "--xlsx", "--output", xlsxPath,
+		"--"+xlsxw.OptMode.Key(), xlsxw.ModeReplace,
+		"--"+xlsxw.OptSheets.Key(), "eng",
+	))
+
+	xl, err = excelize.OpenFile(xlsxPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = xl.Close() })
+	require.Equal(t, []string{"eng", "ops", "first"}, xl.GetSheetList())
+	rows, err = xl.GetRows("eng")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept", "name"}, {"2", "ops", "bob"}, {"3", "eng", "carol"}}, rows)
+	rows, err = xl.GetRows("first")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept", "name"}, {"1", "eng", "alice"}}, rows)
+}
+
+// TestSheets_splitNameCollision verifies that distinct split values whose
+// sheet names collide, such as values that share their first 31 chars,
+// are written to distinct sheets.
+func TestSheets_splitNameCollision(t *testing.T) {
+	const (
+		prefix = "department_of_really_long_names"
+		deptA  = prefix + "_alpha"
+		deptB  = prefix + "_beta"
+	)
+
+	ctx := context.Background()
+	csvPath := tu.WriteTemp(t, "*.csv",
+		[]byte("id,dept\n1,"+deptA+"\n2,"+deptB+"\n3,"+deptA+"\n4,ops\n5,OPS\n"), true)
+	xlsxPath := filepath.Join(t.TempDir(), "report.xlsx")
+
+	tr := testrun.New(ctx, t, nil).Hush()
+	require.NoError(t, tr.Exec("add", csvPath, "--handle", "@depts"))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("@depts.data", "--xlsx", "--output", xlsxPath,
+		"--"+xlsxw.OptSplitBy.Key(), "dept",
+	))
+
+	xl, err := excelize.OpenFile(xlsxPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = xl.Close() })
+	require.Equal(t, []string{prefix, prefix[:29] + "~2", "ops", "OPS~2"}, xl.GetSheetList())
+
+	rows, err := xl.GetRows(prefix)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept"}, {"1", deptA}, {"3", deptA}}, rows)
+	rows, err = xl.GetRows(prefix[:29] + "~2")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept"}, {"2", deptB}}, rows)
+	rows, err = xl.GetRows("OPS~2")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"id", "dept"}, {"5", "OPS"}}, rows)
+}
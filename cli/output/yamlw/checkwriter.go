@@ -0,0 +1,31 @@
+package yamlw
+
+import (
+	"io"
+
+	"github.com/goccy/go-yaml/printer"
+
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.CheckWriter = (*checkWriter)(nil)
+
+// checkWriter implements output.CheckWriter for YAML.
+type checkWriter struct {
+	p   printer.Printer
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewCheckWriter returns a YAML output.CheckWriter.
+func NewCheckWriter(out io.Writer, pr *output.Printing) output.CheckWriter {
+	return &checkWriter{out: out, pr: pr, p: newPrinter(pr)}
+}
+
+// Results implements output.CheckWriter.
+func (w *checkWriter) Results(results []output.CheckResult) error {
+	if results == nil {
+		results = []output.CheckResult{}
+	}
+	return writeYAML(w.out, w.p, results)
+}
@@ -0,0 +1,28 @@
+package yamlw
+
+import (
+	"io"
+
+	"github.com/goccy/go-yaml/printer"
+
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.PlanWriter = (*planWriter)(nil)
+
+// planWriter implements output.PlanWriter for YAML.
+type planWriter struct {
+	p   printer.Printer
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewPlanWriter returns a YAML output.PlanWriter.
+func NewPlanWriter(out io.Writer, pr *output.Printing) output.PlanWriter {
+	return &planWriter{out: out, pr: pr, p: newPrinter(pr)}
+}
+
+// Plan implements output.PlanWriter.
+func (w *planWriter) Plan(plan output.QueryPlan) error {
+	return writeYAML(w.out, w.p, plan)
+}
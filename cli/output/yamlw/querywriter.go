@@ -0,0 +1,37 @@
+package yamlw
+
+import (
+	"io"
+
+	"github.com/goccy/go-yaml/printer"
+
+	"github.com/neilotoole/sq/cli/config"
+	"github.com/neilotoole/sq/cli/output"
+)
+
+var _ output.QueryWriter = (*queryWriter)(nil)
+
+// queryWriter implements output.QueryWriter for YAML.
+type queryWriter struct {
+	p   printer.Printer
+	out io.Writer
+	pr  *output.Printing
+}
+
+// NewQueryWriter returns a YAML output.QueryWriter.
+func NewQueryWriter(out io.Writer, pr *output.Printing) output.QueryWriter {
+	return &queryWriter{out: out, pr: pr, p: newPrinter(pr)}
+}
+
+// List implements output.QueryWriter.
+func (w *queryWriter) List(queries []*config.Query) error {
+	if queries == nil {
+		queries = []*config.Query{}
+	}
+	return writeYAML(w.out, w.p, queries)
+}
+
+// Query implements output.QueryWriter.
+func (w *queryWriter) Query(q *config.Query) error {
+	return writeYAML(w.out, w.p, q)
+}
@@ -20,7 +20,10 @@ import (
 	"github.com/neilotoole/sq/libsq/core/record"
 )
 
-var _ output.NewRecordWriterFunc = NewRecordWriter
+var (
+	_ output.NewRecordWriterFunc = NewRecordWriter
+	_ output.ResultSetWriter     = (*recordWriter)(nil)
+)
 
 // NewRecordWriter returns an output.RecordWriter that writes YAML.
 func NewRecordWriter(out io.Writer, pr *output.Printing) output.RecordWriter {
@@ -42,6 +45,9 @@ type recordWriter struct {
 	byValue    []bool
 	keys       []string
 	mu         sync.Mutex
+
+	// resultSets is the count of result sets ended via EndResultSet.
+	resultSets int
 }
 
 // Open implements output.RecordWriter.
@@ -55,6 +61,11 @@ func (w *recordWriter) Open(_ context.Context, recMeta record.Meta) error {
 	w.keys = make([]string, len(w.recMeta))
 	w.null = w.pr.Null.Sprint("null")
 
+	if w.resultSets > 0 {
+		// Each result set is a document of a YAML document stream.
+		w.buf.WriteString("---\n")
+	}
+
 	var (
 		node ast.Node
 		err  error
@@ -231,6 +242,15 @@ func (w *recordWriter) Close(ctx context.Context) error {
 	return w.Flush(ctx)
 }
 
+// EndResultSet implements output.ResultSetWriter.
+func (w *recordWriter) EndResultSet(ctx context.Context) error {
+	if err := w.Flush(ctx); err != nil {
+		return err
+	}
+	w.resultSets++
+	return nil
+}
+
 // renderTime renders the *time.Time val into a fully-rendered string
 // ready for writing out.
 func (w *recordWriter) renderTime(fieldMeta *record.FieldMeta, val any) (string, error) {
@@ -142,3 +142,11 @@ func (w *sourceWriter) Groups(tree *source.Group) error {
 	}
 	return writeYAML(w.out, w.p, tree)
 }
+
+// Imported implements output.SourceWriter.
+func (w *sourceWriter) Imported(rows []output.SourceImportRow) error {
+	if rows == nil {
+		rows = []output.SourceImportRow{}
+	}
+	return writeYAML(w.out, w.p, rows)
+}
@@ -15,12 +15,16 @@ import (
 	v0_34_0 "github.com/neilotoole/sq/cli/config/yamlstore/upgrades/v0.34.0" //nolint:revive
 	v0_54_0 "github.com/neilotoole/sq/cli/config/yamlstore/upgrades/v0.54.0" //nolint:revive
 	"github.com/neilotoole/sq/cli/flag"
+	"github.com/neilotoole/sq/cli/output/format"
+	"github.com/neilotoole/sq/cli/output/xlsxw"
 	"github.com/neilotoole/sq/cli/run"
 	"github.com/neilotoole/sq/drivers/clickhouse"
 	"github.com/neilotoole/sq/drivers/csv"
 	"github.com/neilotoole/sq/drivers/duckdb"
 	"github.com/neilotoole/sq/drivers/json"
+	"github.com/neilotoole/sq/drivers/mongodb"
 	"github.com/neilotoole/sq/drivers/mysql"
+	"github.com/neilotoole/sq/drivers/odbc"
 	"github.com/neilotoole/sq/drivers/oracle"
 	"github.com/neilotoole/sq/drivers/postgres"
 	"github.com/neilotoole/sq/drivers/rqlite"
@@ -43,6 +47,8 @@ import (
 	"github.com/neilotoole/sq/libsq/core/secret/file"
 	"github.com/neilotoole/sq/libsq/core/secret/keyring"
 	"github.com/neilotoole/sq/libsq/core/secret/op"
+	"github.com/neilotoole/sq/libsq/core/secret/sops"
+	"github.com/neilotoole/sq/libsq/core/secret/vault"
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/files"
 	"github.com/neilotoole/sq/libsq/source"
@@ -172,11 +178,19 @@ func preRun(cmd *cobra.Command, ru *run.Run) error {
 		ru.Stdin = f
 	}
 
+	cmdOpts, err := getOptionsFromCmd(ru.Cmd)
+	if err != nil {
+		return err
+	}
+
 	// If the --output=/some/file flag is set, then we need to
 	// override ru.Stdout (which is typically stdout) to point it at
 	// the output destination file.
 	//
-
+	// The exception is XLSX output that updates an existing workbook
+	// (format.excel.mode "append" or "replace"): the file mustn't be
+	// truncated, so instead its path is handed to the XLSX writer.
+	var excelFile string
 	if cmdFlagChanged(ru.Cmd, flag.FileOutput) && !cmdRequiresPlainStdout(ru.Cmd) {
 		fpath, _ := ru.Cmd.Flags().GetString(flag.FileOutput)
 		fpath, err := filepath.Abs(fpath)
@@ -190,18 +204,18 @@ func preRun(cmd *cobra.Command, ru *run.Run) error {
 			return errz.Wrapf(err, "failed to make parent dir for --%s", flag.FileOutput)
 		}
 
-		f, err := os.Create(fpath)
-		if err != nil {
-			return errz.Wrapf(err, "failed to open file specified by flag --%s", flag.FileOutput)
-		}
-
-		ru.Cleanup.AddC(f) // Make sure the file gets closed eventually
-		ru.Stdout = f
-	}
+		if getFormat(ru.Cmd, cmdOpts) == format.XLSX && xlsxw.OptMode.Get(cmdOpts) != xlsxw.ModeNew {
+			excelFile = fpath
+			ru.Stdout = io.Discard
+		} else {
+			f, err := os.Create(fpath)
+			if err != nil {
+				return errz.Wrapf(err, "failed to open file specified by flag --%s", flag.FileOutput)
+			}
 
-	cmdOpts, err := getOptionsFromCmd(ru.Cmd)
-	if err != nil {
-		return err
+			ru.Cleanup.AddC(f) // Make sure the file gets closed eventually
+			ru.Stdout = f
+		}
 	}
 
 	// --no-redact is deprecated in favor of --reveal (see #717). Warn
@@ -224,6 +238,8 @@ func preRun(cmd *cobra.Command, ru *run.Run) error {
 	ru.SecretRegistry.Register("env", env.NewResolver())
 	ru.SecretRegistry.Register("file", file.NewResolver())
 	ru.SecretRegistry.Register("op", op.NewResolver())
+	ru.SecretRegistry.Register("sops", sops.NewResolver())
+	ru.SecretRegistry.Register("vault", vault.NewResolver())
 
 	if err = FinishRunInit(ctx, ru); err != nil {
 		return err
@@ -240,6 +256,7 @@ func preRun(cmd *cobra.Command, ru *run.Run) error {
 	// Markdown/HTML schema documents can render a "generated by sq" line.
 	if outCfg.outPr != nil {
 		outCfg.outPr.GeneratedAt = time.Now().UTC()
+		outCfg.outPr.ExcelFile = excelFile
 	}
 
 	if cmdRequiresConfigLock(cmd) {
@@ -312,6 +329,7 @@ func FinishRunInit(ctx context.Context, ru *run.Run) error {
 	// because databases could depend upon the existence of
 	// files (such as a sqlite db file).
 	ru.Cleanup.AddE(ru.Files.Close)
+	ru.Files.SetSecretRegistry(ru.SecretRegistry)
 
 	ru.DriverRegistry = driver.NewRegistry(log)
 	dr := ru.DriverRegistry
@@ -329,15 +347,19 @@ func FinishRunInit(ctx context.Context, ru *run.Run) error {
 	dr.AddProvider(drivertype.MySQL, &mysql.Provider{Log: log})
 	dr.AddProvider(drivertype.ClickHouse, &clickhouse.Provider{Log: log})
 	dr.AddProvider(drivertype.Oracle, &oracle.Provider{Log: log})
+	dr.AddProvider(drivertype.MongoDB, &mongodb.Provider{Log: log, Ingester: ru.Grips})
+	dr.AddProvider(drivertype.ODBC, &odbc.Provider{Log: log})
 	csvp := &csv.Provider{Log: log, Ingester: ru.Grips, Files: ru.Files}
 	dr.AddProvider(drivertype.CSV, csvp)
 	dr.AddProvider(drivertype.TSV, csvp)
+	dr.AddProvider(drivertype.FixedWidth, csvp)
 	ru.Files.AddDriverDetectors(csv.DetectCSV, csv.DetectTSV)
 
 	jsonp := &json.Provider{Log: log, Ingester: ru.Grips, Files: ru.Files}
 	dr.AddProvider(drivertype.JSON, jsonp)
 	dr.AddProvider(drivertype.JSONA, jsonp)
 	dr.AddProvider(drivertype.JSONL, jsonp)
+	dr.AddProvider(drivertype.REST, jsonp)
 	sampleSize := driver.OptIngestSampleSize.Get(cfg.Options)
 	ru.Files.AddDriverDetectors(
 		json.DetectJSON(sampleSize),
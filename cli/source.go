@@ -318,19 +318,12 @@ func processFlagActiveSchema(cmd *cobra.Command, activeSrc *source.Source) (modi
 	return modified, nil
 }
 
-// checkStdinSource checks if there's stdin data (on pipe/redirect). If there
-// is, that pipe is inspected, and if it has recognizable input, a new source
-// instance with handle @stdin is constructed and returned. If the pipe has no
-// data (size is zero), then (nil,nil) is returned.
-//
-// There's special handling for a SQLite db on stdin: the input is copied to
-// a temp file, and a Source returned with handle @stdin, but with the location
-// set to the temp file path.
-func checkStdinSource(ctx context.Context, ru *run.Run) (*source.Source, error) {
-	f := ru.Stdin
+// stdinHasData returns true if there's input on stdin f, via pipe or
+// redirect.
+func stdinHasData(ctx context.Context, f *os.File) (bool, error) {
 	fi, err := f.Stat()
 	if err != nil {
-		return nil, errz.Wrap(err, "failed to get stat on stdin")
+		return false, errz.Wrap(err, "failed to get stat on stdin")
 	}
 
 	mode := fi.Mode()
@@ -342,7 +335,24 @@ func checkStdinSource(ctx context.Context, ru *run.Run) (*source.Source, error)
 		log.Info("Detected stdin redirect via size > 0")
 	default:
 		log.Info("No stdin data detected")
-		return nil, nil //nolint:nilnil
+		return false, nil
+	}
+	return true, nil
+}
+
+// checkStdinSource checks if there's stdin data (on pipe/redirect). If there
+// is, that pipe is inspected, and if it has recognizable input, a new source
+// instance with handle @stdin is constructed and returned. If the pipe has no
+// data (size is zero), then (nil,nil) is returned.
+//
+// There's special handling for a SQLite db on stdin: the input is copied to
+// a temp file, and a Source returned with handle @stdin, but with the location
+// set to the temp file path.
+func checkStdinSource(ctx context.Context, ru *run.Run) (*source.Source, error) {
+	f := ru.Stdin
+	ok, err := stdinHasData(ctx, f)
+	if err != nil || !ok {
+		return nil, err
 	}
 
 	// If we got this far, we have input from pipe or redirect.
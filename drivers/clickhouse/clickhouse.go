@@ -235,6 +235,15 @@ func (d *driveri) Dialect() dialect.Dialect {
 		Ops:                       dialect.DefaultOps(),
 		Joins:                     jointype.All(),
 		IsRowsAffectedUnsupported: true,
+		// LENGTH returns the length in bytes; uniq is HyperLogLog-based.
+		Profile: dialect.Profile{
+			CharLength:          "lengthUTF8",
+			StdDev:              "stddevSamp",
+			ApproxCountDistinct: "uniq",
+			Float:               "Float64",
+			Limit:               " LIMIT %d",
+		},
+		RegexpMatch: "match",
 	}
 }
 
@@ -0,0 +1,78 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer, using EXPLAIN PLAN json = 1.
+// ClickHouse reports neither row estimates nor costs, and has no means
+// of analyzing a query.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*driver.PlanNode,
+	error,
+) {
+	if analyze {
+		return nil, errz.New("clickhouse: explain analyze is not supported")
+	}
+
+	rows, err := conn.QueryContext(ctx, "EXPLAIN PLAN json = 1, description = 1 "+query)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseDBRows, rows)
+
+	// The JSON may be split over several rows, one per line.
+	var sb strings.Builder
+	for rows.Next() {
+		var line string
+		if err = rows.Scan(&line); err != nil {
+			return nil, errw(err)
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errw(err)
+	}
+
+	return parsePlan([]byte(sb.String()))
+}
+
+// chPlanNode is a node of ClickHouse's EXPLAIN PLAN json = 1 output.
+type chPlanNode struct {
+	NodeType    string        `json:"Node Type"`
+	Description string        `json:"Description"`
+	Plans       []*chPlanNode `json:"Plans"`
+}
+
+// parsePlan parses the output of EXPLAIN PLAN json = 1.
+func parsePlan(data []byte) (*driver.PlanNode, error) {
+	var doc []struct {
+		Plan *chPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errz.Wrap(err, "clickhouse: parse query plan")
+	}
+	if len(doc) == 0 || doc[0].Plan == nil {
+		return nil, errz.New("clickhouse: query plan is empty")
+	}
+
+	return doc[0].Plan.normalize(), nil
+}
+
+func (n *chPlanNode) normalize() *driver.PlanNode {
+	node := &driver.PlanNode{Op: n.NodeType, Detail: n.Description}
+	for _, child := range n.Plans {
+		node.Children = append(node.Children, child.normalize())
+	}
+	return node
+}
@@ -1,4 +1,5 @@
-// Package csv implements the sq driver for CSV/TSV et al.
+// Package csv implements the sq driver for CSV/TSV et al, and for
+// fixed-width text.
 package csv
 
 import (
@@ -33,6 +34,8 @@ func (d *Provider) DriverFor(typ drivertype.Type) (driver.Driver, error) {
 		return &driveri{log: d.Log, typ: drivertype.CSV, ingester: d.Ingester, files: d.Files}, nil
 	case drivertype.TSV:
 		return &driveri{log: d.Log, typ: drivertype.TSV, ingester: d.Ingester, files: d.Files}, nil
+	case drivertype.FixedWidth:
+		return &driveri{log: d.Log, typ: drivertype.FixedWidth, ingester: d.Ingester, files: d.Files}, nil
 	}
 
 	return nil, errz.Errorf("unsupported driver type {%s}", typ)
@@ -49,10 +52,14 @@ type driveri struct {
 // DriverMetadata implements driver.Driver.
 func (d *driveri) DriverMetadata() driver.Metadata {
 	md := driver.Metadata{Type: d.typ, Monotable: true}
-	if d.typ == drivertype.CSV {
+	switch d.typ { //nolint:exhaustive
+	case drivertype.CSV:
 		md.Description = "Comma-Separated Values"
 		md.Doc = "https://en.wikipedia.org/wiki/Comma-separated_values"
-	} else {
+	case drivertype.FixedWidth:
+		md.Description = "Fixed-Width Text"
+		md.Doc = "https://en.wikipedia.org/wiki/Flat-file_database"
+	default:
 		md.Description = "Tab-Separated Values"
 		md.Doc = "https://en.wikipedia.org/wiki/Tab-separated_values"
 	}
@@ -161,6 +168,7 @@ func (g *grip) SourceMetadata(ctx context.Context, noSchema bool) (*metadata.Sou
 	md.Size = &size
 
 	md.FQName = md.Name
+	driver.MarkIngestOverrides(ctx, g.src, md.Tables...)
 	return md, nil
 }
 
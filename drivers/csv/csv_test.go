@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/drivers/csv"
 	"github.com/neilotoole/sq/libsq/core/kind"
 	"github.com/neilotoole/sq/libsq/core/langz"
 	"github.com/neilotoole/sq/libsq/core/record"
@@ -23,6 +25,7 @@ import (
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/metadata"
 	"github.com/neilotoole/sq/testh"
 	"github.com/neilotoole/sq/testh/fixt"
 	"github.com/neilotoole/sq/testh/sakila"
@@ -233,6 +236,39 @@ func TestIngest_DuplicateColumns(t *testing.T) {
 	require.Equal(t, wantHeaders, data[0])
 }
 
+func TestIngest_Schema(t *testing.T) {
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil)
+
+	err := tr.Exec(
+		"add", filepath.Join("testdata", "zip_codes.csv"),
+		"--handle", "@zip_codes",
+	)
+	require.NoError(t, err)
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec(
+		"config", "set", "--src", "@zip_codes",
+		driver.OptIngestSchema.Key(), "zip:text,id:int=user_id,-notes",
+	))
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".data"))
+	data := tr.BindCSV()
+	require.Equal(t, []string{"user_id", "name", "zip", "joined"}, data[0])
+	// Detected as int, the zip code's leading zero would be lost.
+	require.Equal(t, []string{"1", "Alice", "01234", "2024-01-02"}, data[1])
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("inspect", "--json", ".data"))
+	md := &metadata.Table{}
+	tr.Bind(md)
+	require.Len(t, md.Columns, 4)
+	require.Equal(t, &metadata.IngestOverride{Kind: kind.Int, RenamedFrom: "id"}, md.Columns[0].IngestOverride)
+	require.Nil(t, md.Columns[1].IngestOverride)
+	require.Equal(t, &metadata.IngestOverride{Kind: kind.Text}, md.Columns[2].IngestOverride)
+}
+
 func TestIngest_Kind_Timestamp(t *testing.T) {
 	t.Parallel()
 
@@ -459,3 +495,140 @@ func TestGenerateTimestampVals(t *testing.T) {
 
 	t.Logf("\n\n")
 }
+
+func TestIngest_Dialect(t *testing.T) {
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil)
+
+	err := tr.Exec(
+		"add", filepath.Join("testdata", "dialect_latin1.csv"),
+		"--handle", "@dialect",
+	)
+	require.NoError(t, err)
+
+	for _, kv := range [][2]string{
+		{csv.OptDelim.Key(), "semi"},
+		{csv.OptSkipLines.Key(), "2"},
+		{csv.OptComment.Key(), "#"},
+		{csv.OptQuote.Key(), "'"},
+		{csv.OptNullTokens.Key(), "NA"},
+		{csv.OptDateFormat.Key(), "%d/%m/%Y"},
+	} {
+		tr = testrun.New(ctx, t, tr)
+		require.NoError(t, tr.Exec("config", "set", "--src", "@dialect", kv[0], kv[1]))
+	}
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".data"))
+	data := tr.BindCSV()
+	require.Equal(t, [][]string{
+		{"id", "name", "city", "joined", "balance"},
+		{"1", "Renée", "Montréal; QC", "1989-11-09", "12.5"},
+		{"2", "O'Brien", "", "2001-12-25", ""},
+		{"3", "Zoë", "Paris", "2003-02-01", "7"},
+	}, data)
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("inspect", "--json", ".data"))
+	md := &metadata.Table{}
+	tr.Bind(md)
+	require.Len(t, md.Columns, 5)
+	require.Equal(t, kind.Int, md.Columns[0].Kind)
+	require.Equal(t, kind.Date, md.Columns[3].Kind)
+	require.Equal(t, kind.Decimal, md.Columns[4].Kind)
+}
+
+func TestIngest_FixedWidth(t *testing.T) {
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil)
+
+	// The layout of report.txt is inferred from its aligned columns.
+	err := tr.Exec(
+		"add", filepath.Join("testdata", "report.txt"),
+		"--handle", "@report", "--driver", drivertype.FixedWidth.String(),
+	)
+	require.NoError(t, err)
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".data"))
+	require.Equal(t, [][]string{
+		{"ID", "NAME", "BALANCE", "JOINED"},
+		{"1", "Alice Smith", "12.5", "2024-01-02"},
+		{"22", "Bob", "-300", "2023-12-31"},
+		{"333", "Zoë Quinn", "7", "2022-06-15"},
+	}, tr.BindCSV())
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--src", "@report", driver.OptIngestColRename.Key(), "x_{{.Name}}"))
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".data"))
+	require.Equal(t, []string{"x_ID", "x_NAME", "x_BALANCE", "x_JOINED"}, tr.BindCSV()[0])
+
+	// The layout of accounts.txt is specified by a layout file.
+	tr = testrun.New(ctx, t, tr)
+	err = tr.Exec(
+		"add", filepath.Join("testdata", "accounts.txt"),
+		"--handle", "@accounts", "--driver", drivertype.FixedWidth.String(),
+		"--"+csv.OptFixedWidthLayout.Key(), filepath.Join("testdata", "accounts.layout.yml"),
+		"--"+driver.OptIngestHeader.Key()+"=false",
+	)
+	require.NoError(t, err)
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", "@accounts.data"))
+	require.Equal(t, [][]string{
+		{"id", "name", "balance", "opened"},
+		{"1", "ALICE SMITH", "12.5", "2024-01-02"},
+		{"22", "BOB", "-300", "2023-12-31"},
+		{"333", "", "0.07", "2022-06-15"},
+	}, tr.BindCSV())
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("inspect", "--json", "@accounts.data"))
+	md := &metadata.Table{}
+	tr.Bind(md)
+	require.Len(t, md.Columns, 4)
+	require.Equal(t, kind.Int, md.Columns[0].Kind)
+	require.Equal(t, kind.Text, md.Columns[1].Kind)
+	require.Equal(t, kind.Decimal, md.Columns[2].Kind)
+	require.Equal(t, kind.Date, md.Columns[3].Kind)
+}
+
+// TestIngest_FixedWidth_LayoutFile verifies that a relative layout file
+// path is made absolute when the source is added, and that editing the
+// layout file causes the data to be ingested afresh.
+func TestIngest_FixedWidth_LayoutFile(t *testing.T) {
+	dataFile, err := filepath.Abs(filepath.Join("testdata", "accounts.txt"))
+	require.NoError(t, err)
+	layout, err := os.ReadFile(filepath.Join("testdata", "accounts.layout.yml"))
+	require.NoError(t, err)
+
+	t.Chdir(t.TempDir())
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	layoutFile := filepath.Join(dir, "accounts.layout.yml")
+	require.NoError(t, os.WriteFile(layoutFile, layout, 0o600))
+
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil)
+	require.NoError(t, tr.Exec(
+		"add", dataFile,
+		"--handle", "@accounts", "--driver", drivertype.FixedWidth.String(),
+		"--"+csv.OptFixedWidthLayout.Key(), "./accounts.layout.yml",
+		"--"+driver.OptIngestHeader.Key()+"=false",
+	))
+	src, err := tr.Run.Config.Collection.Get("@accounts")
+	require.NoError(t, err)
+	require.Equal(t, layoutFile, csv.OptFixedWidthLayout.Get(src.Options))
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", "@accounts.data"))
+	require.Equal(t, []string{"id", "name", "balance", "opened"}, tr.BindCSV()[0])
+
+	layout = []byte(strings.Replace(string(layout), "name: balance", "name: amount", 1))
+	require.NoError(t, os.WriteFile(layoutFile, layout, 0o600))
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", "@accounts.data"))
+	require.Equal(t, []string{"id", "name", "amount", "opened"}, tr.BindCSV()[0])
+}
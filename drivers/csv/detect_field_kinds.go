@@ -5,8 +5,9 @@ import (
 	"github.com/neilotoole/sq/libsq/core/kind"
 )
 
-// detectColKinds detects the kinds of recs' columns.
-func detectColKinds(recs [][]string) ([]kind.Kind, []kind.MungeFunc, error) {
+// detectColKinds detects the kinds of recs' columns. The layouts arg
+// holds custom time layouts, per kind.Detector.SetLayouts; it may be nil.
+func detectColKinds(recs [][]string, layouts map[kind.Kind][]string) ([]kind.Kind, []kind.MungeFunc, error) {
 	if len(recs) == 0 || len(recs[0]) == 0 {
 		return nil, nil, errz.New("no records")
 	}
@@ -16,6 +17,9 @@ func detectColKinds(recs [][]string) ([]kind.Kind, []kind.MungeFunc, error) {
 	detectors := make([]*kind.Detector, len(recs[0]))
 	for i := range fieldCount {
 		detectors[i] = kind.NewDetector()
+		for k, kindLayouts := range layouts {
+			detectors[i].SetLayouts(k, kindLayouts...)
+		}
 	}
 
 	for i := range recs {
@@ -0,0 +1,570 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/timez"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// The dialect option keys are declared as constants, because an option's
+// validation func can't refer to the option itself.
+const (
+	optEncodingKey       = "driver.csv.encoding"
+	optCommentKey        = "driver.csv.comment"
+	optQuoteKey          = "driver.csv.quote"
+	optEscapeKey         = "driver.csv.escape"
+	optDatetimeFormatKey = "driver.csv.datetime-format"
+	optDateFormatKey     = "driver.csv.date-format"
+	optTimeFormatKey     = "driver.csv.time-format"
+)
+
+// encodingAuto is the OptEncoding value that indicates that the encoding
+// should be detected.
+const encodingAuto = "auto"
+
+// OptEncoding specifies the text encoding of CSV data.
+var OptEncoding = options.NewString(
+	optEncodingKey,
+	nil,
+	encodingAuto,
+	func(s string) error {
+		_, err := getEncoding(s)
+		return err
+	},
+	"Text encoding of ingest CSV data",
+	`Text encoding of CSV data. The default, "auto", detects UTF-8 and UTF-16
+via a byte order mark (BOM); otherwise the data is treated as UTF-8 if it
+is valid UTF-8, and as Windows-1252 (a superset of ISO-8859-1/Latin-1) if
+not. Any WHATWG encoding label can be specified, e.g. utf-8, utf-16le,
+latin1, windows-1252, shift_jis, gbk.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptNullTokens specifies CSV field values that are treated as NULL.
+var OptNullTokens = options.NewString(
+	"driver.csv.null-tokens",
+	nil,
+	"",
+	nil,
+	"Ingest CSV field values treated as NULL",
+	`Comma-separated list of CSV field values that are treated as NULL, e.g.
+"NA,N/A,NULL,-". Matching is case-sensitive, and applies to the entire
+field value. Null tokens are ignored when detecting column kinds, so a
+numeric column containing "NA" is still detected as numeric.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptSkipLines specifies the number of lines to skip at the start of
+// CSV data.
+var OptSkipLines = options.NewInt(
+	"driver.csv.skip-lines",
+	nil,
+	0,
+	"Lines to skip at start of ingest CSV data",
+	`Number of lines to skip at the start of CSV data, before the header row
+(if any). This is useful for files that have a title or banner before the
+CSV data proper.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptComment specifies the CSV comment character.
+var OptComment = options.NewString(
+	optCommentKey,
+	nil,
+	"",
+	func(s string) error {
+		_, err := parseDialectRune(optCommentKey, s, true)
+		return err
+	},
+	"Comment character for ingest CSV data",
+	`Comment character for CSV data, e.g. "#". Lines beginning with the
+comment character are ignored. By default, there is no comment character.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptQuote specifies the CSV quote character.
+var OptQuote = options.NewString(
+	optQuoteKey,
+	nil,
+	`"`,
+	func(s string) error {
+		_, err := parseDialectRune(optQuoteKey, s, false)
+		return err
+	},
+	"Quote character for ingest CSV data",
+	`Quote character for CSV data. A field enclosed in quote characters can
+contain the delimiter, newlines, and (doubled) quote characters.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptEscape specifies the CSV escape character.
+var OptEscape = options.NewString(
+	optEscapeKey,
+	nil,
+	"",
+	func(s string) error {
+		_, err := parseDialectRune(optEscapeKey, s, true)
+		return err
+	},
+	"Escape character for ingest CSV data",
+	`Escape character for CSV data, e.g. "\". Within a field, the escape
+character followed by the quote character is a literal quote character,
+and a doubled escape character is a literal escape character. By default,
+there is no escape character: a literal quote is written as two quote
+characters, per RFC 4180.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"csv",
+)
+
+// OptDatetimeFormat specifies the layout of CSV datetime values.
+var OptDatetimeFormat = newLayoutOpt(optDatetimeFormatKey, kind.Datetime,
+	"%d/%m/%Y %H:%M:%S")
+
+// OptDateFormat specifies the layout of CSV date values.
+var OptDateFormat = newLayoutOpt(optDateFormatKey, kind.Date, "%d/%m/%Y")
+
+// OptTimeFormat specifies the layout of CSV time values.
+var OptTimeFormat = newLayoutOpt(optTimeFormatKey, kind.Time, "%I:%M %p")
+
+// newLayoutOpt returns an option that specifies the layout of CSV values
+// of kind k.
+func newLayoutOpt(key string, k kind.Kind, example string) options.String {
+	return options.NewString(
+		key,
+		nil,
+		"",
+		func(s string) error {
+			if s == "" {
+				return nil
+			}
+			_, err := timez.Layout(s)
+			return err
+		},
+		"Layout of ingest CSV "+k.String()+" values",
+		`Layout of CSV `+k.String()+` values that sq doesn't otherwise detect, e.g.
+"`+example+`". The value is a strftime layout, or a named layout such
+as RFC3339. The layout is tried before the built-in layouts when
+detecting `+k.String()+` columns, and matching values are parsed using it.`,
+		options.TagSource,
+		options.TagIngestMutate,
+		"csv",
+	)
+}
+
+// dialect describes the format of CSV data, as specified by the
+// source's options.
+type dialect struct {
+	// enc is the text encoding. If nil, the encoding is detected.
+	enc encoding.Encoding
+
+	// layouts holds the custom time layouts for kinds Datetime,
+	// Date and Time.
+	layouts map[kind.Kind][]string
+
+	// fwLayout is the column layout of fixed-width data. If nil, the
+	// layout is inferred from the first sampleSize lines.
+	fwLayout *fixedWidthLayout
+
+	nullTokens []string
+	skipLines  int
+	sampleSize int
+
+	delim   rune
+	quote   rune
+	escape  rune
+	comment rune
+
+	// fixedWidth is true if the data is fixed-width text, rather than
+	// delimited.
+	fixedWidth bool
+}
+
+// getDialect returns the dialect for src.
+func getDialect(src *source.Source) (*dialect, error) {
+	if src.Type == drivertype.FixedWidth {
+		return getFixedWidthDialect(src)
+	}
+
+	var err error
+	d := &dialect{}
+	if d.delim, err = getDelimiter(src); err != nil {
+		return nil, err
+	}
+
+	o := src.Options
+	if d.enc, err = getEncoding(OptEncoding.Get(o)); err != nil {
+		return nil, err
+	}
+	if d.quote, err = parseDialectRune(optQuoteKey, OptQuote.Get(o), false); err != nil {
+		return nil, err
+	}
+	if d.escape, err = parseDialectRune(optEscapeKey, OptEscape.Get(o), true); err != nil {
+		return nil, err
+	}
+	if d.comment, err = parseDialectRune(optCommentKey, OptComment.Get(o), true); err != nil {
+		return nil, err
+	}
+
+	if d.escape == d.quote {
+		// A doubled quote is the default escape mechanism.
+		d.escape = 0
+	}
+
+	switch {
+	case d.quote == d.delim:
+		return nil, errz.Errorf("%s: quote {%c} can't be the same as the delimiter", optQuoteKey, d.quote)
+	case d.escape != 0 && d.escape == d.delim:
+		return nil, errz.Errorf("%s: escape {%c} can't be the same as the delimiter", optEscapeKey, d.escape)
+	case d.comment != 0 && (d.comment == d.delim || d.comment == d.quote || d.comment == d.escape):
+		return nil, errz.Errorf("%s: comment {%c} can't be the same as the delimiter, quote or escape",
+			optCommentKey, d.comment)
+	}
+
+	if d.skipLines = OptSkipLines.Get(o); d.skipLines < 0 {
+		return nil, errz.Errorf("%s: must not be negative: %d", OptSkipLines.Key(), d.skipLines)
+	}
+
+	for _, tok := range strings.Split(OptNullTokens.Get(o), ",") {
+		if tok = strings.TrimSpace(tok); tok != "" {
+			d.nullTokens = append(d.nullTokens, tok)
+		}
+	}
+
+	for _, opt := range []struct {
+		opt options.String
+		k   kind.Kind
+	}{
+		{OptDatetimeFormat, kind.Datetime},
+		{OptDateFormat, kind.Date},
+		{OptTimeFormat, kind.Time},
+	} {
+		val := opt.opt.Get(o)
+		if val == "" {
+			continue
+		}
+
+		var layout string
+		if layout, err = timez.Layout(val); err != nil {
+			return nil, errz.Wrap(err, opt.opt.Key())
+		}
+		if d.layouts == nil {
+			d.layouts = map[kind.Kind][]string{}
+		}
+		d.layouts[opt.k] = append(d.layouts[opt.k], layout)
+	}
+
+	return d, nil
+}
+
+// parseDialectRune parses val, the value of option key, which must be a
+// single character. If allowEmpty is true, the empty string is permitted,
+// and returns zero.
+func parseDialectRune(key, val string, allowEmpty bool) (rune, error) {
+	if val == "" && allowEmpty {
+		return 0, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(val)
+	switch {
+	case size == 0 || size != len(val):
+		return 0, errz.Errorf("%s: must be a single character: %q", key, val)
+	case r == utf8.RuneError, r == '\r', r == '\n':
+		return 0, errz.Errorf("%s: invalid character: %q", key, val)
+	}
+	return r, nil
+}
+
+// getEncoding returns the encoding named by name, which is a WHATWG
+// encoding label. If name is encodingAuto, nil is returned, indicating
+// that the encoding should be detected.
+func getEncoding(name string) (encoding.Encoding, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, encodingAuto) {
+		return nil, nil //nolint:nilnil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, errz.Errorf("%s: unknown encoding {%s}", optEncodingKey, name)
+	}
+	return enc, nil
+}
+
+// encodingPeekSize is the number of bytes examined by detectEncoding.
+const encodingPeekSize = 4096
+
+// newDecodingReader returns a reader that decodes r from encoding enc to
+// UTF-8. A BOM, if present, overrides enc, and is stripped. If enc is nil,
+// the encoding is detected via detectEncoding.
+func newDecodingReader(r io.Reader, enc encoding.Encoding) io.Reader {
+	if enc != nil {
+		return transform.NewReader(r, unicode.BOMOverride(enc.NewDecoder()))
+	}
+
+	br := bufio.NewReaderSize(r, encodingPeekSize)
+	peek, err := br.Peek(encodingPeekSize)
+	if enc = detectEncoding(peek, err == nil); enc == nil {
+		return br
+	}
+	return transform.NewReader(br, enc.NewDecoder())
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// detectEncoding returns the encoding of data, which is the start of the
+// input. If truncated is true, data is a prefix of a longer input, and
+// thus may end with a partial UTF-8 sequence. If data is UTF-8 without a
+// BOM, nil is returned, indicating that no decoding is needed.
+func detectEncoding(data []byte, truncated bool) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+
+	if truncated {
+		// Drop a trailing partial rune, if any.
+		for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+			if utf8.RuneStart(data[len(data)-i]) {
+				if !utf8.FullRune(data[len(data)-i:]) {
+					data = data[:len(data)-i]
+				}
+				break
+			}
+		}
+	}
+
+	if utf8.Valid(data) {
+		return nil
+	}
+
+	// Windows-1252 is a superset of the printable characters
+	// of ISO-8859-1, and is what Excel typically exports.
+	return charmap.Windows1252
+}
+
+// recordReader reads CSV records. It is implemented by *csv.Reader.
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+var _ recordReader = (*csv.Reader)(nil)
+
+// newReader returns a recordReader that reads CSV records from r per d.
+// It decodes r from d's encoding, skips d.skipLines lines, and handles
+// d's quote, escape and comment characters. For fixed-width data, the
+// records are the lines of r, split per d.fwLayout.
+func (d *dialect) newReader(r io.Reader) (recordReader, error) {
+	// We add the CR filter reader to deal with CSV files exported
+	// from Excel which can have the DOS-style \r EOL markers.
+	r = &crFilterReader{r: newDecodingReader(r, d.enc)}
+
+	if d.skipLines > 0 {
+		br := bufio.NewReader(r)
+		for range d.skipLines {
+			if _, err := br.ReadString('\n'); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, errz.Err(err)
+			}
+		}
+		r = br
+	}
+
+	if d.fixedWidth {
+		return newFixedWidthReader(r, d.fwLayout, d.sampleSize)
+	}
+
+	if d.quote == '"' && d.escape == 0 {
+		cr := csv.NewReader(r)
+		cr.Comma = d.delim
+		cr.Comment = d.comment
+		return cr, nil
+	}
+
+	// encoding/csv only supports the double-quote character, and has no
+	// escape character. Thus, dialectReader rewrites the input so that d's
+	// quote becomes '"' (and vice versa), and escape sequences become
+	// doubled quotes. The fields are then swapped back by swapQuoteReader.
+	cr := csv.NewReader(&dialectReader{r: bufio.NewReader(r), quote: d.quote, escape: d.escape})
+	cr.Comma = d.swapQuote(d.delim)
+	cr.Comment = d.swapQuote(d.comment)
+	if d.escape != 0 {
+		// An escaped quote outside a quoted field becomes a bare quote.
+		cr.LazyQuotes = true
+	}
+
+	if d.quote == '"' {
+		return cr, nil
+	}
+	return &swapQuoteReader{cr: cr, swapFn: d.swapQuote}, nil
+}
+
+// swapQuote returns '"' if c is d's quote character, or d's quote
+// character if c is '"', else c.
+func (d *dialect) swapQuote(c rune) rune {
+	switch c {
+	case d.quote:
+		return '"'
+	case '"':
+		return d.quote
+	default:
+		return c
+	}
+}
+
+// dialectReader is an io.Reader that rewrites CSV data with a custom
+// quote or escape character into the form understood by encoding/csv.
+// See dialect.newReader.
+type dialectReader struct {
+	r      *bufio.Reader
+	err    error
+	buf    []byte
+	quote  rune
+	escape rune
+}
+
+// Read implements io.Reader.
+func (r *dialectReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) < len(p) && r.err == nil {
+		var c rune
+		if c, _, r.err = r.r.ReadRune(); r.err != nil {
+			break
+		}
+
+		if r.escape == 0 || c != r.escape {
+			r.put(c)
+			continue
+		}
+
+		var next rune
+		if next, _, r.err = r.r.ReadRune(); r.err != nil {
+			// A trailing escape is literal.
+			r.put(c)
+			break
+		}
+
+		switch next {
+		case r.quote:
+			r.buf = append(r.buf, '"', '"')
+		case r.escape:
+			r.put(c)
+		default:
+			// Not an escape sequence: the escape is literal.
+			r.put(c)
+			_ = r.r.UnreadRune()
+		}
+	}
+
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	if n == 0 && r.err != nil {
+		return 0, r.err
+	}
+	return n, nil
+}
+
+// put appends c to r.buf, swapping the quote character and '"'.
+func (r *dialectReader) put(c rune) {
+	switch c {
+	case r.quote:
+		c = '"'
+	case '"':
+		c = r.quote
+	}
+	r.buf = utf8.AppendRune(r.buf, c)
+}
+
+// swapQuoteReader is a recordReader that reverses the quote swap
+// performed by dialectReader on each field.
+type swapQuoteReader struct {
+	cr     *csv.Reader
+	swapFn func(rune) rune
+}
+
+// Read implements recordReader.
+func (r *swapQuoteReader) Read() ([]string, error) {
+	rec, err := r.cr.Read()
+	for i := range rec {
+		rec[i] = strings.Map(r.swapFn, rec[i])
+	}
+	return rec, err
+}
+
+// blankNullTokens returns a copy of recs with fields that match one of
+// tokens replaced by the empty string, for use with kind detection. If
+// tokens is empty, recs is returned.
+func blankNullTokens(recs [][]string, tokens []string) [][]string {
+	if len(tokens) == 0 {
+		return recs
+	}
+
+	blanked := make([][]string, len(recs))
+	for i := range recs {
+		blanked[i] = make([]string, len(recs[i]))
+		for j, field := range recs[i] {
+			if !slices.Contains(tokens, field) {
+				blanked[i][j] = field
+			}
+		}
+	}
+	return blanked
+}
+
+// configureNullTokenMunge configures mungers so that a field value that
+// matches one of tokens is munged to nil.
+func configureNullTokenMunge(mungers []kind.MungeFunc, tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	for i := range mungers {
+		existing := mungers[i]
+		mungers[i] = func(v any) (any, error) {
+			if s, ok := v.(string); ok && slices.Contains(tokens, s) {
+				return nil, nil //nolint:nilnil
+			}
+			if existing == nil {
+				return v, nil
+			}
+			return existing(v)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/core/timez"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// optFixedWidthLayoutKey is the key of OptFixedWidthLayout, which can't
+// refer to itself in its validation func.
+const optFixedWidthLayoutKey = "driver.fixedwidth.layout"
+
+// OptFixedWidthLayout specifies the column layout of fixed-width data,
+// either inline or as the path to a YAML or JSON layout file.
+var OptFixedWidthLayout = options.NewString(
+	optFixedWidthLayoutKey,
+	nil,
+	"",
+	func(s string) error {
+		if s == "" || driver.IsIngestFile(s) {
+			// The file is read at ingest time, as it may not exist yet.
+			return nil
+		}
+		_, err := parseInlineFixedWidthLayout(s)
+		return err
+	},
+	"Column layout of ingest fixed-width data",
+	`Column layout of fixed-width data. Each column has a name, and occupies
+the character positions from start (counting from 1) for width characters
+on each line. If not set, the layout is inferred from the columns of
+whitespace that are common to the sampled lines: this works for aligned
+text reports, but not for data whose fields abut each other.
+
+The value is either an inline layout, or the path to a YAML or JSON layout
+file. An inline layout is a comma-separated list of column entries of the
+form "name:start:width[:kind][:key=val...]", e.g.
+
+  id:1:6:int,name:7:20,amount:27:9:decimal:decimals=2,code:36:3:trim=none
+
+The optional kind overrides kind detection: text, int, float, decimal,
+bool, datetime, date, time. The keys are:
+
+  decimals   The number of implied decimal places, as in mainframe data:
+             with decimals=2, "0012345" is 123.45. A leading or trailing
+             sign is allowed.
+  trim       Trim padding spaces from the value: both (default), left,
+             right, or none.
+  format     The strftime or named layout of a datetime, date or time
+             column, e.g. format=%Y%m%d. As the layout may contain colons,
+             format must be the last key of the entry.
+
+A width of zero extends the column to the end of the line. A layout file
+looks like:
+
+  columns:
+    - name: id
+      start: 1
+      width: 6
+      kind: int
+    - name: amount
+      start: 27
+      width: 9
+      kind: decimal
+      decimals: 2
+    - name: opened
+      start: 36
+      width: 8
+      kind: date
+      format: "%Y%m%d"
+
+The layout's column names take precedence over a header row, which is
+skipped per option "ingest.header".
+
+  $ sq add ./accounts.txt --driver=fixedwidth --driver.fixedwidth.layout=./accounts.layout.yml
+
+A relative layout file path is made absolute when the option is set via
+"sq add" or "sq config set". Editing the layout file causes the data to be
+ingested afresh.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	options.TagIngestFile,
+	"fixedwidth",
+)
+
+// The values of fixedWidthColumn.Trim.
+const (
+	trimBoth  = "both"
+	trimLeft  = "left"
+	trimRight = "right"
+	trimNone  = "none"
+)
+
+// fixedWidthPad is the padding trimmed from fixed-width values.
+const fixedWidthPad = " \t"
+
+// fixedWidthLayout is the column layout of fixed-width data, as specified
+// by OptFixedWidthLayout, or as inferred by inferFixedWidthLayout.
+type fixedWidthLayout struct {
+	Columns []*fixedWidthColumn `json:"columns" yaml:"columns"`
+}
+
+// fixedWidthColumn is a column of a fixedWidthLayout.
+type fixedWidthColumn struct {
+	// Name is the column name.
+	Name string `json:"name" yaml:"name"`
+
+	// Trim is one of trimBoth, trimLeft, trimRight, or trimNone. If empty,
+	// trimBoth is used.
+	Trim string `json:"trim,omitempty" yaml:"trim,omitempty"`
+
+	// Start is the 1-based character position of the column's first
+	// character.
+	Start int `json:"start" yaml:"start"`
+
+	// Width is the column's width in characters. If zero, the column
+	// extends to the end of the line.
+	Width int `json:"width" yaml:"width"`
+
+	// Decimals is the number of implied decimal places of the column's
+	// values.
+	Decimals int `json:"decimals,omitempty" yaml:"decimals,omitempty"`
+
+	// Format, if non-empty, is the strftime or named layout of the
+	// column's values, for kinds Datetime, Date and Time.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Kind, if not kind.Unknown, is the column's kind, overriding
+	// the detected kind.
+	Kind kind.Kind `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// readFixedWidthLayout returns the layout specified by src's
+// OptFixedWidthLayout, or nil if the option is not set. If the option
+// value is a file path, the file is loaded.
+func readFixedWidthLayout(src *source.Source) (*fixedWidthLayout, error) {
+	val := strings.TrimSpace(OptFixedWidthLayout.Get(src.Options))
+	if val == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	if !driver.IsIngestFile(val) {
+		return parseInlineFixedWidthLayout(val)
+	}
+
+	data, err := os.ReadFile(val)
+	if err != nil {
+		return nil, errz.Wrapf(err, "%s: read layout file", optFixedWidthLayoutKey)
+	}
+
+	layout := &fixedWidthLayout{}
+	if err = ioz.UnmarshallYAML(data, layout); err != nil {
+		return nil, errz.Wrapf(err, "%s: parse layout file {%s}", optFixedWidthLayoutKey, val)
+	}
+
+	if err = layout.validate(); err != nil {
+		return nil, errz.Wrapf(err, "%s: layout file {%s}", optFixedWidthLayoutKey, val)
+	}
+	return layout, nil
+}
+
+// parseInlineFixedWidthLayout parses an inline OptFixedWidthLayout value,
+// such as "id:1:6:int,name:7:20,amount:27:9:decimal:decimals=2".
+func parseInlineFixedWidthLayout(val string) (*fixedWidthLayout, error) {
+	layout := &fixedWidthLayout{}
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		col := &fixedWidthColumn{Name: parts[0]}
+		if len(parts) < 3 {
+			return nil, errz.Errorf("%s: column {%s}: expected name:start:width", optFixedWidthLayoutKey, col.Name)
+		}
+
+		var err error
+		if col.Start, err = strconv.Atoi(parts[1]); err != nil {
+			return nil, errz.Errorf("%s: column {%s}: invalid start {%s}", optFixedWidthLayoutKey, col.Name, parts[1])
+		}
+		if col.Width, err = strconv.Atoi(parts[2]); err != nil {
+			return nil, errz.Errorf("%s: column {%s}: invalid width {%s}", optFixedWidthLayoutKey, col.Name, parts[2])
+		}
+
+		for j, part := range parts[3:] {
+			key, v, ok := strings.Cut(part, "=")
+			if !ok {
+				if err = col.Kind.UnmarshalText([]byte(part)); err != nil {
+					return nil, errz.Wrapf(err, "%s: column {%s}", optFixedWidthLayoutKey, col.Name)
+				}
+				continue
+			}
+
+			switch strings.TrimSpace(key) {
+			case "decimals":
+				if col.Decimals, err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+					return nil, errz.Errorf("%s: column {%s}: invalid decimals {%s}",
+						optFixedWidthLayoutKey, col.Name, v)
+				}
+			case "trim":
+				col.Trim = strings.TrimSpace(v)
+			case "format":
+				// A layout such as "%H:%M" contains colons, so format
+				// is the remainder of the entry.
+				col.Format = strings.Join(append([]string{v}, parts[3+j+1:]...), ":")
+			default:
+				return nil, errz.Errorf("%s: column {%s}: unknown key {%s}", optFixedWidthLayoutKey, col.Name, key)
+			}
+			if col.Format != "" {
+				break
+			}
+		}
+
+		layout.Columns = append(layout.Columns, col)
+	}
+
+	if err := layout.validate(); err != nil {
+		return nil, errz.Wrap(err, optFixedWidthLayoutKey)
+	}
+	return layout, nil
+}
+
+// validate returns an error if l has a malformed column.
+func (l *fixedWidthLayout) validate() error {
+	if len(l.Columns) == 0 {
+		return errz.New("layout has no columns")
+	}
+
+	names := make([]string, 0, len(l.Columns))
+	for _, col := range l.Columns {
+		switch {
+		case col == nil:
+			return errz.New("empty column entry")
+		case col.Name == "":
+			return errz.New("column entry has no name")
+		case slices.Contains(names, col.Name):
+			return errz.Errorf("duplicate column name {%s}", col.Name)
+		case col.Start < 1:
+			return errz.Errorf("column {%s}: start must be 1 or greater: %d", col.Name, col.Start)
+		case col.Width < 0:
+			return errz.Errorf("column {%s}: width must not be negative: %d", col.Name, col.Width)
+		case col.Decimals < 0:
+			return errz.Errorf("column {%s}: decimals must not be negative: %d", col.Name, col.Decimals)
+		case col.Kind == kind.Null:
+			return errz.Errorf("column {%s}: kind {%s} is not allowed", col.Name, col.Kind)
+		}
+
+		if col.Format != "" {
+			switch col.Kind { //nolint:exhaustive
+			case kind.Datetime, kind.Date, kind.Time:
+			default:
+				return errz.Errorf("column {%s}: format requires kind datetime, date or time", col.Name)
+			}
+			if _, err := timez.Layout(col.Format); err != nil {
+				return errz.Wrapf(err, "column {%s}", col.Name)
+			}
+		}
+
+		switch col.Trim {
+		case "", trimBoth, trimLeft, trimRight, trimNone:
+		default:
+			return errz.Errorf("column {%s}: invalid trim {%s}: expected one of: %s", col.Name, col.Trim,
+				strings.Join([]string{trimBoth, trimLeft, trimRight, trimNone}, ", "))
+		}
+		names = append(names, col.Name)
+	}
+	return nil
+}
+
+// names returns the names of l's columns.
+func (l *fixedWidthLayout) names() []string {
+	names := make([]string, len(l.Columns))
+	for i, col := range l.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// applyKinds sets the elements of kinds and mungers for each of l's
+// columns that has an explicit kind. The elements correspond to l's
+// columns.
+func (l *fixedWidthLayout) applyKinds(kinds []kind.Kind, mungers []kind.MungeFunc) error {
+	for i, col := range l.Columns {
+		if col.Kind == kind.Unknown || i >= len(kinds) {
+			continue
+		}
+
+		if col.Format != "" {
+			layout, err := timez.Layout(col.Format)
+			if err != nil {
+				return errz.Wrapf(err, "%s: column {%s}", optFixedWidthLayoutKey, col.Name)
+			}
+			kinds[i], mungers[i] = col.Kind, newTimeLayoutMunger(col.Kind, layout)
+			continue
+		}
+
+		fn, err := kind.MungeFuncFor(col.Kind)
+		if err != nil {
+			return errz.Wrapf(err, "%s: column {%s}", optFixedWidthLayoutKey, col.Name)
+		}
+		kinds[i], mungers[i] = col.Kind, fn
+	}
+	return nil
+}
+
+// newTimeLayoutMunger returns a kind.MungeFunc that parses values of
+// kind k (Datetime, Date or Time) per layout. The munged value has the
+// same canonical form as that of kind.MungeFuncFor.
+func newTimeLayoutMunger(k kind.Kind, layout string) kind.MungeFunc {
+	return func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok || s == "" {
+			return nil, nil //nolint:nilnil
+		}
+
+		t, err := time.Parse(layout, strings.TrimSpace(s))
+		if err != nil {
+			return nil, errz.Wrapf(err, "convert %q to %s", s, k)
+		}
+
+		switch k { //nolint:exhaustive
+		case kind.Date:
+			return t.Format(time.DateOnly), nil
+		case kind.Time:
+			return t.Format(time.TimeOnly), nil
+		default:
+			return t, nil
+		}
+	}
+}
+
+// split returns the values of l's columns in line. A column that lies
+// beyond the end of line is the empty string.
+func (l *fixedWidthLayout) split(line string) []string {
+	runes := []rune(line)
+	rec := make([]string, len(l.Columns))
+	for i, col := range l.Columns {
+		start := col.Start - 1
+		if start >= len(runes) {
+			continue
+		}
+
+		end := len(runes)
+		if col.Width > 0 {
+			end = min(start+col.Width, end)
+		}
+
+		val := string(runes[start:end])
+		switch col.Trim {
+		case trimNone:
+		case trimLeft:
+			val = strings.TrimLeft(val, fixedWidthPad)
+		case trimRight:
+			val = strings.TrimRight(val, fixedWidthPad)
+		default:
+			val = strings.Trim(val, fixedWidthPad)
+		}
+
+		rec[i] = applyImpliedDecimals(val, col.Decimals)
+	}
+	return rec
+}
+
+// applyImpliedDecimals returns numeric string val with a decimal point
+// inserted n digits from the right, e.g. "0012345" with n=2 is "123.45".
+// A leading or trailing sign is allowed; a trailing sign, as in
+// "12345-", is moved to the front. If val isn't a string of digits, or n
+// is zero, val is returned unchanged.
+func applyImpliedDecimals(val string, n int) string {
+	s := strings.TrimSpace(val)
+	if n == 0 || s == "" {
+		return val
+	}
+
+	var sign string
+	switch {
+	case s[0] == '-' || s[0] == '+':
+		sign, s = s[:1], s[1:]
+	case s[len(s)-1] == '-' || s[len(s)-1] == '+':
+		sign, s = s[len(s)-1:], s[:len(s)-1]
+	}
+	if sign == "+" {
+		sign = ""
+	}
+
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return val
+	}
+
+	if len(s) <= n {
+		s = strings.Repeat("0", n-len(s)+1) + s
+	}
+
+	whole := strings.TrimLeft(s[:len(s)-n], "0")
+	if whole == "" {
+		whole = "0"
+	}
+	return sign + whole + "." + s[len(s)-n:]
+}
+
+// inferFixedWidthLayout infers the layout of fixed-width data from sample
+// lines. A column boundary is a character position that is whitespace on
+// every line, and that is followed by a non-whitespace position: thus
+// each column starts at the start of its values, and extends up to the
+// start of the next column's values. The last column extends to the end
+// of the line. The columns are named A, B, C, etc.; the header row, if
+// any, is split by the layout like any other line.
+func inferFixedWidthLayout(lines []string) (*fixedWidthLayout, error) {
+	var blank []bool
+	for _, line := range lines {
+		for i, r := range []rune(line) {
+			if i >= len(blank) {
+				blank = append(blank, true)
+			}
+			if r != ' ' && r != '\t' {
+				blank[i] = false
+			}
+		}
+	}
+
+	var starts []int
+	for i := range blank {
+		if !blank[i] && (i == 0 || blank[i-1]) {
+			starts = append(starts, i)
+		}
+	}
+
+	if len(starts) == 0 {
+		return nil, errz.Errorf("fixed-width data has no content from which to infer a layout: specify option %s",
+			optFixedWidthLayoutKey)
+	}
+	// The first column starts at the start of the line, so that leading
+	// indentation doesn't shift the content of a longer value.
+	starts[0] = 0
+
+	layout := &fixedWidthLayout{Columns: make([]*fixedWidthColumn, len(starts))}
+	for i, start := range starts {
+		col := &fixedWidthColumn{Name: stringz.GenerateAlphaColName(i, false), Start: start + 1}
+		if i < len(starts)-1 {
+			col.Width = starts[i+1] - start
+		}
+		layout.Columns[i] = col
+	}
+	return layout, nil
+}
+
+// fixedWidthReader is a recordReader that reads the lines of fixed-width
+// data, splitting each line into fields per its layout. Empty lines are
+// skipped.
+type fixedWidthReader struct {
+	br     *bufio.Reader
+	layout *fixedWidthLayout
+
+	// readAhead holds lines read for layout inference, which are returned
+	// before any further lines are read from br.
+	readAhead []string
+}
+
+var _ recordReader = (*fixedWidthReader)(nil)
+
+// newFixedWidthReader returns a recordReader that reads fixed-width data
+// from r per layout. If layout is nil, it is inferred from the first
+// sampleSize lines of r.
+func newFixedWidthReader(r io.Reader, layout *fixedWidthLayout, sampleSize int) (recordReader, error) {
+	fr := &fixedWidthReader{br: bufio.NewReader(r), layout: layout}
+	if layout != nil {
+		return fr, nil
+	}
+
+	for range sampleSize {
+		line, err := fr.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		fr.readAhead = append(fr.readAhead, line)
+	}
+
+	var err error
+	if fr.layout, err = inferFixedWidthLayout(fr.readAhead); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+// Read implements recordReader.
+func (r *fixedWidthReader) Read() ([]string, error) {
+	if len(r.readAhead) > 0 {
+		line := r.readAhead[0]
+		r.readAhead = r.readAhead[1:]
+		return r.layout.split(line), nil
+	}
+
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	return r.layout.split(line), nil
+}
+
+// readLine returns the next non-empty line from r.br, without its line
+// terminator. It returns io.EOF when there are no more lines.
+func (r *fixedWidthReader) readLine() (string, error) {
+	for {
+		line, err := r.br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", errz.Err(err)
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line != "" {
+			return line, nil
+		}
+		if err != nil {
+			return "", io.EOF
+		}
+	}
+}
+
+// getFixedWidthDialect returns the dialect for fixed-width src. The CSV
+// dialect options don't apply to fixed-width data.
+func getFixedWidthDialect(src *source.Source) (*dialect, error) {
+	layout, err := readFixedWidthLayout(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dialect{
+		fixedWidth: true,
+		fwLayout:   layout,
+		sampleSize: driver.OptIngestSampleSize.Get(src.Options),
+	}, nil
+}
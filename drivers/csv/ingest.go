@@ -2,7 +2,6 @@ package csv
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"io"
 	"time"
@@ -51,7 +50,8 @@ Possible values are: comma, space, pipe, tab, colon, semi, period.`,
 	"csv",
 )
 
-// ingestCSV loads the src CSV data into destGrip.
+// ingestCSV loads the src CSV data into destGrip. Fixed-width data is
+// also ingested via ingestCSV, per its dialect.
 func (d *driveri) ingestCSV(ctx context.Context, src *source.Source, destGrip driver.Grip) error {
 	log := lg.FromContext(ctx)
 	startUTC := time.Now().UTC()
@@ -63,19 +63,25 @@ func (d *driveri) ingestCSV(ctx context.Context, src *source.Source, destGrip dr
 
 	defer lg.WarnIfCloseError(log, lgm.CloseFileReader, rc)
 
-	delim, err := getDelimiter(src)
+	dlct, err := getDialect(src)
 	if err != nil {
 		return err
 	}
 
-	cr := newCSVReader(rc, delim)
+	cr, err := dlct.newReader(rc)
+	if err != nil {
+		return err
+	}
 
 	recs, err := readRecords(cr, driver.OptIngestSampleSize.Get(src.Options))
 	if err != nil {
 		return err
 	}
 
-	headerPresent, err := hasHeaderRow(ctx, recs, src.Options)
+	// Null tokens are ignored for the purposes of detection.
+	sample := blankNullTokens(recs, dlct.nullTokens)
+
+	headerPresent, err := hasHeaderRow(ctx, sample, src.Options)
 	if err != nil {
 		return err
 	}
@@ -86,6 +92,7 @@ func (d *driveri) ingestCSV(ctx context.Context, src *source.Source, destGrip dr
 
 		// We're done with the first row
 		recs = recs[1:]
+		sample = sample[1:]
 	} else {
 		// The CSV file does not have a header record. We will generate
 		// col names [A,B,C...].
@@ -95,15 +102,41 @@ func (d *driveri) ingestCSV(ctx context.Context, src *source.Source, destGrip dr
 		}
 	}
 
+	if dlct.fwLayout != nil {
+		// The fixed-width layout's column names take precedence over
+		// the header row, if any.
+		header = dlct.fwLayout.names()
+	}
+
 	if header, err = driver.MungeIngestColNames(ctx, header); err != nil {
 		return err
 	}
 
-	kinds, mungers, err := detectColKinds(recs)
+	kinds, mungers, err := detectColKinds(sample, dlct.layouts)
 	if err != nil {
 		return err
 	}
 
+	if dlct.fwLayout != nil {
+		if err = dlct.fwLayout.applyKinds(kinds, mungers); err != nil {
+			return err
+		}
+	}
+
+	sch, err := driver.ReadIngestSchema(src)
+	if err != nil {
+		return err
+	}
+
+	keep, err := sch.Apply(ctx, source.MonotableName, header, kinds, mungers)
+	if err != nil {
+		return err
+	}
+	header = driver.ProjectIngestFields(keep, header)
+	kinds = driver.ProjectIngestFields(keep, kinds)
+	mungers = driver.ProjectIngestFields(keep, mungers)
+	configureNullTokenMunge(mungers, dlct.nullTokens)
+
 	// And now we need to create the dest table in scratchDB
 	tblDef := createTblDef(source.MonotableName, header, kinds)
 
@@ -133,7 +166,7 @@ func (d *driveri) ingestCSV(ctx context.Context, src *source.Source, destGrip dr
 		tuning.OptRecBufSize.Get(destGrip.Source().Options),
 	)
 
-	err = execInsert(ctx, insertWriter, recMeta, mungers, recs, cr)
+	err = execInsert(ctx, insertWriter, recMeta, mungers, keep, recs, cr)
 	if err != nil {
 		return err
 	}
@@ -269,14 +302,6 @@ func getDelimFromOptions(opts options.Options) (r rune, ok bool, err error) {
 	return r, true, nil
 }
 
-func newCSVReader(r io.Reader, delim rune) *csv.Reader {
-	// We add the CR filter reader to deal with CSV files exported
-	// from Excel which can have the DOS-style \r EOL markers.
-	cr := csv.NewReader(&crFilterReader{r: r})
-	cr.Comma = delim
-	return cr
-}
-
 // crFilterReader is a reader whose Read method converts
 // standalone carriage return '\r' bytes to newline '\n'.
 // CRLF "\r\n" sequences are untouched.
@@ -303,7 +328,7 @@ func (r *crFilterReader) Read(p []byte) (n int, err error) {
 }
 
 // readRecords reads a maximum of n records from cr.
-func readRecords(cr *csv.Reader, n int) ([][]string, error) {
+func readRecords(cr recordReader, n int) ([][]string, error) {
 	recs := make([][]string, 0, n)
 
 	for range n {
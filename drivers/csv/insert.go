@@ -2,7 +2,6 @@ package csv
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"io"
 
@@ -17,9 +16,11 @@ import (
 )
 
 // execInsert inserts the CSV records in readAheadRecs (followed by records
-// from the csv.Reader) via recw. The caller should wait on recw to complete.
+// from r) via recw. If keep is non-nil, only the fields at
+// those indices are inserted; see driver.IngestSchema.Apply. The caller
+// should wait on recw to complete.
 func execInsert(ctx context.Context, recw libsq.RecordWriter, recMeta record.Meta,
-	mungers []kind.MungeFunc, readAheadRecs [][]string, r *csv.Reader,
+	mungers []kind.MungeFunc, keep []int, readAheadRecs [][]string, r recordReader,
 ) error {
 	ctx, cancelFn := context.WithCancel(ctx)
 	// We don't do "defer cancelFn" here. The cancelFn is passed
@@ -35,7 +36,7 @@ func execInsert(ctx context.Context, recw libsq.RecordWriter, recMeta record.Met
 	// any CSV records we read earlier.
 	for i := range readAheadRecs {
 		var rec []any
-		if rec, err = mungeCSV2InsertRecord(ctx, mungers, readAheadRecs[i]); err != nil {
+		if rec, err = mungeCSV2InsertRecord(ctx, mungers, driver.ProjectIngestFields(keep, readAheadRecs[i])); err != nil {
 			return err
 		}
 
@@ -63,7 +64,7 @@ func execInsert(ctx context.Context, recw libsq.RecordWriter, recMeta record.Met
 		}
 
 		var rec []any
-		if rec, err = mungeCSV2InsertRecord(ctx, mungers, csvRecord); err != nil {
+		if rec, err = mungeCSV2InsertRecord(ctx, mungers, driver.ProjectIngestFields(keep, csvRecord)); err != nil {
 			return err
 		}
 
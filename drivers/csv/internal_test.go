@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
 
 	"github.com/neilotoole/sq/libsq/core/kind"
 	"github.com/neilotoole/sq/libsq/core/stringz"
@@ -84,7 +87,7 @@ func Test_detectColKinds(t *testing.T) {
 
 	for i, tc := range testCases {
 		t.Run(tu.Name(i, tc.name), func(t *testing.T) {
-			gotKinds, _, gotErr := detectColKinds(tc.recs)
+			gotKinds, _, gotErr := detectColKinds(tc.recs, nil)
 			if tc.wantErr {
 				require.Error(t, gotErr)
 				return
@@ -122,3 +125,256 @@ func TestCRFilterReader(t *testing.T) {
 		require.Equal(t, tc.want, string(actual))
 	}
 }
+
+func TestDialectReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		dlct    dialect
+		in      string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			dlct: dialect{delim: ',', quote: '"'},
+			in:   "a,\"b,c\",\"d\"\"e\"\n",
+			want: [][]string{{"a", "b,c", `d"e`}},
+		},
+		{
+			name: "single_quote",
+			dlct: dialect{delim: ',', quote: '\''},
+			in:   "a,'b,c','it''s \"x\"'\n",
+			want: [][]string{{"a", "b,c", `it's "x"`}},
+		},
+		{
+			name: "backslash_escape",
+			dlct: dialect{delim: ',', quote: '"', escape: '\\'},
+			in:   "a,\"say \\\"hi\\\"\",\"c:\\\\tmp\",x\\y\n",
+			want: [][]string{{"a", `say "hi"`, `c:\tmp`, `x\y`}},
+		},
+		{
+			name: "single_quote_backslash_escape",
+			dlct: dialect{delim: ';', quote: '\'', escape: '\\'},
+			in:   "'it\\'s';\"b\"\n",
+			want: [][]string{{"it's", `"b"`}},
+		},
+		{
+			name: "comment_skip_lines",
+			dlct: dialect{delim: ',', quote: '"', comment: '#', skipLines: 2},
+			in:   "Report\n\n# a comment\na,b\n1,2\n",
+			want: [][]string{{"a", "b"}, {"1", "2"}},
+		},
+		{
+			name: "skip_all_lines",
+			dlct: dialect{delim: ',', quote: '"', skipLines: 5},
+			in:   "a,b\n1,2\n",
+			want: [][]string{},
+		},
+		{
+			name: "latin1",
+			dlct: dialect{delim: ',', quote: '"'},
+			in:   "caf\xe9,na\xefve\n",
+			want: [][]string{{"café", "naïve"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := tc.dlct.newReader(strings.NewReader(tc.in))
+			require.NoError(t, err)
+
+			got, err := readRecords(r, 100)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		data      []byte
+		truncated bool
+		want      encoding.Encoding
+	}{
+		{name: "empty", data: nil, want: nil},
+		{name: "ascii", data: []byte("a,b\n"), want: nil},
+		{name: "utf8", data: []byte("café\n"), want: nil},
+		{name: "utf8_bom", data: []byte("\xef\xbb\xbfa,b"), want: unicode.UTF8BOM},
+		{name: "latin1", data: []byte("caf\xe9\n"), want: charmap.Windows1252},
+		// The data is cut off midway through "é".
+		{name: "utf8_truncated", data: []byte("caf\xc3"), truncated: true, want: nil},
+		{name: "latin1_not_truncated", data: []byte("caf\xc3"), want: charmap.Windows1252},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := detectEncoding(tc.data, tc.truncated)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	got := detectEncoding([]byte("\xff\xfea\x00"), false)
+	require.NotNil(t, got)
+	b, err := got.NewDecoder().Bytes([]byte("\xff\xfea\x00,\x00b\x00"))
+	require.NoError(t, err)
+	require.Equal(t, "a,b", string(b))
+}
+
+func TestNullTokens(t *testing.T) {
+	tokens := []string{"NA", "-"}
+	recs := [][]string{{"1", "NA"}, {"-", "x"}}
+	require.Equal(t, [][]string{{"1", ""}, {"", "x"}}, blankNullTokens(recs, tokens))
+	require.Equal(t, [][]string{{"1", "NA"}, {"-", "x"}}, recs)
+
+	mungers := []kind.MungeFunc{nil, kind.MungeEmptyStringAsNil}
+	configureNullTokenMunge(mungers, tokens)
+	for _, fn := range mungers {
+		v, err := fn("NA")
+		require.NoError(t, err)
+		require.Nil(t, v)
+
+		v, err = fn("na")
+		require.NoError(t, err)
+		require.Equal(t, "na", v)
+	}
+}
+
+func TestParseInlineFixedWidthLayout(t *testing.T) {
+	layout, err := parseInlineFixedWidthLayout(
+		"id:1:6:int, name:7:20 ,amount:27:9:decimal:decimals=2,code:36:3:trim=none,at:39:5:time:format=%H:%M")
+	require.NoError(t, err)
+	require.Equal(t, []*fixedWidthColumn{
+		{Name: "id", Start: 1, Width: 6, Kind: kind.Int},
+		{Name: "name", Start: 7, Width: 20},
+		{Name: "amount", Start: 27, Width: 9, Kind: kind.Decimal, Decimals: 2},
+		{Name: "code", Start: 36, Width: 3, Trim: trimNone},
+		{Name: "at", Start: 39, Width: 5, Kind: kind.Time, Format: "%H:%M"},
+	}, layout.Columns)
+
+	for _, val := range []string{
+		"",
+		"id",
+		"id:1",
+		"id:x:6",
+		"id:1:x",
+		"id:0:6",
+		"id:1:-1",
+		"id:1:6:nope",
+		"id:1:6:null",
+		"id:1:6:decimals=-1",
+		"id:1:6:trim=sides",
+		"id:1:6:format=%Y",
+		"id:1:6:color=red",
+		"id:1:6,id:7:6",
+	} {
+		_, err = parseInlineFixedWidthLayout(val)
+		require.Error(t, err, val)
+	}
+}
+
+func TestFixedWidthLayout_split(t *testing.T) {
+	layout := &fixedWidthLayout{Columns: []*fixedWidthColumn{
+		{Name: "a", Start: 1, Width: 4},
+		{Name: "b", Start: 5, Width: 4, Trim: trimLeft},
+		{Name: "c", Start: 9, Width: 4, Trim: trimRight},
+		{Name: "d", Start: 13, Width: 4, Trim: trimNone},
+		{Name: "e", Start: 17, Width: 5, Decimals: 2},
+		{Name: "f", Start: 22},
+	}}
+
+	testCases := []struct {
+		line string
+		want []string
+	}{
+		{line: " ab  cd  ef  gh 0123-rest ", want: []string{"ab", "cd ", " ef", " gh ", "-1.23", "rest"}},
+		{line: "Zoë  Ω", want: []string{"Zoë", "Ω", "", "", "", ""}},
+		{line: "", want: []string{"", "", "", "", "", ""}},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, layout.split(tc.line), tc.line)
+	}
+}
+
+func TestApplyImpliedDecimals(t *testing.T) {
+	testCases := []struct {
+		val  string
+		n    int
+		want string
+	}{
+		{val: "0012345", n: 2, want: "123.45"},
+		{val: "12345", n: 0, want: "12345"},
+		{val: "5", n: 2, want: "0.05"},
+		{val: "000", n: 2, want: "0.00"},
+		{val: "-0012345", n: 2, want: "-123.45"},
+		{val: "+0012345", n: 2, want: "123.45"},
+		{val: "0012345-", n: 2, want: "-123.45"},
+		{val: "0012345+", n: 2, want: "123.45"},
+		{val: "", n: 2, want: ""},
+		{val: "-", n: 2, want: "-"},
+		{val: "12.34", n: 2, want: "12.34"},
+		{val: "N/A", n: 2, want: "N/A"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, applyImpliedDecimals(tc.val, tc.n), "%s %d", tc.val, tc.n)
+	}
+}
+
+func TestInferFixedWidthLayout(t *testing.T) {
+	lines := []string{
+		"  ID NAME         BALANCE",
+		"   1 Alice Smith    12.50",
+		"  22 Bob          -300.00",
+		" 333 Zoë Quinn       7.00",
+	}
+
+	layout, err := inferFixedWidthLayout(lines)
+	require.NoError(t, err)
+	require.Equal(t, []*fixedWidthColumn{
+		{Name: "A", Start: 1, Width: 5},
+		{Name: "B", Start: 6, Width: 13},
+		{Name: "C", Start: 19},
+	}, layout.Columns)
+	require.Equal(t, []string{"1", "Alice Smith", "12.50"}, layout.split(lines[1]))
+
+	_, err = inferFixedWidthLayout([]string{"   ", "\t"})
+	require.Error(t, err)
+	_, err = inferFixedWidthLayout(nil)
+	require.Error(t, err)
+}
+
+func TestFixedWidthReader(t *testing.T) {
+	const input = "a  b\r\n\n1  2\n3  4"
+
+	// The layout is inferred from the first two lines, which are then
+	// returned, followed by the remaining lines.
+	r, err := newFixedWidthReader(strings.NewReader(input), nil, 2)
+	require.NoError(t, err)
+	recs, err := readRecords(r, 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}, recs)
+
+	layout := &fixedWidthLayout{Columns: []*fixedWidthColumn{{Name: "x", Start: 1}}}
+	r, err = newFixedWidthReader(strings.NewReader(input), layout, 2)
+	require.NoError(t, err)
+	recs, err = readRecords(r, 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a  b"}, {"1  2"}, {"3  4"}}, recs)
+
+	_, err = newFixedWidthReader(strings.NewReader("\n\n"), nil, 2)
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer, using EXPLAIN (FORMAT json). DuckDB
+// doesn't report plan costs.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*driver.PlanNode,
+	error,
+) {
+	stmt := "EXPLAIN (FORMAT json) " + query
+	if analyze {
+		stmt = "EXPLAIN (ANALYZE, FORMAT json) " + query
+	}
+
+	// The result is a single row of (explain_key, explain_value).
+	var key, data string
+	if err := conn.QueryRowContext(ctx, stmt).Scan(&key, &data); err != nil {
+		return nil, errw(err)
+	}
+
+	return parsePlan([]byte(data), analyze)
+}
+
+// duckPlanNode is a node of DuckDB's EXPLAIN (FORMAT json) output. The
+// plain output uses field name, whereas the analyzed output uses
+// operator_name and reports operator_cardinality.
+type duckPlanNode struct {
+	OperatorCardinality *float64        `json:"operator_cardinality"`
+	ExtraInfo           map[string]any  `json:"extra_info"`
+	Name                string          `json:"name"`
+	OperatorName        string          `json:"operator_name"`
+	Children            []*duckPlanNode `json:"children"`
+}
+
+// parsePlan parses the output of EXPLAIN (FORMAT json). The plain output
+// is an array of root nodes, whereas the analyzed output is a profiling
+// object whose descendant EXPLAIN_ANALYZE node wraps the plan.
+func parsePlan(data []byte, analyze bool) (*driver.PlanNode, error) {
+	var root *duckPlanNode
+	if analyze {
+		if err := json.Unmarshal(data, &root); err != nil {
+			return nil, errz.Wrap(err, "duckdb: parse query plan")
+		}
+		for root != nil && len(root.Children) == 1 &&
+			(root.OperatorName == "" || root.OperatorName == "EXPLAIN_ANALYZE") {
+			root = root.Children[0]
+		}
+	} else {
+		var roots []*duckPlanNode
+		if err := json.Unmarshal(data, &roots); err != nil {
+			return nil, errz.Wrap(err, "duckdb: parse query plan")
+		}
+		if len(roots) > 0 {
+			root = roots[0]
+		}
+	}
+
+	if root == nil {
+		return nil, errz.New("duckdb: query plan is empty")
+	}
+	return root.normalize(), nil
+}
+
+func (n *duckPlanNode) normalize() *driver.PlanNode {
+	node := &driver.PlanNode{
+		Op:         strings.TrimSpace(n.Name),
+		ActualRows: n.OperatorCardinality,
+	}
+	if node.Op == "" {
+		node.Op = strings.TrimSpace(n.OperatorName)
+	}
+
+	if s, ok := n.ExtraInfo["Estimated Cardinality"].(string); ok {
+		node.EstRows = driver.ParsePlanFloat(s)
+	}
+	if s, ok := n.ExtraInfo["Table"].(string); ok {
+		node.Detail = s
+	} else if s, ok = n.ExtraInfo["Join Type"].(string); ok {
+		node.Detail = s
+	}
+
+	for _, child := range n.Children {
+		node.Children = append(node.Children, child.normalize())
+	}
+	return node
+}
@@ -0,0 +1,49 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+	rawDB, err := sql.Open(dbDrvr, filepath.Join(t.TempDir(), "test.duckdb"))
+	require.NoError(t, err)
+	defer rawDB.Close()
+
+	_, err = rawDB.ExecContext(ctx,
+		`CREATE TABLE t AS SELECT range AS id, 'd' || (range % 3) AS dept FROM range(100)`)
+	require.NoError(t, err)
+
+	conn, err := rawDB.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	const query = `SELECT dept, count(*) FROM t WHERE id > 5 GROUP BY dept`
+	d := &driveri{}
+	for _, analyze := range []bool{false, true} {
+		plan, err := d.Explain(ctx, conn, query, analyze)
+		require.NoError(t, err)
+		require.NotEmpty(t, plan.Op)
+		require.NotEqual(t, "EXPLAIN_ANALYZE", plan.Op)
+
+		// Descend to the scan of t.
+		node := plan
+		for len(node.Children) > 0 {
+			node = node.Children[0]
+		}
+		require.Equal(t, "SEQ_SCAN", node.Op)
+		require.Contains(t, node.Detail, "t")
+		require.NotNil(t, node.EstRows)
+		if analyze {
+			require.NotNil(t, node.ActualRows)
+			require.Equal(t, 94.0, *node.ActualRows)
+		} else {
+			require.Nil(t, node.ActualRows)
+		}
+	}
+}
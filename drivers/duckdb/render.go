@@ -25,6 +25,14 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          jointype.All(),
 		Catalog:        true,
+		Profile: dialect.Profile{
+			CharLength:          "LENGTH",
+			StdDev:              "STDDEV_SAMP",
+			ApproxCountDistinct: "APPROX_COUNT_DISTINCT",
+			Float:               "DOUBLE",
+			Limit:               " LIMIT %d",
+		},
+		RegexpMatch: "regexp_matches",
 	}
 }
 
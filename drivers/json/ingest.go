@@ -46,6 +46,10 @@ type ingestJob struct {
 	//
 	// TODO: flatten should come from src.Options
 	flatten bool
+
+	// colOverrides holds the explicit column overrides specified by
+	// driver.OptIngestSchema. It may be nil.
+	colOverrides *driver.IngestSchema
 }
 
 // Close closes the ingestJob. In particular, it closes any cached statements.
@@ -158,12 +162,22 @@ type processor struct {
 	unwrittenObjVals []objectValueSet
 	// if flattened is true, the JSON object will be flattened into a single table.
 	flatten bool
+
+	// colOverrides holds the explicit column overrides specified by
+	// driver.OptIngestSchema. It may be nil.
+	colOverrides *driver.IngestSchema
+
+	// overrideMungeFns caches the munge func for each column whose kind
+	// is overridden by colOverrides, keyed by (original) column name.
+	overrideMungeFns map[string]kind.MungeFunc
 }
 
-func newProcessor(flatten bool) *processor {
+func newProcessor(flatten bool, colOverrides *driver.IngestSchema) *processor {
 	return &processor{
-		flatten:   flatten,
-		curSchema: nil,
+		flatten:          flatten,
+		colOverrides:     colOverrides,
+		overrideMungeFns: map[string]kind.MungeFunc{},
+		curSchema:        nil,
 		root: &entity{
 			name:      source.MonotableName,
 			detectors: map[string]*kind.Detector{},
@@ -201,6 +215,30 @@ func (p *processor) calcColName(ent *entity, fieldName string) string {
 	return p.calcColName(ent.parent, colName)
 }
 
+// colOverride returns the colOverrides entry for the column colName,
+// as named by calcColName, or nil.
+func (p *processor) colOverride(colName string) *driver.IngestColumn {
+	if p.colOverrides == nil {
+		return nil
+	}
+	return p.colOverrides.Column(source.MonotableName, colName)
+}
+
+// overrideMungeFn returns the munge func for ovr, which must
+// have a kind override. See overrideMungeFns.
+func (p *processor) overrideMungeFn(ovr *driver.IngestColumn) (kind.MungeFunc, error) {
+	if fn, ok := p.overrideMungeFns[ovr.Name]; ok {
+		return fn, nil
+	}
+
+	fn, err := kind.MungeFuncFor(ovr.Kind)
+	if err != nil {
+		return nil, errz.Wrapf(err, "%s: column {%s}", driver.OptIngestSchema.Key(), ovr.Name)
+	}
+	p.overrideMungeFns[ovr.Name] = fn
+	return fn, nil
+}
+
 // buildSchemaFlat currently only builds a flat (single table) schema.
 func (p *processor) buildSchemaFlat() (*ingestSchema, error) {
 	tblDef := &schema.Table{
@@ -208,6 +246,9 @@ func (p *processor) buildSchemaFlat() (*ingestSchema, error) {
 	}
 
 	var colDefs []*schema.Column
+	// fieldColNames holds the name of each of colDefs, as calculated
+	// by calcColName, before any colOverrides rename.
+	var fieldColNames []string
 
 	schma := &ingestSchema{
 		colMungeFns: map[*schema.Column]kind.MungeFunc{},
@@ -230,13 +271,32 @@ func (p *processor) buildSchemaFlat() (*ingestSchema, error) {
 					k = kind.Text
 				}
 
+				colName := p.calcColName(e, field)
+				ovr := p.colOverride(colName)
+				if ovr != nil && ovr.Drop {
+					continue
+				}
+
 				colDef := &schema.Column{
-					Name:  p.calcColName(e, field),
+					Name:  colName,
 					Table: tblDef,
 					Kind:  k,
 				}
 
+				if ovr != nil {
+					if ovr.Kind != kind.Unknown {
+						colDef.Kind = ovr.Kind
+						if mungeFn, err = p.overrideMungeFn(ovr); err != nil {
+							return err
+						}
+					}
+					if ovr.Rename != "" {
+						colDef.Name = ovr.Rename
+					}
+				}
+
 				colDefs = append(colDefs, colDef)
+				fieldColNames = append(fieldColNames, colName)
 				if mungeFn != nil {
 					schma.colMungeFns[colDef] = mungeFn
 				}
@@ -255,7 +315,12 @@ func (p *processor) buildSchemaFlat() (*ingestSchema, error) {
 	// Add the column names, in the correct order
 	for _, colName := range p.colNamesOrdered {
 		for j := range colDefs {
-			if colDefs[j].Name == colName {
+			if fieldColNames[j] == colName {
+				if _, err = tblDef.FindCol(colDefs[j].Name); err == nil {
+					// Only a colOverrides rename can result in a duplicate.
+					return nil, errz.Errorf("%s: duplicate column name {%s}",
+						driver.OptIngestSchema.Key(), colDefs[j].Name)
+				}
 				tblDef.Cols = append(tblDef.Cols, colDefs[j])
 			}
 		}
@@ -365,6 +430,7 @@ func (p *processor) doAddObject(ent *entity, m map[string]any) error {
 			entVals[colName] = val
 
 			colDef := p.getColDef(ent, colName)
+			ovr := p.colOverride(colName)
 
 			if colDef == nil && val != nil {
 				val = maybeFloatToInt(val)
@@ -376,7 +442,9 @@ func (p *processor) doAddObject(ent *entity, m map[string]any) error {
 			// the detector.
 
 			// The column is already defined. Check if the value is allowed.
-			if !p.fieldValAllowed(detector, colDef, val) {
+			// A column whose kind is overridden keeps that kind,
+			// whatever its values.
+			if (ovr == nil || ovr.Kind == kind.Unknown) && !p.fieldValAllowed(detector, colDef, val) {
 				p.markSchemaDirty(ent)
 			}
 		}
@@ -414,6 +482,10 @@ func (p *processor) getColDef(ent *entity, colName string) *schema.Column {
 		return nil
 	}
 
+	if ovr := p.colOverride(colName); ovr != nil && ovr.Rename != "" {
+		colName = ovr.Rename
+	}
+
 	colDef, err := tblDef.FindCol(colName)
 	if err != nil {
 		return nil
@@ -441,6 +513,25 @@ func (p *processor) buildInsertionsFlat(schma *ingestSchema) ([]*insertion, erro
 		for ent, fieldVals := range objValSet {
 			// For each entity, we get its values and add them to colVals.
 			for colName, val := range fieldVals {
+				if ovr := p.colOverride(colName); ovr != nil {
+					if ovr.Drop {
+						continue
+					}
+					if ovr.Kind != kind.Unknown {
+						mungeFn, err := p.overrideMungeFn(ovr)
+						if err != nil {
+							return nil, err
+						}
+						if val, err = mungeFn(val); err != nil {
+							return nil, errz.Wrapf(err, "%s: column {%s}",
+								driver.OptIngestSchema.Key(), ovr.Name)
+						}
+					}
+					if ovr.Rename != "" {
+						colName = ovr.Rename
+					}
+				}
+
 				if _, ok := colVals[colName]; ok {
 					return nil, errz.Errorf("column {%s} already exists, but found column with same name in {%s}",
 						colName, ent)
@@ -191,7 +191,7 @@ func ingestJSON(ctx context.Context, job *ingestJob) error { //nolint:gocognit
 		}
 	}()
 
-	proc := newProcessor(job.flatten)
+	proc := newProcessor(job.flatten, job.colOverrides)
 	scan := newObjectInArrayScanner(log, r)
 
 	var (
@@ -18,6 +18,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/schema"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/core/tuning"
+	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/files"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
@@ -137,6 +138,14 @@ func ingestJSONA(ctx context.Context, job *ingestJob) error {
 		colNames[i] = stringz.GenerateAlphaColName(i, true)
 	}
 
+	keep, err := job.colOverrides.Apply(ctx, source.MonotableName, colNames, colKinds, readMungeFns)
+	if err != nil {
+		return err
+	}
+	colNames = driver.ProjectIngestFields(keep, colNames)
+	colKinds = driver.ProjectIngestFields(keep, colKinds)
+	readMungeFns = driver.ProjectIngestFields(keep, readMungeFns)
+
 	// And now we need to create the dest table in destGrip
 	tblDef := schema.NewTable(source.MonotableName, colNames, colKinds)
 	db, err := job.destGrip.DB(ctx)
@@ -178,7 +187,7 @@ func ingestJSONA(ctx context.Context, job *ingestJob) error {
 
 	// After startInsertJSONA returns, we still need to wait
 	// for the insertWriter to finish.
-	err = startInsertJSONA(ctx, recordCh, errCh, r, readMungeFns)
+	err = startInsertJSONA(ctx, recordCh, errCh, r, readMungeFns, keep)
 	if err != nil {
 		return err
 	}
@@ -197,9 +206,10 @@ func ingestJSONA(ctx context.Context, job *ingestJob) error {
 }
 
 // startInsertJSONA reads JSON records from r and sends
-// them on recordCh.
+// them on recordCh. If keep is non-nil, only the elements at
+// those indices are sent; see driver.IngestSchema.Apply.
 func startInsertJSONA(ctx context.Context, recordCh chan<- record.Record, errCh <-chan error, r io.Reader,
-	mungeFns []kind.MungeFunc,
+	mungeFns []kind.MungeFunc, keep []int,
 ) error {
 	defer close(recordCh)
 
@@ -229,6 +239,7 @@ func startInsertJSONA(ctx context.Context, recordCh chan<- record.Record, errCh
 		if err != nil {
 			return errz.Err(err)
 		}
+		rec = driver.ProjectIngestFields(keep, rec)
 
 		for i := 0; i < len(rec); i++ {
 			fn := mungeFns[i]
@@ -137,7 +137,7 @@ func ingestJSONL(ctx context.Context, job *ingestJob) error { //nolint:gocognit
 		}
 	}()
 
-	proc := newProcessor(job.flatten)
+	proc := newProcessor(job.flatten, job.colOverrides)
 	scan := newLineScanner(ctx, r, '{')
 
 	var (
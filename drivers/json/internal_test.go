@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -123,3 +124,87 @@ func TestCannotBeJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"data":{"items":[{"id":1},{"id":2}]},"meta":{"b":"x","a":"y"},"odd key":3}`
+
+	testCases := []struct {
+		path    string
+		want    []string
+		wantStr string
+		wantErr bool
+	}{
+		{path: "$", want: []string{doc}, wantStr: "$"},
+		{path: "", want: []string{doc}, wantStr: "$"},
+		{path: "$.data.items", want: []string{`[{"id":1},{"id":2}]`}, wantStr: "$['data']['items']"},
+		{path: "$.data.items[*]", want: []string{`{"id":1}`, `{"id":2}`}, wantStr: "$['data']['items'][*]"},
+		{path: "$.data.items[1].id", want: []string{`2`}, wantStr: "$['data']['items'][1]['id']"},
+		{path: "$.data.items[7]", wantStr: "$['data']['items'][7]"},
+		{path: "$.meta.*", want: []string{`"x"`, `"y"`}, wantStr: "$['meta'][*]"},
+		{path: "$['odd key']", want: []string{`3`}, wantStr: "$['odd key']"},
+		{path: "$.missing.id", wantStr: "$['missing']['id']"},
+		{path: "$.data[0]", wantStr: "$['data'][0]"},
+		{path: "$..data", wantErr: true},
+		{path: "$.data[", wantErr: true},
+		{path: "$.data[-1]", wantErr: true},
+		{path: "data", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := parseJSONPath(tc.path)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStr, p.String())
+
+			vals, err := p.selectJSON([]byte(doc))
+			require.NoError(t, err)
+			got := make([]string, 0, len(vals))
+			for _, val := range vals {
+				got = append(got, string(val))
+			}
+			if len(tc.want) == 0 {
+				require.Empty(t, got)
+				return
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		links []string
+		want  string
+	}{
+		{},
+		{links: []string{`<https://api.example.com/users?page=2>; rel="next"`}, want: "https://api.example.com/users?page=2"},
+		{
+			links: []string{`<https://x/?page=1>; rel="prev", <https://x/?page=3>; rel="next", <https://x/?page=9>; rel="last"`},
+			want:  "https://x/?page=3",
+		},
+		{links: []string{`<https://x/?page=1>; rel="prev"`, `</users?page=3>; rel=next`}, want: "/users?page=3"},
+		{links: []string{`<https://x/?page=9>; rel="last"`}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+
+			hdr := http.Header{}
+			for _, link := range tc.links {
+				hdr.Add("Link", link)
+			}
+			require.Equal(t, tc.want, linkNext(hdr))
+		})
+	}
+}
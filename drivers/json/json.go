@@ -3,6 +3,9 @@
 // - JSON: plain old JSON
 // - JSONA: JSON Array, where each record is an array of JSON values on its own line.
 // - JSONL: JSON Lines, where each record a JSON object on its own line.
+//
+// The package also implements the REST driver type, for paginated REST APIs
+// that return JSON: see rest.go.
 package json
 
 import (
@@ -41,7 +44,9 @@ func (d *Provider) DriverFor(typ drivertype.Type) (driver.Driver, error) {
 		ingestFn = ingestJSON
 	case drivertype.JSONA:
 		ingestFn = ingestJSONA
-	case drivertype.JSONL:
+	case drivertype.JSONL, drivertype.REST:
+		// The REST driver's reader emits each fetched record
+		// as a line of JSON: see newRESTReaderFunc.
 		ingestFn = ingestJSONL
 	default:
 		return nil, errz.Errorf("unsupported driver type {%s}", typ)
@@ -77,6 +82,9 @@ func (d *driveri) DriverMetadata() driver.Metadata {
 	case drivertype.JSONL:
 		md.Description = "JSON Lines: LF-delimited JSON objects"
 		md.Doc = "https://en.wikipedia.org/wiki/JSON_streaming#Line-delimited_JSON"
+	case drivertype.REST:
+		md.Description = "REST API: paginated JSON over HTTP"
+		md.Doc = "https://sq.io/docs/drivers/rest"
 	}
 
 	return md
@@ -96,17 +104,31 @@ func (d *driveri) Open(ctx context.Context, src *source.Source, _ driver.AccessM
 
 	allowCache := driver.OptIngestCache.Get(options.FromContext(ctx))
 
+	newRdrFn := func(ctx context.Context) (io.ReadCloser, error) {
+		log.Debug("JSON ingest job newRdrFn", lga.Src, src)
+		return d.files.NewReader(ctx, src, false)
+	}
+	if d.typ == drivertype.REST {
+		// The API's data is live, and there's no file to checksum, so
+		// the ingest cache doesn't apply.
+		allowCache = false
+		newRdrFn = newRESTReaderFunc(d.files, src)
+	}
+
 	ingestFn := func(ctx context.Context, destGrip driver.Grip) error {
+		colOverrides, err := driver.ReadIngestSchema(src)
+		if err != nil {
+			return err
+		}
+
 		job := &ingestJob{
-			fromSrc: src,
-			newRdrFn: func(ctx context.Context) (io.ReadCloser, error) {
-				log.Debug("JSON ingest job newRdrFn", lga.Src, src)
-				return d.files.NewReader(ctx, src, false)
-			},
-			destGrip:   destGrip,
-			sampleSize: driver.OptIngestSampleSize.Get(src.Options),
-			flatten:    true,
-			stmtCache:  map[string]*driver.StmtExecer{},
+			fromSrc:      src,
+			newRdrFn:     newRdrFn,
+			destGrip:     destGrip,
+			sampleSize:   driver.OptIngestSampleSize.Get(src.Options),
+			flatten:      true,
+			colOverrides: colOverrides,
+			stmtCache:    map[string]*driver.StmtExecer{},
 		}
 
 		return d.ingestFn(ctx, job)
@@ -126,11 +148,18 @@ func (d *driveri) ValidateSource(src *source.Source) (*source.Source, error) {
 		return nil, errz.Errorf("expected driver type {%s} but got {%s}", d.typ, src.Type)
 	}
 
+	if d.typ == drivertype.REST && location.TypeOf(src.Location) != location.TypeHTTP {
+		return nil, errz.Errorf("%s: REST API location must be an http or https URL", src.Handle)
+	}
+
 	return src, nil
 }
 
 // Ping implements driver.Driver.
 func (d *driveri) Ping(ctx context.Context, src *source.Source, _ driver.AccessMode) error {
+	if d.typ == drivertype.REST {
+		return pingREST(ctx, d.files, src)
+	}
 	return d.files.Ping(ctx, src)
 }
 
@@ -190,13 +219,20 @@ func (g *grip) SourceMetadata(ctx context.Context, noSchema bool) (*metadata.Sou
 		return nil, err
 	}
 
-	size, err := g.files.Filesize(ctx, g.src)
-	if err != nil {
-		return nil, err
+	if g.src.Type == drivertype.REST {
+		// A REST API has no size: md.Size would otherwise be the size of
+		// the ingest DB.
+		md.Size = nil
+	} else {
+		size, err := g.files.Filesize(ctx, g.src)
+		if err != nil {
+			return nil, err
+		}
+		md.Size = &size
 	}
-	md.Size = &size
 
 	md.FQName = md.Name
+	driver.MarkIngestOverrides(ctx, g.src, md.Tables...)
 	return md, nil
 }
 
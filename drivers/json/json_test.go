@@ -2,18 +2,24 @@ package json_test
 
 import (
 	"context"
+	"database/sql"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/neilotoole/sq/drivers/json"
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/lg/lgt"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
 	"github.com/neilotoole/sq/testh/tu"
 )
 
@@ -125,3 +131,89 @@ func TestDriverDetectorFuncs(t *testing.T) {
 		})
 	}
 }
+
+// TestDriver_MultiFile verifies that a glob source of JSONL files is
+// ingested as the union of its files, and that adding a file re-ingests
+// only that file.
+func TestDriver_MultiFile(t *testing.T) {
+	dir := tu.TempDir(t, "events")
+	writeFile := func(name, data string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+	}
+	writeFile("events-2026-10-01.jsonl", `{"id":1,"kind":"a"}`+"\n"+`{"id":2,"kind":"b"}`+"\n")
+	writeFile("events-2026-10-02.jsonl", `{"id":3,"kind":"c","score":1.5}`+"\n")
+
+	th := testh.New(t, testh.OptCaching(true))
+	src := &source.Source{
+		Handle:   "@events",
+		Type:     drivertype.JSONL,
+		Location: filepath.Join(dir, "events-2026-10-*.jsonl"),
+		Options:  options.Options{driver.OptIngestFileColumn.Key(): true},
+	}
+	ctx := options.NewContext(th.Context, options.Merge(options.FromContext(th.Context), src.Options))
+	drvr, err := th.Grips().DriverFor(src.Type)
+	require.NoError(t, err)
+
+	type row struct {
+		id    int64
+		kind  string
+		score sql.NullFloat64
+		file  string
+	}
+	query := func() []row {
+		grip, err := drvr.Open(ctx, src, driver.ModeReadOnly)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, grip.Close()) }()
+		db, err := grip.DB(ctx)
+		require.NoError(t, err)
+
+		var scoreType string
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT type FROM pragma_table_info('data') WHERE name = 'score'`).Scan(&scoreType))
+		require.Equal(t, "REAL", scoreType)
+
+		rows, err := db.QueryContext(ctx, `SELECT id, kind, score, _file FROM data ORDER BY id`)
+		require.NoError(t, err)
+		defer func() { assert.NoError(t, rows.Close()) }()
+		var got []row
+		for rows.Next() {
+			var r row
+			require.NoError(t, rows.Scan(&r.id, &r.kind, &r.score, &r.file))
+			got = append(got, r)
+		}
+		require.NoError(t, rows.Err())
+		return got
+	}
+
+	want := []row{
+		{id: 1, kind: "a", file: "events-2026-10-01.jsonl"},
+		{id: 2, kind: "b", file: "events-2026-10-01.jsonl"},
+		{id: 3, kind: "c", score: sql.NullFloat64{Float64: 1.5, Valid: true}, file: "events-2026-10-02.jsonl"},
+	}
+	require.Equal(t, want, query())
+
+	// Each file is ingested into its own cache DB.
+	fileCacheDB := func(name string) os.FileInfo {
+		fileSrc := src.Clone()
+		fileSrc.Location = filepath.Join(dir, name)
+		delete(fileSrc.Options, driver.OptIngestFileColumn.Key())
+		_, cacheDB, _, err := th.Files().CachePaths(fileSrc)
+		require.NoError(t, err)
+		fi, err := os.Stat(cacheDB)
+		require.NoError(t, err)
+		return fi
+	}
+	fi1 := fileCacheDB("events-2026-10-01.jsonl")
+
+	writeFile("events-2026-10-03.jsonl", `{"id":4,"kind":"d","score":2}`+"\n")
+	want = append(want, row{
+		id: 4, kind: "d", score: sql.NullFloat64{Float64: 2, Valid: true}, file: "events-2026-10-03.jsonl",
+	})
+	require.Equal(t, want, query())
+
+	// The unchanged file was not ingested again.
+	fi1Again := fileCacheDB("events-2026-10-01.jsonl")
+	require.True(t, os.SameFile(fi1, fi1Again))
+	require.Equal(t, fi1.ModTime(), fi1Again.ModTime())
+	fileCacheDB("events-2026-10-03.jsonl")
+}
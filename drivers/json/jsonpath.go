@@ -0,0 +1,196 @@
+package json
+
+import (
+	"bytes"
+	stdj "encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// jsonPath is a parsed JSONPath expression, supporting the subset of the
+// syntax needed to locate records in an API response:
+//
+//	$                 the root value
+//	.name, ['name']   the named member of an object
+//	[n]               the nth element of an array
+//	[*], .*           every element of an array, or member of an object
+//
+// For example, "$.data.items[*]" or "$['results']".
+type jsonPath []jsonPathStep
+
+// jsonPathStep is a single step of a jsonPath. If wildcard is true, the
+// step selects every child; else if name is non-empty, it selects the named
+// object member; else it selects the array element at index.
+type jsonPathStep struct {
+	name     string
+	index    int
+	wildcard bool
+}
+
+// parseJSONPath parses JSONPath expression s. The leading "$" is optional.
+func parseJSONPath(s string) (jsonPath, error) {
+	expr := strings.TrimSpace(s)
+	expr = strings.TrimPrefix(expr, "$")
+
+	var path jsonPath
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end == -1 {
+				end = len(expr)
+			}
+			name := expr[:end]
+			expr = expr[end:]
+			switch name {
+			case "":
+				return nil, errz.Errorf("invalid JSONPath {%s}: empty member name", s)
+			case "*":
+				path = append(path, jsonPathStep{wildcard: true})
+			default:
+				path = append(path, jsonPathStep{name: name})
+			}
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end == -1 {
+				return nil, errz.Errorf("invalid JSONPath {%s}: unclosed '['", s)
+			}
+			inner := expr[1:end]
+			expr = expr[end+1:]
+			switch {
+			case inner == "*":
+				path = append(path, jsonPathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, jsonPathStep{name: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, errz.Errorf("invalid JSONPath {%s}: invalid index {%s}", s, inner)
+				}
+				path = append(path, jsonPathStep{index: i})
+			}
+		default:
+			return nil, errz.Errorf("invalid JSONPath {%s}", s)
+		}
+	}
+
+	return path, nil
+}
+
+// String returns the canonical form of p.
+func (p jsonPath) String() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, step := range p {
+		switch {
+		case step.wildcard:
+			sb.WriteString("[*]")
+		case step.name != "":
+			sb.WriteString("['" + step.name + "']")
+		default:
+			sb.WriteString("[" + strconv.Itoa(step.index) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// selectJSON returns the values selected by p from JSON document doc. The
+// values are returned as raw JSON, so that the order of object members is
+// preserved. A step that doesn't match (e.g. a member name that's not
+// present) selects nothing; it's not an error.
+func (p jsonPath) selectJSON(doc []byte) ([]stdj.RawMessage, error) {
+	vals := []stdj.RawMessage{doc}
+	for _, step := range p {
+		var next []stdj.RawMessage
+		for _, val := range vals {
+			children, err := step.apply(val)
+			if err != nil {
+				return nil, errz.Wrapf(err, "JSONPath %s", p)
+			}
+			next = append(next, children...)
+		}
+		vals = next
+	}
+	return vals, nil
+}
+
+// apply returns the children of val selected by step.
+func (step jsonPathStep) apply(val stdj.RawMessage) ([]stdj.RawMessage, error) {
+	trimmed := bytes.TrimSpace(val)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	switch trimmed[0] {
+	case '{':
+		if !step.wildcard && step.name == "" {
+			return nil, nil
+		}
+
+		members, keys, err := decodeObjectOrdered(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		if !step.wildcard {
+			if child, ok := members[step.name]; ok {
+				return []stdj.RawMessage{child}, nil
+			}
+			return nil, nil
+		}
+
+		children := make([]stdj.RawMessage, len(keys))
+		for i, k := range keys {
+			children[i] = members[k]
+		}
+		return children, nil
+	case '[':
+		if step.name != "" {
+			return nil, nil
+		}
+
+		var elems []stdj.RawMessage
+		if err := stdj.Unmarshal(trimmed, &elems); err != nil {
+			return nil, errz.Err(err)
+		}
+		if step.wildcard {
+			return elems, nil
+		}
+		if step.index < len(elems) {
+			return []stdj.RawMessage{elems[step.index]}, nil
+		}
+		return nil, nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeObjectOrdered decodes JSON object obj into its members, also
+// returning the member keys in document order.
+func decodeObjectOrdered(obj []byte) (members map[string]stdj.RawMessage, keys []string, err error) {
+	dec := stdj.NewDecoder(bytes.NewReader(obj))
+	if _, err = dec.Token(); err != nil { // The opening '{'.
+		return nil, nil, errz.Err(err)
+	}
+
+	members = map[string]stdj.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, errz.Err(err)
+		}
+		k, _ := tok.(string)
+
+		var v stdj.RawMessage
+		if err = dec.Decode(&v); err != nil {
+			return nil, nil, errz.Err(err)
+		}
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = v
+	}
+	return members, keys, nil
+}
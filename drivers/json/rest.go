@@ -0,0 +1,624 @@
+package json
+
+// rest.go implements the REST driver type, which fetches the records of a
+// paginated JSON API, and ingests them via the JSON Lines ingester.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stdj "encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/httpz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/progress"
+	"github.com/neilotoole/sq/libsq/core/retry"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// Pagination strategies for OptRESTPaginate.
+const (
+	paginateNone   = "none"
+	paginateLink   = "link"
+	paginateCursor = "cursor"
+	paginatePage   = "page"
+	paginateOffset = "offset"
+)
+
+// PaginateStrategies returns the REST API pagination strategies, such as
+// [none, link, cursor...]. See OptRESTPaginate.
+func PaginateStrategies() []string {
+	return []string{paginateNone, paginateLink, paginateCursor, paginatePage, paginateOffset}
+}
+
+var (
+	// OptRESTRecords is the JSONPath that selects the records of a REST
+	// API response.
+	OptRESTRecords = options.NewString(
+		"driver.rest.records",
+		nil,
+		"$",
+		func(s string) error {
+			_, err := parseJSONPath(s)
+			return err
+		},
+		"JSONPath of REST API records",
+		`JSONPath expression that selects the records in each page of a REST API
+response, e.g. "$.data" or "$.results[*]". Each record must be a JSON object.
+A selected array is expanded into its elements. The supported syntax is:
+
+  $                 the response document
+  .name, ['name']   the named member of an object
+  [n]               the nth element of an array
+  [*], .*           every element of an array, or member of an object`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTPaginate is the pagination strategy of a REST API.
+	OptRESTPaginate = options.NewString(
+		"driver.rest.paginate",
+		nil,
+		paginateLink,
+		func(s string) error {
+			if !slices.Contains(PaginateStrategies(), s) {
+				return errz.Errorf("invalid pagination strategy {%s}: expected one of: %s",
+					s, strings.Join(PaginateStrategies(), ", "))
+			}
+			return nil
+		},
+		"Pagination strategy of REST API",
+		`Pagination strategy of a REST API. Allowed values:
+
+  none     The API returns all records in a single response.
+  link     The next page's URL is given by the rel="next" entry of the
+           response's Link header (RFC 8288), as used by GitHub, GitLab etc.
+  cursor   The response contains a cursor (see driver.rest.cursor.path),
+           sent in query param driver.rest.cursor.param to get the next
+           page. If the cursor is a URL, it is the next page's URL.
+  page     The page number is sent in query param driver.rest.page.param,
+           starting at driver.rest.page.start.
+  offset   The offset of the page's first record is sent in query param
+           driver.rest.offset.param.
+
+For page and offset, fetching stops at the first page that has no records,
+or fewer than driver.rest.limit records. For link and cursor, a next page URL
+must be on the same host as the source location.`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTCursorPath is the JSONPath of the next page's cursor.
+	OptRESTCursorPath = options.NewString(
+		"driver.rest.cursor.path",
+		nil,
+		"",
+		func(s string) error {
+			if s == "" {
+				return nil
+			}
+			_, err := parseJSONPath(s)
+			return err
+		},
+		"JSONPath of REST API next page cursor",
+		`JSONPath expression that selects the next page's cursor in a REST API
+response, e.g. "$.meta.next_cursor", when driver.rest.paginate is "cursor".
+Fetching stops when the cursor is missing, null, or empty.`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTCursorParam is the query param that carries the cursor.
+	OptRESTCursorParam = options.NewString(
+		"driver.rest.cursor.param",
+		nil,
+		"cursor",
+		nil,
+		"Query param for REST API cursor",
+		`Name of the query param that carries the cursor, when driver.rest.paginate
+is "cursor".`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTPageParam is the query param that carries the page number.
+	OptRESTPageParam = options.NewString(
+		"driver.rest.page.param",
+		nil,
+		"page",
+		nil,
+		"Query param for REST API page number",
+		`Name of the query param that carries the page number, when
+driver.rest.paginate is "page".`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTPageStart is the number of the first page.
+	OptRESTPageStart = options.NewInt(
+		"driver.rest.page.start",
+		nil,
+		1,
+		"Number of REST API first page",
+		`Number of the first page, when driver.rest.paginate is "page". Typically
+0 or 1.`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTOffsetParam is the query param that carries the record offset.
+	OptRESTOffsetParam = options.NewString(
+		"driver.rest.offset.param",
+		nil,
+		"offset",
+		nil,
+		"Query param for REST API offset",
+		`Name of the query param that carries the offset of the page's first
+record, when driver.rest.paginate is "offset".`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTLimit is the page size requested of a REST API.
+	OptRESTLimit = options.NewInt(
+		"driver.rest.limit",
+		nil,
+		0,
+		"Page size of REST API",
+		`Number of records per page, sent in query param driver.rest.limit.param.
+If zero, the param is not sent, and the API's default page size applies.`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTLimitParam is the query param that carries the page size.
+	OptRESTLimitParam = options.NewString(
+		"driver.rest.limit.param",
+		nil,
+		"limit",
+		nil,
+		"Query param for REST API page size",
+		`Name of the query param that carries driver.rest.limit, e.g. "per_page".`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTMaxPages is the maximum number of pages fetched.
+	OptRESTMaxPages = options.NewInt(
+		"driver.rest.max-pages",
+		nil,
+		10000,
+		"Max pages fetched from REST API",
+		`Maximum number of pages fetched from a REST API. If the API has more
+pages, the ingest fails, rather than silently returning partial data. This
+guards against an API whose pagination never terminates. If zero, there is
+no limit.`,
+		options.TagSource,
+		"rest",
+	)
+
+	// OptRESTRetryTimeout is the time allowed for retrying a request.
+	OptRESTRetryTimeout = options.NewDuration(
+		"driver.rest.retry.timeout",
+		nil,
+		time.Minute,
+		"Max time to retry REST API request",
+		`Maximum time to retry a REST API request whose response status is
+429 (Too Many Requests) or 5xx, with backoff between attempts. If zero,
+the request is not retried.`,
+		options.TagSource,
+		"rest",
+	)
+)
+
+// restFetcher fetches the records of a REST API source.
+type restFetcher struct {
+	c        *http.Client
+	baseURL  *url.URL
+	handle   string
+	paginate string
+	records  jsonPath
+
+	cursorPath  jsonPath
+	cursorParam string
+	pageParam   string
+	offsetParam string
+	limitParam  string
+
+	pageStart    int
+	limit        int
+	maxPages     int
+	retryTimeout time.Duration
+}
+
+// newRESTFetcher returns a restFetcher for src, configured per the
+// options in ctx and src.Options.
+func newRESTFetcher(ctx context.Context, fs *files.Files, src *source.Source) (*restFetcher, error) {
+	o := options.Merge(options.FromContext(ctx), src.Options)
+
+	baseURL, err := url.Parse(src.Location)
+	if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") {
+		return nil, errz.Errorf("%s: REST API location must be an http or https URL", src.Handle)
+	}
+
+	f := &restFetcher{
+		baseURL:      baseURL,
+		handle:       src.Handle,
+		paginate:     OptRESTPaginate.Get(o),
+		cursorParam:  OptRESTCursorParam.Get(o),
+		pageParam:    OptRESTPageParam.Get(o),
+		offsetParam:  OptRESTOffsetParam.Get(o),
+		limitParam:   OptRESTLimitParam.Get(o),
+		pageStart:    OptRESTPageStart.Get(o),
+		limit:        OptRESTLimit.Get(o),
+		maxPages:     OptRESTMaxPages.Get(o),
+		retryTimeout: OptRESTRetryTimeout.Get(o),
+	}
+
+	if f.records, err = parseJSONPath(OptRESTRecords.Get(o)); err != nil {
+		return nil, errz.Wrapf(err, "%s: %s", src.Handle, OptRESTRecords.Key())
+	}
+
+	if f.paginate == paginateCursor {
+		cursorPath := OptRESTCursorPath.Get(o)
+		if cursorPath == "" {
+			return nil, errz.Errorf("%s: %s is %q, but %s is not set", src.Handle,
+				OptRESTPaginate.Key(), paginateCursor, OptRESTCursorPath.Key())
+		}
+		if f.cursorPath, err = parseJSONPath(cursorPath); err != nil {
+			return nil, errz.Wrapf(err, "%s: %s", src.Handle, OptRESTCursorPath.Key())
+		}
+	}
+
+	if f.c, err = fs.HTTPClientFor(ctx, src); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// firstURL returns the URL of the first page.
+func (f *restFetcher) firstURL() string {
+	switch f.paginate {
+	case paginatePage:
+		return f.withParam(f.pageParam, strconv.Itoa(f.pageStart))
+	case paginateOffset:
+		return f.withParam(f.offsetParam, "0")
+	default:
+		return f.withParam("", "")
+	}
+}
+
+// withParam returns the base URL, with query param name set to val (if name
+// is non-empty), and the limit param set if configured.
+func (f *restFetcher) withParam(name, val string) string {
+	u := *f.baseURL
+	q := u.Query()
+	if name != "" {
+		q.Set(name, val)
+	}
+	if f.limit > 0 {
+		q.Set(f.limitParam, strconv.Itoa(f.limit))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// fetchAll fetches each page of the API, writing each record to w as a
+// line of JSON.
+func (f *restFetcher) fetchAll(ctx context.Context, w io.Writer) error {
+	log := lg.FromContext(ctx)
+	bar := progress.FromContext(ctx).NewUnitCounter("Fetch REST API pages", "page")
+	defer bar.Stop()
+
+	var (
+		pageURL = f.firstURL()
+		offset  int
+		buf     bytes.Buffer
+	)
+
+	for page := 0; pageURL != ""; page++ {
+		if f.maxPages > 0 && page >= f.maxPages {
+			return errz.Errorf("%s: REST API has more than %d pages: see option %s",
+				f.handle, f.maxPages, OptRESTMaxPages.Key())
+		}
+
+		body, hdr, err := f.get(ctx, pageURL)
+		if err != nil {
+			return err
+		}
+		bar.Incr(1)
+
+		recs, err := f.selectRecords(body)
+		if err != nil {
+			return errz.Wrapf(err, "%s: page %d", f.handle, page+1)
+		}
+
+		for _, rec := range recs {
+			buf.Reset()
+			if err = stdj.Compact(&buf, rec); err != nil {
+				return errz.Wrapf(err, "%s: page %d", f.handle, page+1)
+			}
+			buf.WriteByte('\n')
+			if _, err = w.Write(buf.Bytes()); err != nil {
+				return errz.Err(err)
+			}
+		}
+
+		log.Debug("Fetched REST API page", lga.Index, page, lga.Count, len(recs))
+
+		offset += len(recs)
+		if pageURL, err = f.nextURL(pageURL, hdr, body, page, len(recs), offset); err != nil {
+			return errz.Wrapf(err, "%s: page %d", f.handle, page+1)
+		}
+	}
+
+	return nil
+}
+
+// selectRecords returns the records of API response body. A selected array
+// is expanded into its elements. It's an error if a record is not a JSON
+// object.
+func (f *restFetcher) selectRecords(body []byte) ([]stdj.RawMessage, error) {
+	vals, err := f.records.selectJSON(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []stdj.RawMessage
+	for _, val := range vals {
+		val = bytes.TrimSpace(val)
+		if len(val) > 0 && val[0] == '[' {
+			var elems []stdj.RawMessage
+			if err = stdj.Unmarshal(val, &elems); err != nil {
+				return nil, errz.Err(err)
+			}
+			recs = append(recs, elems...)
+			continue
+		}
+		recs = append(recs, val)
+	}
+
+	for _, rec := range recs {
+		if rec = bytes.TrimSpace(rec); len(rec) == 0 || rec[0] != '{' {
+			return nil, errz.Errorf("record selected by %s is not a JSON object: %s",
+				f.records, stringz.Ellipsify(string(rec), 40))
+		}
+	}
+	return recs, nil
+}
+
+// nextURL returns the URL of the page after the page at pageURL, or empty
+// string if there are no more pages. Arg page is the zero-based index of
+// the page, count is the number of records on the page, and offset is the
+// total number of records so far.
+func (f *restFetcher) nextURL(pageURL string, hdr http.Header, body []byte, page, count, offset int) (string,
+	error,
+) {
+	switch f.paginate {
+	case paginateLink:
+		next := linkNext(hdr)
+		if next == "" {
+			return "", nil
+		}
+		return f.resolveURL(pageURL, next)
+
+	case paginateCursor:
+		cursor, err := f.cursor(body)
+		switch {
+		case err != nil:
+			return "", err
+		case cursor == "":
+			return "", nil
+		case strings.HasPrefix(cursor, "http://"), strings.HasPrefix(cursor, "https://"), strings.HasPrefix(cursor, "/"):
+			return f.resolveURL(pageURL, cursor)
+		default:
+			return f.withParam(f.cursorParam, cursor), nil
+		}
+
+	case paginatePage, paginateOffset:
+		if count == 0 || (f.limit > 0 && count < f.limit) {
+			return "", nil
+		}
+		if f.paginate == paginatePage {
+			return f.withParam(f.pageParam, strconv.Itoa(f.pageStart+page+1)), nil
+		}
+		return f.withParam(f.offsetParam, strconv.Itoa(offset)), nil
+
+	default:
+		return "", nil
+	}
+}
+
+// cursor returns the next page's cursor from response body, or empty string
+// if there's none.
+func (f *restFetcher) cursor(body []byte) (string, error) {
+	vals, err := f.cursorPath.selectJSON(body)
+	if err != nil || len(vals) == 0 {
+		return "", err
+	}
+
+	var v any
+	dec := stdj.NewDecoder(bytes.NewReader(vals[0]))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return "", errz.Err(err)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case stdj.Number:
+		return v.String(), nil
+	default:
+		return "", errz.Errorf("cursor selected by %s is not a string or number", f.cursorPath)
+	}
+}
+
+// restStatusError is returned by restFetcher.get for a response whose
+// status is not 2xx.
+type restStatusError struct {
+	url  string
+	code int
+}
+
+// Error implements error.
+func (e *restStatusError) Error() string {
+	return "GET " + location.Redact(e.url) + ": " + httpz.StatusText(e.code)
+}
+
+// isRetryable is a retry.MatchFunc that matches a restStatusError whose
+// status indicates that the request may succeed if retried.
+func isRetryable(err error) bool {
+	var statusErr *restStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+}
+
+// get returns the body and header of the response to a GET of pageURL. A
+// request that fails with a retryable status (see isRetryable) is retried
+// with backoff, per OptRESTRetryTimeout.
+func (f *restFetcher) get(ctx context.Context, pageURL string) (body []byte, hdr http.Header, err error) {
+	fetch := func() error {
+		body, hdr, err = f.doGet(ctx, pageURL)
+		return err
+	}
+
+	if f.retryTimeout <= 0 {
+		err = fetch()
+	} else {
+		err = retry.Do(ctx, f.retryTimeout, fetch, isRetryable)
+	}
+	if err != nil {
+		return nil, nil, errz.Wrapf(err, "%s", f.handle)
+	}
+	return body, hdr, nil
+}
+
+// doGet performs a single GET of pageURL.
+func (f *restFetcher) doGet(ctx context.Context, pageURL string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, nil, errz.Err(err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.c.Do(req)
+	if err != nil {
+		return nil, nil, errz.Err(err)
+	}
+	defer lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseHTTPResponseBody, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &restStatusError{url: pageURL, code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errz.Wrapf(err, "read response: %s", location.Redact(pageURL))
+	}
+	return body, resp.Header, nil
+}
+
+// newRESTReaderFunc returns a files.NewReaderFunc that streams the records
+// of REST API source src as JSON lines, fetching the pages as the reader
+// is consumed.
+func newRESTReaderFunc(fs *files.Files, src *source.Source) files.NewReaderFunc {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		f, err := newRESTFetcher(ctx, fs, src)
+		if err != nil {
+			return nil, err
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			bw := bufio.NewWriter(pw)
+			err := f.fetchAll(ctx, bw)
+			if err == nil {
+				err = errz.Err(bw.Flush())
+			}
+			// If err is nil, the reader gets io.EOF.
+			_ = pw.CloseWithError(err)
+		}()
+		return pr, nil
+	}
+}
+
+// pingREST pings REST API source src by fetching its first page.
+func pingREST(ctx context.Context, fs *files.Files, src *source.Source) error {
+	f, err := newRESTFetcher(ctx, fs, src)
+	if err != nil {
+		return errz.Wrapf(err, "ping")
+	}
+
+	if _, _, err = f.doGet(ctx, f.firstURL()); err != nil {
+		return errz.Wrapf(err, "ping: %s", src.Handle)
+	}
+	return nil
+}
+
+// linkNext returns the URL of the rel="next" entry of the Link headers in
+// hdr, or empty string if there's none. See RFC 8288.
+func linkNext(hdr http.Header) string {
+	for _, val := range hdr.Values("Link") {
+		for _, link := range strings.Split(val, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(k), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(v), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resolveURL resolves next page URL ref, which may be relative, against
+// base. It's an error if the resolved URL's scheme or host differs from
+// the source's: the request would carry the source's credentials.
+func (f *restFetcher) resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", errz.Err(err)
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", errz.Wrapf(err, "invalid next page URL")
+	}
+
+	u := b.ResolveReference(r)
+	if u.Scheme != f.baseURL.Scheme || !strings.EqualFold(u.Host, f.baseURL.Host) {
+		return "", errz.Errorf("next page URL {%s://%s} is not on the source's host {%s://%s}",
+			u.Scheme, u.Host, f.baseURL.Scheme, f.baseURL.Host)
+	}
+	return u.String(), nil
+}
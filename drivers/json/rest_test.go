@@ -0,0 +1,281 @@
+package json_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqjson "github.com/neilotoole/sq/drivers/json"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// restUsers is the data served by newRESTServer.
+var restUsers = []map[string]any{
+	{"id": 1, "name": "ada"},
+	{"id": 2, "name": "grace"},
+	{"id": 3, "name": "edsger"},
+	{"id": 4, "name": "barbara"},
+	{"id": 5, "name": "donald"},
+}
+
+// newRESTServer returns a server that serves restUsers, paginated in pages
+// of two records, per the pagination strategy given by the path, e.g.
+// "/link/users". The server requires header "Authorization: Bearer
+// s3cr3t", and fails the first request to each page with 429 if the path
+// is prefixed with "/flaky".
+func newRESTServer(t *testing.T) *httptest.Server {
+	const pageSize = 2
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := r.URL.Path
+		if path == "/flaky/page/users" {
+			mu.Lock()
+			attempts[r.URL.RawQuery]++
+			n := attempts[r.URL.RawQuery]
+			mu.Unlock()
+			if n == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			path = "/page/users"
+		}
+
+		q := r.URL.Query()
+		atoi := func(s string) int {
+			i, _ := strconv.Atoi(s)
+			return i
+		}
+		page := func(start int) []map[string]any {
+			if start >= len(restUsers) {
+				return []map[string]any{}
+			}
+			return restUsers[start:min(start+pageSize, len(restUsers))]
+		}
+
+		var body any
+		switch path {
+		case "/none/users":
+			body = restUsers
+		case "/link/users":
+			start := atoi(q.Get("start"))
+			if start+pageSize < len(restUsers) {
+				w.Header().Set("Link", fmt.Sprintf(`</link/users?start=%d>; rel="next"`, start+pageSize))
+			}
+			body = page(start)
+		case "/cursor/users":
+			start := atoi(q.Get("after"))
+			next := any(nil)
+			if start+pageSize < len(restUsers) {
+				next = strconv.Itoa(start + pageSize)
+			}
+			body = map[string]any{"data": page(start), "meta": map[string]any{"next": next}}
+		case "/page/users":
+			body = map[string]any{"results": page((atoi(q.Get("page")) - 1) * pageSize)}
+		case "/offset/users":
+			body = map[string]any{"results": page(atoi(q.Get("offset")))}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(srvr.Close)
+	return srvr
+}
+
+func TestDriver_REST(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		opts    options.Options
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "none", path: "/none/users", want: 5,
+			opts: options.Options{sqjson.OptRESTPaginate.Key(): "none"},
+		},
+		{name: "link", path: "/link/users", want: 5},
+		{
+			name: "cursor", path: "/cursor/users", want: 5,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key():    "cursor",
+				sqjson.OptRESTRecords.Key():     "$.data",
+				sqjson.OptRESTCursorPath.Key():  "$.meta.next",
+				sqjson.OptRESTCursorParam.Key(): "after",
+			},
+		},
+		{
+			name: "page", path: "/page/users", want: 5,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key(): "page",
+				sqjson.OptRESTRecords.Key():  "$.results[*]",
+			},
+		},
+		{
+			name: "offset", path: "/offset/users", want: 5,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key(): "offset",
+				sqjson.OptRESTRecords.Key():  "$.results",
+				sqjson.OptRESTLimit.Key():    2,
+			},
+		},
+		{
+			name: "retry", path: "/flaky/page/users", want: 5,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key(): "page",
+				sqjson.OptRESTRecords.Key():  "$.results",
+			},
+		},
+		{
+			name: "no_retry", path: "/flaky/page/users", wantErr: true,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key():     "page",
+				sqjson.OptRESTRecords.Key():      "$.results",
+				sqjson.OptRESTRetryTimeout.Key(): time.Duration(0),
+			},
+		},
+		{
+			name: "max_pages", path: "/link/users", wantErr: true,
+			opts: options.Options{sqjson.OptRESTMaxPages.Key(): 2},
+		},
+		{
+			name: "records_not_objects", path: "/cursor/users", wantErr: true,
+			opts: options.Options{
+				sqjson.OptRESTPaginate.Key(): "none",
+				sqjson.OptRESTRecords.Key():  "$.data[*].id",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srvr := newRESTServer(t)
+			th := testh.New(t)
+			opts := options.Options{files.OptHTTPAuthBearer.Key(): "s3cr3t"}
+			for k, v := range tc.opts {
+				opts[k] = v
+			}
+			src := &source.Source{
+				Handle:   "@users_" + tc.name,
+				Type:     drivertype.REST,
+				Location: srvr.URL + tc.path,
+				Options:  opts,
+			}
+			ctx := options.NewContext(th.Context, options.Merge(options.FromContext(th.Context), src.Options))
+			drvr, err := th.Grips().DriverFor(src.Type)
+			require.NoError(t, err)
+
+			src, err = drvr.ValidateSource(src)
+			require.NoError(t, err)
+
+			grip, err := drvr.Open(ctx, src, driver.ModeReadOnly)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			t.Cleanup(func() { assert.NoError(t, grip.Close()) })
+
+			db, err := grip.DB(ctx)
+			require.NoError(t, err)
+			rows, err := db.QueryContext(ctx, `SELECT id, name FROM data ORDER BY id`)
+			require.NoError(t, err)
+			defer func() { assert.NoError(t, rows.Close()) }()
+
+			var got int
+			for rows.Next() {
+				var id int64
+				var name string
+				require.NoError(t, rows.Scan(&id, &name))
+				require.Equal(t, restUsers[got]["id"], int(id))
+				require.Equal(t, restUsers[got]["name"], name)
+				got++
+			}
+			require.NoError(t, rows.Err())
+			require.Equal(t, tc.want, got)
+
+			md, err := grip.SourceMetadata(ctx, false)
+			require.NoError(t, err)
+			require.Nil(t, md.Size)
+		})
+	}
+}
+
+// TestDriver_REST_crossHostNext verifies that pagination doesn't follow a
+// next page link to another host.
+func TestDriver_REST_crossHostNext(t *testing.T) {
+	var otherHits atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		otherHits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(other.Close)
+
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Link", "<"+other.URL+`/users?start=2>; rel="next"`)
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(restUsers[:2]))
+	}))
+	t.Cleanup(srvr.Close)
+
+	th := testh.New(t)
+	src := &source.Source{
+		Handle:   "@users_cross_host",
+		Type:     drivertype.REST,
+		Location: srvr.URL + "/users",
+		Options:  options.Options{files.OptHTTPAuthBearer.Key(): "s3cr3t"},
+	}
+	drvr, err := th.Grips().DriverFor(src.Type)
+	require.NoError(t, err)
+	src, err = drvr.ValidateSource(src)
+	require.NoError(t, err)
+
+	_, err = drvr.Open(th.Context, src, driver.ModeReadOnly)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not on the source's host")
+	require.Zero(t, otherHits.Load())
+}
+
+func TestDriver_REST_Ping(t *testing.T) {
+	srvr := newRESTServer(t)
+	th := testh.New(t)
+	drvr, err := th.Grips().DriverFor(drivertype.REST)
+	require.NoError(t, err)
+
+	src := &source.Source{Handle: "@users", Type: drivertype.REST, Location: srvr.URL + "/link/users"}
+	require.Error(t, drvr.Ping(th.Context, src, driver.ModeReadOnly), "missing bearer token")
+
+	src.Options = options.Options{files.OptHTTPAuthBearer.Key(): "s3cr3t"}
+	require.NoError(t, drvr.Ping(th.Context, src, driver.ModeReadOnly))
+
+	src.Location = "/tmp/users.json"
+	_, err = drvr.ValidateSource(src)
+	require.Error(t, err, "REST source must be a URL")
+}
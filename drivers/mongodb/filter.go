@@ -0,0 +1,143 @@
+package mongodb
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+// mongoOps maps the operators of driver.FilterPred to MongoDB query
+// operators.
+var mongoOps = map[string]string{
+	"==": "$eq",
+	"!=": "$ne",
+	"<":  "$lt",
+	"<=": "$lte",
+	">":  "$gt",
+	">=": "$gte",
+}
+
+// numericTypes are the BSON types of the values of a numeric column that
+// compare the same in MongoDB as in the ingest DB.
+var numericTypes = []string{"int", "long", "double"}
+
+// objectIDRx matches the hex form of an ObjectID, as it's ingested.
+var objectIDRx = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// mongoFilter returns the MongoDB query filter for the predicates of
+// filter on the columns of ts, the collection's own table. The returned
+// filter selects every document whose row satisfies filter, once ingested,
+// and perhaps some others: the query's WHERE clause is applied to the
+// ingested rows in any case.
+//
+// Because the table's schema is inferred from a sample, a document beyond
+// the sample may hold a value of another type than expected, which the
+// ingest DB compares differently than MongoDB does; or, that can't be
+// converted, and is ingested as NULL. Thus, for each predicate, a document
+// is also selected if its value isn't of the column's sampled type. A
+// predicate on a column whose sampled values are of mixed types, or on a
+// datetime column, is omitted, as is a predicate that a column is NULL.
+//
+// If there are no predicates that can be pushed down, nil is returned.
+func mongoFilter(ts *tblSchema, filter *driver.Filter) bson.D {
+	if filter == nil || ts.isChild() {
+		return nil
+	}
+
+	var conds bson.A
+	for _, pred := range filter.Preds {
+		if cond := mongoCond(ts, pred); cond != nil {
+			conds = append(conds, cond)
+		}
+	}
+
+	switch len(conds) {
+	case 0:
+		return nil
+	case 1:
+		return conds[0].(bson.D)
+	default:
+		return bson.D{{Key: "$and", Value: conds}}
+	}
+}
+
+// mongoCond returns the MongoDB query condition for pred, or nil if pred
+// can't be pushed down. See mongoFilter.
+func mongoCond(ts *tblSchema, pred driver.FilterPred) bson.D {
+	col, ok := ts.colsByName[pred.Col]
+	if !ok || col.synthetic {
+		return nil
+	}
+	op, ok := mongoOps[pred.Op]
+	if !ok {
+		return nil
+	}
+	field := strings.Join(col.path, ".")
+
+	if pred.Val == nil {
+		if op != "$ne" {
+			return nil
+		}
+		// A NULL value is ingested for a missing field, as for a null
+		// value.
+		return bson.D{{Key: field, Value: bson.D{{Key: op, Value: nil}}}}
+	}
+
+	val, types := mongoCondValue(col, pred.Val)
+	if types == nil {
+		return nil
+	}
+
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: field, Value: bson.D{{Key: op, Value: val}}}},
+		bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$type", Value: types}}}}}},
+	}}}
+}
+
+// mongoCondValue returns predicate value v, converted for comparison with
+// the values of col in MongoDB, and the BSON types of the values that
+// compare the same in MongoDB as in the ingest DB. If v can't be compared
+// in MongoDB, types is nil.
+func mongoCondValue(col *colSchema, v any) (val any, types []string) {
+	switch col.kind {
+	case kind.Int, kind.Float:
+		if !col.hasOnlyTypes(numericTypes...) {
+			return nil, nil
+		}
+		switch v.(type) {
+		case int64, float64:
+			return v, numericTypes
+		}
+	case kind.Bool:
+		if v, ok := v.(bool); ok {
+			return v, []string{"bool"}
+		}
+	case kind.Text:
+		s, ok := v.(string)
+		if !ok {
+			return nil, nil
+		}
+		switch {
+		case col.hasOnlyTypes("string"):
+			return s, []string{"string"}
+		case col.hasOnlyTypes("objectId"):
+			// The ObjectID is ingested as lowercase hex, whose order is
+			// the same as the ObjectID's.
+			if !objectIDRx.MatchString(s) {
+				return nil, nil
+			}
+			id, err := bson.ObjectIDFromHex(s)
+			if err != nil {
+				return nil, nil
+			}
+			return id, []string{"objectId"}
+		}
+	default:
+	}
+
+	return nil, nil
+}
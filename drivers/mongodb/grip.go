@@ -0,0 +1,399 @@
+package mongodb
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	mopts "go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/connstring"
+	"golang.org/x/mod/semver"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+var (
+	_ driver.Grip          = (*grip)(nil)
+	_ driver.TablePreparer = (*grip)(nil)
+)
+
+// grip implements driver.Grip for MongoDB. The collections' documents are
+// ingested into the backing scratch DB on demand, via PrepareTable. If DB
+// is invoked without any prior call to PrepareTable, such as by "sq sql",
+// every collection is ingested in full.
+type grip struct {
+	log     *slog.Logger
+	src     *source.Source
+	client  *mongo.Client
+	db      *mongo.Database
+	backing driver.Grip
+
+	// schemas holds the sampled schema of each collection, keyed by
+	// collection name.
+	schemas map[string]*collSchema
+
+	// ingested holds the ingest state of each ingested collection, keyed
+	// by collection name.
+	ingested map[string]*ingestState
+
+	closeErr error
+
+	// colls holds the names of the database's collections, once listed.
+	colls []string
+
+	sampleSize int
+	mu         sync.Mutex
+	closeOnce  sync.Once
+
+	// prepared is true if PrepareTable has been invoked.
+	prepared bool
+}
+
+// ingestState records how a collection was ingested.
+type ingestState struct {
+	// filter is the MongoDB query filter that selected the ingested
+	// documents. If nil, every document was ingested.
+	filter bson.D
+}
+
+// DB implements driver.Grip.
+func (g *grip) DB(ctx context.Context) (*sql.DB, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.prepared {
+		colls, err := g.listColls(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, coll := range colls {
+			if err = g.prepareColl(ctx, coll, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return g.backing.DB(ctx)
+}
+
+// PrepareTable implements driver.TablePreparer. The documents of the
+// table's collection are ingested, if they haven't been already. If
+// filter is non-nil, and tbl is a collection's own table, only the
+// documents selected by the equivalent MongoDB query filter are ingested.
+// A collection previously ingested with a different filter is re-ingested.
+func (g *grip) PrepareTable(ctx context.Context, tbl string, filter *driver.Filter) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.prepared = true
+	cs, ts, err := g.resolveTable(ctx, tbl)
+	if err != nil {
+		return err
+	}
+
+	return g.prepareColl(ctx, cs.coll, mongoFilter(ts, filter))
+}
+
+// prepareColl ingests the documents of collection coll that are selected
+// by mfilter, or all documents if mfilter is nil, unless the collection's
+// current ingest state already includes those documents.
+func (g *grip) prepareColl(ctx context.Context, coll string, mfilter bson.D) error {
+	if state, ok := g.ingested[coll]; ok {
+		if state.filter == nil || (mfilter != nil && filtersEqual(state.filter, mfilter)) {
+			return nil
+		}
+	}
+
+	cs, err := g.collSchema(ctx, coll)
+	if err != nil {
+		return err
+	}
+
+	if err = g.ingestColl(ctx, cs, mfilter); err != nil {
+		return err
+	}
+
+	g.ingested[coll] = &ingestState{filter: mfilter}
+	return nil
+}
+
+// resolveTable returns the collection schema and table schema for table
+// tbl, which is either a collection, or the child table of a collection's
+// array field.
+func (g *grip) resolveTable(ctx context.Context, tbl string) (*collSchema, *tblSchema, error) {
+	colls, err := g.listColls(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if slices.Contains(colls, tbl) {
+		cs, err := g.collSchema(ctx, tbl)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cs, cs.root(), nil
+	}
+
+	for _, coll := range colls {
+		if !strings.HasPrefix(tbl, coll+colScopeSep) {
+			continue
+		}
+
+		cs, err := g.collSchema(ctx, coll)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ts := cs.table(tbl); ts != nil {
+			return cs, ts, nil
+		}
+	}
+
+	return nil, nil, errz.Errorf("%s: table not found: %s", g.src.Handle, tbl)
+}
+
+// listColls returns the names of the database's collections, in
+// alphabetical order. System collections are omitted.
+func (g *grip) listColls(ctx context.Context) ([]string, error) {
+	if g.colls != nil {
+		return g.colls, nil
+	}
+
+	names, err := g.db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, errw(err)
+	}
+
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return strings.HasPrefix(name, "system.")
+	})
+	slices.Sort(names)
+	g.colls = names
+	return g.colls, nil
+}
+
+// collSchema returns the schema of collection coll, sampling the
+// collection if it hasn't been already.
+func (g *grip) collSchema(ctx context.Context, coll string) (*collSchema, error) {
+	if cs, ok := g.schemas[coll]; ok {
+		return cs, nil
+	}
+
+	cs, err := sampleColl(ctx, g.db.Collection(coll), g.sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	g.schemas[coll] = cs
+	return cs, nil
+}
+
+// sampleColl returns the schema of collection coll, inferred from its
+// first sampleSize documents.
+func sampleColl(ctx context.Context, coll *mongo.Collection, sampleSize int) (*collSchema, error) {
+	cur, err := coll.Find(ctx, bson.D{}, mopts.Find().SetLimit(int64(sampleSize)))
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfFuncError(lg.FromContext(ctx), "Close MongoDB cursor",
+		func() error { return cur.Close(ctx) })
+
+	cs := newCollSchema(coll.Name())
+	for cur.Next(ctx) {
+		var doc bson.D
+		if err = cur.Decode(&doc); err != nil {
+			return nil, errw(err)
+		}
+		cs.sample(doc)
+	}
+	if err = cur.Err(); err != nil {
+		return nil, errw(err)
+	}
+
+	cs.finish()
+	return cs, nil
+}
+
+// rowCount returns the number of rows of table ts of collection cs: for
+// the collection's own table, the (estimated) count of documents; for a
+// child table, the count of the array's elements.
+func (g *grip) rowCount(ctx context.Context, cs *collSchema, ts *tblSchema) (int64, error) {
+	coll := g.db.Collection(cs.coll)
+	if !ts.isChild() {
+		n, err := coll.EstimatedDocumentCount(ctx)
+		return n, errw(err)
+	}
+
+	field := "$" + strings.Join(ts.path, ".")
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "n", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$isArray", Value: field}},
+				bson.D{{Key: "$size", Value: field}},
+				0,
+			}}}}}},
+		}}},
+	}
+
+	cur, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, errw(err)
+	}
+
+	var results []struct {
+		N int64 `bson:"n"`
+	}
+	if err = cur.All(ctx, &results); err != nil {
+		return 0, errw(err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].N, nil
+}
+
+// SQLDriver implements driver.Grip.
+func (g *grip) SQLDriver() driver.SQLDriver {
+	return g.backing.SQLDriver()
+}
+
+// Source implements driver.Grip.
+func (g *grip) Source() *source.Source {
+	return g.src
+}
+
+// SourceMetadata implements driver.Grip. The tables are those inferred
+// from the collections' sampled documents, whether ingested or not.
+func (g *grip) SourceMetadata(ctx context.Context, noSchema bool) (*metadata.Source, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	md := &metadata.Source{
+		Handle:   g.src.Handle,
+		Location: g.src.Location,
+		Name:     g.db.Name(),
+		FQName:   g.db.Name(),
+		Schema:   g.db.Name(),
+		Driver:   drivertype.MongoDB,
+		DBDriver: g.backing.SQLDriver().DriverMetadata().Type,
+	}
+
+	if cs, err := connstring.Parse(g.src.Location); err == nil {
+		md.User = cs.Username
+	}
+
+	var err error
+	if md.DBVersion, err = g.dbVersion(ctx); err != nil {
+		return nil, err
+	}
+	md.DBProduct = "MongoDB " + md.DBVersion
+	md.DBSemver = semver.Canonical("v" + md.DBVersion)
+
+	if noSchema {
+		return md, nil
+	}
+
+	colls, err := g.listColls(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, coll := range colls {
+		cs, err := g.collSchema(ctx, coll)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ts := range cs.tbls {
+			tblMeta, err := g.tableMetadata(ctx, cs, ts)
+			if err != nil {
+				return nil, err
+			}
+			md.Tables = append(md.Tables, tblMeta)
+		}
+	}
+
+	md.RecomputeTableCounts()
+	return md, nil
+}
+
+// TableMetadata implements driver.Grip.
+func (g *grip) TableMetadata(ctx context.Context, tblName string) (*metadata.Table, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cs, ts, err := g.resolveTable(ctx, tblName)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.tableMetadata(ctx, cs, ts)
+}
+
+func (g *grip) tableMetadata(ctx context.Context, cs *collSchema, ts *tblSchema) (*metadata.Table, error) {
+	n, err := g.rowCount(ctx, cs, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.tableMetadata(g.db.Name(), n), nil
+}
+
+// DBSemver implements driver.Grip.
+func (g *grip) DBSemver(ctx context.Context) (string, error) {
+	version, err := g.dbVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	v := semver.Canonical("v" + version)
+	if !semver.IsValid(v) {
+		return "", errz.Errorf("invalid MongoDB semver %q from %q", v, version)
+	}
+	return v, nil
+}
+
+// dbVersion returns the MongoDB server version, e.g. "7.0.5".
+func (g *grip) dbVersion(ctx context.Context) (string, error) {
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := g.db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", errw(err)
+	}
+	return info.Version, nil
+}
+
+// Close implements driver.Grip.
+func (g *grip) Close() error {
+	g.closeOnce.Do(func() {
+		g.log.Debug(lgm.CloseDB, lga.Handle, g.src.Handle)
+		g.closeErr = errz.Append(errw(g.client.Disconnect(context.Background())), g.backing.Close())
+	})
+	return g.closeErr
+}
+
+// filtersEqual returns true if MongoDB query filters a and b are equal.
+func filtersEqual(a, b bson.D) bool {
+	aBytes, err := bson.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bBytes, err := bson.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aBytes) == string(bBytes)
+}
@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/progress"
+	"github.com/neilotoole/sq/libsq/core/tablefq"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+// ingestColl ingests the documents of collection cs that are selected by
+// mfilter, or all documents if mfilter is nil, into the backing DB. Any
+// previously ingested tables of the collection are replaced.
+func (g *grip) ingestColl(ctx context.Context, cs *collSchema, mfilter bson.D) error {
+	log := lg.FromContext(ctx).With(lga.Src, g.src)
+	start := time.Now()
+
+	bar := progress.FromContext(ctx).NewUnitCounter("Ingest "+cs.coll, "document")
+	defer bar.Stop()
+
+	drvr := g.backing.SQLDriver()
+	db, err := g.backing.DB(ctx)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errz.Err(err)
+	}
+	defer lg.WarnIfCloseError(log, lgm.CloseDB, conn)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errz.Err(err)
+	}
+	// Roll back unless we reach the explicit Commit below.
+	committed := false
+	defer func() {
+		if !committed {
+			lg.WarnIfError(log, "Rollback MongoDB ingest tx", errz.Err(tx.Rollback()))
+		}
+	}()
+
+	execers := make(map[*tblSchema]*driver.StmtExecer, len(cs.tbls))
+	defer func() {
+		for _, execer := range execers {
+			lg.WarnIfCloseError(log, "Close insert statement", execer)
+		}
+	}()
+
+	for _, ts := range cs.tbls {
+		if err = drvr.DropTable(ctx, tx, tablefq.New(ts.name), true); err != nil {
+			return err
+		}
+
+		tblDef := ts.tableDef()
+		if err = drvr.CreateTable(ctx, tx, tblDef); err != nil {
+			return err
+		}
+
+		if execers[ts], err = drvr.PrepareInsertStmt(ctx, tx, ts.name, tblDef.ColNames(), 1); err != nil {
+			return err
+		}
+	}
+
+	if mfilter == nil {
+		mfilter = bson.D{}
+	}
+
+	cur, err := g.db.Collection(cs.coll).Find(ctx, mfilter)
+	if err != nil {
+		return errw(err)
+	}
+	defer lg.WarnIfFuncError(log, "Close MongoDB cursor", func() error { return cur.Close(ctx) })
+
+	emit := func(ts *tblSchema, rec []any) error {
+		execer := execers[ts]
+		if err := execer.Munge(rec); err != nil {
+			return err
+		}
+		_, err := execer.Exec(ctx, rec...)
+		return err
+	}
+
+	var count int64
+	for cur.Next(ctx) {
+		var doc bson.D
+		if err = cur.Decode(&doc); err != nil {
+			return errw(err)
+		}
+		if err = cs.records(doc, emit); err != nil {
+			return err
+		}
+		count++
+		bar.Incr(1)
+	}
+	if err = cur.Err(); err != nil {
+		return errw(err)
+	}
+
+	committed = true
+	if err = tx.Commit(); err != nil {
+		return errz.Err(err)
+	}
+
+	log.Debug("Ingested MongoDB collection",
+		lga.Table, cs.coll,
+		lga.Count, count,
+		"filter", mfilter,
+		lga.Elapsed, time.Since(start).Round(time.Millisecond))
+	return nil
+}
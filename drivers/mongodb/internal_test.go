@@ -0,0 +1,295 @@
+package mongodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+func testCollSchema(t *testing.T) (*collSchema, bson.ObjectID) {
+	t.Helper()
+
+	id := bson.NewObjectID()
+	docs := []bson.D{
+		{
+			{Key: "_id", Value: id},
+			{Key: "name", Value: "alice"},
+			{Key: "age", Value: int32(30)},
+			{Key: "address", Value: bson.D{
+				{Key: "city", Value: "Dublin"},
+				{Key: "geo", Value: bson.D{{Key: "lat", Value: 53.3}}},
+			}},
+			{Key: "tags", Value: bson.A{"a", "b"}},
+			{Key: "orders", Value: bson.A{
+				bson.D{{Key: "sku", Value: "x1"}, {Key: "qty", Value: int64(2)}},
+				bson.D{{Key: "sku", Value: "x2"}, {Key: "dims", Value: bson.A{int32(1), int32(2)}}},
+			}},
+		},
+		{
+			{Key: "_id", Value: bson.NewObjectID()},
+			{Key: "name", Value: "bob"},
+			{Key: "age", Value: 41.5},
+			{Key: "address_city", Value: "Cork"},
+			{Key: "note", Value: nil},
+		},
+	}
+
+	cs := newCollSchema("people")
+	for _, doc := range docs {
+		cs.sample(doc)
+	}
+	cs.finish()
+	return cs, id
+}
+
+func colNames(ts *tblSchema) []string {
+	names := make([]string, len(ts.cols))
+	for i, col := range ts.cols {
+		names[i] = col.name
+	}
+	return names
+}
+
+func TestCollSchema(t *testing.T) {
+	cs, _ := testCollSchema(t)
+
+	require.Len(t, cs.tbls, 3)
+	root := cs.root()
+	require.Equal(t, "people", root.name)
+	require.False(t, root.isChild())
+	require.Equal(t,
+		[]string{"_id", "name", "age", "address_city", "address_geo_lat", "address_city_2", "note"},
+		colNames(root))
+
+	assert.Equal(t, kind.Text, root.colsByName["_id"].kind)
+	assert.Equal(t, "objectId", root.colsByName["_id"].baseType())
+	assert.Equal(t, kind.Float, root.colsByName["age"].kind)
+	assert.Equal(t, "int|double", root.colsByName["age"].baseType())
+	assert.Equal(t, kind.Float, root.colsByName["address_geo_lat"].kind)
+	assert.Equal(t, []string{"address", "city"}, root.colsByName["address_city"].path)
+	assert.Equal(t, []string{"address_city"}, root.colsByName["address_city_2"].path)
+	assert.Equal(t, kind.Text, root.colsByName["note"].kind, "null-only column should be text")
+	assert.Equal(t, "null", root.colsByName["note"].baseType())
+
+	tags := cs.table("people_tags")
+	require.NotNil(t, tags)
+	require.True(t, tags.isChild())
+	require.Equal(t, []string{"_parent_id", "_index", "value"}, colNames(tags))
+	assert.Equal(t, kind.Text, tags.colsByName["_parent_id"].kind)
+	assert.Equal(t, kind.Int, tags.colsByName["_index"].kind)
+	assert.Equal(t, kind.Text, tags.colsByName["value"].kind)
+
+	orders := cs.table("people_orders")
+	require.NotNil(t, orders)
+	require.Equal(t, []string{"_parent_id", "_index", "sku", "qty", "dims"}, colNames(orders))
+	assert.Equal(t, kind.Int, orders.colsByName["qty"].kind)
+	assert.Equal(t, kind.Text, orders.colsByName["dims"].kind, "nested array should be JSON text")
+
+	require.Nil(t, cs.table("people_address"))
+}
+
+func TestCollSchema_records(t *testing.T) {
+	cs, id := testCollSchema(t)
+
+	doc := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "alice"},
+		{Key: "age", Value: int32(30)},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Dublin"}}},
+		{Key: "unsampled", Value: "ignored"},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "orders", Value: bson.A{
+			bson.D{{Key: "sku", Value: "x1"}, {Key: "qty", Value: int64(2)}},
+			bson.D{{Key: "sku", Value: "x2"}, {Key: "dims", Value: bson.A{int32(1), int32(2)}}},
+		}},
+		{Key: "unsampled_arr", Value: bson.A{"ignored"}},
+	}
+
+	got := map[string][][]any{}
+	err := cs.records(doc, func(ts *tblSchema, rec []any) error {
+		got[ts.name] = append(got[ts.name], rec)
+		return nil
+	})
+	require.NoError(t, err)
+
+	idHex := id.Hex()
+	require.Equal(t, [][]any{{idHex, "alice", float64(30), "Dublin", nil, nil, nil}}, got["people"])
+	require.Equal(t, [][]any{{idHex, int64(0), "a"}, {idHex, int64(1), "b"}}, got["people_tags"])
+	require.Equal(t, [][]any{
+		{idHex, int64(0), "x1", int64(2), nil},
+		{idHex, int64(1), "x2", nil, "[1,2]"},
+	}, got["people_orders"])
+	require.Len(t, got, 3)
+}
+
+func TestCollSchema_tableMetadata(t *testing.T) {
+	cs, _ := testCollSchema(t)
+
+	tblMeta := cs.root().tableMetadata("mydb", 2)
+	require.Equal(t, "people", tblMeta.Name)
+	require.Equal(t, "mydb.people", tblMeta.FQName)
+	require.Equal(t, "collection", tblMeta.DBTableType)
+	require.Equal(t, int64(2), tblMeta.RowCount)
+	require.True(t, tblMeta.Column("_id").PrimaryKey)
+	require.False(t, tblMeta.Column("_id").Nullable)
+	require.True(t, tblMeta.Column("name").Nullable)
+	require.Equal(t, "int|double", tblMeta.Column("age").BaseType)
+
+	tblMeta = cs.table("people_orders").tableMetadata("mydb", 2)
+	require.Equal(t, "array", tblMeta.DBTableType)
+	require.Equal(t, "Elements of array field orders", tblMeta.Comment)
+	require.False(t, tblMeta.Column("_parent_id").Nullable)
+	require.False(t, tblMeta.Column("_parent_id").PrimaryKey)
+}
+
+func TestMergeKind(t *testing.T) {
+	testCases := []struct {
+		a, b, want kind.Kind
+	}{
+		{a: kind.Unknown, b: kind.Int, want: kind.Int},
+		{a: kind.Null, b: kind.Bool, want: kind.Bool},
+		{a: kind.Int, b: kind.Int, want: kind.Int},
+		{a: kind.Int, b: kind.Float, want: kind.Float},
+		{a: kind.Float, b: kind.Decimal, want: kind.Decimal},
+		{a: kind.Int, b: kind.Text, want: kind.Text},
+		{a: kind.Bool, b: kind.Datetime, want: kind.Text},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a.String()+"_"+tc.b.String(), func(t *testing.T) {
+			require.Equal(t, tc.want, mergeKind(tc.a, tc.b))
+			require.Equal(t, tc.want, mergeKind(tc.b, tc.a))
+		})
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	id := bson.NewObjectID()
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		k    kind.Kind
+		v    any
+		want any
+	}{
+		{name: "nil", k: kind.Int, v: nil, want: nil},
+		{name: "int32", k: kind.Int, v: int32(7), want: int64(7)},
+		{name: "int_as_float", k: kind.Float, v: int64(7), want: float64(7)},
+		{name: "float_as_int", k: kind.Int, v: 7.5, want: nil},
+		{name: "bool", k: kind.Bool, v: true, want: true},
+		{name: "string_as_bool", k: kind.Bool, v: "true", want: nil},
+		{name: "datetime", k: kind.Datetime, v: bson.NewDateTimeFromTime(tm), want: tm},
+		{name: "binary", k: kind.Bytes, v: bson.Binary{Data: []byte("hi")}, want: []byte("hi")},
+		{name: "objectid", k: kind.Text, v: id, want: id.Hex()},
+		{name: "text_int", k: kind.Text, v: int32(7), want: "7"},
+		{name: "text_doc", k: kind.Text, v: bson.D{{Key: "b", Value: int32(1)}, {Key: "a", Value: "x"}}, want: `{"b":1,"a":"x"}`},
+		{name: "text_array", k: kind.Text, v: bson.A{"x", nil, true}, want: `["x",null,true]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, convertValue(tc.k, tc.v))
+		})
+	}
+}
+
+func TestMongoFilter(t *testing.T) {
+	cs, id := testCollSchema(t)
+	root := cs.root()
+
+	// Column "age" holds both int and double values.
+	numericOr := func(field, op string, val any) bson.D {
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: op, Value: val}}}},
+			bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$type", Value: numericTypes}}}}}},
+		}}}
+	}
+	stringOr := func(field, op string, val any) bson.D {
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: op, Value: val}}}},
+			bson.D{{Key: field, Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$type", Value: []string{"string"}}}}}}},
+		}}}
+	}
+
+	testCases := []struct {
+		name  string
+		preds []driver.FilterPred
+		want  bson.D
+	}{
+		{
+			name:  "numeric",
+			preds: []driver.FilterPred{{Col: "age", Op: ">=", Val: int64(21)}},
+			want:  numericOr("age", "$gte", int64(21)),
+		},
+		{
+			name:  "nested_string",
+			preds: []driver.FilterPred{{Col: "address_city", Op: "==", Val: "Dublin"}},
+			want:  stringOr("address.city", "$eq", "Dublin"),
+		},
+		{
+			name:  "objectid",
+			preds: []driver.FilterPred{{Col: "_id", Op: "==", Val: id.Hex()}},
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "_id", Value: bson.D{{Key: "$eq", Value: id}}}},
+				bson.D{{Key: "_id", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$type", Value: []string{"objectId"}}}}}}},
+			}}},
+		},
+		{
+			name:  "not_null",
+			preds: []driver.FilterPred{{Col: "name", Op: "!=", Val: nil}},
+			want:  bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: nil}}}},
+		},
+		{
+			name: "and",
+			preds: []driver.FilterPred{
+				{Col: "age", Op: "<", Val: 50.5},
+				{Col: "name", Op: "!=", Val: "bob"},
+			},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				numericOr("age", "$lt", 50.5),
+				stringOr("name", "$ne", "bob"),
+			}}},
+		},
+		{
+			name: "skip_unpushable",
+			preds: []driver.FilterPred{
+				{Col: "missing", Op: "==", Val: "x"},
+				{Col: "name", Op: "==", Val: nil},
+				{Col: "age", Op: "==", Val: "21"},
+				{Col: "_id", Op: "==", Val: "not-an-objectid"},
+				{Col: "name", Op: ">", Val: "m"},
+			},
+			want: stringOr("name", "$gt", "m"),
+		},
+		{
+			name:  "none",
+			preds: []driver.FilterPred{{Col: "missing", Op: "==", Val: "x"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mongoFilter(root, &driver.Filter{Preds: tc.preds})
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	require.Nil(t, mongoFilter(root, nil))
+	require.Nil(t, mongoFilter(cs.table("people_tags"),
+		&driver.Filter{Preds: []driver.FilterPred{{Col: "value", Op: "==", Val: "a"}}}))
+}
+
+func TestFiltersEqual(t *testing.T) {
+	a := bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: int64(1)}}}}
+	b := bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: int64(1)}}}}
+	c := bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: int64(2)}}}}
+	require.True(t, filtersEqual(a, b))
+	require.False(t, filtersEqual(a, c))
+}
@@ -0,0 +1,176 @@
+// Package mongodb implements the sq driver for MongoDB. A MongoDB source
+// is not queried via SQL directly: instead, the schema of each collection
+// is inferred from a sample of its documents, and the collection's
+// documents are ingested into a scratch DB when the collection is
+// queried. Nested documents are flattened into columns, and each array
+// field becomes a child table. Simple predicates of a query's where()
+// clause are pushed down to MongoDB, so that only the matching documents
+// are ingested.
+package mongodb
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	mopts "go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/connstring"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/progress"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// defaultPort is the default MongoDB port.
+const defaultPort = 27017
+
+// Provider is the MongoDB implementation of driver.Provider.
+type Provider struct {
+	Log      *slog.Logger
+	Ingester driver.GripOpenIngester
+}
+
+// DriverFor implements driver.Provider.
+func (p *Provider) DriverFor(typ drivertype.Type) (driver.Driver, error) {
+	if typ != drivertype.MongoDB {
+		return nil, errz.Errorf("unsupported driver type {%s}", typ)
+	}
+
+	return &driveri{log: p.Log, ingester: p.Ingester}, nil
+}
+
+var _ driver.Driver = (*driveri)(nil)
+
+// driveri is the MongoDB implementation of driver.Driver.
+type driveri struct {
+	log      *slog.Logger
+	ingester driver.GripOpenIngester
+}
+
+// DriverMetadata implements driver.Driver.
+func (d *driveri) DriverMetadata() driver.Metadata {
+	return driver.Metadata{
+		Type:        drivertype.MongoDB,
+		Description: "MongoDB",
+		Doc:         "https://www.mongodb.com",
+		DefaultPort: defaultPort,
+	}
+}
+
+// Open implements driver.Driver. The collections are ingested into the
+// returned grip's scratch DB on demand: see grip.PrepareTable.
+func (d *driveri) Open(ctx context.Context, src *source.Source, _ driver.AccessMode) (driver.Grip, error) {
+	log := lg.FromContext(ctx).With(lga.Src, src)
+	log.Debug(lgm.OpenSrc, lga.Src, src)
+
+	dbName, err := databaseName(src.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := connect(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	// The scratch DB starts out empty: the ingest cache isn't used, as
+	// the documents ingested depend on the query's filter.
+	noopIngestFn := func(context.Context, driver.Grip) error { return nil }
+	backing, err := d.ingester.OpenIngest(ctx, src, false, noopIngestFn)
+	if err != nil {
+		lg.WarnIfFuncError(log, lgm.CloseDB, func() error { return client.Disconnect(ctx) })
+		return nil, err
+	}
+
+	return &grip{
+		log:        log,
+		src:        src,
+		client:     client,
+		db:         client.Database(dbName),
+		backing:    backing,
+		sampleSize: driver.OptIngestSampleSize.Get(options.FromContext(ctx)),
+		schemas:    map[string]*collSchema{},
+		ingested:   map[string]*ingestState{},
+	}, nil
+}
+
+// Ping implements driver.Driver.
+func (d *driveri) Ping(ctx context.Context, src *source.Source, _ driver.AccessMode) error {
+	if _, err := databaseName(src.Location); err != nil {
+		return err
+	}
+
+	client, err := connect(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	return errw(client.Disconnect(ctx))
+}
+
+// ValidateSource implements driver.Driver.
+func (d *driveri) ValidateSource(src *source.Source) (*source.Source, error) {
+	if src.Type != drivertype.MongoDB {
+		return nil, errz.Errorf("expected driver type {%s} but got {%s}", drivertype.MongoDB, src.Type)
+	}
+
+	if !strings.HasPrefix(src.Location, "mongodb://") && !strings.HasPrefix(src.Location, "mongodb+srv://") {
+		return nil, errz.Errorf("%s: location must be a mongodb:// or mongodb+srv:// URI: %s",
+			src.Handle, location.Redact(src.Location))
+	}
+
+	return src, nil
+}
+
+// connect returns a client connected to src, having verified the
+// connection via ping, honoring driver.OptConnOpenTimeout.
+func connect(ctx context.Context, src *source.Source) (*mongo.Client, error) {
+	o := options.Merge(options.FromContext(ctx), src.Options)
+	timeout := driver.OptConnOpenTimeout.Get(o)
+
+	client, err := mongo.Connect(mopts.Client().ApplyURI(src.Location).SetConnectTimeout(timeout))
+	if err != nil {
+		// The error may echo the URI, which may hold a password.
+		return nil, errz.Errorf("%s: invalid MongoDB location: %s", src.Handle, location.Redact(src.Location))
+	}
+
+	bar := progress.FromContext(ctx).NewWaiter("Ping " + src.Handle)
+	defer bar.Stop()
+
+	pingCtx, cancelFn := context.WithTimeout(ctx, timeout)
+	defer cancelFn()
+
+	if err = client.Ping(pingCtx, nil); err != nil {
+		lg.WarnIfFuncError(lg.FromContext(ctx), lgm.CloseDB, func() error { return client.Disconnect(ctx) })
+		return nil, errz.Wrapf(errw(err), "%s: ping", src.Handle)
+	}
+
+	return client, nil
+}
+
+// databaseName returns the name of the database in MongoDB URI loc.
+func databaseName(loc string) (string, error) {
+	cs, err := connstring.Parse(loc)
+	if err != nil {
+		// Don't echo err: it may contain the URI.
+		return "", errz.Errorf("invalid MongoDB location: %s", location.Redact(loc))
+	}
+	if cs.Database == "" {
+		return "", errz.Errorf("MongoDB location must specify the database, e.g. %s",
+			"mongodb://localhost:27017/mydb")
+	}
+	return cs.Database, nil
+}
+
+// errw wraps any MongoDB error with the "mongodb" prefix.
+func errw(err error) error {
+	return errz.Wrap(err, "mongodb")
+}
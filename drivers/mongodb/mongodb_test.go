@@ -0,0 +1,202 @@
+package mongodb_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	mopts "go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// mongoTestURI is the default URI of the test MongoDB instance, such as
+// started by "docker run -p 27017:27017 mongo"; override with
+// SQ_TEST_MONGODB_URI. The test database is dropped and re-seeded.
+const mongoTestURI = "mongodb://localhost:27017/sq_test"
+
+// seedMongoDB seeds the test MongoDB database, skipping the test if the
+// instance is unreachable. It returns the database's URI.
+func seedMongoDB(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	uri := os.Getenv("SQ_TEST_MONGODB_URI")
+	if uri == "" {
+		uri = mongoTestURI
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(mopts.Client().ApplyURI(uri).SetServerSelectionTimeout(3 * time.Second))
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, client.Disconnect(context.Background())) }()
+	if err = client.Ping(ctx, nil); err != nil {
+		t.Skipf("MongoDB not reachable: %v", err)
+	}
+
+	db := client.Database("sq_test")
+	require.NoError(t, db.Drop(ctx))
+
+	_, err = db.Collection("users").InsertMany(ctx, []any{
+		bson.D{
+			{Key: "_id", Value: int32(1)},
+			{Key: "name", Value: "ada"},
+			{Key: "age", Value: int32(36)},
+			{Key: "address", Value: bson.D{{Key: "city", Value: "London"}}},
+			{Key: "tags", Value: bson.A{"math", "poetry"}},
+		},
+		bson.D{
+			{Key: "_id", Value: int32(2)},
+			{Key: "name", Value: "grace"},
+			{Key: "age", Value: int32(85)},
+			{Key: "address", Value: bson.D{{Key: "city", Value: "New York"}}},
+			{Key: "tags", Value: bson.A{"navy"}},
+		},
+		bson.D{
+			{Key: "_id", Value: int32(3)},
+			{Key: "name", Value: "edsger"},
+			{Key: "age", Value: int32(72)},
+			{Key: "address", Value: bson.D{{Key: "city", Value: "Rotterdam"}}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = db.Collection("orders").InsertMany(ctx, []any{
+		bson.D{{Key: "user_id", Value: int32(1)}, {Key: "total", Value: 9.5}},
+		bson.D{{Key: "user_id", Value: int32(1)}, {Key: "total", Value: 20.0}},
+		bson.D{{Key: "user_id", Value: int32(3)}, {Key: "total", Value: 7.25}},
+	})
+	require.NoError(t, err)
+
+	return uri
+}
+
+func openMongoDB(t *testing.T) (*testh.Helper, driver.Grip) {
+	t.Helper()
+	uri := seedMongoDB(t)
+
+	th := testh.New(t)
+	src := &source.Source{Handle: "@mongo", Type: drivertype.MongoDB, Location: uri}
+	drvr, err := th.Grips().DriverFor(src.Type)
+	require.NoError(t, err)
+	src, err = drvr.ValidateSource(src)
+	require.NoError(t, err)
+
+	grip, err := drvr.Open(th.Context, src, driver.ModeReadOnly)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, grip.Close()) })
+	return th, grip
+}
+
+func queryCount(t *testing.T, ctx context.Context, grip driver.Grip, query string) int64 { //nolint:revive
+	t.Helper()
+	db, err := grip.DB(ctx)
+	require.NoError(t, err)
+	var n int64
+	require.NoError(t, db.QueryRowContext(ctx, query).Scan(&n))
+	return n
+}
+
+func TestGrip_DB(t *testing.T) {
+	th, grip := openMongoDB(t)
+
+	// Without PrepareTable, every collection is ingested in full.
+	require.Equal(t, int64(3), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users`))
+	require.Equal(t, int64(3), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM orders`))
+	require.Equal(t, int64(3), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users_tags`))
+	require.Equal(t, int64(1), queryCount(t, th.Context, grip,
+		`SELECT COUNT(*) FROM users WHERE address_city = 'London'`))
+}
+
+func TestGrip_PrepareTable(t *testing.T) {
+	th, grip := openMongoDB(t)
+	preparer, ok := grip.(driver.TablePreparer)
+	require.True(t, ok)
+
+	filter := &driver.Filter{Preds: []driver.FilterPred{{Col: "age", Op: ">", Val: int64(70)}}}
+	require.NoError(t, preparer.PrepareTable(th.Context, "users", filter))
+	require.Equal(t, int64(2), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users`),
+		"only matching documents should be ingested")
+	require.Equal(t, int64(1), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users_tags`))
+
+	// A different filter triggers re-ingest.
+	filter = &driver.Filter{Preds: []driver.FilterPred{{Col: "address_city", Op: "==", Val: "London"}}}
+	require.NoError(t, preparer.PrepareTable(th.Context, "users", filter))
+	require.Equal(t, int64(1), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users`))
+
+	// A child table is always ingested in full.
+	require.NoError(t, preparer.PrepareTable(th.Context, "users_tags", filter))
+	require.Equal(t, int64(3), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users`))
+	require.Equal(t, int64(3), queryCount(t, th.Context, grip, `SELECT COUNT(*) FROM users_tags`))
+
+	require.Error(t, preparer.PrepareTable(th.Context, "not_exist", nil))
+}
+
+func TestGrip_SourceMetadata(t *testing.T) {
+	th, grip := openMongoDB(t)
+
+	md, err := grip.SourceMetadata(th.Context, false)
+	require.NoError(t, err)
+	require.Equal(t, drivertype.MongoDB, md.Driver)
+	require.Equal(t, "sq_test", md.Name)
+	require.NotEmpty(t, md.DBVersion)
+	require.NotEmpty(t, md.DBSemver)
+
+	var names []string
+	for _, tbl := range md.Tables {
+		names = append(names, tbl.Name)
+	}
+	require.Equal(t, []string{"orders", "users", "users_tags"}, names)
+
+	tblMeta := md.Table("users")
+	require.NotNil(t, tblMeta)
+	require.Equal(t, int64(3), tblMeta.RowCount)
+	require.NotNil(t, tblMeta.Column("address_city"))
+
+	tblMeta, err = grip.TableMetadata(th.Context, "users_tags")
+	require.NoError(t, err)
+	require.Equal(t, "array", tblMeta.DBTableType)
+	require.Equal(t, int64(3), tblMeta.RowCount)
+
+	semver, err := grip.DBSemver(th.Context)
+	require.NoError(t, err)
+	require.Equal(t, md.DBSemver, semver)
+}
+
+func TestDriver_Ping(t *testing.T) {
+	uri := seedMongoDB(t)
+	th := testh.New(t)
+	drvr, err := th.Grips().DriverFor(drivertype.MongoDB)
+	require.NoError(t, err)
+
+	src := &source.Source{Handle: "@mongo", Type: drivertype.MongoDB, Location: uri}
+	require.NoError(t, drvr.Ping(th.Context, src, driver.ModeReadOnly))
+}
+
+func TestDriver_ValidateSource(t *testing.T) {
+	th := testh.New(t)
+	drvr, err := th.Grips().DriverFor(drivertype.MongoDB)
+	require.NoError(t, err)
+
+	src := &source.Source{Handle: "@mongo", Type: drivertype.MongoDB, Location: mongoTestURI}
+	_, err = drvr.ValidateSource(src)
+	require.NoError(t, err)
+
+	src.Location = "postgres://localhost/sq_test"
+	_, err = drvr.ValidateSource(src)
+	require.Error(t, err)
+
+	src.Location = "mongodb://localhost:27017"
+	require.Error(t, drvr.Ping(th.Context, src, driver.ModeReadOnly), "database is required")
+}
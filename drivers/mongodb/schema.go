@@ -0,0 +1,577 @@
+package mongodb
+
+import (
+	"bytes"
+	stdj "encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/schema"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+const (
+	// colScopeSep is used when generating flat column names. Thus the field
+	// "address.city" of a nested document becomes column "address_city".
+	colScopeSep = "_"
+
+	// colID is the name of the column holding a document's _id field.
+	colID = "_id"
+
+	// colParentID is the name of a child table's column that holds the
+	// _id of the array's document.
+	colParentID = "_parent_id"
+
+	// colIndex is the name of a child table's column that holds the
+	// element's index in the array.
+	colIndex = "_index"
+
+	// colValue is the name of a child table's column that holds the
+	// element, when the element isn't a document.
+	colValue = "value"
+)
+
+// collSchema is the schema of a collection, inferred from a sample of its
+// documents. The fields of nested documents are flattened into columns of
+// the collection's table. Each array field becomes a child table, with a
+// row per element, linked to the document's row via colParentID.
+type collSchema struct {
+	// coll is the collection name.
+	coll string
+
+	// tbls holds the collection's tables: the first is the collection's
+	// own table, and the rest are the child tables of its array fields.
+	tbls []*tblSchema
+}
+
+// newCollSchema returns a new collSchema for collection coll. Its tables
+// are populated via sample.
+func newCollSchema(coll string) *collSchema {
+	return &collSchema{
+		coll: coll,
+		tbls: []*tblSchema{newTblSchema(coll, nil)},
+	}
+}
+
+// root returns the collection's own table.
+func (cs *collSchema) root() *tblSchema {
+	return cs.tbls[0]
+}
+
+// table returns the table named name, or nil.
+func (cs *collSchema) table(name string) *tblSchema {
+	for _, ts := range cs.tbls {
+		if ts.name == name {
+			return ts
+		}
+	}
+	return nil
+}
+
+// child returns the child table for the array at path, creating it if
+// create is true. If not found, and create is false, nil is returned.
+func (cs *collSchema) child(path []string, create bool) *tblSchema {
+	key := pathKey(path)
+	for _, ts := range cs.tbls[1:] {
+		if pathKey(ts.path) == key {
+			return ts
+		}
+	}
+	if !create {
+		return nil
+	}
+
+	ts := newTblSchema(cs.coll+colScopeSep+strings.Join(path, colScopeSep), path)
+	ts.col([]string{colParentID}).synthetic = true
+	ts.col([]string{colIndex}).synthetic = true
+	cs.tbls = append(cs.tbls, ts)
+	return ts
+}
+
+// sample adds the fields of doc to the schema.
+func (cs *collSchema) sample(doc bson.D) {
+	cs.root().sampleDoc(cs, nil, doc)
+}
+
+// finish completes the schema, after sampling.
+func (cs *collSchema) finish() {
+	root := cs.root()
+	for _, ts := range cs.tbls {
+		for _, col := range ts.cols {
+			if col.kind == kind.Unknown || col.kind == kind.Null {
+				col.kind = kind.Text
+			}
+		}
+	}
+
+	parentID := &colSchema{kind: kind.Text, types: []string{"objectId"}}
+	if col := root.colsByName[colID]; col != nil {
+		parentID = col
+	}
+	for _, ts := range cs.tbls[1:] {
+		col := ts.colsByName[colParentID]
+		col.kind, col.types = parentID.kind, parentID.types
+		col = ts.colsByName[colIndex]
+		col.kind, col.types = kind.Int, []string{"int"}
+	}
+}
+
+// records invokes emit for each of the table records of doc: one for the
+// collection's own table, and one for each element of each array that has
+// a child table.
+func (cs *collSchema) records(doc bson.D, emit func(ts *tblSchema, rec []any) error) error {
+	root := cs.root()
+	rec := make([]any, len(root.cols))
+	var arrays []arrayField
+	root.fill(rec, nil, doc, &arrays)
+	if err := emit(root, rec); err != nil {
+		return err
+	}
+
+	var parentID any
+	if i := root.colIndex(colID); i >= 0 {
+		parentID = rec[i]
+	}
+
+	for _, arr := range arrays {
+		child := cs.child(arr.path, false)
+		if child == nil {
+			// The array wasn't seen in the sample.
+			continue
+		}
+
+		for i, elem := range arr.elems {
+			childRec := make([]any, len(child.cols))
+			childRec[0] = parentID
+			childRec[1] = int64(i)
+			if doc, ok := elem.(bson.D); ok {
+				child.fill(childRec, nil, doc, nil)
+			} else if j := child.colIndex(colValue); j >= 0 {
+				childRec[j] = convertValue(child.cols[j].kind, elem)
+			}
+
+			if err := emit(child, childRec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// arrayField is an array field of a document.
+type arrayField struct {
+	path  []string
+	elems bson.A
+}
+
+// tblSchema is the schema of a table of a collSchema.
+type tblSchema struct {
+	colsByKey  map[string]*colSchema
+	colsByName map[string]*colSchema
+
+	// name is the table name.
+	name string
+
+	// path is the path of the array field, for a child table. For the
+	// collection's own table, path is nil.
+	path []string
+
+	cols []*colSchema
+}
+
+func newTblSchema(name string, path []string) *tblSchema {
+	return &tblSchema{
+		name:       name,
+		path:       path,
+		colsByKey:  map[string]*colSchema{},
+		colsByName: map[string]*colSchema{},
+	}
+}
+
+// isChild returns true if ts is the child table of an array field.
+func (ts *tblSchema) isChild() bool {
+	return ts.path != nil
+}
+
+// col returns the column for the field at path, creating it if necessary.
+// The column name is the path elements joined by colScopeSep. In the
+// unlikely event that another field has the same column name, e.g.
+// "a_b" and "a.b", a numeric suffix is appended.
+func (ts *tblSchema) col(path []string) *colSchema {
+	key := pathKey(path)
+	if col, ok := ts.colsByKey[key]; ok {
+		return col
+	}
+
+	base := strings.Join(path, colScopeSep)
+	name := base
+	for i := 2; ts.colsByName[name] != nil; i++ {
+		name = base + colScopeSep + strconv.Itoa(i)
+	}
+
+	col := &colSchema{name: name, path: slices.Clone(path)}
+	ts.cols = append(ts.cols, col)
+	ts.colsByKey[key] = col
+	ts.colsByName[name] = col
+	return col
+}
+
+// colIndex returns the index of the column named name, or -1.
+func (ts *tblSchema) colIndex(name string) int {
+	return slices.IndexFunc(ts.cols, func(col *colSchema) bool { return col.name == name })
+}
+
+// sampleDoc adds the fields of doc, whose field path is prefix, to ts.
+func (ts *tblSchema) sampleDoc(cs *collSchema, prefix []string, doc bson.D) {
+	for _, e := range doc {
+		path := append(slices.Clip(prefix), e.Key)
+		switch v := e.Value.(type) {
+		case bson.D:
+			ts.sampleDoc(cs, path, v)
+		case bson.A:
+			if ts.isChild() {
+				// An array within an array element is a column of JSON
+				// text, rather than a grandchild table.
+				ts.col(path).sample(v)
+				continue
+			}
+
+			child := cs.child(path, true)
+			for _, elem := range v {
+				if elem, ok := elem.(bson.D); ok {
+					child.sampleDoc(cs, nil, elem)
+					continue
+				}
+				child.col([]string{colValue}).sample(elem)
+			}
+		default:
+			ts.col(path).sample(v)
+		}
+	}
+}
+
+// fill sets the values of rec, a record of ts, from doc, whose field path
+// is prefix. The array fields of doc are appended to arrays, unless arrays
+// is nil. Fields that weren't seen in the sample are ignored.
+func (ts *tblSchema) fill(rec []any, prefix []string, doc bson.D, arrays *[]arrayField) {
+	for _, e := range doc {
+		path := append(slices.Clip(prefix), e.Key)
+		switch v := e.Value.(type) {
+		case bson.D:
+			ts.fill(rec, path, v, arrays)
+			continue
+		case bson.A:
+			if arrays != nil {
+				*arrays = append(*arrays, arrayField{path: path, elems: v})
+				continue
+			}
+		default:
+		}
+
+		col, ok := ts.colsByKey[pathKey(path)]
+		if !ok {
+			continue
+		}
+		rec[slices.Index(ts.cols, col)] = convertValue(col.kind, e.Value)
+	}
+}
+
+// tableDef returns the definition of ts's table in the ingest DB.
+func (ts *tblSchema) tableDef() *schema.Table {
+	names := make([]string, len(ts.cols))
+	kinds := make([]kind.Kind, len(ts.cols))
+	for i, col := range ts.cols {
+		names[i] = col.name
+		kinds[i] = col.kind
+	}
+	return schema.NewTable(ts.name, names, kinds)
+}
+
+// tableMetadata returns the metadata of ts. Arg dbName is the name of the
+// MongoDB database.
+func (ts *tblSchema) tableMetadata(dbName string, rowCount int64) *metadata.Table {
+	md := &metadata.Table{
+		Name:        ts.name,
+		FQName:      dbName + "." + ts.name,
+		TableType:   sqlz.TableTypeTable,
+		DBTableType: "collection",
+		RowCount:    rowCount,
+	}
+	if ts.isChild() {
+		md.DBTableType = "array"
+		md.Comment = "Elements of array field " + strings.Join(ts.path, ".")
+	}
+
+	for i, col := range ts.cols {
+		md.Columns = append(md.Columns, &metadata.Column{
+			Name:       col.name,
+			Position:   int64(i),
+			PrimaryKey: col.name == colID && !ts.isChild(),
+			BaseType:   col.baseType(),
+			ColumnType: col.baseType(),
+			Kind:       col.kind,
+			Nullable:   !col.synthetic && col.name != colID,
+		})
+	}
+	return md
+}
+
+// colSchema is the schema of a column of a tblSchema.
+type colSchema struct {
+	// name is the column name.
+	name string
+
+	// path is the path of the column's field within the table's document,
+	// which is the array element for a child table.
+	path []string
+
+	// types holds the BSON type names, such as "string" or "objectId", of
+	// the field's sampled values, in order of appearance.
+	types []string
+
+	// kind is the column kind.
+	kind kind.Kind
+
+	// synthetic is true for the colParentID and colIndex columns of a
+	// child table, which don't correspond to a document field.
+	synthetic bool
+}
+
+// sample adds v, a sampled value of the field, to the column.
+func (col *colSchema) sample(v any) {
+	k, typ := bsonKind(v)
+	if k == kind.Null {
+		return
+	}
+
+	if !slices.Contains(col.types, typ) {
+		col.types = append(col.types, typ)
+	}
+	col.kind = mergeKind(col.kind, k)
+}
+
+// baseType returns the BSON type of the column's values, such as "string".
+// If the sampled values are of several types, they're joined by "|".
+func (col *colSchema) baseType() string {
+	if len(col.types) == 0 {
+		return "null"
+	}
+	return strings.Join(col.types, "|")
+}
+
+// hasOnlyTypes returns true if each of the column's sampled BSON types is
+// one of types.
+func (col *colSchema) hasOnlyTypes(types ...string) bool {
+	if len(col.types) == 0 {
+		return false
+	}
+	for _, typ := range col.types {
+		if !slices.Contains(types, typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// bsonKind returns the kind and BSON type name of v, a value decoded from
+// a BSON document. For a null value, kind.Null is returned.
+func bsonKind(v any) (kind.Kind, string) {
+	switch v.(type) {
+	case nil, bson.Null, bson.Undefined:
+		return kind.Null, "null"
+	case string:
+		return kind.Text, "string"
+	case bson.ObjectID:
+		return kind.Text, "objectId"
+	case int32:
+		return kind.Int, "int"
+	case int64:
+		return kind.Int, "long"
+	case float64:
+		return kind.Float, "double"
+	case bson.Decimal128:
+		return kind.Decimal, "decimal"
+	case bool:
+		return kind.Bool, "bool"
+	case bson.DateTime:
+		return kind.Datetime, "date"
+	case bson.Binary:
+		return kind.Bytes, "binData"
+	case bson.D:
+		return kind.Text, "object"
+	case bson.A:
+		return kind.Text, "array"
+	case bson.Timestamp:
+		return kind.Text, "timestamp"
+	case bson.Regex:
+		return kind.Text, "regex"
+	default:
+		return kind.Text, fmt.Sprintf("%T", v)
+	}
+}
+
+// mergeKind returns the kind of a column whose values are of kinds a and b.
+func mergeKind(a, b kind.Kind) kind.Kind {
+	switch {
+	case a == kind.Unknown, a == kind.Null, a == b:
+		return b
+	case b == kind.Unknown, b == kind.Null:
+		return a
+	case a == kind.Int && b == kind.Float, a == kind.Float && b == kind.Int:
+		return kind.Float
+	case (a == kind.Int || a == kind.Float) && b == kind.Decimal,
+		a == kind.Decimal && (b == kind.Int || b == kind.Float):
+		return kind.Decimal
+	default:
+		return kind.Text
+	}
+}
+
+// convertValue returns v, a value decoded from a BSON document, converted
+// for insertion into a column of kind k. If v can't be converted, because
+// its type doesn't match a column kind inferred from the sample, nil is
+// returned. Any value can be converted for a kind.Text column.
+func convertValue(k kind.Kind, v any) any {
+	switch v.(type) {
+	case nil, bson.Null, bson.Undefined:
+		return nil
+	default:
+	}
+
+	switch k {
+	case kind.Int:
+		switch v := v.(type) {
+		case int32:
+			return int64(v)
+		case int64:
+			return v
+		}
+	case kind.Float:
+		switch v := v.(type) {
+		case int32:
+			return float64(v)
+		case int64:
+			return float64(v)
+		case float64:
+			return v
+		}
+	case kind.Decimal:
+		switch v := v.(type) {
+		case int32:
+			return decimal.NewFromInt(int64(v))
+		case int64:
+			return decimal.NewFromInt(v)
+		case float64:
+			return decimal.NewFromFloat(v)
+		case bson.Decimal128:
+			if d, err := decimal.NewFromString(v.String()); err == nil {
+				return d
+			}
+		}
+	case kind.Bool:
+		if v, ok := v.(bool); ok {
+			return v
+		}
+	case kind.Datetime:
+		if v, ok := v.(bson.DateTime); ok {
+			return v.Time().UTC()
+		}
+	case kind.Bytes:
+		if v, ok := v.(bson.Binary); ok {
+			return v.Data
+		}
+	default:
+		return textValue(v)
+	}
+
+	return nil
+}
+
+// textValue returns the text representation of v, a non-null value decoded
+// from a BSON document. A document or array is rendered as JSON.
+func textValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bson.ObjectID:
+		return v.Hex()
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case bson.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	case bson.Decimal128:
+		return v.String()
+	case bson.D, bson.A:
+		buf := &bytes.Buffer{}
+		writeJSON(buf, v)
+		return buf.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeJSON writes v, a value decoded from a BSON document, to buf as JSON.
+// The order of a document's fields is preserved. Values without a JSON
+// equivalent, such as an ObjectID or date, are written as JSON strings.
+func writeJSON(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil, bson.Null, bson.Undefined:
+		buf.WriteString("null")
+	case bson.D:
+		buf.WriteByte('{')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, e.Key)
+			buf.WriteByte(':')
+			writeJSON(buf, e.Value)
+		}
+		buf.WriteByte('}')
+	case bson.A:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, elem)
+		}
+		buf.WriteByte(']')
+	case string, int32, int64, bool:
+		b, _ := stdj.Marshal(v)
+		buf.Write(b)
+	case float64:
+		b, err := stdj.Marshal(v)
+		if err != nil {
+			// NaN or infinity.
+			b, _ = stdj.Marshal(textValue(v))
+		}
+		buf.Write(b)
+	case bson.Binary:
+		b, _ := stdj.Marshal(v.Data)
+		buf.Write(b)
+	default:
+		b, _ := stdj.Marshal(textValue(v))
+		buf.Write(b)
+	}
+}
+
+// pathKey returns a map key for the field path.
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer, using EXPLAIN FORMAT=TREE, or
+// EXPLAIN ANALYZE when analyze is true. Both require MySQL 8.0.18 or
+// later.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*driver.PlanNode,
+	error,
+) {
+	stmt := "EXPLAIN FORMAT=TREE " + query
+	if analyze {
+		stmt = "EXPLAIN ANALYZE " + query
+	}
+
+	var tree string
+	if err := conn.QueryRowContext(ctx, stmt).Scan(&tree); err != nil {
+		return nil, errw(err)
+	}
+
+	return parsePlanTree(tree)
+}
+
+var (
+	// planEstRE matches the estimates of a plan tree line, e.g.
+	// "(cost=1.25 rows=10)".
+	planEstRE = regexp.MustCompile(`\(cost=([\d.e+-]+) rows=([\d.e+-]+)\)`)
+
+	// planActualRE matches the actuals of an analyzed plan tree line,
+	// e.g. "(actual time=0.05..0.1 rows=10 loops=1)".
+	planActualRE = regexp.MustCompile(`\(actual time=\S+ rows=([\d.e+-]+) loops=([\d.e+-]+)\)`)
+)
+
+// parsePlanTree parses the tree output of EXPLAIN FORMAT=TREE or EXPLAIN
+// ANALYZE. Each node is a line of the form "-> Table scan on actor
+// (cost=...)", indented by four spaces per level.
+func parsePlanTree(tree string) (*driver.PlanNode, error) {
+	var (
+		root  *driver.PlanNode
+		stack []*driver.PlanNode // stack[i] is the last node at depth i
+	)
+
+	for _, line := range strings.Split(tree, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(trimmed, "-> ") {
+			continue
+		}
+		depth := (len(line) - len(trimmed)) / 4
+		node := parsePlanLine(strings.TrimPrefix(trimmed, "-> "))
+
+		switch {
+		case root == nil:
+			root = node
+			stack = []*driver.PlanNode{node}
+			continue
+		case depth == 0 || depth > len(stack):
+			// Shouldn't happen; attach to the deepest node.
+			depth = len(stack)
+		}
+
+		parent := stack[depth-1]
+		parent.Children = append(parent.Children, node)
+		stack = append(stack[:depth], node)
+	}
+
+	if root == nil {
+		return nil, errz.New("mysql: query plan is empty")
+	}
+	return root, nil
+}
+
+// parsePlanLine returns the node described by a line of plan tree output,
+// sans the leading arrow.
+func parsePlanLine(line string) *driver.PlanNode {
+	node := &driver.PlanNode{}
+	desc := line
+	if i := strings.Index(line, "  ("); i >= 0 {
+		desc = line[:i]
+	}
+
+	if m := planEstRE.FindStringSubmatch(line); m != nil {
+		node.Cost = driver.ParsePlanFloat(m[1])
+		node.EstRows = driver.ParsePlanFloat(m[2])
+	}
+
+	if m := planActualRE.FindStringSubmatch(line); m != nil {
+		// The actual rows is the per-loop average.
+		rows, loops := driver.ParsePlanFloat(m[1]), driver.ParsePlanFloat(m[2])
+		if rows != nil && loops != nil {
+			*rows *= *loops
+		}
+		node.ActualRows = rows
+	}
+
+	// Separate the operation from its subject, e.g. "Table scan on actor",
+	// or "Filter: (actor.actor_id > 10)".
+	switch {
+	case strings.Contains(desc, ": "):
+		node.Op, node.Detail, _ = strings.Cut(desc, ": ")
+	case strings.Contains(desc, " on "):
+		node.Op, node.Detail, _ = strings.Cut(desc, " on ")
+	default:
+		node.Op = desc
+	}
+	return node
+}
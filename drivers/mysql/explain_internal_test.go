@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlanTree(t *testing.T) {
+	const tree = `-> Nested loop inner join  (cost=4.50 rows=10) (actual time=0.052..0.107 rows=10 loops=1)
+    -> Filter: (a.actor_id > 190)  (cost=2.25 rows=10) (actual time=0.031..0.040 rows=10 loops=1)
+        -> Table scan on a  (cost=2.25 rows=200) (actual time=0.027..0.035 rows=200 loops=1)
+    -> Index lookup on fa using PRIMARY (actor_id=a.actor_id)  (cost=0.25 rows=1) (actual time=0.004..0.005 rows=2 loops=10)
+`
+
+	plan, err := parsePlanTree(tree)
+	require.NoError(t, err)
+	require.Equal(t, "Nested loop inner join", plan.Op)
+	require.Equal(t, "", plan.Detail)
+	require.Equal(t, 4.5, *plan.Cost)
+	require.Equal(t, 10.0, *plan.EstRows)
+	require.Equal(t, 10.0, *plan.ActualRows)
+	require.Len(t, plan.Children, 2)
+
+	filter := plan.Children[0]
+	require.Equal(t, "Filter", filter.Op)
+	require.Equal(t, "(a.actor_id > 190)", filter.Detail)
+	require.Len(t, filter.Children, 1)
+	require.Equal(t, "Table scan", filter.Children[0].Op)
+	require.Equal(t, "a", filter.Children[0].Detail)
+
+	lookup := plan.Children[1]
+	require.Equal(t, "Index lookup", lookup.Op)
+	require.Equal(t, "fa using PRIMARY (actor_id=a.actor_id)", lookup.Detail)
+	require.Equal(t, 20.0, *lookup.ActualRows, "actual rows should be per-loop rows times loops")
+	require.Empty(t, lookup.Children)
+
+	// Without analyze, there are no actuals.
+	plan, err = parsePlanTree("-> Table scan on a  (cost=2.25 rows=200)\n")
+	require.NoError(t, err)
+	require.Nil(t, plan.ActualRows)
+	require.Equal(t, 200.0, *plan.EstRows)
+
+	_, err = parsePlanTree("")
+	require.Error(t, err)
+}
@@ -134,6 +134,13 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          lo.Without(jointype.All(), jointype.FullOuter),
 		Catalog:        false,
+		// MySQL's LENGTH returns the length in bytes, not characters.
+		Profile: dialect.Profile{
+			CharLength: "CHAR_LENGTH",
+			StdDev:     "STDDEV_SAMP",
+			Float:      "DOUBLE",
+			Limit:      " LIMIT %d",
+		},
 	}
 }
 
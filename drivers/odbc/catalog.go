@@ -0,0 +1,377 @@
+package odbc
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// catalogFunc identifies an ODBC catalog function. Each returns a result
+// set whose columns are defined by the ODBC spec; see the parse funcs
+// below. The ODBC driver manager does the work of mapping the database's
+// system tables to these result sets, which is what lets sq get metadata
+// from a database it otherwise knows nothing about.
+type catalogFunc string
+
+const (
+	catTables      catalogFunc = "SQLTables"
+	catColumns     catalogFunc = "SQLColumns"
+	catPrimaryKeys catalogFunc = "SQLPrimaryKeys"
+	catForeignKeys catalogFunc = "SQLForeignKeys"
+)
+
+// infoType is an ODBC SQLGetInfo information type. Only the types whose
+// value is a character string are used.
+type infoType uint16
+
+const (
+	infoDriverName          infoType = 6  // SQL_DRIVER_NAME
+	infoDriverVer           infoType = 7  // SQL_DRIVER_VER
+	infoDatabaseName        infoType = 16 // SQL_DATABASE_NAME
+	infoDBMSName            infoType = 17 // SQL_DBMS_NAME
+	infoDBMSVer             infoType = 18 // SQL_DBMS_VER
+	infoIdentifierQuoteChar infoType = 29 // SQL_IDENTIFIER_QUOTE_CHAR
+	infoUserName            infoType = 47 // SQL_USER_NAME
+)
+
+// String returns the name of the information type, as used for the keys
+// of the source's DBProperties.
+func (t infoType) String() string {
+	switch t {
+	case infoDriverName:
+		return "driver_name"
+	case infoDriverVer:
+		return "driver_ver"
+	case infoDatabaseName:
+		return "database_name"
+	case infoDBMSName:
+		return "dbms_name"
+	case infoDBMSVer:
+		return "dbms_ver"
+	case infoIdentifierQuoteChar:
+		return "identifier_quote_char"
+	case infoUserName:
+		return "user_name"
+	default:
+		return strconv.Itoa(int(t))
+	}
+}
+
+// Table types that sq lists, per the TABLE_TYPE column of SQLTables.
+const (
+	odbcTypeTable = "TABLE"
+	odbcTypeView  = "VIEW"
+)
+
+// null is the NULL catalog function argument, which matches everything.
+var null = sql.NullString{}
+
+// arg returns s as a non-NULL catalog function argument. Note that the
+// empty string is not the same as NULL: it matches only objects that
+// don't have, say, a schema.
+func arg(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+// argOrNull returns arg(s), or null if s is empty.
+func argOrNull(s string) sql.NullString {
+	if s == "" {
+		return null
+	}
+	return arg(s)
+}
+
+// val returns the value at index i of a catalog function's result row,
+// or empty string if the value is NULL or the row is too short, as it
+// may be for an ODBC 2.x driver.
+func val(row []sql.NullString, i int) string {
+	if i >= len(row) || !row[i].Valid {
+		return ""
+	}
+	return row[i].String
+}
+
+// intVal is like val, but returns the value as an int, or -1 if the value
+// isn't an integer.
+func intVal(row []sql.NullString, i int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(val(row, i)))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// tableRef identifies a table returned by SQLTables.
+type tableRef struct {
+	catalog string
+	schema  string
+	name    string
+	typ     string
+	remarks string
+}
+
+// listTables returns the tables and views of schma, or of every schema if
+// schma is empty, via SQLTables. The names may be a search pattern; use
+// findTable to match a name exactly.
+func listTables(ctx context.Context, db sqlz.DB, schma, names string) ([]tableRef, error) {
+	rows, err := queryCatalog(ctx, db, catTables,
+		null, argOrNull(schma), arg(names), arg(odbcTypeTable+","+odbcTypeView))
+	if err != nil {
+		return nil, err
+	}
+	return parseTableRows(rows), nil
+}
+
+// findTable returns the table or view named tbl in schma, or in any
+// schema if schma is empty. If there's no such table, ok is false.
+func findTable(ctx context.Context, db sqlz.DB, schma, tbl string) (ref tableRef, ok bool, err error) {
+	// The name argument of SQLTables is a pattern, in which "_" matches
+	// any character; the results are filtered for the exact name.
+	refs, err := listTables(ctx, db, schma, tbl)
+	if err != nil {
+		return ref, false, err
+	}
+
+	for _, ref = range refs {
+		if ref.name == tbl {
+			return ref, true, nil
+		}
+	}
+	return tableRef{}, false, nil
+}
+
+// parseTableRows parses the result set of SQLTables. The columns are
+// TABLE_CAT, TABLE_SCHEM, TABLE_NAME, TABLE_TYPE, and REMARKS. Rows that
+// are neither tables nor views are dropped: some drivers ignore the
+// table type argument.
+func parseTableRows(rows [][]sql.NullString) []tableRef {
+	refs := make([]tableRef, 0, len(rows))
+	for _, row := range rows {
+		ref := tableRef{
+			catalog: val(row, 0),
+			schema:  val(row, 1),
+			name:    val(row, 2),
+			typ:     strings.ToUpper(val(row, 3)),
+			remarks: val(row, 4),
+		}
+		if ref.name == "" || (ref.typ != odbcTypeTable && ref.typ != odbcTypeView) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// listSchemas returns the names of the database's schemas, via the
+// SQLTables schema enumeration: the catalog and table names are the
+// empty string, and the schema is "%". Many drivers for databases
+// without schemas, such as SQLite, return no rows.
+func listSchemas(ctx context.Context, db sqlz.DB) ([]string, error) {
+	rows, err := queryCatalog(ctx, db, catTables, arg(""), arg("%"), arg(""), arg(""))
+	if err != nil {
+		return nil, err
+	}
+	return distinctCol(rows, 1), nil
+}
+
+// listCatalogs returns the names of the database's catalogs, via the
+// SQLTables catalog enumeration: the catalog is "%", and the schema and
+// table names are the empty string.
+func listCatalogs(ctx context.Context, db sqlz.DB) ([]string, error) {
+	rows, err := queryCatalog(ctx, db, catTables, arg("%"), arg(""), arg(""), arg(""))
+	if err != nil {
+		return nil, err
+	}
+	return distinctCol(rows, 0), nil
+}
+
+// distinctCol returns the sorted, distinct, non-empty values of column i
+// of rows.
+func distinctCol(rows [][]sql.NullString, i int) []string {
+	var vals []string
+	for _, row := range rows {
+		if v := val(row, i); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	sort.Strings(vals)
+	return slices.Compact(vals)
+}
+
+// resolveSchema returns the schema that is the current schema of user,
+// or empty string if there is no such schema. ODBC has no notion of a
+// current schema, but most databases that have schemas give each user a
+// schema of the same name, subject to case folding: Db2 reports user
+// "db2inst1" and schema "DB2INST1". Where the database has no schemas,
+// or none for user, the current schema is empty, meaning all schemas.
+func resolveSchema(user string, schemas []string) string {
+	if user == "" {
+		return ""
+	}
+	for _, s := range schemas {
+		if strings.EqualFold(s, user) {
+			return s
+		}
+	}
+	return ""
+}
+
+// getColumns returns the columns of ref, via SQLColumns and
+// SQLPrimaryKeys.
+func getColumns(ctx context.Context, db sqlz.DB, ref tableRef) ([]*metadata.Column, error) {
+	rows, err := queryCatalog(ctx, db, catColumns,
+		argOrNull(ref.catalog), argOrNull(ref.schema), arg(ref.name), arg("%"))
+	if err != nil {
+		return nil, err
+	}
+	cols := parseColumnRows(ref, rows)
+
+	if ref.typ != odbcTypeTable {
+		return cols, nil
+	}
+
+	rows, err = queryCatalog(ctx, db, catPrimaryKeys,
+		argOrNull(ref.catalog), argOrNull(ref.schema), arg(ref.name))
+	if err != nil {
+		return nil, err
+	}
+	for _, pkCol := range parsePrimaryKeyRows(rows) {
+		for _, col := range cols {
+			if col.Name == pkCol {
+				col.PrimaryKey = true
+			}
+		}
+	}
+	return cols, nil
+}
+
+// parseColumnRows parses the result set of SQLColumns for table ref. The
+// columns of interest are COLUMN_NAME (3), DATA_TYPE (4), TYPE_NAME (5),
+// COLUMN_SIZE (6), DECIMAL_DIGITS (8), NULLABLE (10), REMARKS (11),
+// COLUMN_DEF (12), and ORDINAL_POSITION (16). Because the name
+// arguments are patterns, rows of other tables are dropped.
+func parseColumnRows(ref tableRef, rows [][]sql.NullString) []*metadata.Column {
+	cols := make([]*metadata.Column, 0, len(rows))
+	for _, row := range rows {
+		if val(row, 2) != ref.name || (ref.schema != "" && val(row, 1) != ref.schema) {
+			continue
+		}
+
+		code := int16(intVal(row, 4))
+		col := &metadata.Column{
+			Name:         val(row, 3),
+			Position:     int64(len(cols) + 1),
+			BaseType:     val(row, 5),
+			Kind:         kindFromSQLType(code),
+			Nullable:     intVal(row, 10) != 0, // SQL_NO_NULLS
+			Comment:      val(row, 11),
+			DefaultValue: val(row, 12),
+		}
+		if col.BaseType == "" {
+			col.BaseType = sqlTypeName(code)
+		}
+		col.ColumnType = columnTypeName(col.BaseType, code, intVal(row, 6), intVal(row, 8))
+		if pos := intVal(row, 16); pos > 0 {
+			col.Position = int64(pos)
+		}
+		cols = append(cols, col)
+	}
+
+	sort.SliceStable(cols, func(i, j int) bool { return cols[i].Position < cols[j].Position })
+	return cols
+}
+
+// parsePrimaryKeyRows parses the result set of SQLPrimaryKeys, returning
+// the names of the key's columns in key order. The columns of interest
+// are COLUMN_NAME (3) and KEY_SEQ (4).
+func parsePrimaryKeyRows(rows [][]sql.NullString) []string {
+	rows = slices.Clone(rows)
+	sort.SliceStable(rows, func(i, j int) bool { return intVal(rows[i], 4) < intVal(rows[j], 4) })
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, val(row, 3))
+	}
+	return names
+}
+
+// getForeignKeys returns the foreign keys declared on ref, via
+// SQLForeignKeys.
+func getForeignKeys(ctx context.Context, db sqlz.DB, ref tableRef) ([]*metadata.ForeignKey, error) {
+	rows, err := queryCatalog(ctx, db, catForeignKeys,
+		null, null, null,
+		argOrNull(ref.catalog), argOrNull(ref.schema), arg(ref.name))
+	if err != nil {
+		return nil, err
+	}
+	return parseForeignKeyRows(ref, rows), nil
+}
+
+// parseForeignKeyRows parses the result set of SQLForeignKeys for the
+// foreign keys declared on table ref. The columns are PKTABLE_CAT (0),
+// PKTABLE_SCHEM (1), PKTABLE_NAME (2), PKCOLUMN_NAME (3), FKTABLE_CAT (4),
+// FKTABLE_SCHEM (5), FKTABLE_NAME (6), FKCOLUMN_NAME (7), KEY_SEQ (8),
+// UPDATE_RULE (9), DELETE_RULE (10), and FK_NAME (11). A row per column
+// of each key is returned; a KEY_SEQ of 1 starts a new key.
+func parseForeignKeyRows(ref tableRef, rows [][]sql.NullString) []*metadata.ForeignKey {
+	var (
+		fks []*metadata.ForeignKey
+		fk  *metadata.ForeignKey
+	)
+
+	for _, row := range rows {
+		if val(row, 6) != ref.name {
+			continue
+		}
+
+		name := val(row, 11)
+		if fk == nil || intVal(row, 8) <= 1 || (name != "" && name != fk.Name) {
+			fk = &metadata.ForeignKey{
+				Name:     name,
+				Table:    ref.name,
+				RefTable: val(row, 2),
+				OnUpdate: fkRule(intVal(row, 9)),
+				OnDelete: fkRule(intVal(row, 10)),
+			}
+			// The reference's catalog and schema are set only if they
+			// differ from the table's own.
+			if refCatalog := val(row, 0); refCatalog != ref.catalog {
+				fk.RefCatalog = refCatalog
+			}
+			if refSchema := val(row, 1); refSchema != ref.schema {
+				fk.RefSchema = refSchema
+			}
+			fks = append(fks, fk)
+		}
+
+		fk.Columns = append(fk.Columns, val(row, 7))
+		fk.RefColumns = append(fk.RefColumns, val(row, 3))
+	}
+
+	return fks
+}
+
+// fkRule returns the referential action for the UPDATE_RULE or
+// DELETE_RULE value of an SQLForeignKeys row.
+func fkRule(rule int) string {
+	switch rule {
+	case 0: // SQL_CASCADE
+		return "CASCADE"
+	case 1: // SQL_RESTRICT
+		return "RESTRICT"
+	case 2: // SQL_SET_NULL
+		return "SET NULL"
+	case 3: // SQL_NO_ACTION
+		return "NO ACTION"
+	case 4: // SQL_SET_DEFAULT
+		return "SET DEFAULT"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,198 @@
+//go:build odbc && cgo && (linux || darwin || freebsd)
+
+package odbc
+
+// The cgo flags are those of github.com/alexbrainman/odbc/api, which
+// doesn't wrap the catalog functions or SQLGetInfo.
+
+// #cgo darwin LDFLAGS: -L /usr/local/opt/unixodbc/lib -lodbc
+// #cgo darwin CFLAGS: -I /usr/local/opt/unixodbc/include
+// #cgo linux LDFLAGS: -lodbc
+// #cgo freebsd LDFLAGS: -L /usr/local/lib -lodbc
+// #cgo freebsd CFLAGS: -I/usr/local/include
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/alexbrainman/odbc"
+	"github.com/alexbrainman/odbc/api"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+)
+
+// numCatalogArgs is the number of (string) args of each catalog func.
+var numCatalogArgs = map[catalogFunc]int{
+	catTables:      4,
+	catColumns:     4,
+	catPrimaryKeys: 3,
+	catForeignKeys: 6,
+}
+
+// queryCatalog invokes the ODBC catalog function fn, e.g. SQLTables, on a
+// connection from db, and returns the rows of its result set. A null
+// arg is passed to fn as a null pointer.
+func queryCatalog(ctx context.Context, db sqlz.DB, fn catalogFunc, args ...sql.NullString,
+) (rows [][]sql.NullString, err error) {
+	if numCatalogArgs[fn] != len(args) {
+		return nil, errz.Errorf("%s: expected %d args but got %d", fn, numCatalogArgs[fn], len(args))
+	}
+
+	err = withConnHandle(ctx, db, func(hdbc api.SQLHDBC) error {
+		var out api.SQLHANDLE
+		if ret := api.SQLAllocHandle(api.SQL_HANDLE_STMT, api.SQLHANDLE(hdbc), &out); odbc.IsError(ret) {
+			return odbc.NewError("SQLAllocHandle", hdbc)
+		}
+		h := api.SQLHSTMT(out)
+		defer api.SQLFreeHandle(api.SQL_HANDLE_STMT, out)
+
+		if ret := callCatalog(h, fn, args); odbc.IsError(ret) {
+			return odbc.NewError(string(fn), h)
+		}
+
+		rows, err = fetchStrings(h)
+		return err
+	})
+	return rows, errw(err)
+}
+
+// callCatalog calls catalog func fn on statement h.
+func callCatalog(h api.SQLHSTMT, fn catalogFunc, args []sql.NullString) api.SQLRETURN {
+	ptrs := make([]*C.SQLWCHAR, len(args))
+	lens := make([]C.SQLSMALLINT, len(args))
+	for i, a := range args {
+		if !a.Valid {
+			continue
+		}
+		b := api.StringToUTF16(a.String)
+		ptrs[i], lens[i] = (*C.SQLWCHAR)(unsafe.Pointer(&b[0])), C.SQL_NTS
+	}
+
+	hstmt := C.SQLHSTMT(unsafe.Pointer(h))
+	var ret C.SQLRETURN
+	switch fn {
+	case catTables:
+		ret = C.SQLTablesW(hstmt, ptrs[0], lens[0], ptrs[1], lens[1], ptrs[2], lens[2], ptrs[3], lens[3])
+	case catColumns:
+		ret = C.SQLColumnsW(hstmt, ptrs[0], lens[0], ptrs[1], lens[1], ptrs[2], lens[2], ptrs[3], lens[3])
+	case catPrimaryKeys:
+		ret = C.SQLPrimaryKeysW(hstmt, ptrs[0], lens[0], ptrs[1], lens[1], ptrs[2], lens[2])
+	case catForeignKeys:
+		ret = C.SQLForeignKeysW(hstmt, ptrs[0], lens[0], ptrs[1], lens[1], ptrs[2], lens[2],
+			ptrs[3], lens[3], ptrs[4], lens[4], ptrs[5], lens[5])
+	}
+	return api.SQLRETURN(ret)
+}
+
+// fetchStrings fetches the rows of the result set of statement h, each
+// value as a string.
+func fetchStrings(h api.SQLHSTMT) ([][]sql.NullString, error) {
+	var numCols api.SQLSMALLINT
+	if ret := api.SQLNumResultCols(h, &numCols); odbc.IsError(ret) {
+		return nil, odbc.NewError("SQLNumResultCols", h)
+	}
+
+	var rows [][]sql.NullString
+	buf := make([]uint16, 256)
+	for {
+		ret := api.SQLFetch(h)
+		if ret == api.SQL_NO_DATA {
+			return rows, nil
+		}
+		if odbc.IsError(ret) {
+			return nil, odbc.NewError("SQLFetch", h)
+		}
+
+		row := make([]sql.NullString, numCols)
+		for i := range row {
+			var err error
+			if row[i], err = getString(h, i+1, buf); err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// getString gets the value of column col of the current row of statement
+// h as a string, via buf. A value longer than buf is got in parts.
+func getString(h api.SQLHSTMT, col int, buf []uint16) (sql.NullString, error) {
+	var val []uint16
+	for {
+		var n api.SQLLEN
+		ret := api.SQLGetData(h, api.SQLUSMALLINT(col), api.SQL_C_WCHAR,
+			api.SQLPOINTER(unsafe.Pointer(&buf[0])), api.SQLLEN(len(buf)*2), &n)
+		switch {
+		case ret == api.SQL_NO_DATA:
+			// The value was got in full by the previous calls.
+			return sql.NullString{String: string(utf16.Decode(val)), Valid: true}, nil
+		case odbc.IsError(ret):
+			return sql.NullString{}, odbc.NewError("SQLGetData", h)
+		case n == api.SQL_NULL_DATA:
+			return sql.NullString{}, nil
+		case n == api.SQL_NO_TOTAL || int(n)/2 >= len(buf):
+			// Truncated: the buffer is full, less the null terminator.
+			val = append(val, buf[:len(buf)-1]...)
+		default:
+			val = append(val, buf[:int(n)/2]...)
+			return sql.NullString{String: string(utf16.Decode(val)), Valid: true}, nil
+		}
+	}
+}
+
+// sqlStatesUnsupported are the SQLSTATEs of a SQLGetInfo type that the
+// driver doesn't support.
+var sqlStatesUnsupported = []string{"HY096", "HYC00"}
+
+// getInfo returns the values of the specified SQLGetInfo types for a
+// connection from db. A type that the driver doesn't support is omitted.
+func getInfo(ctx context.Context, db sqlz.DB, types ...infoType) (map[infoType]string, error) {
+	info := make(map[infoType]string, len(types))
+	err := withConnHandle(ctx, db, func(hdbc api.SQLHDBC) error {
+		buf := make([]uint16, 1024)
+		for _, typ := range types {
+			var n C.SQLSMALLINT
+			ret := api.SQLRETURN(C.SQLGetInfoW(C.SQLHDBC(unsafe.Pointer(hdbc)), C.SQLUSMALLINT(typ),
+				C.SQLPOINTER(unsafe.Pointer(&buf[0])), C.SQLSMALLINT(len(buf)*2), &n))
+			if odbc.IsError(ret) {
+				err := odbc.NewError("SQLGetInfo", hdbc)
+				if slices.ContainsFunc(sqlStates(err), func(s string) bool {
+					return slices.Contains(sqlStatesUnsupported, s)
+				}) {
+					continue
+				}
+				return errz.Wrapf(err, "get info {%s}", typ)
+			}
+
+			info[typ] = string(utf16.Decode(buf[:min(int(n)/2, len(buf)-1)]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errw(err)
+	}
+	return info, nil
+}
+
+// sqlStates returns the SQLSTATE codes of the diagnostic records of an
+// ODBC error, or nil if err isn't an ODBC error.
+func sqlStates(err error) []string {
+	var odbcErr *odbc.Error
+	if !errors.As(err, &odbcErr) {
+		return nil
+	}
+
+	states := make([]string, len(odbcErr.Diag))
+	for i, rec := range odbcErr.Diag {
+		states[i] = rec.State
+	}
+	return states
+}
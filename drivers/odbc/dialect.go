@@ -0,0 +1,255 @@
+package odbc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/ast/render"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/jointype"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/driver/dialect"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// Identifier quote styles, the allowed values of OptQuote.
+const (
+	quoteDouble   = `"`
+	quoteBacktick = "`"
+	quoteBracket  = "[]"
+)
+
+// Row range styles, the allowed values of OptLimit.
+const (
+	// limitFetch is the SQL standard "OFFSET n ROWS FETCH NEXT m ROWS ONLY",
+	// as used by Db2, Oracle and Derby.
+	limitFetch = "fetch"
+
+	// limitLimit is "LIMIT m OFFSET n", as used by SQLite, MySQL and
+	// Postgres.
+	limitLimit = "limit"
+
+	// limitTop is "SELECT TOP m", as used by SQL Server, Access and
+	// Teradata. It doesn't support an offset.
+	limitTop = "top"
+
+	// limitFirst is Informix's "SELECT SKIP n FIRST m".
+	limitFirst = "first"
+)
+
+// QuoteStyles returns the allowed values of OptQuote.
+func QuoteStyles() []string {
+	return []string{quoteDouble, quoteBacktick, quoteBracket}
+}
+
+// LimitStyles returns the allowed values of OptLimit.
+func LimitStyles() []string {
+	return []string{limitFetch, limitLimit, limitTop, limitFirst}
+}
+
+// quirks holds the dialect differences of the database behind an ODBC
+// source that sq can't paper over with standard SQL.
+type quirks struct {
+	// quote is the identifier quote style, one of QuoteStyles.
+	quote string
+
+	// limit is the row range style, one of LimitStyles.
+	limit string
+}
+
+// defaultQuirks are the quirks of a database that sq knows nothing
+// about: standard SQL.
+var defaultQuirks = quirks{quote: quoteDouble, limit: limitFetch}
+
+// detectQuirks returns the quirks of the database identified by dbmsName,
+// the driver's SQL_DBMS_NAME, and quoteChar, its
+// SQL_IDENTIFIER_QUOTE_CHAR. Either may be empty, in which case the
+// standard SQL behavior is assumed.
+func detectQuirks(dbmsName, quoteChar string) quirks {
+	q := defaultQuirks
+
+	switch strings.TrimSpace(quoteChar) {
+	case "`":
+		q.quote = quoteBacktick
+	case "[", "[]":
+		q.quote = quoteBracket
+	}
+
+	name := strings.ToUpper(dbmsName)
+	switch {
+	case strings.Contains(name, "INFORMIX"):
+		q.limit = limitFirst
+	case strings.Contains(name, "TERADATA"),
+		strings.Contains(name, "ACCESS"),
+		strings.Contains(name, "SQL SERVER"):
+		q.limit = limitTop
+	case strings.Contains(name, "SQLITE"),
+		strings.Contains(name, "MYSQL"),
+		strings.Contains(name, "MARIADB"),
+		strings.Contains(name, "POSTGRES"):
+		q.limit = limitLimit
+	}
+
+	if q.quote == quoteDouble && strings.Contains(name, "ACCESS") {
+		// Access treats a double-quoted identifier as a string literal.
+		q.quote = quoteBracket
+	}
+
+	return q
+}
+
+// enquote returns the identifier quoting func for q.
+func (q quirks) enquote() func(string) string {
+	switch q.quote {
+	case quoteBacktick:
+		return stringz.BacktickQuote
+	case quoteBracket:
+		return func(s string) string {
+			return "[" + strings.ReplaceAll(s, "]", "]]") + "]"
+		}
+	default:
+		return stringz.DoubleQuote
+	}
+}
+
+// dialect returns the dialect.Dialect for q.
+func (q quirks) dialect() dialect.Dialect {
+	prof := dialect.DefaultProfile()
+	switch q.limit {
+	case limitFetch:
+		prof.Limit = " FETCH FIRST %d ROWS ONLY"
+	case limitTop, limitFirst:
+		// The row limit belongs in the SELECT clause, which the
+		// profile's Limit suffix can't express. Without it, the
+		// top-values query stops reading once it has the rows it needs.
+		prof.Limit = ""
+	}
+
+	return dialect.Dialect{
+		Type:         drivertype.ODBC,
+		Placeholders: placeholders,
+		Enquote:      q.enquote(),
+		ExecModeFor:  dialect.DefaultExecModeFor,
+		// Not every database accepts a multi-row VALUES clause, so each
+		// INSERT statement inserts a single row.
+		MaxBatchValues: 1,
+		Ops:            dialect.DefaultOps(),
+		Joins:          jointype.All(),
+		Catalog:        true,
+		Profile:        prof,
+	}
+}
+
+// placeholders returns the "?" placeholders of an insert, e.g.
+// "(?, ?), (?, ?)".
+func placeholders(numCols, numRows int) string {
+	rows := make([]string, numRows)
+	for i := range rows {
+		rows[i] = "(" + stringz.RepeatJoin("?", numCols, driver.Comma) + ")"
+	}
+	return strings.Join(rows, driver.Comma)
+}
+
+// renderer returns the render.Renderer for q.
+func (q quirks) renderer() *render.Renderer {
+	r := render.NewDefaultRenderer()
+	switch q.limit {
+	case limitFetch:
+		r.Range = renderRangeFetch
+	case limitTop:
+		r.Range = renderRangeTop
+		r.PreRender = append(r.PreRender, preRenderTop)
+	case limitFirst:
+		r.Range = renderRangeFirst
+		r.PreRender = append(r.PreRender, preRenderFirst)
+	}
+	return r
+}
+
+// renderRangeFetch renders a row range as the SQL standard
+// "OFFSET n ROWS FETCH NEXT m ROWS ONLY". The offset is omitted if
+// unset, as "FETCH FIRST m ROWS ONLY" is accepted by older versions of
+// Db2 and others that don't support OFFSET.
+func renderRangeFetch(_ *render.Context, rr *ast.RowRangeNode) (string, error) {
+	if rr == nil || (rr.Limit < 0 && rr.Offset < 0) {
+		return "", nil
+	}
+
+	if rr.Offset < 1 {
+		if rr.Limit < 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("FETCH FIRST %d ROWS ONLY", rr.Limit), nil
+	}
+
+	s := fmt.Sprintf("OFFSET %d ROWS", rr.Offset)
+	if rr.Limit > -1 {
+		s += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", rr.Limit)
+	}
+	return s, nil
+}
+
+// renderRangeTop renders a row range as "TOP m". It's moved into the
+// SELECT clause by preRenderTop. TOP can't skip rows, so a range with
+// an offset is an error.
+func renderRangeTop(_ *render.Context, rr *ast.RowRangeNode) (string, error) {
+	if rr == nil || (rr.Limit < 0 && rr.Offset < 0) {
+		return "", nil
+	}
+
+	if rr.Offset > 0 {
+		return "", errz.Errorf("row range offset is not supported by the ODBC source's limit style {%s}: "+
+			"try a different value for option driver.odbc.limit", limitTop)
+	}
+
+	if rr.Limit < 0 {
+		// An offset of zero, and no limit.
+		return "", nil
+	}
+	return fmt.Sprintf("TOP %d", rr.Limit), nil
+}
+
+// preRenderTop moves the "TOP m" rendered by renderRangeTop from the end
+// of the query to the start of the SELECT clause, after any DISTINCT.
+func preRenderTop(_ *render.Context, f *render.Fragments) error {
+	if f.Range != "" {
+		f.Columns = f.Range + " " + f.Columns
+		f.Range = ""
+	}
+	return nil
+}
+
+// renderRangeFirst renders a row range as Informix's "SKIP n FIRST m".
+// It's moved into the SELECT clause by preRenderFirst.
+func renderRangeFirst(_ *render.Context, rr *ast.RowRangeNode) (string, error) {
+	if rr == nil || (rr.Limit < 0 && rr.Offset < 0) {
+		return "", nil
+	}
+
+	var parts []string
+	if rr.Offset > 0 {
+		parts = append(parts, fmt.Sprintf("SKIP %d", rr.Offset))
+	}
+	if rr.Limit > -1 {
+		parts = append(parts, fmt.Sprintf("FIRST %d", rr.Limit))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// preRenderFirst moves the "SKIP n FIRST m" rendered by renderRangeFirst
+// from the end of the query to the start of the SELECT clause. Unlike
+// TOP, Informix requires it to precede any DISTINCT.
+func preRenderFirst(_ *render.Context, f *render.Fragments) error {
+	if f.Range == "" {
+		return nil
+	}
+	if f.Distinct != "" {
+		f.Distinct = f.Range + " " + f.Distinct
+	} else {
+		f.Distinct = f.Range
+	}
+	f.Range = ""
+	return nil
+}
@@ -0,0 +1,29 @@
+package odbc
+
+import (
+	"slices"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+// sqlStateTableNotFound is the SQLSTATE for "base table or view not found".
+const sqlStateTableNotFound = "42S02"
+
+// errw wraps any error from the ODBC driver. If err is nil, nil is returned.
+func errw(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case isErrTableNotExist(err):
+		return driver.NewNotExistError(err)
+	default:
+		return errz.Err(err)
+	}
+}
+
+// isErrTableNotExist returns true if err's diagnostic records include
+// SQLSTATE 42S02 ("base table or view not found").
+func isErrTableNotExist(err error) bool {
+	return slices.Contains(sqlStates(err), sqlStateTableNotFound)
+}
@@ -0,0 +1,110 @@
+package odbc
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/progress"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// grip implements driver.Grip for ODBC.
+type grip struct {
+	log       *slog.Logger
+	drvr      *driveri
+	db        *sql.DB
+	src       *source.Source
+	closeErr  error
+	closeOnce sync.Once
+}
+
+var _ driver.Grip = (*grip)(nil)
+
+// DB implements driver.Grip.
+func (g *grip) DB(context.Context) (*sql.DB, error) {
+	return g.db, nil
+}
+
+// SQLDriver implements driver.Grip.
+func (g *grip) SQLDriver() driver.SQLDriver {
+	return g.drvr
+}
+
+// Source implements driver.Grip.
+func (g *grip) Source() *source.Source {
+	return g.src
+}
+
+// TableMetadata implements driver.Grip.
+func (g *grip) TableMetadata(ctx context.Context, tblName string) (*metadata.Table, error) {
+	bar := progress.FromContext(ctx).NewUnitCounter(
+		g.Source().Handle+"."+tblName+": read schema", "item",
+	)
+	defer bar.Stop()
+	ctx = progress.NewBarContext(ctx, bar)
+
+	schma, err := g.drvr.CurrentSchema(ctx, g.db)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, ok, err := findTable(ctx, g.db, schma, tblName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, driver.NewNotExistError(errz.Errorf("table not found: %s", tblName))
+	}
+
+	tbl, err := getTableMetadata(ctx, g.db, g.drvr.quirks, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.typ == odbcTypeTable {
+		var fks []*metadata.ForeignKey
+		if fks, err = getForeignKeys(ctx, g.db, ref); err != nil {
+			return nil, err
+		}
+		metadata.AssignForeignKeys(lg.FromContext(ctx), []*metadata.Table{tbl}, fks)
+	}
+
+	return tbl, nil
+}
+
+// SourceMetadata implements driver.Grip.
+func (g *grip) SourceMetadata(ctx context.Context, noSchema bool) (*metadata.Source, error) {
+	bar := progress.FromContext(ctx).NewUnitCounter(
+		g.Source().Handle+": read schema", "item",
+	)
+	defer bar.Stop()
+	ctx = progress.NewBarContext(ctx, bar)
+
+	return getSourceMetadata(ctx, g.src, g.db, g.drvr.quirks, noSchema)
+}
+
+// DBSemver implements driver.Grip.
+func (g *grip) DBSemver(ctx context.Context) (string, error) {
+	return g.drvr.DBSemver(ctx, g.db)
+}
+
+// Close implements driver.Grip.
+func (g *grip) Close() error {
+	g.closeOnce.Do(func() {
+		g.closeErr = errw(g.db.Close())
+		if g.closeErr != nil {
+			g.log.Error(lgm.CloseDB, lga.Handle, g.src.Handle, lga.Err, g.closeErr)
+		} else {
+			g.log.Debug(lgm.CloseDB, lga.Handle, g.src.Handle)
+		}
+	})
+	return g.closeErr
+}
@@ -0,0 +1,321 @@
+package odbc
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/ast/render"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/schema"
+)
+
+func TestDetectQuirks(t *testing.T) {
+	testCases := []struct {
+		dbmsName  string
+		quoteChar string
+		want      quirks
+	}{
+		{"", "", defaultQuirks},
+		{"DB2/LINUXX8664", `"`, quirks{quote: quoteDouble, limit: limitFetch}},
+		{"IBM Informix Dynamic Server", `"`, quirks{quote: quoteDouble, limit: limitFirst}},
+		{"Teradata", `"`, quirks{quote: quoteDouble, limit: limitTop}},
+		{"ACCESS", "`", quirks{quote: quoteBacktick, limit: limitTop}},
+		{"ACCESS", `"`, quirks{quote: quoteBracket, limit: limitTop}},
+		{"Microsoft SQL Server", `"`, quirks{quote: quoteDouble, limit: limitTop}},
+		{"SQLite", `"`, quirks{quote: quoteDouble, limit: limitLimit}},
+		{"MySQL", "`", quirks{quote: quoteBacktick, limit: limitLimit}},
+		{"PostgreSQL", `"`, quirks{quote: quoteDouble, limit: limitLimit}},
+		{"Foo", "[", quirks{quote: quoteBracket, limit: limitFetch}},
+		{"Foo", " ", defaultQuirks},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dbmsName+"_"+tc.quoteChar, func(t *testing.T) {
+			assert.Equal(t, tc.want, detectQuirks(tc.dbmsName, tc.quoteChar))
+		})
+	}
+}
+
+func TestQuirks_Enquote(t *testing.T) {
+	assert.Equal(t, `"a""b"`, quirks{quote: quoteDouble}.enquote()(`a"b`))
+	assert.Equal(t, "`a``b`", quirks{quote: quoteBacktick}.enquote()("a`b"))
+	assert.Equal(t, "[a]]b]", quirks{quote: quoteBracket}.enquote()("a]b"))
+}
+
+func TestQuirks_Dialect(t *testing.T) {
+	dlct := quirks{quote: quoteDouble, limit: limitFetch}.dialect()
+	assert.Equal(t, " FETCH FIRST %d ROWS ONLY", dlct.Profile.Limit)
+	assert.Equal(t, 1, dlct.MaxBatchValues)
+	assert.Equal(t, "(?, ?), (?, ?)", dlct.Placeholders(2, 2))
+
+	assert.Equal(t, " LIMIT %d", quirks{quote: quoteDouble, limit: limitLimit}.dialect().Profile.Limit)
+	assert.Empty(t, quirks{quote: quoteDouble, limit: limitTop}.dialect().Profile.Limit)
+	assert.Empty(t, quirks{quote: quoteDouble, limit: limitFirst}.dialect().Profile.Limit)
+}
+
+func TestRenderRange(t *testing.T) {
+	testCases := []struct {
+		offset, limit int
+		wantFetch     string
+		wantTop       string
+		wantTopErr    bool
+		wantFirst     string
+	}{
+		{-1, -1, "", "", false, ""},
+		{0, 5, "FETCH FIRST 5 ROWS ONLY", "TOP 5", false, "FIRST 5"},
+		{-1, 5, "FETCH FIRST 5 ROWS ONLY", "TOP 5", false, "FIRST 5"},
+		{10, 5, "OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY", "", true, "SKIP 10 FIRST 5"},
+		{10, -1, "OFFSET 10 ROWS", "", true, "SKIP 10"},
+		{0, -1, "", "", false, ""},
+	}
+
+	for _, tc := range testCases {
+		rr := &ast.RowRangeNode{Offset: tc.offset, Limit: tc.limit}
+
+		got, err := renderRangeFetch(nil, rr)
+		require.NoError(t, err)
+		assert.Equal(t, tc.wantFetch, got, "fetch: %+v", tc)
+
+		got, err = renderRangeTop(nil, rr)
+		if tc.wantTopErr {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTop, got, "top: %+v", tc)
+		}
+
+		got, err = renderRangeFirst(nil, rr)
+		require.NoError(t, err)
+		assert.Equal(t, tc.wantFirst, got, "first: %+v", tc)
+	}
+}
+
+func TestPreRender(t *testing.T) {
+	f := &render.Fragments{Distinct: "DISTINCT", Columns: `"name"`, Range: "TOP 5"}
+	require.NoError(t, preRenderTop(nil, f))
+	assert.Equal(t, "DISTINCT", f.Distinct)
+	assert.Equal(t, `TOP 5 "name"`, f.Columns)
+	assert.Empty(t, f.Range)
+
+	f = &render.Fragments{Distinct: "DISTINCT", Columns: `"name"`, Range: "SKIP 1 FIRST 5"}
+	require.NoError(t, preRenderFirst(nil, f))
+	assert.Equal(t, "SKIP 1 FIRST 5 DISTINCT", f.Distinct)
+	assert.Equal(t, `"name"`, f.Columns)
+	assert.Empty(t, f.Range)
+
+	f = &render.Fragments{Columns: `"name"`, Range: "FIRST 5"}
+	require.NoError(t, preRenderFirst(nil, f))
+	assert.Equal(t, "FIRST 5", f.Distinct)
+
+	f = &render.Fragments{Columns: `"name"`}
+	require.NoError(t, preRenderTop(nil, f))
+	require.NoError(t, preRenderFirst(nil, f))
+	assert.Equal(t, `"name"`, f.Columns)
+	assert.Empty(t, f.Distinct)
+}
+
+func TestSQLTypes(t *testing.T) {
+	assert.Equal(t, kind.Text, kindFromSQLType(12))
+	assert.Equal(t, kind.Int, kindFromSQLType(-5))
+	assert.Equal(t, kind.Datetime, kindFromSQLType(93))
+	assert.Equal(t, kind.Text, kindFromSQLType(107))
+	assert.Equal(t, kind.Unknown, kindFromSQLType(-360))
+
+	assert.Equal(t, "WVARCHAR", sqlTypeName(-9))
+	assert.Equal(t, "INTERVAL", sqlTypeName(101))
+	assert.Equal(t, "SQL_TYPE(-360)", sqlTypeName(-360))
+
+	for code, typ := range sqlTypes {
+		assert.Equal(t, typ.kind, kindFromDBTypeName(sqlTypeName(code)), typ.name)
+	}
+	assert.Equal(t, kind.Text, kindFromDBTypeName("INTERVAL"))
+	assert.Equal(t, kind.Unknown, kindFromDBTypeName("SQL_TYPE(-360)"))
+}
+
+func TestColumnTypeName(t *testing.T) {
+	assert.Equal(t, "VARCHAR(40)", columnTypeName("VARCHAR", 12, 40, -1))
+	assert.Equal(t, "DECIMAL(5,2)", columnTypeName("DECIMAL", 3, 5, 2))
+	assert.Equal(t, "NUMERIC(5)", columnTypeName("NUMERIC", 2, 5, -1))
+	assert.Equal(t, "INTEGER", columnTypeName("INTEGER", 4, 10, 0))
+	assert.Equal(t, "VARCHAR", columnTypeName("VARCHAR", 12, -1, -1))
+	assert.Equal(t, "varchar(40)", columnTypeName("varchar(40)", 12, 40, -1))
+}
+
+func TestBuildCreateTableStmt(t *testing.T) {
+	tblDef := &schema.Table{
+		Name:      "person",
+		PKColName: "id",
+		Cols: []*schema.Column{
+			{Name: "id", Kind: kind.Int, NotNull: true},
+			{Name: "name", Kind: kind.Text},
+			{Name: "active", Kind: kind.Bool},
+		},
+	}
+
+	want := `CREATE TABLE [person] (
+  [id] BIGINT NOT NULL,
+  [name] VARCHAR(4000),
+  [active] SMALLINT,
+  PRIMARY KEY ([id])
+)`
+	assert.Equal(t, want, buildCreateTableStmt(quirks{quote: quoteBracket}.enquote(), tblDef))
+}
+
+// nullStrings returns a row of a catalog func result set, in which the
+// nil elements of vals are null.
+func nullStrings(vals ...any) []sql.NullString {
+	row := make([]sql.NullString, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			row[i] = sql.NullString{String: s, Valid: true}
+		}
+	}
+	return row
+}
+
+func TestParseTableRows(t *testing.T) {
+	rows := [][]sql.NullString{
+		nullStrings(nil, "MAIN", "ACTOR", "TABLE", nil),
+		nullStrings(nil, "MAIN", "ACTOR_INFO", "view", "all actors"),
+		nullStrings(nil, "SYSIBM", "SYSTABLES", "SYSTEM TABLE", nil),
+		nullStrings(nil, "MAIN", nil, "TABLE", nil),
+	}
+
+	got := parseTableRows(rows)
+	require.Len(t, got, 2)
+	assert.Equal(t, tableRef{schema: "MAIN", name: "ACTOR", typ: odbcTypeTable}, got[0])
+	assert.Equal(t, tableRef{schema: "MAIN", name: "ACTOR_INFO", typ: odbcTypeView, remarks: "all actors"}, got[1])
+}
+
+func TestDistinctCol(t *testing.T) {
+	rows := [][]sql.NullString{
+		nullStrings(nil, "b"),
+		nullStrings(nil, "a"),
+		nullStrings(nil, nil),
+		nullStrings(nil, "b"),
+	}
+	assert.Equal(t, []string{"a", "b"}, distinctCol(rows, 1))
+	assert.Empty(t, distinctCol(rows, 0))
+}
+
+func TestResolveSchema(t *testing.T) {
+	schemas := []string{"DB2INST1", "SYSIBM"}
+	assert.Equal(t, "DB2INST1", resolveSchema("db2inst1", schemas))
+	assert.Empty(t, resolveSchema("alice", schemas))
+	assert.Empty(t, resolveSchema("", schemas))
+	assert.Empty(t, resolveSchema("alice", nil))
+}
+
+func TestParseColumnRows(t *testing.T) {
+	ref := tableRef{schema: "MAIN", name: "ACTOR", typ: odbcTypeTable}
+	rows := [][]sql.NullString{
+		nullStrings(nil, "MAIN", "ACTOR", "LAST_NAME", "12", "VARCHAR", "45", nil, nil, nil, "0",
+			nil, nil, nil, nil, nil, "3"),
+		nullStrings(nil, "MAIN", "ACTOR", "ACTOR_ID", "4", "INTEGER", "10", nil, "0", nil, "0",
+			"the id", nil, nil, nil, nil, "1"),
+		nullStrings(nil, "MAIN", "ACTOR", "SCORE", "3", nil, "5", nil, "2", nil, "1",
+			nil, "0", nil, nil, nil, "2"),
+		// Matched by the "ACTOR" pattern, where "_" matches any character.
+		nullStrings(nil, "MAIN", "ACTOR2", "X", "4", "INTEGER", "10", nil, "0", nil, "1",
+			nil, nil, nil, nil, nil, "1"),
+	}
+
+	cols := parseColumnRows(ref, rows)
+	require.Len(t, cols, 3)
+
+	assert.Equal(t, "ACTOR_ID", cols[0].Name)
+	assert.Equal(t, int64(1), cols[0].Position)
+	assert.Equal(t, kind.Int, cols[0].Kind)
+	assert.Equal(t, "INTEGER", cols[0].ColumnType)
+	assert.False(t, cols[0].Nullable)
+	assert.Equal(t, "the id", cols[0].Comment)
+
+	assert.Equal(t, "SCORE", cols[1].Name)
+	assert.Equal(t, kind.Decimal, cols[1].Kind)
+	assert.Equal(t, "DECIMAL", cols[1].BaseType)
+	assert.Equal(t, "DECIMAL(5,2)", cols[1].ColumnType)
+	assert.True(t, cols[1].Nullable)
+	assert.Equal(t, "0", cols[1].DefaultValue)
+
+	assert.Equal(t, "LAST_NAME", cols[2].Name)
+	assert.Equal(t, "VARCHAR(45)", cols[2].ColumnType)
+	assert.Equal(t, kind.Text, cols[2].Kind)
+}
+
+func TestParsePrimaryKeyRows(t *testing.T) {
+	rows := [][]sql.NullString{
+		nullStrings(nil, nil, "FILM_ACTOR", "FILM_ID", "2"),
+		nullStrings(nil, nil, "FILM_ACTOR", "ACTOR_ID", "1"),
+	}
+	assert.Equal(t, []string{"ACTOR_ID", "FILM_ID"}, parsePrimaryKeyRows(rows))
+	assert.Empty(t, parsePrimaryKeyRows(nil))
+}
+
+func TestParseForeignKeyRows(t *testing.T) {
+	ref := tableRef{schema: "MAIN", name: "FILM_ACTOR", typ: odbcTypeTable}
+	rows := [][]sql.NullString{
+		nullStrings(nil, "MAIN", "ACTOR", "ACTOR_ID", nil, "MAIN", "FILM_ACTOR", "ACTOR_ID", "1",
+			"0", "1", "FK_ACTOR"),
+		nullStrings(nil, "OTHER", "FILM", "FILM_ID", nil, "MAIN", "FILM_ACTOR", "FILM_ID", "1",
+			"3", "2", "FK_FILM"),
+		nullStrings(nil, "OTHER", "FILM", "LANG_ID", nil, "MAIN", "FILM_ACTOR", "LANG_ID", "2",
+			"3", "2", "FK_FILM"),
+		nullStrings(nil, "MAIN", "ACTOR", "ACTOR_ID", nil, "MAIN", "FILM_ACTOR2", "ACTOR_ID", "1",
+			"0", "1", "FK_OTHER"),
+	}
+
+	fks := parseForeignKeyRows(ref, rows)
+	require.Len(t, fks, 2)
+
+	assert.Equal(t, "FK_ACTOR", fks[0].Name)
+	assert.Equal(t, "FILM_ACTOR", fks[0].Table)
+	assert.Equal(t, "ACTOR", fks[0].RefTable)
+	assert.Empty(t, fks[0].RefSchema)
+	assert.Equal(t, []string{"ACTOR_ID"}, fks[0].Columns)
+	assert.Equal(t, []string{"ACTOR_ID"}, fks[0].RefColumns)
+	assert.Equal(t, "CASCADE", fks[0].OnUpdate)
+	assert.Equal(t, "RESTRICT", fks[0].OnDelete)
+
+	assert.Equal(t, "FK_FILM", fks[1].Name)
+	assert.Equal(t, "OTHER", fks[1].RefSchema)
+	assert.Equal(t, []string{"FILM_ID", "LANG_ID"}, fks[1].Columns)
+	assert.Equal(t, []string{"FILM_ID", "LANG_ID"}, fks[1].RefColumns)
+	assert.Equal(t, "NO ACTION", fks[1].OnUpdate)
+	assert.Equal(t, "SET NULL", fks[1].OnDelete)
+}
+
+func TestParseSemver(t *testing.T) {
+	testCases := map[string]string{
+		"11.05.0900":       "v11.5.900",
+		"03.40.0000":       "v3.40.0",
+		"SQLite 3.45.1":    "v3.45.1",
+		"17.00":            "v17.0.0",
+		"IDS 14.10.FC10W1": "v14.10.0",
+	}
+	for raw, want := range testCases {
+		got, err := parseSemver(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	_, err := parseSemver("unknown")
+	require.Error(t, err)
+}
+
+func TestOptValidation(t *testing.T) {
+	process := func(opt options.String, v string) error {
+		_, err := opt.Process(options.Options{opt.Key(): v})
+		return err
+	}
+
+	require.NoError(t, process(OptQuote, "[]"))
+	require.NoError(t, process(OptQuote, ""))
+	require.Error(t, process(OptQuote, "'"))
+	require.NoError(t, process(OptLimit, "first"))
+	require.Error(t, process(OptLimit, "rownum"))
+}
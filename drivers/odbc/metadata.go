@@ -0,0 +1,345 @@
+package odbc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/progress"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/location"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// sqlType describes an ODBC SQL data type.
+type sqlType struct {
+	// name is the type's name, as returned by the DatabaseTypeName method
+	// of sql.ColumnType, e.g. "VARCHAR".
+	name string
+
+	// kind is the kind.Kind that values of the type are surfaced as.
+	kind kind.Kind
+}
+
+// sqlTypes maps the ODBC SQL data type codes, as returned by
+// SQLDescribeCol, and in the DATA_TYPE column of SQLColumns, to the
+// type's name and kind. The negative codes are the ODBC extensions to
+// the SQL standard types; codes below -100 are SQL Server's.
+var sqlTypes = map[int16]sqlType{
+	1:    {"CHAR", kind.Text},                // SQL_CHAR
+	2:    {"NUMERIC", kind.Decimal},          // SQL_NUMERIC
+	3:    {"DECIMAL", kind.Decimal},          // SQL_DECIMAL
+	4:    {"INTEGER", kind.Int},              // SQL_INTEGER
+	5:    {"SMALLINT", kind.Int},             // SQL_SMALLINT
+	6:    {"FLOAT", kind.Float},              // SQL_FLOAT
+	7:    {"REAL", kind.Float},               // SQL_REAL
+	8:    {"DOUBLE", kind.Float},             // SQL_DOUBLE
+	9:    {"DATE", kind.Date},                // SQL_DATE (ODBC 2.x)
+	10:   {"TIME", kind.Time},                // SQL_TIME (ODBC 2.x)
+	11:   {"TIMESTAMP", kind.Datetime},       // SQL_TIMESTAMP (ODBC 2.x)
+	12:   {"VARCHAR", kind.Text},             // SQL_VARCHAR
+	16:   {"BOOLEAN", kind.Bool},             // SQL_BOOLEAN
+	91:   {"DATE", kind.Date},                // SQL_TYPE_DATE
+	92:   {"TIME", kind.Time},                // SQL_TYPE_TIME
+	93:   {"TIMESTAMP", kind.Datetime},       // SQL_TYPE_TIMESTAMP
+	94:   {"TIME WITH TIME ZONE", kind.Time}, // SQL_TYPE_TIME_WITH_TIMEZONE
+	95:   {"TIMESTAMP WITH TIME ZONE", kind.Datetime},
+	-1:   {"LONGVARCHAR", kind.Text},        // SQL_LONGVARCHAR
+	-2:   {"BINARY", kind.Bytes},            // SQL_BINARY
+	-3:   {"VARBINARY", kind.Bytes},         // SQL_VARBINARY
+	-4:   {"LONGVARBINARY", kind.Bytes},     // SQL_LONGVARBINARY
+	-5:   {"BIGINT", kind.Int},              // SQL_BIGINT
+	-6:   {"TINYINT", kind.Int},             // SQL_TINYINT
+	-7:   {"BIT", kind.Bool},                // SQL_BIT
+	-8:   {"WCHAR", kind.Text},              // SQL_WCHAR
+	-9:   {"WVARCHAR", kind.Text},           // SQL_WVARCHAR
+	-10:  {"WLONGVARCHAR", kind.Text},       // SQL_WLONGVARCHAR
+	-11:  {"GUID", kind.Text},               // SQL_GUID
+	-152: {"XML", kind.Text},                // SQL_SS_XML
+	-154: {"TIME", kind.Time},               // SQL_SS_TIME2
+	-155: {"DATETIMEOFFSET", kind.Datetime}, // SQL_SS_TIMESTAMPOFFSET
+}
+
+// kindFromSQLType returns the kind.Kind for ODBC SQL data type code. The
+// interval types (SQL_INTERVAL_YEAR through SQL_INTERVAL_MINUTE_TO_SECOND)
+// are surfaced as text. An unknown code is kind.Unknown.
+func kindFromSQLType(code int16) kind.Kind {
+	if t, ok := sqlTypes[code]; ok {
+		return t.kind
+	}
+	if code >= 101 && code <= 113 {
+		return kind.Text
+	}
+	return kind.Unknown
+}
+
+// sqlTypeName returns the name of ODBC SQL data type code, e.g. "VARCHAR".
+// An unknown code is named for its number, e.g. "SQL_TYPE(-360)".
+func sqlTypeName(code int16) string {
+	if t, ok := sqlTypes[code]; ok {
+		return t.name
+	}
+	if code >= 101 && code <= 113 {
+		return "INTERVAL"
+	}
+	return fmt.Sprintf("SQL_TYPE(%d)", code)
+}
+
+// kindFromDBTypeName returns the kind.Kind for dbTypeName, the name of an
+// ODBC SQL data type, as returned by sqlTypeName.
+func kindFromDBTypeName(dbTypeName string) kind.Kind {
+	if dbTypeName == "INTERVAL" {
+		return kind.Text
+	}
+	for _, t := range sqlTypes {
+		if t.name == dbTypeName {
+			return t.kind
+		}
+	}
+	return kind.Unknown
+}
+
+// columnTypeName returns the full type of a column, e.g. "VARCHAR(40)" or
+// "DECIMAL(5,2)", per the COLUMN_SIZE and DECIMAL_DIGITS values of
+// SQLColumns. For types whose size isn't declared by the user, such as
+// INTEGER, typeName is returned as is. A size or digits of -1 is unknown.
+func columnTypeName(typeName string, code int16, size, digits int) string {
+	if size < 1 || strings.Contains(typeName, "(") {
+		return typeName
+	}
+
+	switch code {
+	case 1, 12, -8, -9, -2, -3: // CHAR, VARCHAR, WCHAR, WVARCHAR, BINARY, VARBINARY
+		return fmt.Sprintf("%s(%d)", typeName, size)
+	case 2, 3: // NUMERIC, DECIMAL
+		if digits < 0 {
+			return fmt.Sprintf("%s(%d)", typeName, size)
+		}
+		return fmt.Sprintf("%s(%d,%d)", typeName, size, digits)
+	default:
+		return typeName
+	}
+}
+
+// dbTypeNameFromKind returns the SQL type used for a column of kind knd
+// when sq creates a table. These are the SQL standard types, which most
+// databases accept.
+func dbTypeNameFromKind(knd kind.Kind) string {
+	switch knd {
+	case kind.Int:
+		return "BIGINT"
+	case kind.Float:
+		return "DOUBLE PRECISION"
+	case kind.Decimal:
+		// Db2 has the lowest maximum precision of the usual suspects.
+		return "DECIMAL(31,10)"
+	case kind.Bool:
+		// Many databases, e.g. Db2 before 11.1, and Teradata, don't have
+		// a BOOLEAN type.
+		return "SMALLINT"
+	case kind.Datetime:
+		return "TIMESTAMP"
+	case kind.Date:
+		return "DATE"
+	case kind.Time:
+		return "TIME"
+	case kind.Bytes:
+		return "BLOB"
+	default:
+		return "VARCHAR(4000)"
+	}
+}
+
+// getSourceMetadata returns metadata for src, via the ODBC catalog
+// functions.
+func getSourceMetadata(ctx context.Context, src *source.Source, db *sql.DB, q quirks, noSchema bool,
+) (*metadata.Source, error) {
+	log := lg.FromContext(ctx)
+
+	info, err := getInfo(ctx, db, dbPropertyInfoTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &metadata.Source{
+		Handle:    src.Handle,
+		Location:  src.Location,
+		Driver:    drivertype.ODBC,
+		DBDriver:  drivertype.ODBC,
+		Catalog:   info[infoDatabaseName],
+		User:      info[infoUserName],
+		DBProduct: strings.TrimSpace(info[infoDBMSName] + " " + info[infoDBMSVer]),
+		DBVersion: info[infoDBMSVer],
+	}
+	md.DBProperties = infoProps(info)
+
+	if md.DBSemver, err = parseSemver(md.DBVersion); err != nil {
+		log.Warn("Cannot derive db_semver from db_version", lga.Err, err, lga.Version, md.DBVersion)
+		md.DBSemver = ""
+	}
+
+	schemas, err := listSchemas(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	md.Schema = resolveSchema(md.User, schemas)
+
+	md.Name = md.Catalog
+	if md.Name == "" {
+		// The driver may not know the database's name, as is the case for
+		// some file-based databases: use the name from the location.
+		if fields, fieldsErr := location.Parse(src.Location); fieldsErr == nil {
+			md.Name = fields.Name
+		}
+	}
+	md.FQName = qualifiedName(md.Catalog, md.Schema)
+	if md.FQName == "" {
+		md.FQName = md.Name
+	}
+
+	if noSchema {
+		return md, nil
+	}
+
+	refs, err := listTables(ctx, db, md.Schema, "%")
+	if err != nil {
+		return nil, err
+	}
+
+	var fks []*metadata.ForeignKey
+	for _, ref := range refs {
+		var tbl *metadata.Table
+		if tbl, err = getTableMetadata(ctx, db, q, ref); err != nil {
+			return nil, err
+		}
+		md.Tables = append(md.Tables, tbl)
+
+		if ref.typ == odbcTypeTable {
+			var tblFKs []*metadata.ForeignKey
+			if tblFKs, err = getForeignKeys(ctx, db, ref); err != nil {
+				return nil, err
+			}
+			fks = append(fks, tblFKs...)
+		}
+	}
+
+	md.RecomputeTableCounts()
+	metadata.AssignForeignKeys(log, md.Tables, fks)
+	metadata.LinkForeignKeys(log, md)
+	return md, nil
+}
+
+// getTableMetadata returns metadata for the table or view identified by
+// ref. Foreign keys are not loaded.
+func getTableMetadata(ctx context.Context, db sqlz.DB, q quirks, ref tableRef) (*metadata.Table, error) {
+	tbl := &metadata.Table{
+		Name:        ref.name,
+		FQName:      qualifiedName(ref.catalog, ref.schema, ref.name),
+		DBTableType: ref.typ,
+		Comment:     ref.remarks,
+		TableType:   sqlz.TableTypeTable,
+	}
+	if ref.typ == odbcTypeView {
+		tbl.TableType = sqlz.TableTypeView
+	}
+
+	var err error
+	if tbl.Columns, err = getColumns(ctx, db, ref); err != nil {
+		return nil, err
+	}
+
+	// ODBC has no portable means of getting a table's size, or an
+	// estimated row count, so the rows are counted.
+	enquote := q.enquote()
+	tblRef := enquote(ref.name)
+	if ref.schema != "" {
+		tblRef = enquote(ref.schema) + "." + tblRef
+	}
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tblRef).Scan(&tbl.RowCount); err != nil {
+		return nil, errw(err)
+	}
+
+	progress.Incr(ctx, 1)
+	return tbl, nil
+}
+
+// qualifiedName joins the non-empty parts of a name with ".", e.g.
+// "SAMPLE.DB2INST1.ACTOR".
+func qualifiedName(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ".")
+}
+
+// dbPropertyInfoTypes are the SQLGetInfo types that are surfaced as the
+// source's DBProperties.
+var dbPropertyInfoTypes = []infoType{
+	infoDBMSName, infoDBMSVer, infoDatabaseName, infoUserName,
+	infoDriverName, infoDriverVer, infoIdentifierQuoteChar,
+}
+
+// getDBProperties returns the properties of the database, and the driver
+// that connects to it, per SQLGetInfo.
+func getDBProperties(ctx context.Context, db sqlz.DB) (map[string]any, error) {
+	info, err := getInfo(ctx, db, dbPropertyInfoTypes...)
+	if err != nil {
+		return nil, err
+	}
+	return infoProps(info), nil
+}
+
+// infoProps returns info as DBProperties, keyed by infoType.String.
+func infoProps(info map[infoType]string) map[string]any {
+	props := make(map[string]any, len(info))
+	for typ, v := range info {
+		props[typ.String()] = v
+	}
+	return props
+}
+
+// semverRx matches the first dotted-numeric version token of a version
+// string. The ODBC spec requires SQL_DBMS_VER to be of the form
+// "##.##.####", e.g. "11.05.0900", but some drivers prefix it with the
+// product name.
+var semverRx = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// parseSemver normalizes an SQL_DBMS_VER value, such as "11.05.0900", to
+// canonical semver, such as "v11.5.900".
+func parseSemver(raw string) (string, error) {
+	m := semverRx.FindStringSubmatch(raw)
+	if m == nil {
+		return "", errz.Errorf("no semver in ODBC DBMS version string: %q", raw)
+	}
+
+	parts := make([]string, 0, 3)
+	for _, p := range m[1:] {
+		if p == "" {
+			break
+		}
+		p = strings.TrimLeft(p, "0")
+		if p == "" {
+			p = "0"
+		}
+		parts = append(parts, p)
+	}
+
+	v := semver.Canonical("v" + strings.Join(parts, "."))
+	if !semver.IsValid(v) {
+		return "", errz.Errorf("invalid ODBC DBMS semver %q from %q", v, raw)
+	}
+	return v, nil
+}
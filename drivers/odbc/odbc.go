@@ -0,0 +1,703 @@
+// Package odbc implements the sq driver for databases that are reached
+// via ODBC, such as Db2, Informix, Teradata, or Access. The source's
+// location is an ODBC connection string or DSN, prefixed with "odbc://",
+// e.g. "odbc://DSN=db2prod;UID=alice;PWD=secret".
+//
+// sq knows nothing in particular about the database behind an ODBC
+// source: metadata is read via the ODBC catalog functions (SQLTables,
+// SQLColumns etc.), and queries are rendered as conservative standard
+// SQL. The few dialect differences that can't be avoided, namely how
+// identifiers are quoted, and how the rows of a query are limited, are
+// detected from the driver's SQLGetInfo, and can be configured per
+// source via OptQuote and OptLimit.
+//
+// The ODBC driver manager (unixODBC on Linux and macOS) is a C library,
+// and so ODBC support requires cgo, and the "odbc" build tag. If sq is
+// built without it, the driver is still registered, but opening an ODBC
+// source returns an error.
+package odbc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/ast/render"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/core/schema"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/core/tablefq"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/driver/dialect"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/location"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+var (
+	// OptQuote is the identifier quote style of an ODBC source.
+	OptQuote = options.NewString(
+		"driver.odbc.quote",
+		nil,
+		"",
+		func(s string) error {
+			if s != "" && !slices.Contains(QuoteStyles(), s) {
+				return errz.Errorf("invalid ODBC quote style {%s}: expected one of: %s",
+					s, strings.Join(QuoteStyles(), ", "))
+			}
+			return nil
+		},
+		"Identifier quote style of ODBC source",
+		`Identifier quote style of an ODBC source. Allowed values:
+
+  "    Double quotes, per the SQL standard: "first_name"
+  `+"`"+`    Backticks, as used by MySQL: `+"`first_name`"+`
+  []   Square brackets, as used by Access and SQL Server: [first_name]
+
+If empty, the style is determined from the database's identifier quote
+character, as reported by the ODBC driver.`,
+		options.TagSource,
+		"odbc",
+	)
+
+	// OptLimit is the row range style of an ODBC source.
+	OptLimit = options.NewString(
+		"driver.odbc.limit",
+		nil,
+		"",
+		func(s string) error {
+			if s != "" && !slices.Contains(LimitStyles(), s) {
+				return errz.Errorf("invalid ODBC limit style {%s}: expected one of: %s",
+					s, strings.Join(LimitStyles(), ", "))
+			}
+			return nil
+		},
+		"Row range style of ODBC source",
+		`Style of the SQL that limits the rows of a query, as when the query has a
+row range such as ".[10:20]", for an ODBC source. Allowed values:
+
+  fetch   OFFSET 10 ROWS FETCH NEXT 10 ROWS ONLY  (SQL standard; Db2)
+  limit   LIMIT 10 OFFSET 10                      (SQLite, MySQL, Postgres)
+  top     SELECT TOP 10                           (Access, Teradata)
+  first   SELECT SKIP 10 FIRST 10                 (Informix)
+
+The top style can't skip rows, so a row range with an offset is an error.
+If empty, the style is determined from the database product name, as
+reported by the ODBC driver, defaulting to fetch.`,
+		options.TagSource,
+		"odbc",
+	)
+)
+
+// Provider is the ODBC implementation of driver.Provider.
+type Provider struct {
+	Log *slog.Logger
+}
+
+// DriverFor implements driver.Provider.
+func (p *Provider) DriverFor(typ drivertype.Type) (driver.Driver, error) {
+	if typ != drivertype.ODBC {
+		return nil, errz.Errorf("unsupported driver type {%s}", typ)
+	}
+
+	return &driveri{log: p.Log, quirks: defaultQuirks}, nil
+}
+
+var _ driver.SQLDriver = (*driveri)(nil)
+
+// driveri is the ODBC implementation of driver.Driver. The dialect of a
+// driveri returned by Provider.DriverFor is standard SQL; the grip of
+// an open source has its own driveri, whose dialect is adapted to the
+// source's database: see driveri.forSource.
+type driveri struct {
+	log    *slog.Logger
+	quirks quirks
+}
+
+// ConnParams implements driver.SQLDriver. An ODBC location is a
+// connection string rather than a URL, and so has no query params.
+func (d *driveri) ConnParams() map[string][]string {
+	return nil
+}
+
+// LocationShape implements driver.SQLDriver. An ODBC connection string
+// isn't URL-shaped, so the location isn't offered for completion.
+func (d *driveri) LocationShape() driver.LocationShape {
+	return driver.LocationShape{}
+}
+
+// ErrWrapFunc implements driver.SQLDriver.
+func (d *driveri) ErrWrapFunc() func(error) error {
+	return errw
+}
+
+// DriverMetadata implements driver.Driver.
+func (d *driveri) DriverMetadata() driver.Metadata {
+	return driver.Metadata{
+		Type:        drivertype.ODBC,
+		Description: "ODBC",
+		Doc:         "https://github.com/alexbrainman/odbc",
+		IsSQL:       true,
+	}
+}
+
+// Dialect implements driver.SQLDriver.
+func (d *driveri) Dialect() dialect.Dialect {
+	return d.quirks.dialect()
+}
+
+// Renderer implements driver.SQLDriver.
+func (d *driveri) Renderer() *render.Renderer {
+	return d.quirks.renderer()
+}
+
+// Open implements driver.Driver.
+func (d *driveri) Open(ctx context.Context, src *source.Source, _ driver.AccessMode) (driver.Grip, error) {
+	lg.FromContext(ctx).Debug(lgm.OpenSrc, lga.Src, src)
+
+	db, err := d.doOpen(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = driver.OpeningPing(ctx, src, db); err != nil {
+		return nil, err
+	}
+
+	drvr, err := d.forSource(ctx, src, db)
+	if err != nil {
+		lg.WarnIfCloseError(d.log, lgm.CloseDB, db)
+		return nil, err
+	}
+
+	return &grip{log: d.log, db: db, src: src, drvr: drvr}, nil
+}
+
+// forSource returns a copy of d whose quirks are those of src's database,
+// per options OptQuote and OptLimit, or else as detected via db.
+func (d *driveri) forSource(ctx context.Context, src *source.Source, db sqlz.DB) (*driveri, error) {
+	o := options.Merge(options.FromContext(ctx), src.Options)
+	q := quirks{quote: OptQuote.Get(o), limit: OptLimit.Get(o)}
+
+	if q.quote == "" || q.limit == "" {
+		info, err := getInfo(ctx, db, infoDBMSName, infoIdentifierQuoteChar)
+		if err != nil {
+			return nil, err
+		}
+
+		detected := detectQuirks(info[infoDBMSName], info[infoIdentifierQuoteChar])
+		if q.quote == "" {
+			q.quote = detected.quote
+		}
+		if q.limit == "" {
+			q.limit = detected.limit
+		}
+	}
+
+	lg.FromContext(ctx).Debug("ODBC source dialect", lga.Src, src,
+		OptQuote.Key(), q.quote, OptLimit.Key(), q.limit)
+	return &driveri{log: d.log, quirks: q}, nil
+}
+
+// doOpen opens a connection to the database via the ODBC driver manager.
+func (d *driveri) doOpen(ctx context.Context, src *source.Source) (*sql.DB, error) {
+	connStr, err := location.ODBCConnString(src.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openDB(connStr)
+	if err != nil {
+		return nil, errw(err)
+	}
+	driver.ConfigureDB(ctx, db, src.Options)
+
+	return db, nil
+}
+
+// ValidateSource implements driver.Driver.
+func (d *driveri) ValidateSource(src *source.Source) (*source.Source, error) {
+	if src.Type != drivertype.ODBC {
+		return nil, errz.Errorf("expected driver type {%s} but got {%s}", drivertype.ODBC, src.Type)
+	}
+
+	if _, err := location.ODBCConnString(src.Location); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// Ping implements driver.Driver.
+func (d *driveri) Ping(ctx context.Context, src *source.Source, _ driver.AccessMode) error {
+	db, err := d.doOpen(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer lg.WarnIfCloseError(d.log, lgm.CloseDB, db)
+
+	return errw(db.PingContext(ctx))
+}
+
+// CurrentSchema implements driver.SQLDriver. ODBC has no notion of a
+// current schema: it's the schema named for the user, if there is one.
+// If there isn't, as is the case for databases that don't have schemas,
+// the empty string is returned.
+func (d *driveri) CurrentSchema(ctx context.Context, db sqlz.DB) (string, error) {
+	info, err := getInfo(ctx, db, infoUserName)
+	if err != nil {
+		return "", err
+	}
+
+	schemas, err := listSchemas(ctx, db)
+	if err != nil {
+		return "", err
+	}
+	return resolveSchema(info[infoUserName], schemas), nil
+}
+
+// ListSchemas implements driver.SQLDriver.
+func (d *driveri) ListSchemas(ctx context.Context, db sqlz.DB) ([]string, error) {
+	return listSchemas(ctx, db)
+}
+
+// ListSchemaMetadata implements driver.SQLDriver.
+func (d *driveri) ListSchemaMetadata(ctx context.Context, db sqlz.DB) ([]*metadata.Schema, error) {
+	schemaNames, err := listSchemas(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := getInfo(ctx, db, infoDatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]*metadata.Schema, len(schemaNames))
+	for i, name := range schemaNames {
+		schemas[i] = &metadata.Schema{Name: name, Catalog: info[infoDatabaseName]}
+	}
+	return schemas, nil
+}
+
+// CurrentCatalog implements driver.SQLDriver. It's the database name, as
+// reported by the ODBC driver.
+func (d *driveri) CurrentCatalog(ctx context.Context, db sqlz.DB) (string, error) {
+	info, err := getInfo(ctx, db, infoDatabaseName)
+	if err != nil {
+		return "", err
+	}
+	if info[infoDatabaseName] == "" {
+		return "", errz.New("ODBC driver does not report the current catalog")
+	}
+	return info[infoDatabaseName], nil
+}
+
+// ListCatalogs implements driver.SQLDriver.
+func (d *driveri) ListCatalogs(ctx context.Context, db sqlz.DB) ([]string, error) {
+	return listCatalogs(ctx, db)
+}
+
+// CreateSchema implements driver.SQLDriver.
+func (d *driveri) CreateSchema(ctx context.Context, db sqlz.DB, schemaName string) error {
+	_, err := db.ExecContext(ctx, "CREATE SCHEMA "+d.quirks.enquote()(schemaName))
+	return errw(err)
+}
+
+// DropSchema implements driver.SQLDriver.
+func (d *driveri) DropSchema(ctx context.Context, db sqlz.DB, schemaName string) error {
+	_, err := db.ExecContext(ctx, "DROP SCHEMA "+d.quirks.enquote()(schemaName))
+	return errw(err)
+}
+
+// SchemaExists implements driver.SQLDriver.
+func (d *driveri) SchemaExists(ctx context.Context, db sqlz.DB, schma string) (bool, error) {
+	schemas, err := listSchemas(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(schemas, schma), nil
+}
+
+// CatalogExists implements driver.SQLDriver.
+func (d *driveri) CatalogExists(ctx context.Context, db sqlz.DB, catalog string) (bool, error) {
+	catalogs, err := listCatalogs(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(catalogs, catalog), nil
+}
+
+// TableExists implements driver.SQLDriver.
+func (d *driveri) TableExists(ctx context.Context, db sqlz.DB, tbl string) (bool, error) {
+	schma, err := d.CurrentSchema(ctx, db)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok, err := findTable(ctx, db, schma, tbl)
+	return ok, err
+}
+
+// ListTableNames implements driver.SQLDriver.
+func (d *driveri) ListTableNames(ctx context.Context, db sqlz.DB, schma string, tables, views bool) ([]string, error) {
+	names := []string{}
+	if !tables && !views {
+		return names, nil
+	}
+
+	if schma == "" {
+		var err error
+		if schma, err = d.CurrentSchema(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	refs, err := listTables(ctx, db, schma, "%")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if (ref.typ == odbcTypeTable && tables) || (ref.typ == odbcTypeView && views) {
+			names = append(names, ref.name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// tblRef returns the rendered, quoted reference to tbl.
+func (d *driveri) tblRef(tbl tablefq.T) string {
+	// The catalog is dropped: few databases support three-part names.
+	return tablefq.T{Schema: tbl.Schema, Table: tbl.Table}.Render(d.quirks.enquote())
+}
+
+// DropTable implements driver.SQLDriver. Not every database supports
+// "DROP TABLE IF EXISTS", so if ifExists is true, the table's existence
+// is checked first.
+func (d *driveri) DropTable(ctx context.Context, db sqlz.DB, tbl tablefq.T, ifExists bool) error {
+	if ifExists {
+		_, ok, err := findTable(ctx, db, tbl.Schema, tbl.Table)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	_, err := db.ExecContext(ctx, "DROP TABLE "+d.tblRef(tbl))
+	return errw(err)
+}
+
+// Truncate implements driver.SQLDriver. It deletes the table's rows, as
+// not every database supports TRUNCATE. Arg reset is ignored: there's no
+// portable means of resetting a table's identity column.
+func (d *driveri) Truncate(ctx context.Context, src *source.Source, tbl string, _ bool) (int64, error) {
+	db, err := d.doOpen(ctx, src)
+	if err != nil {
+		return 0, err
+	}
+	defer lg.WarnIfCloseError(d.log, lgm.CloseDB, db)
+
+	affected, err := sqlz.ExecAffected(ctx, db, "DELETE FROM "+d.tblRef(tablefq.New(tbl)))
+	return affected, errw(err)
+}
+
+// CreateTable implements driver.SQLDriver.
+func (d *driveri) CreateTable(ctx context.Context, db sqlz.DB, tblDef *schema.Table) error {
+	_, err := db.ExecContext(ctx, buildCreateTableStmt(d.quirks.enquote(), tblDef))
+	return errw(err)
+}
+
+// buildCreateTableStmt returns the CREATE TABLE statement for tblDef,
+// using the SQL standard types of dbTypeNameFromKind.
+func buildCreateTableStmt(enquote func(string) string, tblDef *schema.Table) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE ")
+	sb.WriteString(enquote(tblDef.Name))
+	sb.WriteString(" (")
+
+	for i, colDef := range tblDef.Cols {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		sb.WriteString("\n  ")
+		sb.WriteString(enquote(colDef.Name))
+		sb.WriteRune(' ')
+		sb.WriteString(dbTypeNameFromKind(colDef.Kind))
+		if colDef.NotNull {
+			sb.WriteString(" NOT NULL")
+		}
+	}
+
+	if tblDef.PKColName != "" {
+		sb.WriteString(",\n  PRIMARY KEY (")
+		sb.WriteString(enquote(tblDef.PKColName))
+		sb.WriteRune(')')
+	}
+
+	sb.WriteString("\n)")
+	return sb.String()
+}
+
+// CopyTable implements driver.SQLDriver. The new table's columns have the
+// types reported by SQLColumns for fromTable; constraints aren't copied.
+func (d *driveri) CopyTable(ctx context.Context, db sqlz.DB, fromTable, toTable tablefq.T, copyData bool,
+) (int64, error) {
+	ref, ok, err := findTable(ctx, db, fromTable.Schema, fromTable.Table)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, driver.NewNotExistError(errz.Errorf("table not found: %s", fromTable))
+	}
+
+	cols, err := getColumns(ctx, db, ref)
+	if err != nil {
+		return 0, err
+	}
+
+	enquote := d.quirks.enquote()
+	colDefs := make([]string, len(cols))
+	for i, col := range cols {
+		colDefs[i] = enquote(col.Name) + " " + col.ColumnType
+	}
+
+	stmt := fmt.Sprintf("CREATE TABLE %s (%s)", d.tblRef(toTable), strings.Join(colDefs, ", "))
+	if _, err = db.ExecContext(ctx, stmt); err != nil {
+		return 0, errw(err)
+	}
+
+	if !copyData {
+		return 0, nil
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", d.tblRef(toTable), d.tblRef(fromTable))
+	affected, err := sqlz.ExecAffected(ctx, db, stmt)
+	return affected, errw(err)
+}
+
+// AlterTableAddColumn implements driver.SQLDriver.
+func (d *driveri) AlterTableAddColumn(ctx context.Context, db sqlz.DB, tbl, col string, knd kind.Kind) error {
+	enquote := d.quirks.enquote()
+	q := fmt.Sprintf("ALTER TABLE %s ADD %s %s", enquote(tbl), enquote(col), dbTypeNameFromKind(knd))
+	_, err := db.ExecContext(ctx, q)
+	return errw(err)
+}
+
+// AlterTableRename implements driver.SQLDriver.
+func (d *driveri) AlterTableRename(ctx context.Context, db sqlz.DB, tbl, newName string) error {
+	enquote := d.quirks.enquote()
+	q := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", enquote(tbl), enquote(newName))
+	_, err := db.ExecContext(ctx, q)
+	return errw(err)
+}
+
+// AlterTableRenameColumn implements driver.SQLDriver.
+func (d *driveri) AlterTableRenameColumn(ctx context.Context, db sqlz.DB, tbl, col, newName string) error {
+	enquote := d.quirks.enquote()
+	q := fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", enquote(tbl), enquote(col), enquote(newName))
+	_, err := db.ExecContext(ctx, q)
+	return errw(err)
+}
+
+// AlterTableColumnKinds implements driver.SQLDriver. The syntax for
+// changing a column's type varies too much across databases.
+func (d *driveri) AlterTableColumnKinds(_ context.Context, _ sqlz.DB, _ string, _ []string, _ []kind.Kind) error {
+	return errz.New("AlterTableColumnKinds: not supported for ODBC sources")
+}
+
+// TableColumnTypes implements driver.SQLDriver.
+func (d *driveri) TableColumnTypes(ctx context.Context, db sqlz.DB, tblName string, colNames []string,
+) ([]*sql.ColumnType, error) {
+	enquote := d.quirks.enquote()
+
+	colsClause := "*"
+	if len(colNames) > 0 {
+		quoted := make([]string, len(colNames))
+		for i, colName := range colNames {
+			quoted[i] = enquote(colName)
+		}
+		colsClause = strings.Join(quoted, driver.Comma)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE 1=0", colsClause, enquote(tblName))
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(d.log, lgm.CloseDBRows, rows)
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, errw(err)
+	}
+	return colTypes, errw(rows.Err())
+}
+
+// RecordMeta implements driver.SQLDriver.
+func (d *driveri) RecordMeta(ctx context.Context, colTypes []*sql.ColumnType, kindHints map[int]kind.Kind,
+) (record.Meta, driver.NewRecordFunc, error) {
+	sColTypeData := make([]*record.ColumnTypeData, len(colTypes))
+	ogColNames := make([]string, len(colTypes))
+	for i, colType := range colTypes {
+		knd := kindFromDBTypeName(colType.DatabaseTypeName())
+		if hint, ok := kindHints[i]; ok {
+			knd = hint
+		}
+		colTypeData := record.NewColumnTypeData(colType, knd)
+		setScanType(colTypeData, knd)
+		sColTypeData[i] = colTypeData
+		ogColNames[i] = colTypeData.Name
+	}
+
+	mungedColNames, err := driver.MungeResultColNames(ctx, ogColNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recMeta := make(record.Meta, len(colTypes))
+	for i := range sColTypeData {
+		recMeta[i] = record.NewFieldMeta(sColTypeData[i], mungedColNames[i])
+	}
+
+	mungeFn := func(row []any) (record.Record, error) {
+		rec, skipped := driver.NewRecordFromScanRow(recMeta, row, nil)
+		if len(skipped) > 0 {
+			return nil, errz.Errorf("expected zero skipped cols but have %v", skipped)
+		}
+		return rec, nil
+	}
+
+	return recMeta, mungeFn, nil
+}
+
+// setScanType sets the scan type of colTypeData per knd. The ODBC driver
+// returns a value of a fixed Go type for each SQL type, e.g. int32 for
+// INTEGER, and float64 for DECIMAL, each of which the scan type accepts.
+func setScanType(colTypeData *record.ColumnTypeData, knd kind.Kind) {
+	switch knd {
+	case kind.Int:
+		colTypeData.ScanType = sqlz.RTypeNullInt64
+	case kind.Float:
+		colTypeData.ScanType = sqlz.RTypeNullFloat64
+	case kind.Decimal:
+		colTypeData.ScanType = sqlz.RTypeNullDecimal
+	case kind.Bool:
+		colTypeData.ScanType = sqlz.RTypeNullBool
+	case kind.Datetime, kind.Date, kind.Time:
+		colTypeData.ScanType = sqlz.RTypeNullTime
+	case kind.Bytes:
+		colTypeData.ScanType = sqlz.RTypeBytes
+	default:
+		colTypeData.ScanType = sqlz.RTypeNullString
+	}
+}
+
+// getTableRecordMeta returns the record metadata for the specified
+// columns of a table.
+func (d *driveri) getTableRecordMeta(ctx context.Context, db sqlz.DB, tblName string, colNames []string,
+) (record.Meta, error) {
+	colTypes, err := d.TableColumnTypes(ctx, db, tblName, colNames)
+	if err != nil {
+		return nil, err
+	}
+
+	recMeta, _, err := d.RecordMeta(ctx, colTypes, nil)
+	return recMeta, err
+}
+
+// newStmtExecFunc returns a StmtExecFunc that wraps stmt.ExecContext.
+func newStmtExecFunc(stmt *sql.Stmt) driver.StmtExecFunc {
+	return func(ctx context.Context, args ...any) (int64, error) {
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return 0, errw(err)
+		}
+		affected, err := res.RowsAffected()
+		return affected, errw(err)
+	}
+}
+
+// PrepareInsertStmt implements driver.SQLDriver.
+func (d *driveri) PrepareInsertStmt(ctx context.Context, db sqlz.DB, destTbl string, destColNames []string,
+	numRows int,
+) (*driver.StmtExecer, error) {
+	destColsMeta, err := d.getTableRecordMeta(ctx, db, destTbl, destColNames)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := driver.PrepareInsertStmt(ctx, d, db, destTbl, destColsMeta.Names(), numRows)
+	if err != nil {
+		return nil, err
+	}
+
+	execer := driver.NewStmtExecer(stmt, driver.DefaultInsertMungeFunc(destTbl, destColsMeta),
+		newStmtExecFunc(stmt), destColsMeta)
+	return execer, nil
+}
+
+// NewBatchInsert implements driver.SQLDriver.
+func (d *driveri) NewBatchInsert(ctx context.Context, msg string, db sqlz.DB,
+	_ *source.Source, destTbl string, destColNames []string,
+) (*driver.BatchInsert, error) {
+	return driver.DefaultNewBatchInsert(ctx, msg, d, db, destTbl, destColNames)
+}
+
+// PrepareUpdateStmt implements driver.SQLDriver.
+func (d *driveri) PrepareUpdateStmt(ctx context.Context, db sqlz.DB, destTbl string, destColNames []string,
+	where string,
+) (*driver.StmtExecer, error) {
+	destColsMeta, err := d.getTableRecordMeta(ctx, db, destTbl, destColNames)
+	if err != nil {
+		return nil, err
+	}
+
+	enquote := d.quirks.enquote()
+	setClause := make([]string, len(destColNames))
+	for i, colName := range destColNames {
+		setClause[i] = enquote(colName) + " = ?"
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s", enquote(destTbl), strings.Join(setClause, driver.Comma))
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, errw(err)
+	}
+
+	execer := driver.NewStmtExecer(stmt, driver.DefaultInsertMungeFunc(destTbl, destColsMeta),
+		newStmtExecFunc(stmt), destColsMeta)
+	return execer, nil
+}
+
+// DBProperties implements driver.SQLDriver.
+func (d *driveri) DBProperties(ctx context.Context, db sqlz.DB) (map[string]any, error) {
+	return getDBProperties(ctx, db)
+}
+
+// DBSemver implements driver.SQLDriver.
+func (d *driveri) DBSemver(ctx context.Context, db sqlz.DB) (string, error) {
+	info, err := getInfo(ctx, db, infoDBMSVer)
+	if err != nil {
+		return "", err
+	}
+	return parseSemver(info[infoDBMSVer])
+}
@@ -0,0 +1,38 @@
+//go:build !(odbc && cgo && (linux || darwin || freebsd))
+
+package odbc
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+)
+
+// errNoODBC is returned by the ODBC bridge funcs when sq is built
+// without ODBC support.
+var errNoODBC = errz.New("sq was built without ODBC support: rebuild with cgo and -tags odbc")
+
+// openDB opens the database identified by the ODBC connection string.
+func openDB(string) (*sql.DB, error) {
+	return nil, errNoODBC
+}
+
+// queryCatalog invokes the ODBC catalog function fn, e.g. SQLTables, on a
+// connection from db, and returns the rows of its result set.
+func queryCatalog(context.Context, sqlz.DB, catalogFunc, ...sql.NullString) ([][]sql.NullString, error) {
+	return nil, errNoODBC
+}
+
+// getInfo returns the values of the specified SQLGetInfo types for a
+// connection from db.
+func getInfo(context.Context, sqlz.DB, ...infoType) (map[infoType]string, error) {
+	return nil, errNoODBC
+}
+
+// sqlStates returns the SQLSTATE codes of the diagnostic records of an
+// ODBC error, or nil if err isn't an ODBC error.
+func sqlStates(error) []string {
+	return nil
+}
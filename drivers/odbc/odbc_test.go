@@ -0,0 +1,117 @@
+//go:build odbc && cgo && (linux || darwin || freebsd)
+
+package odbc_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/schema"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// envODBCConn is the envar that holds the ODBC connection string of the
+// test database, e.g. "Driver=SQLite3;Database=/tmp/sq_odbc.db" for the
+// SQLite ODBC driver under unixODBC. If it's unset, the tests that need
+// a database are skipped.
+const envODBCConn = "SQ_TEST_ODBC_CONN"
+
+// newODBCSource returns a test helper, and the ODBC source of the test
+// database, with a new table of three rows.
+func newODBCSource(t *testing.T) (th *testh.Helper, src *source.Source, tbl string) {
+	t.Helper()
+	connStr := os.Getenv(envODBCConn)
+	if connStr == "" {
+		t.Skipf("skipping ODBC test: envar %s not set", envODBCConn)
+	}
+
+	th = testh.New(t)
+	src = th.Add(&source.Source{Handle: "@odbc_test", Type: drivertype.ODBC, Location: "odbc://" + connStr})
+
+	tbl = stringz.UniqTableName("odbc_person")
+	tblDef := schema.NewTable(tbl, []string{"id", "name", "score"},
+		[]kind.Kind{kind.Int, kind.Text, kind.Float})
+	tblDef.PKColName = "id"
+	th.CreateTable(true, src, tblDef,
+		[]any{int64(1), "ada", 1.5},
+		[]any{int64(2), "grace", 2.5},
+		[]any{int64(3), "edsger", nil},
+	)
+	return th, src, tbl
+}
+
+func TestDriver_Roundtrip(t *testing.T) {
+	th, src, tbl := newODBCSource(t)
+	require.Equal(t, int64(3), th.RowCount(src, tbl))
+
+	sink, err := th.QuerySLQ(src.Handle+"."+tbl+" | .name", nil)
+	require.NoError(t, err)
+	require.Len(t, sink.Recs, 3)
+	require.Equal(t, kind.Text, sink.RecMeta[0].Kind())
+
+	sink, err = th.QuerySLQ(src.Handle+"."+tbl+" | where(.score > 2) | .id, .score", nil)
+	require.NoError(t, err)
+	require.Len(t, sink.Recs, 1)
+	require.Equal(t, int64(2), sink.Recs[0][0])
+	require.Equal(t, 2.5, sink.Recs[0][1])
+
+	sink, err = th.QuerySLQ(src.Handle+"."+tbl+" | order_by(.id) | .[0:2]", nil)
+	require.NoError(t, err)
+	require.Len(t, sink.Recs, 2)
+}
+
+func TestGrip_Metadata(t *testing.T) {
+	th, src, tbl := newODBCSource(t)
+
+	md, err := th.SourceMetadata(src)
+	require.NoError(t, err)
+	require.Equal(t, drivertype.ODBC, md.Driver)
+	require.NotEmpty(t, md.DBProduct)
+
+	tblMeta := md.Table(tbl)
+	require.NotNil(t, tblMeta)
+	require.Equal(t, int64(3), tblMeta.RowCount)
+
+	tblMeta, err = th.TableMetadata(src, tbl)
+	require.NoError(t, err)
+	require.Len(t, tblMeta.Columns, 3)
+	require.Equal(t, "id", tblMeta.Columns[0].Name)
+	require.True(t, tblMeta.Columns[0].PrimaryKey)
+	require.Equal(t, kind.Int, tblMeta.Columns[0].Kind)
+	require.Equal(t, kind.Text, tblMeta.Columns[1].Kind)
+	require.Equal(t, kind.Float, tblMeta.Columns[2].Kind)
+
+	drvr := th.SQLDriverFor(src)
+	db := th.OpenDB(src)
+	ok, err := drvr.TableExists(th.Context, db, tbl)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = drvr.TableExists(th.Context, db, "not_exist")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDriver_ValidateSource(t *testing.T) {
+	th := testh.New(t)
+	drvr, err := th.Grips().DriverFor(drivertype.ODBC)
+	require.NoError(t, err)
+
+	src := &source.Source{Handle: "@odbc", Type: drivertype.ODBC, Location: "odbc://DSN=db2prod;UID=alice"}
+	_, err = drvr.ValidateSource(src)
+	require.NoError(t, err)
+
+	src.Location = "postgres://localhost/sq_test"
+	_, err = drvr.ValidateSource(src)
+	require.Error(t, err)
+
+	src.Location = "odbc://Driver=NotInstalled;Database=x"
+	require.Error(t, drvr.Ping(th.Context, src, driver.ModeReadOnly))
+}
@@ -0,0 +1,225 @@
+//go:build odbc && cgo && (linux || darwin || freebsd)
+
+package odbc
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/alexbrainman/odbc"
+	"github.com/alexbrainman/odbc/api"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// odbcDriver returns the alexbrainman/odbc driver. The package doesn't
+// export it, but it can be had from a sql.DB, which doesn't connect
+// until it's used.
+var odbcDriver = sync.OnceValue(func() driver.Driver {
+	db, _ := sql.Open("odbc", "")
+	drvr := db.Driver()
+	_ = db.Close()
+	return drvr
+})
+
+// openDB opens the database identified by the ODBC connection string.
+func openDB(connStr string) (*sql.DB, error) {
+	return sql.OpenDB(&connector{connStr: connStr}), nil
+}
+
+var _ driver.Connector = (*connector)(nil)
+
+// connector is a driver.Connector that returns connections of the
+// alexbrainman/odbc driver, wrapped as sqConn.
+type connector struct {
+	connStr string
+}
+
+// Connect implements driver.Connector.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := odbcDriver().Open(c.connStr)
+	if err != nil {
+		return nil, err
+	}
+	return &sqConn{Conn: conn.(*odbc.Conn)}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *connector) Driver() driver.Driver {
+	return odbcDriver()
+}
+
+// sqConn wraps odbc.Conn, so that its rows report their column types.
+type sqConn struct {
+	*odbc.Conn
+}
+
+// Prepare implements driver.Conn.
+func (c *sqConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &sqStmt{Stmt: stmt.(*odbc.Stmt)}, nil
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *sqConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.Conn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQRows(rows), nil
+}
+
+// sqStmt wraps odbc.Stmt, so that its rows report their column types.
+type sqStmt struct {
+	*odbc.Stmt
+}
+
+// Query implements driver.Stmt.
+func (s *sqStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.Stmt.Query(args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQRows(rows), nil
+}
+
+var _ driver.RowsColumnTypeDatabaseTypeName = (*sqRows)(nil)
+
+// sqRows wraps odbc.Rows, adding the ColumnTypeDatabaseTypeName method
+// that the ODBC driver doesn't implement. Without it, sq couldn't
+// determine the kind of a result column.
+type sqRows struct {
+	driver.Rows
+
+	// sqlTypes holds the ODBC SQL type code of each column, or zero
+	// (SQL_UNKNOWN_TYPE) if it couldn't be determined.
+	sqlTypes []int16
+}
+
+// newSQRows wraps rows, which should be *odbc.Rows.
+func newSQRows(rows driver.Rows) *sqRows {
+	r := &sqRows{Rows: rows, sqlTypes: make([]int16, len(rows.Columns()))}
+
+	odbcRows, ok := rows.(*odbc.Rows)
+	if !ok {
+		return r
+	}
+
+	os, ok := rowsStmt(odbcRows)
+	if !ok || os == nil {
+		return r
+	}
+
+	for i, col := range os.Cols {
+		if i >= len(r.sqlTypes) {
+			break
+		}
+		switch col := col.(type) {
+		case *odbc.BindableColumn:
+			r.sqlTypes[i] = int16(col.SQLType)
+		case *odbc.NonBindableColumn:
+			r.sqlTypes[i] = int16(col.SQLType)
+		}
+	}
+	return r
+}
+
+// ColumnTypeDatabaseTypeName implements
+// driver.RowsColumnTypeDatabaseTypeName. It returns the name of the
+// column's ODBC SQL type, e.g. "VARCHAR", which is all that the ODBC
+// driver describes.
+func (r *sqRows) ColumnTypeDatabaseTypeName(index int) string {
+	return sqlTypeName(r.sqlTypes[index])
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *sqRows) HasNextResultSet() bool {
+	rs, ok := r.Rows.(driver.RowsNextResultSet)
+	return ok && rs.HasNextResultSet()
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *sqRows) NextResultSet() error {
+	rs, ok := r.Rows.(driver.RowsNextResultSet)
+	if !ok {
+		return io.EOF
+	}
+	return rs.NextResultSet()
+}
+
+var (
+	// rtypeODBCStmt is the expected reflect.Type of odbc.Rows' os field.
+	rtypeODBCStmt = reflect.TypeOf((*odbc.ODBCStmt)(nil))
+
+	// rtypeHDBC is the expected reflect.Type of odbc.Conn's h field.
+	rtypeHDBC = reflect.TypeOf(api.SQLHDBC(nil))
+)
+
+// rowsStmt returns the statement behind rows, whose columns hold their
+// SQL types. The odbc package provides no public access to it, so the
+// unexported field is read via reflect+unsafe, guarded so that a future
+// layout change reports ok=false (and Test_ODBCLayout fails) rather than
+// panicking.
+func rowsStmt(rows *odbc.Rows) (os *odbc.ODBCStmt, ok bool) {
+	f := reflect.ValueOf(rows).Elem().FieldByName("os")
+	if !f.IsValid() || f.Type() != rtypeODBCStmt {
+		return nil, false
+	}
+	f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	os, ok = f.Interface().(*odbc.ODBCStmt)
+	return os, ok
+}
+
+// connHandle returns the ODBC connection handle of conn, as needed to
+// call the catalog functions, which the odbc package doesn't wrap. The
+// unexported field is read as in rowsStmt.
+func connHandle(conn *odbc.Conn) (h api.SQLHDBC, ok bool) {
+	f := reflect.ValueOf(conn).Elem().FieldByName("h")
+	if !f.IsValid() || f.Type() != rtypeHDBC {
+		return nil, false
+	}
+	f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	h, ok = f.Interface().(api.SQLHDBC)
+	return h, ok
+}
+
+// withConnHandle invokes fn with the ODBC connection handle of a
+// connection from db. The catalog functions need a connection of their
+// own, and so db must be a *sql.DB or *sql.Conn: they can't be called
+// within a transaction.
+func withConnHandle(ctx context.Context, db any, fn func(h api.SQLHDBC) error) error {
+	var conn *sql.Conn
+	switch db := db.(type) {
+	case *sql.Conn:
+		conn = db
+	case *sql.DB:
+		var err error
+		if conn, err = db.Conn(ctx); err != nil {
+			return err
+		}
+		defer conn.Close()
+	default:
+		return errz.Errorf("ODBC catalog functions can't be called via %T", db)
+	}
+
+	return conn.Raw(func(dc any) error {
+		c, ok := dc.(*sqConn)
+		if !ok {
+			return errz.Errorf("expected ODBC connection %T but got %T", c, dc)
+		}
+
+		h, ok := connHandle(c.Conn)
+		if !ok {
+			return errz.New("unable to get ODBC connection handle: unexpected odbc.Conn layout")
+		}
+		return fn(h)
+	})
+}
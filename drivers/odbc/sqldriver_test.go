@@ -0,0 +1,30 @@
+//go:build odbc && cgo && (linux || darwin || freebsd)
+
+package odbc
+
+import (
+	"testing"
+
+	"github.com/alexbrainman/odbc"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_ODBCLayout verifies that the unexported fields of the odbc
+// package, which rowsStmt and connHandle read via reflect+unsafe, are
+// still present.
+func Test_ODBCLayout(t *testing.T) {
+	os, ok := rowsStmt(&odbc.Rows{})
+	require.True(t, ok, "odbc.Rows no longer has an os *odbc.ODBCStmt field")
+	require.Nil(t, os)
+
+	h, ok := connHandle(&odbc.Conn{})
+	require.True(t, ok, "odbc.Conn no longer has an h api.SQLHDBC field")
+	require.Nil(t, h)
+}
+
+func Test_sqRows_ColumnTypeDatabaseTypeName(t *testing.T) {
+	r := &sqRows{sqlTypes: []int16{12, -5, 0}}
+	require.Equal(t, "VARCHAR", r.ColumnTypeDatabaseTypeName(0))
+	require.Equal(t, "BIGINT", r.ColumnTypeDatabaseTypeName(1))
+	require.Equal(t, "SQL_TYPE(0)", r.ColumnTypeDatabaseTypeName(2))
+}
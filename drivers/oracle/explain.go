@@ -0,0 +1,88 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer, using EXPLAIN PLAN, which writes
+// the plan to PLAN_TABLE. The plan's rows are deleted afterwards. Oracle
+// can only report actual row counts for a query executed with the
+// gather_plan_statistics hint, so analyze is not supported.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (plan *driver.PlanNode,
+	err error,
+) {
+	if analyze {
+		return nil, errz.New("oracle: explain analyze is not supported")
+	}
+
+	stmtID := "sq_" + stringz.Uniq8()
+	if _, err = conn.ExecContext(ctx, "EXPLAIN PLAN SET STATEMENT_ID = '"+stmtID+"' FOR "+query); err != nil {
+		return nil, errw(err)
+	}
+	defer func() {
+		const deleteQuery = `DELETE FROM plan_table WHERE statement_id = :1`
+		if _, delErr := conn.ExecContext(context.WithoutCancel(ctx), deleteQuery, stmtID); delErr != nil {
+			err = errz.Append(err, errw(delErr))
+		}
+	}()
+
+	const planQuery = `SELECT id, parent_id, operation, options, object_name, cardinality, cost
+FROM plan_table WHERE statement_id = :1 ORDER BY id`
+	rows, err := conn.QueryContext(ctx, planQuery, stmtID)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseDBRows, rows)
+
+	nodes := map[int64]*driver.PlanNode{}
+	for rows.Next() {
+		var (
+			id                     int64
+			parentID               sql.NullInt64
+			operation, options     sql.NullString
+			objectName             sql.NullString
+			cardinality, costValue sql.NullFloat64
+		)
+		if err = rows.Scan(&id, &parentID, &operation, &options, &objectName, &cardinality,
+			&costValue); err != nil {
+			return nil, errw(err)
+		}
+
+		// For example, operation "TABLE ACCESS" with options "FULL".
+		node := &driver.PlanNode{
+			Op:     strings.TrimSpace(operation.String + " " + options.String),
+			Detail: objectName.String,
+		}
+		if cardinality.Valid {
+			node.EstRows = &cardinality.Float64
+		}
+		if costValue.Valid {
+			node.Cost = &costValue.Float64
+		}
+
+		if plan == nil {
+			plan = node
+		} else if parent, ok := nodes[parentID.Int64]; ok && parentID.Valid {
+			parent.Children = append(parent.Children, node)
+		}
+		nodes[id] = node
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errw(err)
+	}
+
+	if plan == nil {
+		return nil, errz.New("oracle: query plan is empty")
+	}
+	return plan, nil
+}
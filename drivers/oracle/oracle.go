@@ -109,6 +109,13 @@ func (d *driveri) Dialect() dialect.Dialect {
 		Catalog:        false, // Oracle uses schemas only
 		// BOOLEAN is emulated as NUMBER(1,0); drivers typically scan as integer.
 		IntBool: true,
+		Profile: dialect.Profile{
+			CharLength:          "LENGTH",
+			StdDev:              "STDDEV_SAMP",
+			ApproxCountDistinct: "APPROX_COUNT_DISTINCT",
+			Float:               "BINARY_DOUBLE",
+			Limit:               " FETCH FIRST %d ROWS ONLY",
+		},
 	}
 }
 
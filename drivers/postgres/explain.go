@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer, using EXPLAIN (FORMAT JSON).
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*driver.PlanNode,
+	error,
+) {
+	stmt := "EXPLAIN (FORMAT JSON) " + query
+	if analyze {
+		stmt = "EXPLAIN (ANALYZE, FORMAT JSON) " + query
+	}
+
+	var data []byte
+	if err := conn.QueryRowContext(ctx, stmt).Scan(&data); err != nil {
+		return nil, errw(err)
+	}
+
+	return parsePlan(data)
+}
+
+// pgPlanNode is a node of Postgres's EXPLAIN (FORMAT JSON) output.
+type pgPlanNode struct {
+	PlanRows     *float64      `json:"Plan Rows"`
+	TotalCost    *float64      `json:"Total Cost"`
+	ActualRows   *float64      `json:"Actual Rows"`
+	ActualLoops  *float64      `json:"Actual Loops"`
+	NodeType     string        `json:"Node Type"`
+	RelationName string        `json:"Relation Name"`
+	IndexName    string        `json:"Index Name"`
+	JoinType     string        `json:"Join Type"`
+	Plans        []*pgPlanNode `json:"Plans"`
+}
+
+// parsePlan parses the output of EXPLAIN (FORMAT JSON).
+func parsePlan(data []byte) (*driver.PlanNode, error) {
+	var doc []struct {
+		Plan *pgPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errz.Wrap(err, "postgres: parse query plan")
+	}
+	if len(doc) == 0 || doc[0].Plan == nil {
+		return nil, errz.New("postgres: query plan is empty")
+	}
+
+	return doc[0].Plan.normalize(), nil
+}
+
+func (n *pgPlanNode) normalize() *driver.PlanNode {
+	node := &driver.PlanNode{
+		Op:      n.NodeType,
+		EstRows: n.PlanRows,
+		Cost:    n.TotalCost,
+	}
+
+	switch {
+	case n.RelationName != "" && n.IndexName != "":
+		node.Detail = n.RelationName + " using " + n.IndexName
+	case n.RelationName != "":
+		node.Detail = n.RelationName
+	case n.IndexName != "":
+		node.Detail = n.IndexName
+	case n.JoinType != "":
+		node.Detail = n.JoinType
+	}
+
+	if n.ActualRows != nil {
+		// Actual Rows is the per-loop average.
+		rows := *n.ActualRows
+		if n.ActualLoops != nil {
+			rows *= *n.ActualLoops
+		}
+		node.ActualRows = &rows
+	}
+
+	for _, child := range n.Plans {
+		node.Children = append(node.Children, child.normalize())
+	}
+	return node
+}
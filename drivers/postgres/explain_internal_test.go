@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlan(t *testing.T) {
+	const data = `[{
+  "Plan": {
+    "Node Type": "Hash Join", "Join Type": "Inner",
+    "Total Cost": 12.5, "Plan Rows": 200, "Actual Rows": 190, "Actual Loops": 1,
+    "Plans": [
+      {"Node Type": "Seq Scan", "Relation Name": "film_actor", "Alias": "film_actor",
+       "Total Cost": 4.0, "Plan Rows": 5462, "Actual Rows": 5462, "Actual Loops": 1},
+      {"Node Type": "Index Scan", "Relation Name": "actor", "Index Name": "actor_pkey",
+       "Total Cost": 0.3, "Plan Rows": 1, "Actual Rows": 2, "Actual Loops": 3}
+    ]
+  },
+  "Planning Time": 0.2
+}]`
+
+	plan, err := parsePlan([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, "Hash Join", plan.Op)
+	require.Equal(t, "Inner", plan.Detail)
+	require.Equal(t, 200.0, *plan.EstRows)
+	require.Equal(t, 12.5, *plan.Cost)
+	require.Equal(t, 190.0, *plan.ActualRows)
+	require.Len(t, plan.Children, 2)
+	require.Equal(t, "film_actor", plan.Children[0].Detail)
+
+	idx := plan.Children[1]
+	require.Equal(t, "Index Scan", idx.Op)
+	require.Equal(t, "actor using actor_pkey", idx.Detail)
+	require.Equal(t, 6.0, *idx.ActualRows, "actual rows should be per-loop rows times loops")
+
+	_, err = parsePlan([]byte(`[]`))
+	require.Error(t, err)
+}
@@ -113,6 +113,9 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          jointype.All(),
 		Catalog:        true,
+		Profile:        dialect.DefaultProfile(),
+		// textregexeq is the function behind the ~ operator.
+		RegexpMatch: "textregexeq",
 	}
 }
 
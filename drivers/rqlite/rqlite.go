@@ -355,6 +355,12 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          jointype.All(),
 		Catalog:        false,
+		// rqlite is SQLite underneath: see the sqlite3 driver.
+		Profile: dialect.Profile{
+			CharLength: "LENGTH",
+			Float:      "REAL",
+			Limit:      " LIMIT %d",
+		},
 	}
 }
 
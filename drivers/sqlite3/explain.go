@@ -0,0 +1,79 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// Explain implements driver.Explainer. SQLite's EXPLAIN QUERY PLAN
+// reports neither row estimates nor costs, and SQLite has no means of
+// analyzing a query.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*driver.PlanNode,
+	error,
+) {
+	if analyze {
+		return nil, errz.New("sqlite3: explain analyze is not supported")
+	}
+
+	rows, err := conn.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseDBRows, rows)
+
+	var steps []planStep
+	for rows.Next() {
+		var (
+			step    planStep
+			notUsed int64
+		)
+		if err = rows.Scan(&step.id, &step.parent, &notUsed, &step.detail); err != nil {
+			return nil, errw(err)
+		}
+		steps = append(steps, step)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errw(err)
+	}
+
+	return buildPlan(steps), nil
+}
+
+// planStep is a row of EXPLAIN QUERY PLAN output.
+type planStep struct {
+	detail     string
+	id, parent int64
+}
+
+// buildPlan returns the plan tree for steps. SQLite's plan is a forest,
+// so the returned root is a synthetic "QUERY PLAN" node, as printed by
+// the sqlite3 shell.
+func buildPlan(steps []planStep) *driver.PlanNode {
+	root := &driver.PlanNode{Op: "QUERY PLAN"}
+	nodes := map[int64]*driver.PlanNode{0: root}
+	for _, step := range steps {
+		node := &driver.PlanNode{Op: step.detail}
+		// For the common cases, e.g. "SCAN actor", separate the
+		// table from the operation.
+		if op, detail, ok := strings.Cut(step.detail, " "); ok && (op == "SCAN" || op == "SEARCH") {
+			node.Op, node.Detail = op, detail
+		}
+
+		parent, ok := nodes[step.parent]
+		if !ok {
+			parent = root
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[step.id] = node
+	}
+
+	return root
+}
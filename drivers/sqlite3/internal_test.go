@@ -204,3 +204,22 @@ func TestParseSemver(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildPlan(t *testing.T) {
+	plan := buildPlan([]planStep{
+		{id: 2, parent: 0, detail: "MATERIALIZE v"},
+		{id: 3, parent: 2, detail: "SCAN actor"},
+		{id: 8, parent: 0, detail: "SEARCH film USING INDEX idx_title (title=?)"},
+		{id: 12, parent: 0, detail: "USE TEMP B-TREE FOR ORDER BY"},
+	})
+
+	require.Equal(t, "QUERY PLAN", plan.Op)
+	require.Len(t, plan.Children, 3)
+	require.Equal(t, "MATERIALIZE v", plan.Children[0].Op)
+	require.Len(t, plan.Children[0].Children, 1)
+	require.Equal(t, "SCAN", plan.Children[0].Children[0].Op)
+	require.Equal(t, "actor", plan.Children[0].Children[0].Detail)
+	require.Equal(t, "SEARCH", plan.Children[1].Op)
+	require.Equal(t, "film USING INDEX idx_title (title=?)", plan.Children[1].Detail)
+	require.Equal(t, "USE TEMP B-TREE FOR ORDER BY", plan.Children[2].Op)
+}
@@ -254,6 +254,12 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          jointype.All(),
 		Catalog:        false,
+		// SQLite has no STDDEV_SAMP, nor an approximate COUNT(DISTINCT).
+		Profile: dialect.Profile{
+			CharLength: "LENGTH",
+			Float:      "REAL",
+			Limit:      " LIMIT %d",
+		},
 	}
 }
 
@@ -0,0 +1,197 @@
+package sqlserver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+var _ driver.Explainer = (*driveri)(nil)
+
+// showplanColName is the name of the column of the result set that
+// contains the XML showplan.
+const showplanColName = "Microsoft SQL Server 2005 XML Showplan"
+
+// Explain implements driver.Explainer. The estimated plan is obtained via
+// SET SHOWPLAN_XML, which doesn't execute the query; the actual plan is
+// obtained via SET STATISTICS XML, which does.
+func (d *driveri) Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (plan *driver.PlanNode,
+	err error,
+) {
+	setting := "SHOWPLAN_XML"
+	if analyze {
+		setting = "STATISTICS XML"
+	}
+
+	// The SET statement must be the only statement in its batch.
+	if _, err = conn.ExecContext(ctx, "SET "+setting+" ON"); err != nil {
+		return nil, errw(err)
+	}
+	defer func() {
+		if _, offErr := conn.ExecContext(context.WithoutCancel(ctx), "SET "+setting+" OFF"); offErr != nil {
+			err = errz.Append(err, errw(offErr))
+		}
+	}()
+
+	data, err := readShowplan(ctx, conn, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseShowplan(data)
+}
+
+// readShowplan executes query, returning the XML showplan. The showplan
+// is in its own result set, which with SET STATISTICS XML follows the
+// query's own result sets.
+func readShowplan(ctx context.Context, conn *sql.Conn, query string) ([]byte, error) {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseDBRows, rows)
+
+	var data []byte
+	for {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, errw(err)
+		}
+
+		isShowplan := len(cols) == 1 && cols[0] == showplanColName
+		for rows.Next() {
+			if isShowplan && data == nil {
+				if err = rows.Scan(&data); err != nil {
+					return nil, errw(err)
+				}
+			}
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errw(err)
+	}
+	if data == nil {
+		return nil, errz.New("sqlserver: query plan not returned")
+	}
+	return data, nil
+}
+
+// parseShowplan parses an XML showplan. Each RelOp element is a plan
+// node; child RelOp elements are nested within an element specific to
+// the operator, e.g. NestedLoops.
+func parseShowplan(data []byte) (*driver.PlanNode, error) {
+	var (
+		root      *driver.PlanNode
+		stack     []*driver.PlanNode
+		hasObject = map[*driver.PlanNode]bool{}
+		dec       = xml.NewDecoder(bytes.NewReader(data))
+	)
+
+	// The showplan may declare encoding utf-16, but the driver has already
+	// decoded the value, so the declaration is ignored.
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errz.Wrap(err, "sqlserver: parse query plan")
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "RelOp":
+				node := newShowplanNode(el)
+				switch {
+				case len(stack) > 0:
+					parent := stack[len(stack)-1]
+					parent.Children = append(parent.Children, node)
+				case root == nil:
+					root = node
+				default:
+					// Only the plan of the first statement is reported.
+					if err = dec.Skip(); err != nil {
+						return nil, errz.Wrap(err, "sqlserver: parse query plan")
+					}
+					continue
+				}
+				stack = append(stack, node)
+			case "RunTimeCountersPerThread":
+				// The actual rows are reported per thread.
+				if len(stack) > 0 {
+					node := stack[len(stack)-1]
+					if rows := driver.ParsePlanFloat(xmlAttr(el, "ActualRows")); rows != nil {
+						if node.ActualRows != nil {
+							*rows += *node.ActualRows
+						}
+						node.ActualRows = rows
+					}
+				}
+			case "Object":
+				// The object, i.e. the table or index, is more useful than
+				// the logical op, but only the first object belongs to the
+				// node itself.
+				if len(stack) > 0 && !hasObject[stack[len(stack)-1]] {
+					node := stack[len(stack)-1]
+					hasObject[node] = true
+					node.Detail = strings.Trim(xmlAttr(el, "Table"), "[]")
+					if index := strings.Trim(xmlAttr(el, "Index"), "[]"); index != "" {
+						node.Detail += " using " + index
+					}
+				}
+			}
+		case xml.EndElement:
+			if el.Name.Local == "RelOp" && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errz.New("sqlserver: query plan is empty")
+	}
+	return root, nil
+}
+
+// newShowplanNode returns a node for showplan RelOp element el.
+func newShowplanNode(el xml.StartElement) *driver.PlanNode {
+	node := &driver.PlanNode{
+		Op:      xmlAttr(el, "PhysicalOp"),
+		EstRows: driver.ParsePlanFloat(xmlAttr(el, "EstimateRows")),
+		Cost:    driver.ParsePlanFloat(xmlAttr(el, "EstimatedTotalSubtreeCost")),
+	}
+
+	// The logical op is useful when it's different, e.g.
+	// "Hash Match" and "Inner Join".
+	if logical := xmlAttr(el, "LogicalOp"); logical != node.Op {
+		node.Detail = logical
+	}
+	return node
+}
+
+// xmlAttr returns the value of el's attribute name, or empty string.
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
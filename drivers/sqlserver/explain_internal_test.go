@@ -0,0 +1,54 @@
+package sqlserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShowplan(t *testing.T) {
+	const data = `<?xml version="1.0" encoding="utf-16"?>
+<ShowPlanXML xmlns="http://schemas.microsoft.com/sqlserver/2004/07/showplan" Version="1.564">
+ <BatchSequence><Batch><Statements>
+  <StmtSimple StatementText="SELECT ..." StatementType="SELECT">
+   <QueryPlan>
+    <RelOp NodeId="0" PhysicalOp="Hash Match" LogicalOp="Inner Join" EstimateRows="5462" EstimatedTotalSubtreeCost="0.0712">
+     <RunTimeInformation>
+      <RunTimeCountersPerThread Thread="0" ActualRows="5000"/>
+      <RunTimeCountersPerThread Thread="1" ActualRows="462"/>
+     </RunTimeInformation>
+     <Hash>
+      <RelOp NodeId="1" PhysicalOp="Clustered Index Scan" LogicalOp="Clustered Index Scan" EstimateRows="200" EstimatedTotalSubtreeCost="0.0035">
+       <RunTimeInformation><RunTimeCountersPerThread Thread="0" ActualRows="200"/></RunTimeInformation>
+       <IndexScan Ordered="0">
+        <Object Database="[sakila]" Schema="[dbo]" Table="[actor]" Index="[PK_actor]"/>
+       </IndexScan>
+      </RelOp>
+      <RelOp NodeId="2" PhysicalOp="Table Scan" LogicalOp="Table Scan" EstimateRows="5462" EstimatedTotalSubtreeCost="0.0251">
+       <TableScan><Object Database="[sakila]" Schema="[dbo]" Table="[film_actor]"/></TableScan>
+      </RelOp>
+     </Hash>
+    </RelOp>
+   </QueryPlan>
+  </StmtSimple>
+ </Statements></Batch></BatchSequence>
+</ShowPlanXML>`
+
+	plan, err := parseShowplan([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, "Hash Match", plan.Op)
+	require.Equal(t, "Inner Join", plan.Detail)
+	require.Equal(t, 5462.0, *plan.EstRows)
+	require.Equal(t, 0.0712, *plan.Cost)
+	require.Equal(t, 5462.0, *plan.ActualRows, "actual rows should be summed across threads")
+	require.Len(t, plan.Children, 2)
+
+	require.Equal(t, "Clustered Index Scan", plan.Children[0].Op)
+	require.Equal(t, "actor using PK_actor", plan.Children[0].Detail)
+	require.Equal(t, 200.0, *plan.Children[0].ActualRows)
+	require.Equal(t, "film_actor", plan.Children[1].Detail)
+	require.Nil(t, plan.Children[1].ActualRows)
+
+	_, err = parseShowplan([]byte(`<ShowPlanXML/>`))
+	require.Error(t, err)
+}
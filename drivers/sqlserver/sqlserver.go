@@ -128,6 +128,14 @@ func (d *driveri) Dialect() dialect.Dialect {
 		ExecModeFor:    dialect.DefaultExecModeFor,
 		Joins:          jointype.All(),
 		Catalog:        true,
+		// APPROX_COUNT_DISTINCT requires SQL Server 2019.
+		Profile: dialect.Profile{
+			CharLength:          "LEN",
+			StdDev:              "STDEV",
+			ApproxCountDistinct: "APPROX_COUNT_DISTINCT",
+			Float:               "FLOAT",
+			Limit:               " OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY",
+		},
 	}
 }
 
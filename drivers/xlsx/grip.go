@@ -60,6 +60,7 @@ func (g *grip) SourceMetadata(ctx context.Context, noSchema bool) (*metadata.Sou
 	}
 	md.Size = &size
 
+	driver.MarkIngestOverrides(ctx, g.src, md.Tables...)
 	return md, nil
 }
 
@@ -70,7 +71,13 @@ func (g *grip) DBSemver(ctx context.Context) (string, error) {
 
 // TableMetadata implements driver.Grip.
 func (g *grip) TableMetadata(ctx context.Context, tblName string) (*metadata.Table, error) {
-	return g.dbGrip.TableMetadata(ctx, tblName)
+	md, err := g.dbGrip.TableMetadata(ctx, tblName)
+	if err != nil {
+		return nil, err
+	}
+
+	driver.MarkIngestOverrides(ctx, g.src, md)
+	return md, nil
 }
 
 // Close implements driver.Grip.
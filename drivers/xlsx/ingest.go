@@ -33,16 +33,39 @@ type sheetTable struct {
 	sheet             *xSheet
 	def               *schema.Table
 	colIngestMungeFns []kind.MungeFunc
-	hasHeaderRow      bool
+
+	// keep holds the indices of the sheet columns that are ingested, or
+	// nil if all are. See driver.IngestSchema.Apply.
+	keep         []int
+	hasHeaderRow bool
 }
 
-// xSheet encapsulates access to a worksheet.
+// xSheet encapsulates access to a worksheet, or a range of a worksheet,
+// that is ingested as a table.
 type xSheet struct {
-	file       *excelize.File
-	name       string
+	file *excelize.File
+
+	// header, if non-nil, specifies whether the first row of the range
+	// is a header row. If nil, the header row is detected.
+	header *bool
+
+	// name is the sheet name.
+	name string
+
+	// tblName is the name of the table. For a whole sheet, it's the
+	// same as name.
+	tblName string
+
 	sampleRows [][]string
+
+	// rng is the range of the sheet that is ingested.
+	rng cellRange
+
 	// sampleRowsMaxWidth is the width of the widest row in sampleRows.
 	sampleRowsMaxWidth int
+
+	// firstRowNum is the sheet row number of sampleRows[0].
+	firstRowNum int
 }
 
 // loadSampleRows loads up to sampleSize rows, storing them to xSheet.sampleRows.
@@ -56,9 +79,10 @@ func (xs *xSheet) loadSampleRows(ctx context.Context, sampleSize int) error {
 
 	defer lg.WarnIfCloseError(lg.FromContext(ctx), msgCloseRowIter, iter)
 
-	var count int
+	var count, rowNum int
 	for iter.Next() {
-		if count >= sampleSize {
+		rowNum++
+		if count >= sampleSize || xs.pastEnd(rowNum) {
 			break
 		}
 		var cells []string
@@ -66,7 +90,15 @@ func (xs *xSheet) loadSampleRows(ctx context.Context, sampleSize int) error {
 			return err
 		}
 
+		var ok bool
+		if cells, ok = xs.clip(rowNum, cells); !ok {
+			continue
+		}
+
 		if !langz.IsSliceZeroed(cells) {
+			if len(xs.sampleRows) == 0 {
+				xs.firstRowNum = rowNum
+			}
 			xs.sampleRows = append(xs.sampleRows, cells)
 			if len(cells) > xs.sampleRowsMaxWidth {
 				xs.sampleRowsMaxWidth = len(cells)
@@ -81,8 +113,9 @@ func (xs *xSheet) loadSampleRows(ctx context.Context, sampleSize int) error {
 	return nil
 }
 
-// ingestXLSX loads the data in xfile into destGrip.
-// If includeSheetNames is non-empty, only the named sheets are ingested.
+// ingestXLSX loads the data in xfile into destGrip. The sheets and
+// ranges that are ingested are determined by src's options; see
+// buildXSheets.
 func ingestXLSX(ctx context.Context, src *source.Source, destGrip driver.Grip, xfile *excelize.File) error {
 	log := lg.FromContext(ctx)
 	start := time.Now()
@@ -90,16 +123,17 @@ func ingestXLSX(ctx context.Context, src *source.Source, destGrip driver.Grip, x
 		lga.Src, src,
 		lga.Target, destGrip.Source())
 
-	var sheets []*xSheet
+	sheets, err := buildXSheets(ctx, src, xfile)
+	if err != nil {
+		return err
+	}
 
-	sheetNames := xfile.GetSheetList()
-	sheets = make([]*xSheet, len(sheetNames))
-	for i := range sheetNames {
-		sheets[i] = &xSheet{file: xfile, name: sheetNames[i]}
+	sch, err := driver.ReadIngestSchema(src)
+	if err != nil {
+		return err
 	}
 
-	srcIngestHeader := getSrcIngestHeader(src.Options)
-	sheetTbls, err := buildSheetTables(ctx, srcIngestHeader, sheets)
+	sheetTbls, err := buildSheetTables(ctx, sch, sheets)
 	if err != nil {
 		return err
 	}
@@ -206,14 +240,17 @@ func ingestSheetToTable(ctx context.Context, destGrip driver.Grip, sheetTbl *she
 
 	defer lg.WarnIfCloseError(log, msgCloseRowIter, iter)
 
-	var cells []string
+	var (
+		cells []string
+		ok    bool
+	)
 
 	i := -1
 LOOP:
 	for iter.Next() {
 		i++
-		if hasHeader && i == 0 {
-			continue
+		if sheet.pastEnd(i + 1) {
+			break
 		}
 
 		if cells, err = iter.Columns(); err != nil {
@@ -221,6 +258,19 @@ LOOP:
 			return err
 		}
 
+		if cells, ok = sheet.clip(i+1, cells); !ok || langz.IsSliceZeroed(cells) {
+			continue
+		}
+
+		if hasHeader {
+			// The header is the first non-empty row, as per
+			// xSheet.loadSampleRows.
+			hasHeader = false
+			continue
+		}
+
+		cells = driver.ProjectIngestFields(sheetTbl.keep, cells)
+
 		if langz.IsSliceZeroed(cells) {
 			// Skip empty row
 			continue
@@ -270,7 +320,8 @@ LOOP:
 
 // buildSheetTables executes buildSheetTable for each sheet. If sheet is
 // empty (has no data), the sheetTable element for that sheet will be nil.
-func buildSheetTables(ctx context.Context, srcIngestHeader *bool, sheets []*xSheet) ([]*sheetTable, error) {
+func buildSheetTables(ctx context.Context, sch *driver.IngestSchema, sheets []*xSheet,
+) ([]*sheetTable, error) {
 	sheetTbls := make([]*sheetTable, len(sheets))
 
 	g, gCtx := errgroup.WithContext(ctx)
@@ -282,12 +333,13 @@ func buildSheetTables(ctx context.Context, srcIngestHeader *bool, sheets []*xShe
 			default:
 			}
 
-			sheetTbl, err := buildSheetTable(gCtx, srcIngestHeader, sheets[i])
+			sheetTbl, err := buildSheetTable(gCtx, sch, sheets[i])
 			if err != nil {
 				if errz.Has[driver.EmptyDataError](err) {
 					// If the sheet has no data, we log it and skip it.
 					lg.FromContext(ctx).Warn("Excel sheet has no data",
 						laSheet, sheets[i].name,
+						lga.Table, sheets[i].tblName,
 						lga.Err, err)
 					return nil
 				}
@@ -321,10 +373,11 @@ func getSrcIngestHeader(o options.Options) *bool {
 
 // buildSheetTable constructs a table definition for the given sheet, and returns
 // a model of the table, or an error. If the sheet is empty, (nil,nil)
-// is returned. If srcIngestHeader is nil, the function attempts
-// to detect if the sheet has a header row.
+// is returned. If sheet.header is nil, the function attempts
+// to detect if the sheet has a header row. The column overrides in sch,
+// which may be nil, are applied to the table definition.
 // If the sheet has no data, errz.EmptyDataError is returned.
-func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet) (*sheetTable, error) {
+func buildSheetTable(ctx context.Context, sch *driver.IngestSchema, sheet *xSheet) (*sheetTable, error) {
 	log := lg.FromContext(ctx)
 
 	sampleSize := driver.OptIngestSampleSize.Get(options.FromContext(ctx))
@@ -333,23 +386,23 @@ func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet)
 	}
 
 	if len(sheet.sampleRows) == 0 {
-		return nil, driver.NewEmptyDataError("excel: sheet {%s} has no row data", sheet.name)
+		return nil, driver.NewEmptyDataError("excel: sheet {%s} has no row data", sheet.tblName)
 	}
 
 	if sheet.sampleRowsMaxWidth == 0 {
-		return nil, driver.NewEmptyDataError("excel: sheet {%s} has no column data", sheet.name)
+		return nil, driver.NewEmptyDataError("excel: sheet {%s} has no column data", sheet.tblName)
 	}
 
 	var hasHeader bool
-	if srcIngestHeader != nil {
-		hasHeader = *srcIngestHeader
+	if sheet.header != nil {
+		hasHeader = *sheet.header
 	} else {
 		var err error
 		if hasHeader, err = detectHeaderRow(ctx, sheet); err != nil {
 			return nil, err
 		}
 
-		log.Debug("Detect header row for sheet", laSheet, sheet.name, lga.Val, hasHeader)
+		log.Debug("Detect header row for sheet", laSheet, sheet.name, lga.Table, sheet.tblName, lga.Val, hasHeader)
 	}
 
 	maxCols := sheet.sampleRowsMaxWidth
@@ -370,6 +423,9 @@ func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet)
 	if hasHeader {
 		firstDataRow = 1
 		copy(colNames, sheet.sampleRows[0])
+		if err := sheet.applyMergedHeader(colNames); err != nil {
+			return nil, err
+		}
 	} else {
 		for i := range maxCols {
 			colNames[i] = stringz.GenerateAlphaColName(i, false)
@@ -399,7 +455,15 @@ func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet)
 		return nil, err
 	}
 
-	tblDef := &schema.Table{Name: sheet.name}
+	keep, err := sch.Apply(ctx, sheet.tblName, colNames, colKinds, colIngestMungeFns)
+	if err != nil {
+		return nil, err
+	}
+	colNames = driver.ProjectIngestFields(keep, colNames)
+	colKinds = driver.ProjectIngestFields(keep, colKinds)
+	colIngestMungeFns = driver.ProjectIngestFields(keep, colIngestMungeFns)
+
+	tblDef := &schema.Table{Name: sheet.tblName}
 	cols := make([]*schema.Column, len(colNames))
 	for i, colName := range colNames {
 		cols[i] = &schema.Column{Table: tblDef, Name: colName, Kind: colKinds[i]}
@@ -407,6 +471,7 @@ func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet)
 	tblDef.Cols = cols
 	lg.FromContext(ctx).Debug("Built table def",
 		laSheet, sheet.name,
+		lga.Table, sheet.tblName,
 		"cols", strings.Join(colNames, ", "))
 
 	return &sheetTable{
@@ -414,6 +479,7 @@ func buildSheetTable(ctx context.Context, srcIngestHeader *bool, sheet *xSheet)
 		def:               tblDef,
 		hasHeaderRow:      hasHeader,
 		colIngestMungeFns: colIngestMungeFns,
+		keep:              keep,
 	}, nil
 }
 
@@ -0,0 +1,383 @@
+package xlsx
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	excelize "github.com/xuri/excelize/v2"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+// optRangesKey is the key of OptRanges, which can't refer to itself in
+// its validation func.
+const optRangesKey = "driver.xlsx.ranges"
+
+// OptSheets specifies the sheets to ingest.
+var OptSheets = options.NewString(
+	"driver.xlsx.sheets",
+	nil,
+	"",
+	nil,
+	"Sheets to ingest from XLSX",
+	`Comma-separated list of the sheets to ingest from an XLSX workbook, each
+of which becomes a table. By default, every sheet is ingested, unless
+option "driver.xlsx.ranges" is set, in which case only those ranges are
+ingested.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"xlsx",
+)
+
+// OptRanges specifies cell ranges, Excel tables or defined names to ingest.
+var OptRanges = options.NewString(
+	optRangesKey,
+	nil,
+	"",
+	func(s string) error {
+		_, err := parseRangeSpecs(s)
+		return err
+	},
+	"Ranges, tables or names to ingest from XLSX",
+	`Comma-separated list of cell ranges to ingest from an XLSX workbook, each
+of which becomes a table. This is useful for workbooks with a title above
+the data, or with several tables on a sheet. An entry is one of:
+
+  Sheet1!A5:F200   an A1-style cell range
+  Sheet1!A5:F      columns A-F, from row 5 to the end of the sheet
+  Sheet1!A5        from cell A5 to the end of the sheet
+  SalesData        an Excel table, or a defined name
+
+Quote a sheet name that contains special characters, e.g. 'Q1 Sales'!B3:E40.
+An entry can be prefixed with the table name to use, e.g.
+"sales=Sheet1!A5:F200". Otherwise, an Excel table or defined name is used as
+the table name, and a cell range is named for its sheet and range, e.g.
+"Sheet1_A5_F200". If this option is set, whole sheets are not ingested,
+unless also listed in option "driver.xlsx.sheets".
+
+  $ sq config set --src @report driver.xlsx.ranges 'SalesData,costs=Summary!B4:H30'`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"xlsx",
+)
+
+// OptHeaderRow specifies the header row of XLSX sheets and ranges.
+var OptHeaderRow = options.NewInt(
+	"driver.xlsx.header-row",
+	nil,
+	0,
+	"Header row number of XLSX sheets and ranges",
+	`Row number (starting at 1) of the header row of each XLSX sheet or cell
+range. Rows above the header row are skipped, which is useful for sheets
+that have a title or notes above the data. When zero, the header row is
+detected (see option "ingest.header"). An Excel table's own header row
+takes precedence. Merged header cells are applied to each column of the
+merge, e.g. a header "Q1" merged across two columns names both of them.`,
+	options.TagSource,
+	options.TagIngestMutate,
+	"xlsx",
+)
+
+// cellRange is a rectangular area of a sheet. Its fields are 1-based and
+// inclusive. If maxRow or maxCol is zero, the range is unbounded in that
+// direction.
+type cellRange struct {
+	minRow, minCol int
+	maxRow, maxCol int
+}
+
+// parseCellRange parses an A1-style range such as "A5:F200", "A5:F" or
+// "A5". Absolute references such as "$A$5" are accepted.
+func parseCellRange(ref string) (cellRange, error) {
+	var rng cellRange
+	start, end, hasEnd := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+
+	var err error
+	if rng.minCol, rng.minRow, err = excelize.CellNameToCoordinates(start); err != nil {
+		return rng, errz.Errorf("invalid cell range {%s}", ref)
+	}
+	if !hasEnd {
+		return rng, nil
+	}
+
+	if rng.maxCol, err = excelize.ColumnNameToNumber(end); err != nil {
+		// It's not a column-only ref, e.g. "F".
+		if rng.maxCol, rng.maxRow, err = excelize.CellNameToCoordinates(end); err != nil {
+			return rng, errz.Errorf("invalid cell range {%s}", ref)
+		}
+	}
+
+	if rng.maxCol < rng.minCol || (rng.maxRow != 0 && rng.maxRow < rng.minRow) {
+		return rng, errz.Errorf("invalid cell range {%s}: end is before start", ref)
+	}
+	return rng, nil
+}
+
+// rangeSpec is an entry of OptRanges.
+type rangeSpec struct {
+	// tblName is the explicit table name, or empty.
+	tblName string
+
+	// sheet is the sheet name. If empty, ref is the name of an
+	// Excel table, or a defined name.
+	sheet string
+
+	// ref is an A1-style cell range, or the name of an Excel table or
+	// defined name.
+	ref string
+}
+
+// parseRangeSpecs parses the value of OptRanges.
+func parseRangeSpecs(val string) ([]rangeSpec, error) {
+	var specs []rangeSpec
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		spec := rangeSpec{ref: entry}
+		if tblName, ref, ok := strings.Cut(entry, "="); ok {
+			spec.tblName, spec.ref = strings.TrimSpace(tblName), strings.TrimSpace(ref)
+			if spec.tblName == "" || spec.ref == "" {
+				return nil, errz.Errorf("%s: invalid entry {%s}", optRangesKey, entry)
+			}
+		}
+
+		if i := strings.LastIndex(spec.ref, "!"); i >= 0 {
+			spec.sheet, spec.ref = unquoteSheetName(spec.ref[:i]), spec.ref[i+1:]
+			if spec.sheet == "" {
+				return nil, errz.Errorf("%s: invalid entry {%s}: empty sheet name", optRangesKey, entry)
+			}
+			if _, err := parseCellRange(spec.ref); err != nil {
+				return nil, errz.Wrapf(err, "%s: entry {%s}", optRangesKey, entry)
+			}
+		}
+
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// unquoteSheetName unquotes a sheet name as it appears in a reference,
+// e.g. 'Q1 Sales' becomes Q1 Sales.
+func unquoteSheetName(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// buildXSheets returns the sheets and ranges of xfile to ingest, per
+// src's options. Each returned xSheet becomes a table.
+func buildXSheets(ctx context.Context, src *source.Source, xfile *excelize.File) ([]*xSheet, error) {
+	var (
+		o          = src.Options
+		sheetNames = xfile.GetSheetList()
+		header     = getSrcIngestHeader(o)
+		headerRow  = OptHeaderRow.Get(o)
+		xsheets    []*xSheet
+	)
+
+	if headerRow < 0 {
+		return nil, errz.Errorf("%s: must not be negative: %d", OptHeaderRow.Key(), headerRow)
+	}
+
+	specs, err := parseRangeSpecs(OptRanges.Get(o))
+	if err != nil {
+		return nil, err
+	}
+
+	wantSheets := sheetNames
+	if val := strings.TrimSpace(OptSheets.Get(o)); val != "" || len(specs) > 0 {
+		wantSheets = nil
+		for _, name := range strings.Split(val, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if !slices.Contains(sheetNames, name) {
+				return nil, errz.Errorf("%s: sheet {%s} not found in workbook", OptSheets.Key(), name)
+			}
+			wantSheets = append(wantSheets, name)
+		}
+	}
+
+	for _, name := range wantSheets {
+		xsheets = append(xsheets, &xSheet{file: xfile, name: name, tblName: name, rng: cellRange{minRow: 1, minCol: 1}})
+	}
+
+	for _, spec := range specs {
+		var xs *xSheet
+		if xs, err = resolveRangeSpec(xfile, sheetNames, spec); err != nil {
+			return nil, err
+		}
+		xsheets = append(xsheets, xs)
+	}
+
+	seen := make(map[string]struct{}, len(xsheets))
+	for _, xs := range xsheets {
+		if _, ok := seen[xs.tblName]; ok {
+			return nil, errz.Errorf("excel: duplicate table name {%s}: set an explicit name via %s",
+				xs.tblName, optRangesKey)
+		}
+		seen[xs.tblName] = struct{}{}
+
+		if xs.header != nil {
+			// An Excel table determines its own header.
+			continue
+		}
+
+		xs.header = header
+		if headerRow > 0 {
+			xs.rng.minRow += headerRow - 1
+			if xs.rng.maxRow != 0 && xs.rng.minRow > xs.rng.maxRow {
+				return nil, errz.Errorf("%s: row %d is outside of {%s}", OptHeaderRow.Key(), headerRow, xs.tblName)
+			}
+			if xs.header == nil {
+				hasHeader := true
+				xs.header = &hasHeader
+			}
+		}
+	}
+
+	lg.FromContext(ctx).Debug("Resolved XLSX tables", lga.Count, len(xsheets), lga.Src, src)
+	return xsheets, nil
+}
+
+// resolveRangeSpec returns the xSheet for spec.
+func resolveRangeSpec(xfile *excelize.File, sheetNames []string, spec rangeSpec) (*xSheet, error) {
+	xs := &xSheet{file: xfile, name: spec.sheet, tblName: spec.tblName}
+	if spec.sheet != "" {
+		if !slices.Contains(sheetNames, spec.sheet) {
+			return nil, errz.Errorf("%s: sheet {%s} not found in workbook", optRangesKey, spec.sheet)
+		}
+
+		var err error
+		if xs.rng, err = parseCellRange(spec.ref); err != nil {
+			return nil, err
+		}
+		if xs.tblName == "" {
+			xs.tblName = spec.sheet + "_" + strings.ReplaceAll(strings.ReplaceAll(spec.ref, "$", ""), ":", "_")
+		}
+		return xs, nil
+	}
+
+	if xs.tblName == "" {
+		xs.tblName = spec.ref
+	}
+
+	// Excel table names and defined names are case-insensitive.
+	for _, sheetName := range sheetNames {
+		tbls, err := xfile.GetTables(sheetName)
+		if err != nil {
+			return nil, errw(err)
+		}
+
+		for _, tbl := range tbls {
+			if !strings.EqualFold(tbl.Name, spec.ref) {
+				continue
+			}
+
+			xs.name = sheetName
+			if xs.rng, err = parseCellRange(tbl.Range); err != nil {
+				return nil, errz.Wrapf(err, "excel: table {%s}", tbl.Name)
+			}
+			hasHeader := tbl.ShowHeaderRow == nil || *tbl.ShowHeaderRow
+			xs.header = &hasHeader
+			return xs, nil
+		}
+	}
+
+	for _, dn := range xfile.GetDefinedName() {
+		if !strings.EqualFold(dn.Name, spec.ref) {
+			continue
+		}
+
+		refersTo := strings.TrimPrefix(dn.RefersTo, "=")
+		i := strings.LastIndex(refersTo, "!")
+		if i < 0 || strings.Contains(refersTo, ",") {
+			return nil, errz.Errorf("excel: defined name {%s}: unsupported reference {%s}", dn.Name, dn.RefersTo)
+		}
+
+		xs.name = unquoteSheetName(refersTo[:i])
+		if !slices.Contains(sheetNames, xs.name) {
+			return nil, errz.Errorf("excel: defined name {%s}: sheet {%s} not found", dn.Name, xs.name)
+		}
+
+		var err error
+		if xs.rng, err = parseCellRange(refersTo[i+1:]); err != nil {
+			return nil, errz.Wrapf(err, "excel: defined name {%s}", dn.Name)
+		}
+		return xs, nil
+	}
+
+	return nil, errz.Errorf("%s: no table or defined name {%s} in workbook", optRangesKey, spec.ref)
+}
+
+// clip returns the cells of row rowNum (1-based) that are within xs's
+// range. If the row is outside the range, ok is false.
+func (xs *xSheet) clip(rowNum int, cells []string) (clipped []string, ok bool) {
+	if rowNum < xs.rng.minRow || (xs.rng.maxRow != 0 && rowNum > xs.rng.maxRow) {
+		return nil, false
+	}
+
+	if xs.rng.minCol > 1 {
+		if xs.rng.minCol > len(cells) {
+			return nil, true
+		}
+		cells = cells[xs.rng.minCol-1:]
+	}
+
+	if xs.rng.maxCol != 0 {
+		if width := xs.rng.maxCol - xs.rng.minCol + 1; len(cells) > width {
+			cells = cells[:width]
+		}
+	}
+	return cells, true
+}
+
+// pastEnd returns true if row rowNum (1-based) is beyond xs's range.
+func (xs *xSheet) pastEnd(rowNum int) bool {
+	return xs.rng.maxRow != 0 && rowNum > xs.rng.maxRow
+}
+
+// applyMergedHeader sets the elements of colNames, the header row (the
+// first row of xs.sampleRows), that are empty because their cell is part
+// of a merged cell, to the value of the merged cell.
+func (xs *xSheet) applyMergedHeader(colNames []string) error {
+	if xs.firstRowNum == 0 {
+		return nil
+	}
+
+	merges, err := xs.file.GetMergeCells(xs.name)
+	if err != nil {
+		return errw(err)
+	}
+
+	for _, m := range merges {
+		var startCol, startRow, endCol, endRow int
+		if startCol, startRow, err = excelize.CellNameToCoordinates(m.GetStartAxis()); err != nil {
+			return errw(err)
+		}
+		if endCol, endRow, err = excelize.CellNameToCoordinates(m.GetEndAxis()); err != nil {
+			return errw(err)
+		}
+
+		if xs.firstRowNum < startRow || xs.firstRowNum > endRow {
+			continue
+		}
+
+		for col := startCol; col <= endCol; col++ {
+			i := col - xs.rng.minCol
+			if i >= 0 && i < len(colNames) && colNames[i] == "" {
+				colNames[i] = m.GetCellValue()
+			}
+		}
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/neilotoole/sq/cli/testrun"
+	"github.com/neilotoole/sq/drivers/xlsx"
 	"github.com/neilotoole/sq/libsq/core/errz"
 	"github.com/neilotoole/sq/libsq/core/kind"
 	"github.com/neilotoole/sq/libsq/core/langz"
@@ -23,6 +24,7 @@ import (
 	"github.com/neilotoole/sq/libsq/driver"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/libsq/source/metadata"
 	"github.com/neilotoole/sq/testh"
 	"github.com/neilotoole/sq/testh/fixt"
 	"github.com/neilotoole/sq/testh/proj"
@@ -545,3 +547,74 @@ func TestDatetime(t *testing.T) {
 		})
 	}
 }
+
+func TestIngestRanges(t *testing.T) {
+	ctx := context.Background()
+	tr := testrun.New(ctx, t, nil)
+
+	require.NoError(t, tr.Exec(
+		"add", filepath.Join("testdata", "finance.xlsx"),
+		"--handle", "@finance",
+	))
+
+	// The title rows are skipped, and the merged header cells "Q1" and
+	// "Q2" name both of their columns.
+	for _, kv := range [][2]string{
+		{xlsx.OptSheets.Key(), "Summary"},
+		{xlsx.OptHeaderRow.Key(), "4"},
+	} {
+		tr = testrun.New(ctx, t, tr)
+		require.NoError(t, tr.Exec("config", "set", "--src", "@finance", kv[0], kv[1]))
+	}
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".Summary"))
+	require.Equal(t, [][]string{
+		{"Region", "Q1", "Q1_1", "Q2", "Q2_1"},
+		{"North", "100", "80", "120", "90"},
+		{"South", "200", "150", "210", "160"},
+		{"West", "50", "40", "70", "45"},
+	}, tr.BindCSV())
+
+	// Now ingest a defined name, an Excel table, and an explicit range.
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--src", "@finance", xlsx.OptHeaderRow.Key(), "--delete"))
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--src", "@finance", xlsx.OptSheets.Key(), "--delete"))
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--src", "@finance",
+		xlsx.OptRanges.Key(), "SalesData,targets, west=Summary!A7:C7"))
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("inspect", "--json", "@finance"))
+	md := &metadata.Source{}
+	tr.Bind(md)
+	require.Equal(t, []string{"SalesData", "targets", "west"}, md.TableNames())
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".SalesData | .Region, .Q2_1"))
+	require.Equal(t, [][]string{
+		{"Region", "Q2_1"},
+		{"North", "90"},
+		{"South", "160"},
+		{"West", "45"},
+	}, tr.BindCSV())
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", ".targets"))
+	require.Equal(t, [][]string{
+		{"Region", "Target"},
+		{"North", "250"},
+		{"South", "400"},
+		{"West", "100"},
+	}, tr.BindCSV())
+
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.NoError(t, tr.Exec("--csv", "--no-header", ".west"))
+	require.Equal(t, [][]string{{"West", "50", "40"}}, tr.BindCSV())
+
+	tr = testrun.New(ctx, t, tr)
+	require.NoError(t, tr.Exec("config", "set", "--src", "@finance", xlsx.OptRanges.Key(), "NoSuchName"))
+	tr = testrun.New(ctx, t, tr).Hush()
+	require.Error(t, tr.Exec("--csv", ".NoSuchName"))
+}
@@ -40,6 +40,30 @@ func Parse(log *slog.Logger, input string) (*AST, error) { //nolint:staticcheck
 	return ast, nil
 }
 
+// SplitStatements splits input, which may be a script of several SLQ
+// queries separated by semicolon, into the text of each query. For
+// example, "@sakila.actor; @sakila.film;" returns "@sakila.actor" and
+// "@sakila.film". An error is returned if input is not syntactically
+// valid. Note that the returned queries are not otherwise validated:
+// each should be passed to Parse.
+func SplitStatements(log *slog.Logger, input string) ([]string, error) {
+	log = lg.Discard() //nolint:staticcheck // Disable parser logging, as per Parse.
+	slCtx, err := parseStmtList(log, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token offsets are rune offsets into the input.
+	runes := []rune(input)
+	queries := slCtx.AllQuery()
+	stmts := make([]string, len(queries))
+	for i, q := range queries {
+		stmts[i] = string(runes[q.GetStart().GetStart() : q.GetStop().GetStop()+1])
+	}
+
+	return stmts, nil
+}
+
 // buildAST constructs sq's AST from a parse tree.
 func buildAST(log *slog.Logger, query slq.IQueryContext) (*AST, error) {
 	if query == nil {
@@ -94,3 +94,38 @@ func TestParseCatalogSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "@sakila.actor", want: []string{"@sakila.actor"}},
+		{in: "@sakila.actor;", want: []string{"@sakila.actor"}},
+		{in: ";;@sakila.actor;;", want: []string{"@sakila.actor"}},
+		{in: "@sakila.actor; .film | .[0:2]", want: []string{"@sakila.actor", ".film | .[0:2]"}},
+		{
+			in:   "@sakila.actor | where(.first_name == \"a;b\");\n# comment\n.film;\n",
+			want: []string{"@sakila.actor | where(.first_name == \"a;b\")", ".film"},
+		},
+		{in: `@sakila.actor; ."föö" | .x`, want: []string{"@sakila.actor", `."föö" | .x`}},
+		{in: "", wantErr: true},
+		{in: ";", wantErr: true},
+		{in: "@sakila.actor; |", wantErr: true},
+		{in: "@sakila.actor junk(", wantErr: true},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tu.Name(i, tc.in), func(t *testing.T) {
+			got, err := ast.SplitStatements(nil, tc.in)
+			if tc.wantErr {
+				require.Error(t, err)
+				t.Log(err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -74,6 +74,22 @@ func (in *Inspector) FindHandles() []string {
 	return lo.Uniq(handles)
 }
 
+// FindArgs returns the keys of all the args (e.g. "$name") mentioned in the
+// AST, in order of first appearance, without duplicates.
+func (in *Inspector) FindArgs() []string {
+	var keys []string
+
+	if err := walkWith(in.ast, typeArgNode, func(_ *Walker, node Node) error {
+		n, _ := node.(*ArgNode)
+		keys = append(keys, n.Key())
+		return nil
+	}); err != nil {
+		panic(err)
+	}
+
+	return lo.Uniq(keys)
+}
+
 // FindWhereClauses returns all the WHERE clauses in the AST.
 func (in *Inspector) FindWhereClauses() ([]*WhereNode, error) {
 	var nodes []*WhereNode
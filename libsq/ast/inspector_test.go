@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/lg/lgt"
 )
 
 func TestInspector_findTableSegments(t *testing.T) {
@@ -25,3 +27,25 @@ func TestInspector_findTableSegments(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, selSegs[0], finalSelSeg)
 }
+
+func TestInspector_FindArgs(t *testing.T) {
+	testCases := []struct {
+		q    string
+		want []string
+	}{
+		{q: `@mydb1 | .user`, want: []string{}},
+		{q: `@mydb1 | .user | where(.uid == $uid)`, want: []string{"uid"}},
+		{
+			q:    `@mydb1 | .user | where(.region == $region && .uid > $min && .uid < $max && .region != $region)`,
+			want: []string{"region", "min", "max"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.q, func(t *testing.T) {
+			a, err := Parse(lgt.New(t), tc.q)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, NewInspector(a).FindArgs())
+		})
+	}
+}
@@ -448,6 +448,7 @@ func NodePrevSegmentChild[T Node](node Node) (T, error) {
 
 // Results from reflect.TypeOf for node types.
 var (
+	typeArgNode            = reflect.TypeFor[*ArgNode]()
 	typeAST                = reflect.TypeFor[*AST]()
 	typeColSelectorNode    = reflect.TypeFor[*ColSelectorNode]()
 	typeExprNode           = reflect.TypeFor[*ExprNode]()
@@ -16,27 +16,68 @@ import (
 // parseSLQ processes SLQ input text and returns a parse tree. It
 // executes both lexer and parser phases.
 func parseSLQ(log *slog.Logger, input string) (*slq.QueryContext, error) {
+	p, lexErrs, parseErrs := newSLQParser(log, input)
+	qCtx := p.Query()
+	if err := checkParseErrs(lexErrs, parseErrs); err != nil {
+		return nil, err
+	}
+
+	return qCtx.(*slq.QueryContext), nil
+}
+
+// parseStmtList is like parseSLQ, but it parses input as a list of
+// ';'-separated queries. Unlike parseSLQ, it's an error if input isn't
+// consumed in its entirety.
+func parseStmtList(log *slog.Logger, input string) (*slq.StmtListContext, error) {
+	p, lexErrs, parseErrs := newSLQParser(log, input)
+	slCtx := p.StmtList()
+	if err := checkParseErrs(lexErrs, parseErrs); err != nil {
+		return nil, err
+	}
+
+	if tok := p.GetTokenStream().LT(1); tok != nil && tok.GetTokenType() != antlr.TokenEOF {
+		// The stmtList rule doesn't end with EOF, so the parser stops
+		// quietly at the first token that can't continue the list.
+		return nil, errz.Err(&ParseError{Input: input, Issues: []ParseIssue{{
+			stage: "parser",
+			Token: tok.GetText(),
+			Span:  &Span{Start: tok.GetStart(), Stop: tok.GetStop()},
+			Msg:   buildIssueMsg(tok.GetText(), ""),
+			Line:  tok.GetLine(),
+			Col:   tok.GetColumn(),
+		}}})
+	}
+
+	return slCtx.(*slq.StmtListContext), nil
+}
+
+// newSLQParser returns a parser for input, with an error listener
+// attached to each of the lexer and the parser.
+func newSLQParser(log *slog.Logger, input string) (p *slq.SLQParser, lexErrs, parseErrs *antlrErrorListener) {
 	lex := slq.NewSLQLexer(antlr.NewInputStream(input))
 	lex.RemoveErrorListeners() // the generated lexer has default listeners we don't want
-	lexErrs := &antlrErrorListener{name: "lexer", log: log, input: input}
+	lexErrs = &antlrErrorListener{name: "lexer", log: log, input: input}
 	lex.AddErrorListener(lexErrs)
 
-	p := slq.NewSLQParser(antlr.NewCommonTokenStream(lex, 0))
+	p = slq.NewSLQParser(antlr.NewCommonTokenStream(lex, 0))
 	p.RemoveErrorListeners() // the generated parser has default listeners we don't want
-	parseErrs := &antlrErrorListener{name: "parser", log: log, input: input}
+	parseErrs = &antlrErrorListener{name: "parser", log: log, input: input}
 	p.AddErrorListener(parseErrs)
+	return p, lexErrs, parseErrs
+}
 
-	qCtx := p.Query()
+// checkParseErrs logs the diagnostics of lexErrs and parseErrs, and
+// returns an error if either reported an issue.
+func checkParseErrs(lexErrs, parseErrs *antlrErrorListener) error {
 	lexErrs.logDiagnostics()
 	parseErrs.logDiagnostics()
 	if err := lexErrs.error(); err != nil {
-		return nil, errz.Err(err)
+		return errz.Err(err)
 	}
 	if err := parseErrs.error(); err != nil {
-		return nil, errz.Err(err)
+		return errz.Err(err)
 	}
-
-	return qCtx.(*slq.QueryContext), nil
+	return nil
 }
 
 var _ antlr.ErrorListener = (*antlrErrorListener)(nil)
@@ -322,9 +363,11 @@ func (v *parseTreeVisitor) VisitElement(ctx *slq.ElementContext) any {
 	return v.VisitChildren(ctx)
 }
 
-// VisitStmtList implements slq.SLQVisitor.
+// VisitStmtList implements slq.SLQVisitor. A StmtList is never visited:
+// SplitStatements splits it into its queries, and each query is then
+// parsed individually via Parse.
 func (v *parseTreeVisitor) VisitStmtList(_ *slq.StmtListContext) any {
-	return nil // not using StmtList just yet
+	return nil
 }
 
 // VisitUnaryOperator implements slq.SLQVisitor.
@@ -0,0 +1,62 @@
+package ioz
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+var _ io.ReadCloser = (*followReader)(nil)
+
+// NewFollowReader returns an io.ReadCloser that reads f, and on reaching
+// the end of f, waits for more data to be appended, as "tail -f" does,
+// checking every poll interval. If f is truncated, reading resumes from
+// the start of f. The reader returns io.EOF only when ctx is done. Close
+// closes f.
+func NewFollowReader(ctx context.Context, f *os.File, poll time.Duration) io.ReadCloser {
+	return &followReader{ctx: ctx, f: f, poll: poll}
+}
+
+type followReader struct {
+	ctx    context.Context
+	f      *os.File
+	poll   time.Duration
+	offset int64
+}
+
+// Read implements io.Reader.
+func (r *followReader) Read(p []byte) (n int, err error) {
+	for {
+		n, err = r.f.Read(p)
+		r.offset += int64(n)
+		if n > 0 || (err != nil && err != io.EOF) { //nolint:errorlint
+			return n, err
+		}
+
+		// We're at the end of the file: wait for it to grow.
+		select {
+		case <-r.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(r.poll):
+		}
+
+		fi, err := r.f.Stat()
+		if err != nil {
+			return 0, errz.Err(err)
+		}
+		if fi.Size() < r.offset {
+			// The file was truncated, e.g. by log rotation.
+			if r.offset, err = r.f.Seek(0, io.SeekStart); err != nil {
+				return 0, errz.Err(err)
+			}
+		}
+	}
+}
+
+// Close implements io.Closer.
+func (r *followReader) Close() error {
+	return r.f.Close()
+}
@@ -530,6 +530,54 @@ func TestOptUserAgent(t *testing.T) {
 	require.Equal(t, "my-agent/1.0", gotUA)
 }
 
+func TestOptHeaders(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+	}))
+	t.Cleanup(srv.Close)
+
+	c := httpz.NewClient(httpz.OptHeaders(http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Multi":       {"a", "b"},
+	}))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Basic old")
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, []string{"Bearer abc"}, gotHeader.Values("Authorization"))
+	require.Equal(t, []string{"a", "b"}, gotHeader.Values("X-Multi"))
+	require.Equal(t, "Basic old", req.Header.Get("Authorization"), "caller's request should be unchanged")
+}
+
+func TestOptForHost(t *testing.T) {
+	var gotAuth []string
+	other := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(other.Close)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	c := httpz.NewClient(httpz.OptForHost(u.Host, httpz.OptHeaders(http.Header{
+		"Authorization": {"Bearer abc"},
+	})))
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"Bearer abc", ""}, gotAuth,
+		"the redirect target on another host shouldn't get the header")
+}
+
 func TestOptResponseTimeout_success(t *testing.T) {
 	const body = "hello"
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
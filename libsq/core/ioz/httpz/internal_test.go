@@ -2,6 +2,7 @@ package httpz
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"testing"
 
@@ -56,3 +57,18 @@ func TestOptInsecureSkipVerify_apply(t *testing.T) {
 	require.True(t, tr2.TLSClientConfig.InsecureSkipVerify)
 	require.Equal(t, "example.com", tr2.TLSClientConfig.ServerName)
 }
+
+func TestOptRootCAs_apply(t *testing.T) {
+	pool := x509.NewCertPool()
+
+	// Nil pool: no-op.
+	tr1 := &http.Transport{}
+	OptRootCAs{}.apply(tr1)
+	require.Nil(t, tr1.TLSClientConfig)
+
+	// Existing config: the pool is set and other fields preserved.
+	tr2 := &http.Transport{TLSClientConfig: &tls.Config{ServerName: "example.com"}}
+	OptRootCAs{Pool: pool}.apply(tr2)
+	require.Same(t, pool, tr2.TLSClientConfig.RootCAs)
+	require.Equal(t, "example.com", tr2.TLSClientConfig.ServerName)
+}
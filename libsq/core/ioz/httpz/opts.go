@@ -3,8 +3,10 @@ package httpz
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net/http"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -39,6 +41,43 @@ func (b OptInsecureSkipVerify) apply(tr *http.Transport) {
 	tr.TLSClientConfig.InsecureSkipVerify = bool(b)
 }
 
+var _ Opt = OptRootCAs{}
+
+// OptRootCAs is an Opt that can be passed to NewClient to verify server
+// certificates against Pool instead of the system roots. A nil Pool is a
+// no-op.
+type OptRootCAs struct {
+	Pool *x509.CertPool
+}
+
+func (o OptRootCAs) apply(tr *http.Transport) {
+	if o.Pool == nil {
+		return
+	}
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	tr.TLSClientConfig.RootCAs = o.Pool
+}
+
+var _ Opt = OptClientCert{}
+
+// OptClientCert is an Opt that can be passed to NewClient to present Cert
+// as the TLS client certificate. A nil Cert is a no-op.
+type OptClientCert struct {
+	Cert *tls.Certificate
+}
+
+func (o OptClientCert) apply(tr *http.Transport) {
+	if o.Cert == nil {
+		return
+	}
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	tr.TLSClientConfig.Certificates = []tls.Certificate{*o.Cert}
+}
+
 var _ Opt = (*minTLSVersion)(nil)
 
 type minTLSVersion uint16
@@ -70,6 +109,42 @@ func OptUserAgent(ua string) TripFunc {
 	}
 }
 
+// OptHeaders is passed to NewClient to set the request headers in h,
+// replacing any existing values for those headers. If h is empty, this
+// is no-op. If h holds credentials, use OptForHost to restrict the
+// headers to the credentials' host.
+func OptHeaders(h http.Header) TripFunc {
+	if len(h) == 0 {
+		return NopTripFunc
+	}
+
+	return func(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+		// A RoundTripper must not modify the request.
+		req = req.Clone(req.Context())
+		for k, vals := range h {
+			req.Header.Del(k)
+			for _, v := range vals {
+				req.Header.Add(k, v)
+			}
+		}
+		return next.RoundTrip(req)
+	}
+}
+
+// OptForHost returns a TripFunc that applies fn only to requests to host,
+// such as "example.com" or "example.com:8080". It's for TripFuncs that
+// add credentials to the request: the client follows redirects, and the
+// credentials mustn't be sent to another host, such as a server that
+// serves the redirect target via a presigned URL.
+func OptForHost(host string, fn TripFunc) TripFunc {
+	return func(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+		if !strings.EqualFold(req.URL.Host, host) {
+			return next.RoundTrip(req)
+		}
+		return fn(next, req)
+	}
+}
+
 // DefaultUserAgent is the default User-Agent header value,
 // as used by NewDefaultClient.
 var DefaultUserAgent = OptUserAgent(buildinfo.Get().UserAgent())
@@ -624,6 +624,48 @@ func TestWriteErrorCloser(t *testing.T) {
 	})
 }
 
+func TestFollowReader(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(fp, []byte("one\n"), 0o600))
+
+	f, err := os.Open(fp)
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	r := ioz.NewFollowReader(ctx, f, time.Millisecond*10)
+
+	buf := make([]byte, 64)
+	readString := func() string {
+		t.Helper()
+		n, err := r.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+	require.Equal(t, "one\n", readString())
+
+	// Append to the file: the reader picks up the new data.
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		af, err := os.OpenFile(fp, os.O_APPEND|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = af.WriteString("two\n")
+			_ = af.Close()
+		}
+	}()
+	require.Equal(t, "two\n", readString())
+
+	// Truncate the file: the reader resumes from the start.
+	require.NoError(t, os.WriteFile(fp, []byte("3\n"), 0o600))
+	require.Equal(t, "3\n", readString())
+
+	cancelFn()
+	n, err := r.Read(buf)
+	require.Zero(t, n)
+	require.Equal(t, io.EOF, err)
+	require.NoError(t, r.Close())
+}
+
 // --- test helpers ---
 
 type errCloser struct{ err error }
@@ -20,7 +20,11 @@ import (
 type Detector struct {
 	kinds    map[Kind]struct{}
 	mungeFns map[Kind]MungeFunc
-	dirty    bool
+
+	// layouts holds custom layouts for Time, Date and Datetime,
+	// which are tried before the built-in layouts.
+	layouts map[Kind][]string
+	dirty   bool
 
 	// foundString is set to true if any of the values passed
 	// to Detector.Sample had type string.
@@ -43,6 +47,28 @@ func NewDetector() *Detector {
 	}
 }
 
+// SetLayouts sets custom stdlib time layouts for kind k, which must be
+// Time, Date or Datetime. When detecting that kind, the custom layouts are
+// tried before the built-in layouts, and a value matched by a custom layout
+// is munged using that layout. SetLayouts must be invoked before Sample.
+func (d *Detector) SetLayouts(k Kind, layouts ...string) {
+	if d.layouts == nil {
+		d.layouts = map[Kind][]string{}
+	}
+	d.layouts[k] = layouts
+}
+
+// detectLayout returns the layout of s for kind k, trying d's custom
+// layouts before detectFn, which tries the built-in layouts.
+func (d *Detector) detectLayout(k Kind, s string, detectFn func(string) (bool, string)) (ok bool, format string) {
+	for _, layout := range d.layouts[k] {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true, layout
+		}
+	}
+	return detectFn(s)
+}
+
 // Sample adds a sample to the detector.
 func (d *Detector) Sample(v any) {
 	switch v.(type) {
@@ -147,7 +173,7 @@ func (d *Detector) doSampleString(s string) {
 	}
 
 	if d.has(Time) {
-		ok, format := detectKindTime(s)
+		ok, format := d.detectLayout(Time, s, detectKindTime)
 		if !ok {
 			// It's not a recognized time format
 			d.delete(Time)
@@ -183,7 +209,7 @@ func (d *Detector) doSampleString(s string) {
 	}
 
 	if d.has(Date) {
-		ok, format := detectKindDate(s)
+		ok, format := d.detectLayout(Date, s, detectKindDate)
 		if !ok {
 			// It's not a recognized date format
 			d.delete(Date)
@@ -218,7 +244,7 @@ func (d *Detector) doSampleString(s string) {
 	}
 
 	if d.has(Datetime) {
-		ok, format := detectKindDatetime(s)
+		ok, format := d.detectLayout(Datetime, s, detectKindDatetime)
 		if !ok {
 			// It's not a recognized datetime format
 			d.delete(Datetime)
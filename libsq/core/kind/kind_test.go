@@ -179,3 +179,72 @@ func TestDetector(t *testing.T) {
 		})
 	}
 }
+
+func TestMungeFuncFor(t *testing.T) {
+	testCases := []struct {
+		k       kind.Kind
+		in      any
+		want    any
+		wantErr bool
+	}{
+		{k: kind.Text, in: "01234", want: "01234"},
+		{k: kind.Text, in: "", want: ""},
+		{k: kind.Text, in: float64(2134), want: "2134"},
+		{k: kind.Text, in: stdj.Number("2134"), want: "2134"},
+		{k: kind.Int, in: "42", want: int64(42)},
+		{k: kind.Int, in: "", want: nil},
+		{k: kind.Int, in: nil, want: nil},
+		{k: kind.Int, in: float64(7), want: int64(7)},
+		{k: kind.Int, in: stdj.Number("7"), want: int64(7)},
+		{k: kind.Int, in: "4.2", wantErr: true},
+		{k: kind.Float, in: "4.2", want: 4.2},
+		{k: kind.Decimal, in: "1.10", want: decimal.RequireFromString("1.10")},
+		{k: kind.Bool, in: "yes", want: true},
+		{k: kind.Bool, in: "maybe", wantErr: true},
+		{k: kind.Date, in: "09 Nov 1989", want: "1989-11-09"},
+		{k: kind.Date, in: "not a date", wantErr: true},
+		{k: kind.Time, in: "7:30 pm", want: "19:30:00"},
+		{k: kind.Datetime, in: "not a datetime", wantErr: true},
+		{k: kind.Null, wantErr: true},
+		{k: kind.Unknown, wantErr: true},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tu.Name(i, tc.k, tc.in), func(t *testing.T) {
+			fn, err := kind.MungeFuncFor(tc.k)
+			if err == nil {
+				var got any
+				got, err = fn(tc.in)
+				if err == nil {
+					require.False(t, tc.wantErr, "expected error")
+					require.Equal(t, tc.want, got)
+					return
+				}
+			}
+
+			require.True(t, tc.wantErr, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestDetector_SetLayouts(t *testing.T) {
+	// Without a custom layout, "02/01/2024" is not a recognized date.
+	kd := kind.NewDetector()
+	kd.Sample("02/01/2024")
+	gotKind, _, err := kd.Detect()
+	require.NoError(t, err)
+	require.Equal(t, kind.Text, gotKind)
+
+	kd = kind.NewDetector()
+	kd.SetLayouts(kind.Date, "02/01/2006")
+	kd.Sample("02/01/2024")
+	kd.Sample("31/12/2024")
+	gotKind, mungeFn, err := kd.Detect()
+	require.NoError(t, err)
+	require.Equal(t, kind.Date, gotKind)
+	require.NotNil(t, mungeFn)
+
+	got, err := mungeFn("02/01/2024")
+	require.NoError(t, err)
+	require.Equal(t, "2024-01-02", got)
+}
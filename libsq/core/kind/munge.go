@@ -1,5 +1,18 @@
 package kind
 
+import (
+	stdj "encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+)
+
 // MungeFunc is a function that accepts a value and returns a munged
 // value with the appropriate Kind. For example, a Datetime MungeFunc
 // would accept string "2020-06-11T02:50:54Z" and return a time.Time.
@@ -25,3 +38,117 @@ func MungeEmptyStringAsNil(v any) (any, error) {
 
 	return v, nil
 }
+
+// MungeFuncFor returns a MungeFunc that converts a value to kind k. It's
+// used when a column's kind is declared rather than detected, e.g. via an
+// ingest schema, so the value's format isn't known in advance: each value
+// is parsed individually. The returned value has the same canonical form
+// as the MungeFunc returned by Detector.Detect: for Date and Time, a
+// "2006-01-02" or "15:04:05" string; for Datetime, a time.Time. Nil, and
+// the empty string for any kind other than Text, are munged to nil.
+//
+// It returns an error if k is not a kind that a column can be declared as,
+// i.e. Unknown or Null.
+func MungeFuncFor(k Kind) (MungeFunc, error) {
+	var fn func(s string) (any, error)
+	switch k {
+	case Text:
+		return mungeText, nil
+	case Int:
+		fn = func(s string) (any, error) { return errz.Return(strconv.ParseInt(s, 10, 64)) }
+	case Float:
+		fn = func(s string) (any, error) { return errz.Return(strconv.ParseFloat(s, 64)) }
+	case Decimal:
+		fn = func(s string) (any, error) { return errz.Return(decimal.NewFromString(s)) }
+	case Bool:
+		fn = func(s string) (any, error) { return errz.Return(stringz.ParseBool(s)) }
+	case Bytes:
+		fn = func(s string) (any, error) { return []byte(s), nil }
+	case Datetime:
+		fn = func(s string) (any, error) {
+			ok, format := detectKindDatetime(s)
+			if !ok {
+				return nil, errz.Errorf("unrecognized datetime format: %s", s)
+			}
+			return errz.Return(time.Parse(format, s))
+		}
+	case Date:
+		fn = mungeTimeString(Date, detectKindDate, time.DateOnly)
+	case Time:
+		fn = mungeTimeString(Time, detectKindTime, time.TimeOnly)
+	default:
+		return nil, errz.Errorf("cannot munge to kind {%s}", k)
+	}
+
+	return func(v any) (any, error) {
+		if n, ok := v.(stdj.Number); ok {
+			v = n.String()
+		}
+
+		switch v := v.(type) {
+		case nil:
+			return nil, nil //nolint:nilnil
+		case string:
+			if v == "" {
+				return nil, nil //nolint:nilnil
+			}
+			val, err := fn(strings.TrimSpace(v))
+			if err != nil {
+				return nil, errz.Wrapf(err, "convert %q to %s", v, k)
+			}
+			return val, nil
+		case time.Time:
+			switch k { //nolint:exhaustive
+			case Date:
+				return v.Format(time.DateOnly), nil
+			case Time:
+				return v.Format(time.TimeOnly), nil
+			}
+			return v, nil
+		case float64:
+			if k == Int && v == float64(int64(v)) {
+				// A whole JSON number.
+				return int64(v), nil
+			}
+			return v, nil
+		default:
+			// The value is already typed, e.g. it's an int from
+			// an XLSX cell: the DB handles any conversion.
+			return v, nil
+		}
+	}, nil
+}
+
+// mungeText munges v to a string, so that, for example, a numeric value
+// that should be text keeps its representation.
+func mungeText(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// mungeTimeString returns a func that parses a Date or Time value, as
+// identified by detectFn, returning it formatted per canonical.
+func mungeTimeString(k Kind, detectFn func(string) (bool, string), canonical string,
+) func(string) (any, error) {
+	return func(s string) (any, error) {
+		ok, format := detectFn(s)
+		if !ok {
+			return nil, errz.Errorf("unrecognized %s format: %s", k, s)
+		}
+		t, err := time.Parse(format, s)
+		if err != nil {
+			return nil, errz.Err(err)
+		}
+		return t.Format(canonical), nil
+	}
+}
@@ -11,6 +11,7 @@ const (
 	Catalog       = "catalog"
 	Cmd           = "cmd"
 	Col           = "column"
+	Cols          = "columns"
 	Count         = "count"
 	Commit        = "commit"
 	Conn          = "conn"
@@ -29,6 +29,12 @@ const (
 	// during ingestion. This tag is significant in that its value may affect
 	// data realization, and thus affect program aspects such as caching behavior.
 	TagIngestMutate = "mutate"
+
+	// TagIngestFile indicates the Opt's value may be the path of a file,
+	// such as a schema file or masking policy file. A relative path is made
+	// absolute when the Opt is set via "sq add" or "sq config set", and the
+	// file is among the files that a source's ingest cache is derived from.
+	TagIngestFile = "ingest_file"
 )
 
 // Opt is an option type. Concrete impls exist for various types,
@@ -40,7 +40,7 @@ func NewResolver() *Resolver {
 // user's home directory. Otherwise path must be absolute. Relative
 // paths return an error.
 func (r *Resolver) Resolve(_ context.Context, path string) (string, error) {
-	resolved, err := expandPath(path)
+	resolved, err := ExpandPath(path)
 	if err != nil {
 		return "", err
 	}
@@ -68,10 +68,11 @@ func (r *Resolver) Resolve(_ context.Context, path string) (string, error) {
 	return s, nil
 }
 
-// expandPath resolves "~" and "~/..." to the user's home directory.
+// ExpandPath resolves "~" and "~/..." to the user's home directory.
 // Other paths must be absolute; relative paths and URI forms (file://)
-// are rejected.
-func expandPath(path string) (string, error) {
+// are rejected. It is exported for other file-backed resolvers, such as
+// secret/sops, that accept the same path forms.
+func ExpandPath(path string) (string, error) {
 	if path == "" {
 		return "", errz.New("empty path")
 	}
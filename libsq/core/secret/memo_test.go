@@ -110,3 +110,28 @@ func TestRegistry_ConcurrentResolveSingleflight(t *testing.T) {
 	require.Equal(t, int32(1), resolver.count.Load(),
 		"concurrent resolutions of one path must share a single backend hit")
 }
+
+// leasedResolver is a countingResolver that reports every value as leased.
+type leasedResolver struct {
+	countingResolver
+}
+
+func (r *leasedResolver) Expires(string) bool { return true }
+
+// TestRegistry_DoesNotMemoizeLeased verifies that values from a Resolver
+// implementing secret.Expirer bypass the Registry memo, so that the
+// Resolver gets the chance to renew or re-issue an expiring lease.
+func TestRegistry_DoesNotMemoizeLeased(t *testing.T) {
+	ctx := context.Background()
+
+	reg := secret.NewRegistry()
+	leased := &leasedResolver{countingResolver{value: "hunter2"}}
+	reg.Register("test", leased)
+
+	for range 3 {
+		got, err := reg.ResolveScheme(ctx, "test", "pw")
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", got)
+	}
+	require.Equal(t, 3, leased.count)
+}
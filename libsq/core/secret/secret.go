@@ -69,6 +69,17 @@ type Resolver interface {
 	Resolve(ctx context.Context, path string) (string, error)
 }
 
+// Expirer is an optional interface implemented by a Resolver whose values
+// can carry a lease that expires, such as Vault dynamic database
+// credentials. Registry does not memoize a value for which Expires reports
+// true, so each resolution reaches the Resolver, which is then responsible
+// for its own caching and lease renewal.
+type Expirer interface {
+	// Expires reports whether the most recently resolved value for path is
+	// leased. It is called only after a successful Resolve of path.
+	Expires(path string) bool
+}
+
 // Registry maps schemes to Resolvers.
 type Registry struct {
 	resolvers map[string]Resolver
@@ -88,7 +99,7 @@ type Registry struct {
 	// backend hit, which for keyring is an OS keychain roundtrip that may
 	// prompt the user. The memo also gives one invocation a consistent
 	// view of each secret. Failures are not cached, so transient backend
-	// errors don't stick.
+	// errors don't stick. Leased values (see Expirer) are not memoized.
 	memo sync.Map
 
 	mu sync.RWMutex
@@ -168,7 +179,9 @@ func (r *Registry) ResolveScheme(ctx context.Context, scheme, path string) (stri
 		if err != nil {
 			return nil, err
 		}
-		r.memo.Store(key, v)
+		if e, ok := resolver.(Expirer); !ok || !e.Expires(path) {
+			r.memo.Store(key, v)
+		}
 		return v, nil
 	})
 	select {
@@ -0,0 +1,692 @@
+// Package sops is the encrypted-secrets-file backend for libsq/core/secret.
+// A ${sops:<file>#<key.path>} placeholder resolves to the value at the
+// dotted key path of an encrypted YAML or JSON document. For example,
+// ${sops:/work/proj/secrets.enc.yaml#pg.password} resolves to the value of
+// key "password" inside the top-level "pg" map.
+//
+// Two file formats are supported:
+//
+//   - SOPS files (https://getsops.io) encrypted with the age backend. Each
+//     value is decrypted individually, using the data key held in the
+//     file's "sops.age" metadata, and the file's MAC is verified, as the
+//     sops CLI does. A plaintext value is rejected unless its key is
+//     exempted from encryption by the file's unencrypted_suffix,
+//     encrypted_suffix, unencrypted_regex or encrypted_regex setting.
+//     Only the age key source is supported. sq never writes SOPS files:
+//     edit them with the sops CLI.
+//   - Whole-file age encryption (https://age-encryption.org), armored or
+//     binary, of a plaintext YAML or JSON document. This is the format
+//     written by WriteFile, e.g. by "sq config sops migrate".
+//
+// The age identities come from the same places the sops CLI looks:
+// the SOPS_AGE_KEY env var (the key file contents), the key file named by
+// SOPS_AGE_KEY_FILE, or else "sops/age/keys.txt" in the user's config
+// dir. No keyring or agent is involved, so CI runners only need the key.
+//
+// As with the file resolver, the path must be absolute or start with "~/";
+// "sq add" rewrites a relative path to absolute at add time.
+package sops
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/goccy/go-yaml"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/core/secret/file"
+)
+
+// Environment variables consulted for age keys. The names match the sops
+// CLI's, so an environment set up for sops works unchanged.
+const (
+	EnvAgeKey        = "SOPS_AGE_KEY"
+	EnvAgeKeyFile    = "SOPS_AGE_KEY_FILE"
+	EnvAgeRecipients = "SOPS_AGE_RECIPIENTS"
+)
+
+// metadataKey is the top-level key holding SOPS metadata.
+const metadataKey = "sops"
+
+// binaryHeader is the first line of a binary (non-armored) age file.
+const binaryHeader = "age-encryption.org/v1"
+
+// Resolver implements secret.Resolver against encrypted YAML/JSON files.
+// A single Resolver caches each decrypted document for its lifetime, so a
+// file referenced by several placeholders is read and its key unwrapped
+// only once per sq invocation. Concurrent loads of the same file are
+// coalesced via singleflight.
+type Resolver struct {
+	flight singleflight.Group
+	cache  sync.Map // absolute file path -> *document
+}
+
+// NewResolver returns a Resolver. Callers register the result with a
+// secret.Registry under the "sops" scheme.
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// document is a decoded secrets file. Its tree is plaintext: a SOPS
+// file's values are decrypted, and its MAC verified, when it's read.
+type document struct {
+	tree map[string]any
+}
+
+// Resolve returns the value at the key path of the file named by path,
+// which has the form "<file>#<key.path>". Returns secret.ErrNotFound when
+// the file or the key does not exist.
+func (r *Resolver) Resolve(ctx context.Context, path string) (string, error) {
+	fp, keyPath, err := SplitPath(path)
+	if err != nil {
+		return "", err
+	}
+	if fp, err = file.ExpandPath(fp); err != nil {
+		return "", err
+	}
+
+	if v, ok := r.cache.Load(fp); ok {
+		return v.(*document).lookup(keyPath)
+	}
+
+	ch := r.flight.DoChan(fp, func() (any, error) {
+		if v, ok := r.cache.Load(fp); ok {
+			return v, nil
+		}
+		ids, err := LoadIdentities()
+		if err != nil {
+			return nil, err
+		}
+		doc, err := readDocument(fp, ids)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.Store(fp, doc)
+		return doc, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(*document).lookup(keyPath)
+	case <-ctx.Done():
+		return "", errz.Wrapf(ctx.Err(), "sops read %s", fp)
+	}
+}
+
+// SplitPath splits a placeholder path into the file path and the dotted
+// key path.
+func SplitPath(path string) (fp, keyPath string, err error) {
+	i := strings.LastIndexByte(path, '#')
+	if i < 0 {
+		return "", "", errz.Errorf("sops: missing #key in %q: expected <file>#<key.path>", path)
+	}
+	fp, keyPath = path[:i], path[i+1:]
+	if fp == "" || keyPath == "" || slices.Contains(strings.Split(keyPath, "."), "") {
+		return "", "", errz.Errorf("sops: invalid path %q: expected <file>#<key.path>", path)
+	}
+	return fp, keyPath, nil
+}
+
+// lookup returns the scalar value at keyPath.
+func (d *document) lookup(keyPath string) (string, error) {
+	keys := strings.Split(keyPath, ".")
+	var node any = d.tree
+	for _, k := range keys {
+		m, ok := asMap(node)
+		if !ok {
+			return "", secret.ErrNotFound
+		}
+		if node, ok = m[k]; !ok {
+			return "", secret.ErrNotFound
+		}
+	}
+
+	switch v := node.(type) {
+	case nil:
+		return "", secret.ErrNotFound
+	case string:
+		return v, nil
+	case map[string]any, map[any]any, []any:
+		return "", errz.Errorf("sops: value at %q is not a scalar", keyPath)
+	default:
+		// A plaintext (whole-file age, or SOPS unencrypted) number or bool.
+		return fmt.Sprint(v), nil
+	}
+}
+
+// encValuePattern matches a SOPS-encrypted value.
+var encValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// decryptValue decrypts a single SOPS value, returning its plaintext
+// bytes and its SOPS type, e.g. "str" or "bool".
+func decryptValue(v string, dataKey []byte, aad string) (plain []byte, typ string, err error) {
+	m := encValuePattern.FindStringSubmatch(v)
+	if m == nil {
+		return nil, "", errz.New("sops: value is not encrypted")
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[i+1])
+		if err != nil {
+			return nil, "", errz.Wrap(err, "sops: decode encrypted value")
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, "", errz.Wrap(err, "sops: data key")
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", errz.Wrap(err, "sops: data key")
+	}
+	plain, err = gcm.Open(nil, iv, append(data, tag...), []byte(aad))
+	if err != nil {
+		return nil, "", errz.Wrap(err, "sops: decrypt value: wrong key, or the file has been tampered with")
+	}
+	return plain, m[4], nil
+}
+
+// plainValue returns the string form of a decrypted value of SOPS type typ.
+func plainValue(plain []byte, typ string) (string, error) {
+	switch typ {
+	case "str", "int", "float", "bytes":
+		return string(plain), nil
+	case "bool":
+		// SOPS stores booleans as "True" and "False".
+		b, err := strconv.ParseBool(strings.ToLower(string(plain)))
+		if err != nil {
+			return "", errz.Wrap(err, "sops: decrypt bool value")
+		}
+		return strconv.FormatBool(b), nil
+	default:
+		return "", errz.Errorf("sops: unsupported value type %q", typ)
+	}
+}
+
+// readDocument reads and decodes the encrypted file at fp.
+func readDocument(fp string, ids []age.Identity) (*document, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, secret.ErrNotFound
+		}
+		return nil, errz.Err(err)
+	}
+
+	if isAgeFile(data) {
+		tree, err := decryptAgeDocument(data, ids)
+		if err != nil {
+			return nil, errz.Wrapf(err, "sops: %s", fp)
+		}
+		return &document{tree: tree}, nil
+	}
+
+	// The MAC is computed over the values in document order, so the
+	// document is decoded as ordered maps.
+	var ordered yaml.MapSlice
+	if err = yaml.UnmarshalWithOptions(data, &ordered, yaml.UseOrderedMap()); err != nil {
+		return nil, errz.Wrapf(err, "sops: %s", fp)
+	}
+	var meta map[string]any
+	body := make(yaml.MapSlice, 0, len(ordered))
+	for _, item := range ordered {
+		if fmt.Sprint(item.Key) == metadataKey {
+			meta, _ = unorder(item.Value).(map[string]any)
+			continue
+		}
+		body = append(body, item)
+	}
+	if meta == nil {
+		return nil, errz.Errorf("sops: %s is neither a SOPS file nor age-encrypted", fp)
+	}
+
+	dataKey, err := unwrapDataKey(meta, ids)
+	if err != nil {
+		return nil, errz.Wrapf(err, "sops: %s", fp)
+	}
+	tree, err := decryptTree(body, meta, dataKey)
+	if err != nil {
+		return nil, errz.Wrapf(err, "sops: %s", fp)
+	}
+	return &document{tree: tree}, nil
+}
+
+// decryptTree decrypts the values of the SOPS document body, and verifies
+// the document's MAC: the SHA-512 of every value's plaintext, in document
+// order, as recorded in the metadata's "mac" field.
+func decryptTree(body yaml.MapSlice, meta map[string]any, dataKey []byte) (map[string]any, error) {
+	rules, err := newEncryptionRules(meta)
+	if err != nil {
+		return nil, err
+	}
+	d := &treeDecrypter{
+		dataKey:          dataKey,
+		rules:            rules,
+		macOnlyEncrypted: meta["mac_only_encrypted"] == true,
+		hash:             sha512.New(),
+	}
+	tree, err := d.walk(body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mac, _ := meta["mac"].(string)
+	if mac == "" {
+		return nil, errz.New("no MAC in SOPS metadata")
+	}
+	// The MAC is bound to the file's last-modified time.
+	wantMAC, _, err := decryptValue(mac, dataKey, fmt.Sprint(meta["lastmodified"]))
+	if err != nil {
+		return nil, errz.Wrap(err, "decrypt MAC")
+	}
+	if gotMAC := fmt.Sprintf("%X", d.hash.Sum(nil)); gotMAC != string(wantMAC) {
+		return nil, errz.New("MAC mismatch: the file has been tampered with")
+	}
+	return tree.(map[string]any), nil
+}
+
+// treeDecrypter walks a SOPS document body, decrypting its values and
+// hashing their plaintext for the MAC.
+type treeDecrypter struct {
+	dataKey          []byte
+	rules            encryptionRules
+	macOnlyEncrypted bool
+	hash             hash.Hash
+}
+
+// walk returns the plaintext form of v, the value at path. Ordered maps
+// are returned as map[string]any.
+func (d *treeDecrypter) walk(v any, path []string) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case yaml.MapSlice:
+		m := make(map[string]any, len(v))
+		for _, item := range v {
+			k := fmt.Sprint(item.Key)
+			val, err := d.walk(item.Value, append(path[:len(path):len(path)], k))
+			if err != nil {
+				return nil, err
+			}
+			m[k] = val
+		}
+		return m, nil
+	case []any:
+		// As with sops, list elements share the list's key path.
+		out := make([]any, len(v))
+		for i, elem := range v {
+			val, err := d.walk(elem, path)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	}
+
+	if !d.rules.encrypted(path) {
+		if !d.macOnlyEncrypted {
+			d.hash.Write(macBytes(v))
+		}
+		return v, nil
+	}
+
+	s, ok := v.(string)
+	if !ok || !encValuePattern.MatchString(s) {
+		return nil, errz.Errorf("value at %q is not encrypted: the file has been tampered with",
+			strings.Join(path, "."))
+	}
+	// SOPS binds each value to its location by using the key path,
+	// colon-terminated, as the AES-GCM additional data.
+	plain, typ, err := decryptValue(s, d.dataKey, strings.Join(path, ":")+":")
+	if err != nil {
+		return nil, errz.Wrapf(err, "value at %q", strings.Join(path, "."))
+	}
+	d.hash.Write(plain)
+	return plainValue(plain, typ)
+}
+
+// macBytes returns the bytes of the plaintext value v that sops hashes for
+// the MAC.
+func macBytes(v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case bool:
+		// SOPS writes booleans as "True" and "False".
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// defaultUnencryptedSuffix is the sops default for keys left unencrypted,
+// applied when the file's metadata sets none of the encryption rules.
+const defaultUnencryptedSuffix = "_unencrypted"
+
+// encryptionRules are a SOPS file's settings for which values are
+// encrypted.
+type encryptionRules struct {
+	unencryptedSuffix string
+	encryptedSuffix   string
+	unencryptedRegex  *regexp.Regexp
+	encryptedRegex    *regexp.Regexp
+}
+
+// newEncryptionRules returns the encryption rules of the SOPS metadata.
+func newEncryptionRules(meta map[string]any) (encryptionRules, error) {
+	var rules encryptionRules
+	rules.unencryptedSuffix, _ = meta["unencrypted_suffix"].(string)
+	rules.encryptedSuffix, _ = meta["encrypted_suffix"].(string)
+	for key, re := range map[string]**regexp.Regexp{
+		"unencrypted_regex": &rules.unencryptedRegex,
+		"encrypted_regex":   &rules.encryptedRegex,
+	} {
+		if s, _ := meta[key].(string); s != "" {
+			var err error
+			if *re, err = regexp.Compile(s); err != nil {
+				return rules, errz.Wrapf(err, "SOPS metadata: %s", key)
+			}
+		}
+	}
+	if rules.unencryptedSuffix == "" && rules.encryptedSuffix == "" &&
+		rules.unencryptedRegex == nil && rules.encryptedRegex == nil {
+		rules.unencryptedSuffix = defaultUnencryptedSuffix
+	}
+	return rules, nil
+}
+
+// encrypted reports whether the value at path should be encrypted. The
+// rules are applied in the same order as sops applies them.
+func (r encryptionRules) encrypted(path []string) bool {
+	encrypted := true
+	if r.unencryptedSuffix != "" &&
+		slices.ContainsFunc(path, func(k string) bool { return strings.HasSuffix(k, r.unencryptedSuffix) }) {
+		encrypted = false
+	}
+	if r.encryptedSuffix != "" {
+		encrypted = slices.ContainsFunc(path, func(k string) bool { return strings.HasSuffix(k, r.encryptedSuffix) })
+	}
+	if r.unencryptedRegex != nil && slices.ContainsFunc(path, r.unencryptedRegex.MatchString) {
+		encrypted = false
+	}
+	if r.encryptedRegex != nil {
+		encrypted = slices.ContainsFunc(path, r.encryptedRegex.MatchString)
+	}
+	return encrypted
+}
+
+// unorder returns v with its ordered maps converted to map[string]any.
+func unorder(v any) any {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		m := make(map[string]any, len(v))
+		for _, item := range v {
+			m[fmt.Sprint(item.Key)] = unorder(item.Value)
+		}
+		return m
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = unorder(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// unwrapDataKey decrypts the SOPS data key from the file's age stanzas,
+// using whichever stanza one of ids can decrypt.
+func unwrapDataKey(meta map[string]any, ids []age.Identity) ([]byte, error) {
+	stanzas, _ := meta["age"].([]any)
+	if len(stanzas) == 0 {
+		return nil, errz.New("no age recipients in SOPS metadata: only the age key source is supported")
+	}
+
+	var lastErr error
+	for _, s := range stanzas {
+		m, _ := asMap(s)
+		enc, _ := m["enc"].(string)
+		if enc == "" {
+			continue
+		}
+		rdr, err := age.Decrypt(armor.NewReader(strings.NewReader(enc)), ids...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		key, err := io.ReadAll(rdr)
+		if err != nil {
+			return nil, errz.Wrap(err, "read data key")
+		}
+		return key, nil
+	}
+	if lastErr == nil {
+		lastErr = errz.New("no usable age stanza")
+	}
+	return nil, errz.Wrap(lastErr, "decrypt data key")
+}
+
+// isAgeFile reports whether data is an armored or binary age file.
+func isAgeFile(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	return bytes.HasPrefix(data, []byte(armor.Header)) || bytes.HasPrefix(data, []byte(binaryHeader))
+}
+
+// decryptAgeDocument decrypts the age file data and decodes the plaintext
+// YAML or JSON document.
+func decryptAgeDocument(data []byte, ids []age.Identity) (map[string]any, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte(binaryHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimLeft(data, " \t\r\n")))
+	}
+	rdr, err := age.Decrypt(src, ids...)
+	if err != nil {
+		return nil, errz.Wrap(err, "decrypt")
+	}
+	plain, err := io.ReadAll(rdr)
+	if err != nil {
+		return nil, errz.Wrap(err, "decrypt")
+	}
+	tree := map[string]any{}
+	if len(bytes.TrimSpace(plain)) == 0 {
+		return tree, nil
+	}
+	if err = ioz.UnmarshallYAML(plain, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// asMap returns v as a map with string keys.
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			out[fmt.Sprint(k)] = val
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// LoadIdentities returns the age identities from SOPS_AGE_KEY,
+// SOPS_AGE_KEY_FILE, or the default sops key file, in that order.
+func LoadIdentities() ([]age.Identity, error) {
+	if v := os.Getenv(EnvAgeKey); v != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(v))
+		if err != nil {
+			return nil, errz.Wrapf(err, "sops: parse %s", EnvAgeKey)
+		}
+		return ids, nil
+	}
+
+	fp := os.Getenv(EnvAgeKeyFile)
+	if fp == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, errz.Wrapf(err, "sops: no age key: set %s or %s", EnvAgeKey, EnvAgeKeyFile)
+		}
+		fp = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errz.Errorf("sops: no age key: set %s or %s, or create %s", EnvAgeKey, EnvAgeKeyFile, fp)
+		}
+		return nil, errz.Wrap(err, "sops: read age key file")
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, errz.Wrapf(err, "sops: parse age key file %s", fp)
+	}
+	return ids, nil
+}
+
+// LoadRecipients returns the age recipients to encrypt to. If recips is
+// empty, the comma-separated SOPS_AGE_RECIPIENTS env var is used, and
+// failing that, the recipients of the X25519 identities returned by
+// LoadIdentities, so that the caller can always decrypt what it writes.
+func LoadRecipients(recips []string) ([]age.Recipient, error) {
+	if len(recips) == 0 {
+		if v := os.Getenv(EnvAgeRecipients); v != "" {
+			recips = strings.Split(v, ",")
+		}
+	}
+
+	if len(recips) > 0 {
+		out := make([]age.Recipient, 0, len(recips))
+		for _, s := range recips {
+			rcp, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+			if err != nil {
+				return nil, errz.Wrap(err, "sops: parse age recipient")
+			}
+			out = append(out, rcp)
+		}
+		return out, nil
+	}
+
+	ids, err := LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	var out []age.Recipient
+	for _, id := range ids {
+		if x, ok := id.(*age.X25519Identity); ok {
+			out = append(out, x.Recipient())
+		}
+	}
+	if len(out) == 0 {
+		return nil, errz.Errorf("sops: no age recipients: set %s", EnvAgeRecipients)
+	}
+	return out, nil
+}
+
+// ReadFile decrypts the whole-file age document at fp. If fp does not
+// exist, an empty document is returned. A SOPS file is an error: sq
+// doesn't write SOPS files, so it doesn't read them for update either.
+func ReadFile(fp string) (map[string]any, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]any{}, nil
+		}
+		return nil, errz.Err(err)
+	}
+	if !isAgeFile(data) {
+		return nil, errz.Errorf("sops: %s is not an age-encrypted file; edit SOPS files with the sops CLI", fp)
+	}
+	ids, err := LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decryptAgeDocument(data, ids)
+	if err != nil {
+		return nil, errz.Wrapf(err, "sops: %s", fp)
+	}
+	return tree, nil
+}
+
+// WriteFile encodes doc as YAML, encrypts it to recipients as an armored
+// age file, and atomically writes it to fp.
+func WriteFile(fp string, doc map[string]any, recipients []age.Recipient) error {
+	plain, err := ioz.MarshalYAML(doc)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	aw := armor.NewWriter(buf)
+	w, err := age.Encrypt(aw, recipients...)
+	if err != nil {
+		return errz.Wrap(err, "sops: encrypt")
+	}
+	if _, err = w.Write(plain); err != nil {
+		return errz.Wrap(err, "sops: encrypt")
+	}
+	if err = w.Close(); err != nil {
+		return errz.Wrap(err, "sops: encrypt")
+	}
+	if err = aw.Close(); err != nil {
+		return errz.Wrap(err, "sops: encrypt")
+	}
+	return ioz.WriteFileAtomic(fp, buf.Bytes(), 0o600)
+}
+
+// SetValue sets the value at the dotted keyPath of doc, creating
+// intermediate maps as needed.
+func SetValue(doc map[string]any, keyPath, value string) error {
+	keys := strings.Split(keyPath, ".")
+	m := doc
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := asMap(m[k])
+		if !ok {
+			if m[k] != nil {
+				return errz.Errorf("sops: value at %q is not a map", k)
+			}
+			next = map[string]any{}
+		}
+		m[k] = next
+		m = next
+	}
+	m[keys[len(keys)-1]] = value
+	return nil
+}
@@ -0,0 +1,326 @@
+package sops_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/core/secret/sops"
+)
+
+// setupKey generates an age identity, exposes it via SOPS_AGE_KEY, and
+// returns it.
+func setupKey(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Setenv(sops.EnvAgeKey, id.String())
+	t.Setenv(sops.EnvAgeKeyFile, "")
+	t.Setenv(sops.EnvAgeRecipients, "")
+	return id
+}
+
+// encryptValue encrypts v the way the sops CLI does, binding it to the
+// colon-joined key path.
+func encryptValue(t *testing.T, dataKey []byte, v, typ string, path ...string) string {
+	t.Helper()
+	return encryptAAD(t, dataKey, v, typ, strings.Join(path, ":")+":")
+}
+
+// encryptAAD encrypts v in SOPS's ENC[...] form with additional data aad.
+func encryptAAD(t *testing.T, dataKey []byte, v, typ, aad string) string {
+	t.Helper()
+	block, err := aes.NewCipher(dataKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	require.NoError(t, err)
+	iv := make([]byte, 32)
+	_, err = rand.Read(iv)
+	require.NoError(t, err)
+	out := gcm.Seal(nil, iv, []byte(v), []byte(aad))
+	data, tag := out[:len(out)-gcm.Overhead()], out[len(out)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), typ)
+}
+
+// sopsEntry is a value under the "pg" key of a test SOPS file.
+type sopsEntry struct {
+	key, plain, typ string
+
+	// aadKey, if set, is the key whose path the value is encrypted for.
+	aadKey string
+
+	// unencrypted writes the value as plaintext.
+	unencrypted bool
+}
+
+// defaultEntries are the values of the SOPS file written by writeSOPSFile.
+var defaultEntries = []sopsEntry{
+	{key: "password", plain: "hunter2", typ: "str"},
+	{key: "port", plain: "5432", typ: "int"},
+	{key: "ssl", plain: "True", typ: "bool"},
+	{key: "host_unencrypted", plain: "db.acme.com", unencrypted: true},
+}
+
+// writeSOPSFile writes a SOPS-format YAML file whose data key is wrapped
+// for id, holding defaultEntries.
+func writeSOPSFile(t *testing.T, id *age.X25519Identity) string {
+	t.Helper()
+	return writeSOPSEntries(t, id, "", defaultEntries...)
+}
+
+// writeSOPSEntries writes a SOPS-format YAML file whose data key is wrapped
+// for id, holding entries under key "pg", with a valid MAC. The meta lines
+// are appended to the file's SOPS metadata.
+func writeSOPSEntries(t *testing.T, id *age.X25519Identity, meta string, entries ...sopsEntry) string {
+	t.Helper()
+	dataKey := make([]byte, 32)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	aw := armor.NewWriter(buf)
+	w, err := age.Encrypt(aw, id.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write(dataKey)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, aw.Close())
+
+	const lastModified = "2026-10-01T00:00:00Z"
+	mac := sha512.New()
+	var sb strings.Builder
+	sb.WriteString("pg:\n")
+	for _, e := range entries {
+		mac.Write([]byte(e.plain))
+		if e.unencrypted {
+			fmt.Fprintf(&sb, "    %s: %s\n", e.key, e.plain)
+			continue
+		}
+		aadKey := e.key
+		if e.aadKey != "" {
+			aadKey = e.aadKey
+		}
+		fmt.Fprintf(&sb, "    %s: %s\n", e.key, encryptValue(t, dataKey, e.plain, e.typ, "pg", aadKey))
+	}
+	sb.WriteString("sops:\n    age:\n        - recipient: " + id.Recipient().String() + "\n          enc: |\n")
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		sb.WriteString("            " + line + "\n")
+	}
+	sb.WriteString("    lastmodified: \"" + lastModified + "\"\n")
+	sb.WriteString("    mac: " + encryptAAD(t, dataKey, fmt.Sprintf("%X", mac.Sum(nil)), "str", lastModified) + "\n")
+	sb.WriteString(meta)
+	sb.WriteString("    version: 3.9.0\n")
+
+	fp := filepath.Join(t.TempDir(), "secrets.enc.yaml")
+	require.NoError(t, os.WriteFile(fp, []byte(sb.String()), 0o600))
+	return fp
+}
+
+func TestResolver_SOPSFile(t *testing.T) {
+	id := setupKey(t)
+	fp := writeSOPSFile(t, id)
+	r := sops.NewResolver()
+	ctx := context.Background()
+
+	testCases := []struct {
+		key  string
+		want string
+	}{
+		{"pg.password", "hunter2"},
+		{"pg.port", "5432"},
+		{"pg.ssl", "true"},
+		{"pg.host_unencrypted", "db.acme.com"},
+	}
+	for _, tc := range testCases {
+		got, err := r.Resolve(ctx, fp+"#"+tc.key)
+		require.NoError(t, err, tc.key)
+		require.Equal(t, tc.want, got, tc.key)
+	}
+
+	_, err := r.Resolve(ctx, fp+"#pg.nope")
+	require.ErrorIs(t, err, secret.ErrNotFound)
+
+	_, err = r.Resolve(ctx, fp+"#pg")
+	require.Error(t, err, "a map is not a scalar")
+}
+
+func TestResolver_SOPSFile_Tampered(t *testing.T) {
+	id := setupKey(t)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name    string
+		entries []sopsEntry
+		meta    string
+		wantErr string
+	}{
+		{
+			// A value whose ciphertext was moved from another key must not decrypt.
+			name:    "moved",
+			entries: append(slices.Clone(defaultEntries), sopsEntry{key: "moved", plain: "hunter2", typ: "str", aadKey: "password"}),
+			wantErr: "decrypt value",
+		},
+		{
+			name:    "plaintext",
+			entries: append(slices.Clone(defaultEntries), sopsEntry{key: "user", plain: "mallory", unencrypted: true}),
+			wantErr: "not encrypted",
+		},
+		{
+			name:    "plaintext_bool",
+			entries: append(slices.Clone(defaultEntries), sopsEntry{key: "debug", plain: "true", unencrypted: true}),
+			wantErr: "not encrypted",
+		},
+		{
+			// The default "_unencrypted" suffix doesn't apply when the
+			// file sets its own rules.
+			name:    "regex_overrides_default_suffix",
+			entries: defaultEntries,
+			meta:    "    unencrypted_regex: ^user$\n",
+			wantErr: "not encrypted",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fp := writeSOPSEntries(t, id, tc.meta, tc.entries...)
+			_, err := sops.NewResolver().Resolve(ctx, fp+"#pg.password")
+			require.Error(t, err)
+			require.NotErrorIs(t, err, secret.ErrNotFound)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+
+	t.Run("mac_mismatch", func(t *testing.T) {
+		fp := writeSOPSFile(t, id)
+		data, err := os.ReadFile(fp)
+		require.NoError(t, err)
+		data = bytes.Replace(data, []byte("db.acme.com"), []byte("evil.acme.com"), 1)
+		require.NoError(t, os.WriteFile(fp, data, 0o600))
+
+		_, err = sops.NewResolver().Resolve(ctx, fp+"#pg.password")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "MAC mismatch")
+	})
+}
+
+func TestResolver_SOPSFile_UnencryptedRegex(t *testing.T) {
+	id := setupKey(t)
+	entries := append(slices.Clone(defaultEntries[:3]), sopsEntry{key: "host", plain: "db.acme.com", unencrypted: true})
+	fp := writeSOPSEntries(t, id, "    unencrypted_regex: ^host$\n", entries...)
+
+	r := sops.NewResolver()
+	got, err := r.Resolve(context.Background(), fp+"#pg.host")
+	require.NoError(t, err)
+	require.Equal(t, "db.acme.com", got)
+	got, err = r.Resolve(context.Background(), fp+"#pg.password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+}
+
+func TestResolver_SOPSFile_WrongKey(t *testing.T) {
+	id := setupKey(t)
+	fp := writeSOPSFile(t, id)
+	setupKey(t) // Replace the key with one that can't decrypt fp.
+
+	_, err := sops.NewResolver().Resolve(context.Background(), fp+"#pg.password")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestAgeFile_RoundTrip(t *testing.T) {
+	id := setupKey(t)
+	fp := filepath.Join(t.TempDir(), "secrets.yaml.age")
+
+	doc, err := sops.ReadFile(fp)
+	require.NoError(t, err)
+	require.Empty(t, doc, "a missing file reads as an empty document")
+
+	require.NoError(t, sops.SetValue(doc, "sakila.pg.dsn", "postgres://alice:pa$$word@db/sakila"))
+	require.NoError(t, sops.SetValue(doc, "other", "x"))
+	rcps, err := sops.LoadRecipients(nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{id.Recipient().String()}, []string{rcps[0].(*age.X25519Recipient).String()})
+	require.NoError(t, sops.WriteFile(fp, doc, rcps))
+
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte(armor.Header)))
+	require.NotContains(t, string(data), "alice")
+
+	got, err := sops.NewResolver().Resolve(context.Background(), fp+"#sakila.pg.dsn")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://alice:pa$$word@db/sakila", got)
+
+	doc, err = sops.ReadFile(fp)
+	require.NoError(t, err)
+	require.NoError(t, sops.SetValue(doc, "sakila.pg.user", "alice"))
+	require.NoError(t, sops.WriteFile(fp, doc, rcps))
+
+	r := sops.NewResolver()
+	got, err = r.Resolve(context.Background(), fp+"#sakila.pg.user")
+	require.NoError(t, err)
+	require.Equal(t, "alice", got)
+	got, err = r.Resolve(context.Background(), fp+"#other")
+	require.NoError(t, err)
+	require.Equal(t, "x", got)
+}
+
+func TestReadFile_RejectsSOPSFile(t *testing.T) {
+	id := setupKey(t)
+	_, err := sops.ReadFile(writeSOPSFile(t, id))
+	require.Error(t, err)
+}
+
+func TestResolver_KeyFile(t *testing.T) {
+	id := setupKey(t)
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(keyFile, []byte("# created: now\n"+id.String()+"\n"), 0o600))
+	t.Setenv(sops.EnvAgeKey, "")
+	t.Setenv(sops.EnvAgeKeyFile, keyFile)
+
+	fp := writeSOPSFile(t, id)
+	got, err := sops.NewResolver().Resolve(context.Background(), fp+"#pg.password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+}
+
+func TestResolver_Errors(t *testing.T) {
+	setupKey(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	_, err := sops.NewResolver().Resolve(ctx, filepath.Join(dir, "nope.yaml")+"#a")
+	require.ErrorIs(t, err, secret.ErrNotFound)
+
+	for _, path := range []string{
+		"secrets.yaml#a",                 // relative
+		filepath.Join(dir, "x.yaml"),     // no key
+		filepath.Join(dir, "x.yaml#"),    // empty key
+		filepath.Join(dir, "x.yaml#a.."), // empty key segment
+	} {
+		_, err = sops.NewResolver().Resolve(ctx, path)
+		require.Error(t, err, path)
+		require.NotErrorIs(t, err, secret.ErrNotFound, path)
+	}
+
+	plain := filepath.Join(dir, "plain.yaml")
+	require.NoError(t, os.WriteFile(plain, []byte("a: b\n"), 0o600))
+	_, err = sops.NewResolver().Resolve(ctx, plain+"#a")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "neither a SOPS file nor age-encrypted")
+}
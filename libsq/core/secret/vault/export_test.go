@@ -0,0 +1,11 @@
+package vault
+
+import "time"
+
+// This file exposes internals to the vault_test package so that lease
+// expiry can be driven without waiting on the wall clock.
+
+// SetNow replaces the Resolver's clock.
+func (r *Resolver) SetNow(fn func() time.Time) {
+	r.now = fn
+}
@@ -0,0 +1,558 @@
+// Package vault is the HashiCorp Vault backend for libsq/core/secret. A
+// ${vault:<mount>/<path>#<field>} placeholder resolves to the named field
+// of the secret at that path, read via Vault's HTTP API. For example,
+// ${vault:secret/sakila/db#password} reads field "password" of the KV
+// secret "sakila/db" on the "secret/" mount.
+//
+// The mount's engine is discovered at first use via the same preflight
+// request the vault CLI makes. KV v2 paths are rewritten to the engine's
+// "data/" API path; KV v1 and every other engine (e.g. the database
+// secrets engine's "database/creds/<role>") are read verbatim.
+//
+// Configuration comes from the standard Vault environment variables:
+// VAULT_ADDR, VAULT_NAMESPACE, VAULT_CACERT and VAULT_SKIP_VERIFY. The
+// client token is taken from the first of these that is available:
+//
+//   - VAULT_TOKEN.
+//   - AppRole login, when VAULT_ROLE_ID and VAULT_SECRET_ID are set.
+//   - Kubernetes login, when VAULT_K8S_ROLE is set. The service account
+//     JWT is read from VAULT_K8S_TOKEN_PATH, which defaults to the
+//     in-cluster service account token file.
+//   - The ~/.vault-token file written by "vault login".
+//
+// VAULT_AUTH_MOUNT overrides the default "approle" or "kubernetes" auth
+// mount path. Read-only: sq never writes to Vault.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/httpz"
+	"github.com/neilotoole/sq/libsq/core/secret"
+)
+
+// Environment variables consulted by the Resolver.
+const (
+	EnvAddr         = "VAULT_ADDR"
+	EnvToken        = "VAULT_TOKEN"
+	EnvNamespace    = "VAULT_NAMESPACE"
+	EnvCACert       = "VAULT_CACERT"
+	EnvSkipVerify   = "VAULT_SKIP_VERIFY"
+	EnvRoleID       = "VAULT_ROLE_ID"
+	EnvSecretID     = "VAULT_SECRET_ID"
+	EnvK8sRole      = "VAULT_K8S_ROLE"
+	EnvK8sTokenPath = "VAULT_K8S_TOKEN_PATH"
+	EnvAuthMount    = "VAULT_AUTH_MOUNT"
+)
+
+// defaultK8sTokenPath is where Kubernetes mounts the pod's service account
+// token.
+const defaultK8sTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec // not a credential
+
+// renewWindow is how far ahead of a lease's expiry the Resolver renews it
+// (or, if not renewable, re-reads the secret). It leaves a connection that
+// is about to be opened enough time to authenticate with the credentials.
+const renewWindow = 30 * time.Second
+
+// Resolver implements secret.Resolver against the Vault HTTP API. A single
+// Resolver caches secrets for its lifetime, keyed by secret path (not by
+// field), so that ${vault:database/creds/ro#username} and
+// ${vault:database/creds/ro#password} come from the same lease. Concurrent
+// reads of the same secret path are coalesced via singleflight, as with
+// op.Resolver.
+//
+// Leased secrets (dynamic credentials) are renewed when they are within
+// renewWindow of expiry, or re-read when the lease is not renewable or has
+// reached its max TTL. Resolver implements secret.Expirer so that the
+// Registry passes every resolution of a leased secret through to it.
+type Resolver struct {
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	client *http.Client
+	flight singleflight.Group
+	cache  sync.Map // secret path -> *lease
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time // zero means the token doesn't expire
+	mounts      map[string]mount
+}
+
+// NewResolver returns a Resolver. Callers register the result with a
+// secret.Registry under the "vault" scheme. No environment is consulted
+// until the first Resolve call.
+func NewResolver() *Resolver {
+	return &Resolver{now: time.Now, mounts: map[string]mount{}}
+}
+
+// lease is a cached secret read, with its lease metadata if any.
+type lease struct {
+	expires   time.Time // zero for non-leased (e.g. KV) secrets
+	data      map[string]any
+	id        string
+	renewable bool
+}
+
+// mount describes the secrets engine that serves a path.
+type mount struct {
+	path      string // e.g. "secret/"
+	kvVersion int    // 2 for KV v2, else 0
+}
+
+// Expires implements secret.Expirer. It reports whether the secret behind
+// the placeholder path carries a lease.
+func (r *Resolver) Expires(path string) bool {
+	secretPath, _, err := parsePath(path)
+	if err != nil {
+		return false
+	}
+	v, ok := r.cache.Load(secretPath)
+	return ok && !v.(*lease).expires.IsZero()
+}
+
+// Resolve returns field of the secret at the placeholder path, which has
+// the form "<mount>/<path>#<field>". Returns secret.ErrNotFound when the
+// secret or the field does not exist. A non-string field value is
+// returned as its JSON encoding.
+func (r *Resolver) Resolve(ctx context.Context, path string) (string, error) {
+	secretPath, field, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	l, err := r.getLease(ctx, secretPath)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := l.data[field]
+	if !ok || v == nil {
+		return "", secret.ErrNotFound
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", errz.Err(err)
+	}
+	return string(b), nil
+}
+
+// parsePath splits a placeholder path into secret path and field.
+func parsePath(path string) (secretPath, field string, err error) {
+	i := strings.LastIndexByte(path, '#')
+	if i < 0 {
+		return "", "", errz.Errorf("vault: missing #field in %q: expected <mount>/<path>#<field>", path)
+	}
+	secretPath, field = strings.Trim(path[:i], "/"), path[i+1:]
+	if secretPath == "" || field == "" {
+		return "", "", errz.Errorf("vault: invalid path %q: expected <mount>/<path>#<field>", path)
+	}
+	return secretPath, field, nil
+}
+
+// getLease returns the cached lease for secretPath, renewing or re-reading
+// it if it is about to expire.
+func (r *Resolver) getLease(ctx context.Context, secretPath string) (*lease, error) {
+	if v, ok := r.cache.Load(secretPath); ok {
+		l := v.(*lease)
+		if l.expires.IsZero() || r.now().Add(renewWindow).Before(l.expires) {
+			return l, nil
+		}
+	}
+
+	// DoChan (not Do) so each caller can honor its own ctx while waiting,
+	// mirroring op.Resolver.
+	ch := r.flight.DoChan(secretPath, func() (any, error) {
+		if v, ok := r.cache.Load(secretPath); ok {
+			l := v.(*lease)
+			if l.expires.IsZero() || r.now().Add(renewWindow).Before(l.expires) {
+				return l, nil
+			}
+			if l.renewable {
+				if renewed, err := r.renew(ctx, l); err == nil {
+					r.cache.Store(secretPath, renewed)
+					return renewed, nil
+				}
+				// Renewal failed (e.g. max TTL reached, or lease revoked):
+				// fall through and read a fresh secret.
+			}
+		}
+
+		l, err := r.read(ctx, secretPath)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.Store(secretPath, l)
+		return l, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*lease), nil
+	case <-ctx.Done():
+		return nil, errz.Wrapf(ctx.Err(), "vault read %s", secretPath)
+	}
+}
+
+// read fetches the secret at secretPath.
+func (r *Resolver) read(ctx context.Context, secretPath string) (*lease, error) {
+	m, err := r.lookupMount(ctx, secretPath)
+	if err != nil {
+		return nil, err
+	}
+
+	apiPath := secretPath
+	if m.kvVersion == 2 {
+		apiPath = m.path + "data/" + strings.TrimPrefix(secretPath, m.path)
+	}
+
+	var resp secretResponse
+	if err = r.do(ctx, http.MethodGet, apiPath, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		return nil, secret.ErrNotFound
+	}
+
+	l := &lease{data: resp.Data, id: resp.LeaseID, renewable: resp.Renewable}
+	if m.kvVersion == 2 {
+		data, _ := resp.Data["data"].(map[string]any)
+		if data == nil {
+			// A deleted or destroyed KV v2 version has null data.
+			return nil, secret.ErrNotFound
+		}
+		l.data = data
+	}
+	if resp.LeaseID != "" && resp.LeaseDuration > 0 {
+		l.expires = r.now().Add(time.Duration(resp.LeaseDuration) * time.Second)
+	}
+	return l, nil
+}
+
+// renew extends l via the sys/leases/renew endpoint, returning the
+// renewed lease. The secret data of a renewed lease is unchanged.
+func (r *Resolver) renew(ctx context.Context, l *lease) (*lease, error) {
+	body := map[string]any{"lease_id": l.id}
+	var resp secretResponse
+	if err := r.do(ctx, http.MethodPut, "sys/leases/renew", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.LeaseDuration <= 0 {
+		return nil, errz.Errorf("vault: lease %s not renewed", l.id)
+	}
+	return &lease{
+		data:      l.data,
+		id:        l.id,
+		renewable: resp.Renewable,
+		expires:   r.now().Add(time.Duration(resp.LeaseDuration) * time.Second),
+	}, nil
+}
+
+// lookupMount returns the mount serving secretPath, using the vault CLI's
+// preflight endpoint. If the preflight is not permitted or not supported,
+// the path is treated as a plain (non-KV v2) read, as the CLI does.
+func (r *Resolver) lookupMount(ctx context.Context, secretPath string) (mount, error) {
+	r.mu.Lock()
+	for prefix, m := range r.mounts {
+		if strings.HasPrefix(secretPath, prefix) {
+			r.mu.Unlock()
+			return m, nil
+		}
+	}
+	r.mu.Unlock()
+
+	var resp struct {
+		Data struct {
+			Path    string            `json:"path"`
+			Type    string            `json:"type"`
+			Options map[string]string `json:"options"`
+		} `json:"data"`
+	}
+	err := r.do(ctx, http.MethodGet, "sys/internal/ui/mounts/"+secretPath, nil, &resp)
+	switch {
+	case err == nil:
+	case errors.Is(err, secret.ErrNotFound), errors.Is(err, errForbidden):
+		return mount{}, nil
+	default:
+		return mount{}, err
+	}
+
+	m := mount{path: resp.Data.Path}
+	if resp.Data.Type == "kv" && resp.Data.Options["version"] == "2" {
+		m.kvVersion = 2
+	}
+	if m.path != "" {
+		r.mu.Lock()
+		r.mounts[m.path] = m
+		r.mu.Unlock()
+	}
+	return m, nil
+}
+
+// errForbidden is returned by Resolver.do for an HTTP 403 response.
+var errForbidden = errors.New("permission denied")
+
+// secretResponse is the common envelope of Vault API responses.
+type secretResponse struct {
+	Data          map[string]any `json:"data"`
+	Auth          *authResponse  `json:"auth"`
+	LeaseID       string         `json:"lease_id"`
+	Errors        []string       `json:"errors"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+}
+
+type authResponse struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+}
+
+// do performs an authenticated request against apiPath (relative to
+// "/v1/"), JSON-encoding body if non-nil and decoding the response into
+// dest. HTTP 404 maps to secret.ErrNotFound, and 403 to errForbidden.
+func (r *Resolver) do(ctx context.Context, method, apiPath string, body, dest any) error {
+	// Check the client config first, so that a missing VAULT_ADDR is
+	// reported as such, rather than as a login failure.
+	if _, _, err := r.getClient(); err != nil {
+		return err
+	}
+	token, err := r.getToken(ctx)
+	if err != nil {
+		return err
+	}
+	return r.request(ctx, method, apiPath, token, body, dest)
+}
+
+// request performs a request against apiPath with the given token, which
+// may be empty (as for a login request).
+func (r *Resolver) request(ctx context.Context, method, apiPath, token string, body, dest any) error {
+	client, addr, err := r.getClient()
+	if err != nil {
+		return err
+	}
+
+	var rdr io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errz.Err(err)
+		}
+		rdr = bytes.NewReader(b)
+	}
+
+	u := strings.TrimRight(addr, "/") + "/v1/" + (&url.URL{Path: apiPath}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, method, u, rdr)
+	if err != nil {
+		return errz.Err(err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if ns := os.Getenv(EnvNamespace); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errz.Wrapf(err, "vault %s %s", method, apiPath)
+	}
+	defer resp.Body.Close()
+
+	var sr secretResponse
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errz.Wrapf(err, "vault %s %s", method, apiPath)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound:
+		// Bare sentinel, matching the other resolvers; expand.go adds
+		// "resolve ${vault:<path>}" context at the outer layer.
+		return secret.ErrNotFound
+	default:
+		_ = json.Unmarshal(raw, &sr)
+		msg := strings.Join(sr.Errors, "; ")
+		if msg == "" {
+			msg = httpz.StatusText(resp.StatusCode)
+		}
+		if resp.StatusCode == http.StatusForbidden {
+			return errz.Wrapf(errForbidden, "vault %s %s: %s", method, apiPath, msg)
+		}
+		return errz.Errorf("vault %s %s: %s", method, apiPath, msg)
+	}
+
+	if dest == nil || len(raw) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(raw, dest); err != nil {
+		return errz.Wrapf(err, "vault %s %s: decode response", method, apiPath)
+	}
+	return nil
+}
+
+// getClient returns the HTTP client and the Vault address, constructing
+// the client on first use from the TLS environment variables.
+func (r *Resolver) getClient() (*http.Client, string, error) {
+	addr := os.Getenv(EnvAddr)
+	if addr == "" {
+		return nil, "", errz.Errorf("vault: %s is not set", EnvAddr)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil {
+		return r.client, addr, nil
+	}
+
+	var skipVerify bool
+	if v := os.Getenv(EnvSkipVerify); v != "" {
+		var err error
+		if skipVerify, err = strconv.ParseBool(v); err != nil {
+			return nil, "", errz.Wrapf(err, "vault: invalid %s", EnvSkipVerify)
+		}
+	}
+
+	var pool *x509.CertPool
+	if caFile := os.Getenv(EnvCACert); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, "", errz.Wrapf(err, "vault: read %s", EnvCACert)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, "", errz.Errorf("vault: no certificates found in %s file %s", EnvCACert, caFile)
+		}
+	}
+
+	r.client = httpz.NewClient(
+		httpz.OptInsecureSkipVerify(skipVerify),
+		httpz.OptRootCAs{Pool: pool},
+		httpz.DefaultUserAgent,
+		httpz.DefaultHeaderTimeout,
+	)
+	return r.client, addr, nil
+}
+
+// getToken returns a client token, logging in if necessary. A token
+// obtained via login is cached until shortly before it expires.
+func (r *Resolver) getToken(ctx context.Context) (string, error) {
+	if token := os.Getenv(EnvToken); token != "" {
+		return token, nil
+	}
+
+	r.mu.Lock()
+	token, expiry := r.token, r.tokenExpiry
+	r.mu.Unlock()
+	if token != "" && (expiry.IsZero() || r.now().Add(renewWindow).Before(expiry)) {
+		return token, nil
+	}
+
+	auth, err := r.login(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.token = auth.ClientToken
+	r.tokenExpiry = time.Time{}
+	if auth.LeaseDuration > 0 {
+		r.tokenExpiry = r.now().Add(time.Duration(auth.LeaseDuration) * time.Second)
+	}
+	return r.token, nil
+}
+
+// login obtains a token via AppRole or Kubernetes auth, falling back to
+// the vault CLI's ~/.vault-token file.
+func (r *Resolver) login(ctx context.Context) (*authResponse, error) {
+	var authPath string
+	var body map[string]any
+
+	roleID, secretID, k8sRole := os.Getenv(EnvRoleID), os.Getenv(EnvSecretID), os.Getenv(EnvK8sRole)
+	switch {
+	case roleID != "" && secretID != "":
+		authPath = authMount("approle")
+		body = map[string]any{"role_id": roleID, "secret_id": secretID}
+	case k8sRole != "":
+		jwtPath := os.Getenv(EnvK8sTokenPath)
+		if jwtPath == "" {
+			jwtPath = defaultK8sTokenPath
+		}
+		jwt, err := os.ReadFile(jwtPath)
+		if err != nil {
+			return nil, errz.Wrap(err, "vault: read kubernetes service account token")
+		}
+		authPath = authMount("kubernetes")
+		body = map[string]any{"role": k8sRole, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		token, err := readTokenFile()
+		if err != nil {
+			return nil, err
+		}
+		return &authResponse{ClientToken: token}, nil
+	}
+
+	var resp secretResponse
+	if err := r.request(ctx, http.MethodPost, "auth/"+authPath+"/login", "", body, &resp); err != nil {
+		if errors.Is(err, secret.ErrNotFound) {
+			// The secret isn't what's missing; the auth mount is.
+			return nil, errz.Errorf("vault: auth method not found at auth/%s", authPath)
+		}
+		return nil, errz.Wrap(err, "vault: login")
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return nil, errz.New("vault: login returned no client token")
+	}
+	return resp.Auth, nil
+}
+
+// authMount returns the auth mount path from EnvAuthMount, or def.
+func authMount(def string) string {
+	if v := strings.Trim(os.Getenv(EnvAuthMount), "/"); v != "" {
+		return v
+	}
+	return def
+}
+
+// readTokenFile returns the token in ~/.vault-token.
+func readTokenFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errz.Wrap(err, "vault: no credentials")
+	}
+	b, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", errz.Errorf("vault: no credentials: set %s, %s and %s, or %s, or run 'vault login'",
+				EnvToken, EnvRoleID, EnvSecretID, EnvK8sRole)
+		}
+		return "", errz.Wrap(err, "vault: read token file")
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,353 @@
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/core/secret/vault"
+)
+
+// devVault is an in-process stand-in for a dev-mode Vault server. It
+// serves a KV v2 mount at "secret/", a KV v1 mount at "kv/", and a
+// database secrets engine at "database/", plus AppRole and Kubernetes
+// login endpoints. It records the requests it receives.
+type devVault struct {
+	t        *testing.T
+	kv2      map[string]map[string]any
+	kv1      map[string]map[string]any
+	requests []string
+	token    string
+	dbSerial int
+	renewals int
+	mu       sync.Mutex
+}
+
+func newDevVault(t *testing.T) *devVault {
+	t.Helper()
+	dv := &devVault{
+		t:     t,
+		token: "root-token",
+		kv2: map[string]map[string]any{
+			"sakila/db": {"password": "hunter2", "port": 5432},
+		},
+		kv1: map[string]map[string]any{
+			"legacy": {"dsn": "postgres://alice:pw@db/sakila"},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(dv.serve))
+	t.Cleanup(srv.Close)
+	t.Setenv(vault.EnvAddr, srv.URL)
+	for _, k := range []string{
+		vault.EnvToken, vault.EnvNamespace, vault.EnvCACert, vault.EnvSkipVerify,
+		vault.EnvRoleID, vault.EnvSecretID, vault.EnvK8sRole, vault.EnvK8sTokenPath,
+		vault.EnvAuthMount,
+	} {
+		t.Setenv(k, "")
+	}
+	// Isolate from any real ~/.vault-token.
+	t.Setenv("HOME", t.TempDir())
+	return dv
+}
+
+func (dv *devVault) count(prefix string) int {
+	dv.mu.Lock()
+	defer dv.mu.Unlock()
+	var n int
+	for _, r := range dv.requests {
+		if strings.HasPrefix(r, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (dv *devVault) serve(w http.ResponseWriter, r *http.Request) {
+	dv.mu.Lock()
+	defer dv.mu.Unlock()
+	p := strings.TrimPrefix(r.URL.Path, "/v1/")
+	dv.requests = append(dv.requests, r.Method+" "+p)
+
+	reply := func(code int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		require.NoError(dv.t, json.NewEncoder(w).Encode(v))
+	}
+	var body map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case p == "auth/approle/login":
+		if body["role_id"] != "role" || body["secret_id"] != "sekrit" {
+			reply(http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token": dv.token, "lease_duration": 3600,
+		}})
+		return
+	case p == "auth/kubernetes/login":
+		if body["role"] != "sq" || body["jwt"] != "k8s-jwt" {
+			reply(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token": dv.token, "lease_duration": 3600,
+		}})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != dv.token {
+		reply(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(p, "sys/internal/ui/mounts/"):
+		rest := strings.TrimPrefix(p, "sys/internal/ui/mounts/")
+		switch {
+		case strings.HasPrefix(rest, "secret/"):
+			reply(http.StatusOK, map[string]any{"data": map[string]any{
+				"path": "secret/", "type": "kv", "options": map[string]string{"version": "2"},
+			}})
+		case strings.HasPrefix(rest, "kv/"):
+			reply(http.StatusOK, map[string]any{"data": map[string]any{
+				"path": "kv/", "type": "kv", "options": map[string]string{"version": "1"},
+			}})
+		case strings.HasPrefix(rest, "database/"):
+			reply(http.StatusOK, map[string]any{"data": map[string]any{
+				"path": "database/", "type": "database",
+			}})
+		default:
+			reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+		}
+	case strings.HasPrefix(p, "secret/data/"):
+		data, ok := dv.kv2[strings.TrimPrefix(p, "secret/data/")]
+		if !ok {
+			reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"data": map[string]any{
+			"data": data, "metadata": map[string]any{"version": 1},
+		}})
+	case strings.HasPrefix(p, "kv/"):
+		data, ok := dv.kv1[strings.TrimPrefix(p, "kv/")]
+		if !ok {
+			reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+			return
+		}
+		reply(http.StatusOK, map[string]any{"data": data, "lease_duration": 2764800})
+	case p == "database/creds/readonly":
+		dv.dbSerial++
+		reply(http.StatusOK, map[string]any{
+			"lease_id":       "database/creds/readonly/lease" + string(rune('0'+dv.dbSerial)),
+			"lease_duration": 60,
+			"renewable":      true,
+			"data": map[string]any{
+				"username": "v-readonly-" + string(rune('0'+dv.dbSerial)),
+				"password": "pw-" + string(rune('0'+dv.dbSerial)),
+			},
+		})
+	case p == "sys/leases/renew":
+		dv.renewals++
+		reply(http.StatusOK, map[string]any{
+			"lease_id": body["lease_id"], "lease_duration": 60, "renewable": dv.renewals < 2,
+		})
+	default:
+		reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+	}
+}
+
+func TestResolver_KVv2(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvToken, dv.token)
+
+	r := vault.NewResolver()
+	got, err := r.Resolve(context.Background(), "secret/sakila/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+
+	// Non-string values are JSON-encoded.
+	got, err = r.Resolve(context.Background(), "secret/sakila/db#port")
+	require.NoError(t, err)
+	require.Equal(t, "5432", got)
+
+	// Both fields came from a single read, and KV secrets aren't leased.
+	require.Equal(t, 1, dv.count("GET secret/data/sakila/db"))
+	require.False(t, r.Expires("secret/sakila/db#password"))
+}
+
+func TestResolver_KVv1(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvToken, dv.token)
+
+	got, err := vault.NewResolver().Resolve(context.Background(), "kv/legacy#dsn")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://alice:pw@db/sakila", got)
+}
+
+func TestResolver_NotFound(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvToken, dv.token)
+
+	r := vault.NewResolver()
+	_, err := r.Resolve(context.Background(), "secret/nope#password")
+	require.ErrorIs(t, err, secret.ErrNotFound)
+
+	_, err = r.Resolve(context.Background(), "secret/sakila/db#nope")
+	require.ErrorIs(t, err, secret.ErrNotFound)
+}
+
+func TestResolver_InvalidPath(t *testing.T) {
+	newDevVault(t)
+	for _, path := range []string{"secret/sakila/db", "secret/sakila/db#", "#password"} {
+		_, err := vault.NewResolver().Resolve(context.Background(), path)
+		require.Error(t, err, path)
+		require.NotErrorIs(t, err, secret.ErrNotFound, path)
+	}
+}
+
+func TestResolver_PermissionDenied(t *testing.T) {
+	newDevVault(t)
+	t.Setenv(vault.EnvToken, "wrong-token")
+
+	_, err := vault.NewResolver().Resolve(context.Background(), "secret/sakila/db#password")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, secret.ErrNotFound)
+	require.Contains(t, err.Error(), "permission denied")
+}
+
+func TestResolver_NoAddr(t *testing.T) {
+	newDevVault(t)
+	t.Setenv(vault.EnvAddr, "")
+	_, err := vault.NewResolver().Resolve(context.Background(), "secret/sakila/db#password")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), vault.EnvAddr)
+}
+
+func TestResolver_AppRole(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvRoleID, "role")
+	t.Setenv(vault.EnvSecretID, "sekrit")
+
+	r := vault.NewResolver()
+	got, err := r.Resolve(context.Background(), "secret/sakila/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+
+	_, err = r.Resolve(context.Background(), "kv/legacy#dsn")
+	require.NoError(t, err)
+	require.Equal(t, 1, dv.count("POST auth/approle/login"), "login token should be reused")
+}
+
+func TestResolver_Kubernetes(t *testing.T) {
+	dv := newDevVault(t)
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwtFile, []byte("k8s-jwt\n"), 0o600))
+	t.Setenv(vault.EnvK8sRole, "sq")
+	t.Setenv(vault.EnvK8sTokenPath, jwtFile)
+
+	got, err := vault.NewResolver().Resolve(context.Background(), "secret/sakila/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+	require.Equal(t, 1, dv.count("POST auth/kubernetes/login"))
+}
+
+func TestResolver_TokenFile(t *testing.T) {
+	dv := newDevVault(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".vault-token"), []byte(dv.token+"\n"), 0o600))
+
+	got, err := vault.NewResolver().Resolve(context.Background(), "secret/sakila/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", got)
+}
+
+func TestResolver_NoCredentials(t *testing.T) {
+	newDevVault(t)
+	_, err := vault.NewResolver().Resolve(context.Background(), "secret/sakila/db#password")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no credentials")
+}
+
+// TestResolver_DynamicLease verifies that the username and password of a
+// dynamic database credential come from the same lease, that the lease is
+// renewed as it nears expiry, and that a fresh credential is read once the
+// lease can no longer be renewed.
+func TestResolver_DynamicLease(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvToken, dv.token)
+
+	now := time.Now()
+	r := vault.NewResolver()
+	r.SetNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	user, err := r.Resolve(ctx, "database/creds/readonly#username")
+	require.NoError(t, err)
+	pw, err := r.Resolve(ctx, "database/creds/readonly#password")
+	require.NoError(t, err)
+	require.Equal(t, "v-readonly-1", user)
+	require.Equal(t, "pw-1", pw)
+	require.Equal(t, 1, dv.count("GET database/creds/readonly"))
+	require.True(t, r.Expires("database/creds/readonly#password"))
+
+	// Near expiry: the lease is renewed, and the credential is unchanged.
+	now = now.Add(45 * time.Second)
+	pw, err = r.Resolve(ctx, "database/creds/readonly#password")
+	require.NoError(t, err)
+	require.Equal(t, "pw-1", pw)
+	require.Equal(t, 1, dv.count("PUT sys/leases/renew"))
+	require.Equal(t, 1, dv.count("GET database/creds/readonly"))
+
+	// The stand-in marks the second renewal as the last, so the next
+	// resolution near expiry renews once more, and after that a fresh
+	// credential is read.
+	now = now.Add(45 * time.Second)
+	_, err = r.Resolve(ctx, "database/creds/readonly#password")
+	require.NoError(t, err)
+	require.Equal(t, 2, dv.count("PUT sys/leases/renew"))
+
+	now = now.Add(45 * time.Second)
+	pw, err = r.Resolve(ctx, "database/creds/readonly#password")
+	require.NoError(t, err)
+	require.Equal(t, "pw-2", pw)
+	require.Equal(t, 2, dv.count("GET database/creds/readonly"))
+}
+
+// TestRegistry_PassesLeasedThrough verifies the Registry integration: a
+// leased value isn't memoized by the Registry, so renewal is reachable.
+func TestRegistry_PassesLeasedThrough(t *testing.T) {
+	dv := newDevVault(t)
+	t.Setenv(vault.EnvToken, dv.token)
+
+	now := time.Now()
+	r := vault.NewResolver()
+	r.SetNow(func() time.Time { return now })
+	reg := secret.NewRegistry()
+	reg.Register("vault", r)
+	ctx := context.Background()
+
+	got, err := reg.Expand(ctx, "postgres://${vault:database/creds/readonly#username}:"+
+		"${vault:database/creds/readonly#password}@db/sakila")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://v-readonly-1:pw-1@db/sakila", got)
+
+	now = now.Add(45 * time.Second)
+	_, err = reg.Expand(ctx, "${vault:database/creds/readonly#password}")
+	require.NoError(t, err)
+	require.Equal(t, 1, dv.count("PUT sys/leases/renew"))
+}
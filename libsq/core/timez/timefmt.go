@@ -6,6 +6,8 @@ import (
 	"time"
 
 	strftime "github.com/ncruces/go-strftime"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
 )
 
 const (
@@ -144,3 +146,25 @@ func FormatFunc(layout string) func(time.Time) string {
 		return strftime.Format(layout, t)
 	}
 }
+
+// Layout returns the stdlib time layout for layout, for parsing. As with
+// FormatFunc, layout is either a named layout (per NamedLayouts, ignoring
+// case), or a strftime layout. An error is returned if layout is one of the
+// Unix named layouts, which have no stdlib equivalent, or if the strftime
+// layout can't be expressed as a stdlib layout.
+func Layout(layout string) (string, error) {
+	if f, ok := mNamedStdlibLayouts[strings.ToUpper(layout)]; ok {
+		return f, nil
+	}
+
+	switch strings.ToUpper(layout) {
+	case strings.ToUpper(unix), strings.ToUpper(unixMilli), strings.ToUpper(unixMicro), strings.ToUpper(unixNano):
+		return "", errz.Errorf("time layout {%s} can't be used for parsing", layout)
+	}
+
+	f, err := strftime.Layout(layout)
+	if err != nil {
+		return "", errz.Wrapf(err, "invalid time layout {%s}", layout)
+	}
+	return f, nil
+}
@@ -289,6 +289,33 @@ func TestFormatFunc(t *testing.T) {
 	})
 }
 
+func TestLayout(t *testing.T) {
+	testCases := []struct {
+		layout  string
+		want    string
+		wantErr bool
+	}{
+		{layout: "RFC3339", want: time.RFC3339},
+		{layout: "dateonly", want: time.DateOnly},
+		{layout: "%d/%m/%Y", want: "02/01/2006"},
+		{layout: "%Y-%m-%d %H:%M:%S", want: time.DateTime},
+		{layout: "Unix", wantErr: true},
+		{layout: "%s", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.layout, func(t *testing.T) {
+			got, err := timez.Layout(tc.layout)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
 func TestExcelLongDate(t *testing.T) {
 	s := mar1UTC.Format(timez.ExcelLongDate)
 	require.Equal(t, "Wednesday, March 1, 2023", s)
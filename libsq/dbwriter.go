@@ -13,6 +13,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/schema"
 	"github.com/neilotoole/sq/libsq/core/sqlz"
 	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/libsq/source"
 )
 
@@ -38,6 +39,12 @@ type DBWriter struct {
 	// needed to perform actions before insertion, such as creating
 	// the dest table on the fly.
 	preWriteHooks []DBWriterPreWriteHook
+
+	// maskPolicy, when non-nil, is used to mask each record before it's
+	// written. See DBWriter.SetMask.
+	maskPolicy *mask.Policy
+	masker     *mask.Masker
+	maskTbls   []string
 }
 
 // DBWriterPreWriteHook is a function that is invoked before DBWriter
@@ -97,6 +104,15 @@ func NewDBWriter(msg string, destGrip driver.Grip, destTbl string, recChSize int
 	// ctx is done, we send ctx.Err, followed by any rollback err.
 }
 
+// SetMask sets the masking policy that is applied to each record before
+// it's written. The policy's table-qualified rules are matched against
+// the dest table, and any additional tbls, such as the origin table of
+// the records. SetMask must be invoked before Open.
+func (w *DBWriter) SetMask(policy *mask.Policy, tbls ...string) {
+	w.maskPolicy = policy
+	w.maskTbls = append([]string{w.destTbl}, tbls...)
+}
+
 // Open implements RecordWriter.
 func (w *DBWriter) Open(ctx context.Context, cancelFn context.CancelFunc, recMeta record.Meta) (
 	chan<- record.Record, <-chan error, error,
@@ -122,6 +138,17 @@ func (w *DBWriter) Open(ctx context.Context, cancelFn context.CancelFunc, recMet
 		}
 	}
 
+	if w.maskPolicy != nil {
+		if w.masker, err = w.maskPolicy.Masker(recMeta, w.maskTbls...); err != nil {
+			w.rollback(ctx, tx, err)
+			return nil, nil, err
+		}
+		if w.masker != nil {
+			lg.FromContext(ctx).Debug("Masking columns", lga.Target, source.Target(w.destGrip.Source(), w.destTbl),
+				lga.Cols, w.masker.Columns())
+		}
+	}
+
 	w.bi, err = w.destGrip.SQLDriver().NewBatchInsert(
 		ctx,
 		w.msg,
@@ -242,6 +269,12 @@ func (w *DBWriter) rollback(ctx context.Context, tx *sql.Tx, causeErrs ...error)
 }
 
 func (w *DBWriter) doInsert(ctx context.Context, rec record.Record) error {
+	if w.masker != nil {
+		if err := w.masker.Mask(rec); err != nil {
+			return err
+		}
+	}
+
 	err := w.bi.Munge(rec)
 	if err != nil {
 		return err
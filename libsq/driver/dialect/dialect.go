@@ -101,6 +101,56 @@ type Dialect struct {
 	// so that the zero value (false) represents the common case where rows
 	// ARE reported, following Go idioms for sensible zero values.
 	IsRowsAffectedUnsupported bool
+
+	// Profile holds the dialect's SQL for column profiling. If Profile is
+	// the zero value, DefaultProfile is used.
+	Profile Profile
+
+	// RegexpMatch is the name of the function f(s, pattern) that reports
+	// whether s contains a match of the regular expression pattern, e.g.
+	// "regexp_matches". If empty, the dialect has no such function.
+	RegexpMatch string
+}
+
+// Profile holds the parts of the SQL used by column profiling (as in
+// "sq inspect --profile") that vary by dialect. The profiling aggregates
+// are pushed down to the database, so that profiling a large table
+// doesn't require reading its rows.
+type Profile struct {
+	// CharLength is the function that returns the length of a string in
+	// characters, e.g. "LENGTH" or "CHAR_LENGTH".
+	CharLength string
+
+	// StdDev is the sample standard deviation aggregate function, e.g.
+	// "STDDEV_SAMP". If empty, the dialect has no such function, and the
+	// standard deviation is derived from SUM(x*x) instead.
+	StdDev string
+
+	// ApproxCountDistinct is the approximate (typically HyperLogLog)
+	// count-distinct aggregate function, e.g. "APPROX_COUNT_DISTINCT". If
+	// empty, the dialect has no such function, and the exact
+	// COUNT(DISTINCT x) is used instead.
+	ApproxCountDistinct string
+
+	// Float is the SQL type that a numeric value is cast to for
+	// floating-point aggregates, such as AVG, e.g. "DOUBLE PRECISION".
+	Float string
+
+	// Limit is the format string of the clause, appended to a query after
+	// its ORDER BY clause, that limits the query to %d rows, e.g.
+	// " LIMIT %d". If empty, the query isn't limited, and reading of its
+	// rows stops after %d rows instead.
+	Limit string
+}
+
+// DefaultProfile returns the Profile for standard SQL.
+func DefaultProfile() Profile {
+	return Profile{
+		CharLength: "LENGTH",
+		StdDev:     "STDDEV_SAMP",
+		Float:      "DOUBLE PRECISION",
+		Limit:      " LIMIT %d",
+	}
 }
 
 // String returns a log/debug-friendly representation.
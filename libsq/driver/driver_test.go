@@ -482,6 +482,7 @@ var sqlDrivers = []drivertype.Type{
 var docDrivers = []drivertype.Type{
 	drivertype.CSV,
 	drivertype.TSV,
+	drivertype.FixedWidth,
 	drivertype.XLSX,
 }
 
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Explainer is an optional interface implemented by SQL drivers that can
+// report the execution plan of a query, via the database's native EXPLAIN
+// facility. The plan is normalized into a tree of [PlanNode], so that
+// plans from different databases can be rendered the same way. Mirrors
+// the optional-capability pattern of [ConnParamDetector].
+type Explainer interface {
+	// Explain returns the plan of query. The statements are executed on
+	// conn, a single connection, so that any session settings required
+	// by the database's EXPLAIN facility apply to the query.
+	//
+	// If analyze is true, the query is actually executed, and the plan
+	// reports actual row counts in addition to the planner's estimates.
+	// Drivers whose database can't do that return an error. The caller
+	// is responsible for determining that it's safe to execute query.
+	Explain(ctx context.Context, conn *sql.Conn, query string, analyze bool) (*PlanNode, error)
+}
+
+// PlanNode is a node of a query plan, normalized from the output of a
+// database's EXPLAIN facility. The numeric fields are nil if the database
+// doesn't report that value.
+type PlanNode struct {
+	// EstRows is the planner's estimate of the rows output by the node.
+	EstRows *float64 `json:"est_rows,omitempty" yaml:"est_rows,omitempty"`
+
+	// ActualRows is the actual count of rows output by the node. It is
+	// only reported when the plan is analyzed.
+	ActualRows *float64 `json:"actual_rows,omitempty" yaml:"actual_rows,omitempty"`
+
+	// Cost is the planner's estimated cost of the node, in the database's
+	// own units. Costs are not comparable across databases.
+	Cost *float64 `json:"cost,omitempty" yaml:"cost,omitempty"`
+
+	// Op is the node type, e.g. "Seq Scan" or "HASH_GROUP_BY", using the
+	// database's own terminology.
+	Op string `json:"op" yaml:"op"`
+
+	// Detail is any additional description of the node, such as the
+	// table scanned, or the index used.
+	Detail string `json:"detail,omitempty" yaml:"detail,omitempty"`
+
+	// Children are the node's inputs.
+	Children []*PlanNode `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// ParsePlanFloat is a convenience function for Explainer implementations,
+// which returns a pointer to the float value of s, or nil if s is empty
+// or not a number.
+func ParsePlanFloat(s string) *float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -45,15 +46,25 @@ type Grips struct {
 	// placeholders fails; cache hits are served before resolution runs.
 	secretReg *secret.Registry
 
+	// tunnels holds the SSH tunnels (see OptSSHTunnel) of the opened
+	// sources. They're torn down by Close, after the grips are closed.
+	tunnels *Tunnels
+
 	// grips caches open Grip instances, keyed by gripCacheKey: the source
 	// handle plus the access mode (read-write, implicit read-only, or
 	// explicit read-only) passed to Open. Keying on the mode means a
 	// source opened both read-only and read-write within one run gets two
 	// coexisting grips, each opened with the correct mode, regardless of
-	// which open happened first (gh #779). Both grips are registered on
-	// clnup, so Close releases them all.
+	// which open happened first (gh #779). Close closes each grip in the
+	// map (see closeGrips), so a grip removed by Evict isn't closed again.
 	grips map[string]Grip
 
+	// gripKeys holds the keys of gs.grips, in the order the grips were
+	// opened. Close closes the grips in reverse order, so that a grip is
+	// closed before the grips opened before it, such as a join DB before
+	// the sources copied into it.
+	gripKeys []string
+
 	clnup     *cleanup.Cleanup
 	closeOnce sync.Once
 	mu        sync.Mutex
@@ -77,15 +88,22 @@ func gripCacheKey(mode AccessMode, handle string) string {
 func NewGrips(drvrs Provider, fs *files.Files, secretReg *secret.Registry,
 	scratchSrcFn ScratchSrcFunc,
 ) *Grips {
-	return &Grips{
+	gs := &Grips{
 		drvrs:        drvrs,
 		mu:           sync.Mutex{},
 		scratchSrcFn: scratchSrcFn,
 		files:        fs,
 		secretReg:    secretReg,
+		tunnels:      NewTunnels(secretReg),
 		grips:        map[string]Grip{},
 		clnup:        cleanup.New(),
 	}
+
+	// Cleanup funcs run in reverse order, so the tunnels, added first,
+	// are closed after the grips that use them.
+	gs.clnup.AddE(gs.tunnels.Close)
+	gs.clnup.AddE(gs.closeGrips)
+	return gs
 }
 
 // Open returns an opened Grip for src. The returned Grip may be cached and
@@ -116,11 +134,43 @@ func (gs *Grips) Open(ctx context.Context, src *source.Source, mode AccessMode)
 	if err != nil {
 		return nil, err
 	}
-	gs.clnup.AddC(g)
-	gs.grips[gripCacheKey(mode, src.Handle)] = g
+	gs.cacheGrip(gripCacheKey(mode, src.Handle), g)
 	return g, nil
 }
 
+// cacheGrip adds g to gs.grips under key. It must be invoked with gs.mu
+// held.
+func (gs *Grips) cacheGrip(key string, g Grip) {
+	if _, ok := gs.grips[key]; !ok {
+		gs.gripKeys = append(gs.gripKeys, key)
+	}
+	gs.grips[key] = g
+}
+
+// Evict closes and removes from the cache any grips for the source with
+// the given handle, in every access mode, such that the next Open of that
+// source opens it afresh. For example, a document source whose file has
+// changed is re-ingested by the next Open after Evict. It is not an error
+// if there's no cached grip for handle.
+func (gs *Grips) Evict(ctx context.Context, handle string) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	var err error
+	for _, mode := range []AccessMode{ModeReadWrite, ModeReadOnly, ModeReadOnlyExplicit} {
+		key := gripCacheKey(mode, handle)
+		g, ok := gs.grips[key]
+		if !ok {
+			continue
+		}
+		delete(gs.grips, key)
+		gs.gripKeys = slices.DeleteFunc(gs.gripKeys, func(k string) bool { return k == key })
+		lg.FromContext(ctx).Debug("Evicting cached grip", lga.Handle, handle, lga.Mode, mode)
+		err = errz.Append(err, g.Close())
+	}
+	return err
+}
+
 // DriverFor returns the driver for typ.
 func (gs *Grips) DriverFor(typ drivertype.Type) (Driver, error) {
 	return gs.drvrs.DriverFor(typ)
@@ -255,6 +305,10 @@ func (gs *Grips) doOpen(ctx context.Context, src *source.Source, mode AccessMode
 	baseOptions := options.FromContext(ctx)
 	o := options.Merge(baseOptions, src.Options)
 
+	if src, err = gs.tunnels.Rewrite(ctx, src, o, drvr.DriverMetadata().DefaultPort); err != nil {
+		return nil, err
+	}
+
 	ctx = options.NewContext(ctx, o)
 	grip, err = drvr.Open(ctx, src, mode)
 	if err != nil {
@@ -308,9 +362,8 @@ func (gs *Grips) OpenEphemeral(ctx context.Context) (Grip, error) {
 		Grip:  grip,
 		clnup: clnup,
 	}
-	gs.clnup.AddC(g)
 	log.Info("Opened ephemeral db", lga.Src, g.Source())
-	gs.grips[gripCacheKey(ModeReadWrite, g.Source().Handle)] = g
+	gs.cacheGrip(gripCacheKey(ModeReadWrite, g.Source().Handle), g)
 	return g, nil
 }
 
@@ -373,6 +426,12 @@ func (gs *Grips) OpenIngest(ctx context.Context, src *source.Source, allowCache
 	}
 	defer unlock()
 
+	if files.IsMultiFile(src.Location) {
+		// The driver's ingestFn can't read a multi-file source: instead,
+		// each file is ingested individually, and the results unioned.
+		ingestFn = gs.multiFileIngestFn(src)
+	}
+
 	if !allowCache || src.Handle == source.StdinHandle {
 		// Note that we can never cache stdin, because it's a stream
 		// that is effectively unique each time.
@@ -558,11 +617,27 @@ func (gs *Grips) OpenJoin(ctx context.Context, srcs ...*source.Source) (Grip, er
 		Grip:  grip,
 		clnup: clnup,
 	}
-	gs.clnup.AddC(g)
-	gs.grips[gripCacheKey(ModeReadWrite, g.Source().Handle)] = g
+	gs.cacheGrip(gripCacheKey(ModeReadWrite, g.Source().Handle), g)
 	return g, nil
 }
 
+// closeGrips closes and removes each cached grip, in the reverse of the
+// order they were opened. It is invoked by Close, via gs.clnup.
+func (gs *Grips) closeGrips() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	var err error
+	for i := len(gs.gripKeys) - 1; i >= 0; i-- {
+		key := gs.gripKeys[i]
+		g := gs.grips[key]
+		delete(gs.grips, key)
+		err = errz.Append(err, g.Close())
+	}
+	gs.gripKeys = nil
+	return err
+}
+
 // Close closes gs, invoking any cleanup funcs.
 func (gs *Grips) Close() error {
 	gs.closeOnce.Do(func() {
@@ -29,7 +29,8 @@ type fakeDriver struct {
 	mu        sync.Mutex
 	opens     []openRecord
 	grips     []*fakeGrip
-	failOpens int // if >0, the next Open returns an error and decrements this.
+	closed    []string // handles of the closed grips, in order of closing.
+	failOpens int      // if >0, the next Open returns an error and decrements this.
 }
 
 func (d *fakeDriver) Open(_ context.Context, src *source.Source, mode driver.AccessMode) (driver.Grip, error) {
@@ -44,7 +45,7 @@ func (d *fakeDriver) Open(_ context.Context, src *source.Source, mode driver.Acc
 		readOnly: mode.IsReadOnly(),
 		explicit: mode == driver.ModeReadOnlyExplicit,
 	})
-	g := &fakeGrip{src: src}
+	g := &fakeGrip{src: src, drvr: d}
 	d.grips = append(d.grips, g)
 	return g, nil
 }
@@ -74,8 +75,9 @@ func (p *fakeProvider) DriverFor(_ drivertype.Type) (driver.Driver, error) {
 // fakeGrip is a minimal driver.Grip.
 type fakeGrip struct {
 	src    *source.Source
+	drvr   *fakeDriver
 	mu     sync.Mutex
-	closed bool
+	closes int // number of Close invocations
 }
 
 // errFakeGrip is returned by the fakeGrip methods the tests never invoke.
@@ -102,14 +104,22 @@ func (g *fakeGrip) DBSemver(_ context.Context) (string, error) {
 func (g *fakeGrip) Close() error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	g.closed = true
+	g.closes++
+
+	g.drvr.mu.Lock()
+	defer g.drvr.mu.Unlock()
+	g.drvr.closed = append(g.drvr.closed, g.src.Handle)
 	return nil
 }
 
 func (g *fakeGrip) isClosed() bool {
+	return g.closeCount() > 0
+}
+
+func (g *fakeGrip) closeCount() int {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	return g.closed
+	return g.closes
 }
 
 func newFakeGrips(reg *secret.Registry) (*driver.Grips, *fakeDriver) {
@@ -275,3 +285,52 @@ func TestGrips_Open_ErrorNotCached(t *testing.T) {
 
 	require.NoError(t, gs.Close())
 }
+
+// TestGrips_Evict verifies that Evict closes the source's cached grips, so
+// that the next Open reaches the driver again, and that Close doesn't close
+// an evicted grip a second time.
+func TestGrips_Evict(t *testing.T) {
+	gs, drvr := newFakeGrips(nil)
+	ctx := context.Background()
+	src := &source.Source{Handle: "@fake", Type: drivertype.Pg, Location: "postgres://db/sakila"}
+
+	for range 3 {
+		_, err := gs.Open(ctx, src, driver.ModeReadWrite)
+		require.NoError(t, err)
+		_, err = gs.Open(ctx, src, driver.ModeReadOnly)
+		require.NoError(t, err)
+		require.NoError(t, gs.Evict(ctx, src.Handle))
+	}
+	_, err := gs.Open(ctx, src, driver.ModeReadWrite)
+	require.NoError(t, err)
+	require.NoError(t, gs.Evict(ctx, "@not_open"))
+	require.Equal(t, 7, drvr.openCount())
+
+	require.NoError(t, gs.Close())
+	for i, g := range drvr.grips {
+		require.Equal(t, 1, g.closeCount(), "grip %d", i)
+	}
+}
+
+// TestGrips_Close_order verifies that Close closes the grips in the reverse
+// of the order they were opened, and that a grip reopened after Evict is
+// ordered per its reopening.
+func TestGrips_Close_order(t *testing.T) {
+	gs, drvr := newFakeGrips(nil)
+	ctx := context.Background()
+	for _, handle := range []string{"@a", "@b", "@c"} {
+		src := &source.Source{Handle: handle, Type: drivertype.Pg, Location: "postgres://db/" + handle[1:]}
+		_, err := gs.Open(ctx, src, driver.ModeReadWrite)
+		require.NoError(t, err)
+		_, err = gs.Open(ctx, src, driver.ModeReadWrite) // Cache hit.
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, gs.Evict(ctx, "@a"))
+	_, err := gs.Open(ctx, &source.Source{Handle: "@a", Type: drivertype.Pg, Location: "postgres://db/a"},
+		driver.ModeReadWrite)
+	require.NoError(t, err)
+
+	require.NoError(t, gs.Close())
+	require.Equal(t, []string{"@a", "@a", "@c", "@b"}, drvr.closed)
+}
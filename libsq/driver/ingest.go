@@ -47,6 +47,24 @@ ingested each time.
 	options.TagSource,
 )
 
+// OptIngestFileColumn specifies whether a multi-file source's tables get
+// a column holding the name of the file that each row was ingested from.
+var OptIngestFileColumn = options.NewBool(
+	"ingest.file-column",
+	nil,
+	false,
+	"Add _file column to multi-file sources",
+	`Specifies whether each table of a multi-file source (a directory, or a glob
+such as "./events/*.jsonl") gets an additional "_file" column, holding the
+name of the file that the row was ingested from. The name is relative to the
+directory, or to the glob's base directory.
+
+  $ sq add './events/*.jsonl' --handle @events --ingest.file-column
+  $ sq '@events.data | where(._file == "events-2026-10-01.jsonl")'`,
+	options.TagSource,
+	options.TagIngestMutate,
+)
+
 // OptIngestSampleSize specifies the number of samples that a detector
 // should take to determine ingest data type.
 var OptIngestSampleSize = options.NewInt(
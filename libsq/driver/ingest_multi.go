@@ -0,0 +1,382 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/cleanup"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// FileColumn is the name of the column that holds the name of the file that
+// a multi-file source's row was ingested from. See OptIngestFileColumn.
+const FileColumn = "_file"
+
+// multiFile is one of the files of a multi-file source, ingested into its
+// own DB.
+type multiFile struct {
+	// name is the file's path, relative to files.MultiFileBase.
+	name string
+
+	// dbPath is the path to the SQLite DB that the file was ingested into.
+	dbPath string
+
+	// tbls holds the file's tables, in the order that they were created.
+	tbls []*multiTable
+}
+
+// multiTable is a table of a multiFile, or the union of that table across
+// all files.
+type multiTable struct {
+	name string
+	cols []string
+
+	// types holds the declared type of each col.
+	types map[string]string
+}
+
+// multiFileIngestFn returns an ingest func for multi-file source src (see
+// files.IsMultiFile), which is invoked in lieu of the driver's own ingest
+// func. Each of the source's files is opened via the driver as a source of
+// its own, with the file as its location. Thus, each file is ingested into
+// its own cache DB, guarded by that file's ingest checksum: when the set of
+// files changes, only the new or changed files are ingested again. Then, the
+// same-named tables of those DBs are unioned into dest. A column that's not
+// present in all files is NULL for the rows of the files that lack it; a
+// column whose declared type differs between files is reconciled via
+// reconcileDeclType.
+func (gs *Grips) multiFileIngestFn(src *source.Source) func(ctx context.Context, dest Grip) error {
+	return func(ctx context.Context, dest Grip) error {
+		log := lg.FromContext(ctx)
+
+		if dest.Source().Type != drivertype.SQLite {
+			return errz.Errorf("multi-file source %s: ingest DB must be %s, but got %s",
+				src.Handle, drivertype.SQLite, dest.Source().Type)
+		}
+
+		paths, err := files.MultiFilePaths(src.Location)
+		if err != nil {
+			return err
+		}
+
+		drvr, err := gs.drvrs.DriverFor(src.Type)
+		if err != nil {
+			return err
+		}
+
+		destDB, err := dest.DB(ctx)
+		if err != nil {
+			return err
+		}
+
+		// ATTACH applies only to the connection that executes it,
+		// so all the work happens on a single connection.
+		conn, err := destDB.Conn(ctx)
+		if err != nil {
+			return errz.Err(err)
+		}
+		defer lg.WarnIfCloseError(log, lgm.CloseConn, conn)
+
+		o := options.FromContext(ctx)
+		allowCache := OptIngestCache.Get(o)
+		clnup := cleanup.New()
+		defer lg.WarnIfFuncError(log, lgm.CloseDB, clnup.Run)
+
+		base := files.MultiFileBase(src.Location)
+		mfs := make([]*multiFile, 0, len(paths))
+		for _, p := range paths {
+			var mf *multiFile
+			var grip Grip
+			if mf, grip, err = gs.ingestMultiFileMember(ctx, drvr, conn, src, p); err != nil {
+				return err
+			}
+
+			if allowCache {
+				// The file's cache DB outlives the grip.
+				lg.WarnIfCloseError(log, lgm.CloseDB, grip)
+			} else {
+				// Without caching, closing the grip deletes its DB, so it's
+				// kept open until the union is complete.
+				clnup.AddC(grip)
+			}
+
+			if mf.name, err = filepath.Rel(base, p); err != nil {
+				return errz.Err(err)
+			}
+			mf.name = filepath.ToSlash(mf.name)
+			mfs = append(mfs, mf)
+		}
+
+		addFileCol := OptIngestFileColumn.Get(o)
+		unions, err := unionMultiTables(mfs, addFileCol)
+		if err != nil {
+			return errz.Wrapf(err, "multi-file source %s", src.Handle)
+		}
+
+		for _, union := range unions {
+			if err = createMultiTable(ctx, conn, union, addFileCol); err != nil {
+				return err
+			}
+		}
+
+		for _, mf := range mfs {
+			if err = copyMultiFile(ctx, conn, mf, unions, addFileCol); err != nil {
+				return errz.Wrapf(err, "multi-file source %s: %s", src.Handle, mf.name)
+			}
+		}
+
+		log.Debug("Ingested multi-file source",
+			lga.Src, src, lga.Count, len(mfs), lga.Dest, dest.Source())
+		return nil
+	}
+}
+
+// ingestMultiFileMember opens the grip for file path of multi-file source
+// src, and returns the file's tables. The caller is responsible for closing
+// the returned grip.
+func (gs *Grips) ingestMultiFileMember(ctx context.Context, drvr Driver, conn *sql.Conn,
+	src *source.Source, path string,
+) (*multiFile, Grip, error) {
+	fileSrc := src.Clone()
+	fileSrc.Location = path
+	// The file column is added by the union, not by the file's ingest, so
+	// it's omitted from the file's options: toggling it must not invalidate
+	// the file's cache DB.
+	delete(fileSrc.Options, OptIngestFileColumn.Key())
+
+	grip, err := drvr.Open(ctx, fileSrc, ModeReadOnly)
+	if err != nil {
+		return nil, nil, errz.Wrapf(err, "multi-file source %s: ingest %s", src.Handle, path)
+	}
+
+	mf := &multiFile{}
+	if mf.dbPath, err = gripDBPath(ctx, grip); err == nil {
+		mf.tbls, err = readMultiTables(ctx, conn, mf.dbPath)
+	}
+
+	if err != nil {
+		lg.WarnIfCloseError(lg.FromContext(ctx), lgm.CloseDB, grip)
+		return nil, nil, errz.Wrapf(err, "multi-file source %s: %s", src.Handle, path)
+	}
+
+	return mf, grip, nil
+}
+
+// gripDBPath returns the path to the SQLite DB file of grip.
+func gripDBPath(ctx context.Context, grip Grip) (string, error) {
+	db, err := grip.DB(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var dbPath string
+	err = db.QueryRowContext(ctx, `SELECT file FROM pragma_database_list WHERE name = 'main'`).Scan(&dbPath)
+	if err != nil {
+		return "", errz.Wrap(err, "get ingest DB path")
+	}
+	if dbPath == "" {
+		return "", errz.New("ingest DB has no file")
+	}
+	return dbPath, nil
+}
+
+// attachMultiFile attaches the SQLite DB at dbPath to conn as schema "f".
+// The caller must invoke the returned detach func.
+func attachMultiFile(ctx context.Context, conn *sql.Conn, dbPath string) (detach func() error, err error) {
+	if _, err = conn.ExecContext(ctx, `ATTACH DATABASE ? AS f`, dbPath); err != nil {
+		return nil, errz.Wrap(err, "attach ingest DB")
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(ctx, `DETACH DATABASE f`)
+		return errz.Wrap(err, "detach ingest DB")
+	}, nil
+}
+
+// readMultiTables returns the tables of the SQLite DB at dbPath.
+func readMultiTables(ctx context.Context, conn *sql.Conn, dbPath string) (tbls []*multiTable, err error) {
+	detach, err := attachMultiFile(ctx, conn, dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errz.Append(err, detach()) }()
+
+	rows, err := conn.QueryContext(ctx,
+		`SELECT name FROM f.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY rowid`)
+	if err != nil {
+		return nil, errz.Err(err)
+	}
+
+	for rows.Next() {
+		tbl := &multiTable{types: map[string]string{}}
+		if err = rows.Scan(&tbl.name); err != nil {
+			_ = rows.Close()
+			return nil, errz.Err(err)
+		}
+		tbls = append(tbls, tbl)
+	}
+	if err = errz.Append(rows.Err(), rows.Close()); err != nil {
+		return nil, errz.Err(err)
+	}
+
+	for _, tbl := range tbls {
+		if err = readMultiTableCols(ctx, conn, tbl); err != nil {
+			return nil, err
+		}
+	}
+	return tbls, nil
+}
+
+// readMultiTableCols populates the cols of tbl, in attached schema "f".
+func readMultiTableCols(ctx context.Context, conn *sql.Conn, tbl *multiTable) error {
+	rows, err := conn.QueryContext(ctx, `SELECT name, type FROM pragma_table_info(?, 'f') ORDER BY cid`, tbl.name)
+	if err != nil {
+		return errz.Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var col, typ string
+		if err = rows.Scan(&col, &typ); err != nil {
+			return errz.Err(err)
+		}
+		tbl.cols = append(tbl.cols, col)
+		tbl.types[col] = typ
+	}
+	return errz.Err(rows.Err())
+}
+
+// unionMultiTables returns the union of the same-named tables of mfs, in
+// order of first appearance. The cols of each union table are likewise in
+// order of first appearance.
+func unionMultiTables(mfs []*multiFile, addFileCol bool) ([]*multiTable, error) {
+	var unions []*multiTable
+	byName := map[string]*multiTable{}
+	for _, mf := range mfs {
+		for _, tbl := range mf.tbls {
+			if addFileCol {
+				if _, ok := tbl.types[FileColumn]; ok {
+					return nil, errz.Errorf("%s: table {%s} already has column {%s}: unset option {%s}",
+						mf.name, tbl.name, FileColumn, OptIngestFileColumn.Key())
+				}
+			}
+
+			union, ok := byName[tbl.name]
+			if !ok {
+				union = &multiTable{name: tbl.name, types: map[string]string{}}
+				byName[tbl.name] = union
+				unions = append(unions, union)
+			}
+
+			for _, col := range tbl.cols {
+				typ, ok := union.types[col]
+				if !ok {
+					union.cols = append(union.cols, col)
+					union.types[col] = tbl.types[col]
+					continue
+				}
+				union.types[col] = reconcileDeclType(typ, tbl.types[col])
+			}
+		}
+	}
+	return unions, nil
+}
+
+// reconcileDeclType returns the declared type of a union col that is
+// declared as type a in one file, and type b in another. If the types
+// differ, but are both numeric, the wider numeric type is returned;
+// otherwise TEXT is returned, which can hold any value.
+func reconcileDeclType(a, b string) string {
+	if strings.EqualFold(a, b) {
+		return a
+	}
+
+	numeric := map[string]int{"INTEGER": 1, "NUMERIC": 2, "REAL": 3}
+	rankA, okA := numeric[strings.ToUpper(a)]
+	rankB, okB := numeric[strings.ToUpper(b)]
+	switch {
+	case !okA || !okB:
+		return "TEXT"
+	case rankA > rankB:
+		return a
+	default:
+		return b
+	}
+}
+
+// createMultiTable creates union table tbl via conn.
+func createMultiTable(ctx context.Context, conn *sql.Conn, tbl *multiTable, addFileCol bool) error {
+	defs := make([]string, 0, len(tbl.cols)+1)
+	for _, col := range tbl.cols {
+		typ := tbl.types[col]
+		if typ == "" {
+			typ = "TEXT"
+		}
+		defs = append(defs, stringz.DoubleQuote(col)+" "+typ)
+	}
+	if addFileCol {
+		defs = append(defs, stringz.DoubleQuote(FileColumn)+" TEXT")
+	}
+
+	stmt := "CREATE TABLE " + stringz.DoubleQuote(tbl.name) + " (" + strings.Join(defs, ", ") + ")"
+	_, err := conn.ExecContext(ctx, stmt)
+	return errz.Wrapf(err, "create table {%s}", tbl.name)
+}
+
+// copyMultiFile copies the rows of each of mf's tables into the
+// corresponding union table, via conn.
+func copyMultiFile(ctx context.Context, conn *sql.Conn, mf *multiFile, unions []*multiTable,
+	addFileCol bool,
+) (err error) {
+	detach, err := attachMultiFile(ctx, conn, mf.dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errz.Append(err, detach()) }()
+
+	for _, tbl := range mf.tbls {
+		var union *multiTable
+		for _, u := range unions {
+			if u.name == tbl.name {
+				union = u
+				break
+			}
+		}
+
+		dests := make([]string, 0, len(union.cols)+1)
+		exprs := make([]string, 0, len(union.cols)+1)
+		for _, col := range union.cols {
+			dests = append(dests, stringz.DoubleQuote(col))
+			if _, ok := tbl.types[col]; ok {
+				exprs = append(exprs, stringz.DoubleQuote(col))
+			} else {
+				exprs = append(exprs, "NULL")
+			}
+		}
+
+		var args []any
+		if addFileCol {
+			dests = append(dests, stringz.DoubleQuote(FileColumn))
+			exprs = append(exprs, "?")
+			args = append(args, mf.name)
+		}
+
+		stmt := "INSERT INTO main." + stringz.DoubleQuote(union.name) +
+			" (" + strings.Join(dests, ", ") + ") SELECT " + strings.Join(exprs, ", ") +
+			" FROM f." + stringz.DoubleQuote(tbl.name)
+		if _, err = conn.ExecContext(ctx, stmt, args...); err != nil {
+			return errz.Wrapf(err, "copy table {%s}", tbl.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// ingestSchemaKey is the key of OptIngestSchema, which can't refer to
+// itself in its validation func.
+const ingestSchemaKey = "ingest.schema"
+
+// OptIngestSchema specifies explicit column overrides for ingested data,
+// either inline or as the path to a YAML or JSON schema file.
+var OptIngestSchema = options.NewString(
+	ingestSchemaKey,
+	nil,
+	"",
+	func(s string) error {
+		if IsIngestFile(s) {
+			// The file is read at ingest time, as it may not exist yet.
+			return nil
+		}
+		_, err := parseInlineIngestSchema(s)
+		return err
+	},
+	"Column overrides for ingest data",
+	`Explicitly set the kind of ingested columns, rename them, or drop them,
+instead of relying on detection. This is useful when detection gets it wrong,
+e.g. a zip code column "01234" that is detected as int, losing the leading
+zero. The option applies to document sources (CSV, TSV, JSON, XLSX), and is
+typically set per-source.
+
+The value is either an inline schema, or the path to a YAML or JSON schema
+file. An inline schema is a comma-separated list of column entries:
+
+  zip:text           set the kind of column "zip" to text
+  id:int=user_id     set the kind of "id" to int, and rename it "user_id"
+  name=full_name     rename "name" to "full_name"
+  -notes             drop column "notes"
+
+The available kinds are: text, int, float, decimal, bool, datetime, date,
+time, bytes. Columns are matched by name, after any rename performed by
+option "ingest.column.rename". A schema file looks like:
+
+  columns:
+    - name: zip
+      kind: text
+    - name: id
+      kind: int
+      rename: user_id
+    - name: notes
+      drop: true
+    - name: amount
+      kind: decimal
+      table: Sheet2
+
+The "table" field restricts an entry to a particular table, e.g. an XLSX
+sheet; by default an entry applies to every table of the source.
+
+  $ sq config set --src @customers ingest.schema 'zip:text,-notes'
+  $ sq config set --src @customers ingest.schema ./customers.schema.yml
+
+Use "sq inspect -v" to see which columns were overridden. A relative schema
+file path is made absolute when the option is set via "sq add" or
+"sq config set".`,
+	options.TagSource,
+	options.TagIngestMutate,
+	options.TagIngestFile,
+)
+
+// IngestSchema holds explicit column overrides for ingested data, as
+// specified by OptIngestSchema.
+type IngestSchema struct {
+	Columns []*IngestColumn `json:"columns" yaml:"columns"`
+}
+
+// IngestColumn is an IngestSchema column override. The column is matched
+// by Name, and optionally Table.
+type IngestColumn struct {
+	// Name is the column name, as it would be named without the
+	// override.
+	Name string `json:"name" yaml:"name"`
+
+	// Table, if non-empty, restricts the override to the named table.
+	Table string `json:"table,omitempty" yaml:"table,omitempty"`
+
+	// Rename, if non-empty, is the column's new name.
+	Rename string `json:"rename,omitempty" yaml:"rename,omitempty"`
+
+	// Kind, if not kind.Unknown, is the column's kind, overriding
+	// the detected kind.
+	Kind kind.Kind `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// Drop indicates that the column is not ingested.
+	Drop bool `json:"drop,omitempty" yaml:"drop,omitempty"`
+}
+
+// ReadIngestSchema returns the IngestSchema specified by src's
+// OptIngestSchema, or nil if the option is not set. If the option value
+// is a file path, the file is loaded.
+func ReadIngestSchema(src *source.Source) (*IngestSchema, error) {
+	val := strings.TrimSpace(OptIngestSchema.Get(src.Options))
+	if val == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	if !IsIngestFile(val) {
+		return parseInlineIngestSchema(val)
+	}
+
+	data, err := os.ReadFile(val)
+	if err != nil {
+		return nil, errz.Wrapf(err, "%s: read schema file", ingestSchemaKey)
+	}
+
+	sch := &IngestSchema{}
+	if err = ioz.UnmarshallYAML(data, sch); err != nil {
+		return nil, errz.Wrapf(err, "%s: parse schema file {%s}", ingestSchemaKey, val)
+	}
+
+	if err = sch.validate(); err != nil {
+		return nil, errz.Wrapf(err, "%s: schema file {%s}", ingestSchemaKey, val)
+	}
+	return sch, nil
+}
+
+// IsIngestFile returns true if val, the value of an option tagged
+// options.TagIngestFile, such as OptIngestSchema, is a file path rather
+// than an inline value.
+func IsIngestFile(val string) bool {
+	switch strings.ToLower(filepath.Ext(val)) {
+	case ".yml", ".yaml", ".json":
+		return true
+	}
+
+	fi, err := os.Stat(val)
+	return err == nil && !fi.IsDir()
+}
+
+// parseInlineIngestSchema parses an inline OptIngestSchema value, such as
+// "zip:text,id:int=user_id,-notes".
+func parseInlineIngestSchema(val string) (*IngestSchema, error) {
+	sch := &IngestSchema{}
+	for _, entry := range strings.Split(val, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		col := &IngestColumn{}
+		if name, ok := strings.CutPrefix(entry, "-"); ok {
+			col.Name, col.Drop = strings.TrimSpace(name), true
+			sch.Columns = append(sch.Columns, col)
+			continue
+		}
+
+		entry, col.Rename, _ = strings.Cut(entry, "=")
+		col.Rename = strings.TrimSpace(col.Rename)
+
+		var kindName string
+		var hasKind bool
+		col.Name, kindName, hasKind = strings.Cut(entry, ":")
+		col.Name = strings.TrimSpace(col.Name)
+		if hasKind {
+			if err := col.Kind.UnmarshalText([]byte(strings.TrimSpace(kindName))); err != nil {
+				return nil, errz.Wrapf(err, "%s: column {%s}", ingestSchemaKey, col.Name)
+			}
+		}
+
+		sch.Columns = append(sch.Columns, col)
+	}
+
+	if err := sch.validate(); err != nil {
+		return nil, errz.Wrap(err, ingestSchemaKey)
+	}
+	return sch, nil
+}
+
+// validate returns an error if sch has a malformed or useless entry.
+func (sch *IngestSchema) validate() error {
+	for _, col := range sch.Columns {
+		switch {
+		case col == nil:
+			return errz.New("empty column entry")
+		case col.Name == "":
+			return errz.New("column entry has no name")
+		case col.Kind == kind.Null:
+			return errz.Errorf("column {%s}: kind {%s} is not allowed", col.Name, col.Kind)
+		case col.Drop && (col.Kind != kind.Unknown || col.Rename != ""):
+			return errz.Errorf("column {%s}: a dropped column can't have a kind or rename", col.Name)
+		case !col.Drop && col.Kind == kind.Unknown && col.Rename == "":
+			return errz.Errorf("column {%s}: entry must specify a kind, rename, or drop", col.Name)
+		}
+	}
+	return nil
+}
+
+// Column returns the override for column name of table tbl, or nil.
+// A table-specific override takes precedence.
+func (sch *IngestSchema) Column(tbl, name string) *IngestColumn {
+	var found *IngestColumn
+	for _, col := range sch.Columns {
+		if col.Name != name {
+			continue
+		}
+		if col.Table == tbl {
+			return col
+		}
+		if col.Table == "" && found == nil {
+			found = col
+		}
+	}
+	return found
+}
+
+// Apply applies sch to the columns of table tbl, whose names and kinds
+// are as detected by the ingester. The elements of names, kinds and
+// mungeFns are modified in place for each overridden column: mungeFns
+// gets a kind.MungeFunc that converts values to the override kind. The
+// returned keep slice holds the indices of the columns that remain after
+// dropped columns are removed; it is nil if no column is dropped. Use
+// ProjectIngestFields to apply keep to the ingester's column slices and
+// records. If sch is nil, Apply is a no-op.
+//
+// A schema entry that matches no column of tbl is logged, but is not an
+// error, as it may apply to a different table of the source.
+func (sch *IngestSchema) Apply(ctx context.Context, tbl string, names []string, kinds []kind.Kind,
+	mungeFns []kind.MungeFunc,
+) (keep []int, err error) {
+	if sch == nil {
+		return nil, nil
+	}
+
+	matched := make(map[*IngestColumn]bool, len(sch.Columns))
+	var dropped bool
+	for i := range names {
+		col := sch.Column(tbl, names[i])
+		if col == nil {
+			keep = append(keep, i)
+			continue
+		}
+
+		matched[col] = true
+		if col.Drop {
+			dropped = true
+			continue
+		}
+
+		keep = append(keep, i)
+		if col.Kind != kind.Unknown {
+			var fn kind.MungeFunc
+			if fn, err = kind.MungeFuncFor(col.Kind); err != nil {
+				return nil, errz.Wrapf(err, "%s: column {%s}", ingestSchemaKey, col.Name)
+			}
+
+			name := col.Name
+			kinds[i] = col.Kind
+			mungeFns[i] = func(v any) (any, error) {
+				v, err := fn(v)
+				if err != nil {
+					return nil, errz.Wrapf(err, "%s: table {%s}: column {%s}",
+						ingestSchemaKey, tbl, name)
+				}
+				return v, nil
+			}
+		}
+		if col.Rename != "" {
+			names[i] = col.Rename
+		}
+	}
+
+	log := lg.FromContext(ctx)
+	for _, col := range sch.Columns {
+		if !matched[col] && (col.Table == "" || col.Table == tbl) {
+			log.Warn("Ingest schema column not found", lga.Table, tbl, lga.Col, col.Name)
+		}
+	}
+
+	if !dropped {
+		keep = nil
+	}
+
+	if len(keep) == 0 && dropped {
+		return nil, errz.Errorf("%s: table {%s}: all columns are dropped", ingestSchemaKey, tbl)
+	}
+
+	for i, name := range names {
+		if (keep == nil || slices.Contains(keep, i)) && slices.Index(names, name) != i {
+			// Only a rename can introduce a duplicate; the ingester has
+			// already deduplicated the detected names.
+			return nil, errz.Errorf("%s: table {%s}: duplicate column name {%s}",
+				ingestSchemaKey, tbl, name)
+		}
+	}
+
+	return keep, nil
+}
+
+// ProjectIngestFields returns the elements of a at the indices in keep,
+// as returned by IngestSchema.Apply. If keep is nil, a is returned
+// unchanged.
+func ProjectIngestFields[T any](keep []int, a []T) []T {
+	if keep == nil {
+		return a
+	}
+
+	b := make([]T, len(keep))
+	for i, j := range keep {
+		if j < len(a) {
+			b[i] = a[j]
+		}
+	}
+	return b
+}
+
+// MarkIngestOverrides sets metadata.Column.IngestOverride on the columns
+// of md that were overridden by src's OptIngestSchema. It's used by the
+// document drivers' grips, whose metadata is otherwise that of the
+// ingest cache DB. If the schema can't be read, an error is logged, and
+// md is unchanged.
+func MarkIngestOverrides(ctx context.Context, src *source.Source, md ...*metadata.Table) {
+	sch, err := ReadIngestSchema(src)
+	if err != nil {
+		lg.FromContext(ctx).Warn("Failed to read ingest schema", lga.Src, src, lga.Err, err)
+		return
+	}
+	if sch == nil {
+		return
+	}
+
+	for _, tbl := range md {
+		if tbl == nil {
+			continue
+		}
+
+		for _, col := range tbl.Columns {
+			for _, sc := range sch.Columns {
+				if sc.Drop || (sc.Table != "" && sc.Table != tbl.Name) {
+					continue
+				}
+
+				name := sc.Name
+				if sc.Rename != "" {
+					name = sc.Rename
+				}
+				if name != col.Name || sch.Column(tbl.Name, sc.Name) != sc {
+					continue
+				}
+
+				col.IngestOverride = &metadata.IngestOverride{Kind: sc.Kind}
+				if sc.Rename != "" {
+					col.IngestOverride.RenamedFrom = sc.Name
+				}
+				break
+			}
+		}
+	}
+}
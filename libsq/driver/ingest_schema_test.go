@@ -0,0 +1,136 @@
+package driver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+func TestReadIngestSchema_inline(t *testing.T) {
+	testCases := []struct {
+		val     string
+		want    []*driver.IngestColumn
+		wantErr bool
+	}{
+		{val: "", want: nil},
+		{
+			val: "zip:text, id:int=user_id,name=full_name,-notes",
+			want: []*driver.IngestColumn{
+				{Name: "zip", Kind: kind.Text},
+				{Name: "id", Kind: kind.Int, Rename: "user_id"},
+				{Name: "name", Rename: "full_name"},
+				{Name: "notes", Drop: true},
+			},
+		},
+		{val: "zip:bogus", wantErr: true},
+		{val: "zip:null", wantErr: true},
+		{val: "zip", wantErr: true},
+		{val: ":text", wantErr: true},
+		{val: "-", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.val, func(t *testing.T) {
+			src := &source.Source{Options: options.Options{driver.OptIngestSchema.Key(): tc.val}}
+			sch, err := driver.ReadIngestSchema(src)
+			if tc.wantErr {
+				require.Error(t, err)
+				_, err = driver.OptIngestSchema.Process(src.Options)
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.want == nil {
+				require.Nil(t, sch)
+				return
+			}
+			require.Equal(t, tc.want, sch.Columns)
+		})
+	}
+}
+
+func TestReadIngestSchema_file(t *testing.T) {
+	const data = `columns:
+  - name: zip
+    kind: text
+  - name: amount
+    kind: decimal
+    table: Sheet2
+  - name: notes
+    drop: true
+`
+	fp := filepath.Join(t.TempDir(), "schema.yml")
+	require.NoError(t, os.WriteFile(fp, []byte(data), 0o600))
+
+	src := &source.Source{Options: options.Options{driver.OptIngestSchema.Key(): fp}}
+	sch, err := driver.ReadIngestSchema(src)
+	require.NoError(t, err)
+	require.Equal(t, []*driver.IngestColumn{
+		{Name: "zip", Kind: kind.Text},
+		{Name: "amount", Kind: kind.Decimal, Table: "Sheet2"},
+		{Name: "notes", Drop: true},
+	}, sch.Columns)
+
+	src.Options[driver.OptIngestSchema.Key()] = filepath.Join(t.TempDir(), "missing.yml")
+	_, err = driver.ReadIngestSchema(src)
+	require.Error(t, err)
+}
+
+func TestIngestSchema_Apply(t *testing.T) {
+	ctx := context.Background()
+	sch := &driver.IngestSchema{Columns: []*driver.IngestColumn{
+		{Name: "zip", Kind: kind.Text},
+		{Name: "id", Kind: kind.Int, Rename: "user_id"},
+		{Name: "notes", Drop: true},
+		{Name: "amount", Kind: kind.Decimal, Table: "Sheet2"},
+	}}
+
+	names := []string{"id", "zip", "notes", "amount"}
+	kinds := []kind.Kind{kind.Int, kind.Int, kind.Text, kind.Float}
+	mungeFns := make([]kind.MungeFunc, len(names))
+
+	keep, err := sch.Apply(ctx, "Sheet1", names, kinds, mungeFns)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 3}, keep)
+	require.Equal(t, []string{"user_id", "zip", "amount"}, driver.ProjectIngestFields(keep, names))
+	require.Equal(t, []kind.Kind{kind.Int, kind.Text, kind.Float}, driver.ProjectIngestFields(keep, kinds))
+
+	// The "amount" override applies only to table Sheet2.
+	require.Nil(t, mungeFns[3])
+
+	v, err := mungeFns[1]("01234")
+	require.NoError(t, err)
+	require.Equal(t, "01234", v)
+
+	_, err = mungeFns[0]("abc")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "column {id}")
+
+	// A nil schema is a no-op.
+	keep, err = (*driver.IngestSchema)(nil).Apply(ctx, "Sheet1", names, kinds, mungeFns)
+	require.NoError(t, err)
+	require.Nil(t, keep)
+
+	// A rename can't clash with another column.
+	sch = &driver.IngestSchema{Columns: []*driver.IngestColumn{{Name: "id", Rename: "zip"}}}
+	_, err = sch.Apply(ctx, "Sheet1", []string{"id", "zip"}, make([]kind.Kind, 2), make([]kind.MungeFunc, 2))
+	require.Error(t, err)
+}
+
+func TestProjectIngestFields(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	require.Equal(t, a, driver.ProjectIngestFields(nil, a))
+	require.Equal(t, []string{"c", "a"}, driver.ProjectIngestFields([]int{2, 0}, a))
+
+	// A short record, e.g. a ragged CSV row, gets zero values.
+	require.Equal(t, []string{"a", ""}, driver.ProjectIngestFields([]int{0, 5}, a))
+}
@@ -0,0 +1,405 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/driver/dialect"
+	"github.com/neilotoole/sq/libsq/source/metadata"
+)
+
+// ProfileOpts configures column profiling. See ProfileTable.
+type ProfileOpts struct {
+	// TopN is the number of most frequent values to report for each
+	// column. If zero, frequent values are not reported.
+	TopN int
+
+	// HistogramBins is the number of bins of the histogram reported for
+	// each numeric column. If zero, histograms are not reported.
+	HistogramBins int
+
+	// Approx indicates that distinct values should be counted via the
+	// dialect's approximate (HyperLogLog) aggregate, if it has one. An
+	// approximate count is much cheaper to compute for a large table.
+	Approx bool
+}
+
+// profileClass determines which statistics are computed for a column.
+type profileClass int
+
+const (
+	// profileOpaque is a column whose values can't be compared, such as
+	// a bytes column: only nulls are counted.
+	profileOpaque profileClass = iota
+
+	// profileBasic is a column whose values can be compared, but not
+	// ordered, such as a bool column.
+	profileBasic
+	profileNumeric
+	profileText
+	profileTemporal
+)
+
+func profileClassOf(k kind.Kind) profileClass {
+	switch k { //nolint:exhaustive // Bytes, Unknown and Null are opaque
+	case kind.Int, kind.Float, kind.Decimal:
+		return profileNumeric
+	case kind.Text:
+		return profileText
+	case kind.Datetime, kind.Date, kind.Time:
+		return profileTemporal
+	case kind.Bool:
+		return profileBasic
+	default:
+		return profileOpaque
+	}
+}
+
+// colProfile holds the scan destinations of a column's aggregates, as
+// computed by the single aggregate query of ProfileTable.
+type colProfile struct {
+	col      *metadata.Column
+	class    profileClass
+	ident    string
+	nonNull  sql.NullInt64
+	distinct sql.NullInt64
+	minVal   any
+	maxVal   any
+	mean     sql.NullFloat64
+	stddev   sql.NullFloat64
+	sumSq    sql.NullFloat64
+	minLen   sql.NullInt64
+	maxLen   sql.NullInt64
+}
+
+// ProfileTable profiles each of tbl's columns, setting its
+// metadata.Column.Profile. The statistics are computed by SQL aggregates
+// pushed down to grip's database: one aggregate query computes the basic
+// statistics of every column, and further queries compute each column's
+// most frequent values, and the histograms, as configured by opts.
+func ProfileTable(ctx context.Context, grip Grip, tbl *metadata.Table, opts ProfileOpts) error {
+	if tbl == nil || len(tbl.Columns) == 0 {
+		return nil
+	}
+
+	log := lg.FromContext(ctx)
+	db, err := grip.DB(ctx)
+	if err != nil {
+		return err
+	}
+
+	dlct := grip.SQLDriver().Dialect()
+	p := dlct.Profile
+	if p == (dialect.Profile{}) {
+		p = dialect.DefaultProfile()
+	}
+
+	tblRef := dlct.Enquote(tbl.Name)
+	cps := make([]*colProfile, 0, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		if col != nil {
+			cps = append(cps, &colProfile{col: col, class: profileClassOf(col.Kind), ident: dlct.Enquote(col.Name)})
+		}
+	}
+
+	start := time.Now()
+	rowCount, err := profileAggregates(ctx, db, p, tblRef, cps, opts)
+	if err != nil {
+		return errz.Wrapf(err, "profile table %s", tbl.Name)
+	}
+
+	for _, cp := range cps {
+		cp.col.Profile = cp.toProfile(rowCount, opts.Approx && p.ApproxCountDistinct != "")
+	}
+
+	if opts.TopN > 0 {
+		for _, cp := range cps {
+			if err = profileTop(ctx, db, p, tblRef, cp, opts.TopN); err != nil {
+				return errz.Wrapf(err, "profile table %s: column %s", tbl.Name, cp.col.Name)
+			}
+		}
+	}
+
+	if opts.HistogramBins > 0 {
+		if err = profileHistograms(ctx, db, tblRef, cps, opts.HistogramBins); err != nil {
+			return errz.Wrapf(err, "profile table %s", tbl.Name)
+		}
+	}
+
+	log.Debug("Profiled table", lga.Table, tbl.Name, lga.Count, rowCount, lga.Elapsed, time.Since(start))
+	return nil
+}
+
+// profileAggregates executes the aggregate query that computes the basic
+// statistics of each of cps, returning the table's row count.
+func profileAggregates(ctx context.Context, db sqlz.DB, p dialect.Profile, tblRef string,
+	cps []*colProfile, opts ProfileOpts,
+) (int64, error) {
+	var rowCount int64
+	exprs := []string{"COUNT(*)"}
+	dests := []any{&rowCount}
+	add := func(expr string, dest any) {
+		exprs = append(exprs, expr)
+		dests = append(dests, dest)
+	}
+
+	for _, cp := range cps {
+		add("COUNT("+cp.ident+")", &cp.nonNull)
+		if cp.class == profileOpaque {
+			continue
+		}
+
+		if opts.Approx && p.ApproxCountDistinct != "" {
+			add(p.ApproxCountDistinct+"("+cp.ident+")", &cp.distinct)
+		} else {
+			add("COUNT(DISTINCT "+cp.ident+")", &cp.distinct)
+		}
+
+		switch cp.class { //nolint:exhaustive // other classes have no further aggregates
+		case profileNumeric:
+			f := "CAST(" + cp.ident + " AS " + p.Float + ")"
+			add("MIN("+cp.ident+")", &cp.minVal)
+			add("MAX("+cp.ident+")", &cp.maxVal)
+			add("AVG("+f+")", &cp.mean)
+			if p.StdDev != "" {
+				add(p.StdDev+"("+f+")", &cp.stddev)
+			} else {
+				add("SUM("+f+" * "+f+")", &cp.sumSq)
+			}
+		case profileText:
+			add("MIN("+cp.ident+")", &cp.minVal)
+			add("MAX("+cp.ident+")", &cp.maxVal)
+			add("MIN("+p.CharLength+"("+cp.ident+"))", &cp.minLen)
+			add("MAX("+p.CharLength+"("+cp.ident+"))", &cp.maxLen)
+		case profileTemporal:
+			add("MIN("+cp.ident+")", &cp.minVal)
+			add("MAX("+cp.ident+")", &cp.maxVal)
+		}
+	}
+
+	query := "SELECT " + strings.Join(exprs, ", ") + " FROM " + tblRef
+	lg.FromContext(ctx).Debug("Profile aggregates", lga.SQL, query)
+	if err := db.QueryRowContext(ctx, query).Scan(dests...); err != nil {
+		return 0, errz.Err(err)
+	}
+	return rowCount, nil
+}
+
+// toProfile returns the metadata.ColumnProfile of cp's scanned aggregates.
+func (cp *colProfile) toProfile(rowCount int64, approx bool) *metadata.ColumnProfile {
+	prof := &metadata.ColumnProfile{NullCount: rowCount - cp.nonNull.Int64}
+	if rowCount > 0 {
+		prof.NullPct = float64(prof.NullCount) * 100 / float64(rowCount)
+	}
+
+	if cp.class == profileOpaque {
+		return prof
+	}
+
+	prof.DistinctCount = &cp.distinct.Int64
+	prof.DistinctApprox = approx
+	prof.Min = profileValue(cp.col.Kind, cp.minVal)
+	prof.Max = profileValue(cp.col.Kind, cp.maxVal)
+	if cp.class == profileText {
+		prof.MinLength = nullPtr(cp.minLen.Int64, cp.minLen.Valid)
+		prof.MaxLength = nullPtr(cp.maxLen.Int64, cp.maxLen.Valid)
+	}
+
+	if cp.class != profileNumeric || !cp.mean.Valid {
+		return prof
+	}
+
+	prof.Mean = &cp.mean.Float64
+	switch n := float64(cp.nonNull.Int64); {
+	case cp.stddev.Valid:
+		prof.StdDev = &cp.stddev.Float64
+	case cp.sumSq.Valid && n > 1:
+		// The dialect has no stddev aggregate: derive the sample standard
+		// deviation from the sum of squares.
+		variance := (cp.sumSq.Float64 - n*cp.mean.Float64*cp.mean.Float64) / (n - 1)
+		stddev := math.Sqrt(max(variance, 0))
+		prof.StdDev = &stddev
+	}
+
+	return prof
+}
+
+// profileTop populates the Top values of cp's profile, via a GROUP BY
+// query. A column whose values are all distinct, such as a primary key,
+// has no frequent values. If the distinct count is exact, such a column
+// isn't queried; an approximate count can't rule out duplicates, so the
+// column is queried regardless, and its Top values are left empty if the
+// most frequent value occurs only once.
+func profileTop(ctx context.Context, db sqlz.DB, p dialect.Profile, tblRef string,
+	cp *colProfile, n int,
+) error {
+	prof := cp.col.Profile
+	if cp.class == profileOpaque || cp.nonNull.Int64 == 0 || prof.DistinctCount == nil {
+		return nil
+	}
+	if !prof.DistinctApprox && *prof.DistinctCount >= cp.nonNull.Int64 {
+		return nil
+	}
+
+	query := "SELECT " + cp.ident + ", COUNT(*) FROM " + tblRef +
+		" WHERE " + cp.ident + " IS NOT NULL GROUP BY " + cp.ident +
+		" ORDER BY COUNT(*) DESC, " + cp.ident
+	if p.Limit != "" {
+		query += fmt.Sprintf(p.Limit, n)
+	}
+
+	log := lg.FromContext(ctx)
+	log.Debug("Profile top values", lga.SQL, query)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return errz.Err(err)
+	}
+	defer sqlz.CloseRows(log, rows)
+
+	for rows.Next() && len(prof.Top) < n {
+		var (
+			val   any
+			count int64
+		)
+		if err = rows.Scan(&val, &count); err != nil {
+			return errz.Err(err)
+		}
+		if count < 2 && len(prof.Top) == 0 {
+			// The most frequent value is unique: the values are all distinct.
+			break
+		}
+		prof.Top = append(prof.Top, &metadata.ValueCount{Value: profileValue(cp.col.Kind, val), Count: count})
+	}
+
+	return errz.Err(rows.Err())
+}
+
+// profileHistograms populates the Histogram of the profile of each
+// numeric column of cps, via a single query that counts the values in
+// each bin of each column.
+func profileHistograms(ctx context.Context, db sqlz.DB, tblRef string, cps []*colProfile, bins int) error {
+	var (
+		exprs []string
+		dests []any
+	)
+
+	for _, cp := range cps {
+		prof := cp.col.Profile
+		if cp.class != profileNumeric {
+			continue
+		}
+
+		lo, ok1 := profileFloat(prof.Min)
+		hi, ok2 := profileFloat(prof.Max)
+		if !ok1 || !ok2 || lo >= hi {
+			continue
+		}
+
+		width := (hi - lo) / float64(bins)
+		prof.Histogram = make([]*metadata.HistogramBin, bins)
+		for i := range bins {
+			bin := &metadata.HistogramBin{Lower: lo + float64(i)*width, Upper: lo + float64(i+1)*width}
+			cond := cp.ident + " >= " + formatFloat(bin.Lower) + " AND " + cp.ident + " < " + formatFloat(bin.Upper)
+			if i == bins-1 {
+				// The final bin includes the max value.
+				bin.Upper = hi
+				cond = cp.ident + " >= " + formatFloat(bin.Lower) + " AND " + cp.ident + " <= " + formatFloat(hi)
+			}
+
+			prof.Histogram[i] = bin
+			exprs = append(exprs, "SUM(CASE WHEN "+cond+" THEN 1 ELSE 0 END)")
+			dests = append(dests, &histogramDest{bin: bin})
+		}
+	}
+
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	query := "SELECT " + strings.Join(exprs, ", ") + " FROM " + tblRef
+	lg.FromContext(ctx).Debug("Profile histograms", lga.SQL, query)
+	return errz.Err(db.QueryRowContext(ctx, query).Scan(dests...))
+}
+
+// histogramDest is a sql.Scanner that scans a bin's count.
+type histogramDest struct {
+	bin *metadata.HistogramBin
+}
+
+// Scan implements sql.Scanner.
+func (d *histogramDest) Scan(src any) error {
+	var n sql.NullInt64
+	if err := n.Scan(src); err != nil {
+		return err
+	}
+	d.bin.Count = n.Int64
+	return nil
+}
+
+// profileValue normalizes a value scanned by a profile query: a []byte is
+// converted to string, and a numeric column's value that the driver
+// returned as text is parsed.
+func profileValue(k kind.Kind, v any) any {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+
+	s, ok := v.(string)
+	if !ok || profileClassOf(k) != profileNumeric {
+		return v
+	}
+
+	if k == kind.Int {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return v
+}
+
+// profileFloat returns v, the value of a numeric column, as float64.
+func profileFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func nullPtr[T any](v T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+	return &v
+}
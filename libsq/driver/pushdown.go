@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TablePreparer is an optional interface implemented by a Grip whose tables
+// are populated on demand, such as a Grip that ingests a table from a
+// non-SQL database only when the table is queried. The query pipeline
+// invokes PrepareTable for each table that a query selects from, before the
+// query is executed against the Grip's DB. Mirrors the optional-capability
+// pattern of [ConnParamDetector].
+type TablePreparer interface {
+	// PrepareTable populates tbl. If filter is non-nil, the query selects
+	// only the rows of tbl that satisfy filter, and so the implementation
+	// may populate tbl with just those rows. The filter is an optimization
+	// only: the query still applies its own WHERE clause to tbl, so the
+	// implementation is free to ignore filter (or some of its predicates),
+	// but it must not exclude any row that satisfies filter.
+	PrepareTable(ctx context.Context, tbl string, filter *Filter) error
+}
+
+// Filter is a conjunction of simple predicates on the columns of a table,
+// derived from a query's WHERE clause, that a TablePreparer may push down
+// to its underlying database. A row satisfies the filter if it satisfies
+// every one of Preds. See TablePreparer.
+type Filter struct {
+	// Preds are the predicates of the filter.
+	Preds []FilterPred
+}
+
+// String returns a log/debug-friendly representation.
+func (f *Filter) String() string {
+	if f == nil || len(f.Preds) == 0 {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	for i, pred := range f.Preds {
+		if i > 0 {
+			sb.WriteString(" && ")
+		}
+		sb.WriteString(pred.String())
+	}
+	return sb.String()
+}
+
+// FilterPred is a predicate of a Filter, comparing column Col with the
+// literal value Val, e.g. ".age >= 21".
+type FilterPred struct {
+	// Val is the value that the column is compared with. It is one of nil,
+	// int64, float64, string or bool. If nil, Op is "==" or "!=", and the
+	// predicate tests whether the column is NULL.
+	Val any
+
+	// Col is the column name.
+	Col string
+
+	// Op is the comparison operator, one of "==", "!=", "<", "<=", ">",
+	// or ">=".
+	Op string
+}
+
+// String returns a log/debug-friendly representation.
+func (p FilterPred) String() string {
+	if s, ok := p.Val.(string); ok {
+		return fmt.Sprintf(".%s %s %q", p.Col, p.Op, s)
+	}
+	if p.Val == nil {
+		return fmt.Sprintf(".%s %s null", p.Col, p.Op)
+	}
+	return fmt.Sprintf(".%s %s %v", p.Col, p.Op, p.Val)
+}
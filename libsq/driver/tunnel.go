@@ -0,0 +1,540 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/checksum"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+var (
+	// OptSSHTunnel specifies the SSH bastion through which a source's
+	// database is reached.
+	OptSSHTunnel = options.NewString(
+		"ssh.tunnel",
+		nil,
+		"",
+		func(s string) error {
+			if s == "" {
+				return nil
+			}
+			_, _, err := parseSSHTunnel(s)
+			return err
+		},
+		"SSH tunnel for DB connection",
+		`SSH bastion, in the form "user@host[:port]", through which the source's
+database is reached. When set, sq connects to the bastion, forwards a local
+port to the database host and port in the source location, and connects to
+the database via that port. Sources that use the same bastion share the SSH
+connection. The port defaults to 22.
+
+The SSH key is given by ssh.key. If that's not set, keys are obtained from the
+SSH agent (via SSH_AUTH_SOCK), and from the default key files in ~/.ssh.
+
+  $ sq config set --src @prod_pg ssh.tunnel alice@bastion.acme.com`,
+		options.TagSource,
+		options.TagSQL,
+	)
+
+	// OptSSHKey specifies the private key for OptSSHTunnel.
+	OptSSHKey = options.NewString(
+		"ssh.key",
+		nil,
+		"",
+		nil,
+		"SSH tunnel private key",
+		`Private key used to authenticate to the ssh.tunnel bastion. The value
+is the path to a PEM key file, or the PEM data itself, typically via a secret
+reference such as ${keyring:bastion/key}. If the key is encrypted, its
+passphrase is given by ssh.key-passphrase.`,
+		options.TagSource,
+		options.TagSQL,
+	)
+
+	// OptSSHKeyPassphrase specifies the passphrase for OptSSHKey.
+	OptSSHKeyPassphrase = options.NewString(
+		"ssh.key-passphrase",
+		nil,
+		"",
+		nil,
+		"SSH tunnel private key passphrase",
+		`Passphrase of an encrypted ssh.key. Typically a secret reference, e.g.
+${keyring:bastion/passphrase}.`,
+		options.TagSource,
+		options.TagSQL,
+	)
+
+	// OptSSHKnownHosts specifies the known_hosts file used to verify the
+	// OptSSHTunnel host key.
+	OptSSHKnownHosts = options.NewString(
+		"ssh.known-hosts",
+		nil,
+		"",
+		nil,
+		"SSH tunnel known_hosts file",
+		`Path to the known_hosts file used to verify the host key of the
+ssh.tunnel bastion. If not set, ~/.ssh/known_hosts is used.
+
+Contrast with ssh.insecure-skip-verify.`,
+		options.TagSource,
+		options.TagSQL,
+	)
+
+	// OptSSHInsecureSkipVerify disables OptSSHTunnel host key verification.
+	OptSSHInsecureSkipVerify = options.NewBool(
+		"ssh.insecure-skip-verify",
+		nil,
+		false,
+		"Skip SSH tunnel host key verification",
+		`Skip verification of the host key of the ssh.tunnel bastion. This is
+insecure, and should only be used for testing.`,
+		options.TagSource,
+		options.TagSQL,
+	)
+)
+
+// TunnelError indicates that the SSH tunnel to a source's database
+// could not be established, as opposed to the database itself being
+// unreachable via the tunnel.
+type TunnelError struct {
+	error
+
+	// Tunnel is the OptSSHTunnel value, e.g. "alice@bastion:22".
+	Tunnel string
+}
+
+// Error satisfies the stdlib error interface.
+func (e *TunnelError) Error() string {
+	return "ssh tunnel " + e.Tunnel + ": " + e.error.Error()
+}
+
+// Unwrap satisfies the stdlib errors.Unwrap function.
+func (e *TunnelError) Unwrap() error { return e.error }
+
+// NewTunnelError returns a TunnelError for tunnel, or nil if err is nil.
+func NewTunnelError(tunnel string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return errz.Err(&TunnelError{Tunnel: tunnel, error: err}, errz.Skip(1))
+}
+
+// IsTunnelError returns true if err is or wraps a TunnelError.
+func IsTunnelError(err error) bool {
+	var te *TunnelError
+	return errors.As(err, &te)
+}
+
+// Tunnels manages the SSH tunnels, configured via OptSSHTunnel, through
+// which sources' databases are reached. An SSH connection is shared by
+// the sources that use the same bastion and credentials, and the local
+// port-forward for a database host is shared by the sources that use it.
+// Tunnels must be closed via Close, which tears down the forwards and the
+// SSH connections. A shared SSH connection that has died, e.g. due to a
+// network outage, is redialed when next needed.
+type Tunnels struct {
+	// secretReg resolves secret references in the SSH options. It may be
+	// nil, in which case using a secret reference in those options is an
+	// error.
+	secretReg *secret.Registry
+
+	// clients is keyed by a checksum of the bastion address and the
+	// SSH credentials.
+	clients map[string]*ssh.Client
+
+	// forwards maps the client key and remote database address to the
+	// local listener forwarding to that address. A forward uses the
+	// client for its key at the time of each connection, so the forward
+	// survives the client being redialed.
+	forwards map[string]net.Listener
+
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewTunnels returns a new Tunnels instance. Arg secretReg may be nil.
+func NewTunnels(secretReg *secret.Registry) *Tunnels {
+	return &Tunnels{
+		secretReg: secretReg,
+		clients:   map[string]*ssh.Client{},
+		forwards:  map[string]net.Listener{},
+	}
+}
+
+// Rewrite returns src if OptSSHTunnel is not set in o. Otherwise, it
+// establishes the tunnel to the database host in src.Location (using
+// defaultPort if the location specifies none), and returns a clone of src
+// whose location is rewritten to connect to the local end of the tunnel.
+// The src.Location must already have its secrets resolved (see
+// ResolveSourceSecrets). If the tunnel can't be established, the returned
+// error is a *TunnelError.
+func (t *Tunnels) Rewrite(ctx context.Context, src *source.Source, o options.Options,
+	defaultPort int,
+) (*source.Source, error) {
+	spec := OptSSHTunnel.Get(o)
+	if spec == "" {
+		return src, nil
+	}
+
+	u, err := url.Parse(src.Location)
+	if err != nil || location.TypeOf(src.Location) != location.TypeSQL || u.Hostname() == "" {
+		// Don't echo the location: it may hold a resolved secret.
+		return nil, errz.Errorf("%s: %s: not supported for a location without a network host",
+			src.Handle, OptSSHTunnel.Key())
+	}
+
+	port := u.Port()
+	if port == "" {
+		if defaultPort <= 0 {
+			return nil, errz.Errorf("%s: %s: source location must specify the database port",
+				src.Handle, OptSSHTunnel.Key())
+		}
+		port = strconv.Itoa(defaultPort)
+	}
+	remoteAddr := net.JoinHostPort(u.Hostname(), port)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, errz.New("ssh tunnels already closed")
+	}
+
+	clientKey, err := t.client(ctx, src, spec, o)
+	if err != nil {
+		return nil, NewTunnelError(spec, err)
+	}
+
+	ln, err := t.forward(ctx, clientKey, remoteAddr)
+	if err != nil {
+		return nil, NewTunnelError(spec, err)
+	}
+
+	u.Host = ln.Addr().String()
+	clone := src.Clone()
+	clone.Location = u.String()
+	return clone, nil
+}
+
+// client ensures that t.clients holds the SSH client for spec, dialing it
+// if necessary, and returns the client's key. A cached client that fails a
+// keepalive check is closed and redialed.
+func (t *Tunnels) client(ctx context.Context, src *source.Source, spec string, o options.Options) (string,
+	error,
+) {
+	user, addr, err := parseSSHTunnel(spec)
+	if err != nil {
+		return "", err
+	}
+
+	keyVal, err := resolveSecretOpt(ctx, t.secretReg, src, OptSSHKey, o)
+	if err != nil {
+		return "", err
+	}
+	passphrase, err := resolveSecretOpt(ctx, t.secretReg, src, OptSSHKeyPassphrase, o)
+	if err != nil {
+		return "", err
+	}
+
+	knownHostsFile := OptSSHKnownHosts.Get(o)
+	insecure := OptSSHInsecureSkipVerify.Get(o)
+	clientKey := checksum.Sum([]byte(strings.Join([]string{
+		user, addr, keyVal, knownHostsFile, strconv.FormatBool(insecure),
+	}, "\x00")))
+	if client, ok := t.clients[clientKey]; ok {
+		if sshAlive(ctx, client) {
+			return clientKey, nil
+		}
+
+		lg.FromContext(ctx).Warn("SSH tunnel connection is dead: reconnecting", lga.Src, src.Handle, lga.Via, addr)
+		lg.WarnIfError(lg.FromContext(ctx), "Close dead SSH tunnel connection", ignoreNetClosed(client.Close()))
+		delete(t.clients, clientKey)
+	}
+
+	auth, agentConn, err := sshAuthMethods(keyVal, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if agentConn != nil {
+		// The agent is only needed to authenticate, that is, until
+		// ssh.NewClientConn returns.
+		defer lg.WarnIfCloseError(lg.FromContext(ctx), "Close SSH agent conn", agentConn)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec // Only if explicitly configured.
+	if !insecure {
+		if knownHostsFile == "" {
+			home, _ := os.UserHomeDir()
+			knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		if hostKeyCallback, err = knownhosts.New(knownHostsFile); err != nil {
+			return "", errz.Wrapf(err, "load %s (or set %s)", OptSSHKnownHosts.Key(),
+				OptSSHInsecureSkipVerify.Key())
+		}
+	}
+
+	cfg := &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", errz.Err(err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		_ = conn.Close()
+		return "", errz.Err(err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	t.clients[clientKey] = client
+	lg.FromContext(ctx).Debug("SSH tunnel connected", lga.Src, src.Handle, lga.Via, addr)
+	return clientKey, nil
+}
+
+// sshKeepaliveTimeout is how long sshAlive waits for the bastion to reply.
+const sshKeepaliveTimeout = 5 * time.Second
+
+// sshAlive returns true if client's connection is alive, as determined by
+// a keepalive request. The reply to the request doesn't matter, only that
+// there is one.
+func sshAlive(ctx context.Context, client *ssh.Client) bool {
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err == nil
+	case <-ctx.Done():
+		return false
+	case <-time.After(sshKeepaliveTimeout):
+		return false
+	}
+}
+
+// forward returns the local listener that forwards, via the client for
+// clientKey, to remoteAddr, starting it if necessary.
+func (t *Tunnels) forward(ctx context.Context, clientKey, remoteAddr string) (net.Listener, error) {
+	fwdKey := clientKey + "\x00" + remoteAddr
+	if ln, ok := t.forwards[fwdKey]; ok {
+		return ln, nil
+	}
+
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errz.Wrap(err, "listen for port forward")
+	}
+	t.forwards[fwdKey] = ln
+
+	log := lg.FromContext(ctx).With(lga.Target, remoteAddr)
+	log.Debug("SSH tunnel forwarding", lga.From, ln.Addr().String())
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			local, err := ln.Accept()
+			if err != nil {
+				// The listener was closed by Tunnels.Close.
+				return
+			}
+			go t.forwardConn(log, clientKey, local, remoteAddr)
+		}
+	}()
+	return ln, nil
+}
+
+// forwardConn copies data between local and a new connection, via the
+// client for clientKey, to remoteAddr, until either end is closed.
+func (t *Tunnels) forwardConn(log *slog.Logger, clientKey string, local net.Conn, remoteAddr string) {
+	defer lg.WarnIfCloseError(log, "Close SSH tunnel local conn", local)
+
+	t.mu.Lock()
+	client := t.clients[clientKey]
+	t.mu.Unlock()
+	if client == nil {
+		// Tunnels.Close has been invoked.
+		return
+	}
+
+	remote, err := client.Dial("tcp", remoteAddr)
+	if err != nil {
+		log.Warn("SSH tunnel: dial DB via bastion", lga.Err, err)
+		return
+	}
+	defer lg.WarnIfCloseError(log, "Close SSH tunnel remote conn", remote)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// Close closes the local port-forwards and the SSH connections.
+func (t *Tunnels) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	var err error
+	for _, ln := range t.forwards {
+		err = errz.Append(err, ignoreNetClosed(ln.Close()))
+	}
+	t.wg.Wait()
+	for _, client := range t.clients {
+		err = errz.Append(err, ignoreNetClosed(client.Close()))
+	}
+	t.forwards = nil
+	t.clients = nil
+	return err
+}
+
+func ignoreNetClosed(err error) error {
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return errz.Err(err)
+}
+
+// parseSSHTunnel parses an OptSSHTunnel value of the form
+// "user@host[:port]", returning the user and the address "host:port".
+func parseSSHTunnel(s string) (user, addr string, err error) {
+	const key = "ssh.tunnel" // Not key, to avoid an init cycle.
+
+	user, hostPort, ok := strings.Cut(s, "@")
+	if !ok || user == "" || hostPort == "" {
+		return "", "", errz.Errorf("%s: must be in the form user@host[:port]", key)
+	}
+
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		// No port specified.
+		host, port = strings.Trim(hostPort, "[]"), "22"
+	}
+	if host == "" || strings.ContainsAny(host, "/@ ") {
+		return "", "", errz.Errorf("%s: invalid host {%s}", key, hostPort)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", "", errz.Errorf("%s: invalid port {%s}", key, port)
+	}
+	return user, net.JoinHostPort(host, port), nil
+}
+
+// sshAuthMethods returns the SSH auth methods for the key keyVal, which is
+// the path to a PEM key file, or the PEM data itself. If keyVal is empty,
+// the SSH agent and the default key files in ~/.ssh are used. If the
+// agent is used, the returned agentConn is the connection to it, which
+// the caller must close when done authenticating.
+func sshAuthMethods(keyVal, passphrase string) (methods []ssh.AuthMethod, agentConn net.Conn, err error) {
+	if keyVal != "" {
+		signer, err := parseSSHKey(keyVal, passphrase)
+		if err != nil {
+			return nil, nil, errz.Wrap(err, OptSSHKey.Key())
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil, nil
+	}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if agentConn, err = net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+	var signers []ssh.Signer
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		if signer, err := parseSSHKey(filepath.Join(home, ".ssh", name), passphrase); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if len(methods) == 0 {
+		return nil, nil, errz.Errorf("no SSH key: set %s, or use an SSH agent", OptSSHKey.Key())
+	}
+	return methods, agentConn, nil
+}
+
+// parseSSHKey parses the private key keyVal, which is the path to a PEM
+// key file, or the PEM data itself.
+func parseSSHKey(keyVal, passphrase string) (ssh.Signer, error) {
+	data := []byte(keyVal)
+	if !strings.Contains(keyVal, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(keyVal); err != nil {
+			return nil, errz.Err(err)
+		}
+	}
+
+	if passphrase != "" {
+		return errz.Return(ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase)))
+	}
+	return errz.Return(ssh.ParsePrivateKey(data))
+}
+
+// resolveSecretOpt returns the value of opt in o, with any secret
+// references resolved via reg, and any $$ escapes reduced to a literal $.
+func resolveSecretOpt(ctx context.Context, reg *secret.Registry, src *source.Source, opt options.String,
+	o options.Options,
+) (string, error) {
+	val := opt.Get(o)
+	refs, err := secret.ExtractRefs(val)
+	if err != nil {
+		return "", errz.Wrapf(err, "%s: %s: parse placeholders", src.Handle, opt.Key())
+	}
+	if len(refs) == 0 {
+		return secret.Unescape(val), nil
+	}
+
+	if reg == nil {
+		return "", errz.Errorf("%s: %s: resolve placeholders: no secret registry provided",
+			src.Handle, opt.Key())
+	}
+	if val, err = reg.Expand(ctx, val); err != nil {
+		return "", errz.Wrapf(err, "%s: %s", src.Handle, opt.Key())
+	}
+	return val, nil
+}
@@ -0,0 +1,139 @@
+package libsq
+
+import (
+	"context"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// QueryPlan is the result of ExplainSLQ or ExplainSQL: the plan of a
+// query, as reported by the database the query targets, normalized via
+// driver.Explainer.
+type QueryPlan struct {
+	// Plan is the root node of the plan.
+	Plan *driver.PlanNode `json:"plan" yaml:"plan"`
+
+	// SQL is the query that was explained.
+	SQL string `json:"sql" yaml:"sql"`
+
+	// Dialect identifies the SQL dialect / driver type of the target
+	// database. For cross-source queries, this is the join DB's dialect.
+	Dialect drivertype.Type `json:"dialect" yaml:"dialect"`
+
+	// Target is the handle of the source the query targets. For
+	// cross-source queries, this is the synthetic join DB's handle.
+	Target string `json:"target" yaml:"target"`
+
+	// JoinCopies lists the tables that were copied into the join DB
+	// before the query could be explained. It is empty except for
+	// cross-source queries.
+	JoinCopies []JoinCopy `json:"join_copies,omitempty" yaml:"join_copies,omitempty"`
+
+	// Analyze is true if the query was executed, and the plan reports
+	// actual row counts.
+	Analyze bool `json:"analyze" yaml:"analyze"`
+}
+
+// JoinCopy describes a table copy performed for a cross-source join.
+type JoinCopy struct {
+	// From is the source table, e.g. "@sakila_pg.actor".
+	From string `json:"from" yaml:"from"`
+
+	// To is the table in the join DB, e.g. "@join_b7x2.actor".
+	To string `json:"to" yaml:"to"`
+}
+
+// ExplainSLQ returns the plan of the SQL that SLQ query renders to. The
+// pipeline is built as for ExecSLQ. For a cross-source query, the join
+// copy tasks are executed, because the join DB's tables must exist for
+// the query to be planned; the copies are listed in the returned
+// QueryPlan.JoinCopies.
+//
+// If analyze is true, the rendered query is executed, so that the plan
+// reports actual row counts. SLQ queries are always SELECT statements,
+// so it's safe to do so. Not all drivers support analyze.
+func ExplainSLQ(ctx context.Context, qc *QueryContext, query string, analyze bool) (*QueryPlan, error) {
+	p, err := newPipeline(ctx, qc, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var copies []JoinCopy
+	noQuote := func(s string) string { return s }
+	for _, t := range p.tasks {
+		if jt, ok := t.(*joinCopyTask); ok {
+			copies = append(copies, JoinCopy{
+				From: jt.fromGrip.Source().Handle + "." + jt.fromTbl.Render(noQuote),
+				To:   jt.toGrip.Source().Handle + "." + jt.toTbl.Render(noQuote),
+			})
+		}
+	}
+
+	if err = p.executeTasks(ctx); err != nil {
+		return nil, p.targetGrip.SQLDriver().ErrWrapFunc()(err)
+	}
+
+	plan, err := ExplainSQL(ctx, p.targetGrip, p.targetSQL, analyze)
+	if err != nil {
+		return nil, err
+	}
+	plan.JoinCopies = copies
+	return plan, nil
+}
+
+// ExplainSQL returns the plan of SQL query against grip. The grip's
+// driver must implement driver.Explainer. If analyze is true, query is
+// executed, so that the plan reports actual row counts. The query is
+// executed in a tx that is always rolled back, because even a query
+// that looks like a SELECT can modify data, e.g. a Postgres WITH query
+// whose CTE is a DELETE.
+func ExplainSQL(ctx context.Context, grip driver.Grip, query string, analyze bool) (*QueryPlan, error) {
+	src := grip.Source()
+	explainer, ok := grip.SQLDriver().(driver.Explainer)
+	if !ok {
+		return nil, errz.Errorf("explain: driver {%s} does not support query plans", src.Type)
+	}
+
+	log := lg.FromContext(ctx)
+	log.Info("Explain SQL query", lga.Src, src, lga.SQL, query)
+
+	errw := grip.SQLDriver().ErrWrapFunc()
+	db, err := grip.DB(ctx)
+	if err != nil {
+		return nil, errw(err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errw(err)
+	}
+	defer lg.WarnIfCloseError(log, lgm.CloseConn, conn)
+
+	if analyze {
+		// The tx is on conn, so the query executed by the explainer,
+		// also on conn, is part of the tx.
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, errw(err)
+		}
+		defer lg.WarnIfFuncError(log, lgm.TxRollback, tx.Rollback)
+	}
+
+	node, err := explainer.Explain(ctx, conn, query, analyze)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryPlan{
+		Plan:    node,
+		SQL:     query,
+		Dialect: src.Type,
+		Target:  src.Handle,
+		Analyze: analyze,
+	}, nil
+}
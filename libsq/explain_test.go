@@ -0,0 +1,39 @@
+package libsq_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+// TestExplainSQL_analyzeRollback verifies that a query executed by
+// ExplainSQL in order to analyze it has no effect on the data. On some
+// databases, e.g. Postgres, a WITH query can modify data; here, a DELETE
+// stands in for such a query.
+func TestExplainSQL_analyzeRollback(t *testing.T) {
+	th := testh.New(t)
+	src := th.Add(&source.Source{
+		Handle:   "@explain_duckdb",
+		Type:     drivertype.DuckDB,
+		Location: "duckdb://" + filepath.Join(t.TempDir(), "explain.duckdb"),
+	})
+	grip := th.Open(src)
+	db, err := grip.DB(th.Context)
+	require.NoError(t, err)
+	_, err = db.ExecContext(th.Context, `CREATE TABLE t AS SELECT range AS id FROM range(10)`)
+	require.NoError(t, err)
+
+	plan, err := libsq.ExplainSQL(th.Context, grip, `DELETE FROM t WHERE id > 2`, true)
+	require.NoError(t, err)
+	require.True(t, plan.Analyze)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(th.Context, `SELECT count(*) FROM t`).Scan(&count))
+	require.Equal(t, 10, count, "analyzed query should have been rolled back")
+}
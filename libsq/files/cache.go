@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -87,12 +89,7 @@ func (fs *Files) WriteIngestChecksum(ctx context.Context, src, backingSrc *sourc
 	defer fs.mu.Unlock()
 
 	log := lg.FromContext(ctx)
-	ingestFilePath, err := fs.filepath(src)
-	if err != nil {
-		return err
-	}
-
-	if location.TypeOf(src.Location) == location.TypeHTTP {
+	if location.TypeOf(src.Location).IsRemote() {
 		// If the source is remote, check if there was a download,
 		// and if so, make sure it's completed.
 		stream, ok := fs.streams[src.Handle]
@@ -116,11 +113,12 @@ func (fs *Files) WriteIngestChecksum(ctx context.Context, src, backingSrc *sourc
 	}
 
 	// Now, we need to write a checksum file that contains the computed checksum
-	// value from ingestFilePath.
-	var sum checksum.Checksum
-	if sum, err = checksum.ForFile(ingestFilePath); err != nil {
+	// value of each file that the ingest DB derives from. For a multi-file
+	// source, that's every one of its files.
+	var sums map[string]checksum.Checksum
+	if sums, err = fs.ingestChecksums(ctx, src); err != nil {
 		log.Warn("Failed to compute checksum for source file; caching not in effect",
-			lga.Src, src, lga.Dest, backingSrc, lga.Path, ingestFilePath, lga.Err, err)
+			lga.Src, src, lga.Dest, backingSrc, lga.Err, err)
 		return err
 	}
 
@@ -136,9 +134,17 @@ func (fs *Files) WriteIngestChecksum(ctx context.Context, src, backingSrc *sourc
 		return errz.Wrap(err, "write ingest checksum")
 	}
 
-	if err = checksum.WriteFile(checksumsPath, sum, ingestFilePath); err != nil {
+	var buf bytes.Buffer
+	for _, p := range slices.Sorted(maps.Keys(sums)) {
+		if err = checksum.Write(&buf, sums[p], p); err != nil {
+			return err
+		}
+	}
+
+	if err = os.WriteFile(checksumsPath, buf.Bytes(), ioz.RWPerms); err != nil {
+		err = errz.Wrap(err, "write checksum file")
 		log.Warn("Failed to write checksum; file caching not in effect",
-			lga.Src, src, lga.Dest, backingSrc, lga.Path, ingestFilePath, lga.Err, err)
+			lga.Src, src, lga.Dest, backingSrc, lga.Path, checksumsPath, lga.Err, err)
 	}
 	return err
 }
@@ -154,7 +160,7 @@ func (fs *Files) CachedBackingSourceFor(ctx context.Context, src *source.Source)
 	switch location.TypeOf(src.Location) {
 	case location.TypeFile:
 		return fs.cachedBackingSourceForFile(ctx, src)
-	case location.TypeHTTP:
+	case location.TypeHTTP, location.TypeObject:
 		return fs.cachedBackingSourceForRemoteFile(ctx, src)
 	default:
 		return nil, false, errz.Errorf("caching not applicable for source: %s", src.Handle)
@@ -187,23 +193,21 @@ func (fs *Files) cachedBackingSourceForFile(ctx context.Context, src *source.Sou
 		return nil, false, err
 	}
 
-	srcFilepath, err := fs.filepath(src)
+	srcChecksums, err := fs.ingestChecksums(ctx, src)
 	if err != nil {
 		return nil, false, err
 	}
 
-	cachedChecksum, ok := mChecksums[srcFilepath]
-	if !ok {
+	// Every file must match: for a multi-file source, a file that's been
+	// added, removed, or changed since ingest invalidates the cache DB.
+	if len(srcChecksums) != len(mChecksums) {
 		return nil, false, nil
 	}
 
-	srcChecksum, err := checksum.ForFile(srcFilepath)
-	if err != nil {
-		return nil, false, err
-	}
-
-	if srcChecksum != cachedChecksum {
-		return nil, false, nil
+	for fp, srcChecksum := range srcChecksums {
+		if cachedChecksum, ok := mChecksums[fp]; !ok || srcChecksum != cachedChecksum {
+			return nil, false, nil
+		}
 	}
 
 	// The checksums match, so we can use the cached DB,
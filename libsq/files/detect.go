@@ -122,6 +122,16 @@ func (fs *Files) detectType(ctx context.Context, handle, loc string) (typ driver
 	log := lg.FromContext(ctx).With(lga.Loc, loc)
 	start := time.Now()
 
+	if IsMultiFile(loc) {
+		// A multi-file source is detected by its first file: the files
+		// are expected to be of the same type.
+		paths, err := MultiFilePaths(loc)
+		if err != nil {
+			return drivertype.None, false, err
+		}
+		loc = paths[0]
+	}
+
 	var newRdrFn NewReaderFunc
 	if location.TypeOf(loc) == location.TypeFile {
 		newRdrFn = func(_ context.Context) (io.ReadCloser, error) {
@@ -3,6 +3,7 @@ package files
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/files/internal/downloader"
 	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
 )
 
 var (
@@ -128,7 +130,7 @@ func (fs *Files) maybeStartDownload(ctx context.Context, src *source.Source, che
 
 // downloadPaths returns the paths for src's download cache dir and
 // cache body file. It is not guaranteed that the returned paths exist.
-func (fs *Files) downloadPaths(src *source.Source) (dlDir, dlFile string, err error) {
+func (fs *Files) downloadPaths(ctx context.Context, src *source.Source) (dlDir, dlFile string, err error) {
 	var cacheDir string
 	cacheDir, err = fs.CacheDirFor(src)
 	if err != nil {
@@ -138,7 +140,10 @@ func (fs *Files) downloadPaths(src *source.Source) (dlDir, dlFile string, err er
 	// Note: we depend on internal knowledge of the downloader impl here,
 	// which is not great. It might be better to implement a function
 	// in pkg downloader.
-	dlDir = filepath.Join(cacheDir, "download", checksum.Sum([]byte(src.Location)))
+	//
+	// The download dir is keyed on the credentials, as well as the
+	// location. See credentialsKey.
+	dlDir = filepath.Join(cacheDir, "download", checksum.Sum([]byte(src.Location+credentialsKey(ctx, src))))
 	dlFile = filepath.Join(dlDir, "main", "body")
 	return dlDir, dlFile, nil
 }
@@ -151,7 +156,7 @@ func (fs *Files) downloaderFor(ctx context.Context, src *source.Source) (*downlo
 		return dl, nil
 	}
 
-	dlDir, _, err := fs.downloadPaths(src)
+	dlDir, _, err := fs.downloadPaths(ctx, src)
 	if err != nil {
 		return nil, err
 	}
@@ -159,20 +164,91 @@ func (fs *Files) downloaderFor(ctx context.Context, src *source.Source) (*downlo
 		return nil, err
 	}
 
-	c := fs.httpClientFor(ctx, src)
-	if dl, err = downloader.New(src.Handle, c, src.Location, dlDir); err != nil {
+	c, err := fs.httpClientFor(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	dlURL, err := fs.remoteURL(ctx, src, c)
+	if err != nil {
+		return nil, err
+	}
+	if dl, err = downloader.New(src.Handle, c, dlURL, dlDir); err != nil {
 		return nil, err
 	}
 	fs.downloaders[src.Handle] = dl
 	return dl, nil
 }
 
-func (fs *Files) httpClientFor(ctx context.Context, src *source.Source) *http.Client {
+// httpClientFor returns the HTTP client for src, configured with any
+// credentials (headers, bearer token, basic auth, client certificate)
+// specified by src's options.
+func (fs *Files) httpClientFor(ctx context.Context, src *source.Source) (*http.Client, error) {
 	o := options.Merge(options.FromContext(ctx), src.Options)
-	return httpz.NewClient(
+
+	// The credentials are only sent to src's host, and not, for example,
+	// to the target of a redirect to another host.
+	var (
+		host    string
+		objTrip httpz.TripFunc
+	)
+	if location.TypeOf(src.Location) == location.TypeObject {
+		obj, cfg, err := fs.objectConfigFor(ctx, src, o)
+		if err != nil {
+			return nil, err
+		}
+		if host, err = cfg.Host(obj); err != nil {
+			return nil, err
+		}
+		objTrip = cfg.TripFunc(obj.Scheme)
+	} else if u, err := url.Parse(src.Location); err == nil {
+		host = u.Host
+	}
+
+	authOpts, err := fs.httpAuthOpts(ctx, src, o, host)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []httpz.Opt{
 		httpz.DefaultUserAgent,
 		httpz.OptRequestTimeout(OptHTTPRequestTimeout.Get(o)),
 		httpz.OptResponseTimeout(OptHTTPResponseTimeout.Get(o)),
 		httpz.OptInsecureSkipVerify(OptHTTPSInsecureSkipVerify.Get(o)),
-	)
+	}
+	opts = append(opts, authOpts...)
+
+	if objTrip != nil {
+		// The object store request signing must be last, so that it's
+		// applied after any other TripFunc has modified the request.
+		opts = append(opts, httpz.OptForHost(host, objTrip))
+	}
+
+	return httpz.NewClient(opts...), nil
+}
+
+// HTTPClientFor returns an HTTP client for src, configured per the
+// source's HTTP options, such as its request timeouts, headers, and
+// credentials (e.g. http.auth.bearer). It's for drivers that make their
+// own requests to src, rather than downloading it via Files.NewReader.
+func (fs *Files) HTTPClientFor(ctx context.Context, src *source.Source) (*http.Client, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.httpClientFor(ctx, src)
+}
+
+// remoteURL returns the HTTP URL from which src is downloaded. For an
+// object store location, such as "s3://bucket/key", that's the object's
+// URL, as resolved via client c. Otherwise, it's src.Location.
+func (fs *Files) remoteURL(ctx context.Context, src *source.Source, c *http.Client) (string, error) {
+	if location.TypeOf(src.Location) != location.TypeObject {
+		return src.Location, nil
+	}
+
+	o := options.Merge(options.FromContext(ctx), src.Options)
+	obj, cfg, err := fs.objectConfigFor(ctx, src, o)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Resolve(ctx, c, obj)
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/lg/lgt"
 	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/core/secret/env"
 	"github.com/neilotoole/sq/libsq/files"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
@@ -468,3 +470,167 @@ func TestFiles_Ping_HTTP_BadRequestURL(t *testing.T) {
 	src := &source.Source{Handle: "@remote", Type: drivertype.CSV, Location: "http://\x7f/x.csv"}
 	require.Error(t, fs.Ping(ctx, src))
 }
+
+// TestFiles_NewReader_HTTP_Auth verifies that the HTTP credential options
+// are sent with the download request, with secret references resolved.
+func TestFiles_NewReader_HTTP_Auth(t *testing.T) {
+	const body = "a,b\n1,2\n"
+	t.Setenv("SQ_TEST_HTTP_TOKEN", "tok123")
+
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok123" || r.Header.Get("X-Api-Key") != "k1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srvr.Close)
+
+	newSrc := func() *source.Source {
+		return &source.Source{
+			Handle:   "@remote",
+			Type:     drivertype.CSV,
+			Location: srvr.URL,
+			Options: options.Options{
+				files.OptHTTPAuthBearer.Key(): "${env:SQ_TEST_HTTP_TOKEN}",
+				files.OptHTTPHeaders.Key():    "X-Api-Key: k1",
+			},
+		}
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		ctx, fs := newTestFiles(t)
+		t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+		reg := secret.NewRegistry()
+		reg.Register("env", env.NewResolver())
+		fs.SetSecretRegistry(reg)
+
+		r, err := fs.NewReader(ctx, newSrc(), false)
+		require.NoError(t, err)
+		got, err := readAllAndClose(t, r)
+		require.NoError(t, err)
+		require.Equal(t, body, string(got))
+		require.NoError(t, fs.Ping(ctx, newSrc()))
+	})
+
+	t.Run("no_registry", func(t *testing.T) {
+		ctx, fs := newTestFiles(t)
+		t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+		_, err := fs.NewReader(ctx, newSrc(), false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), files.OptHTTPAuthBearer.Key())
+	})
+}
+
+// TestFiles_NewReader_HTTP_AuthRedirect verifies that the HTTP credential
+// options are not sent to another host that the source redirects to, such
+// as a server of presigned URLs.
+func TestFiles_NewReader_HTTP_AuthRedirect(t *testing.T) {
+	const body = "a,b\n1,2\n"
+
+	var gotHeader http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(other.Close)
+
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok123" || r.Header.Get("X-Api-Key") != "k1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, other.URL+"/x.csv", http.StatusFound)
+	}))
+	t.Cleanup(srvr.Close)
+
+	ctx, fs := newTestFiles(t)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+	src := &source.Source{
+		Handle:   "@remote",
+		Type:     drivertype.CSV,
+		Location: srvr.URL + "/x.csv",
+		Options: options.Options{
+			files.OptHTTPAuthBearer.Key(): "tok123",
+			files.OptHTTPHeaders.Key():    "X-Api-Key: k1",
+		},
+	}
+
+	r, err := fs.NewReader(ctx, src, false)
+	require.NoError(t, err)
+	got, err := readAllAndClose(t, r)
+	require.NoError(t, err)
+	require.Equal(t, body, string(got))
+	require.NotNil(t, gotHeader, "redirect target should have been requested")
+	require.Empty(t, gotHeader.Get("Authorization"))
+	require.Empty(t, gotHeader.Get("X-Api-Key"))
+}
+
+// TestFiles_NewReader_ObjectStore verifies that an s3:// source is
+// downloaded, with signed requests, from an S3-compatible endpoint, such as
+// MinIO, and that a glob key resolves to the latest matching object.
+func TestFiles_NewReader_ObjectStore(t *testing.T) {
+	const (
+		body = "a,b\n1,2\n"
+		etag = `"etag-1"`
+	)
+	t.Setenv("SQ_TEST_S3_SECRET", "secret123")
+
+	srvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKTEST/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/bkt" && r.URL.Query().Get("list-type") == "2":
+			_, _ = w.Write([]byte(`<ListBucketResult>
+<Contents><Key>exports/old.csv</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified></Contents>
+<Contents><Key>exports/data.csv</Key><LastModified>2024-02-01T00:00:00.000Z</LastModified></Contents>
+</ListBucketResult>`))
+		case r.URL.Path == "/bkt/exports/data.csv":
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srvr.Close)
+
+	for _, loc := range []string{"s3://bkt/exports/data.csv", "s3://bkt/exports/*.csv"} {
+		t.Run(loc, func(t *testing.T) {
+			ctx, fs := newTestFiles(t)
+			t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+			reg := secret.NewRegistry()
+			reg.Register("env", env.NewResolver())
+			fs.SetSecretRegistry(reg)
+
+			src := &source.Source{
+				Handle:   "@s3",
+				Type:     drivertype.CSV,
+				Location: loc,
+				Options: options.Options{
+					files.OptS3Endpoint.Key():        srvr.URL,
+					files.OptS3Region.Key():          "us-east-1",
+					files.OptS3AccessKeyID.Key():     "AKTEST",
+					files.OptS3SecretAccessKey.Key(): "${env:SQ_TEST_S3_SECRET}",
+				},
+			}
+
+			r, err := fs.NewReader(ctx, src, false)
+			require.NoError(t, err)
+			got, err := readAllAndClose(t, r)
+			require.NoError(t, err)
+			require.Equal(t, body, string(got))
+			require.NoError(t, fs.Ping(ctx, src))
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/lg/lga"
 	"github.com/neilotoole/sq/libsq/core/lg/lgm"
 	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/secret"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/core/tuning"
 	"github.com/neilotoole/sq/libsq/files/internal/downloader"
@@ -67,6 +68,10 @@ type Files struct {
 	// call to check the freshness of an already downloaded file).
 	downloadedFiles map[string]string
 
+	// secretReg resolves secret references in the HTTP credential
+	// options. It may be nil. See Files.SetSecretRegistry.
+	secretReg *secret.Registry
+
 	// cfgLockFn is the lock func for sq's config.
 	cfgLockFn lockfile.LockFunc
 
@@ -130,6 +135,22 @@ func New(ctx context.Context, optReg *options.Registry, cfgLock lockfile.LockFun
 func (fs *Files) Filesize(ctx context.Context, src *source.Source) (size int64, err error) {
 	switch location.TypeOf(src.Location) {
 	case location.TypeFile:
+		if IsMultiFile(src.Location) {
+			// The size of a multi-file source is the sum of its files.
+			var paths []string
+			if paths, err = MultiFilePaths(src.Location); err != nil {
+				return 0, err
+			}
+			for _, p := range paths {
+				var n int64
+				if n, err = ioz.Filesize(p); err != nil {
+					return 0, err
+				}
+				size += n
+			}
+			return size, nil
+		}
+
 		var fi os.FileInfo
 		if fi, err = os.Stat(src.Location); err != nil {
 			return 0, errz.Err(err)
@@ -150,7 +171,7 @@ func (fs *Files) Filesize(ctx context.Context, src *source.Source) (size int64,
 		}
 		return int64(total), nil
 
-	case location.TypeHTTP:
+	case location.TypeHTTP, location.TypeObject:
 		fs.mu.Lock()
 
 		// First check if the file is already downloaded
@@ -222,12 +243,12 @@ func (fs *Files) AddStdin(ctx context.Context, f *os.File) error {
 // SQL driver). If src is a remote (http) location, the returned filepath
 // is that of the cached download file. It's not guaranteed that that
 // file exists.
-func (fs *Files) filepath(src *source.Source) (string, error) {
+func (fs *Files) filepath(ctx context.Context, src *source.Source) (string, error) {
 	switch location.TypeOf(src.Location) {
 	case location.TypeFile:
 		return src.Location, nil
-	case location.TypeHTTP:
-		_, dlFile, err := fs.downloadPaths(src)
+	case location.TypeHTTP, location.TypeObject:
+		_, dlFile, err := fs.downloadPaths(ctx, src)
 		if err != nil {
 			return "", err
 		}
@@ -272,6 +293,11 @@ func (fs *Files) newReader(ctx context.Context, src *source.Source, finalRdr boo
 	case location.TypeSQL:
 		return nil, errz.Errorf("invalid to read SQL source: %s", loc)
 	case location.TypeFile:
+		if IsMultiFile(loc) {
+			// Each of the files is read individually, via a source whose
+			// location is that file.
+			return nil, errz.Errorf("invalid to read multi-file source directly: %s", src.Handle)
+		}
 		return errz.Return(os.Open(loc))
 	case location.TypeStdin:
 		stdinStream, ok := fs.streams[source.StdinHandle]
@@ -336,18 +362,34 @@ func (fs *Files) Ping(ctx context.Context, src *source.Source) error {
 		// Stdin is always available.
 		return nil
 	case location.TypeFile:
+		if IsMultiFile(src.Location) {
+			if _, err := MultiFilePaths(src.Location); err != nil {
+				return errz.Wrapf(err, "ping: %s", src.Handle)
+			}
+			return nil
+		}
+
 		if _, err := os.Stat(src.Location); err != nil {
 			return errz.Wrapf(err, "ping: failed to stat file source %s: %s", src.Handle, src.Location)
 		}
 		return nil
 
-	case location.TypeHTTP:
-		req, err := http.NewRequestWithContext(ctx, http.MethodHead, src.Location, nil)
+	case location.TypeHTTP, location.TypeObject:
+		c, err := fs.httpClientFor(ctx, src)
+		if err != nil {
+			return errz.Wrapf(err, "ping: %s", src.Handle)
+		}
+
+		dlURL, err := fs.remoteURL(ctx, src, c)
+		if err != nil {
+			return errz.Wrapf(err, "ping: %s", src.Handle)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, dlURL, nil)
 		if err != nil {
 			return errz.Wrapf(err, "ping: %s", src.Handle)
 		}
 
-		c := fs.httpClientFor(ctx, src)
 		resp, err := c.Do(req) //nolint:bodyclose
 		if err != nil {
 			return errz.Wrapf(err, "ping: %s", src.Handle)
@@ -0,0 +1,280 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/checksum"
+	"github.com/neilotoole/sq/libsq/core/ioz/httpz"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/secret"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+var (
+	OptHTTPHeaders = options.NewString(
+		"http.headers",
+		nil,
+		"",
+		func(s string) error {
+			_, err := parseHTTPHeaders(s)
+			return err
+		},
+		"HTTP/S request headers",
+		`Semicolon-separated list of "Name: value" request headers sent when
+downloading a remote document source. A header value can't contain a
+semicolon. Values may contain secret references, which are resolved at
+download time, so the secret never lands in the config file:
+
+  $ sq config set --src @sales http.headers 'X-Api-Key: ${keyring:sales/key}'`,
+		options.TagSource,
+	)
+	OptHTTPAuthBearer = options.NewString(
+		"http.auth.bearer",
+		nil,
+		"",
+		nil,
+		"HTTP/S bearer token",
+		`Bearer token sent in the Authorization header when downloading a remote
+document source. Typically a secret reference, e.g. ${env:SALES_TOKEN}.
+
+Contrast with http.auth.basic.`,
+		options.TagSource,
+	)
+	OptHTTPAuthBasic = options.NewString(
+		"http.auth.basic",
+		nil,
+		"",
+		nil,
+		"HTTP/S basic auth credentials",
+		`Credentials, in the form "user:password", for HTTP basic auth when
+downloading a remote document source. Typically the password part is a
+secret reference, e.g. alice:${keyring:sales/alice}.
+
+Contrast with http.auth.bearer.`,
+		options.TagSource,
+	)
+	OptHTTPSClientCert = options.NewString(
+		"https.client-cert",
+		nil,
+		"",
+		nil,
+		"HTTPS TLS client certificate",
+		`TLS client certificate presented when downloading a remote document
+source. The value is the path to a PEM file, or the PEM data itself (e.g. via
+a secret reference such as ${keyring:sales/cert}). If https.client-key is not
+set, the private key is read from the certificate PEM.`,
+		options.TagSource,
+	)
+	OptHTTPSClientKey = options.NewString(
+		"https.client-key",
+		nil,
+		"",
+		nil,
+		"HTTPS TLS client private key",
+		`Private key for https.client-cert. The value is the path to a PEM file,
+or the PEM data itself (e.g. via a secret reference such as
+${keyring:sales/key}).`,
+		options.TagSource,
+	)
+)
+
+// httpCredentialOpts are the options that carry HTTP credentials. Their
+// values may contain secret references.
+var httpCredentialOpts = []options.String{
+	OptHTTPHeaders,
+	OptHTTPAuthBearer,
+	OptHTTPAuthBasic,
+	OptHTTPSClientCert,
+	OptHTTPSClientKey,
+}
+
+// SetSecretRegistry sets the registry used to resolve secret references
+// in the HTTP credential options, such as http.auth.bearer. It may be nil,
+// in which case using a secret reference in those options is an error.
+func (fs *Files) SetSecretRegistry(reg *secret.Registry) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.secretReg = reg
+}
+
+// httpAuthOpts returns the httpz options that apply the credentials
+// configured in o, with any secret references resolved. The headers are
+// only sent to host, which is the host of src's location.
+func (fs *Files) httpAuthOpts(ctx context.Context, src *source.Source, o options.Options, host string,
+) ([]httpz.Opt, error) {
+	var opts []httpz.Opt
+
+	val, err := fs.resolveSecretOpt(ctx, src, OptHTTPHeaders, o)
+	if err != nil {
+		return nil, err
+	}
+	hdrs, err := parseHTTPHeaders(val)
+	if err != nil {
+		return nil, errz.Wrapf(err, "%s: %s", src.Handle, OptHTTPHeaders.Key())
+	}
+
+	if val, err = fs.resolveSecretOpt(ctx, src, OptHTTPAuthBasic, o); err != nil {
+		return nil, err
+	}
+	if val != "" {
+		if !strings.Contains(val, ":") {
+			return nil, errz.Errorf("%s: %s: must be in the form user:password",
+				src.Handle, OptHTTPAuthBasic.Key())
+		}
+		hdrs.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(val)))
+	}
+
+	if val, err = fs.resolveSecretOpt(ctx, src, OptHTTPAuthBearer, o); err != nil {
+		return nil, err
+	}
+	if val != "" {
+		hdrs.Set("Authorization", "Bearer "+val)
+	}
+
+	if len(hdrs) > 0 {
+		opts = append(opts, httpz.OptForHost(host, httpz.OptHeaders(hdrs)))
+	}
+
+	certVal, err := fs.resolveSecretOpt(ctx, src, OptHTTPSClientCert, o)
+	if err != nil {
+		return nil, err
+	}
+	keyVal, err := fs.resolveSecretOpt(ctx, src, OptHTTPSClientKey, o)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case certVal != "":
+		cert, err := loadClientCert(certVal, keyVal)
+		if err != nil {
+			return nil, errz.Wrapf(err, "%s: %s", src.Handle, OptHTTPSClientCert.Key())
+		}
+		opts = append(opts, httpz.OptClientCert{Cert: cert})
+	case keyVal != "":
+		return nil, errz.Errorf("%s: %s is set, but %s is not",
+			src.Handle, OptHTTPSClientKey.Key(), OptHTTPSClientCert.Key())
+	}
+
+	return opts, nil
+}
+
+// resolveSecretOpt returns the value of opt in o, with any secret
+// references resolved via fs.secretReg, and any $$ escapes reduced to a
+// literal $. See also: driver.ResolveSourceSecrets.
+func (fs *Files) resolveSecretOpt(ctx context.Context, src *source.Source, opt options.String,
+	o options.Options,
+) (string, error) {
+	val := opt.Get(o)
+	refs, err := secret.ExtractRefs(val)
+	if err != nil {
+		return "", errz.Wrapf(err, "%s: %s: parse placeholders", src.Handle, opt.Key())
+	}
+	if len(refs) == 0 {
+		return secret.Unescape(val), nil
+	}
+
+	if fs.secretReg == nil {
+		return "", errz.Errorf("%s: %s: resolve placeholders: no secret registry provided",
+			src.Handle, opt.Key())
+	}
+	if val, err = fs.secretReg.Expand(ctx, val); err != nil {
+		return "", errz.Wrapf(err, "%s: %s", src.Handle, opt.Key())
+	}
+	return val, nil
+}
+
+// credentialsKey returns a key identifying the credentials configured
+// for src, for use in the download cache path: a source's downloads are
+// cached separately per credentials, so that data fetched with one set of
+// credentials is never served for another. The key is computed from the
+// unresolved option values: when a secret referenced by those values is
+// rotated, the key, and thus the cached download, is unchanged. If no
+// credentials are configured, the key is empty.
+//
+// As with httpClientFor, the options are src's options merged over the
+// base config options on ctx, as a credential may be set in either.
+func credentialsKey(ctx context.Context, src *source.Source) string {
+	if src == nil {
+		return ""
+	}
+
+	o := options.Merge(options.FromContext(ctx), src.Options)
+	buf := bytes.Buffer{}
+	for _, opt := range append(httpCredentialOpts, objectCredentialOpts...) {
+		if !opt.IsSet(o) {
+			continue
+		}
+		buf.WriteString(opt.Key())
+		buf.WriteByte('=')
+		buf.WriteString(opt.Get(o))
+		buf.WriteByte('\n')
+	}
+
+	if buf.Len() == 0 {
+		return ""
+	}
+	return checksum.Sum(buf.Bytes())
+}
+
+// parseHTTPHeaders parses the value of OptHTTPHeaders.
+func parseHTTPHeaders(s string) (http.Header, error) {
+	hdrs := http.Header{}
+	for entry := range strings.SplitSeq(s, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		name, val, ok := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			// Don't echo the entry: it may hold a resolved secret.
+			return nil, errz.New("invalid header: must be in the form 'Name: value'")
+		}
+		hdrs.Add(name, strings.TrimSpace(val))
+	}
+	return hdrs, nil
+}
+
+// loadClientCert loads the TLS client certificate from certVal and keyVal,
+// each of which is the path to a PEM file, or the PEM data itself. If
+// keyVal is empty, the private key is read from the certificate PEM.
+func loadClientCert(certVal, keyVal string) (*tls.Certificate, error) {
+	certPEM, err := readPEM(certVal)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM := certPEM
+	if keyVal != "" {
+		if keyPEM, err = readPEM(keyVal); err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errz.Err(err)
+	}
+	return &cert, nil
+}
+
+// readPEM returns val if it is PEM data, or else the contents of the file
+// at path val.
+func readPEM(val string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(val), "-----BEGIN") {
+		return []byte(val), nil
+	}
+
+	b, err := os.ReadFile(val)
+	if err != nil {
+		return nil, errz.Err(err)
+	}
+	return b, nil
+}
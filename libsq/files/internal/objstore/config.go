@@ -0,0 +1,196 @@
+package objstore
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/httpz"
+)
+
+// Config is the configuration for accessing an object store. A zero
+// field is populated from the environment by Config.WithEnv.
+type Config struct {
+	// Endpoint is the base URL of the store's API. For S3, if empty,
+	// the AWS endpoint for Region is used. A custom S3 endpoint, such
+	// as that of a MinIO server, is addressed path-style, that is,
+	// https://endpoint/bucket/key. For GCS, if empty, the Google endpoint
+	// is used. For Azure, if empty, the endpoint of Account is used.
+	Endpoint string
+
+	// Region is the S3 region.
+	Region string
+
+	// Profile is the AWS shared config profile that supplies the S3
+	// credentials and region, if they are not otherwise specified.
+	Profile string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the S3
+	// credentials. If AccessKeyID is empty, S3 requests are anonymous.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Token is the GCS OAuth2 bearer token. If empty, GCS requests are
+	// anonymous.
+	Token string
+
+	// Account is the Azure storage account name.
+	Account string
+
+	// SASToken is the Azure shared access signature query string. If
+	// empty, Azure requests are anonymous.
+	SASToken string
+}
+
+// azureVersion is the Azure Blob Storage REST API version.
+const azureVersion = "2021-08-06"
+
+// WithEnv returns a copy of cfg, with any empty fields relevant to scheme
+// populated from the standard environment variables of the store's own
+// tooling, and, for S3, from the AWS shared credentials and config files.
+func (cfg Config) WithEnv(scheme string) Config {
+	switch scheme {
+	case SchemeS3:
+		cfg.Endpoint = firstNonEmpty(cfg.Endpoint, os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL"))
+		cfg.Profile = firstNonEmpty(cfg.Profile, os.Getenv("AWS_PROFILE"), "default")
+		if cfg.AccessKeyID == "" {
+			cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+			cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+			cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+		if cfg.AccessKeyID == "" {
+			prof := loadAWSProfile(awsCredentialsFile(), cfg.Profile, false)
+			cfg.AccessKeyID = prof["aws_access_key_id"]
+			cfg.SecretAccessKey = prof["aws_secret_access_key"]
+			cfg.SessionToken = prof["aws_session_token"]
+		}
+		cfg.Region = firstNonEmpty(cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+		if cfg.Region == "" {
+			cfg.Region = firstNonEmpty(loadAWSProfile(awsConfigFile(), cfg.Profile, true)["region"], "us-east-1")
+		}
+	case SchemeGCS:
+		if host := os.Getenv("STORAGE_EMULATOR_HOST"); cfg.Endpoint == "" && host != "" {
+			cfg.Endpoint = host
+			if !strings.Contains(host, "://") {
+				cfg.Endpoint = "http://" + host
+			}
+		}
+		cfg.Token = firstNonEmpty(cfg.Token, os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"))
+	case SchemeAzure:
+		cfg.Account = firstNonEmpty(cfg.Account, os.Getenv("AZURE_STORAGE_ACCOUNT"))
+		cfg.SASToken = firstNonEmpty(cfg.SASToken, os.Getenv("AZURE_STORAGE_SAS_TOKEN"))
+	default:
+	}
+	return cfg
+}
+
+// ObjectURL returns the HTTP URL of obj, which must not be a glob.
+func (cfg Config) ObjectURL(obj *Object) (string, error) {
+	base, err := cfg.baseURL(obj)
+	if err != nil {
+		return "", err
+	}
+	return base.JoinPath(obj.Key).String(), nil
+}
+
+// Host returns the host, such as "bucket.s3.amazonaws.com", to which the
+// requests for obj are made.
+func (cfg Config) Host(obj *Object) (string, error) {
+	base, err := cfg.baseURL(obj)
+	if err != nil {
+		return "", err
+	}
+	return base.Host, nil
+}
+
+// baseURL returns the URL that object keys of obj's bucket are relative to.
+func (cfg Config) baseURL(obj *Object) (*url.URL, error) {
+	var base string
+	switch obj.Scheme {
+	case SchemeS3:
+		switch {
+		case cfg.Endpoint != "":
+			base = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + obj.Bucket
+		case cfg.Region == "us-east-1":
+			base = "https://" + obj.Bucket + ".s3.amazonaws.com"
+		default:
+			base = "https://" + obj.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+		}
+	case SchemeGCS:
+		base = firstNonEmpty(cfg.Endpoint, "https://storage.googleapis.com")
+		base = strings.TrimSuffix(base, "/") + "/" + obj.Bucket
+	case SchemeAzure:
+		switch {
+		case cfg.Endpoint != "":
+			base = cfg.Endpoint
+		case cfg.Account != "":
+			base = "https://" + cfg.Account + ".blob.core.windows.net"
+		default:
+			return nil, errz.Errorf("%s: no Azure storage account specified", obj)
+		}
+		base = strings.TrimSuffix(base, "/") + "/" + obj.Bucket
+	default:
+		return nil, errz.Errorf("unsupported object store scheme {%s}", obj.Scheme)
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, errz.Wrapf(err, "%s: invalid endpoint", obj)
+	}
+	return u, nil
+}
+
+// TripFunc returns the httpz.TripFunc that authenticates requests to the
+// store identified by scheme. If cfg has no credentials for the store, the
+// requests are anonymous, which is fine for public buckets. The caller
+// should restrict the TripFunc to the store's host via httpz.OptForHost;
+// see Config.Host.
+func (cfg Config) TripFunc(scheme string) httpz.TripFunc {
+	switch scheme {
+	case SchemeS3:
+		if cfg.AccessKeyID == "" {
+			return httpz.NopTripFunc
+		}
+		return func(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			signV4(req, cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken, cfg.Region, time.Now())
+			return next.RoundTrip(req)
+		}
+	case SchemeGCS:
+		if cfg.Token == "" {
+			return httpz.NopTripFunc
+		}
+		return httpz.OptHeaders(http.Header{"Authorization": {"Bearer " + cfg.Token}})
+	case SchemeAzure:
+		sas, _ := url.ParseQuery(strings.TrimPrefix(cfg.SASToken, "?"))
+		return func(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Ms-Version", azureVersion)
+			if len(sas) > 0 {
+				// The SAS is added here, rather than to the object URL,
+				// so that it's not logged, or persisted by the downloader.
+				q := req.URL.Query()
+				for k, vals := range sas {
+					q[k] = vals
+				}
+				req.URL.RawQuery = q.Encode()
+			}
+			return next.RoundTrip(req)
+		}
+	default:
+		return httpz.NopTripFunc
+	}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
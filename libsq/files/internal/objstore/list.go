@@ -0,0 +1,218 @@
+package objstore
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz/httpz"
+)
+
+// Entry is an object listed by List.
+type Entry struct {
+	// Modified is the object's last modification time.
+	Modified time.Time
+
+	// Key is the object's key.
+	Key string
+}
+
+// Resolve returns the HTTP URL of obj. If obj is a glob, the matching
+// objects are listed via c, and the URL of the most recently modified
+// match is returned: for example, "s3://bucket/exports/*.csv" resolves to
+// the latest export. It is an error if there's no match.
+//
+// Note that a glob selects a single object, not the set of matching objects:
+// unlike a local glob (see files.IsMultiFile), the matches are not ingested
+// and unioned. And because the latest match can change at any time, the
+// objects are listed on each call, that is, each time the source is opened.
+//
+// The client c must authenticate its requests, via Config.TripFunc.
+func (cfg Config) Resolve(ctx context.Context, c *http.Client, obj *Object) (string, error) {
+	if !obj.IsGlob() {
+		return cfg.ObjectURL(obj)
+	}
+
+	entries, err := cfg.List(ctx, c, obj)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *Entry
+	for i := range entries {
+		e := &entries[i]
+		if latest == nil || e.Modified.After(latest.Modified) ||
+			(e.Modified.Equal(latest.Modified) && e.Key > latest.Key) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return "", errz.Errorf("%s: no matching object", obj)
+	}
+
+	return cfg.ObjectURL(&Object{Scheme: obj.Scheme, Bucket: obj.Bucket, Key: latest.Key})
+}
+
+// List returns the objects that match obj, whose key may be a glob. The
+// store is queried for the objects having obj.Prefix, and the results are
+// filtered via obj.Match.
+func (cfg Config) List(ctx context.Context, c *http.Client, obj *Object) ([]Entry, error) {
+	base, err := cfg.baseURL(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		entries []Entry
+		token   string
+	)
+	for {
+		var page []Entry
+		switch obj.Scheme {
+		case SchemeS3:
+			page, token, err = listS3Page(ctx, c, base, obj.Prefix(), token)
+		case SchemeGCS:
+			page, token, err = listGCSPage(ctx, c, base, obj.Prefix(), token)
+		default:
+			page, token, err = listAzurePage(ctx, c, base, obj.Prefix(), token)
+		}
+		if err != nil {
+			return nil, errz.Wrapf(err, "%s: list objects", obj)
+		}
+
+		for _, e := range page {
+			if obj.Match(e.Key) {
+				entries = append(entries, e)
+			}
+		}
+		if token == "" {
+			return entries, nil
+		}
+	}
+}
+
+// listS3Page returns a page of objects, via S3 ListObjectsV2.
+func listS3Page(ctx context.Context, c *http.Client, base *url.URL, prefix, token string) ([]Entry,
+	string, error,
+) {
+	q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if token != "" {
+		q.Set("continuation-token", token)
+	}
+
+	var result struct {
+		NextContinuationToken string `xml:"NextContinuationToken"`
+		Contents              []struct {
+			LastModified time.Time `xml:"LastModified"`
+			Key          string    `xml:"Key"`
+		} `xml:"Contents"`
+		IsTruncated bool `xml:"IsTruncated"`
+	}
+	if err := getList(ctx, c, base, q, func(r io.Reader) error {
+		return xml.NewDecoder(r).Decode(&result)
+	}); err != nil {
+		return nil, "", err
+	}
+
+	entries := make([]Entry, len(result.Contents))
+	for i, item := range result.Contents {
+		entries[i] = Entry{Key: item.Key, Modified: item.LastModified}
+	}
+	if !result.IsTruncated {
+		return entries, "", nil
+	}
+	return entries, result.NextContinuationToken, nil
+}
+
+// listGCSPage returns a page of objects, via the GCS JSON API.
+func listGCSPage(ctx context.Context, c *http.Client, base *url.URL, prefix, token string) ([]Entry,
+	string, error,
+) {
+	// The JSON API is at /storage/v1/b/BUCKET/o, whereas base is
+	// the XML API bucket URL, /BUCKET.
+	u := *base
+	u.Path = "/storage/v1/b" + u.Path + "/o"
+	q := url.Values{"prefix": {prefix}}
+	if token != "" {
+		q.Set("pageToken", token)
+	}
+
+	var result struct {
+		NextPageToken string `json:"nextPageToken"`
+		Items         []struct {
+			Updated time.Time `json:"updated"`
+			Name    string    `json:"name"`
+		} `json:"items"`
+	}
+	if err := getList(ctx, c, &u, q, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&result)
+	}); err != nil {
+		return nil, "", err
+	}
+
+	entries := make([]Entry, len(result.Items))
+	for i, item := range result.Items {
+		entries[i] = Entry{Key: item.Name, Modified: item.Updated}
+	}
+	return entries, result.NextPageToken, nil
+}
+
+// listAzurePage returns a page of blobs, via Azure List Blobs.
+func listAzurePage(ctx context.Context, c *http.Client, base *url.URL, prefix, token string) ([]Entry,
+	string, error,
+) {
+	q := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+	if token != "" {
+		q.Set("marker", token)
+	}
+
+	var result struct {
+		NextMarker string `xml:"NextMarker"`
+		Blobs      []struct {
+			Name         string `xml:"Name"`
+			LastModified string `xml:"Properties>Last-Modified"`
+		} `xml:"Blobs>Blob"`
+	}
+	if err := getList(ctx, c, base, q, func(r io.Reader) error {
+		return xml.NewDecoder(r).Decode(&result)
+	}); err != nil {
+		return nil, "", err
+	}
+
+	entries := make([]Entry, len(result.Blobs))
+	for i, blob := range result.Blobs {
+		entries[i] = Entry{Key: blob.Name}
+		if t, err := http.ParseTime(blob.LastModified); err == nil {
+			entries[i].Modified = t
+		}
+	}
+	return entries, result.NextMarker, nil
+}
+
+// getList performs a GET of u with query q, and decodes the response
+// body via decodeFn.
+func getList(ctx context.Context, c *http.Client, u *url.URL, q url.Values, decodeFn func(io.Reader) error) error {
+	listURL := *u
+	listURL.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL.String(), nil)
+	if err != nil {
+		return errz.Err(err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return errz.Err(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return errz.Errorf("expected {%s} but got {%s}",
+			httpz.StatusText(http.StatusOK), httpz.StatusText(resp.StatusCode))
+	}
+	return errz.Err(decodeFn(resp.Body))
+}
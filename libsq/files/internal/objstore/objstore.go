@@ -0,0 +1,115 @@
+// Package objstore implements access to objects in cloud object stores,
+// namely Amazon S3 (and S3-compatible stores such as MinIO), Google Cloud
+// Storage, and Azure Blob Storage, over their HTTP APIs.
+//
+// An object store location, such as "s3://bucket/path/data.csv", is
+// resolved to an HTTPS URL, and the requests for that URL are
+// authenticated by the TripFunc returned by Config.TripFunc. Thus an
+// object can be downloaded by any HTTP client, in particular by
+// the files package's downloader, which gives object store sources the
+// same disk cache and freshness validation (ETag and If-None-Match) as
+// plain HTTP sources.
+package objstore
+
+import (
+	"path"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// Object store location schemes.
+const (
+	SchemeS3    = "s3"
+	SchemeGCS   = "gs"
+	SchemeAzure = "az"
+)
+
+// IsLocation returns true if loc has an object store scheme, such as
+// "s3://".
+func IsLocation(loc string) bool {
+	for _, scheme := range []string{SchemeS3, SchemeGCS, SchemeAzure} {
+		if strings.HasPrefix(loc, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// Object identifies an object, or, if Key is a glob, a set of objects,
+// in an object store.
+type Object struct {
+	// Scheme is one of SchemeS3, SchemeGCS or SchemeAzure.
+	Scheme string
+
+	// Bucket is the bucket name. For Azure, this is the container name.
+	Bucket string
+
+	// Key is the object key, without leading slash. It may be a glob,
+	// e.g. "exports/*.csv". See IsGlob.
+	Key string
+}
+
+// String returns the object's location, e.g. "s3://bucket/key".
+func (o *Object) String() string {
+	return o.Scheme + "://" + o.Bucket + "/" + o.Key
+}
+
+// IsGlob returns true if o.Key contains glob metacharacters, as
+// understood by path.Match.
+func (o *Object) IsGlob() bool {
+	return strings.ContainsAny(o.Key, "*?[")
+}
+
+// Prefix returns the literal prefix of o.Key that precedes the first
+// glob metacharacter. If o.Key is not a glob, Prefix returns o.Key.
+func (o *Object) Prefix() string {
+	if i := strings.IndexAny(o.Key, "*?["); i >= 0 {
+		return o.Key[:i]
+	}
+	return o.Key
+}
+
+// Match returns true if key matches o.Key, which may be a glob.
+func (o *Object) Match(key string) bool {
+	if !o.IsGlob() {
+		return key == o.Key
+	}
+	ok, err := path.Match(o.Key, key)
+	return err == nil && ok
+}
+
+// Parse parses an object store location, such as "s3://bucket/key". The
+// location isn't parsed as a URL: the key is everything after the bucket,
+// verbatim, and isn't URL-decoded. Thus a key may contain characters such
+// as "?" (e.g. the glob "exports/2024-0?.csv") and "#", which in a URL
+// would start the query or fragment.
+func Parse(loc string) (*Object, error) {
+	scheme, rest, _ := strings.Cut(loc, "://")
+	switch scheme {
+	case SchemeS3, SchemeGCS, SchemeAzure:
+	default:
+		return nil, errz.Errorf("parse object store location: unsupported scheme {%s}", scheme)
+	}
+
+	obj := &Object{Scheme: scheme}
+	obj.Bucket, obj.Key, _ = strings.Cut(rest, "/")
+	switch {
+	case obj.Bucket == "":
+		return nil, errz.Errorf("parse object store location: no bucket: %s", loc)
+	case strings.ContainsAny(obj.Bucket, "@:?#"):
+		// Credentials are never part of the location: they are provided
+		// via source options or the environment.
+		return nil, errz.Errorf("parse object store location: must be of the form %s://bucket/key",
+			obj.Scheme)
+	case obj.Key == "" || strings.HasSuffix(obj.Key, "/"):
+		return nil, errz.Errorf("parse object store location: no object key: %s", loc)
+	}
+
+	if obj.IsGlob() {
+		if _, err := path.Match(obj.Key, ""); err != nil {
+			return nil, errz.Wrapf(err, "parse object store location: invalid glob {%s}", obj.Key)
+		}
+	}
+	return obj, nil
+}
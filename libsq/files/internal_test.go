@@ -10,6 +10,7 @@ import (
 	"github.com/neilotoole/sq/libsq/core/ioz/lockfile"
 	"github.com/neilotoole/sq/libsq/core/lg"
 	"github.com/neilotoole/sq/libsq/core/lg/lgt"
+	"github.com/neilotoole/sq/libsq/core/options"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
 )
@@ -34,20 +35,20 @@ func TestFiles_filepath(t *testing.T) {
 
 	t.Run("file", func(t *testing.T) {
 		src := &source.Source{Handle: "@h", Type: drivertype.CSV, Location: "/tmp/a.csv"}
-		fp, err := fs.filepath(src)
+		fp, err := fs.filepath(context.Background(), src)
 		require.NoError(t, err)
 		require.Equal(t, "/tmp/a.csv", fp)
 	})
 
 	t.Run("sql", func(t *testing.T) {
 		src := &source.Source{Handle: "@pg", Type: drivertype.Pg, Location: "postgres://u:p@localhost/db"}
-		_, err := fs.filepath(src)
+		_, err := fs.filepath(context.Background(), src)
 		require.Error(t, err)
 	})
 
 	t.Run("stdin", func(t *testing.T) {
 		src := &source.Source{Handle: source.StdinHandle, Location: source.StdinHandle}
-		_, err := fs.filepath(src)
+		_, err := fs.filepath(context.Background(), src)
 		require.Error(t, err)
 	})
 }
@@ -69,3 +70,47 @@ func TestFiles_WriteIngestChecksum_FilepathError(t *testing.T) {
 	err := fs.WriteIngestChecksum(ctx, sqlSrc, backingSrc)
 	require.Error(t, err, "SQL source has no filepath -> error")
 }
+
+// TestCredentialsKey verifies that the download cache key depends on the
+// unresolved credential options, whether set on the source or in the base
+// config, and not on the resolved secrets.
+func TestCredentialsKey(t *testing.T) {
+	ctx := context.Background()
+	newSrc := func(bearer string) *source.Source {
+		src := &source.Source{Handle: "@h", Type: drivertype.CSV, Location: "https://example.com/a.csv"}
+		if bearer != "" {
+			src.Options = options.Options{OptHTTPAuthBearer.Key(): bearer}
+		}
+		return src
+	}
+
+	require.Empty(t, credentialsKey(ctx, newSrc("")))
+	require.Empty(t, credentialsKey(ctx, &source.Source{Options: options.Options{"foo": "bar"}}))
+
+	key := credentialsKey(ctx, newSrc("${keyring:a}"))
+	require.NotEmpty(t, key)
+	require.Equal(t, key, credentialsKey(ctx, newSrc("${keyring:a}")))
+	require.NotEqual(t, key, credentialsKey(ctx, newSrc("${keyring:b}")))
+
+	// A credential in the base config applies to the source, as it does
+	// for httpClientFor. The source's own option takes precedence.
+	baseCtx := options.NewContext(ctx, options.Options{OptHTTPAuthBearer.Key(): "${keyring:a}"})
+	require.Equal(t, key, credentialsKey(baseCtx, newSrc("")))
+	require.Equal(t, credentialsKey(ctx, newSrc("${keyring:b}")), credentialsKey(baseCtx, newSrc("${keyring:b}")))
+}
+
+func TestParseHTTPHeaders(t *testing.T) {
+	hdrs, err := parseHTTPHeaders("X-Api-Key: ${keyring:k}; Accept: text/csv, text/plain;")
+	require.NoError(t, err)
+	require.Equal(t, "${keyring:k}", hdrs.Get("X-Api-Key"))
+	require.Equal(t, "text/csv, text/plain", hdrs.Get("Accept"))
+
+	hdrs, err = parseHTTPHeaders("")
+	require.NoError(t, err)
+	require.Empty(t, hdrs)
+
+	_, err = parseHTTPHeaders("no-colon")
+	require.Error(t, err)
+	_, err = parseHTTPHeaders("Bad Name: x")
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package files
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/ioz/checksum"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// IsMultiFile returns true if loc is a local location that denotes a set of
+// files, rather than a single file. That is, loc is either a glob pattern,
+// such as "./events/*.jsonl", or a directory. A multi-file source is ingested
+// by ingesting each of its files, and then unioning the results: see
+// driver.Grips.OpenIngest.
+//
+// If loc is the path to an existing regular file, it is not a multi-file
+// location, even if its name contains glob metacharacters.
+func IsMultiFile(loc string) bool {
+	if location.TypeOf(loc) != location.TypeFile {
+		return false
+	}
+
+	if ioz.IsPathToRegularFile(loc) {
+		return false
+	}
+
+	return isGlob(loc) || ioz.DirExists(loc)
+}
+
+// MultiFilePaths returns the sorted paths of the regular files denoted by
+// multi-file location loc (see IsMultiFile). For a directory, that's each
+// non-hidden regular file in the directory; subdirectories are not
+// traversed. For a glob, that's each regular file matching the pattern. An
+// error is returned if no file matches.
+func MultiFilePaths(loc string) ([]string, error) {
+	var candidates []string
+	if isGlob(loc) {
+		var err error
+		if candidates, err = filepath.Glob(loc); err != nil {
+			return nil, errz.Wrapf(err, "invalid glob: %s", loc)
+		}
+	} else {
+		entries, err := os.ReadDir(loc)
+		if err != nil {
+			return nil, errz.Err(err)
+		}
+
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			candidates = append(candidates, filepath.Join(loc, entry.Name()))
+		}
+	}
+
+	paths := make([]string, 0, len(candidates))
+	for _, p := range candidates {
+		if ioz.IsPathToRegularFile(p) {
+			paths = append(paths, p)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, errz.Errorf("no files match: %s", loc)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// MultiFileBase returns the dir of multi-file location loc that its files'
+// names are reported relative to. For a directory, that's the directory
+// itself. For a glob, it's the deepest dir of the pattern that contains no
+// glob metacharacters, e.g. "/data/events" for "/data/events/2026-*/*.jsonl".
+func MultiFileBase(loc string) string {
+	if !isGlob(loc) {
+		return filepath.Clean(loc)
+	}
+
+	dir := filepath.Dir(loc)
+	for isGlob(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// isGlob returns true if loc contains glob metacharacters.
+func isGlob(loc string) bool {
+	return strings.ContainsAny(loc, "*?[")
+}
+
+// IngestChecksums returns the checksums of the files that src's ingest DB
+// is derived from, keyed by file path. A change in the returned checksums
+// indicates that src must be re-ingested.
+func (fs *Files) IngestChecksums(ctx context.Context, src *source.Source) (map[string]checksum.Checksum, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.ingestChecksums(ctx, src)
+}
+
+// ingestChecksums returns the checksums of the files that src's ingest DB
+// is derived from, keyed by file path. For a multi-file source, that's each
+// of the source's files; otherwise, it's the single file returned by
+// Files.filepath. Also included is any file, such as an ingest schema file,
+// named by src's options tagged options.TagIngestFile.
+func (fs *Files) ingestChecksums(ctx context.Context, src *source.Source) (map[string]checksum.Checksum, error) {
+	var paths []string
+	if IsMultiFile(src.Location) {
+		var err error
+		if paths, err = MultiFilePaths(src.Location); err != nil {
+			return nil, err
+		}
+	} else {
+		fp, err := fs.filepath(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{fp}
+	}
+	paths = append(paths, fs.ingestOptionFiles(src)...)
+
+	sums := make(map[string]checksum.Checksum, len(paths))
+	for _, p := range paths {
+		sum, err := checksum.ForFile(p)
+		if err != nil {
+			return nil, err
+		}
+		sums[p] = sum
+	}
+	return sums, nil
+}
+
+// ingestOptionFiles returns the paths of the files named by src's options
+// that are tagged options.TagIngestFile. A value that isn't the path of a
+// regular file, such as an inline schema, is ignored.
+func (fs *Files) ingestOptionFiles(src *source.Source) []string {
+	var paths []string
+	for _, opt := range fs.optRegistry.Opts() {
+		if !opt.HasTag(options.TagIngestFile) || !opt.IsSet(src.Options) {
+			continue
+		}
+		fp, _ := opt.GetAny(src.Options).(string)
+		if fi, err := os.Stat(fp); err == nil && fi.Mode().IsRegular() {
+			paths = append(paths, fp)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,196 @@
+package files_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+	"github.com/neilotoole/sq/testh/tu"
+)
+
+// writeFiles writes each of names (with content name) to dir.
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		fp := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o700))
+		require.NoError(t, os.WriteFile(fp, []byte(name+"\n"), 0o600))
+	}
+}
+
+func TestMultiFilePaths(t *testing.T) {
+	dir := tu.TempDir(t, "src")
+	writeFiles(t, dir, "b.jsonl", "a.jsonl", "c.csv", ".hidden.jsonl", "sub/d.jsonl", "lit[1].jsonl")
+	join := func(names ...string) []string {
+		paths := make([]string, len(names))
+		for i, name := range names {
+			paths[i] = filepath.Join(dir, name)
+		}
+		return paths
+	}
+
+	testCases := []struct {
+		name     string
+		loc      string
+		wantMult bool
+		want     []string
+		wantBase string
+		wantErr  bool
+	}{
+		{name: "file", loc: filepath.Join(dir, "a.jsonl")},
+		{name: "literal_glob_chars", loc: filepath.Join(dir, "lit[1].jsonl")},
+		{name: "url", loc: "https://example.com/*.jsonl"},
+		{
+			name: "dir", loc: dir, wantMult: true, wantBase: dir,
+			want: join("a.jsonl", "b.jsonl", "c.csv", "lit[1].jsonl"),
+		},
+		{
+			name: "glob", loc: filepath.Join(dir, "*.jsonl"), wantMult: true, wantBase: dir,
+			want: join(".hidden.jsonl", "a.jsonl", "b.jsonl", "lit[1].jsonl"),
+		},
+		{
+			name: "glob_dirs", loc: filepath.Join(dir, "s*", "*.jsonl"), wantMult: true, wantBase: dir,
+			want: join("sub/d.jsonl"),
+		},
+		{name: "glob_no_match", loc: filepath.Join(dir, "*.parquet"), wantMult: true, wantBase: dir, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantMult, files.IsMultiFile(tc.loc))
+			if !tc.wantMult {
+				return
+			}
+			require.Equal(t, tc.wantBase, files.MultiFileBase(tc.loc))
+
+			got, err := files.MultiFilePaths(tc.loc)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestFiles_WriteIngestChecksum_MultiFile verifies that the cache of a
+// multi-file source is invalidated when any of its files is added,
+// removed, or changed.
+func TestFiles_WriteIngestChecksum_MultiFile(t *testing.T) {
+	ctx, fs := newTestFiles(t)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	dir := tu.TempDir(t, "src")
+	writeFiles(t, dir, "a.jsonl", "b.jsonl")
+	src := &source.Source{Handle: "@events", Type: drivertype.JSONL, Location: filepath.Join(dir, "*.jsonl")}
+	backingSrc := &source.Source{Handle: src.Handle + "_cached", Type: drivertype.SQLite}
+
+	size, err := fs.Filesize(ctx, src)
+	require.NoError(t, err)
+	require.Equal(t, int64(len("a.jsonl\n")+len("b.jsonl\n")), size)
+	require.NoError(t, fs.Ping(ctx, src))
+
+	_, cacheDB, _, err := fs.CachePaths(src)
+	require.NoError(t, err)
+	require.NoError(t, fs.WriteIngestChecksum(ctx, src, backingSrc))
+	require.NoError(t, os.WriteFile(cacheDB, []byte("fake"), 0o600))
+
+	requireCached := func(want bool) {
+		t.Helper()
+		_, ok, err := fs.CachedBackingSourceFor(ctx, src)
+		require.NoError(t, err)
+		require.Equal(t, want, ok)
+	}
+	requireCached(true)
+
+	writeFiles(t, dir, "c.jsonl")
+	requireCached(false)
+	require.NoError(t, fs.WriteIngestChecksum(ctx, src, backingSrc))
+	requireCached(true)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.jsonl")))
+	requireCached(false)
+	require.NoError(t, fs.WriteIngestChecksum(ctx, src, backingSrc))
+	requireCached(true)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.jsonl"), []byte("changed size\n"), 0o600))
+	requireCached(false)
+
+	_, err = fs.NewReader(ctx, src, false)
+	require.Error(t, err, "multi-file source can't be read directly")
+}
+
+func TestFiles_IngestChecksums(t *testing.T) {
+	_, fs := newTestFiles(t)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	dir := tu.TempDir(t, "src")
+	writeFiles(t, dir, "a.jsonl", "b.jsonl")
+	src := &source.Source{Handle: "@events", Type: drivertype.JSONL, Location: filepath.Join(dir, "*.jsonl")}
+
+	sums1, err := fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.Len(t, sums1, 2)
+
+	sums2, err := fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.Equal(t, sums1, sums2)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte("changed size\n"), 0o600))
+	sums2, err = fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.NotEqual(t, sums1[filepath.Join(dir, "a.jsonl")], sums2[filepath.Join(dir, "a.jsonl")])
+	require.Equal(t, sums1[filepath.Join(dir, "b.jsonl")], sums2[filepath.Join(dir, "b.jsonl")])
+}
+
+// TestFiles_IngestChecksums_OptionFile verifies that a file named by an
+// option tagged options.TagIngestFile, such as an ingest schema file, is
+// among the ingest checksums, so that editing the file invalidates the
+// ingest cache.
+func TestFiles_IngestChecksums_OptionFile(t *testing.T) {
+	optSchema := options.NewString("test.schema", nil, "", nil, "", "",
+		options.TagSource, options.TagIngestMutate, options.TagIngestFile)
+	optReg := &options.Registry{}
+	optReg.Add(optSchema)
+	fs, err := files.New(context.Background(), optReg, testh.TempLockFunc(t),
+		tu.TempDir(t, "temp"), tu.TempDir(t, "cache"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	dir := tu.TempDir(t, "src")
+	writeFiles(t, dir, "data.csv", "data.schema.yml")
+	dataFile, schemaFile := filepath.Join(dir, "data.csv"), filepath.Join(dir, "data.schema.yml")
+	src := &source.Source{
+		Handle:   "@data",
+		Type:     drivertype.CSV,
+		Location: dataFile,
+		Options:  options.Options{optSchema.Key(): schemaFile},
+	}
+
+	sums1, err := fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.Len(t, sums1, 2)
+	require.Contains(t, sums1, schemaFile)
+
+	require.NoError(t, os.WriteFile(schemaFile, []byte("changed size\n"), 0o600))
+	sums2, err := fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.NotEqual(t, sums1[schemaFile], sums2[schemaFile])
+	require.Equal(t, sums1[dataFile], sums2[dataFile])
+
+	// An inline value isn't a file.
+	src.Options[optSchema.Key()] = "id:int"
+	sums2, err = fs.IngestChecksums(context.Background(), src)
+	require.NoError(t, err)
+	require.Len(t, sums2, 1)
+}
@@ -0,0 +1,173 @@
+package files
+
+import (
+	"context"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/files/internal/objstore"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+var (
+	OptS3Endpoint = options.NewString(
+		"s3.endpoint",
+		nil,
+		"",
+		nil,
+		"S3 endpoint URL",
+		`Endpoint URL of an S3-compatible store, such as MinIO, for s3:// document
+sources. A custom endpoint is addressed path-style, i.e.
+https://endpoint/bucket/key. If not set, the AWS_ENDPOINT_URL_S3 or
+AWS_ENDPOINT_URL env var is used, and failing that, the AWS endpoint for
+s3.region.
+
+  $ sq config set --src @sales s3.endpoint http://localhost:9000`,
+		options.TagSource,
+	)
+	OptS3Region = options.NewString(
+		"s3.region",
+		nil,
+		"",
+		nil,
+		"S3 region",
+		`AWS region of the bucket of an s3:// document source. If not set, the
+AWS_REGION or AWS_DEFAULT_REGION env var is used, then the region of the
+s3.profile profile in the AWS config file, and failing that, us-east-1.`,
+		options.TagSource,
+	)
+	OptS3Profile = options.NewString(
+		"s3.profile",
+		nil,
+		"",
+		nil,
+		"S3 AWS profile",
+		`Profile in the AWS shared credentials and config files (~/.aws/credentials
+and ~/.aws/config) that supplies the credentials and region for s3:// document
+sources, if they're not otherwise set. If not set, the AWS_PROFILE env var is
+used, and failing that, the "default" profile.`,
+		options.TagSource,
+	)
+	OptS3AccessKeyID = options.NewString(
+		"s3.access-key-id",
+		nil,
+		"",
+		nil,
+		"S3 access key ID",
+		`Access key ID for s3:// document sources. If not set, the
+AWS_ACCESS_KEY_ID env var is used, and failing that, the s3.profile profile.
+If there are no credentials, requests are anonymous, which works for public
+buckets.
+
+Contrast with s3.secret-access-key.`,
+		options.TagSource,
+	)
+	OptS3SecretAccessKey = options.NewString(
+		"s3.secret-access-key",
+		nil,
+		"",
+		nil,
+		"S3 secret access key",
+		`Secret access key for s3.access-key-id. Typically a secret reference,
+e.g. ${keyring:sales/s3}.`,
+		options.TagSource,
+	)
+	OptS3SessionToken = options.NewString(
+		"s3.session-token",
+		nil,
+		"",
+		nil,
+		"S3 session token",
+		`Session token for temporary S3 credentials, as used with
+s3.access-key-id.`,
+		options.TagSource,
+	)
+	OptGCSToken = options.NewString(
+		"gs.token",
+		nil,
+		"",
+		nil,
+		"GCS OAuth2 access token",
+		`OAuth2 access token for gs:// document sources, e.g. as printed by
+"gcloud auth print-access-token". If not set, the GOOGLE_OAUTH_ACCESS_TOKEN
+env var is used. If there's no token, requests are anonymous, which works for
+public buckets. The STORAGE_EMULATOR_HOST env var is honored.`,
+		options.TagSource,
+	)
+	OptAzureAccount = options.NewString(
+		"az.account",
+		nil,
+		"",
+		nil,
+		"Azure storage account",
+		`Azure storage account of az://container/blob document sources. If not
+set, the AZURE_STORAGE_ACCOUNT env var is used.`,
+		options.TagSource,
+	)
+	OptAzureSASToken = options.NewString(
+		"az.sas-token",
+		nil,
+		"",
+		nil,
+		"Azure SAS token",
+		`Shared access signature (SAS) token for az:// document sources. If not
+set, the AZURE_STORAGE_SAS_TOKEN env var is used. If there's no token,
+requests are anonymous, which works for public containers. Typically a secret
+reference, e.g. ${keyring:sales/sas}.`,
+		options.TagSource,
+	)
+)
+
+// objectCredentialOpts are the object store options that carry
+// credentials. Their values may contain secret references.
+var objectCredentialOpts = []options.String{
+	OptS3Profile,
+	OptS3AccessKeyID,
+	OptS3SecretAccessKey,
+	OptS3SessionToken,
+	OptGCSToken,
+	OptAzureAccount,
+	OptAzureSASToken,
+}
+
+// objectConfigFor returns the object identified by src.Location, which
+// must be an object store location such as "s3://bucket/key", and the
+// config for accessing it. The config is built from the options in o,
+// with any secret references resolved, and then from the environment.
+func (fs *Files) objectConfigFor(ctx context.Context, src *source.Source, o options.Options) (*objstore.Object,
+	objstore.Config, error,
+) {
+	var cfg objstore.Config
+	obj, err := objstore.Parse(src.Location)
+	if err != nil {
+		return nil, cfg, errz.Wrap(err, src.Handle)
+	}
+
+	fields := []struct {
+		opt  options.String
+		dest *string
+	}{
+		{OptS3Endpoint, &cfg.Endpoint},
+		{OptS3Region, &cfg.Region},
+		{OptS3Profile, &cfg.Profile},
+		{OptS3AccessKeyID, &cfg.AccessKeyID},
+		{OptS3SecretAccessKey, &cfg.SecretAccessKey},
+		{OptS3SessionToken, &cfg.SessionToken},
+		{OptGCSToken, &cfg.Token},
+		{OptAzureAccount, &cfg.Account},
+		{OptAzureSASToken, &cfg.SASToken},
+	}
+	for _, f := range fields {
+		if *f.dest, err = fs.resolveSecretOpt(ctx, src, f.opt, o); err != nil {
+			return nil, cfg, err
+		}
+	}
+
+	if obj.Scheme != objstore.SchemeS3 {
+		// The endpoint option is S3-specific; GCS honors the
+		// STORAGE_EMULATOR_HOST env var instead.
+		cfg.Endpoint = ""
+	}
+
+	return obj, cfg.WithEnv(obj.Scheme), nil
+}
@@ -0,0 +1,241 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/ioz/checksum"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/location"
+)
+
+// OptResultCacheTTL is the duration for which query results are cached.
+// Result caching is opt-in: the default value of zero disables it.
+//
+// See also: driver.OptIngestCache, which when false (--no-cache) also
+// bypasses the result cache.
+var OptResultCacheTTL = options.NewDuration(
+	"cache.result.ttl",
+	&options.Flag{
+		Name:  "cache-ttl",
+		Usage: "Reuse cached query results younger than duration",
+	},
+	0,
+	"Query result cache TTL",
+	`How long the results of a query are cached. When non-zero, the records
+returned by a query are stored in the cache dir, keyed by the rendered SQL, the
+identity of the queried source(s), and any --arg values. An identical query
+executed within the TTL reuses the cached records instead of querying the
+source again. For document sources (CSV, Excel, etc.), the cached results are
+invalidated when the document changes. The TTL can be set on a default or
+per-source basis, or for an individual query via --cache-ttl. A query that
+touches several sources uses the smallest TTL among them. Use --no-cache to
+bypass the cache. Clear cached results via "sq cache clear". Example: 90s or
+10m.
+
+  # Cache results for 5 minutes, by default
+  $ sq config set cache.result.ttl 5m
+
+  # Cache results for a particular source
+  $ sq config set --src @sakila cache.result.ttl 1h
+
+  # Cache the results of this query for 30 seconds
+  $ sq --cache-ttl 30s '@sakila.actor'`,
+	options.TagSource,
+)
+
+// resultCacheDir is the name of the dir, within a source's cache dir (see
+// CacheDirFor), that holds that source's cached query results.
+const resultCacheDir = "results"
+
+// ResultCacheEntry returns the path of the result cache entry for query
+// executed against srcs with args. There is no guarantee that the file
+// exists: this is just the path. The entry is keyed by query, by the
+// identity of each source (the same hash that determines CacheDirFor), and
+// by args. For document sources, the ingest checksum (see
+// WriteIngestChecksum) is also incorporated, so that a change to the
+// document results in a different entry.
+//
+// The entry lives in the cache dir of the first of srcs, ordered by
+// handle, so it is removed by CacheClearSourceAll for that handle, and by
+// CacheClearAll.
+//
+// If arg ok is false, the query's results can't be safely cached, e.g.
+// because a document source has no ingest checksum to detect change, or
+// because a source is stdin.
+func (fs *Files) ResultCacheEntry(ctx context.Context, srcs []*source.Source, query string,
+	args map[string]string,
+) (fp string, ok bool, err error) {
+	if len(srcs) == 0 {
+		return "", false, nil
+	}
+
+	srcs = slices.Clone(srcs)
+	slices.SortFunc(srcs, func(a, b *source.Source) int {
+		return strings.Compare(a.Handle, b.Handle)
+	})
+
+	buf := bytes.Buffer{}
+	buf.WriteString(query)
+	for _, src := range srcs {
+		switch location.TypeOf(src.Location) {
+		case location.TypeStdin, location.TypeUnknown:
+			return "", false, nil
+		case location.TypeFile, location.TypeHTTP, location.TypeObject:
+			var sum string
+			if sum, ok = fs.ingestChecksum(ctx, src); !ok {
+				return "", false, nil
+			}
+			buf.WriteString(sum)
+		default:
+		}
+
+		buf.WriteByte(0)
+		buf.WriteString(src.Handle)
+		buf.WriteString(fs.sourceHash(src))
+	}
+
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		buf.WriteByte(0)
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(args[k])
+	}
+
+	srcCacheDir, err := fs.CacheDirFor(srcs[0])
+	if err != nil {
+		return "", false, err
+	}
+
+	fp = filepath.Join(srcCacheDir, resultCacheDir, checksum.Sum(buf.Bytes())+".gob")
+	return fp, true, nil
+}
+
+// ingestChecksum returns the ingest checksum of document source src, as
+// written by WriteIngestChecksum. The checksum incorporates every file that
+// the ingest DB is derived from, such as an ingest schema file. If there's
+// no checksum for src's file, ok is false.
+func (fs *Files) ingestChecksum(ctx context.Context, src *source.Source) (sum string, ok bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	_, _, checksumsPath, err := fs.CachePaths(src)
+	if err != nil || !ioz.FileAccessible(checksumsPath) {
+		return "", false
+	}
+
+	mChecksums, err := checksum.ReadFile(checksumsPath)
+	if err != nil {
+		return "", false
+	}
+
+	srcFilepath, err := fs.filepath(ctx, src)
+	if err != nil {
+		return "", false
+	}
+
+	if _, ok = mChecksums[srcFilepath]; !ok {
+		return "", false
+	}
+
+	buf := bytes.Buffer{}
+	for _, p := range slices.Sorted(maps.Keys(mChecksums)) {
+		buf.WriteString(p)
+		buf.WriteByte(0)
+		buf.WriteString(string(mChecksums[p]))
+		buf.WriteByte(0)
+	}
+	return checksum.Sum(buf.Bytes()), true
+}
+
+// ResultCacheOpen opens the result cache entry at fp (see
+// ResultCacheEntry), if the entry exists and is younger than ttl. If the
+// entry doesn't exist or has expired, ok is false. On success, the caller
+// must close the returned file.
+func (fs *Files) ResultCacheOpen(ctx context.Context, fp string, ttl time.Duration) (f *os.File, ok bool,
+	err error,
+) {
+	fi, err := os.Stat(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, errz.Wrap(err, "result cache")
+	}
+
+	if age := time.Since(fi.ModTime()); age > ttl {
+		lg.FromContext(ctx).Debug("Result cache entry expired", lga.Path, fp, "age", age, "ttl", ttl)
+		// Best-effort: the entry is useless now.
+		_ = os.Remove(fp)
+		return nil, false, nil
+	}
+
+	if f, err = os.Open(fp); err != nil {
+		if os.IsNotExist(err) {
+			// Removed by a concurrent cache clear.
+			return nil, false, nil
+		}
+		return nil, false, errz.Wrap(err, "result cache")
+	}
+
+	return f, true, nil
+}
+
+// ResultCacheCreate returns a temp file to which the result cache entry at
+// fp is written. When writing completes, the caller must invoke commit,
+// which closes f and moves it into place at fp, or discard on failure.
+// Only the first invocation of either func has effect.
+func (fs *Files) ResultCacheCreate(fp string) (f *os.File, commit, discard func() error, err error) {
+	dir := filepath.Dir(fp)
+	if err = ioz.RequireDir(dir); err != nil {
+		return nil, nil, nil, errz.Wrap(err, "result cache")
+	}
+
+	if f, err = os.CreateTemp(dir, filepath.Base(fp)+".*.tmp"); err != nil {
+		return nil, nil, nil, errz.Wrap(err, "result cache")
+	}
+
+	tmp := f.Name()
+	var done bool
+	commit = func() error {
+		if done {
+			return nil
+		}
+		done = true
+		if err := f.Close(); err != nil {
+			_ = os.Remove(tmp)
+			return errz.Wrap(err, "result cache")
+		}
+		if err := os.Rename(tmp, fp); err != nil {
+			_ = os.Remove(tmp)
+			return errz.Wrap(err, "result cache")
+		}
+		return nil
+	}
+	discard = func() error {
+		if done {
+			return nil
+		}
+		done = true
+		_ = f.Close()
+		return errz.Err(os.Remove(tmp))
+	}
+
+	return f, commit, discard, nil
+}
@@ -0,0 +1,146 @@
+package files_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgt"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+	"github.com/neilotoole/sq/testh/tu"
+)
+
+func TestFiles_ResultCacheEntry(t *testing.T) {
+	ctx := lg.NewContext(context.Background(), lgt.New(t))
+	fs, err := files.New(ctx, nil, testh.TempLockFunc(t), tu.TempDir(t, "temp"), tu.TempDir(t, "cache"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	srcA := &source.Source{Handle: "@a", Type: drivertype.SQLite, Location: "sqlite3:///tmp/a.db"}
+	srcB := &source.Source{Handle: "@b", Type: drivertype.SQLite, Location: "sqlite3:///tmp/b.db"}
+
+	fp, ok, err := fs.ResultCacheEntry(ctx, []*source.Source{srcA, srcB}, "SELECT 1", map[string]string{"x": "1"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	srcCacheDir, err := fs.CacheDirFor(srcA)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(srcCacheDir, "results"), filepath.Dir(fp),
+		"entry should live in the cache dir of the first source by handle")
+
+	fp2, ok, err := fs.ResultCacheEntry(ctx, []*source.Source{srcB, srcA}, "SELECT 1", map[string]string{"x": "1"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, fp, fp2, "source order should not matter")
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		args  map[string]string
+		srcs  []*source.Source
+	}{
+		{name: "query", query: "SELECT 2", args: map[string]string{"x": "1"}, srcs: []*source.Source{srcA, srcB}},
+		{name: "args", query: "SELECT 1", args: map[string]string{"x": "2"}, srcs: []*source.Source{srcA, srcB}},
+		{name: "no_args", query: "SELECT 1", srcs: []*source.Source{srcA, srcB}},
+		{name: "srcs", query: "SELECT 1", args: map[string]string{"x": "1"}, srcs: []*source.Source{srcA}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK, gotErr := fs.ResultCacheEntry(ctx, tc.srcs, tc.query, tc.args)
+			require.NoError(t, gotErr)
+			require.True(t, gotOK)
+			require.NotEqual(t, fp, got)
+		})
+	}
+
+	stdinSrc := &source.Source{Handle: source.StdinHandle, Type: drivertype.CSV, Location: source.StdinHandle}
+	_, ok, err = fs.ResultCacheEntry(ctx, []*source.Source{stdinSrc}, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.False(t, ok, "stdin results can't be cached")
+}
+
+// TestFiles_ResultCacheEntry_DocSource verifies that a document source's
+// result cache entry is keyed by its ingest checksum, so that the entry
+// changes when the document does.
+func TestFiles_ResultCacheEntry_DocSource(t *testing.T) {
+	ctx := lg.NewContext(context.Background(), lgt.New(t))
+	fs, err := files.New(ctx, nil, testh.TempLockFunc(t), tu.TempDir(t, "temp"), tu.TempDir(t, "cache"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	src := mustCSVSrc(t, tu.TempDir(t, "data"), "a,b\n1,2\n")
+	srcs := []*source.Source{src}
+
+	_, ok, err := fs.ResultCacheEntry(ctx, srcs, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.False(t, ok, "no ingest checksum yet, so change can't be detected")
+
+	backingSrc := &source.Source{Handle: src.Handle + "_cached", Type: drivertype.SQLite}
+	require.NoError(t, fs.WriteIngestChecksum(ctx, src, backingSrc))
+	fp1, ok, err := fs.ResultCacheEntry(ctx, srcs, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, os.WriteFile(src.Location, []byte("a,b\n3,4\n"), 0o600))
+	require.NoError(t, fs.WriteIngestChecksum(ctx, src, backingSrc))
+	fp2, ok, err := fs.ResultCacheEntry(ctx, srcs, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotEqual(t, fp1, fp2, "entry should change when the document changes")
+}
+
+func TestFiles_ResultCacheOpenCreate(t *testing.T) {
+	ctx := lg.NewContext(context.Background(), lgt.New(t))
+	fs, err := files.New(ctx, nil, testh.TempLockFunc(t), tu.TempDir(t, "temp"), tu.TempDir(t, "cache"))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+
+	src := &source.Source{Handle: "@a", Type: drivertype.SQLite, Location: "sqlite3:///tmp/a.db"}
+	fp, ok, err := fs.ResultCacheEntry(ctx, []*source.Source{src}, "SELECT 1", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = fs.ResultCacheOpen(ctx, fp, time.Hour)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Discarded entries are not visible.
+	f, _, discard, err := fs.ResultCacheCreate(fp)
+	require.NoError(t, err)
+	_, err = f.WriteString("discarded")
+	require.NoError(t, err)
+	require.NoError(t, discard())
+	_, ok, err = fs.ResultCacheOpen(ctx, fp, time.Hour)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	f, commit, discard, err := fs.ResultCacheCreate(fp)
+	require.NoError(t, err)
+	_, err = f.WriteString("committed")
+	require.NoError(t, err)
+	require.NoError(t, commit())
+	require.NoError(t, discard(), "discard after commit is a no-op")
+
+	f, ok, err = fs.ResultCacheOpen(ctx, fp, time.Hour)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, f.Close())
+	got, err := os.ReadFile(fp)
+	require.NoError(t, err)
+	require.Equal(t, "committed", string(got))
+
+	// Age the entry beyond the TTL: it's expired, and removed.
+	old := time.Now().Add(-time.Hour * 2)
+	require.NoError(t, os.Chtimes(fp, old, old))
+	_, ok, err = fs.ResultCacheOpen(ctx, fp, time.Hour)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoFileExists(t, fp)
+}
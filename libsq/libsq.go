@@ -21,7 +21,11 @@ import (
 	"github.com/neilotoole/sq/libsq/core/progress"
 	"github.com/neilotoole/sq/libsq/core/record"
 	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/core/tablefq"
+	"github.com/neilotoole/sq/libsq/core/tuning"
 	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/mask"
 	"github.com/neilotoole/sq/libsq/source"
 	"github.com/neilotoole/sq/libsq/source/drivertype"
 )
@@ -34,6 +38,10 @@ type QueryContext struct {
 	// Grips mediates access to driver.Grip instances.
 	Grips *driver.Grips
 
+	// Files, when non-nil, enables the query result cache, subject to
+	// files.OptResultCacheTTL. May be nil.
+	Files *files.Files
+
 	// Args defines variables that are substituted into the query.
 	// May be nil or empty.
 	Args map[string]string
@@ -140,13 +148,22 @@ type RecordWriter interface {
 // statement (which is a sort of pipeline) which could result in multiple
 // backend SQL commands being executed against several different sources. By
 // contrast, ExecSQL executes SQL against a single source.
+//
+// If qc.Files is non-nil, and files.OptResultCacheTTL is set for the
+// query's input sources, the results may be served from, or written to,
+// the result cache.
 func ExecSLQ(ctx context.Context, qc *QueryContext, query string, recw RecordWriter) error {
 	p, err := newPipeline(ctx, qc, query)
 	if err != nil {
 		return err
 	}
 
-	return p.execute(ctx, recw)
+	rc, err := p.resultCache(ctx)
+	if err != nil {
+		return err
+	}
+
+	return rc.exec(ctx, recw, p.execute)
 }
 
 // RenderResult is the result of rendering a SLQ query to SQL via SLQ2SQL.
@@ -249,6 +266,24 @@ func (p *pipeline) inputSourceHandles() []string {
 	return handles
 }
 
+// QuerySQLCached is like QuerySQL, but the results may be served from, or
+// written to, the result cache in fs, subject to files.OptResultCacheTTL
+// for src. Arg src is the (unresolved) source that grip was opened from.
+// If fs is nil, QuerySQLCached is equivalent to QuerySQL. As with QuerySQL,
+// the caller may wish to wait for recw to complete.
+func QuerySQLCached(ctx context.Context, fs *files.Files, src *source.Source, grip driver.Grip,
+	recw RecordWriter, query string,
+) error {
+	rc, err := newResultCache(ctx, fs, []*source.Source{src}, query, nil)
+	if err != nil {
+		return err
+	}
+
+	return rc.exec(ctx, recw, func(ctx context.Context, recw RecordWriter) error {
+		return QuerySQL(ctx, grip, nil, recw, nil, query)
+	})
+}
+
 // ExecSQL executes a SQL statement (DDL/DML) that doesn't return rows,
 // such as CREATE TABLE, INSERT, UPDATE, DELETE, DROP TABLE, etc.
 // It returns the number of rows affected (which may be -1 per Go's
@@ -471,3 +506,36 @@ func QuerySQL(ctx context.Context, grip driver.Grip, db sqlz.DB,
 
 	return nil
 }
+
+// CopyTableData copies the rows of fromTbl in fromGrip into destTbl in
+// destGrip, which must already exist, via a DBWriter. If policy is non-nil,
+// each row is masked per the policy, whose table-qualified rules are
+// matched against fromTbl and destTbl. It returns the number of rows copied.
+//
+// Unlike [driver.SQLDriver.CopyTable], which copies the data in the
+// database, each row passes through sq. Thus CopyTableData is slower,
+// but the rows can be transformed, and the grips may be different
+// sources.
+func CopyTableData(ctx context.Context, fromGrip driver.Grip, fromTbl tablefq.T,
+	destGrip driver.Grip, destTbl tablefq.T, policy *mask.Policy,
+) (copied int64, err error) {
+	inserter := NewDBWriter(
+		"Copy records",
+		destGrip,
+		destTbl.Table,
+		tuning.OptRecBufSize.Get(destGrip.Source().Options),
+	)
+	if policy != nil {
+		inserter.SetMask(policy, fromTbl.Table)
+	}
+
+	query := "SELECT * FROM " + fromTbl.Render(fromGrip.SQLDriver().Dialect().Enquote)
+	if err = QuerySQL(ctx, fromGrip, nil, inserter, nil, query); err != nil {
+		return 0, errz.Wrapf(err, "copy to %s.%s failed", destGrip.Source().Handle, destTbl)
+	}
+
+	if copied, err = inserter.Wait(); err != nil {
+		return 0, errz.Wrapf(err, "copy to %s.%s failed", destGrip.Source().Handle, destTbl)
+	}
+	return copied, nil
+}
@@ -0,0 +1,342 @@
+// Package mask implements column-level data masking and pseudonymization,
+// as applied to records as they are written to a database, e.g. by
+// "sq --insert" or "sq tbl copy". The masking rules are defined by a
+// Policy, typically loaded from a YAML file.
+//
+// Masking is deterministic: a given value, masked with the same salt and
+// transform, always results in the same masked value, regardless of the
+// table or column it's in. Thus, joins on masked key columns still match
+// after masking.
+package mask
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/ioz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+)
+
+// OptPolicy is the path to the masking policy file that is applied to data
+// written to a source via "sq --insert", "sq sql --insert" or "sq tbl copy".
+var OptPolicy = options.NewString(
+	"mask.policy",
+	&options.Flag{Name: "mask"},
+	"",
+	nil,
+	"Masking policy file for --insert and tbl copy",
+	`Path to a YAML masking policy file. When set, the policy's rules transform
+column values as data is written via "sq --insert", "sq sql --insert", or
+"sq tbl copy". Data written by other means, such as "sq db restore", or by
+a SQL statement executed via "sq sql", is not masked. For example:
+
+  salt: ${env:MASK_SALT}
+  rules:
+    - column: "*.email"
+      transform: fake_email
+    - column: customer.last_name
+      transform: fake_last_name
+    - column: "*_id"
+      transform: hash
+    - column: payment_date
+      transform: truncate_date
+      unit: month
+    - column: ssn
+      transform: redact
+      keep_last: 4
+
+A rule's column is a pattern that matches either "table.column" (if the
+pattern contains a period) or the column name alone, case-insensitively,
+using shell glob syntax. The first matching rule applies. Masking is
+deterministic for a given salt, so joins on masked columns still match.
+
+The transforms are: hash, nullify, fake_name, fake_first_name,
+fake_last_name, fake_email, fake_phone, truncate_date and redact. The fake_*
+transforms produce text, and so can only be applied to text columns.`,
+	options.TagSource,
+	options.TagIngestFile,
+)
+
+// Transform names.
+const (
+	// TransformHash replaces a value with a salted hash (HMAC-SHA256) of
+	// the value. A string value becomes a hex string, truncated to
+	// Rule.Length if set. An integer value becomes a non-negative integer,
+	// with at most Rule.Length digits if set. A bytes value becomes the raw
+	// hash bytes.
+	TransformHash = "hash"
+
+	// TransformNullify replaces a value with null.
+	TransformNullify = "nullify"
+
+	// TransformFakeName replaces a value with a fake full name.
+	TransformFakeName = "fake_name"
+
+	// TransformFakeFirstName replaces a value with a fake first name.
+	TransformFakeFirstName = "fake_first_name"
+
+	// TransformFakeLastName replaces a value with a fake last name.
+	TransformFakeLastName = "fake_last_name"
+
+	// TransformFakeEmail replaces a value with a fake email address in the
+	// example.com domain.
+	TransformFakeEmail = "fake_email"
+
+	// TransformFakePhone replaces a value with a fake phone number, in the
+	// 555-01XX range that is reserved for fictional use.
+	TransformFakePhone = "fake_phone"
+
+	// TransformTruncateDate truncates a date or datetime value to
+	// Rule.Unit: year, month, day, or hour.
+	TransformTruncateDate = "truncate_date"
+
+	// TransformRedact performs format-preserving redaction: each letter
+	// is replaced by a letter of the same case, and each digit by a digit.
+	// Other characters, and the last Rule.KeepLast characters, are
+	// retained.
+	TransformRedact = "redact"
+)
+
+// Transforms is the set of transform names.
+var Transforms = []string{
+	TransformHash, TransformNullify, TransformFakeName, TransformFakeFirstName,
+	TransformFakeLastName, TransformFakeEmail, TransformFakePhone,
+	TransformTruncateDate, TransformRedact,
+}
+
+// Units of TransformTruncateDate.
+const (
+	UnitYear  = "year"
+	UnitMonth = "month"
+	UnitDay   = "day"
+	UnitHour  = "hour"
+)
+
+// Policy is a set of masking rules.
+type Policy struct {
+	// Salt is the secret salt that keys the masking transforms. It may be
+	// a ${scheme:path} placeholder, which the caller resolves.
+	Salt string `yaml:"salt" json:"salt"`
+
+	// Rules are the masking rules. The first rule that matches a column
+	// applies.
+	Rules []*Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule masks the columns that match its Column pattern.
+type Rule struct {
+	// Column is a glob pattern matching "table.column" if it contains a
+	// period, or else the column name. Matching is case-insensitive.
+	Column string `yaml:"column" json:"column"`
+
+	// Transform is the transform name, e.g. "hash". See Transforms.
+	Transform string `yaml:"transform" json:"transform"`
+
+	// Unit is the truncation unit of a truncate_date rule.
+	Unit string `yaml:"unit,omitempty" json:"unit,omitempty"`
+
+	// Length is the maximum length of a hash rule's output.
+	Length int `yaml:"length,omitempty" json:"length,omitempty"`
+
+	// KeepLast is the number of trailing characters that a redact rule
+	// retains.
+	KeepLast int `yaml:"keep_last,omitempty" json:"keep_last,omitempty"`
+}
+
+// LoadFile loads the policy file at fpath. See Load.
+func LoadFile(fpath string) (*Policy, error) {
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		return nil, errz.Wrap(err, "read mask policy file")
+	}
+
+	p, err := Load(data)
+	if err != nil {
+		return nil, errz.Wrapf(err, "mask policy file %s", fpath)
+	}
+	return p, nil
+}
+
+// Load loads a policy from YAML data, and validates it.
+func Load(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := ioz.UnmarshallYAML(data, p); err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate returns an error if p is not valid.
+func (p *Policy) Validate() error {
+	if p.Salt == "" {
+		return errz.New("salt is required")
+	}
+	if len(p.Rules) == 0 {
+		return errz.New("no rules")
+	}
+
+	for i, r := range p.Rules {
+		if r == nil {
+			return errz.Errorf("rule %d: empty rule", i+1)
+		}
+		if err := r.validate(); err != nil {
+			return errz.Wrapf(err, "rule %d", i+1)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if r.Column == "" {
+		return errz.New("column is required")
+	}
+	if _, err := path.Match(r.Column, ""); err != nil {
+		return errz.Wrapf(err, "invalid column pattern {%s}", r.Column)
+	}
+
+	switch r.Transform {
+	case "":
+		return errz.Errorf("transform is required: one of %s", strings.Join(Transforms, ", "))
+	case TransformHash:
+		if r.Length < 0 {
+			return errz.Errorf("%s: length must not be negative", r.Transform)
+		}
+	case TransformTruncateDate:
+		switch r.Unit {
+		case UnitYear, UnitMonth, UnitDay, UnitHour:
+		default:
+			return errz.Errorf("%s: unit must be one of %s, %s, %s, %s",
+				r.Transform, UnitYear, UnitMonth, UnitDay, UnitHour)
+		}
+	case TransformRedact:
+		if r.KeepLast < 0 {
+			return errz.Errorf("%s: keep_last must not be negative", r.Transform)
+		}
+	case TransformNullify, TransformFakeName, TransformFakeFirstName, TransformFakeLastName,
+		TransformFakeEmail, TransformFakePhone:
+	default:
+		return errz.Errorf("invalid transform {%s}: must be one of %s",
+			r.Transform, strings.Join(Transforms, ", "))
+	}
+	return nil
+}
+
+// match returns true if r applies to column col of any of tbls.
+func (r *Rule) match(tbls []string, col string) bool {
+	pattern := strings.ToLower(r.Column)
+	if !strings.Contains(pattern, ".") {
+		ok, _ := path.Match(pattern, strings.ToLower(col))
+		return ok
+	}
+
+	for _, tbl := range tbls {
+		if ok, _ := path.Match(pattern, strings.ToLower(tbl+"."+col)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Masker masks records of a particular shape, as returned by
+// Policy.Masker.
+type Masker struct {
+	salt []byte
+	cols []maskCol
+}
+
+// maskCol is a column that a Masker masks.
+type maskCol struct {
+	rule *Rule
+	name string
+	idx  int
+}
+
+// Masker returns a Masker that masks records with recMeta. A rule's
+// table-qualified pattern matches if it matches any of tbls, which are
+// typically the origin and dest tables of the records. If no rule applies
+// to any of the columns, nil is returned. Note that p.Salt should already
+// be resolved.
+//
+// An error is returned if a fake_* rule applies to a column whose kind is
+// known and isn't text: the fake value is text, and can't be written to,
+// say, an int or date column.
+func (p *Policy) Masker(recMeta record.Meta, tbls ...string) (*Masker, error) {
+	m := &Masker{salt: []byte(p.Salt)}
+	kinds := recMeta.Kinds()
+	for i, col := range recMeta.Names() {
+		for j, r := range p.Rules {
+			if !r.match(tbls, col) {
+				continue
+			}
+
+			if r.isFake() && !isTextKind(kinds[i]) {
+				return nil, errz.Errorf("mask policy: rule %d: transform %s can't mask column {%s} of kind %s",
+					j+1, r.Transform, col, kinds[i])
+			}
+			m.cols = append(m.cols, maskCol{rule: r, name: col, idx: i})
+			break
+		}
+	}
+
+	if len(m.cols) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+	return m, nil
+}
+
+// isFake returns true if r's transform is one of the fake_* transforms,
+// which always result in a text value.
+func (r *Rule) isFake() bool {
+	switch r.Transform {
+	case TransformFakeName, TransformFakeFirstName, TransformFakeLastName,
+		TransformFakeEmail, TransformFakePhone:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTextKind returns true if a text value can be written to a column of
+// kind k. That's the case for kind.Unknown and kind.Null, because the
+// column's actual type isn't known.
+func isTextKind(k kind.Kind) bool {
+	switch k { //nolint:exhaustive
+	case kind.Text, kind.Unknown, kind.Null:
+		return true
+	default:
+		return false
+	}
+}
+
+// Columns returns the names of the columns that m masks.
+func (m *Masker) Columns() []string {
+	names := make([]string, len(m.cols))
+	for i := range m.cols {
+		names[i] = m.cols[i].name
+	}
+	return names
+}
+
+// Mask masks rec in place. Null values remain null.
+func (m *Masker) Mask(rec record.Record) error {
+	for _, c := range m.cols {
+		if rec[c.idx] == nil {
+			continue
+		}
+
+		v, err := m.apply(c.rule, rec[c.idx])
+		if err != nil {
+			return errz.Wrapf(err, "mask column {%s}: %s", c.name, c.rule.Transform)
+		}
+		rec[c.idx] = v
+	}
+	return nil
+}
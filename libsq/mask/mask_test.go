@@ -0,0 +1,175 @@
+package mask_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/mask"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "no_salt", data: "rules:\n  - column: a\n    transform: hash\n", wantErr: "salt is required"},
+		{name: "no_rules", data: "salt: x\n", wantErr: "no rules"},
+		{name: "no_column", data: "salt: x\nrules:\n  - transform: hash\n", wantErr: "column is required"},
+		{name: "bad_pattern", data: "salt: x\nrules:\n  - column: '[a'\n    transform: hash\n", wantErr: "invalid column"},
+		{name: "no_transform", data: "salt: x\nrules:\n  - column: a\n", wantErr: "transform is required"},
+		{name: "bad_transform", data: "salt: x\nrules:\n  - column: a\n    transform: rot13\n", wantErr: "invalid transform"},
+		{
+			name:    "bad_unit",
+			data:    "salt: x\nrules:\n  - column: a\n    transform: truncate_date\n    unit: week\n",
+			wantErr: "unit must be",
+		},
+		{name: "valid", data: "salt: x\nrules:\n  - column: '*.email'\n    transform: fake_email\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := mask.Load([]byte(tc.data))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, p.Rules, 1)
+		})
+	}
+}
+
+func newRecMeta(names ...string) record.Meta {
+	recMeta := make(record.Meta, len(names))
+	for i, name := range names {
+		recMeta[i] = record.NewFieldMeta(&record.ColumnTypeData{Name: name, Kind: kind.Text}, name)
+	}
+	return recMeta
+}
+
+func TestPolicy_Masker(t *testing.T) {
+	p := &mask.Policy{Salt: "salt", Rules: []*mask.Rule{
+		{Column: "customer.email", Transform: mask.TransformFakeEmail},
+		{Column: "*_ID", Transform: mask.TransformHash},
+		{Column: "name", Transform: mask.TransformNullify},
+	}}
+
+	recMeta := newRecMeta("customer_id", "name", "email", "amount")
+	m, err := p.Masker(recMeta, "customer")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, []string{"customer_id", "name", "email"}, m.Columns())
+
+	m, err = p.Masker(recMeta, "payment")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, []string{"customer_id", "name"}, m.Columns(),
+		"table-qualified rule shouldn't match other tables")
+
+	m, err = p.Masker(newRecMeta("amount"), "payment")
+	require.NoError(t, err)
+	require.Nil(t, m)
+}
+
+func TestPolicy_Masker_kind(t *testing.T) {
+	p := &mask.Policy{Salt: "salt", Rules: []*mask.Rule{
+		{Column: "*_id", Transform: mask.TransformHash},
+		{Column: "*", Transform: mask.TransformFakeName},
+	}}
+
+	recMeta := record.Meta{
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "actor_id", Kind: kind.Int}, "actor_id"),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "name", Kind: kind.Text}, "name"),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "expr", Kind: kind.Unknown}, "expr"),
+	}
+	m, err := p.Masker(recMeta)
+	require.NoError(t, err)
+	require.Equal(t, []string{"actor_id", "name", "expr"}, m.Columns())
+
+	// The fake_name rule applies to an int column.
+	recMeta = append(recMeta,
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "rating", Kind: kind.Int}, "rating"))
+	m, err = p.Masker(recMeta)
+	require.Error(t, err)
+	require.Nil(t, m)
+	require.Contains(t, err.Error(), "rating")
+	require.Contains(t, err.Error(), mask.TransformFakeName)
+}
+
+func TestMasker_Mask(t *testing.T) {
+	p := &mask.Policy{Salt: "salt", Rules: []*mask.Rule{
+		{Column: "id", Transform: mask.TransformHash, Length: 6},
+		{Column: "token", Transform: mask.TransformHash, Length: 8},
+		{Column: "name", Transform: mask.TransformFakeName},
+		{Column: "email", Transform: mask.TransformFakeEmail},
+		{Column: "phone", Transform: mask.TransformFakePhone},
+		{Column: "ssn", Transform: mask.TransformRedact, KeepLast: 4},
+		{Column: "created", Transform: mask.TransformTruncateDate, Unit: mask.UnitMonth},
+		{Column: "updated", Transform: mask.TransformTruncateDate, Unit: mask.UnitYear},
+		{Column: "secret", Transform: mask.TransformNullify},
+	}}
+	recMeta := newRecMeta("id", "token", "name", "email", "phone", "ssn", "created", "updated", "secret")
+	m, err := p.Masker(recMeta)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+
+	created := time.Date(2023, time.April, 17, 10, 22, 33, 0, time.UTC)
+	newRec := func() record.Record {
+		return record.Record{
+			int64(42), "abc", "Alice Smith", "alice@corp.com", "212-555-1234",
+			"123-45-6789", created, "2023-04-17", "hunter2",
+		}
+	}
+
+	rec := newRec()
+	require.NoError(t, m.Mask(rec))
+
+	require.IsType(t, int64(0), rec[0])
+	require.Less(t, rec[0].(int64), int64(1_000_000))
+	require.NotEqual(t, int64(42), rec[0])
+	require.Len(t, rec[1], 8)
+	require.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, rec[2])
+	require.Regexp(t, `^[a-z]+\.[a-z]+\.[0-9a-f]{8}@example\.com$`, rec[3])
+	require.Regexp(t, `^\d{3}-555-01\d{2}$`, rec[4])
+	require.Regexp(t, `^\d{3}-\d{2}-6789$`, rec[5])
+	require.NotEqual(t, "123-45-6789", rec[5])
+	require.Equal(t, time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC), rec[6])
+	require.Equal(t, "2023-01-01", rec[7])
+	require.Nil(t, rec[8])
+
+	// Masking is deterministic.
+	rec2 := newRec()
+	require.NoError(t, m.Mask(rec2))
+	require.Equal(t, rec, rec2)
+
+	// A different salt results in different values.
+	p.Salt = "other"
+	rec3 := newRec()
+	m, err = p.Masker(recMeta)
+	require.NoError(t, err)
+	require.NoError(t, m.Mask(rec3))
+	require.NotEqual(t, rec[0], rec3[0])
+	require.NotEqual(t, rec[3], rec3[3])
+
+	// Null values remain null.
+	rec4 := make(record.Record, len(recMeta))
+	require.NoError(t, m.Mask(rec4))
+	require.Equal(t, make(record.Record, len(recMeta)), rec4)
+}
+
+func TestMasker_Mask_error(t *testing.T) {
+	p := &mask.Policy{Salt: "salt", Rules: []*mask.Rule{
+		{Column: "created", Transform: mask.TransformTruncateDate, Unit: mask.UnitDay},
+	}}
+	m, err := p.Masker(newRecMeta("created"))
+	require.NoError(t, err)
+	err = m.Mask(record.Record{"not a date"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "created")
+}
@@ -0,0 +1,227 @@
+package mask
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// apply returns the result of applying r's transform to non-nil value v.
+func (m *Masker) apply(r *Rule, v any) (any, error) {
+	switch r.Transform {
+	case TransformNullify:
+		return nil, nil //nolint:nilnil // nil is the masked value
+	case TransformHash:
+		return m.hash(r, v)
+	case TransformFakeName:
+		sum := m.sum(v)
+		return pick(firstNames, sum, 0) + " " + pick(lastNames, sum, 8), nil
+	case TransformFakeFirstName:
+		return pick(firstNames, m.sum(v), 0), nil
+	case TransformFakeLastName:
+		return pick(lastNames, m.sum(v), 8), nil
+	case TransformFakeEmail:
+		sum := m.sum(v)
+		return fmt.Sprintf("%s.%s.%s@example.com", strings.ToLower(pick(firstNames, sum, 0)),
+			strings.ToLower(pick(lastNames, sum, 8)), hex.EncodeToString(sum[16:20])), nil
+	case TransformFakePhone:
+		// The 555-0100 to 555-0199 range is reserved for fictional use.
+		n := binary.BigEndian.Uint64(m.sum(v)[:8])
+		return fmt.Sprintf("%03d-555-01%02d", 200+n%800, n/800%100), nil
+	case TransformTruncateDate:
+		return truncateDate(r.Unit, v)
+	case TransformRedact:
+		return m.redact(r, v)
+	default:
+		// Shouldn't happen: the rule was validated.
+		return nil, errz.Errorf("invalid transform {%s}", r.Transform)
+	}
+}
+
+// sum returns the salted hash of v's canonical string form. The string
+// form is used so that, e.g., int 42 and text "42" mask identically.
+func (m *Masker) sum(v any) []byte {
+	mac := hmac.New(sha256.New, m.salt)
+	_, _ = mac.Write([]byte(canonical(v)))
+	return mac.Sum(nil)
+}
+
+// canonical returns the string form of record value v.
+func canonical(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case decimal.Decimal:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// pick returns the element of vals selected by the 8 bytes of sum at
+// offset.
+func pick(vals []string, sum []byte, offset int) string {
+	return vals[binary.BigEndian.Uint64(sum[offset:offset+8])%uint64(len(vals))]
+}
+
+func (m *Masker) hash(r *Rule, v any) (any, error) {
+	sum := m.sum(v)
+	switch v.(type) {
+	case string:
+		s := hex.EncodeToString(sum)
+		if r.Length > 0 && r.Length < len(s) {
+			s = s[:r.Length]
+		}
+		return s, nil
+	case []byte:
+		return sum, nil
+	case int64:
+		n := binary.BigEndian.Uint64(sum[:8]) >> 1
+		if r.Length > 0 && r.Length < 19 {
+			n %= pow10(r.Length)
+		}
+		return int64(n), nil //nolint:gosec // n is at most 63 bits
+	default:
+		return nil, errz.Errorf("value of type %T can't be hashed", v)
+	}
+}
+
+func pow10(n int) uint64 {
+	p := uint64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// dateLayouts are the layouts that truncateDate uses to parse a string
+// value.
+var dateLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// truncateDate truncates v, a time.Time, or a string in one of
+// dateLayouts, to unit.
+func truncateDate(unit string, v any) (any, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return truncateTime(unit, v), nil
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return truncateTime(unit, t).Format(layout), nil
+			}
+		}
+		return nil, errz.Errorf("value {%s} is not a date", v)
+	default:
+		return nil, errz.Errorf("value of type %T is not a date", v)
+	}
+}
+
+func truncateTime(unit string, t time.Time) time.Time {
+	y, mon, d := t.Date()
+	switch unit {
+	case UnitYear:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	case UnitMonth:
+		return time.Date(y, mon, 1, 0, 0, 0, 0, t.Location())
+	case UnitDay:
+		return time.Date(y, mon, d, 0, 0, 0, 0, t.Location())
+	default: // UnitHour
+		return time.Date(y, mon, d, t.Hour(), 0, 0, 0, t.Location())
+	}
+}
+
+// redact performs format-preserving redaction of v, a string or int64.
+func (m *Masker) redact(r *Rule, v any) (any, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return nil, errz.Errorf("value of type %T can't be redacted", v)
+	}
+
+	runes := []rune(s)
+	keepFrom := max(len(runes)-r.KeepLast, 0)
+	ks := &keystream{mac: hmac.New(sha256.New, m.salt), seed: []byte(s)}
+	for i := range runes[:keepFrom] {
+		switch c := runes[i]; {
+		case c >= '0' && c <= '9':
+			runes[i] = rune('0' + ks.next()%10)
+		case unicode.IsUpper(c):
+			runes[i] = rune('A' + ks.next()%26)
+		case unicode.IsLetter(c):
+			runes[i] = rune('a' + ks.next()%26)
+		}
+	}
+
+	if _, ok := v.(int64); ok {
+		n, err := strconv.ParseInt(string(runes), 10, 64)
+		if err != nil {
+			return nil, errz.Err(err)
+		}
+		return n, nil
+	}
+	return string(runes), nil
+}
+
+// keystream is a deterministic stream of pseudo-random bytes, derived from
+// a keyed hash of seed.
+type keystream struct {
+	mac   hash.Hash
+	buf   []byte
+	seed  []byte
+	block uint32
+}
+
+func (ks *keystream) next() byte {
+	if len(ks.buf) == 0 {
+		ks.mac.Reset()
+		_, _ = ks.mac.Write(ks.seed)
+		_, _ = ks.mac.Write(binary.BigEndian.AppendUint32(nil, ks.block))
+		ks.buf = ks.mac.Sum(nil)
+		ks.block++
+	}
+	b := ks.buf[0]
+	ks.buf = ks.buf[1:]
+	return b
+}
+
+// firstNames and lastNames are the fake names used by the fake_* transforms.
+var firstNames = []string{
+	"Aaron", "Abigail", "Adam", "Alice", "Amelia", "Andrew", "Anna", "Ben",
+	"Carlos", "Charlotte", "Chloe", "Daniel", "David", "Diana", "Elena", "Emily",
+	"Emma", "Ethan", "Fatima", "Felix", "Grace", "Hannah", "Henry", "Isaac",
+	"Isabella", "Jack", "James", "Julia", "Kenji", "Laura", "Leo", "Liam",
+	"Lucas", "Lucy", "Maria", "Mason", "Mia", "Noah", "Olivia", "Omar",
+	"Priya", "Rachel", "Ravi", "Sam", "Sara", "Sofia", "Thomas", "Zoe",
+}
+
+var lastNames = []string{
+	"Adams", "Baker", "Brown", "Chen", "Clark", "Davis", "Diaz", "Evans",
+	"Garcia", "Green", "Hall", "Harris", "Hernandez", "Hill", "Jackson", "Johnson",
+	"Jones", "Kim", "King", "Lee", "Lewis", "Lopez", "Martin", "Martinez",
+	"Miller", "Moore", "Murphy", "Nguyen", "Patel", "Perez", "Robinson", "Rodriguez",
+	"Sato", "Scott", "Singh", "Smith", "Taylor", "Thomas", "Thompson", "Walker",
+	"White", "Williams", "Wilson", "Wright", "Young", "Zhang",
+}
@@ -235,12 +235,13 @@ func (p *pipeline) prepareNoTable(ctx context.Context, qm *queryModel) error {
 	return nil
 }
 
-// prepareFromTable builds the "FROM table" fragment.
+// prepareFromTable builds the "FROM table" fragment. Arg filter, which
+// may be nil, is passed to the source's driver.TablePreparer, if any.
 //
 // When this function returns, pipeline.rc will be set.
-func (p *pipeline) prepareFromTable(ctx context.Context, tblSel *ast.TblSelectorNode) (fromClause string,
-	fromGrip driver.Grip, err error,
-) {
+func (p *pipeline) prepareFromTable(ctx context.Context, tblSel *ast.TblSelectorNode,
+	filter *driver.Filter,
+) (fromClause string, fromGrip driver.Grip, err error) {
 	handle := tblSel.Handle()
 	if handle == "" {
 		handle = p.qc.Collection.ActiveHandle()
@@ -259,6 +260,10 @@ func (p *pipeline) prepareFromTable(ctx context.Context, tblSel *ast.TblSelector
 		return "", nil, err
 	}
 
+	if err = prepareTable(ctx, fromGrip, tblSel.Table().Table, filter); err != nil {
+		return "", nil, err
+	}
+
 	rndr := fromGrip.SQLDriver().Renderer()
 	p.rc = &render.Context{
 		Renderer: rndr,
@@ -352,6 +357,12 @@ func (p *pipeline) joinSingleSource(ctx context.Context, jc *joinClause) (fromCl
 		return "", nil, err
 	}
 
+	for _, tbl := range jc.tables() {
+		if err = prepareTable(ctx, fromGrip, tbl.Table().Table, nil); err != nil {
+			return "", nil, err
+		}
+	}
+
 	rndr := fromGrip.SQLDriver().Renderer()
 	p.rc = &render.Context{
 		Renderer: rndr,
@@ -483,6 +494,9 @@ func (p *pipeline) joinCrossSource(ctx context.Context, jc *joinClause) (fromCla
 		if db, err = p.qc.Grips.Open(ctx, src, p.qc.openModeFor(src.Handle)); err != nil {
 			return "", nil, err
 		}
+		if err = prepareTable(ctx, db, tbl.Table().Table, nil); err != nil {
+			return "", nil, err
+		}
 
 		task := &joinCopyTask{
 			fromGrip: db,
@@ -28,7 +28,7 @@ func (p *pipeline) prepare(ctx context.Context, qm *queryModel) error {
 			return err
 		}
 	default:
-		if frags.From, p.targetGrip, err = p.prepareFromTable(ctx, qm.Table); err != nil {
+		if frags.From, p.targetGrip, err = p.prepareFromTable(ctx, qm.Table, pushdownFilter(qm.Where)); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,152 @@
+package libsq
+
+import (
+	"context"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+// prepareTable invokes driver.TablePreparer.PrepareTable for tbl, if grip
+// implements that interface. Arg filter may be nil.
+func prepareTable(ctx context.Context, grip driver.Grip, tbl string, filter *driver.Filter) error {
+	preparer, ok := grip.(driver.TablePreparer)
+	if !ok {
+		return nil
+	}
+
+	lg.FromContext(ctx).Debug("Preparing table",
+		lga.Src, grip.Source(), lga.Table, tbl, "filter", filter.String())
+	return preparer.PrepareTable(ctx, tbl, filter)
+}
+
+// pushdownFilter returns a driver.Filter consisting of the predicates of
+// where that are simple comparisons of a column with a literal, such as
+// ".age >= 21". Only the top-level conjuncts of where (the operands of
+// "&&") are considered; any other conjunct is omitted, which only ever
+// makes the filter less selective than where. If where is a disjunction,
+// or has no suitable conjuncts, nil is returned.
+//
+// Note that the SLQ AST doesn't encode operator precedence: it's left to
+// the database. Thus nested expressions without parentheses are flattened
+// before splitting at "&&", just as the expression is rendered to SQL, and
+// as is done by flattenStreamExpr.
+func pushdownFilter(where *ast.WhereNode) *driver.Filter {
+	if where == nil || where.Expr() == nil {
+		return nil
+	}
+
+	preds, ok := pushdownPreds(where.Expr())
+	if !ok || len(preds) == 0 {
+		return nil
+	}
+	return &driver.Filter{Preds: preds}
+}
+
+// pushdownPreds returns the filter predicates of the conjuncts of expr.
+// If expr is a disjunction at the top level, ok is false.
+func pushdownPreds(expr *ast.ExprNode) (preds []driver.FilterPred, ok bool) {
+	var conjunct []ast.Node
+	addConjunct := func() {
+		switch {
+		case len(conjunct) == 1:
+			// A parenthesized expression, e.g. "(.a > 1 && .b < 2)".
+			if child, isExpr := conjunct[0].(*ast.ExprNode); isExpr {
+				if childPreds, childOK := pushdownPreds(child); childOK {
+					preds = append(preds, childPreds...)
+				}
+			}
+		case len(conjunct) == 3:
+			if pred, predOK := pushdownPred(conjunct[0], conjunct[1], conjunct[2]); predOK {
+				preds = append(preds, pred)
+			}
+		default:
+		}
+		conjunct = conjunct[:0]
+	}
+
+	for _, node := range flattenPushdownExpr(expr) {
+		if op, isOp := node.(*ast.OperatorNode); isOp {
+			switch op.Text() {
+			case "||":
+				return nil, false
+			case "&&":
+				addConjunct()
+				continue
+			default:
+			}
+		}
+		conjunct = append(conjunct, node)
+	}
+	addConjunct()
+
+	return preds, true
+}
+
+// flattenPushdownExpr returns the children of expr, with the children of
+// any nested expression without parentheses spliced in.
+func flattenPushdownExpr(expr *ast.ExprNode) []ast.Node {
+	var nodes []ast.Node
+	for _, child := range expr.Children() {
+		if child, ok := child.(*ast.ExprNode); ok && !child.HasParens() {
+			nodes = append(nodes, flattenPushdownExpr(child)...)
+			continue
+		}
+		nodes = append(nodes, child)
+	}
+	return nodes
+}
+
+// pushdownPred returns the filter predicate for the comparison "a op b",
+// where one of a or b is a column selector, and the other is a literal. If
+// the comparison isn't of that form, ok is false.
+func pushdownPred(a, op, b ast.Node) (pred driver.FilterPred, ok bool) {
+	opNode, ok := op.(*ast.OperatorNode)
+	if !ok {
+		return pred, false
+	}
+	pred.Op = opNode.Text()
+
+	// flipped is the operator for "b op a".
+	var flipped string
+	switch pred.Op {
+	case "==", "!=":
+		flipped = pred.Op
+	case "<":
+		flipped = ">"
+	case "<=":
+		flipped = ">="
+	case ">":
+		flipped = "<"
+	case ">=":
+		flipped = "<="
+	default:
+		return pred, false
+	}
+
+	col, colOK := a.(*ast.ColSelectorNode)
+	lit, litOK := b.(*ast.LiteralNode)
+	if !colOK || !litOK {
+		if col, colOK = b.(*ast.ColSelectorNode); !colOK {
+			return pred, false
+		}
+		if lit, litOK = a.(*ast.LiteralNode); !litOK {
+			return pred, false
+		}
+		pred.Op = flipped
+	}
+
+	val, err := streamLiteral(lit)
+	if err != nil {
+		return pred, false
+	}
+	if val == nil && pred.Op != "==" && pred.Op != "!=" {
+		return pred, false
+	}
+
+	pred.Col = col.ColName()
+	pred.Val = val
+	return pred, true
+}
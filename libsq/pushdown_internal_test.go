@@ -0,0 +1,70 @@
+package libsq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/lg/lgt"
+	"github.com/neilotoole/sq/libsq/driver"
+)
+
+func TestPushdownFilter(t *testing.T) {
+	testCases := []struct {
+		where string
+		want  []driver.FilterPred
+	}{
+		{where: `.age >= 21`, want: []driver.FilterPred{{Col: "age", Op: ">=", Val: int64(21)}}},
+		{where: `21 < .age`, want: []driver.FilterPred{{Col: "age", Op: ">", Val: int64(21)}}},
+		{where: `.name == "bob"`, want: []driver.FilterPred{{Col: "name", Op: "==", Val: "bob"}}},
+		{where: `.score != 1.5`, want: []driver.FilterPred{{Col: "score", Op: "!=", Val: 1.5}}},
+		{where: `.active == true`, want: []driver.FilterPred{{Col: "active", Op: "==", Val: true}}},
+		{where: `.deleted == null`, want: []driver.FilterPred{{Col: "deleted", Op: "==", Val: nil}}},
+		{
+			where: `.age > 21 && .name == "bob"`,
+			want: []driver.FilterPred{
+				{Col: "age", Op: ">", Val: int64(21)},
+				{Col: "name", Op: "==", Val: "bob"},
+			},
+		},
+		{
+			// The disjunction can't be pushed down, but the other
+			// conjuncts can.
+			where: `.age > 21 && (.name == "bob" || .name == "alice") && (.x < 3 && .y > 4)`,
+			want: []driver.FilterPred{
+				{Col: "age", Op: ">", Val: int64(21)},
+				{Col: "x", Op: "<", Val: int64(3)},
+				{Col: "y", Op: ">", Val: int64(4)},
+			},
+		},
+		{
+			// The arithmetic conjunct is omitted.
+			where: `.age + 1 > 21 && .name == "bob"`,
+			want:  []driver.FilterPred{{Col: "name", Op: "==", Val: "bob"}},
+		},
+		{where: `.age > 21 || .name == "bob"`},
+		{where: `.age > 21 && .name == "bob" || .x == 1`},
+		{where: `.age > .min_age`},
+		{where: `.age < null`},
+		{where: `.age > $min`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.where, func(t *testing.T) {
+			a, err := ast.Parse(lgt.New(t), "@sakila | .actor | where("+tc.where+")")
+			require.NoError(t, err)
+			wheres, err := ast.NewInspector(a).FindWhereClauses()
+			require.NoError(t, err)
+			require.Len(t, wheres, 1)
+
+			got := pushdownFilter(wheres[0])
+			if tc.want == nil {
+				require.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			require.Equal(t, tc.want, got.Preds)
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package libsq
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+func init() { //nolint:gochecknoinits
+	// Record values are encoded as interface values, so the non-builtin
+	// concrete types must be registered with gob.
+	gob.Register(time.Time{})
+	gob.Register(decimal.Decimal{})
+}
+
+// resultCacheVersion is incremented when the encoding of result cache
+// entries changes, so that stale entries aren't misread.
+const resultCacheVersion = 1
+
+// resultCacheHeader is the first value encoded in a result cache entry. It
+// is followed by zero or more records, each encoded as []any.
+type resultCacheHeader struct {
+	Fields  []resultCacheField
+	Version int
+}
+
+// resultCacheField is the encodable form of record.FieldMeta. The scan
+// type is not encoded; it's derived from Kind when the entry is read.
+type resultCacheField struct {
+	Name              string
+	MungedName        string
+	DatabaseTypeName  string
+	Length            int64
+	Precision         int64
+	Scale             int64
+	Kind              kind.Kind
+	HasNullable       bool
+	HasLength         bool
+	HasPrecisionScale bool
+	Nullable          bool
+}
+
+// resultCache is a query's entry in the result cache. See
+// files.OptResultCacheTTL.
+type resultCache struct {
+	fs  *files.Files
+	fp  string
+	ttl time.Duration
+}
+
+// newResultCache returns the result cache entry for query executed against
+// srcs with args. If result caching doesn't apply, the returned value is
+// nil, which is safe to use. Result caching applies only if fs is non-nil,
+// and every one of srcs has a non-zero files.OptResultCacheTTL and does not
+// disable driver.OptIngestCache (e.g. via --no-cache).
+func newResultCache(ctx context.Context, fs *files.Files, srcs []*source.Source, query string,
+	args map[string]string,
+) (*resultCache, error) {
+	if fs == nil || len(srcs) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	var (
+		baseOpts = options.FromContext(ctx)
+		ttl      time.Duration
+	)
+	for i, src := range srcs {
+		o := options.Merge(baseOpts, src.Options)
+		if !driver.OptIngestCache.Get(o) {
+			return nil, nil //nolint:nilnil
+		}
+
+		srcTTL := files.OptResultCacheTTL.Get(o)
+		if srcTTL <= 0 {
+			return nil, nil //nolint:nilnil
+		}
+
+		if i == 0 || srcTTL < ttl {
+			ttl = srcTTL
+		}
+	}
+
+	fp, ok, err := fs.ResultCacheEntry(ctx, srcs, query, args)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &resultCache{fs: fs, fp: fp, ttl: ttl}, nil
+}
+
+// resultCache returns the result cache entry for p, or nil if result
+// caching doesn't apply. The entry is keyed on p.targetSQL, plus the
+// pre/post exec stmts, and, for cross-source queries, the tables copied
+// into the join DB: the join SQL names only the copies, not their origin.
+func (p *pipeline) resultCache(ctx context.Context) (*resultCache, error) {
+	if p.qc.Files == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	handles := p.inputSourceHandles()
+	srcs := make([]*source.Source, 0, len(handles))
+	for _, h := range handles {
+		src, err := p.qc.Collection.Get(h)
+		if err != nil {
+			// The scratch source, which isn't in the collection: there's
+			// no point caching a query that doesn't touch a source.
+			return nil, nil //nolint:nilnil
+		}
+		srcs = append(srcs, src)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(p.targetSQL)
+	for _, stmt := range p.qc.PreExecStmts {
+		sb.WriteString("\x00pre:")
+		sb.WriteString(stmt)
+	}
+	for _, stmt := range p.qc.PostExecStmts {
+		sb.WriteString("\x00post:")
+		sb.WriteString(stmt)
+	}
+	for _, t := range p.tasks {
+		if jt, ok := t.(*joinCopyTask); ok {
+			sb.WriteString("\x00copy:")
+			sb.WriteString(jt.fromGrip.Source().Handle)
+			sb.WriteByte('.')
+			sb.WriteString(jt.fromTbl.String())
+			sb.WriteString(" -> ")
+			sb.WriteString(jt.toTbl.String())
+		}
+	}
+
+	return newResultCache(ctx, p.qc.Files, srcs, sb.String(), p.qc.Args)
+}
+
+// exec writes the query results to recw. If rc has a live cache entry, the
+// cached records are written to recw. Otherwise, execFn is invoked to
+// execute the query and write the results to recw, and the results are
+// also written to the cache. If rc is nil, execFn is simply invoked.
+//
+// Failure to read or write the cache is not fatal: it is logged, and the
+// query is executed as normal.
+func (rc *resultCache) exec(ctx context.Context, recw RecordWriter,
+	execFn func(ctx context.Context, recw RecordWriter) error,
+) error {
+	if rc == nil {
+		return execFn(ctx, recw)
+	}
+
+	log := lg.FromContext(ctx).With(lga.Path, rc.fp)
+	f, ok, err := rc.fs.ResultCacheOpen(ctx, rc.fp, rc.ttl)
+	if err != nil {
+		log.Warn("Failed to open result cache entry", lga.Err, err)
+	}
+
+	if ok {
+		defer lg.WarnIfCloseError(log, lgm.CloseFileReader, f)
+		dec := gob.NewDecoder(bufio.NewReader(f))
+		var recMeta record.Meta
+		if recMeta, err = decodeResultCacheHeader(dec); err == nil {
+			log.Debug("Using cached query results")
+			return replayResults(ctx, dec, recMeta, recw)
+		}
+
+		// The entry is unusable, but nothing has been written to recw yet,
+		// so we can still execute the query.
+		log.Warn("Ignoring invalid result cache entry", lga.Err, err)
+		_ = os.Remove(rc.fp)
+	}
+
+	cacheFile, commit, discard, err := rc.fs.ResultCacheCreate(rc.fp)
+	if err != nil {
+		log.Warn("Failed to create result cache entry", lga.Err, err)
+		return execFn(ctx, recw)
+	}
+
+	cw := &resultCacheWriter{dest: recw, buf: bufio.NewWriter(cacheFile)}
+	cw.enc = gob.NewEncoder(cw.buf)
+	execErr := execFn(ctx, cw)
+	cacheErr := cw.wait()
+	if cacheErr == nil {
+		cacheErr = cw.buf.Flush()
+	}
+
+	switch {
+	case execErr != nil:
+		lg.WarnIfError(log, "Discard result cache entry", discard())
+	case cacheErr != nil:
+		log.Warn("Failed to write result cache entry", lga.Err, cacheErr)
+		lg.WarnIfError(log, "Discard result cache entry", discard())
+	default:
+		if err = commit(); err != nil {
+			log.Warn("Failed to write result cache entry", lga.Err, err)
+		} else {
+			log.Debug("Wrote query results to cache", lga.Count, cw.count)
+		}
+	}
+
+	return execErr
+}
+
+// decodeResultCacheHeader decodes the result cache entry header from dec,
+// returning the record.Meta it describes.
+func decodeResultCacheHeader(dec *gob.Decoder) (record.Meta, error) {
+	var hdr resultCacheHeader
+	if err := dec.Decode(&hdr); err != nil {
+		return nil, errz.Wrap(err, "decode result cache header")
+	}
+
+	if hdr.Version != resultCacheVersion {
+		return nil, errz.Errorf("result cache entry version %d: expected %d", hdr.Version, resultCacheVersion)
+	}
+
+	recMeta := make(record.Meta, len(hdr.Fields))
+	for i, fld := range hdr.Fields {
+		recMeta[i] = record.NewFieldMeta(&record.ColumnTypeData{
+			ScanType:          scanTypeForKind(fld.Kind),
+			Name:              fld.Name,
+			DatabaseTypeName:  fld.DatabaseTypeName,
+			Length:            fld.Length,
+			Precision:         fld.Precision,
+			Scale:             fld.Scale,
+			Kind:              fld.Kind,
+			HasNullable:       fld.HasNullable,
+			HasLength:         fld.HasLength,
+			HasPrecisionScale: fld.HasPrecisionScale,
+			Nullable:          fld.Nullable,
+		}, fld.MungedName)
+	}
+
+	return recMeta, nil
+}
+
+// encodeResultCacheHeader encodes the result cache entry header for
+// recMeta to enc.
+func encodeResultCacheHeader(enc *gob.Encoder, recMeta record.Meta) error {
+	hdr := resultCacheHeader{Version: resultCacheVersion, Fields: make([]resultCacheField, len(recMeta))}
+	for i, fm := range recMeta {
+		fld := resultCacheField{
+			Name:             fm.Name(),
+			MungedName:       fm.MungedName(),
+			DatabaseTypeName: fm.DatabaseTypeName(),
+			Kind:             fm.Kind(),
+		}
+		fld.Length, fld.HasLength = fm.Length()
+		fld.Precision, fld.Scale, fld.HasPrecisionScale = fm.DecimalSize()
+		fld.Nullable, fld.HasNullable = fm.Nullable()
+		hdr.Fields[i] = fld
+	}
+
+	return errz.Wrap(enc.Encode(hdr), "encode result cache header")
+}
+
+// scanTypeForKind returns the nullable scan type for k.
+func scanTypeForKind(k kind.Kind) reflect.Type {
+	switch k { //nolint:exhaustive
+	case kind.Int:
+		return sqlz.RTypeNullInt64
+	case kind.Float:
+		return sqlz.RTypeNullFloat64
+	case kind.Decimal:
+		return sqlz.RTypeNullDecimal
+	case kind.Bool:
+		return sqlz.RTypeNullBool
+	case kind.Text:
+		return sqlz.RTypeNullString
+	case kind.Datetime, kind.Date, kind.Time:
+		return sqlz.RTypeNullTime
+	case kind.Bytes:
+		return sqlz.RTypeBytes
+	default:
+		return sqlz.RTypeAny
+	}
+}
+
+// replayResults writes the cached records decoded from dec to recw. This
+// mirrors the record-sending loop of QuerySQL.
+func replayResults(ctx context.Context, dec *gob.Decoder, recMeta record.Meta, recw RecordWriter) error {
+	log := lg.FromContext(ctx)
+
+	ctx, cancelFn := context.WithCancel(ctx)
+	recordCh, errCh, err := recw.Open(ctx, cancelFn, recMeta)
+	if err != nil {
+		cancelFn()
+		return err
+	}
+	defer close(recordCh)
+
+	for {
+		var rec []any
+		if err = dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			cancelFn()
+			return errz.Wrap(err, "read result cache entry")
+		}
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(context.Cause(ctx), errz.ErrStop) {
+				lg.WarnIfError(log, lgm.CtxDone, ctx.Err())
+			}
+			cancelFn()
+			return ctx.Err()
+		case err = <-errCh:
+			lg.WarnIfError(log, "write record", err)
+			cancelFn()
+			return err
+		case recordCh <- record.Record(rec):
+		}
+	}
+}
+
+var _ RecordWriter = (*resultCacheWriter)(nil)
+
+// resultCacheWriter is a RecordWriter that passes records through to dest,
+// additionally encoding them to a result cache entry. It is not intended to
+// be used beyond resultCache.exec: in particular, its Wait method must not
+// be invoked; the caller waits on dest.
+type resultCacheWriter struct {
+	dest  RecordWriter
+	buf   *bufio.Writer
+	enc   *gob.Encoder
+	err   error
+	done  chan struct{}
+	count int64
+}
+
+// Open implements RecordWriter. It opens dest, and starts a goroutine that
+// encodes each record received on the returned channel, and then sends it
+// on to dest. The cache entry is abandoned on the first encoding error, but
+// records continue to be sent to dest.
+func (w *resultCacheWriter) Open(ctx context.Context, cancelFn context.CancelFunc, recMeta record.Meta) (
+	chan<- record.Record, <-chan error, error,
+) {
+	destRecCh, errCh, err := w.dest.Open(ctx, cancelFn, recMeta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.err = encodeResultCacheHeader(w.enc, recMeta)
+	w.done = make(chan struct{})
+	recCh := make(chan record.Record)
+
+	go func() {
+		defer close(w.done)
+		defer close(destRecCh)
+
+		for rec := range recCh {
+			if w.err == nil {
+				if w.err = w.enc.Encode([]any(rec)); w.err == nil {
+					w.count++
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				w.err = errz.Err(context.Cause(ctx))
+				// Drain recCh, so that the sender isn't blocked: it
+				// will also see ctx done, and close recCh.
+				for range recCh { //nolint:revive
+				}
+				return
+			case destRecCh <- rec:
+			}
+		}
+	}()
+
+	return recCh, errCh, nil
+}
+
+// Wait implements RecordWriter, but should not be invoked. See
+// resultCacheWriter.wait.
+func (w *resultCacheWriter) Wait() (written int64, err error) {
+	return 0, errz.New("result cache writer: Wait should not be invoked")
+}
+
+// wait waits for all records to be passed to dest, returning any error
+// that occurred while writing the cache entry. It is not an error if Open
+// was never invoked, but in that case, the cache entry is empty and must
+// be discarded.
+func (w *resultCacheWriter) wait() error {
+	if w.done == nil {
+		return errz.New("result cache writer: not opened")
+	}
+
+	<-w.done
+	return w.err
+}
@@ -0,0 +1,197 @@
+package libsq
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lgt"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/files"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// sinkWriter is a minimal RecordWriter that collects records.
+type sinkWriter struct {
+	done    chan struct{}
+	recMeta record.Meta
+	recs    []record.Record
+}
+
+func (w *sinkWriter) Open(_ context.Context, cancelFn context.CancelFunc, recMeta record.Meta) (
+	chan<- record.Record, <-chan error, error,
+) {
+	w.recMeta = recMeta
+	w.done = make(chan struct{})
+	recCh, errCh := make(chan record.Record), make(chan error)
+	go func() {
+		defer close(w.done)
+		defer close(errCh)
+		defer cancelFn()
+		for rec := range recCh {
+			w.recs = append(w.recs, rec)
+		}
+	}()
+	return recCh, errCh, nil
+}
+
+func (w *sinkWriter) Wait() (int64, error) {
+	<-w.done
+	return int64(len(w.recs)), nil
+}
+
+func newTestResultCacheFiles(t *testing.T) (context.Context, *files.Files) {
+	t.Helper()
+	ctx := lg.NewContext(context.Background(), lgt.New(t))
+	noopLock := func(context.Context) (func(), error) { return func() {}, nil }
+	fs, err := files.New(ctx, nil, noopLock, t.TempDir(), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, fs.Close()) })
+	return ctx, fs
+}
+
+func TestResultCache_exec(t *testing.T) {
+	ctx, fs := newTestResultCacheFiles(t)
+
+	recMeta := record.Meta{
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "id", Kind: kind.Int}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "name", Kind: kind.Text, HasNullable: true, Nullable: true}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "amount", Kind: kind.Decimal}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "ok", Kind: kind.Bool}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "at", Kind: kind.Datetime}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "blob", Kind: kind.Bytes}, ""),
+		record.NewFieldMeta(&record.ColumnTypeData{Name: "id", Kind: kind.Float}, "id_1"),
+	}
+	wantRecs := []record.Record{
+		{int64(1), "alice", decimal.RequireFromString("12.34"), true,
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), []byte("abc"), 1.5},
+		{int64(2), nil, decimal.RequireFromString("-0.5"), false, nil, nil, nil},
+	}
+
+	var execCount int
+	execFn := func(ctx context.Context, recw RecordWriter) error {
+		execCount++
+		ctx, cancelFn := context.WithCancel(ctx)
+		recCh, _, err := recw.Open(ctx, cancelFn, recMeta)
+		if err != nil {
+			cancelFn()
+			return err
+		}
+		defer close(recCh)
+		for _, rec := range wantRecs {
+			recCh <- rec
+		}
+		return nil
+	}
+
+	src := &source.Source{
+		Handle:   "@a",
+		Type:     drivertype.SQLite,
+		Location: "sqlite3:///tmp/a.db",
+		Options:  options.Options{files.OptResultCacheTTL.Key(): time.Hour},
+	}
+	srcs := []*source.Source{src}
+
+	runQuery := func(args map[string]string) *sinkWriter {
+		rc, err := newResultCache(ctx, fs, srcs, "SELECT * FROM t", args)
+		require.NoError(t, err)
+		require.NotNil(t, rc)
+		sink := &sinkWriter{}
+		require.NoError(t, rc.exec(ctx, sink, execFn))
+		_, err = sink.Wait()
+		require.NoError(t, err)
+		return sink
+	}
+
+	sink := runQuery(nil)
+	require.Equal(t, 1, execCount)
+	require.Len(t, sink.recs, len(wantRecs))
+
+	sink = runQuery(nil)
+	require.Equal(t, 1, execCount, "second query should be served from cache")
+	require.Len(t, sink.recs, len(wantRecs))
+	for i := range wantRecs {
+		require.True(t, record.Equal(wantRecs[i], sink.recs[i]), "record %d: %v", i, sink.recs[i])
+	}
+	require.Equal(t, recMeta.MungedNames(), sink.recMeta.MungedNames())
+	require.Equal(t, recMeta.Kinds(), sink.recMeta.Kinds())
+	nullable, ok := sink.recMeta[1].Nullable()
+	require.True(t, ok)
+	require.True(t, nullable)
+
+	_ = runQuery(map[string]string{"x": "1"})
+	require.Equal(t, 2, execCount, "different args should not be served from cache")
+}
+
+func TestResultCache_exec_errorNotCached(t *testing.T) {
+	ctx, fs := newTestResultCacheFiles(t)
+
+	src := &source.Source{
+		Handle:   "@a",
+		Type:     drivertype.SQLite,
+		Location: "sqlite3:///tmp/a.db",
+		Options:  options.Options{files.OptResultCacheTTL.Key(): time.Hour},
+	}
+	rc, err := newResultCache(ctx, fs, []*source.Source{src}, "SELECT * FROM t", nil)
+	require.NoError(t, err)
+	require.NotNil(t, rc)
+
+	wantErr := errors.New("query failed")
+	recMeta := record.Meta{record.NewFieldMeta(&record.ColumnTypeData{Name: "id", Kind: kind.Int}, "")}
+	gotErr := rc.exec(ctx, &sinkWriter{}, func(ctx context.Context, recw RecordWriter) error {
+		ctx, cancelFn := context.WithCancel(ctx)
+		recCh, _, err := recw.Open(ctx, cancelFn, recMeta)
+		require.NoError(t, err)
+		recCh <- record.Record{int64(1)}
+		close(recCh)
+		return wantErr
+	})
+	require.ErrorIs(t, gotErr, wantErr)
+
+	_, err = os.Stat(rc.fp)
+	require.True(t, os.IsNotExist(err), "failed query results must not be cached")
+}
+
+func TestNewResultCache_notApplicable(t *testing.T) {
+	ctx, fs := newTestResultCacheFiles(t)
+
+	newSrc := func(o options.Options) *source.Source {
+		return &source.Source{Handle: "@a", Type: drivertype.SQLite, Location: "sqlite3:///tmp/a.db", Options: o}
+	}
+
+	testCases := []struct {
+		fs   *files.Files
+		name string
+		srcs []*source.Source
+	}{
+		{name: "nil_files", srcs: []*source.Source{newSrc(options.Options{files.OptResultCacheTTL.Key(): time.Hour})}},
+		{name: "no_ttl", fs: fs, srcs: []*source.Source{newSrc(nil)}},
+		{name: "no_cache", fs: fs, srcs: []*source.Source{newSrc(options.Options{
+			files.OptResultCacheTTL.Key(): time.Hour,
+			driver.OptIngestCache.Key():   false,
+		})}},
+		{name: "one_src_no_ttl", fs: fs, srcs: []*source.Source{
+			newSrc(options.Options{files.OptResultCacheTTL.Key(): time.Hour}),
+			{Handle: "@b", Type: drivertype.SQLite, Location: "sqlite3:///tmp/b.db"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc, err := newResultCache(ctx, tc.fs, tc.srcs, "SELECT 1", nil)
+			require.NoError(t, err)
+			require.Nil(t, rc)
+		})
+	}
+}
@@ -41,12 +41,24 @@ const (
 	// Oracle is for Oracle Database.
 	Oracle = Type("oracle")
 
+	// ODBC is for databases that are reached via an ODBC driver, such
+	// as Db2, Informix, or Teradata.
+	ODBC = Type("odbc")
+
+	// MongoDB is for MongoDB. Its collections are ingested, and queried
+	// via SQL.
+	MongoDB = Type("mongodb")
+
 	// CSV is for Comma-Separated Values.
 	CSV = Type("csv")
 
 	// TSV is for Tab-Separated Values.
 	TSV = Type("tsv")
 
+	// FixedWidth is for fixed-width text, in which each column occupies
+	// a fixed range of character positions on each line.
+	FixedWidth = Type("fixedwidth")
+
 	// JSON is for plain-old JSON.
 	JSON = Type("json")
 
@@ -56,6 +68,9 @@ const (
 	// JSONL is for JSON Lines, aka ndjson (newline-delimited).
 	JSONL = Type("jsonl")
 
+	// REST is for paginated REST APIs that return JSON.
+	REST = Type("rest")
+
 	// XLSX is for Microsoft Excel spreadsheets.
 	XLSX = Type("xlsx")
 )
@@ -20,11 +20,15 @@ func TestType_String(t *testing.T) {
 		{drivertype.MSSQL, "sqlserver"},
 		{drivertype.MySQL, "mysql"},
 		{drivertype.ClickHouse, "clickhouse"},
+		{drivertype.ODBC, "odbc"},
+		{drivertype.MongoDB, "mongodb"},
 		{drivertype.CSV, "csv"},
 		{drivertype.TSV, "tsv"},
+		{drivertype.FixedWidth, "fixedwidth"},
 		{drivertype.JSON, "json"},
 		{drivertype.JSONA, "jsona"},
 		{drivertype.JSONL, "jsonl"},
+		{drivertype.REST, "rest"},
 		{drivertype.XLSX, "xlsx"},
 	}
 
@@ -46,11 +50,15 @@ func TestType_Constants(t *testing.T) {
 	require.Equal(t, drivertype.Type("sqlserver"), drivertype.MSSQL)
 	require.Equal(t, drivertype.Type("mysql"), drivertype.MySQL)
 	require.Equal(t, drivertype.Type("clickhouse"), drivertype.ClickHouse)
+	require.Equal(t, drivertype.Type("odbc"), drivertype.ODBC)
+	require.Equal(t, drivertype.Type("mongodb"), drivertype.MongoDB)
 	require.Equal(t, drivertype.Type("csv"), drivertype.CSV)
 	require.Equal(t, drivertype.Type("tsv"), drivertype.TSV)
+	require.Equal(t, drivertype.Type("fixedwidth"), drivertype.FixedWidth)
 	require.Equal(t, drivertype.Type("json"), drivertype.JSON)
 	require.Equal(t, drivertype.Type("jsona"), drivertype.JSONA)
 	require.Equal(t, drivertype.Type("jsonl"), drivertype.JSONL)
+	require.Equal(t, drivertype.Type("rest"), drivertype.REST)
 	require.Equal(t, drivertype.Type("xlsx"), drivertype.XLSX)
 }
 
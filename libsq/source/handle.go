@@ -356,6 +356,20 @@ func suggestNameForScheme(scheme, body string) (string, bool) {
 		}
 		return "", false
 
+	case "sops":
+		// <file>#<key.path>. The key path's final segment is usually a
+		// field name such as "password" or "dsn", so use the segment
+		// before it (its last "/" component, as keys written by "sq
+		// config sops migrate" are handles), else the file basename.
+		fp, keyPath, _ := strings.Cut(body, "#")
+		if keys := strings.Split(keyPath, "."); len(keys) > 1 {
+			k := keys[len(keys)-2]
+			if k = k[strings.LastIndexByte(k, '/')+1:]; k != "" {
+				return strings.ToLower(k), true
+			}
+		}
+		return suggestNameForScheme("file", fp)
+
 	case "keyring":
 		// Legacy handle-encoded form "@<handle>/<slot>" — extract the
 		// handle. Opaque Crockford IDs have no meaningful segment;
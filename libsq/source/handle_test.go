@@ -169,6 +169,12 @@ func TestSuggestHandle_FromPlaceholder(t *testing.T) {
 		{loc: "${vault:secret/data/sakila}", want: "@sakila"},
 		{loc: "${vault:secret/data/sakila#password}", want: "@sakila"},
 
+		// sops: the key path segment before the field, else the file
+		// basename.
+		{loc: "${sops:/proj/secrets.enc.yaml#sakila.password}", want: "@sakila"},
+		{loc: "${sops:/proj/secrets.yaml.age#sakila/pg.dsn}", want: "@pg"},
+		{loc: "${sops:/proj/sakila.yaml.age#dsn}", want: "@sakila_yaml"},
+
 		// keyring legacy handle-encoded form — extract handle name.
 		{loc: "${keyring:@sakila/conn_str}", want: "@sakila"},
 		{loc: "${keyring:@prod_db/password}", want: "@prod_db"},
@@ -31,6 +31,28 @@ var dbSchemes = []string{
 	"duckdb",
 	"clickhouse",
 	"oracle",
+	"odbc",
+	"mongodb",
+	"mongodb+srv",
+}
+
+// urlDBSchemes is the set of network DB driver schemes that xo/dburl
+// doesn't know. Their locations are parsed and redacted via net/url
+// instead.
+var urlDBSchemes = []string{
+	"rqlite",
+	"mongodb",
+	"mongodb+srv",
+}
+
+// isURLDBLoc returns true if loc has one of urlDBSchemes.
+func isURLDBLoc(loc string) bool {
+	for _, scheme := range urlDBSchemes {
+		if strings.HasPrefix(loc, scheme+"://") {
+			return true
+		}
+	}
+	return false
 }
 
 // fileDBSchemes is the set of file-based DB driver schemes whose bare
@@ -96,6 +118,12 @@ func WithPassword(loc, passw string) (string, error) {
 		return loc, nil
 	}
 
+	if strings.HasPrefix(loc, odbcPrefix) {
+		// An ODBC connection string isn't a URL, and its user may be
+		// configured in the DSN, so it needn't have a username.
+		return withODBCPassword(loc, passw)
+	}
+
 	u, err := url.ParseRequestURI(loc)
 	if err != nil {
 		return "", errz.Err(err)
@@ -160,13 +188,16 @@ func Short(loc string) string {
 	}
 
 	// It's a SQL driver.
-	//
-	// rqlite is a network SQL driver, but xo/dburl doesn't know its
-	// scheme, so Parse-ing through dburl returns an error and the
-	// fallback would echo inline credentials. Handle it via
+	if strings.HasPrefix(loc, odbcPrefix) {
+		return shortODBC(loc)
+	}
+
+	// rqlite and MongoDB are network drivers, but xo/dburl doesn't know
+	// their schemes, so Parse-ing through dburl returns an error and the
+	// fallback would echo inline credentials. Handle them via
 	// url.ParseRequestURI here, mirroring the user@host[:port] shape
 	// used for the other DSN drivers below.
-	if strings.HasPrefix(loc, "rqlite://") {
+	if isURLDBLoc(loc) {
 		ru, err := url.ParseRequestURI(loc)
 		if err != nil {
 			// Couldn't parse; fall back to best-effort credential masking
@@ -179,6 +210,10 @@ func Short(loc string) string {
 			sb.WriteString("@")
 		}
 		sb.WriteString(ru.Host)
+		if ru.Scheme != "rqlite" {
+			// rqlite has no database name, but MongoDB does.
+			sb.WriteString(ru.Path)
+		}
 		return sb.String()
 	}
 
@@ -309,7 +344,7 @@ func Parse(loc string) (*Fields, error) {
 	}
 
 	if u, ok := isHTTP(loc); ok {
-		// It's an HTTP or HTTPS URL.
+		// It's an HTTP or HTTPS URL, or an object store URL.
 		fields.Scheme = u.Scheme
 		fields.Hostname = u.Hostname()
 		if u.Port() != "" {
@@ -366,6 +401,16 @@ func Parse(loc string) (*Fields, error) {
 		return parseRqlite(loc, fields)
 	}
 
+	// Likewise for MongoDB.
+	if strings.HasPrefix(loc, "mongodb://") || strings.HasPrefix(loc, "mongodb+srv://") {
+		return parseMongoDB(loc, fields)
+	}
+
+	// An ODBC location is a connection string rather than a URL.
+	if strings.HasPrefix(loc, odbcPrefix) {
+		return parseODBC(loc, fields)
+	}
+
 	if after, ok := strings.CutPrefix(loc, duckdbPrefix); ok {
 		fpath := after
 
@@ -488,6 +533,41 @@ func parseRqlite(loc string, fields *Fields) (*Fields, error) {
 	return fields, nil
 }
 
+// parseMongoDB parses a mongodb:// or mongodb+srv:// location into
+// fields. Like rqlite, the MongoDB schemes are unknown to xo/dburl. A
+// MongoDB URI may list several hosts, e.g. "mongodb://h1:27017,h2/db":
+// fields.Hostname and fields.Port are those of the first host.
+func parseMongoDB(loc string, fields *Fields) (*Fields, error) {
+	u, err := url.ParseRequestURI(loc)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, errz.Wrapf(err, "parse location: %s", redactBestEffort(loc))
+	}
+
+	fields.Scheme = u.Scheme
+	fields.DriverType = drivertype.MongoDB
+	fields.DSN = loc
+	if u.User != nil {
+		fields.User = u.User.Username()
+		fields.Pass, _ = u.User.Password()
+	}
+
+	first, _, _ := strings.Cut(u.Host, ",")
+	host := &url.URL{Host: first}
+	fields.Hostname = host.Hostname()
+	if host.Port() != "" {
+		if fields.Port, err = strconv.Atoi(host.Port()); err != nil {
+			return nil, errz.Wrapf(err, "parse location: invalid port {%s}: %s", host.Port(),
+				redactBestEffort(loc))
+		}
+	}
+	fields.Name = strings.TrimPrefix(u.Path, "/")
+	return fields, nil
+}
+
 // Abs returns the absolute path of loc. That is, relative
 // paths etc. are resolved. If loc is not a file path or
 // it cannot be processed, loc is returned unmodified.
@@ -566,6 +646,7 @@ const (
 	TypeFile    = "local_file"
 	TypeSQL     = "sql"
 	TypeHTTP    = "http_file"
+	TypeObject  = "object_store"
 	TypeUnknown = "unknown"
 )
 
@@ -581,6 +662,10 @@ func TypeOf(loc string) Type {
 	case strings.HasPrefix(loc, "http://"),
 		strings.HasPrefix(loc, "https://"):
 		return TypeHTTP
+	case strings.HasPrefix(loc, "s3://"),
+		strings.HasPrefix(loc, "gs://"),
+		strings.HasPrefix(loc, "az://"):
+		return TypeObject
 	default:
 	}
 
@@ -590,21 +675,32 @@ func TypeOf(loc string) Type {
 	return TypeFile
 }
 
-// IsURL returns true if t is TypeHTTP or TypeSQL.
+// IsURL returns true if t is TypeHTTP, TypeObject or TypeSQL.
 func (t Type) IsURL() bool {
-	return t == TypeHTTP || t == TypeSQL
+	return t == TypeHTTP || t == TypeObject || t == TypeSQL
 }
 
-// isHTTP tests if s is a well-structured HTTP or HTTPS url, and
-// if so, returns the url and true.
+// IsRemote returns true if t is TypeHTTP or TypeObject, that is, if the
+// location is a remote document that is downloaded.
+func (t Type) IsRemote() bool {
+	return t == TypeHTTP || t == TypeObject
+}
+
+// isHTTP tests if s is a well-structured HTTP or HTTPS url, or an object
+// store url such as "s3://bucket/key", and if so, returns the url and true.
 func isHTTP(s string) (u *url.URL, ok bool) {
 	var err error
 	u, err = url.Parse(s)
-	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+	if err != nil || u.Host == "" {
 		return nil, false
 	}
 
-	return u, true
+	switch u.Scheme {
+	case "http", "https", "s3", "gs", "az":
+		return u, true
+	default:
+		return nil, false
+	}
 }
 
 // Redact returns a redacted version of the source location loc, with
@@ -706,6 +802,12 @@ func redactRaw(loc string, sentinels []string) string {
 		return loc
 	}
 
+	if strings.HasPrefix(loc, odbcPrefix) {
+		// An ODBC connection string has no userinfo or query params:
+		// its secrets are attributes such as "PWD=secret".
+		return replaceODBCSecrets(loc, "xxxxx", sentinels)
+	}
+
 	var redacted string
 	switch {
 	case strings.HasPrefix(loc, "/"),
@@ -722,9 +824,9 @@ func redactRaw(loc string, sentinels []string) string {
 			break
 		}
 		redacted = u.Redacted()
-	case strings.HasPrefix(loc, "rqlite://"):
-		// rqlite is a network SQL driver unknown to dburl; redact its
-		// userinfo explicitly rather than relying on the best-effort
+	case isURLDBLoc(loc):
+		// rqlite and MongoDB are network drivers unknown to dburl; redact
+		// their userinfo explicitly rather than relying on the best-effort
 		// regex fallback below.
 		u, err := url.ParseRequestURI(loc)
 		if err != nil {
@@ -872,6 +974,10 @@ func StripSecrets(loc string) string {
 // an unencoded '#' always starts the fragment, so it is carved off
 // before query processing lest it be swallowed by a blanked value.
 func stripSecretsRaw(loc string, sentinels []string) string {
+	if strings.HasPrefix(loc, odbcPrefix) {
+		return replaceODBCSecrets(loc, "", sentinels)
+	}
+
 	loc, frag, hasFrag := strings.Cut(loc, "#")
 	base, query, hasQuery := strings.Cut(loc, "?")
 	base = stripUserinfoPassword(base, sentinels)
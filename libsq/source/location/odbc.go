@@ -0,0 +1,247 @@
+package location
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// odbcPrefix is the prefix of an ODBC location. The remainder of the
+// location is an ODBC connection string, such as
+// "odbc://DSN=db2prod;UID=alice;PWD=secret", or the name of a DSN, such
+// as "odbc://db2prod".
+const odbcPrefix = "odbc://"
+
+// ODBCConnString returns the ODBC connection string of an "odbc://"
+// location. A bare DSN name, such as "odbc://db2prod", is returned as
+// "DSN=db2prod".
+func ODBCConnString(loc string) (string, error) {
+	s, ok := strings.CutPrefix(loc, odbcPrefix)
+	if !ok {
+		return "", errz.Errorf("not an ODBC location: %s", Redact(loc))
+	}
+	if strings.TrimSpace(s) == "" {
+		return "", errz.Errorf("ODBC location has no connection string: %s", loc)
+	}
+	if !strings.Contains(s, "=") {
+		return "DSN=" + s, nil
+	}
+	return s, nil
+}
+
+// odbcAttr is a key=value attribute of an ODBC connection string. The
+// offsets of the value's raw text (including any braces) are retained,
+// so that the value can be replaced in place, as when redacting.
+type odbcAttr struct {
+	key      string
+	val      string
+	keyStart int
+	start    int
+	end      int
+}
+
+// parseODBCAttrs parses the ;-delimited attributes of ODBC connection
+// string s. A value enclosed in braces, e.g. "PWD={a;b}", may contain
+// semicolons; within braces, "}}" is a literal "}". Malformed input is
+// parsed best-effort, rather than rejected: the driver manager has the
+// final say.
+func parseODBCAttrs(s string) []odbcAttr {
+	var attrs []odbcAttr
+	for i := 0; i < len(s); {
+		eq := strings.IndexByte(s[i:], '=')
+		semi := strings.IndexByte(s[i:], ';')
+		if eq < 0 || (semi >= 0 && semi < eq) {
+			// A keyless fragment such as "foo;": skip it.
+			if semi < 0 {
+				break
+			}
+			i += semi + 1
+			continue
+		}
+
+		attr := odbcAttr{key: strings.TrimSpace(s[i : i+eq]), keyStart: i}
+		j := i + eq + 1
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		attr.start = j
+
+		if j < len(s) && s[j] == '{' {
+			var sb strings.Builder
+			j++
+			for j < len(s) {
+				if s[j] == '}' {
+					if j+1 < len(s) && s[j+1] == '}' {
+						sb.WriteByte('}')
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				sb.WriteByte(s[j])
+				j++
+			}
+			attr.val = sb.String()
+			attr.end = j
+			if k := strings.IndexByte(s[j:], ';'); k >= 0 {
+				j += k
+			} else {
+				j = len(s)
+			}
+		} else {
+			k := strings.IndexByte(s[j:], ';')
+			if k < 0 {
+				k = len(s) - j
+			}
+			attr.end = j + k
+			attr.val = strings.TrimSpace(s[j:attr.end])
+			j = attr.end
+		}
+
+		attrs = append(attrs, attr)
+		i = j + 1
+	}
+	return attrs
+}
+
+// odbcAttrVal returns the value of the first attribute of attrs whose
+// key matches one of keys, case-insensitively.
+func odbcAttrVal(attrs []odbcAttr, keys ...string) string {
+	for _, key := range keys {
+		for _, attr := range attrs {
+			if strings.EqualFold(attr.key, key) {
+				return attr.val
+			}
+		}
+	}
+	return ""
+}
+
+// quoteODBCAttrVal returns val, enclosed in braces if it contains
+// characters that are special in an ODBC connection string.
+func quoteODBCAttrVal(val string) string {
+	if !strings.ContainsAny(val, ";{}=") && strings.TrimSpace(val) == val {
+		return val
+	}
+	return "{" + strings.ReplaceAll(val, "}", "}}") + "}"
+}
+
+// parseODBC parses an odbc:// location into fields. The user, password,
+// host, port and name are taken from the conventional attributes of the
+// connection string, where present.
+func parseODBC(loc string, fields *Fields) (*Fields, error) {
+	connStr, err := ODBCConnString(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := parseODBCAttrs(connStr)
+	fields.Scheme = "odbc"
+	fields.DriverType = drivertype.ODBC
+	fields.DSN = connStr
+	fields.User = odbcAttrVal(attrs, "UID", "USER", "USERNAME")
+	fields.Pass = odbcAttrVal(attrs, "PWD", "PASSWORD")
+	fields.Hostname = odbcAttrVal(attrs, "SERVER", "HOST", "HOSTNAME")
+	if port := odbcAttrVal(attrs, "PORT"); port != "" {
+		if fields.Port, err = strconv.Atoi(port); err != nil {
+			return nil, errz.Wrapf(err, "parse location: invalid port {%s}: %s", port, Redact(loc))
+		}
+	}
+
+	fields.Name = odbcAttrVal(attrs, "DATABASE", "DBNAME", "DSN")
+	if strings.ContainsAny(fields.Name, `/\`) {
+		// A file-based database, e.g. "Database=/data/sakila.db".
+		fields.Name = filepath.Base(fields.Name)
+		fields.Name = strings.TrimSuffix(fields.Name, filepath.Ext(fields.Name))
+	}
+	return fields, nil
+}
+
+// shortODBC returns the short form of an odbc:// location, such as
+// "alice@db2prod" or "alice@dbhost:50000/sample".
+func shortODBC(loc string) string {
+	fields, err := parseODBC(loc, &Fields{Loc: loc})
+	if err != nil {
+		return redactBestEffort(loc)
+	}
+
+	sb := strings.Builder{}
+	if fields.User != "" {
+		sb.WriteString(fields.User)
+		sb.WriteString("@")
+	}
+	if fields.Hostname != "" {
+		sb.WriteString(fields.Hostname)
+		if fields.Port != 0 {
+			sb.WriteString(":")
+			sb.WriteString(strconv.Itoa(fields.Port))
+		}
+		if fields.Name != "" {
+			sb.WriteString("/")
+		}
+	}
+	sb.WriteString(fields.Name)
+	return sb.String()
+}
+
+// replaceODBCSecrets sets the value of each secret-bearing attribute
+// (per IsSecretQueryParam) of an odbc:// location to newValue, leaving
+// the rest of loc byte-for-byte intact. Values composed entirely of
+// sentinels are kept, as are empty values.
+func replaceODBCSecrets(loc, newValue string, sentinels []string) string {
+	connStr := strings.TrimPrefix(loc, odbcPrefix)
+	attrs := parseODBCAttrs(connStr)
+
+	var sb strings.Builder
+	sb.WriteString(odbcPrefix)
+	last := 0
+	for _, attr := range attrs {
+		if attr.val == "" || !IsSecretQueryParam(attr.key) || isOnlySentinels(attr.val, sentinels) {
+			continue
+		}
+		sb.WriteString(connStr[last:attr.start])
+		sb.WriteString(newValue)
+		last = attr.end
+	}
+	sb.WriteString(connStr[last:])
+	return sb.String()
+}
+
+// withODBCPassword returns odbc:// location loc with its PWD attribute
+// set to passw, or removed if passw is empty.
+func withODBCPassword(loc, passw string) (string, error) {
+	connStr, err := ODBCConnString(loc)
+	if err != nil {
+		return "", err
+	}
+
+	for _, attr := range parseODBCAttrs(connStr) {
+		if !strings.EqualFold(attr.key, "PWD") && !strings.EqualFold(attr.key, "PASSWORD") {
+			continue
+		}
+		if passw != "" {
+			return odbcPrefix + connStr[:attr.start] + quoteODBCAttrVal(passw) + connStr[attr.end:], nil
+		}
+
+		// Remove the attribute, and its trailing semicolon.
+		rest := connStr[attr.end:]
+		if i := strings.IndexByte(rest, ';'); i >= 0 {
+			rest = rest[i+1:]
+		} else {
+			rest = ""
+		}
+		return odbcPrefix + strings.TrimSuffix(connStr[:attr.keyStart]+rest, ";"), nil
+	}
+
+	if passw == "" {
+		return odbcPrefix + connStr, nil
+	}
+	if !strings.HasSuffix(connStr, ";") {
+		connStr += ";"
+	}
+	return odbcPrefix + connStr + "PWD=" + quoteODBCAttrVal(passw), nil
+}
@@ -426,6 +426,26 @@ type Column struct { //nolint:govet // field alignment
 	Generated     bool   `json:"generated,omitempty" yaml:"generated,omitempty"`
 	GeneratedExpr string `json:"generated_expr,omitempty" yaml:"generated_expr,omitempty"`
 	Collation     string `json:"collation,omitempty" yaml:"collation,omitempty"`
+
+	// Profile holds statistics for the column's values. It is nil unless
+	// the column was profiled, as by "sq inspect --profile".
+	Profile *ColumnProfile `json:"profile,omitempty" yaml:"profile,omitempty"`
+
+	// IngestOverride is non-nil if the column of an ingested document
+	// source was explicitly overridden, as by option "ingest.schema".
+	IngestOverride *IngestOverride `json:"ingest_override,omitempty" yaml:"ingest_override,omitempty"`
+}
+
+// IngestOverride describes how an ingested column was explicitly
+// overridden, rather than detected.
+type IngestOverride struct {
+	// Kind is the override kind, or kind.Unknown if the kind was
+	// detected as normal.
+	Kind kind.Kind `json:"kind,omitempty" yaml:"kind,omitempty"`
+
+	// RenamedFrom is the column's name before it was renamed, or empty
+	// if the column was not renamed.
+	RenamedFrom string `json:"renamed_from,omitempty" yaml:"renamed_from,omitempty"`
 }
 
 // Clone returns a deep copy of c. If c is nil, nil is returned.
@@ -449,9 +469,123 @@ func (c *Column) Clone() *Column {
 		Generated:     c.Generated,
 		GeneratedExpr: c.GeneratedExpr,
 		Collation:     c.Collation,
+		Profile:       c.Profile.Clone(),
+
+		IngestOverride: c.IngestOverride.Clone(),
 	}
 }
 
+// Clone returns a deep copy of o. If o is nil, nil is returned.
+func (o *IngestOverride) Clone() *IngestOverride {
+	if o == nil {
+		return nil
+	}
+
+	o2 := *o
+	return &o2
+}
+
+// ColumnProfile holds statistics for the values of a column, as computed
+// by column profiling. The statistics that apply depend on the column's
+// kind: for example, Mean applies only to a numeric column, and MinLength
+// only to a text column. A statistic that doesn't apply, or that couldn't
+// be computed, is nil.
+type ColumnProfile struct { //nolint:govet // field alignment
+	// NullCount is the number of null values.
+	NullCount int64 `json:"null_count" yaml:"null_count"`
+
+	// NullPct is NullCount as a percentage of the table's row count.
+	NullPct float64 `json:"null_pct" yaml:"null_pct"`
+
+	// DistinctCount is the number of distinct non-null values. It is
+	// nil for a column whose values can't be compared, such as a
+	// bytes column.
+	DistinctCount *int64 `json:"distinct_count,omitempty" yaml:"distinct_count,omitempty"`
+
+	// DistinctApprox is true if DistinctCount is an approximation, as
+	// computed by a HyperLogLog aggregate, rather than an exact count.
+	DistinctApprox bool `json:"distinct_approx,omitempty" yaml:"distinct_approx,omitempty"`
+
+	// Min is the minimum value.
+	Min any `json:"min,omitempty" yaml:"min,omitempty"`
+
+	// Max is the maximum value.
+	Max any `json:"max,omitempty" yaml:"max,omitempty"`
+
+	// Mean is the mean of a numeric column's values.
+	Mean *float64 `json:"mean,omitempty" yaml:"mean,omitempty"`
+
+	// StdDev is the sample standard deviation of a numeric column's values.
+	StdDev *float64 `json:"stddev,omitempty" yaml:"stddev,omitempty"`
+
+	// MinLength is the length in characters of a text column's
+	// shortest value.
+	MinLength *int64 `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+
+	// MaxLength is the length in characters of a text column's
+	// longest value.
+	MaxLength *int64 `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+
+	// Top holds the most frequent values, most frequent first. It is
+	// empty if every value is distinct.
+	Top []*ValueCount `json:"top,omitempty" yaml:"top,omitempty"`
+
+	// Histogram holds the distribution of a numeric column's values,
+	// as equal-width bins from Min to Max.
+	Histogram []*HistogramBin `json:"histogram,omitempty" yaml:"histogram,omitempty"`
+}
+
+// Clone returns a deep copy of p. If p is nil, nil is returned.
+func (p *ColumnProfile) Clone() *ColumnProfile {
+	if p == nil {
+		return nil
+	}
+
+	c := &ColumnProfile{
+		NullCount:      p.NullCount,
+		NullPct:        p.NullPct,
+		DistinctCount:  clonePtr(p.DistinctCount),
+		DistinctApprox: p.DistinctApprox,
+		Min:            p.Min,
+		Max:            p.Max,
+		Mean:           clonePtr(p.Mean),
+		StdDev:         clonePtr(p.StdDev),
+		MinLength:      clonePtr(p.MinLength),
+		MaxLength:      clonePtr(p.MaxLength),
+	}
+
+	if p.Top != nil {
+		c.Top = make([]*ValueCount, len(p.Top))
+		for i := range p.Top {
+			c.Top[i] = clonePtr(p.Top[i])
+		}
+	}
+
+	if p.Histogram != nil {
+		c.Histogram = make([]*HistogramBin, len(p.Histogram))
+		for i := range p.Histogram {
+			c.Histogram[i] = clonePtr(p.Histogram[i])
+		}
+	}
+
+	return c
+}
+
+// ValueCount is a value, and the number of times it occurs.
+type ValueCount struct {
+	Value any   `json:"value" yaml:"value"`
+	Count int64 `json:"count" yaml:"count"`
+}
+
+// HistogramBin is a bin of a histogram: the number of values that are
+// >= Lower, and < Upper. The final bin of a histogram also includes
+// values equal to its Upper.
+type HistogramBin struct {
+	Lower float64 `json:"lower" yaml:"lower"`
+	Upper float64 `json:"upper" yaml:"upper"`
+	Count int64   `json:"count" yaml:"count"`
+}
+
 // FKGroup groups the per-table foreign-key relationships under a
 // single parent so the JSON / YAML shape stays cohesive — both
 // directions of the same conceptual cluster live together under
@@ -542,6 +542,30 @@ func TestColumn_Clone_NewFields(t *testing.T) {
 	require.NotSame(t, c, got)
 }
 
+func TestColumnProfile_Clone(t *testing.T) {
+	require.Nil(t, (*metadata.ColumnProfile)(nil).Clone())
+
+	distinct, minLen := int64(3), int64(2)
+	mean := 1.5
+	p := &metadata.ColumnProfile{
+		NullCount: 1, NullPct: 25, DistinctCount: &distinct, DistinctApprox: true,
+		Min: int64(1), Max: int64(2), Mean: &mean, MinLength: &minLen,
+		Top:       []*metadata.ValueCount{{Value: int64(1), Count: 2}},
+		Histogram: []*metadata.HistogramBin{{Lower: 1, Upper: 2, Count: 3}},
+	}
+
+	got := p.Clone()
+	require.Equal(t, p, got)
+	require.NotSame(t, p.DistinctCount, got.DistinctCount)
+	require.NotSame(t, p.Mean, got.Mean)
+	require.NotSame(t, p.Top[0], got.Top[0])
+	require.NotSame(t, p.Histogram[0], got.Histogram[0])
+
+	col := &metadata.Column{Name: "a", Profile: p}
+	require.NotSame(t, p, col.Clone().Profile)
+	require.Equal(t, p, col.Clone().Profile)
+}
+
 func TestColumn_String(t *testing.T) {
 	col := &metadata.Column{
 		Name: "actor_id",
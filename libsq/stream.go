@@ -0,0 +1,572 @@
+package libsq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/kind"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/lg/lga"
+	"github.com/neilotoole/sq/libsq/core/lg/lgm"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/core/sqlz"
+	"github.com/neilotoole/sq/libsq/source"
+)
+
+var (
+	OptStreamWindow = options.NewDuration(
+		"stream.window",
+		nil,
+		0,
+		"Streaming mode aggregate window",
+		`In streaming mode (--stream), the duration of the tumbling window over
+which count and sum are aggregated. At the end of each window, a record is
+emitted with the window's start and end times, and the aggregate values;
+the aggregates are then reset. Windows are based on the time the rows
+arrive. If zero, a single record is emitted at the end of the input.
+Example: 10s or 1m.`,
+	)
+
+	OptStreamFollow = options.NewBool(
+		"stream.follow",
+		nil,
+		true,
+		"Streaming mode follows appends to source file",
+		`In streaming mode (--stream), when the source is a file, wait for data to be
+appended to the file when its end is reached, as "tail -f" does, instead of
+terminating. The file is read from the start if it's truncated. Has no effect
+when reading from stdin.`,
+	)
+)
+
+// StreamOpenFunc returns a reader of src's data, for use by
+// ExecSLQStream.
+type StreamOpenFunc func(ctx context.Context, src *source.Source) (io.ReadCloser, error)
+
+// ExecSLQStream executes the SLQ query in streaming mode, evaluating it
+// row-by-row as data arrives from the reader returned by openFn, and
+// writing results to recw as they're produced. Unlike ExecSLQ, the
+// source isn't ingested first, so ExecSLQStream works with unbounded
+// input, such as "tail -f app.jsonl | sq --stream".
+//
+// Only a subset of SLQ is supported: a single table of a JSONL, CSV or
+// TSV source, a where clause, column selection, a row range, and either
+// of the aggregates count and sum (which are emitted per OptStreamWindow).
+// Any other construct results in an error.
+func ExecSLQStream(ctx context.Context, qc *QueryContext, query string, openFn StreamOpenFunc,
+	recw RecordWriter,
+) error {
+	log := lg.FromContext(ctx)
+
+	sq, err := compileStream(ctx, qc, query)
+	if err != nil {
+		return err
+	}
+
+	rc, err := openFn(ctx, sq.src)
+	if err != nil {
+		return err
+	}
+	defer lg.WarnIfCloseError(log, lgm.CloseFileReader, rc)
+
+	decodeFn, err := newStreamDecoder(ctx, sq.src, rc)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Execute streaming query", lga.Src, sq.src, "query", query)
+	return sq.execute(ctx, decodeFn, recw)
+}
+
+// streamQuery is a SLQ query compiled for streaming evaluation.
+type streamQuery struct {
+	src   *source.Source
+	where streamEvalFunc
+
+	// cols are the projected columns. If empty, and aggs is also empty,
+	// all fields are output.
+	cols []streamCol
+
+	// aggs are the aggregate columns. A query has cols or aggs, not both.
+	aggs []streamAgg
+
+	window time.Duration
+	offset int
+	limit  int
+}
+
+// streamCol is a projected column of a streamQuery.
+type streamCol struct {
+	eval streamEvalFunc
+	name string
+}
+
+// streamAgg is an aggregate column of a streamQuery: count or sum. If
+// col is empty, the aggregate is count(*).
+type streamAgg struct {
+	fn   string
+	col  string
+	name string
+}
+
+// compileStream compiles query into a streamQuery.
+func compileStream(ctx context.Context, qc *QueryContext, query string) (*streamQuery, error) {
+	a, err := ast.Parse(lg.FromContext(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+
+	qm, err := buildQueryModel(qc, a)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case qm.Table == nil:
+		return nil, errz.Errorf("streaming mode requires a table selector, e.g. .data")
+	case len(qm.Joins) > 0:
+		return nil, errz.Errorf("streaming mode doesn't support join")
+	case qm.OrderBy != nil:
+		return nil, errz.Errorf("streaming mode doesn't support {%s}", qm.OrderBy.Text())
+	case qm.GroupBy != nil:
+		return nil, errz.Errorf("streaming mode doesn't support {%s}", qm.GroupBy.Text())
+	case qm.Having != nil:
+		return nil, errz.Errorf("streaming mode doesn't support {%s}", qm.Having.Text())
+	case qm.Distinct != nil:
+		return nil, errz.Errorf("streaming mode doesn't support {%s}", qm.Distinct.Text())
+	}
+
+	if qm.Table.Handle() == "" {
+		return nil, errz.Errorf("streaming mode: no source specified in query and no active source")
+	}
+
+	sq := &streamQuery{
+		window: OptStreamWindow.Get(options.FromContext(ctx)),
+		limit:  -1,
+	}
+	if sq.src, err = qc.Collection.Get(qm.Table.Handle()); err != nil {
+		return nil, err
+	}
+	if tbl := qm.Table.Table().Table; tbl != source.MonotableName {
+		return nil, errz.Errorf("streaming mode: source %s has only table {%s}, but query specifies {%s}",
+			sq.src.Handle, source.MonotableName, tbl)
+	}
+
+	if qm.Where != nil {
+		if sq.where, err = compileStreamExpr(qm.Where.Expr(), qc.Args); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, col := range qm.Cols {
+		if err = sq.addCol(col, qc.Args); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(sq.aggs) > 0 && len(sq.cols) > 0 {
+		return nil, errz.Errorf("streaming mode doesn't support mixing aggregates and columns")
+	}
+
+	if qm.Range != nil {
+		if len(sq.aggs) > 0 {
+			return nil, errz.Errorf("streaming mode doesn't support row range with aggregates")
+		}
+		sq.offset, sq.limit = qm.Range.Range()
+	}
+
+	return sq, nil
+}
+
+// addCol adds result column col to sq.
+func (sq *streamQuery) addCol(col ast.ResultColumn, args map[string]string) error {
+	name := col.Alias()
+	if name == "" {
+		name = col.Text()
+	}
+
+	switch col := col.(type) {
+	case *ast.ColSelectorNode:
+		if col.Alias() == "" {
+			name = col.ColName()
+		}
+		colName := col.ColName()
+		sq.cols = append(sq.cols, streamCol{
+			name: name,
+			eval: func(row streamRow) any { return row.vals[colName] },
+		})
+		return nil
+	case *ast.ExprElementNode:
+		eval, err := compileStreamExpr(col.ExprNode(), args)
+		if err != nil {
+			return err
+		}
+		sq.cols = append(sq.cols, streamCol{name: name, eval: eval})
+		return nil
+	case *ast.FuncNode:
+		agg := streamAgg{fn: col.FuncName(), name: name}
+		if agg.fn != "count" && agg.fn != "sum" {
+			return errz.Errorf("streaming mode doesn't support function {%s}: only count and sum", col.Text())
+		}
+
+		children := col.Children()
+		switch {
+		case len(children) == 0 && agg.fn == "count":
+		case len(children) == 1:
+			sel, ok := children[0].(*ast.ColSelectorNode)
+			if !ok {
+				return errz.Errorf("streaming mode: %s argument must be a column: {%s}", agg.fn, col.Text())
+			}
+			agg.col = sel.ColName()
+		default:
+			return errz.Errorf("streaming mode: invalid arguments to %s: {%s}", agg.fn, col.Text())
+		}
+		sq.aggs = append(sq.aggs, agg)
+		return nil
+	default:
+		return errz.Errorf("streaming mode doesn't support {%s}", col.Text())
+	}
+}
+
+// execute reads rows via decodeFn, writing results to recw.
+func (sq *streamQuery) execute(ctx context.Context, decodeFn streamDecodeFunc, recw RecordWriter) error {
+	sink := &streamSink{recw: recw}
+	defer sink.close()
+
+	// The rows are decoded in a separate goroutine, so that a window can
+	// be emitted while the decoder is blocked waiting for input. The
+	// goroutine is stopped when execute returns, e.g. on reaching the
+	// row range limit.
+	decodeCtx, cancelDecode := context.WithCancel(ctx)
+	defer cancelDecode()
+
+	rowCh := make(chan streamRow)
+	decodeErrCh := make(chan error, 1)
+	go func() {
+		defer close(rowCh)
+		for {
+			row, err := decodeFn()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					decodeErrCh <- err
+				}
+				return
+			}
+
+			select {
+			case <-decodeCtx.Done():
+				return
+			case rowCh <- row:
+			}
+		}
+	}()
+
+	if len(sq.aggs) > 0 {
+		return sq.executeAggs(ctx, sink, rowCh, decodeErrCh)
+	}
+
+	var skipped, written int
+	for {
+		if sq.limit >= 0 && written >= sq.limit {
+			return sink.finish(ctx, sq.emptyMeta())
+		}
+
+		var row streamRow
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case row, ok = <-rowCh:
+		}
+
+		if !ok {
+			select {
+			case err := <-decodeErrCh:
+				return err
+			default:
+			}
+			return sink.finish(ctx, sq.emptyMeta())
+		}
+
+		if sq.where != nil && !streamTruthy(sq.where(row)) {
+			continue
+		}
+		if skipped < sq.offset {
+			skipped++
+			continue
+		}
+
+		if err := sq.writeRow(ctx, sink, row); err != nil {
+			return err
+		}
+		written++
+	}
+}
+
+// writeRow writes the projection of row to sink, opening sink if
+// necessary. When all fields are output, the fields are those of the
+// first row written: later rows' additional fields are dropped.
+func (sq *streamQuery) writeRow(ctx context.Context, sink *streamSink, row streamRow) error {
+	if sq.cols == nil {
+		for _, k := range row.keys {
+			sq.cols = append(sq.cols, streamCol{
+				name: k,
+				eval: func(row streamRow) any { return row.vals[k] },
+			})
+		}
+	}
+
+	rec := make(record.Record, len(sq.cols))
+	for i, col := range sq.cols {
+		rec[i] = col.eval(row)
+	}
+
+	if !sink.opened {
+		meta := make(record.Meta, len(sq.cols))
+		for i, col := range sq.cols {
+			meta[i] = newStreamFieldMeta(col.name, streamValueKind(rec[i]))
+		}
+		if err := sink.open(ctx, meta); err != nil {
+			return err
+		}
+	}
+
+	return sink.send(ctx, rec)
+}
+
+// emptyMeta returns the record meta used when no row is output.
+func (sq *streamQuery) emptyMeta() record.Meta {
+	meta := make(record.Meta, len(sq.cols))
+	for i, col := range sq.cols {
+		meta[i] = newStreamFieldMeta(col.name, kind.Null)
+	}
+	return meta
+}
+
+// executeAggs aggregates the rows received on rowCh, writing a record to
+// sink at the end of each window, or at the end of input if there's no
+// window.
+func (sq *streamQuery) executeAggs(ctx context.Context, sink *streamSink, rowCh <-chan streamRow,
+	decodeErrCh <-chan error,
+) error {
+	var meta record.Meta
+	if sq.window > 0 {
+		meta = append(meta,
+			newStreamFieldMeta("window_start", kind.Datetime),
+			newStreamFieldMeta("window_end", kind.Datetime),
+		)
+	}
+	for _, agg := range sq.aggs {
+		knd := kind.Int
+		if agg.fn == "sum" {
+			knd = kind.Float
+		}
+		meta = append(meta, newStreamFieldMeta(agg.name, knd))
+	}
+	if err := sink.open(ctx, meta); err != nil {
+		return err
+	}
+
+	states := make([]streamAggState, len(sq.aggs))
+	emit := func(start, end time.Time) error {
+		rec := make(record.Record, 0, len(meta))
+		if sq.window > 0 {
+			rec = append(rec, start, end)
+		}
+		for i := range states {
+			rec = append(rec, states[i].value(sq.aggs[i].fn))
+			states[i] = streamAggState{}
+		}
+		return sink.send(ctx, rec)
+	}
+
+	var tickCh <-chan time.Time
+	windowStart := time.Now()
+	if sq.window > 0 {
+		ticker := time.NewTicker(sq.window)
+		defer ticker.Stop()
+		tickCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-tickCh:
+			if err := emit(windowStart, now); err != nil {
+				return err
+			}
+			windowStart = now
+		case row, ok := <-rowCh:
+			if !ok {
+				select {
+				case err := <-decodeErrCh:
+					return err
+				default:
+				}
+				// Emit the final (possibly partial) window.
+				if err := emit(windowStart, time.Now()); err != nil {
+					return err
+				}
+				return sink.finish(ctx, meta)
+			}
+
+			if sq.where != nil && !streamTruthy(sq.where(row)) {
+				continue
+			}
+			for i, agg := range sq.aggs {
+				states[i].add(agg, row)
+			}
+		}
+	}
+}
+
+// streamAggState is the state of a streamAgg over the current window.
+type streamAggState struct {
+	count   int64
+	intSum  int64
+	fltSum  float64
+	isFloat bool
+}
+
+// add adds row to the state of agg.
+func (s *streamAggState) add(agg streamAgg, row streamRow) {
+	if agg.col == "" {
+		s.count++
+		return
+	}
+
+	// As with SQLite, a non-numeric value is summed as zero.
+	switch v := row.vals[agg.col].(type) {
+	case nil:
+		return
+	case int64:
+		s.intSum += v
+		s.fltSum += float64(v)
+	case float64:
+		s.isFloat = true
+		s.fltSum += v
+	}
+	s.count++
+}
+
+// value returns the value of aggregate fn for s. As with SQL, the sum of
+// no values is nil.
+func (s *streamAggState) value(fn string) any {
+	switch {
+	case fn == "count":
+		return s.count
+	case s.count == 0:
+		return nil
+	case s.isFloat:
+		return s.fltSum
+	default:
+		return s.intSum
+	}
+}
+
+// streamSink wraps a RecordWriter, deferring the writer's Open until the
+// record meta is known, which for streaming can be when the first row
+// arrives.
+type streamSink struct {
+	recw     RecordWriter
+	recCh    chan<- record.Record
+	errCh    <-chan error
+	cancelFn context.CancelFunc
+	opened   bool
+}
+
+// open opens the sink's writer with meta.
+func (s *streamSink) open(ctx context.Context, meta record.Meta) error {
+	// We create a new ctx to pass to recw.Open; we use
+	// the new ctx/cancelFn to stop recw if a problem happens.
+	ctx, s.cancelFn = context.WithCancel(ctx)
+	recCh, errCh, err := s.recw.Open(ctx, s.cancelFn, meta)
+	if err != nil {
+		s.cancelFn()
+		return err
+	}
+	s.recCh, s.errCh, s.opened = recCh, errCh, true
+	return nil
+}
+
+// send sends rec to the writer.
+func (s *streamSink) send(ctx context.Context, rec record.Record) error {
+	select {
+	case <-ctx.Done():
+		s.cancelFn()
+		return ctx.Err()
+	case err := <-s.errCh:
+		lg.WarnIfError(lg.FromContext(ctx), "write record", err)
+		s.cancelFn()
+		return err
+	case s.recCh <- rec:
+		return nil
+	}
+}
+
+// finish opens the sink with meta if it's not already open, so that the
+// writer can write any header or similar even when there are no records.
+func (s *streamSink) finish(ctx context.Context, meta record.Meta) error {
+	if s.opened {
+		return nil
+	}
+	return s.open(ctx, meta)
+}
+
+// close closes the sink's record channel, if the sink was opened.
+func (s *streamSink) close() {
+	if s.opened {
+		close(s.recCh)
+	}
+}
+
+// newStreamFieldMeta returns a record.FieldMeta for a streamed column.
+func newStreamFieldMeta(name string, knd kind.Kind) *record.FieldMeta {
+	ct := &record.ColumnTypeData{
+		Name:        name,
+		Kind:        knd,
+		HasNullable: true,
+		Nullable:    true,
+		ScanType:    sqlz.RTypeAny,
+	}
+
+	switch knd { //nolint:exhaustive
+	case kind.Int:
+		ct.ScanType = sqlz.RTypeNullInt64
+	case kind.Float:
+		ct.ScanType = sqlz.RTypeNullFloat64
+	case kind.Bool:
+		ct.ScanType = sqlz.RTypeNullBool
+	case kind.Text:
+		ct.ScanType = sqlz.RTypeNullString
+	case kind.Datetime:
+		ct.ScanType = sqlz.RTypeNullTime
+	}
+	return record.NewFieldMeta(ct, "")
+}
+
+// streamValueKind returns the kind of record value v.
+func streamValueKind(v any) kind.Kind {
+	switch v.(type) {
+	case nil:
+		return kind.Null
+	case int64:
+		return kind.Int
+	case float64:
+		return kind.Float
+	case bool:
+		return kind.Bool
+	case string:
+		return kind.Text
+	case time.Time:
+		return kind.Datetime
+	default:
+		return kind.Unknown
+	}
+}
@@ -0,0 +1,237 @@
+package libsq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	stdj "encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/neilotoole/sq/libsq/core/errz"
+	"github.com/neilotoole/sq/libsq/core/lg"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/stringz"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+)
+
+// streamDecodeFunc returns the next row from the stream, or io.EOF when
+// the stream is exhausted.
+type streamDecodeFunc func() (streamRow, error)
+
+// newStreamDecoder returns a streamDecodeFunc that decodes rows from r,
+// per src's driver type. Only line-oriented types are supported: JSONL,
+// CSV and TSV.
+func newStreamDecoder(ctx context.Context, src *source.Source, r io.Reader) (streamDecodeFunc, error) {
+	switch src.Type { //nolint:exhaustive
+	case drivertype.JSONL:
+		return newJSONLStreamDecoder(ctx, r), nil
+	case drivertype.CSV, drivertype.TSV:
+		o := options.Merge(options.FromContext(ctx), src.Options)
+		header := true
+		if o.IsSet(driver.OptIngestHeader) {
+			header = driver.OptIngestHeader.Get(o)
+		}
+		delim := ','
+		if src.Type == drivertype.TSV {
+			delim = '\t'
+		}
+		return newCSVStreamDecoder(r, delim, header), nil
+	default:
+		return nil, errz.Errorf("streaming mode doesn't support source type {%s}: must be one of: %s, %s, %s",
+			src.Type, drivertype.JSONL, drivertype.CSV, drivertype.TSV)
+	}
+}
+
+// newJSONLStreamDecoder returns a streamDecodeFunc that decodes a JSON
+// object from each line of r. Nested objects are flattened, e.g. field
+// "b" of object "a" becomes "a_b", and arrays are returned as their JSON
+// text. Blank lines are skipped, as are lines that aren't JSON objects:
+// an unbounded stream shouldn't fail on a single malformed line.
+func newJSONLStreamDecoder(ctx context.Context, r io.Reader) streamDecodeFunc {
+	log := lg.FromContext(ctx)
+	br := bufio.NewReader(r)
+	var lineNum int
+
+	return func() (streamRow, error) {
+		for {
+			line, err := br.ReadBytes('\n')
+			if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+				return streamRow{}, err
+			}
+			lineNum++
+
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+
+			row, decodeErr := decodeJSONLStreamRow(line)
+			if decodeErr != nil {
+				log.Warn("Skipping malformed JSONL line", "line", lineNum, "error", decodeErr)
+				continue
+			}
+			return row, nil
+		}
+	}
+}
+
+// decodeJSONLStreamRow decodes line, which must be a JSON object.
+func decodeJSONLStreamRow(line []byte) (streamRow, error) {
+	row := streamRow{vals: map[string]any{}}
+	if line[0] != '{' {
+		return row, errz.New("not a JSON object")
+	}
+	if err := decodeJSONStreamObject(line, "", &row); err != nil {
+		return row, err
+	}
+	return row, nil
+}
+
+// decodeJSONStreamObject decodes JSON object obj into row, prefixing each
+// field name with prefix.
+func decodeJSONStreamObject(obj []byte, prefix string, row *streamRow) error {
+	members, keys, err := decodeStreamObjectOrdered(obj)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		val := bytes.TrimSpace(members[k])
+		if len(val) > 0 && val[0] == '{' {
+			if err = decodeJSONStreamObject(val, prefix+k+"_", row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v, err := decodeJSONStreamValue(val)
+		if err != nil {
+			return err
+		}
+		row.set(prefix+k, v)
+	}
+	return nil
+}
+
+// decodeStreamObjectOrdered decodes JSON object obj into its members,
+// also returning the member keys in document order.
+func decodeStreamObjectOrdered(obj []byte) (members map[string]stdj.RawMessage, keys []string, err error) {
+	dec := stdj.NewDecoder(bytes.NewReader(obj))
+	if _, err = dec.Token(); err != nil { // The opening '{'.
+		return nil, nil, errz.Err(err)
+	}
+
+	members = map[string]stdj.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, errz.Err(err)
+		}
+		k, _ := tok.(string)
+
+		var v stdj.RawMessage
+		if err = dec.Decode(&v); err != nil {
+			return nil, nil, errz.Err(err)
+		}
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = v
+	}
+	return members, keys, nil
+}
+
+// decodeJSONStreamValue decodes JSON scalar or array val. A number is
+// returned as int64 if it's integral, and float64 otherwise.
+func decodeJSONStreamValue(val []byte) (any, error) {
+	if len(val) == 0 {
+		return nil, errz.New("empty JSON value")
+	}
+
+	switch val[0] {
+	case 'n':
+		return nil, nil //nolint:nilnil
+	case 't', 'f':
+		var b bool
+		if err := stdj.Unmarshal(val, &b); err != nil {
+			return nil, errz.Err(err)
+		}
+		return b, nil
+	case '"':
+		var s string
+		if err := stdj.Unmarshal(val, &s); err != nil {
+			return nil, errz.Err(err)
+		}
+		return s, nil
+	case '[':
+		buf := &bytes.Buffer{}
+		if err := stdj.Compact(buf, val); err != nil {
+			return nil, errz.Err(err)
+		}
+		return buf.String(), nil
+	default:
+		if i, err := strconv.ParseInt(string(val), 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(string(val), 64)
+		if err != nil {
+			return nil, errz.Errorf("invalid JSON value: %s", val)
+		}
+		return f, nil
+	}
+}
+
+// newCSVStreamDecoder returns a streamDecodeFunc that decodes the records
+// of CSV stream r. If header is true, the first record supplies the field
+// names; otherwise, fields are named A, B, C, etc. An empty value is
+// returned as nil; a value that parses as a number is returned as int64
+// or float64.
+func newCSVStreamDecoder(r io.Reader, delim rune, header bool) streamDecodeFunc {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var names []string
+	return func() (streamRow, error) {
+		rec, err := cr.Read()
+		if err != nil {
+			return streamRow{}, err
+		}
+
+		if header && names == nil {
+			names = rec
+			if rec, err = cr.Read(); err != nil {
+				return streamRow{}, err
+			}
+		}
+
+		row := streamRow{vals: make(map[string]any, len(rec))}
+		for i, s := range rec {
+			if i >= len(names) {
+				names = append(names, stringz.GenerateAlphaColName(i, false))
+			}
+			row.set(names[i], csvStreamValue(s))
+		}
+		return row, nil
+	}
+}
+
+// csvStreamValue returns the value of CSV field s.
+func csvStreamValue(s string) any {
+	if s == "" {
+		return nil
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
@@ -0,0 +1,384 @@
+package libsq
+
+import (
+	stdj "encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neilotoole/sq/libsq/ast"
+	"github.com/neilotoole/sq/libsq/core/errz"
+)
+
+// streamRow is a row decoded from a stream. The keys are the row's
+// field names, in the order they were decoded.
+type streamRow struct {
+	vals map[string]any
+	keys []string
+}
+
+// set sets field k to v, appending k to the row's keys if it's new.
+func (r *streamRow) set(k string, v any) {
+	if _, ok := r.vals[k]; !ok {
+		r.keys = append(r.keys, k)
+	}
+	r.vals[k] = v
+}
+
+// streamEvalFunc evaluates an expression against a row. The result is
+// one of the record value types: nil, int64, float64, bool, string or
+// time.Time.
+type streamEvalFunc func(row streamRow) any
+
+// streamOpPrecedence returns the binding strength of a binary operator,
+// per SQLite's precedence: a greater value binds more tightly. The SLQ
+// AST doesn't encode precedence (the SQL renderer leaves that to the
+// database), so the stream evaluator must apply it itself. Note that in
+// SLQ, "||" is logical OR, not string concatenation.
+func streamOpPrecedence(op string) int {
+	switch op {
+	case "*", "/", "%":
+		return 7
+	case "+", "-":
+		return 6
+	case "<<", ">>", "&":
+		return 5
+	case "<", "<=", ">", ">=":
+		return 4
+	case "==", "!=":
+		return 3
+	case "&&":
+		return 2
+	case "||":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compileStreamExpr compiles node, an expression element such as a
+// selector, literal, arg, or expression, into a streamEvalFunc.
+func compileStreamExpr(node ast.Node, args map[string]string) (streamEvalFunc, error) {
+	switch node := node.(type) {
+	case *ast.ColSelectorNode:
+		name := node.ColName()
+		return func(row streamRow) any { return row.vals[name] }, nil
+	case *ast.LiteralNode:
+		val, err := streamLiteral(node)
+		if err != nil {
+			return nil, err
+		}
+		return func(streamRow) any { return val }, nil
+	case *ast.ArgNode:
+		val, ok := args[node.Key()]
+		if !ok {
+			return nil, errz.Errorf("no --arg value found for query variable %s", node.Text())
+		}
+		return func(streamRow) any { return val }, nil
+	case *ast.ExprNode:
+		operands, ops, err := flattenStreamExpr(node, args)
+		if err != nil {
+			return nil, err
+		}
+		return buildStreamExpr(operands, ops), nil
+	default:
+		return nil, errz.Errorf("streaming mode doesn't support {%s}", node.Text())
+	}
+}
+
+// flattenStreamExpr flattens the children of expr into alternating
+// operands and operators, such that len(operands) == len(ops)+1. Nested
+// expressions without parentheses are spliced in, because they don't
+// reflect operator precedence; a parenthesized expression is compiled as
+// a single operand.
+func flattenStreamExpr(expr *ast.ExprNode, args map[string]string) (operands []streamEvalFunc,
+	ops []string, err error,
+) {
+	for _, child := range expr.Children() {
+		if op, ok := child.(*ast.OperatorNode); ok {
+			if len(operands) != len(ops)+1 || streamOpPrecedence(op.Text()) == 0 {
+				return nil, nil, errz.Errorf("streaming mode doesn't support operator {%s} in {%s}",
+					op.Text(), expr.Text())
+			}
+			ops = append(ops, op.Text())
+			continue
+		}
+
+		if len(operands) != len(ops) {
+			return nil, nil, errz.Errorf("streaming mode doesn't support expression {%s}", expr.Text())
+		}
+
+		if child, ok := child.(*ast.ExprNode); ok && !child.HasParens() {
+			childOperands, childOps, err := flattenStreamExpr(child, args)
+			if err != nil {
+				return nil, nil, err
+			}
+			operands = append(operands, childOperands...)
+			ops = append(ops, childOps...)
+			continue
+		}
+
+		fn, err := compileStreamExpr(child, args)
+		if err != nil {
+			return nil, nil, err
+		}
+		operands = append(operands, fn)
+	}
+
+	if len(operands) == 0 || len(operands) != len(ops)+1 {
+		return nil, nil, errz.Errorf("streaming mode doesn't support expression {%s}", expr.Text())
+	}
+	return operands, ops, nil
+}
+
+// buildStreamExpr returns a streamEvalFunc for the flattened expression
+// given by operands and ops. The expression is split at its rightmost
+// operator of lowest precedence, so that operators are left-associative.
+func buildStreamExpr(operands []streamEvalFunc, ops []string) streamEvalFunc {
+	if len(ops) == 0 {
+		return operands[0]
+	}
+
+	split := 0
+	for i, op := range ops {
+		if streamOpPrecedence(op) <= streamOpPrecedence(ops[split]) {
+			split = i
+		}
+	}
+
+	op := ops[split]
+	left := buildStreamExpr(operands[:split+1], ops[:split])
+	right := buildStreamExpr(operands[split+1:], ops[split+1:])
+	return func(row streamRow) any {
+		return streamBinaryOp(op, left(row), right(row))
+	}
+}
+
+// streamLiteral returns the value of lit.
+func streamLiteral(lit *ast.LiteralNode) (any, error) {
+	text := lit.Text()
+	switch lit.LiteralType() {
+	case ast.LiteralNull:
+		return nil, nil //nolint:nilnil
+	case ast.LiteralNaturalNumber:
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, nil
+		}
+		fallthrough
+	case ast.LiteralAnyNumber:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, errz.Wrapf(err, "invalid number literal {%s}", text)
+		}
+		return f, nil
+	case ast.LiteralString:
+		// The SLQ string grammar is the same as JSON's.
+		var s string
+		if err := stdj.Unmarshal([]byte(text), &s); err != nil {
+			return nil, errz.Wrapf(err, "malformed literal: %s", text)
+		}
+		return s, nil
+	case ast.LiteralBool:
+		return text == "true", nil
+	default:
+		return nil, errz.Errorf("unknown literal type {%s}: %s", lit.LiteralType(), text)
+	}
+}
+
+// streamTruthy returns the truth value of v, per SQLite's rules: NULL is
+// false, a number is true if non-zero, and a string is true if it
+// converts to a non-zero number.
+func streamTruthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return err == nil && f != 0
+	case time.Time:
+		return true
+	default:
+		return false
+	}
+}
+
+// streamBinaryOp returns the result of applying binary operator op to
+// l and r.
+func streamBinaryOp(op string, l, r any) any {
+	switch op {
+	case "&&":
+		return streamTruthy(l) && streamTruthy(r)
+	case "||":
+		return streamTruthy(l) || streamTruthy(r)
+	case "==":
+		return streamEqual(l, r)
+	case "!=":
+		return !streamEqual(l, r)
+	case "<", "<=", ">", ">=":
+		c, ok := streamCompare(l, r)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	case "<<", ">>", "&":
+		li, lok := l.(int64)
+		ri, rok := r.(int64)
+		if !lok || !rok {
+			return nil
+		}
+		switch op {
+		case "<<":
+			return li << uint64(ri) //nolint:gosec
+		case ">>":
+			return li >> uint64(ri) //nolint:gosec
+		default:
+			return li & ri
+		}
+	default:
+		return streamArith(op, l, r)
+	}
+}
+
+// streamArith returns the result of arithmetic operator op applied to l
+// and r. If both are int64, the result is int64; otherwise, if both are
+// numbers, it's float64. Otherwise, or on division by zero, the result
+// is nil.
+func streamArith(op string, l, r any) any {
+	li, lok := l.(int64)
+	ri, rok := r.(int64)
+	if lok && rok {
+		switch op {
+		case "+":
+			return li + ri
+		case "-":
+			return li - ri
+		case "*":
+			return li * ri
+		case "/":
+			if ri == 0 {
+				return nil
+			}
+			return li / ri
+		case "%":
+			if ri == 0 {
+				return nil
+			}
+			return li % ri
+		}
+		return nil
+	}
+
+	lf, lok := streamFloat(l)
+	rf, rok := streamFloat(r)
+	if !lok || !rok {
+		return nil
+	}
+	switch op {
+	case "+":
+		return lf + rf
+	case "-":
+		return lf - rf
+	case "*":
+		return lf * rf
+	case "/":
+		if rf == 0 {
+			return nil
+		}
+		return lf / rf
+	case "%":
+		if rf == 0 {
+			return nil
+		}
+		return math.Mod(lf, rf)
+	}
+	return nil
+}
+
+// streamFloat returns v as a float64, if v is a number.
+func streamFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// streamEqual returns true if l and r are equal. Two nils are equal (the
+// SLQ "== null" renders as "IS NULL"). Values of different types, other
+// than int64 and float64, are not equal.
+func streamEqual(l, r any) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	c, ok := streamCompare(l, r)
+	return ok && c == 0
+}
+
+// streamCompare compares l and r, returning -1, 0, or +1. If l and r
+// can't be compared, e.g. because either is nil, or they're of
+// different types, ok is false.
+func streamCompare(l, r any) (c int, ok bool) {
+	switch l := l.(type) {
+	case string:
+		if r, ok := r.(string); ok {
+			return strings.Compare(l, r), true
+		}
+	case bool:
+		if r, ok := r.(bool); ok {
+			switch {
+			case l == r:
+				return 0, true
+			case r:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	case time.Time:
+		if r, ok := r.(time.Time); ok {
+			return l.Compare(r), true
+		}
+	case int64:
+		if r, ok := r.(int64); ok {
+			switch {
+			case l < r:
+				return -1, true
+			case l > r:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	lf, lok := streamFloat(l)
+	rf, rok := streamFloat(r)
+	if !lok || !rok {
+		return 0, false
+	}
+	switch {
+	case lf < rf:
+		return -1, true
+	case lf > rf:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
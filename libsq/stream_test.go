@@ -0,0 +1,261 @@
+package libsq_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neilotoole/sq/cli/output"
+	"github.com/neilotoole/sq/libsq"
+	"github.com/neilotoole/sq/libsq/core/options"
+	"github.com/neilotoole/sq/libsq/core/record"
+	"github.com/neilotoole/sq/libsq/driver"
+	"github.com/neilotoole/sq/libsq/source"
+	"github.com/neilotoole/sq/libsq/source/drivertype"
+	"github.com/neilotoole/sq/testh"
+)
+
+const streamLogJSONL = `{"ts": 1, "level": "info", "msg": "started", "req": {"ms": 12}}
+{"ts": 2, "level": "error", "msg": "timeout", "req": {"ms": 3000}}
+
+not json
+{"ts": 3, "level": "warn", "msg": "slow", "req": {"ms": 900.5}, "tags": ["a", "b"]}
+{"ts": 4, "level": "error", "msg": "refused", "req": {"ms": null}}
+`
+
+const streamLogCSV = `ts,level,ms
+1,info,12
+2,error,3000
+3,warn,900.5
+4,error,
+`
+
+// execSLQStream executes query in streaming mode against an @app source
+// of type typ, whose data is read from r.
+func execSLQStream(t *testing.T, typ drivertype.Type, r io.Reader, query string,
+	args map[string]string, opts options.Options,
+) (*testh.RecordSink, error) {
+	th := testh.New(t)
+	src := &source.Source{Handle: "@app", Type: typ, Location: "/tmp/app." + typ.String(), Options: opts}
+	coll := &source.Collection{}
+	require.NoError(t, coll.Add(src))
+	_, err := coll.SetActive(src.Handle, false)
+	require.NoError(t, err)
+
+	qc := &libsq.QueryContext{Collection: coll, Grips: th.Grips(), Args: args}
+	openFn := func(context.Context, *source.Source) (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	}
+
+	ctx := options.NewContext(th.Context, options.Merge(options.FromContext(th.Context), opts))
+	sink := &testh.RecordSink{}
+	recw := output.NewRecordWriterAdapter(ctx, sink)
+	execErr := libsq.ExecSLQStream(ctx, qc, query, openFn, recw)
+	_, waitErr := recw.Wait()
+	if execErr != nil {
+		return nil, execErr
+	}
+	return sink, waitErr
+}
+
+func TestExecSLQStream(t *testing.T) {
+	testCases := []struct {
+		name      string
+		typ       drivertype.Type
+		in        string
+		args      map[string]string
+		opts      options.Options
+		wantCols  []string
+		wantRecs  []record.Record
+		wantErr   bool
+		wantEmpty bool
+	}{
+		{
+			name:     "jsonl_all",
+			typ:      drivertype.JSONL,
+			in:       ".data | .[0:2]",
+			wantCols: []string{"ts", "level", "msg", "req_ms"},
+			wantRecs: []record.Record{
+				{int64(1), "info", "started", int64(12)},
+				{int64(2), "error", "timeout", int64(3000)},
+			},
+		},
+		{
+			name:     "jsonl_where",
+			typ:      drivertype.JSONL,
+			in:       `@app.data | where(.level == "error") | .ts, .msg:message`,
+			wantCols: []string{"ts", "message"},
+			wantRecs: []record.Record{{int64(2), "timeout"}, {int64(4), "refused"}},
+		},
+		{
+			name:     "jsonl_precedence",
+			typ:      drivertype.JSONL,
+			in:       `.data | where(.ts > 1 && .req_ms < 1000 || .ts == 1) | .ts, .req_ms * 2 + 1:x`,
+			wantCols: []string{"ts", "x"},
+			wantRecs: []record.Record{{int64(1), int64(25)}, {int64(3), float64(1802)}},
+		},
+		{
+			name:     "jsonl_parens",
+			typ:      drivertype.JSONL,
+			in:       `.data | where((.ts + 1) * 2 == 6) | .msg`,
+			wantCols: []string{"msg"},
+			wantRecs: []record.Record{{"timeout"}},
+		},
+		{
+			name:     "jsonl_null_and_array",
+			typ:      drivertype.JSONL,
+			in:       `.data | where(.req_ms == null || .tags != null) | .ts, .tags`,
+			wantCols: []string{"ts", "tags"},
+			wantRecs: []record.Record{{int64(3), `["a","b"]`}, {int64(4), nil}},
+		},
+		{
+			name:     "jsonl_arg_offset",
+			typ:      drivertype.JSONL,
+			in:       `.data | where(.level != $lvl) | .ts | .[1:]`,
+			args:     map[string]string{"lvl": "info"},
+			wantCols: []string{"ts"},
+			wantRecs: []record.Record{{int64(3)}, {int64(4)}},
+		},
+		{
+			name:      "jsonl_no_match",
+			typ:       drivertype.JSONL,
+			in:        `.data | where(.level == "debug") | .ts`,
+			wantCols:  []string{"ts"},
+			wantEmpty: true,
+		},
+		{
+			name:     "jsonl_aggs",
+			typ:      drivertype.JSONL,
+			in:       `.data | count, count(.req_ms):n, sum(.req_ms)`,
+			wantCols: []string{"count", "n", "sum(.req_ms)"},
+			wantRecs: []record.Record{{int64(4), int64(3), float64(3912.5)}},
+		},
+		{
+			name:     "csv_where",
+			typ:      drivertype.CSV,
+			in:       `.data | where(.ms >= 900) | .level, .ms`,
+			wantCols: []string{"level", "ms"},
+			wantRecs: []record.Record{{"error", int64(3000)}, {"warn", 900.5}},
+		},
+		{
+			name:     "csv_no_header",
+			typ:      drivertype.CSV,
+			in:       `.data | where(.A == 4)`,
+			opts:     options.Options{driver.OptIngestHeader.Key(): false},
+			wantCols: []string{"A", "B", "C"},
+			wantRecs: []record.Record{{int64(4), "error", nil}},
+		},
+		{
+			name:     "csv_sum_int",
+			typ:      drivertype.CSV,
+			in:       `.data | where(.level == "error") | sum(.ts):total`,
+			wantCols: []string{"total"},
+			wantRecs: []record.Record{{int64(6)}},
+		},
+		{name: "err_order_by", typ: drivertype.JSONL, in: `.data | order_by(.ts)`, wantErr: true},
+		{name: "err_group_by", typ: drivertype.JSONL, in: `.data | group_by(.level) | count`, wantErr: true},
+		{name: "err_func", typ: drivertype.JSONL, in: `.data | max(.ts)`, wantErr: true},
+		{name: "err_mixed_aggs", typ: drivertype.JSONL, in: `.data | .ts, count`, wantErr: true},
+		{name: "err_missing_arg", typ: drivertype.JSONL, in: `.data | where(.ts == $ts)`, wantErr: true},
+		{name: "err_table", typ: drivertype.JSONL, in: `.logs`, wantErr: true},
+		{name: "err_src_type", typ: drivertype.XLSX, in: `.data`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := streamLogJSONL
+			if tc.typ == drivertype.CSV {
+				in = streamLogCSV
+				if noHeader := tc.opts != nil; noHeader {
+					in = in[strings.IndexByte(in, '\n')+1:]
+				}
+			}
+
+			sink, err := execSLQStream(t, tc.typ, strings.NewReader(in), tc.in, tc.args, tc.opts)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantCols, sink.RecMeta.Names())
+			if tc.wantEmpty {
+				require.Empty(t, sink.Recs)
+				return
+			}
+			require.Equal(t, tc.wantRecs, sink.Recs)
+		})
+	}
+}
+
+// TestExecSLQStream_Unbounded verifies that records are emitted as input
+// arrives, before the input ends.
+func TestExecSLQStream_Unbounded(t *testing.T) {
+	pr, pw := io.Pipe()
+	var (
+		wg   sync.WaitGroup
+		sink *testh.RecordSink
+		err  error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sink, err = execSLQStream(t, drivertype.JSONL, pr, `.data | where(.level == "error") | .msg | .[0:2]`,
+			nil, nil)
+	}()
+
+	write := func(s string) {
+		_, writeErr := pw.Write([]byte(s + "\n"))
+		assert.NoError(t, writeErr)
+	}
+	write(`{"level": "info", "msg": "started"}`)
+	write(`{"level": "error", "msg": "timeout"}`)
+	write(`{"level": "error", "msg": "refused"}`)
+
+	// The row range limit is reached, so the query completes even though
+	// the input is still open.
+	wg.Wait()
+	require.NoError(t, err)
+	require.Equal(t, []record.Record{{"timeout"}, {"refused"}}, sink.Recs)
+	require.NoError(t, pw.Close())
+}
+
+// TestExecSLQStream_Window verifies that windowed aggregates are emitted
+// at the end of each window, while the input is still open.
+func TestExecSLQStream_Window(t *testing.T) {
+	pr, pw := io.Pipe()
+	var (
+		wg   sync.WaitGroup
+		sink *testh.RecordSink
+		err  error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sink, err = execSLQStream(t, drivertype.JSONL, pr, `.data | count`, nil,
+			options.Options{libsq.OptStreamWindow.Key(): time.Millisecond * 200})
+	}()
+
+	_, writeErr := pw.Write([]byte("{\"a\": 1}\n{\"a\": 2}\n"))
+	require.NoError(t, writeErr)
+	time.Sleep(time.Millisecond * 300)
+	require.NoError(t, pw.Close())
+	wg.Wait()
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"window_start", "window_end", "count"}, sink.RecMeta.Names())
+	require.GreaterOrEqual(t, len(sink.Recs), 2)
+	require.Equal(t, int64(2), sink.Recs[0][2])
+	for _, rec := range sink.Recs[1:] {
+		require.Equal(t, int64(0), rec[2])
+	}
+	for _, rec := range sink.Recs {
+		start, end := rec[0].(time.Time), rec[1].(time.Time)
+		require.False(t, end.Before(start))
+	}
+}
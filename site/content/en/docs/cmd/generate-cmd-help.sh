@@ -36,6 +36,8 @@ cmds=(
   "config keyring get"
   "config keyring rm"
   "config keyring migrate"
+  "config sops"
+  "config sops migrate"
   "diff"
   "driver ls"
   "group"
@@ -44,9 +46,17 @@ cmds=(
   "ls"
   "mv"
   "ping"
+  "query"
+  "query ls"
+  "query add"
+  "query rm"
+  "query edit"
   "rm"
+  "run"
   "sql"
   "src"
+  "src export"
+  "src import"
   "tbl copy"
   "tbl drop"
   "tbl truncate"
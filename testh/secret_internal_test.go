@@ -44,7 +44,7 @@ func TestHarnessGripsResolvesEnvPlaceholder(t *testing.T) {
 // production placeholder set. If this drifts from cli/run.go, update both.
 func TestNewSecretRegistrySchemes(t *testing.T) {
 	require.Equal(t,
-		[]string{"env", "file", "keyring", "op"},
+		[]string{"env", "file", "keyring", "op", "sops", "vault"},
 		newSecretRegistry().Schemes())
 }
 
@@ -26,7 +26,9 @@ import (
 	"github.com/neilotoole/sq/drivers/csv"
 	"github.com/neilotoole/sq/drivers/duckdb"
 	"github.com/neilotoole/sq/drivers/json"
+	"github.com/neilotoole/sq/drivers/mongodb"
 	"github.com/neilotoole/sq/drivers/mysql"
+	"github.com/neilotoole/sq/drivers/odbc"
 	"github.com/neilotoole/sq/drivers/oracle"
 	"github.com/neilotoole/sq/drivers/postgres"
 	"github.com/neilotoole/sq/drivers/rqlite"
@@ -51,6 +53,8 @@ import (
 	"github.com/neilotoole/sq/libsq/core/secret/file"
 	"github.com/neilotoole/sq/libsq/core/secret/keyring"
 	"github.com/neilotoole/sq/libsq/core/secret/op"
+	"github.com/neilotoole/sq/libsq/core/secret/sops"
+	"github.com/neilotoole/sq/libsq/core/secret/vault"
 	"github.com/neilotoole/sq/libsq/core/sqlz"
 	"github.com/neilotoole/sq/libsq/core/stringz"
 	"github.com/neilotoole/sq/libsq/core/tablefq"
@@ -191,6 +195,7 @@ func (h *Helper) init() {
 		// Helper.Source (for harness helpers that bypass Grips, e.g. the
 		// file-copy logic and openNew).
 		h.secretReg = newSecretRegistry()
+		h.files.SetSecretRegistry(h.secretReg)
 		h.grips = driver.NewGrips(h.registry, h.files, h.secretReg, sqlite3.NewScratchSource)
 		h.Cleanup.AddC(h.grips)
 
@@ -202,16 +207,20 @@ func (h *Helper) init() {
 		h.registry.AddProvider(drivertype.MySQL, &mysql.Provider{Log: h.Log()})
 		h.registry.AddProvider(drivertype.ClickHouse, &clickhouse.Provider{Log: h.Log()})
 		h.registry.AddProvider(drivertype.Oracle, &oracle.Provider{Log: h.Log()})
+		h.registry.AddProvider(drivertype.MongoDB, &mongodb.Provider{Log: h.Log(), Ingester: h.grips})
+		h.registry.AddProvider(drivertype.ODBC, &odbc.Provider{Log: h.Log()})
 
 		csvp := &csv.Provider{Log: h.Log(), Ingester: h.grips, Files: h.files}
 		h.registry.AddProvider(drivertype.CSV, csvp)
 		h.registry.AddProvider(drivertype.TSV, csvp)
+		h.registry.AddProvider(drivertype.FixedWidth, csvp)
 		h.files.AddDriverDetectors(csv.DetectCSV, csv.DetectTSV)
 
 		jsonp := &json.Provider{Log: h.Log(), Ingester: h.grips, Files: h.files}
 		h.registry.AddProvider(drivertype.JSON, jsonp)
 		h.registry.AddProvider(drivertype.JSONA, jsonp)
 		h.registry.AddProvider(drivertype.JSONL, jsonp)
+		h.registry.AddProvider(drivertype.REST, jsonp)
 		h.files.AddDriverDetectors(
 			json.DetectJSON(driver.OptIngestSampleSize.Get(nil)),
 			json.DetectJSONA(driver.OptIngestSampleSize.Get(nil)),
@@ -1099,5 +1108,7 @@ func newSecretRegistry() *secret.Registry {
 	reg.Register("env", env.NewResolver())
 	reg.Register("file", file.NewResolver())
 	reg.Register("op", op.NewResolver())
+	reg.Register("sops", sops.NewResolver())
+	reg.Register("vault", vault.NewResolver())
 	return reg
 }